@@ -317,7 +317,9 @@ wire_deps() {
   echo "wire_deps: $DEP_COUNT dep(s) wired at create time via --deps (SINGLE_CALL)" >&2
 }
 
-# update_state: Atomic write to state JSON (stage=tasks, epic_id, task_count, dep_count).
+# update_state: Atomic write to state JSON (stage=tasks, epic_id, task_count, dep_count),
+# through 'maestro state update' when maestro is installed, which also checks the
+# feature may move to tasks; with jq otherwise.
 update_state() {
   if [[ "${DRY_RUN:-false}" == "true" ]]; then
     echo "  (dry-run: skipping ${FUNCNAME[0]})" >&2
@@ -341,6 +343,16 @@ update_state() {
     action="tasks created"
   fi
 
+  if command -v maestro >/dev/null 2>&1 && maestro state update --help >/dev/null 2>&1; then
+    # Strings are passed as JSON so an ID that looks like a number stays a string
+    MAESTRO_MAIN_REPO="$MAESTRO_BASE" maestro state update --note "$note" "$FEATURE_ID" tasks "$action" \
+      "epic_id=$(jq -n --arg v "$EPIC_BD_ID" '$v')" \
+      "bd_label=$(jq -n --arg v "feature:$feature_num" '$v')" \
+      "task_count=$task_count" "dep_count=$dep_count" >/dev/null
+    echo "Updated state file: $STATE_FILE" >&2
+    return 0
+  fi
+
   jq \
     --arg epic_id "$EPIC_BD_ID" \
     --arg bd_label "feature:$feature_num" \
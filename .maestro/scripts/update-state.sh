@@ -8,10 +8,10 @@
 # commands only supply real DECISIONS (stage, action, field values) — never timestamps.
 #
 # Usage:
-#   update-state.sh <feature_id> <stage> <action> [field=value ...]
+#   update-state.sh [--force] <feature_id> <stage> <action> [field=value ...]
 #
 #   <feature_id>  e.g. 001-add-task-tracker
-#   <stage>       specify|clarify|research|plan|tasks|implement|pm-validate|commit|analyze|complete
+#   <stage>       specify|clarify|research|plan|tasks|analyze|implement|review|pm-validate|commit|complete
 #   <action>      short history note, e.g. "plan generated: 5 tasks"
 #   field=value   optional top-level fields to set; values are JSON if parseable, else string.
 #                 (e.g. user_stories=5 spec_path=.maestro/specs/001/spec.md worktree_required=false)
@@ -19,14 +19,26 @@
 # Behaviour:
 #   - first call creates the file with created_at = now
 #   - every call sets stage, updated_at = now, and appends {stage,timestamp,action} to history
+#   - when MAESTRO_ACTOR is set (e.g. "claude" or a CI job name), the history entry
+#     also records it as actor, which 'maestro report agents' summarizes
 #   - prints the resulting JSON
 #
-# Requires: jq, date (GNU or BSD both fine for -u +%Y-%m-%dT%H:%M:%SZ).
+# When maestro is installed this runs 'maestro state update --force', which
+# also adds schema_version, and records every stage as this script does but
+# warns on stderr about a change that skips a required stage (specify, plan,
+# tasks, implement).
+#
+# Requires: maestro, or jq and date (GNU or BSD both fine for -u +%Y-%m-%dT%H:%M:%SZ).
 set -euo pipefail
 
+if command -v maestro >/dev/null 2>&1 && maestro state update --help >/dev/null 2>&1; then
+  exec maestro state update --force "$@"
+fi
+[ "${1:-}" = "--force" ] && shift
+
 command -v jq >/dev/null 2>&1 || { echo "update-state.sh: jq required" >&2; exit 2; }
 
-FEATURE_ID="${1:?usage: update-state.sh <feature_id> <stage> <action> [field=value ...]}"
+FEATURE_ID="${1:?usage: update-state.sh [--force] <feature_id> <stage> <action> [field=value ...]}"
 STAGE="${2:?stage required}"
 ACTION="${3:?action required}"
 shift 3
@@ -60,9 +72,11 @@ echo "$BASE" \
       --arg stage "$STAGE" \
       --arg action "$ACTION" \
       --arg now "$NOW" \
+      --arg actor "${MAESTRO_ACTOR:-}" \
       --argjson fields "$FIELDS_JSON" \
       '. + $fields
        | .stage = $stage
        | .updated_at = $now
-       | .history = ((.history // []) + [{stage:$stage, timestamp:$now, action:$action}])' \
+       | .history = ((.history // []) + [{stage:$stage, timestamp:$now, action:$action}
+                                          + (if $actor == "" then {} else {actor:$actor} end)])' \
   | tee "$STATE_FILE"
@@ -64,8 +64,9 @@ Options (new form):
   --feature <id-or-slug> Feature id (e.g. "059-partner-assistant-add-invoice-
                          download") or bare slug. Defaults to the most-recent
                          feature under .maestro/specs/.
-  --base-branch <name>   Base branch to fork from (default: main, or
-                         project.base_branch from .maestro/config.yaml).
+  --base-branch <name>   Base branch to fork from (default:
+                         project.base_branch from .maestro/config.yaml, else
+                         the remote's default branch, else the current one).
 
 The legacy positional form is preserved for backward compatibility with
 in-flight callers; new code should use --repo / --feature.
@@ -167,6 +168,22 @@ config_base_branch() {
   fi
 }
 
+# default_base_branch prints project.base_branch from the config (recorded by
+# `maestro init`), else the remote's default branch, else the current branch,
+# else main.
+default_base_branch() {
+  local branch
+  branch="$(config_base_branch)"
+  if [[ -z "$branch" ]]; then
+    branch="$(git symbolic-ref --short refs/remotes/origin/HEAD 2>/dev/null || true)"
+    branch="${branch#origin/}"
+  fi
+  if [[ -z "$branch" ]]; then
+    branch="$(git symbolic-ref --short HEAD 2>/dev/null || true)"
+  fi
+  echo "${branch:-main}"
+}
+
 # ===========================================================================
 # Common git/worktree primitives
 # ===========================================================================
@@ -222,8 +239,7 @@ run_legacy_form() {
   local base_branch="${LEGACY_POSITIONAL[2]:-}"
 
   if [[ -z "$base_branch" ]]; then
-    base_branch="$(config_base_branch)"
-    base_branch="${base_branch:-main}"
+    base_branch="$(default_base_branch)"
   fi
 
   local worktrees_dir=".worktrees"
@@ -578,8 +594,7 @@ run_new_form() {
 
   local base_branch="$BASE_BRANCH_FLAG"
   if [[ -z "$base_branch" ]]; then
-    base_branch="$(config_base_branch)"
-    base_branch="${base_branch:-main}"
+    base_branch="$(default_base_branch)"
   fi
 
   # ---- Resumable shortcut: if the worktree already exists at the target
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spec-maestro/maestro-cli/pkg/agents"
+	"github.com/spec-maestro/maestro-cli/pkg/agentsmd"
+	"github.com/spec-maestro/maestro-cli/pkg/report"
+)
+
+// agentsMDContent is the instructions maestro writes to AGENTS.md.
+var agentsMDContent = agentsmd.Render(agentsmd.Data{})
+
+// agentsMDMode resolves --agents-md-mode. Without it init overwrites
+// AGENTS.md, or keeps the existing file when merging into .maestro/.
+func agentsMDMode(flag string, merging bool) (agentsmd.Mode, error) {
+	if flag != "" {
+		return agentsmd.ParseMode(flag)
+	}
+	if merging {
+		return agentsmd.Skip, nil
+	}
+	return agentsmd.Overwrite, nil
+}
+
+// readAgentsMD returns the AGENTS.md of the project at root, or nil when
+// there is none.
+func readAgentsMD(root string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(root, "AGENTS.md"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading AGENTS.md: %w", err)
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	return data, nil
+}
+
+// writeAgentsMD writes maestro's instructions to AGENTS.md in mode and
+// records the step.
+func writeAgentsMD(root string, op *report.Operation, mode agentsmd.Mode) error {
+	existing, err := readAgentsMD(root)
+	if err != nil {
+		return op.Fail("AGENTS.md", err)
+	}
+	updated, changed, err := agentsmd.Apply(existing, agentsMDContent, mode)
+	if err != nil {
+		return op.Fail("AGENTS.md", err)
+	}
+	if !changed {
+		fmt.Println("Kept existing AGENTS.md")
+		op.Skip("AGENTS.md", "kept existing file")
+		return nil
+	}
+	if err := os.WriteFile(filepath.Join(root, "AGENTS.md"), []byte(updated), 0644); err != nil {
+		return op.Fail("AGENTS.md", fmt.Errorf("writing AGENTS.md: %w", err))
+	}
+	if existing == nil {
+		op.OK("AGENTS.md", "")
+	} else {
+		op.OK("AGENTS.md", string(mode))
+	}
+	return nil
+}
+
+// planAgentsMD reports what writing AGENTS.md in mode would do.
+func planAgentsMD(root string, mode agentsmd.Mode) (agents.PlannedFile, error) {
+	existing, err := readAgentsMD(root)
+	if err != nil {
+		return agents.PlannedFile{}, err
+	}
+	_, changed, err := agentsmd.Apply(existing, agentsMDContent, mode)
+	if err != nil {
+		return agents.PlannedFile{}, fmt.Errorf("AGENTS.md: %w", err)
+	}
+	planned := agents.PlannedFile{Path: "AGENTS.md", Change: agents.ChangeCreate}
+	switch {
+	case !changed:
+		planned.Change = agents.ChangeUnchanged
+	case existing != nil:
+		planned.Change = agents.ChangeOverwrite
+	}
+	return planned, nil
+}
+
+// refreshAgentsMDBlock rewrites the managed block of AGENTS.md, if it has
+// one, so update keeps the instructions current without touching the
+// user's text. A damaged block is only a warning.
+func refreshAgentsMDBlock(root string, op *report.Operation) {
+	existing, err := readAgentsMD(root)
+	if err != nil {
+		op.Warn("AGENTS.md", err.Error())
+		return
+	}
+	if existing == nil {
+		return
+	}
+	updated, found, err := agentsmd.ReplaceBlock(string(existing), agentsMDContent)
+	if err != nil {
+		op.Warn("AGENTS.md", err.Error())
+		return
+	}
+	if !found {
+		return
+	}
+	if updated == string(existing) {
+		op.Skip("AGENTS.md", "maestro block up to date")
+		return
+	}
+	if err := os.WriteFile(filepath.Join(root, "AGENTS.md"), []byte(updated), 0644); err != nil {
+		op.Warn("AGENTS.md", fmt.Sprintf("writing AGENTS.md: %v", err))
+		return
+	}
+	fmt.Println("Refreshed the maestro block in AGENTS.md")
+	op.OK("AGENTS.md", "refreshed maestro block")
+}
+
+// agentInstructions renders the instruction file for the agent reading dir
+// in the project at root, listing the maestro commands installed there.
+func agentInstructions(root, dir string) (string, error) {
+	installed, err := agents.InstalledCommands(root, dir)
+	if err != nil {
+		return "", err
+	}
+	data := agentsmd.Data{Agent: agents.AgentName(dir)}
+	for _, c := range installed {
+		data.Commands = append(data.Commands, agentsmd.Command{Usage: c.Usage(), Description: c.Description})
+	}
+	return agentsmd.Render(data), nil
+}
+
+// writeAgentInstructions writes the managed block of the instruction file
+// of each agent in dirs that has one (see agents.InstructionFile), leaving
+// the user's text around the block alone, and returns the files it changed,
+// relative to root.
+func writeAgentInstructions(root string, dirs []string) ([]string, error) {
+	var written []string
+	for _, dir := range dirs {
+		path := agents.InstructionFile(dir)
+		if path == "" {
+			continue
+		}
+		content, err := agentInstructions(root, dir)
+		if err != nil {
+			return written, fmt.Errorf("reading %s commands: %w", dir, err)
+		}
+		existing, err := os.ReadFile(filepath.Join(root, path))
+		if err != nil && !os.IsNotExist(err) {
+			return written, fmt.Errorf("reading %s: %w", path, err)
+		}
+		if err == nil && existing == nil {
+			existing = []byte{}
+		}
+		updated, changed, err := agentsmd.Apply(existing, content, agentsmd.Merge)
+		if err != nil {
+			return written, fmt.Errorf("%s: %w", path, err)
+		}
+		if !changed {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(root, path)), 0755); err != nil {
+			return written, fmt.Errorf("creating directory for %s: %w", path, err)
+		}
+		if err := os.WriteFile(filepath.Join(root, path), []byte(updated), 0644); err != nil {
+			return written, fmt.Errorf("writing %s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// planAgentInstructions lists the instruction files installing dirs would
+// write. Their content depends on the commands installed, so a file that
+// exists is always reported as overwritten.
+func planAgentInstructions(root string, dirs []string) []agents.PlannedFile {
+	var planned []agents.PlannedFile
+	for _, dir := range dirs {
+		path := agents.InstructionFile(dir)
+		if path == "" {
+			continue
+		}
+		change := agents.ChangeCreate
+		if _, err := os.Stat(filepath.Join(root, path)); err == nil {
+			change = agents.ChangeOverwrite
+		}
+		planned = append(planned, agents.PlannedFile{Path: path, Change: change})
+	}
+	return planned
+}
+
+// refreshAgentInstructions brings the instruction files of the installed
+// agents in line with their commands after an update. Failures are only
+// warnings, as the update itself succeeded.
+func refreshAgentInstructions(root string, op *report.Operation) {
+	written, err := writeAgentInstructions(root, agents.DetectInstalled(root))
+	if err != nil {
+		op.Warn("agent instructions", err.Error())
+		return
+	}
+	if len(written) == 0 {
+		return
+	}
+	fmt.Printf("Refreshed %s\n", strings.Join(written, ", "))
+	op.OK("agent instructions", strings.Join(written, ", "))
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/internal/version"
+	"github.com/spec-maestro/maestro-cli/pkg/bundle"
+	"github.com/spec-maestro/maestro-cli/pkg/embedded"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Work with offline asset bundles",
+	Long:  "Commands for asset bundles: archives of maestro's assets with a " + bundle.ManifestName + " manifest and " + bundle.ChecksumsName + ", used to install on machines without network access.",
+}
+
+var bundleCreateCmd = &cobra.Command{
+	Use:   "create [archive]",
+	Short: "Write the assets built into this maestro to a bundle",
+	Long:  "Writes the assets compiled into this maestro, the .maestro/ assets and every agent directory, to a .tar.gz bundle with a " + bundle.ManifestName + " manifest and " + bundle.ChecksumsName + ". The manifest records this maestro's version as the bundle version and as its min_cli_version. The archive defaults to maestro-assets-<version>.tar.gz in the current directory, and an existing file is not overwritten. Copy the bundle to an offline machine and install from it with 'maestro init --bundle'.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runBundleCreate,
+}
+
+var bundleVerifyCmd = &cobra.Command{
+	Use:   "verify <archive>",
+	Short: "Check a bundle's manifest, checksums, and CLI compatibility",
+	Long:  "Reads a bundle archive (.tar.gz, .tgz, or .zip) without extracting it and checks that " + bundle.ManifestName + " is present and valid, that every file matches " + bundle.ChecksumsName + " with nothing missing or unlisted, and that this maestro is at least the bundle's min_cli_version. Run it after copying a bundle to an offline machine; 'maestro init --bundle' runs the same checks before installing from it.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runBundleVerify,
+}
+
+func init() {
+	rootCmd.AddCommand(bundleCmd)
+	bundleCmd.AddCommand(bundleCreateCmd)
+	bundleCmd.AddCommand(bundleVerifyCmd)
+}
+
+func runBundleCreate(cmd *cobra.Command, args []string) error {
+	name := "maestro-assets-" + version.Version + ".tar.gz"
+	if len(args) == 1 {
+		name = args[0]
+	}
+	path := userPath(cmd, name)
+
+	files, err := embedded.Files()
+	if err != nil {
+		return err
+	}
+	manifest := bundle.Manifest{Version: version.Version}
+	if version.Version != "dev" {
+		manifest.MinCLIVersion = version.Version
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if os.IsExist(err) {
+		return fmt.Errorf("%s already exists; remove it or name another archive", name)
+	}
+	if err != nil {
+		return err
+	}
+	err = bundle.Create(f, files, manifest, time.Now())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("writing bundle %s: %w", name, err)
+	}
+	fmt.Printf("%s Bundle %s written to %s: %d file(s)\n", glyph.OK(), manifest.Version, pathfmt.Path(name), len(files))
+	return nil
+}
+
+func runBundleVerify(cmd *cobra.Command, args []string) error {
+	result, err := bundle.Verify(args[0], version.Version)
+	if err != nil {
+		return err
+	}
+
+	for _, problem := range result.Problems {
+		fmt.Printf("%s %s\n", glyph.Fail(), problem)
+	}
+	if len(result.Problems) > 0 {
+		fmt.Printf("\n%s is not a valid bundle: %d problem(s) found.\n", args[0], len(result.Problems))
+		return fmt.Errorf("bundle verification failed")
+	}
+
+	fmt.Printf("%s Bundle %s verified: %d file(s) match %s", glyph.OK(), result.Manifest.Version, result.Files, bundle.ChecksumsName)
+	if result.Manifest.MinCLIVersion != "" {
+		fmt.Printf(", requires maestro %s or newer", result.Manifest.MinCLIVersion)
+	}
+	fmt.Println()
+	return nil
+}
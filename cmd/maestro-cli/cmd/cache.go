@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/internal/version"
+	"github.com/spec-maestro/maestro-cli/pkg/assets"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/report"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and maintain the local asset cache",
+	Long:  "Commands for the asset cache in ~/.cache/maestro (maestro-data/cache next to the binary in portable mode) used by 'maestro update'.",
+}
+
+var cacheVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Re-hash cached assets and quarantine corrupt entries",
+	Long:  "Re-hashes every cached asset against the checksum recorded at download time. Entries that no longer match are moved to the cache's quarantine/ directory so they are never extracted into a project.",
+	Args:  cobra.NoArgs,
+	RunE:  runCacheVerify,
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheVerifyCmd)
+}
+
+func runCacheVerify(cmd *cobra.Command, args []string) error {
+	cache, err := assets.NewCacheManager()
+	if err != nil {
+		return fmt.Errorf("initializing cache: %w", err)
+	}
+
+	report, err := cache.Verify()
+	if err != nil {
+		return fmt.Errorf("verifying cache: %w", err)
+	}
+
+	for i, name := range report.Corrupt {
+		fmt.Printf("%s %-30s checksum mismatch, quarantined at %s\n", glyph.Fail(), name, report.Quarantined[i])
+	}
+	for _, name := range report.Unrecorded {
+		fmt.Printf("%s %-30s no recorded checksum (skipped)\n", glyph.Warn(), name)
+	}
+
+	if len(report.Corrupt) > 0 {
+		fmt.Printf("\n%d of %d cached asset(s) were corrupt and have been quarantined.\n", len(report.Corrupt), report.Checked)
+		return fmt.Errorf("cache verification found corrupt entries")
+	}
+
+	fmt.Printf("%s Verified %d cached asset(s) — cache is healthy\n", glyph.OK(), report.Checked)
+	return nil
+}
+
+// evictStaleCache removes the cached bundles of releases older than this
+// CLI the first time a new version uses the cache, unless the
+// cache.evict_stale setting is false. Failing to evict is only a warning.
+func evictStaleCache(root string, cache *assets.CacheManager, op *report.Operation) {
+	value, _ := projectResolver(root).String("cache.evict_stale", "true")
+	if enabled, err := strconv.ParseBool(value); err == nil && !enabled {
+		return
+	}
+	evicted, err := cache.EvictStale(version.Version)
+	if err != nil {
+		op.Warning("evicting stale cache entries: %v", err)
+		return
+	}
+	if len(evicted) > 0 {
+		fmt.Printf("Removed %d cached bundle(s) of releases older than %s\n", len(evicted), version.Version)
+	}
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/prereq"
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check <stage> [feature]",
+	Short: "Check that a feature is ready for a pipeline stage",
+	Long: `Checks that the stages before <stage> are complete for a feature, as
+.maestro/scripts/check-prerequisites.sh does, without bash or python3. It
+prints the same JSON: {"ok":true}, or {"ok":false,"error":"...","suggestion":"..."}
+and exits with status 1.
+
+Stages: clarify (needs the spec), research (also the state file), plan (the
+spec, and when the state marks research ready, every research artifact),
+tasks (plan.md), and implement, review, and pm-validate (bd on PATH).
+
+[feature] is the feature directory, as the script takes it, its name under
+.maestro/specs/ (e.g. 003-user-auth), or its number (e.g. 003). Without it,
+the feature is taken from the current git branch, such as
+spec/003-user-auth or feat/003-user-auth.
+
+State files are read from the main worktree when run in a git worktree, or
+from MAESTRO_MAIN_REPO when it is set.`,
+	Args:      cobra.RangeArgs(1, 2),
+	ValidArgs: prereq.Stages,
+	RunE:      runCheck,
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+	addProjectPathFlag(checkCmd, true)
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	root := workDir(cmd)
+	featureDir := ""
+	if len(args) == 2 {
+		featureDir = checkFeatureDir(cmd, args[1])
+	} else if id := branchFeature(root); id != "" {
+		featureDir = filepath.Join(".maestro", "specs", id)
+	}
+
+	// The JSON is the command's whole output
+	cmd.SilenceErrors, cmd.SilenceUsage = true, true
+	if featureDir == "" {
+		fmt.Fprintln(os.Stderr, prereq.Usage().JSON())
+		return &exitError{code: 1}
+	}
+	result := prereq.Check(root, args[0], featureDir, checkBase(cmd))
+	fmt.Println(result.JSON())
+	if !result.OK {
+		return &exitError{code: 1}
+	}
+	return nil
+}
+
+// checkFeatureDir resolves the feature argument: a directory, or a feature
+// name or number under .maestro/specs/. Anything else is returned as
+// given, so the check reports its spec.md as not found. A relative
+// directory is made relative to the project root, which is where it was
+// given from unless the project was found above it, so messages name it as
+// the script does.
+func checkFeatureDir(cmd *cobra.Command, arg string) string {
+	root := workDir(cmd)
+	dir := userPath(cmd, arg)
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		if filepath.IsAbs(arg) {
+			return arg
+		}
+		if rel, err := filepath.Rel(root, dir); err == nil {
+			return rel
+		}
+		return dir
+	}
+	if id, err := resolveFeatureID(filepath.Join(root, ".maestro", "specs"), arg); err == nil {
+		return filepath.Join(".maestro", "specs", id)
+	}
+	return arg
+}
+
+// branchPrefixes are stripped from a branch name to get the feature ID, as
+// worktree-detect.sh does, and spec/, which /maestro.specify creates.
+var branchPrefixes = []string{"spec/", "feature/", "feat/", "bugfix/", "fix/", "hotfix/", "release/"}
+
+// branchFeature returns the feature the git branch checked out at root is
+// for, or "" when the branch matches no feature directory.
+func branchFeature(root string) string {
+	branch, err := gitOutput(root, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return ""
+	}
+	for _, prefix := range branchPrefixes {
+		if strings.HasPrefix(branch, prefix) {
+			branch = strings.TrimPrefix(branch, prefix)
+			break
+		}
+	}
+	if info, err := os.Stat(filepath.Join(root, ".maestro", "specs", branch)); err == nil && info.IsDir() {
+		return branch
+	}
+	return ""
+}
+
+// checkBase is where state files are read from: MAESTRO_MAIN_REPO when set,
+// or the main worktree when the project is at the root of a git worktree,
+// as worktree-detect.sh resolves it. "." is the project root.
+func checkBase(cmd *cobra.Command) string {
+	if base := os.Getenv("MAESTRO_MAIN_REPO"); base != "" {
+		return userPath(cmd, base)
+	}
+	root := workDir(cmd)
+	top, err := gitOutput(root, "rev-parse", "--show-toplevel")
+	if err != nil || !sameDir(top, root) {
+		return "."
+	}
+	list, err := gitOutput(root, "worktree", "list", "--porcelain")
+	if err != nil {
+		return "."
+	}
+	for _, line := range strings.Split(list, "\n") {
+		if main := strings.TrimPrefix(line, "worktree "); main != line {
+			return main
+		}
+	}
+	return "."
+}
+
+func sameDir(a, b string) bool {
+	ai, errA := os.Stat(a)
+	bi, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(ai, bi)
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/agents"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+)
+
+var commandsCmd = &cobra.Command{
+	Use:   "commands",
+	Short: "Manage the maestro commands of the project",
+}
+
+var commandsNewCmd = &cobra.Command{
+	Use:   "new <name>",
+	Short: "Scaffold a custom maestro command in .maestro/commands/ and each installed agent directory",
+	Long: `Creates maestro.<name>.md in .maestro/commands/ and in the commands/
+directory of each installed agent directory, from a template with
+frontmatter, the argument hint, and a step that runs each --script. In
+.codex/, the command's skill is generated too. Edit the generated steps to
+describe the new stage of your workflow.
+
+List the command in .maestro/.maestroignore so that update, which can
+replace or back up whole agent directories, always leaves it alone.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCommandsNew,
+}
+
+var (
+	commandsNewDescription  string
+	commandsNewArgumentHint string
+	commandsNewScripts      []string
+	commandsNewForce        bool
+	commandsNewDryRun       bool
+)
+
+func init() {
+	rootCmd.AddCommand(commandsCmd)
+	commandsCmd.AddCommand(commandsNewCmd)
+	addProjectPathFlag(commandsNewCmd, true)
+	commandsNewCmd.Flags().StringVar(&commandsNewDescription, "description", "", "What the command does, for the frontmatter (default: a placeholder to edit)")
+	commandsNewCmd.Flags().StringVar(&commandsNewArgumentHint, "argument-hint", "", "The arguments the command takes, e.g. \"<feature-id> [--force]\"")
+	commandsNewCmd.Flags().StringSliceVar(&commandsNewScripts, "script", nil, "A script in .maestro/scripts/ the command runs (repeatable)")
+	commandsNewCmd.Flags().BoolVar(&commandsNewForce, "force", false, "Overwrite a command file that already exists")
+	commandsNewCmd.Flags().BoolVar(&commandsNewDryRun, "dry-run", false, "List the files that would be created without writing them")
+}
+
+// commandName matches the name of a custom command, without the maestro.
+// prefix: lowercase words joined by dashes or dots, e.g. review or
+// release.notes.
+var commandName = regexp.MustCompile(`^[a-z0-9]+([.-][a-z0-9]+)*$`)
+
+// commandTemplate is the prompt file commands new generates.
+var commandTemplate = template.Must(template.New("command").Funcs(template.FuncMap{
+	"inc": func(n int) int { return n + 1 },
+}).Parse(`---
+description: >
+  {{.Description}}
+{{- if .ArgumentHint}}
+argument-hint: {{.ArgumentHint}}
+{{- end}}
+---
+
+# {{.Command}}
+
+{{.Description}}
+
+## Step 1: Prerequisites Check
+
+Verify the project is initialized:
+
+1. Confirm ` + "`.maestro/`" + ` directory exists
+2. If not initialized, tell the user to run ` + "`/maestro.init`" + ` and stop
+{{- $step := 2}}
+{{- if .ArgumentHint}}
+
+## Step {{$step}}: Parse Arguments
+{{- $step = inc $step}}
+
+Extract the arguments from ` + "`$ARGUMENTS`" + `: ` + "`{{.ArgumentHint}}`" + `
+
+If a required argument is missing, ask the user for it and stop.
+{{- end}}
+{{- range .Scripts}}
+
+## Step {{$step}}: Run {{.}}
+{{- $step = inc $step}}
+
+` + "```bash" + `
+bash .maestro/scripts/{{.}} $ARGUMENTS
+` + "```" + `
+
+If the script fails, show its output and stop.
+{{- end}}
+
+## Step {{$step}}: Report
+
+Summarize what was done and suggest the next maestro command to run.
+`))
+
+// commandTemplateData fills commandTemplate.
+type commandTemplateData struct {
+	Command      string
+	Description  string
+	ArgumentHint string
+	Scripts      []string
+}
+
+func runCommandsNew(cmd *cobra.Command, args []string) error {
+	root := workDir(cmd)
+	if _, err := os.Stat(filepath.Join(root, ".maestro")); os.IsNotExist(err) {
+		return fmt.Errorf("not initialized — run 'maestro init' first")
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(args[0], "maestro."), ".md")
+	if !commandName.MatchString(name) {
+		return fmt.Errorf("invalid command name %q: use lowercase letters and digits, with words joined by - or .", args[0])
+	}
+	scripts, err := resolveCommandScripts(root, commandsNewScripts)
+	if err != nil {
+		return err
+	}
+
+	files, err := scaffoldCommand(commandTemplateData{
+		Command:      "maestro." + name,
+		Description:  commandsNewDescription,
+		ArgumentHint: commandsNewArgumentHint,
+		Scripts:      scripts,
+	}, agents.DetectInstalled(root))
+	if err != nil {
+		return err
+	}
+
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	if !commandsNewForce {
+		for _, p := range paths {
+			if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(p))); err == nil {
+				return fmt.Errorf("%s already exists (use --force to overwrite it)", p)
+			}
+		}
+	}
+	if commandsNewDryRun {
+		fmt.Println("Would create:")
+		for _, p := range paths {
+			fmt.Printf("  %s\n", pathfmt.Path(p))
+		}
+		return nil
+	}
+
+	for _, p := range paths {
+		path := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, files[p], 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", p, err)
+		}
+		fmt.Printf("%s Created %s\n", glyph.OK(), pathfmt.Path(p))
+	}
+
+	installed := agents.DetectInstalled(root)
+	if _, err := recordAgentCommands(root, installed); err != nil {
+		fmt.Printf("%s Could not record the agent commands: %v\n", glyph.Warn(), err)
+	}
+	if written, err := writeAgentInstructions(root, installed); err != nil {
+		fmt.Printf("%s Could not refresh the agent instructions: %v\n", glyph.Warn(), err)
+	} else if len(written) > 0 {
+		fmt.Printf("Refreshed %s\n", strings.Join(written, ", "))
+	}
+
+	fmt.Printf("\nEdit the generated steps to describe what maestro.%s does; each agent directory has its own copy.\n", name)
+	return nil
+}
+
+// resolveCommandScripts checks that each script exists in .maestro/scripts/,
+// adding .sh when the name has no extension, and returns their file names.
+func resolveCommandScripts(root string, scripts []string) ([]string, error) {
+	resolved := make([]string, 0, len(scripts))
+	for _, script := range scripts {
+		name := path.Base(filepath.ToSlash(script))
+		if path.Ext(name) == "" {
+			name += ".sh"
+		}
+		if _, err := os.Stat(filepath.Join(root, ".maestro", "scripts", name)); err != nil {
+			return nil, fmt.Errorf("no script %s in .maestro/scripts/", name)
+		}
+		resolved = append(resolved, name)
+	}
+	return resolved, nil
+}
+
+// scaffoldCommand renders the command file and returns it keyed by
+// each path it goes to, relative to the project root: .maestro/commands/
+// and the commands/ directory of each agent directory in dirs, plus the
+// generated skill in .codex/.
+func scaffoldCommand(data commandTemplateData, dirs []string) (map[string][]byte, error) {
+	if data.Description == "" {
+		data.Description = "TODO: describe what " + data.Command + " does."
+	}
+	var buf bytes.Buffer
+	if err := commandTemplate.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("rendering the command template: %w", err)
+	}
+
+	rel := "commands/" + data.Command + ".md"
+	files := map[string][]byte{".maestro/" + rel: buf.Bytes()}
+	for _, dir := range dirs {
+		content := map[string][]byte{rel: buf.Bytes()}
+		if dir == ".codex" {
+			content = agents.AddCodexCommandSkills(content)
+		}
+		for p, data := range content {
+			files[path.Join(dir, p)] = data
+		}
+	}
+	return files, nil
+}
@@ -1,10 +1,43 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/internal/version"
+	"github.com/spec-maestro/maestro-cli/pkg/agentreport"
+	"github.com/spec-maestro/maestro-cli/pkg/agents"
+	"github.com/spec-maestro/maestro-cli/pkg/agentsmd"
+	"github.com/spec-maestro/maestro-cli/pkg/config"
+	"github.com/spec-maestro/maestro-cli/pkg/deprecation"
+	"github.com/spec-maestro/maestro-cli/pkg/doctor"
+	"github.com/spec-maestro/maestro-cli/pkg/envreport"
+	ghclient "github.com/spec-maestro/maestro-cli/pkg/github"
+	"github.com/spec-maestro/maestro-cli/pkg/guard"
+	"github.com/spec-maestro/maestro-cli/pkg/httpheaders"
+	"github.com/spec-maestro/maestro-cli/pkg/ignore"
+	"github.com/spec-maestro/maestro-cli/pkg/merge"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+	"github.com/spec-maestro/maestro-cli/pkg/report"
+	"github.com/spec-maestro/maestro-cli/pkg/scriptcompat"
+	"github.com/spec-maestro/maestro-cli/pkg/snapshot"
+	"github.com/spec-maestro/maestro-cli/pkg/state"
+	"github.com/spec-maestro/maestro-cli/pkg/tui"
 )
 
 func readRepoFileForCommandTests(t *testing.T, relativePath string) string {
@@ -111,6 +144,254 @@ func TestRemoveWithForce(t *testing.T) {
 	}
 }
 
+// TestRemoveAllWithForce tests remove --all also removes the agent
+// directories, AGENTS.md, and maestro's parts of files shared with the user.
+func TestRemoveAllWithForce(t *testing.T) {
+	dir := t.TempDir()
+	defer os.Chdir(chdir(t, dir))
+
+	os.MkdirAll(".maestro", 0755)
+	os.MkdirAll(filepath.Join(".claude", "commands"), 0755)
+	os.WriteFile(filepath.Join(".claude", "commands", "maestro.plan.md"), []byte("plan"), 0644)
+	os.WriteFile("CLAUDE.md", []byte("# House style\n\n"+agentsmd.Block("maestro instructions")), 0644)
+	os.WriteFile("AGENTS.md", []byte(agentsMDContent), 0644)
+	os.WriteFile(".gitignore", []byte("node_modules/\n"), 0644)
+	if _, err := ensureGitignore(".gitignore", gitignoreEntries()); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := removeItems(".", ".maestro", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := joinItemNames(selectRemoveItems(items, true, false)); got != ".maestro/, .claude/ and the maestro block in CLAUDE.md" {
+		t.Errorf("--agents selects %s", got)
+	}
+
+	removeForce, removeAll = true, true
+	defer func() { removeForce, removeAll = false, false }()
+	if err := runRemove(removeCmd, nil); err != nil {
+		t.Fatalf("remove --all error: %v", err)
+	}
+	for _, path := range []string{".maestro", ".claude", "AGENTS.md"} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed", path)
+		}
+	}
+	if data, _ := os.ReadFile("CLAUDE.md"); string(data) != "# House style\n" {
+		t.Errorf("CLAUDE.md = %q, want the user's text kept", data)
+	}
+	if data, _ := os.ReadFile(".gitignore"); string(data) != "node_modules/\n" {
+		t.Errorf(".gitignore = %q, want the user's entries kept", data)
+	}
+}
+
+// TestRemoveAllKeepsUserAgentsMD tests remove --all leaves an AGENTS.md
+// maestro did not write, and cuts only the instructions appended to one.
+func TestRemoveAllKeepsUserAgentsMD(t *testing.T) {
+	dir := t.TempDir()
+	defer os.Chdir(chdir(t, dir))
+	removeForce, removeAll = true, true
+	defer func() { removeForce, removeAll = false, false }()
+
+	os.MkdirAll(".maestro", 0755)
+	os.WriteFile("AGENTS.md", []byte("# Our agents\n"), 0644)
+	if err := runRemove(removeCmd, nil); err != nil {
+		t.Fatalf("remove --all error: %v", err)
+	}
+	if data, _ := os.ReadFile("AGENTS.md"); string(data) != "# Our agents\n" {
+		t.Errorf("AGENTS.md = %q, want the user's file kept", data)
+	}
+
+	os.MkdirAll(".maestro", 0755)
+	appended, _, _ := agentsmd.Apply([]byte("# Our agents\n"), agentsMDContent, agentsmd.Append)
+	os.WriteFile("AGENTS.md", []byte(appended), 0644)
+	if err := runRemove(removeCmd, nil); err != nil {
+		t.Fatalf("remove --all error: %v", err)
+	}
+	if data, _ := os.ReadFile("AGENTS.md"); string(data) != "# Our agents\n" {
+		t.Errorf("AGENTS.md = %q, want only the appended instructions removed", data)
+	}
+}
+
+// TestRemoveKeepSpecsAndState tests remove --keep-specs --keep-state
+// removes the tooling in .maestro/ and keeps the authored specs and state.
+func TestRemoveKeepSpecsAndState(t *testing.T) {
+	dir := t.TempDir()
+	defer os.Chdir(chdir(t, dir))
+
+	for _, sub := range []string{"specs/001-auth", "state", "scripts", "templates"} {
+		os.MkdirAll(filepath.Join(".maestro", filepath.FromSlash(sub)), 0755)
+	}
+	os.WriteFile(filepath.Join(".maestro", "config.yaml"), []byte("cli_version: v0.1.0\n"), 0644)
+	os.WriteFile(filepath.Join(".maestro", "specs", "001-auth", "spec.md"), []byte("# Auth\n"), 0644)
+	os.WriteFile(filepath.Join(".maestro", "state", "001-auth.json"), []byte("{}"), 0644)
+
+	keep := []string{"specs", "state"}
+	targets, err := removalTargets(".", nil, ".maestro", keep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(".maestro", "config.yaml"), filepath.Join(".maestro", "scripts"), filepath.Join(".maestro", "templates")}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("removalTargets() = %v, want %v", targets, want)
+	}
+
+	removeForce, removeKeepSpecs, removeKeepState = true, true, true
+	defer func() { removeForce, removeKeepSpecs, removeKeepState = false, false, false }()
+	if err := runRemove(removeCmd, nil); err != nil {
+		t.Fatalf("remove --keep-specs --keep-state error: %v", err)
+	}
+	for _, path := range want {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed", path)
+		}
+	}
+	for _, path := range []string{"specs/001-auth/spec.md", "state/001-auth.json"} {
+		if _, err := os.Stat(filepath.Join(".maestro", filepath.FromSlash(path))); err != nil {
+			t.Errorf(".maestro/%s should be kept: %v", path, err)
+		}
+	}
+}
+
+// TestRemoveBackupArchiveAndRestore tests remove --backup-archive writes
+// a checksummed archive that restore brings back.
+func TestRemoveBackupArchiveAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	defer os.Chdir(chdir(t, dir))
+
+	os.MkdirAll(filepath.Join(".maestro", "specs", "001-auth"), 0755)
+	os.WriteFile(filepath.Join(".maestro", "config.yaml"), []byte("cli_version: v0.1.0\n"), 0644)
+	os.WriteFile(filepath.Join(".maestro", "specs", "001-auth", "spec.md"), []byte("# Auth\n"), 0644)
+
+	removeForce, removeBackupArchive = true, true
+	defer func() { removeForce, removeBackupArchive = false, false }()
+	if err := runRemove(removeCmd, nil); err != nil {
+		t.Fatalf("remove --backup-archive error: %v", err)
+	}
+	if _, err := os.Stat(".maestro"); !os.IsNotExist(err) {
+		t.Fatal(".maestro should be removed")
+	}
+	archives, _ := filepath.Glob(".maestro-backup-*.tar.gz")
+	if len(archives) != 1 {
+		t.Fatalf("want one backup archive, got %v", archives)
+	}
+	if _, err := os.Stat(archives[0] + ".sha256"); err != nil {
+		t.Errorf("the archive should have a checksum file: %v", err)
+	}
+
+	if err := runRestore(restoreCmd, archives); err != nil {
+		t.Fatalf("restore error: %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(".maestro", "specs", "001-auth", "spec.md")); string(got) != "# Auth\n" {
+		t.Errorf("restored spec.md = %q", got)
+	}
+	if err := runRestore(restoreCmd, archives); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("restoring over existing files should suggest --force, got %v", err)
+	}
+}
+
+// TestPromptRemoveItems tests the remove checklist starts from what the
+// flags select and returns what is picked.
+func TestPromptRemoveItems(t *testing.T) {
+	items := []removeItem{{Name: ".maestro/"}, {Name: ".claude/", Group: "agents"}, {Name: "AGENTS.md", Group: "all"}}
+	var out bytes.Buffer
+	picked, err := promptRemoveItems(strings.NewReader("\n"), &out, items, selectRemoveItems(items, false, false))
+	if err != nil || joinItemNames(picked) != ".maestro/" {
+		t.Errorf("Enter picked %v, %v", picked, err)
+	}
+	if !strings.Contains(out.String(), "[1] .maestro/ [selected]") {
+		t.Errorf("checklist should mark .maestro/ selected:\n%s", out.String())
+	}
+	picked, err = promptRemoveItems(strings.NewReader("1-3\n"), &out, items, nil)
+	if err != nil || len(picked) != 3 {
+		t.Errorf("1-3 picked %v, %v", picked, err)
+	}
+}
+
+// TestRemovePlanDoesNotRemove tests remove --plan reports without deleting.
+func TestRemovePlanDoesNotRemove(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	os.Chdir(dir)
+
+	os.MkdirAll(filepath.Join(".maestro", "state"), 0755)
+	os.WriteFile(filepath.Join(".maestro", "config.yaml"), []byte("cli_version: v0.1.0\n"), 0644)
+	os.WriteFile(filepath.Join(".maestro", "state", "001.json"), []byte("{}"), 0644)
+	os.MkdirAll(".maestro-backup-20250101-000000", 0755)
+
+	removePlan = true
+	removeOutput = "json"
+	defer func() { removePlan = false; removeOutput = "text" }()
+
+	if err := runRemove(removeCmd, nil); err != nil {
+		t.Fatalf("remove --plan error: %v", err)
+	}
+	if _, err := os.Stat(".maestro"); err != nil {
+		t.Error(".maestro/ should not be removed by --plan")
+	}
+
+	plan, err := buildRemovalPlan(".", []string{".maestro"}, false)
+	if err != nil {
+		t.Fatalf("buildRemovalPlan error: %v", err)
+	}
+	if len(plan.Paths) != 1 || plan.TotalFiles != 2 || plan.TotalBytes != int64(len("cli_version: v0.1.0\n")+2) {
+		t.Errorf("unexpected plan: %+v", plan)
+	}
+	if len(plan.ExistingBackups) != 1 {
+		t.Errorf("expected existing backup to be reported, got %v", plan.ExistingBackups)
+	}
+}
+
+// TestUninstallRemovesGlobalFiles tests uninstall removes the cache, the
+// config, and completion scripts maestro generated, and nothing else.
+func TestUninstallRemovesGlobalFiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	cache := filepath.Join(home, ".cache", "maestro")
+	fish := filepath.Join(home, ".config", "fish", "completions", "maestro.fish")
+	foreign := filepath.Join(home, ".zfunc", "_maestro")
+	os.MkdirAll(filepath.Join(cache, "v0.1.0"), 0755)
+	os.WriteFile(filepath.Join(cache, "v0.1.0", "asset"), []byte("data"), 0644)
+	os.MkdirAll(filepath.Dir(fish), 0755)
+	os.WriteFile(fish, []byte("complete -c maestro -f -a '(__maestro_perform_completion)'\n"), 0644)
+	os.MkdirAll(filepath.Dir(foreign), 0755)
+	os.WriteFile(foreign, []byte("#compdef maestro\n"), 0644)
+
+	plan, err := buildUninstallationPlan(false)
+	if err != nil {
+		t.Fatalf("buildUninstallationPlan error: %v", err)
+	}
+	var kinds []string
+	for _, item := range plan.Items {
+		kinds = append(kinds, item.Kind)
+	}
+	if strings.Join(kinds, ",") != "cache,completion" {
+		t.Errorf("planned kinds = %v, want cache and completion only", kinds)
+	}
+	if plan.TotalBytes != int64(len("data"))+plan.Items[1].Bytes {
+		t.Errorf("unexpected total: %+v", plan)
+	}
+
+	uninstallForce = true
+	defer func() { uninstallForce = false }()
+	if err := runUninstall(uninstallCmd, nil); err != nil {
+		t.Fatalf("uninstall error: %v", err)
+	}
+	for _, path := range []string{cache, fish} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed", path)
+		}
+	}
+	if _, err := os.Stat(foreign); err != nil {
+		t.Error("a completion file maestro did not generate should be kept")
+	}
+}
+
 // TestInitWithOpenCodeFlag tests that init --with-opencode sets the flag and creates .maestro/.
 // runInit downloads .maestro/ from GitHub, then fails at the required-starter-assets conflict
 // prompt because stdin is non-interactive (EOF). This is expected: .maestro/ is created by the
@@ -482,6 +763,61 @@ func TestUpdatePromptMissingAgentDirs(t *testing.T) {
 	}
 }
 
+// TestUpdateAgentConfigFailuresAreOptional tests that agent config failures
+// during update are recorded as optional steps with retry hints instead of
+// failing the update.
+func TestUpdateAgentConfigFailuresAreOptional(t *testing.T) {
+	dir := t.TempDir()
+	origDir := chdir(t, dir)
+	defer os.Chdir(origDir)
+
+	_ = os.MkdirAll(".maestro", 0755)
+	_ = os.MkdirAll(".claude", 0755)
+	_ = os.WriteFile(filepath.Join(".maestro", "config.yaml"), []byte("installed: [not, a, mapping\n"), 0644)
+
+	op := report.New("update")
+	updateAgentConfigs(".", nil, op)
+
+	counts := op.Counts()
+	if counts.OptionalFailed != 1 || counts.Failed != 1 {
+		t.Fatalf("expected one optional failure, got %+v: %+v", counts, op.Steps)
+	}
+	if step := op.Steps[0]; step.Name != "refresh .claude" || !step.Optional {
+		t.Errorf("unexpected step %+v", step)
+	}
+	if len(op.FollowUps) != 1 || !strings.Contains(op.FollowUps[0], "maestro init --with-claude") {
+		t.Errorf("expected a retry hint, got %v", op.FollowUps)
+	}
+}
+
+// TestLastUpdateAdvisory tests doctor's last update check warns once the
+// last update is older than 60 days.
+func TestLastUpdateAdvisory(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	cfg := &config.ProjectConfig{Installed: config.InstalledSection{
+		LastUpdate: &config.LastUpdate{At: now.AddDate(0, 0, -10), Command: "update", Source: "v1.2.0", Commit: "0123456789abcdef"},
+	}}
+	message, stale := describeLastUpdate(cfg, now)
+	if stale || message != "updated 2026-05-22 (10 days ago) by maestro update from v1.2.0 (0123456)" {
+		t.Errorf("describeLastUpdate() = %q, %v", message, stale)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	cfg.Installed.LastUpdate.At = now.AddDate(0, 0, -61)
+	_ = config.Save(cfg, path)
+	results := lastUpdateChecks(path, now)
+	if len(results) != 1 || results[0].OK || !results[0].Warn || !strings.Contains(results[0].Message, "61 days ago") {
+		t.Errorf("stale update should be a warning, got %+v", results)
+	}
+
+	var out bytes.Buffer
+	printLastUpdate(&out, cfg, now)
+	if !strings.Contains(out.String(), "Run 'maestro update'") {
+		t.Errorf("version should advise updating:\n%s", out.String())
+	}
+}
+
 // TestUpdateOnUninitializedProject tests update when .maestro/ doesn't exist.
 func TestUpdateOnUninitializedProject(t *testing.T) {
 	dir := t.TempDir()
@@ -571,99 +907,2161 @@ func TestDoctorFailsOnMissingRequiredStructure(t *testing.T) {
 	}
 }
 
-// TestUpdateNoRegressionExistingFlow tests update preserves existing behavior.
-func TestUpdateNoRegressionExistingFlow(t *testing.T) {
-	dir := t.TempDir()
-	orig, _ := os.Getwd()
-	defer os.Chdir(orig)
-	_ = os.Chdir(dir)
+// TestConfigChecks tests that doctor warns about unknown keys in
+// config.yaml and about a cli_version that differs from the running maestro.
+func TestConfigChecks(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	check := func(content, cliVersion string) []doctor.Result {
+		t.Helper()
+		os.WriteFile(path, []byte(content), 0644)
+		return configChecks(path, cliVersion)
+	}
 
-	// Set up minimal .maestro/ structure
-	_ = os.MkdirAll(filepath.Join(".maestro", "scripts"), 0755)
-	_ = os.MkdirAll(filepath.Join(".maestro", "specs"), 0755)
-	_ = os.MkdirAll(filepath.Join(".maestro", "state"), 0755)
-	_ = os.WriteFile(filepath.Join(".maestro", "config.yaml"), []byte("cli_version: v0.1.0\n"), 0644)
+	results := check("cli_version: v1.2.0\nproject:\n  name: demo\n", "v1.2.0")
+	if len(results) != 2 || !results[0].OK || !results[1].OK {
+		t.Fatalf("a valid config at the running version should pass, got %+v", results)
+	}
 
-	// Unset GITHUB_TOKEN to ensure fetch fails gracefully
-	origToken := os.Getenv("GITHUB_TOKEN")
-	_ = os.Unsetenv("GITHUB_TOKEN")
-	defer func() {
-		if origToken != "" {
-			_ = os.Setenv("GITHUB_TOKEN", origToken)
+	results = check("cli_version: v1.1.0\nprojet:\n  name: demo\n", "v1.2.0")
+	if r := results[0]; r.OK || !r.Warn || !strings.Contains(r.Message, "projet (line 2)") {
+		t.Errorf("an unknown key should be a warning naming it, got %+v", r)
+	}
+	if r := results[1]; r.OK || !r.Warn || len(r.Commands) != 1 || r.Commands[0] != "maestro update --yes --version v1.2.0" {
+		t.Errorf("an older project should suggest maestro update, got %+v", r)
+	}
+
+	results = check("cli_version: v2.0.0\n", "v1.2.0")
+	if r := results[1]; r.OK || !strings.Contains(r.Message, "newer than maestro v1.2.0") {
+		t.Errorf("a newer project should ask for a newer maestro, got %+v", r)
+	}
+
+	if results := check("cli_version: v2.0.0\n", "dev"); len(results) != 1 {
+		t.Errorf("a dev build should not compare versions, got %+v", results)
+	}
+
+	results = check("initialized_at: yesterday\n", "v1.2.0")
+	if len(results) != 1 || results[0].OK || results[0].Warn {
+		t.Errorf("an unreadable value should fail the check, got %+v", results)
+	}
+}
+
+// TestScriptChecks tests that doctor reports scripts without a shebang or
+// the exec bit, and scripts that differ from the install manifest.
+func TestScriptChecks(t *testing.T) {
+	dir := t.TempDir()
+	defer os.Chdir(chdir(t, dir))
+	write := func(name, content string, mode os.FileMode) {
+		p := filepath.Join(".maestro", "scripts", filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		os.WriteFile(p, []byte(content), mode)
+		os.Chmod(p, mode)
+	}
+	write("ok.sh", "#!/usr/bin/env bash\necho ok\n", 0755)
+	write("no-exec.sh", "#!/usr/bin/env bash\necho hi\n", 0644)
+	write("lib.sh", "#!/usr/bin/env bash\n# Source this file: source .maestro/scripts/lib.sh\n", 0644)
+	write("no-shebang.sh", "echo hi\n", 0755)
+	write("test/fixtures/plan.md", "# Plan\n", 0644)
+	sums, _ := config.ChecksumFiles(".", []string{".maestro/scripts"})
+	config.Save(&config.ProjectConfig{Installed: config.InstalledSection{Files: sums}}, ".maestro/config.yaml")
+	write("ok.sh", "#!/usr/bin/env bash\necho edited\n", 0755)
+
+	byName := map[string]doctor.Result{}
+	for _, r := range scriptChecks(".maestro") {
+		byName[r.Name] = r
+	}
+	if r := byName["script shebangs"]; r.OK || r.Message != "1 without a shebang: .maestro/scripts/no-shebang.sh" {
+		t.Errorf("script shebangs: got ok=%v %q", r.OK, r.Message)
+	}
+	if runtime.GOOS != "windows" {
+		r := byName["script permissions"]
+		if r.OK || !r.Warn || r.Message != "1 not executable: .maestro/scripts/no-exec.sh" {
+			t.Errorf("script permissions: got ok=%v %q", r.OK, r.Message)
 		}
-	}()
+		if len(r.Commands) != 1 || r.Commands[0] != "chmod +x .maestro/scripts/no-exec.sh" {
+			t.Errorf("script permissions fix = %v", r.Commands)
+		}
+	}
+	if r := byName["script checksums"]; r.OK || !r.Warn || r.Message != "1 modified locally: .maestro/scripts/ok.sh" {
+		t.Errorf("script checksums: got ok=%v %q", r.OK, r.Message)
+	}
 
-	// Run update - should attempt basic update flow
-	err := runUpdate(updateCmd, nil)
-	// Update will fail on GitHub fetch - this is expected behavior
-	if err == nil {
-		t.Log("update succeeded (GitHub fetch may have succeeded unexpectedly)")
+	// Without a manifest the checksums are not checked
+	os.Remove(filepath.Join(".maestro", "config.yaml"))
+	if _, ok := scriptChecksumCheck(filepath.Join(".maestro", "config.yaml"), filepath.Join(".maestro", "scripts")); ok {
+		t.Error("script checksums should be skipped without an install manifest")
 	}
+}
 
-	// Verify .maestro/ structure preserved
-	if _, err := os.Stat(".maestro"); os.IsNotExist(err) {
-		t.Error(".maestro/ should be preserved after update attempt")
+// TestStateChecks tests that doctor reports corrupt state files with the
+// position of the error, state files missing required fields, and paths
+// to files that no longer exist.
+func TestStateChecks(t *testing.T) {
+	dir := t.TempDir()
+	defer os.Chdir(chdir(t, dir))
+	write := func(name, content string) {
+		p := filepath.FromSlash(name)
+		os.MkdirAll(filepath.Dir(p), 0755)
+		os.WriteFile(p, []byte(content), 0644)
+	}
+	write(".maestro/specs/001-ok/spec.md", "# Spec\n")
+	write(".maestro/state/001-ok.json", `{"feature_id": "001-ok", "stage": "specify", "spec_path": ".maestro/specs/001-ok/spec.md"}`)
+	write(".maestro/state/002-gone.json", `{"feature_id": "002-gone", "stage": "plan", "plan_path": ".maestro/specs/002-gone/plan.md"}`)
+	write(".maestro/state/003-corrupt.json", "{\n  \"feature_id\": \"003\",\n  \"stage\": \"plan\"\n}\n}\n")
+	write(".maestro/state/004-partial.json", `{"feature_id": "004-partial"}`)
+	write(".maestro/state/research/notes.json", `not state`)
+
+	byName := map[string]doctor.Result{}
+	for _, r := range stateChecks(".maestro") {
+		byName[r.Name] = r
+	}
+	r := byName["state files"]
+	want := "2 of 4 invalid: .maestro/state/003-corrupt.json (corrupt JSON at line 5, column 1: invalid character '}' after top-level value), .maestro/state/004-partial.json (no stage)"
+	if r.OK || r.Message != want {
+		t.Errorf("state files: got ok=%v %q, want %q", r.OK, r.Message, want)
+	}
+	r = byName["state references"]
+	if r.OK || !r.Warn || r.Message != "1 missing: .maestro/state/002-gone.json (plan_path .maestro/specs/002-gone/plan.md)" {
+		t.Errorf("state references: got ok=%v %q", r.OK, r.Message)
+	}
+
+	// No state files, nothing to check
+	os.RemoveAll(filepath.Join(".maestro", "state"))
+	if results := stateChecks(".maestro"); len(results) != 0 {
+		t.Errorf("stateChecks without state files = %v", results)
 	}
 }
 
-// TestDoctorNoRegressionExistingFlow tests doctor preserves existing behavior.
-func TestDoctorNoRegressionExistingFlow(t *testing.T) {
+// TestFeatureChecks tests doctor --feature: the artifacts present, a state
+// file that contradicts them, and readiness for the next stage.
+func TestFeatureChecks(t *testing.T) {
 	dir := t.TempDir()
-	orig, _ := os.Getwd()
-	defer os.Chdir(orig)
-	_ = os.Chdir(dir)
+	defer os.Chdir(chdir(t, dir))
+	write := func(name, content string) {
+		p := filepath.FromSlash(name)
+		os.MkdirAll(filepath.Dir(p), 0755)
+		os.WriteFile(p, []byte(content), 0644)
+	}
+	byName := func(id string) map[string]doctor.Result {
+		results := map[string]doctor.Result{}
+		for _, r := range featureChecks(".maestro", id) {
+			results[r.Name] = r
+		}
+		return results
+	}
 
-	// Set up minimal valid .maestro/ structure
-	_ = os.MkdirAll(filepath.Join(".maestro", "scripts"), 0755)
-	_ = os.MkdirAll(filepath.Join(".maestro", "specs"), 0755)
-	_ = os.MkdirAll(filepath.Join(".maestro", "state"), 0755)
-	_ = os.WriteFile(filepath.Join(".maestro", "config.yaml"), []byte("cli_version: v0.1.0\n"), 0644)
+	write(".maestro/specs/001-ready/spec.md", "# Ready\n")
+	write(".maestro/specs/001-ready/plan.md", "# Plan\n")
+	write(".maestro/specs/001-ready/research/synthesis.md", "notes\n")
+	write(".maestro/state/001-ready.json", `{"feature_id": "001-ready", "stage": "plan"}`)
+	got := byName("001-ready")
+	if r := got["artifacts"]; r.Message != "present: research (1 file(s)), plan.md; not yet: tasks" {
+		t.Errorf("artifacts: got %q", r.Message)
+	}
+	for name, want := range map[string]string{
+		"spec":       ".maestro/specs/001-ready/spec.md found",
+		"state":      "stage plan, consistent with the artifacts",
+		"next stage": "ready: run /maestro.tasks",
+	} {
+		if r := got[name]; !r.OK || r.Message != want {
+			t.Errorf("%s: got ok=%v %q, want %q", name, r.OK, r.Message, want)
+		}
+	}
 
-	err := runDoctor(doctorCmd, nil)
-	// Should pass with valid structure
-	if err != nil {
-		t.Errorf("doctor should pass on valid project, got: %v", err)
+	write(".maestro/specs/002-ahead/spec.md", "placeholder\n")
+	write(".maestro/state/002-ahead.json", `{"feature_id": "002-ahead", "stage": "tasks", "spec_path": ".maestro/specs/002-ahead/spec.md"}`)
+	got = byName("002-ahead")
+	if r := got["spec"]; r.OK || r.Message != ".maestro/specs/002-ahead/spec.md has no # heading; it may be a placeholder" {
+		t.Errorf("spec: got ok=%v %q", r.OK, r.Message)
+	}
+	if r := got["state"]; r.OK || r.Message != "stage tasks; stage is tasks, but plan.md is missing" {
+		t.Errorf("state: got ok=%v %q", r.OK, r.Message)
+	}
+	if r := got["next stage"]; r.OK || !strings.HasPrefix(r.Message, "not ready for /maestro.implement: ") {
+		t.Errorf("next stage: got ok=%v %q", r.OK, r.Message)
+	}
+
+	write(".maestro/specs/003-new/spec.md", "# New\n")
+	got = byName("003-new")
+	if r := got["state"]; r.OK || !r.Warn {
+		t.Errorf("a missing state file should be a warning, got %+v", r)
 	}
 }
 
-func TestPlanCommandContractIncludesResearchReadinessAndBypassPhrase(t *testing.T) {
-	planCommand := readRepoFileForCommandTests(t, ".maestro/commands/maestro.plan.md")
+// TestAgentCompatChecks tests that doctor flags agent commands that call a
+// script the installed scripts no longer have, or a flag they dropped.
+func TestAgentCompatChecks(t *testing.T) {
+	dir := t.TempDir()
+	defer os.Chdir(chdir(t, dir))
+	write := func(name, content string) {
+		p := filepath.FromSlash(name)
+		os.MkdirAll(filepath.Dir(p), 0755)
+		os.WriteFile(p, []byte(content), 0755)
+	}
+	write(".maestro/scripts/worktree-create.sh", "#!/bin/sh\ncase \"$1\" in\n  --repo) ;;\nesac\n")
+	write(".claude/commands/maestro.implement.md", "bash .maestro/scripts/worktree-create.sh --repo x\n")
+	write(".opencode/commands/maestro.implement.md", "bash .maestro/scripts/worktree-create.sh --repo x --base y\n")
+	write(".codex/skills/maestro-implement/SKILL.md", "bash .maestro/scripts/create-worktree.sh --repo x\n")
+
+	byName := map[string]doctor.Result{}
+	for _, r := range agentCompatChecks(".", ".maestro") {
+		byName[r.Name] = r
+	}
+	if r := byName[".claude/ script calls"]; !r.OK {
+		t.Errorf(".claude: got ok=%v %q", r.OK, r.Message)
+	}
+	r := byName[".opencode/ script calls"]
+	if r.OK || !r.Warn || r.Message != "1 incompatible call(s): .opencode/commands/maestro.implement.md:1 calls worktree-create.sh with --base, which it does not accept" {
+		t.Errorf(".opencode: got ok=%v warn=%v %q", r.OK, r.Warn, r.Message)
+	}
+	r = byName[".codex/ script calls"]
+	if r.OK || r.Warn || r.Message != "1 incompatible call(s): .codex/skills/maestro-implement/SKILL.md:1 calls create-worktree.sh, which does not exist" {
+		t.Errorf(".codex: got ok=%v warn=%v %q", r.OK, r.Warn, r.Message)
+	}
+	if len(r.Commands) != 1 || r.Commands[0] != "maestro update --agents-only --yes" {
+		t.Errorf(".codex fix = %v", r.Commands)
+	}
 
-	requiredSnippets := []string{
-		"Consider research ready only when all are true",
-		"I acknowledge proceeding without complete research",
-		"synthesis minimum quality signals are present",
-		"run `/maestro.research {feature_id}`",
+	// A matrix recorded at install is used over the scripts' own parsing
+	config.Save(&config.ProjectConfig{Installed: config.InstalledSection{Scripts: scriptcompat.Matrix{"worktree-create.sh": {"--base", "--repo"}}}}, ".maestro/config.yaml")
+	for _, r := range agentCompatChecks(".", ".maestro") {
+		if r.Name == ".opencode/ script calls" && !r.OK {
+			t.Errorf(".opencode with a recorded matrix: got %q", r.Message)
+		}
 	}
+}
 
-	for _, snippet := range requiredSnippets {
-		if !strings.Contains(planCommand, snippet) {
-			t.Fatalf("plan command contract missing snippet %q", snippet)
+// TestNetworkResults tests how doctor --network reports the token source,
+// the API quota, and hosts that do not answer.
+func TestNetworkResults(t *testing.T) {
+	t.Parallel()
+	reset := time.Date(2026, 1, 2, 15, 4, 0, 0, time.UTC)
+	byName := func(s networkStatus) map[string]doctor.Result {
+		results := map[string]doctor.Result{}
+		for _, r := range networkResults(s) {
+			results[r.Name] = r
 		}
+		return results
+	}
+
+	got := byName(networkStatus{tokenSource: "GH_TOKEN", limit: &ghclient.RateLimit{Limit: 5000, Remaining: 4990, Reset: reset}})
+	if r := got["github token"]; !r.OK || r.Message != "found in GH_TOKEN" {
+		t.Errorf("github token: got ok=%v %q", r.OK, r.Message)
+	}
+	if r := got["github rate limit"]; !r.OK || r.Message != "4990 of 5000 requests left, resets at 15:04" {
+		t.Errorf("github rate limit: got ok=%v %q", r.OK, r.Message)
+	}
+	if r := got["api.github.com"]; !r.OK || !got["codeload.github.com"].OK {
+		t.Errorf("hosts should be reachable, got %+v", got)
+	}
+
+	got = byName(networkStatus{limit: &ghclient.RateLimit{Limit: 60, Remaining: 0, Reset: reset}, codeloadErr: errors.New("dial tcp: i/o timeout")})
+	if r := got["github token"]; r.OK || !r.Warn {
+		t.Errorf("an anonymous run should warn, got %+v", r)
+	}
+	if r := got["github rate limit"]; r.OK || r.Warn || r.Message != "exhausted (60 per hour), resets at 15:04" {
+		t.Errorf("exhausted quota: got ok=%v warn=%v %q", r.OK, r.Warn, r.Message)
+	}
+	if r := got["codeload.github.com"]; r.OK || r.Message != "not reachable: dial tcp: i/o timeout" {
+		t.Errorf("codeload: got ok=%v %q", r.OK, r.Message)
+	}
+
+	got = byName(networkStatus{tokenSource: "GITHUB_TOKEN", apiErr: ghclient.ErrBadCredentials})
+	if r := got["api.github.com"]; r.OK || r.Fix != "Replace the expired or revoked token in GITHUB_TOKEN" {
+		t.Errorf("rejected token: got ok=%v fix %q", r.OK, r.Fix)
+	}
+	if _, ok := got["github rate limit"]; ok {
+		t.Error("no quota should be reported when the API did not answer")
 	}
 }
 
-func TestPlanCommandContractKeepsLegacyStateCompatibility(t *testing.T) {
-	planCommand := readRepoFileForCommandTests(t, ".maestro/commands/maestro.plan.md")
+// TestResolveDirs tests that -C sets the directory a command runs in,
+// from which --path and paths given on the command line are resolved,
+// without changing the process's directory.
+func TestResolveDirs(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "pkg", "api"), 0755)
+	cwd, _ := os.Getwd()
+	defer func() { chdirFlag, projectPath = "", "" }()
+	defer pathfmt.SetRoot(".")
+
+	chdirFlag = filepath.Join(dir, "pkg")
+	plain := &cobra.Command{}
+	if err := resolveDirs(plain); err != nil {
+		t.Fatalf("resolveDirs() error: %v", err)
+	}
+	if got := workDir(plain); got != chdirFlag {
+		t.Errorf("workDir() = %s, want %s", got, chdirFlag)
+	}
+	if got, want := userPath(plain, "api"), filepath.Join(chdirFlag, "api"); got != want {
+		t.Errorf("userPath(api) = %s, want %s", got, want)
+	}
 
-	if !strings.Contains(planCommand, "Treat missing research fields as legacy state") {
-		t.Fatal("plan command contract must preserve legacy research-field compatibility")
+	withPath := &cobra.Command{}
+	addProjectPathFlag(withPath, false)
+	projectPath = "api"
+	if err := resolveDirs(withPath); err != nil {
+		t.Fatalf("resolveDirs() with --path error: %v", err)
+	}
+	if got, want := workDir(withPath), filepath.Join(chdirFlag, "api"); got != want {
+		t.Errorf("--path should resolve from -C: workDir() = %s, want %s", got, want)
+	}
+	if got, want := userPath(withPath, "notes.md"), filepath.Join(chdirFlag, "notes.md"); got != want {
+		t.Errorf("paths should resolve from -C, not --path: got %s, want %s", got, want)
+	}
+	if now, _ := os.Getwd(); now != cwd {
+		t.Errorf("resolveDirs should not change directory, now in %s", now)
 	}
 
-	if !strings.Contains(planCommand, "Never fail only because research metadata fields are missing") {
-		t.Fatal("plan command contract must not hard-fail when legacy state lacks research metadata")
+	projectPath = ""
+	chdirFlag = filepath.Join(dir, "missing")
+	if err := resolveDirs(&cobra.Command{}); err == nil || !strings.Contains(err.Error(), "entering -C") {
+		t.Errorf("resolveDirs() with a missing directory = %v", err)
 	}
 }
 
-func TestSynthesisFixturesCoverQualityMinimumsAndMissingFields(t *testing.T) {
-	complete := readRepoFileForCommandTests(t, "cmd/maestro-cli/test/fixtures/research/complete/synthesis.md")
-	missing := readRepoFileForCommandTests(t, "cmd/maestro-cli/test/fixtures/research/missing-quality/synthesis.md")
+// TestGitChecks tests that doctor reports a project outside git, a base
+// branch that does not exist, an ignored .maestro/state/, and uncommitted
+// changes in .maestro/.
+func TestGitChecks(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	defer os.Chdir(chdir(t, dir))
+	byName := func() map[string]doctor.Result {
+		results := map[string]doctor.Result{}
+		for _, r := range gitChecks(".", ".maestro") {
+			results[r.Name] = r
+		}
+		return results
+	}
+	os.MkdirAll(filepath.Join(".maestro", "state"), 0755)
+	config.Save(&config.ProjectConfig{Project: config.ProjectSection{BaseBranch: "develop"}}, filepath.Join(".maestro", "config.yaml"))
 
-	if missingSignals := missingSynthesisQualitySignals(complete); len(missingSignals) != 0 {
-		t.Fatalf("complete synthesis fixture missing required quality signals: %v", missingSignals)
+	if r := byName()["git repository"]; r.OK || len(r.Commands) != 1 || r.Commands[0] != "git init" {
+		t.Errorf("outside git: got %+v", r)
 	}
 
-	if missingSignals := missingSynthesisQualitySignals(missing); len(missingSignals) == 0 {
-		t.Fatal("missing-quality synthesis fixture must omit at least one required quality signal")
+	runGit(t, dir, "init", "-q", "-b", "main")
+	os.WriteFile(".gitignore", []byte("node_modules/\n.maestro/state/\n"), 0644)
+	got := byName()
+	if r := got["base branch"]; r.OK || r.Message != "develop (project.base_branch) does not exist locally or on origin" {
+		t.Errorf("base branch: got ok=%v %q", r.OK, r.Message)
+	}
+	if r := got["state not ignored"]; r.OK || !r.Warn || r.Message != `.maestro/state/ is ignored by ".maestro/state/" at .gitignore:2, so feature state is never committed` {
+		t.Errorf("state not ignored: got ok=%v %q", r.OK, r.Message)
+	}
+	if r := got["uncommitted changes"]; r.OK || !r.Warn || r.Message != "1 file(s) in .maestro/: .maestro/config.yaml" {
+		t.Errorf("uncommitted changes: got ok=%v %q", r.OK, r.Message)
+	}
+
+	os.WriteFile(".gitignore", []byte("node_modules/\n.maestro/state/\n!.maestro/state/\n"), 0644)
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+	runGit(t, dir, "branch", "develop")
+	for name, r := range byName() {
+		if !r.OK {
+			t.Errorf("%s: got %q, want it to pass", name, r.Message)
+		}
+	}
+}
+
+// TestAgentCLIChecks tests that doctor checks the CLIs of installed agents,
+// and gh, against the oldest versions maestro supports.
+func TestAgentCLIChecks(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".claude"), 0755)
+	os.MkdirAll(filepath.Join(dir, ".codex"), 0755)
+	versions := map[string]string{
+		"/bin/claude": "0.2.9 (Claude Code)",
+		"/bin/codex":  "codex-cli 0.21.0",
+	}
+	d := envreport.Detector{
+		LookPath: func(name string) (string, error) {
+			if name == "gh" {
+				return "", errors.New("not found")
+			}
+			return "/bin/" + name, nil
+		},
+		Version: func(path string) (string, error) { return versions[path], nil },
+	}
+
+	results := map[string]doctor.Result{}
+	for _, r := range agentCLIChecks(dir, d) {
+		results[r.Name] = r
+		if !r.Warn {
+			t.Errorf("%s: agent CLIs are optional, want a warning", r.Name)
+		}
+	}
+	if _, ok := results["opencode (agent CLI)"]; ok || len(results) != 3 {
+		t.Errorf("want claude, codex, and gh only, got %v", results)
+	}
+	if r := results["claude (agent CLI)"]; r.OK || r.Message != "0.2.9 is older than 1.0.0, the first release with argument-hint in command frontmatter" || len(r.Commands) != 1 {
+		t.Errorf("claude: got %+v", r)
+	}
+	if r := results["codex (agent CLI)"]; !r.OK || r.Message != "0.21.0 found on PATH" {
+		t.Errorf("codex: got %+v", r)
+	}
+	if r := results["gh (agent CLI)"]; r.OK || r.Message != "not found on PATH; it provides a GitHub token when GITHUB_TOKEN is unset" {
+		t.Errorf("gh: got %+v", r)
+	}
+
+	versions["/bin/claude"] = "1.0.83 (Claude Code)"
+	if r := agentCLIResult(agentCLIs[1], d.Tool("claude")); !r.OK || r.Message != "1.0.83 found on PATH (1.0.0 or later supported)" {
+		t.Errorf("claude 1.0.83: got %+v", r)
+	}
+}
+
+// TestDoctorAfterSync tests that the base file maestro sync records is
+// neither read as a feature's state nor reported as uncommitted.
+func TestDoctorAfterSync(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	remote, dir := filepath.Join(root, "remote.git"), filepath.Join(root, "project")
+	runGit(t, root, "init", "-q", "--bare", remote)
+	runGit(t, root, "init", "-q", "-b", "main", dir)
+	runGit(t, dir, "remote", "add", "origin", remote)
+	defer os.Chdir(chdir(t, dir))
+
+	os.MkdirAll(filepath.Join(".maestro", "state"), 0755)
+	os.WriteFile(filepath.Join(".maestro", "state", "001-auth.json"), []byte(`{"feature_id": "001-auth", "stage": "plan"}`), 0644)
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+
+	if err := runSyncPush(syncPushCmd, nil); err != nil {
+		t.Fatalf("sync push: %v", err)
+	}
+	if _, err := os.Stat(syncBasePath); err != nil {
+		t.Fatalf("sync push should record its base: %v", err)
+	}
+	for _, r := range stateChecks(".maestro") {
+		if !r.OK {
+			t.Errorf("%s: %s", r.Name, r.Message)
+		}
+	}
+	if r, ok := uncommittedCheck(".", ".maestro"); !ok || !r.OK {
+		t.Errorf("uncommitted changes: got %+v", r)
+	}
+}
+
+// TestDeepChecks tests that doctor --deep flags files whose content is
+// corrupt, which the structure checks accept.
+func TestDeepChecks(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	_ = os.Chdir(dir)
+
+	write := func(name, content string) {
+		p := filepath.Join(".maestro", filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		os.WriteFile(p, []byte(content), 0644)
+	}
+	write("scripts/ok.sh", "#!/usr/bin/env bash\necho ok\n")
+	write("scripts/no-shebang.sh", "echo hi\n")
+	write("templates/spec-template.md", "# Spec\n<<<<<<< local\nmine\n=======\ntheirs\n>>>>>>> v1.3.0\n")
+	write("commands/maestro.plan.md", "---\ndescription: >\n  Plan a feature.\nargument-hint: [feature-id] (optional)\n---\n# Plan\n")
+	write("commands/maestro.bad.md", "---\nargument-hint: [id]\n---\n")
+	write("skills/review/SKILL.md", "---\nname: review\ndescription: Review code.\n---\n")
+	write("state/001-auth.json", `{"stage": "plan"}`)
+	write("state/002-broken.json", `{"stage": "plan"`)
+
+	byName := map[string]doctor.Result{}
+	for _, r := range deepChecks(".maestro") {
+		byName[r.Name] = r
+	}
+	for name, want := range map[string]string{
+		".maestro/scripts/":   "1 of 2 script(s) invalid: .maestro/scripts/no-shebang.sh (no shebang)",
+		".maestro/templates/": "unresolved conflict markers",
+		".maestro/commands/":  "1 of 2 command(s) invalid: .maestro/commands/maestro.bad.md (frontmatter has no description)",
+		".maestro/state/":     "1 of 2 state file(s) invalid: .maestro/state/002-broken.json",
+	} {
+		if r := byName[name]; r.OK || !strings.Contains(r.Message, want) {
+			t.Errorf("%s: got ok=%v %q, want it to fail with %q", name, r.OK, r.Message, want)
+		}
+	}
+	if r := byName[".maestro/skills/"]; !r.OK {
+		t.Errorf("skills should pass, got %q", r.Message)
+	}
+	if r := byName[".maestro/commands/"]; len(r.Commands) != 1 || r.Commands[0] != "maestro scripts update --backup commands" {
+		t.Errorf("invalid starter files should be fixed by restoring the directory, got %v", r.Commands)
+	}
+
+	if _, err := exec.LookPath("bash"); err == nil {
+		write("scripts/no-shebang.sh", "#!/bin/sh\nif true; then\n")
+		if r := deepChecks(".maestro")[0]; r.OK || !strings.Contains(r.Message, "bash -n") {
+			t.Errorf("a script with a syntax error should fail, got %q", r.Message)
+		}
+	}
+}
+
+// TestDoctorEmitFixes tests that doctor --emit-fixes writes a script that
+// runs the fixes for failed checks and only suggests the optional ones.
+func TestDoctorEmitFixes(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	_ = os.Chdir(dir)
+
+	_ = os.MkdirAll(filepath.Join(".maestro", "scripts"), 0755)
+	_ = os.MkdirAll(filepath.Join(".maestro", "specs"), 0755)
+	_ = os.WriteFile(filepath.Join(".maestro", "config.yaml"), []byte("cli_version: v0.1.0\n"), 0644)
+
+	doctorEmitFixes = "fixes.sh"
+	defer func() { doctorEmitFixes = "" }()
+	if err := runDoctor(doctorCmd, nil); err == nil {
+		t.Fatal("doctor should fail without .maestro/state")
+	}
+
+	data, err := os.ReadFile("fixes.sh")
+	if err != nil {
+		t.Fatalf("fix script not written: %v", err)
+	}
+	script := string(data)
+	for _, want := range []string{
+		"#!/bin/sh\n",
+		"\n# FAIL .maestro/state/: missing\nmkdir -p .maestro/state\n",
+		"\n# WARN (optional, uncomment to apply) .claude/: not found (optional)\n# maestro init --yes --conflict-action merge --with-claude\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("fix script missing %q:\n%s", want, script)
+		}
+	}
+	if strings.Contains(script, "scripts/") {
+		t.Errorf("passing checks should not be in the script:\n%s", script)
+	}
+	if info, _ := os.Stat("fixes.sh"); info.Mode()&0100 == 0 {
+		t.Error("fix script should be executable")
+	}
+}
+
+// TestDoctorFormatFlag tests that doctor rejects unknown formats, and
+// printing the fix script to stdout along with a machine-readable format.
+func TestDoctorFormatFlag(t *testing.T) {
+	defer func() { doctorOutput, doctorEmitFixes = "text", "" }()
+	doctorOutput = "yaml"
+	if err := runDoctor(doctorCmd, nil); err == nil {
+		t.Error("an unknown format should fail")
+	}
+	doctorOutput, doctorEmitFixes = "json", "-"
+	if err := runDoctor(doctorCmd, nil); err == nil {
+		t.Error("--emit-fixes - should not be combined with --output json")
+	}
+}
+
+// TestDriftFixCommands tests that drifted files map to the starter
+// directories that restore them.
+func TestDriftFixCommands(t *testing.T) {
+	got := driftFixCommands([]string{".maestro/scripts/a.sh", ".maestro/templates/spec.md", ".maestro/scripts/b.sh", ".claude/x.md"})
+	if len(got) != 1 || got[0] != "maestro scripts update --backup scripts templates" {
+		t.Errorf("driftFixCommands() = %v", got)
+	}
+	if shellQuote("it's here") != `'it'\''s here'` || shellQuote(".maestro/state") != ".maestro/state" {
+		t.Errorf("shellQuote quoting is wrong: %s", shellQuote("it's here"))
+	}
+}
+
+// TestUpdateNoRegressionExistingFlow tests update preserves existing behavior.
+func TestUpdateNoRegressionExistingFlow(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	_ = os.Chdir(dir)
+
+	// Set up minimal .maestro/ structure
+	_ = os.MkdirAll(filepath.Join(".maestro", "scripts"), 0755)
+	_ = os.MkdirAll(filepath.Join(".maestro", "specs"), 0755)
+	_ = os.MkdirAll(filepath.Join(".maestro", "state"), 0755)
+	_ = os.WriteFile(filepath.Join(".maestro", "config.yaml"), []byte("cli_version: v0.1.0\n"), 0644)
+
+	// Unset GITHUB_TOKEN to ensure fetch fails gracefully
+	origToken := os.Getenv("GITHUB_TOKEN")
+	_ = os.Unsetenv("GITHUB_TOKEN")
+	defer func() {
+		if origToken != "" {
+			_ = os.Setenv("GITHUB_TOKEN", origToken)
+		}
+	}()
+
+	// Run update - should attempt basic update flow
+	err := runUpdate(updateCmd, nil)
+	// Update will fail on GitHub fetch - this is expected behavior
+	if err == nil {
+		t.Log("update succeeded (GitHub fetch may have succeeded unexpectedly)")
+	}
+
+	// Verify .maestro/ structure preserved
+	if _, err := os.Stat(".maestro"); os.IsNotExist(err) {
+		t.Error(".maestro/ should be preserved after update attempt")
+	}
+}
+
+// TestDoctorNoRegressionExistingFlow tests doctor preserves existing behavior.
+func TestDoctorNoRegressionExistingFlow(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	_ = os.Chdir(dir)
+
+	// Set up minimal valid .maestro/ structure
+	_ = os.MkdirAll(filepath.Join(".maestro", "scripts"), 0755)
+	_ = os.MkdirAll(filepath.Join(".maestro", "specs"), 0755)
+	_ = os.MkdirAll(filepath.Join(".maestro", "state"), 0755)
+	_ = os.WriteFile(filepath.Join(".maestro", "config.yaml"), []byte("cli_version: v0.1.0\n"), 0644)
+
+	err := runDoctor(doctorCmd, nil)
+	// Should pass with valid structure
+	if err != nil {
+		t.Errorf("doctor should pass on valid project, got: %v", err)
+	}
+}
+
+// TestScriptsUpdateOnUninitializedProject tests scripts update when .maestro/ doesn't exist.
+func TestScriptsUpdateOnUninitializedProject(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	_ = os.Chdir(dir)
+
+	if err := runScriptsUpdate(scriptsUpdateCmd, nil); err == nil {
+		t.Error("scripts update should fail when .maestro/ not found")
+	}
+}
+
+// TestScriptsUpdateTargets tests which directories and ref scripts update uses.
+func TestScriptsUpdateTargets(t *testing.T) {
+	if got := resolveStarterDirs(nil); len(got) != 1 || got[0] != ".maestro/scripts" {
+		t.Errorf("default dirs = %v, want [.maestro/scripts]", got)
+	}
+	if got := resolveStarterDirs([]string{"skills", "templates", "skills"}); len(got) != 2 || got[1] != ".maestro/templates" {
+		t.Errorf("dirs = %v, want [.maestro/skills .maestro/templates]", got)
+	}
+	if err := scriptsUpdateCmd.Args(scriptsUpdateCmd, []string{"state"}); err == nil {
+		t.Error("user data directories should not be accepted")
+	}
+
+	if ref := pinnedRef("v0.4.0"); ref != "v0.4.0" {
+		t.Errorf("pinnedRef(v0.4.0) = %s", ref)
+	}
+	if ref := pinnedRef("dev"); ref != "main" {
+		t.Errorf("pinnedRef(dev) = %s, want main", ref)
+	}
+}
+
+// TestShippedTemplatesLintClean verifies every starter template passes
+// 'maestro templates lint'.
+func TestShippedTemplatesLintClean(t *testing.T) {
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	if err := os.Chdir("../../.."); err != nil {
+		t.Fatalf("chdir to repo root: %v", err)
+	}
+
+	if err := runTemplatesLint(templatesLintCmd, nil); err != nil {
+		t.Errorf("shipped templates should lint clean, got: %v", err)
+	}
+}
+
+// TestInitRefusesAssetsRepo tests init refuses to clobber the assets repo without --force-self.
+func TestInitRefusesAssetsRepo(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	_ = os.Chdir(dir)
+
+	_ = os.MkdirAll(filepath.Join(".maestro", "commands"), 0755)
+	_ = os.WriteFile(filepath.Join(".maestro", "commands", "source.md"), []byte("source-controlled"), 0644)
+	_ = os.WriteFile(assetsRepoMarker, []byte(""), 0644)
+
+	err := runInit(initCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "--force-self") {
+		t.Fatalf("init should refuse to run in the assets repo, got: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(".maestro", "commands", "source.md")); string(data) != "source-controlled" {
+		t.Error("source-controlled .maestro/ content should be untouched")
+	}
+
+	if err := guardAssetsRepo(".", "maestro init", true); err != nil {
+		t.Errorf("--force-self should allow running, got: %v", err)
+	}
+}
+
+// TestInitRefusesHomeDirectory tests init will not write .maestro/ into the
+// home directory without --allow-unsafe-dir.
+func TestInitRefusesHomeDirectory(t *testing.T) {
+	home, _ := filepath.EvalSymlinks(t.TempDir())
+	t.Setenv("HOME", home)
+	origDir := chdir(t, home)
+	defer os.Chdir(origDir)
+
+	nonInteractive = true
+	defer func() { nonInteractive, allowUnsafeDir = false, false }()
+
+	err := runInit(initCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "home directory") || !strings.Contains(err.Error(), "--allow-unsafe-dir") {
+		t.Fatalf("init should refuse the home directory, got: %v", err)
+	}
+	if _, err := os.Stat(".maestro"); !os.IsNotExist(err) {
+		t.Error(".maestro/ should not be created")
+	}
+
+	allowUnsafeDir = true
+	if err := guardTargetDir(initCmd); err != nil {
+		t.Errorf("--allow-unsafe-dir should allow running, got: %v", err)
+	}
+}
+
+// TestUnsafeTargetReasons tests which directories are flagged.
+func TestUnsafeTargetReasons(t *testing.T) {
+	t.Parallel()
+	if reasons := unsafeTargetReasons(t.TempDir()); len(reasons) != 0 {
+		t.Errorf("a project directory should not be flagged, got %v", reasons)
+	}
+	if reasons := unsafeTargetReasons(string(filepath.Separator)); len(reasons) == 0 {
+		t.Error("the filesystem root should be flagged")
+	}
+
+	if os.Geteuid() != 0 {
+		return
+	}
+	dir := t.TempDir()
+	if err := os.Chown(dir, 1000, 1000); err != nil {
+		t.Skipf("chown: %v", err)
+	}
+	if reasons := unsafeTargetReasons(dir); len(reasons) != 1 || !strings.Contains(reasons[0], "uid 1000") {
+		t.Errorf("root in another user's directory should be flagged, got %v", reasons)
+	}
+}
+
+// TestInitNonInteractiveUsesDefaultAction tests init --yes never prompts and
+// applies --conflict-action to an existing project.
+func TestInitNonInteractiveUsesDefaultAction(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	_ = os.Chdir(dir)
+
+	_ = os.MkdirAll(filepath.Join(".maestro", "scripts"), 0755)
+	_ = os.WriteFile(filepath.Join(".maestro", "scripts", "custom.sh"), []byte("echo custom"), 0644)
+
+	nonInteractive = true
+	defer func() { nonInteractive = false }()
+
+	if err := runInit(initCmd, nil); err != nil {
+		t.Fatalf("init --yes should not need stdin, got: %v", err)
+	}
+
+	backups, _ := filepath.Glob(".maestro-backup-*")
+	if len(backups) != 1 {
+		t.Fatalf("expected default backup action to create one backup, got %v", backups)
+	}
+	if _, err := os.Stat(filepath.Join(backups[0], "scripts", "custom.sh")); err != nil {
+		t.Errorf("backup should contain the previous files: %v", err)
+	}
+	for _, agentDir := range agents.KnownAgentDirs() {
+		if _, err := os.Stat(agentDir); !os.IsNotExist(err) {
+			t.Errorf("%s should not be installed without a --with-* flag", agentDir)
+		}
+	}
+}
+
+// TestInitMergeKeepsModifiedFiles tests re-running init with
+// --conflict-action=merge restores deleted files, keeps edited ones, and
+// leaves the project config in place.
+func TestInitMergeKeepsModifiedFiles(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	_ = os.Chdir(dir)
+
+	nonInteractive = true
+	defer func() { nonInteractive, conflictActionDefault = false, "backup" }()
+	if err := runInit(initCmd, nil); err != nil {
+		t.Fatalf("first init failed: %v", err)
+	}
+
+	scripts, _ := filepath.Glob(filepath.Join(".maestro", "scripts", "*.sh"))
+	if len(scripts) < 2 {
+		t.Fatalf("expected embedded scripts, got %v", scripts)
+	}
+	edited, deleted := scripts[0], scripts[1]
+	_ = os.WriteFile(edited, []byte("# customized\n"), 0644)
+	_ = os.Remove(deleted)
+	_ = os.WriteFile("AGENTS.md", []byte("# Ours\n"), 0644)
+	cfg, _ := config.Load(filepath.Join(".maestro", "config.yaml"))
+	cfg.Project.BaseBranch = "trunk"
+	_ = config.Save(cfg, filepath.Join(".maestro", "config.yaml"))
+
+	conflictActionDefault = "merge"
+	if err := runInit(initCmd, nil); err != nil {
+		t.Fatalf("merge init failed: %v", err)
+	}
+
+	if data, _ := os.ReadFile(edited); string(data) != "# customized\n" {
+		t.Errorf("edited script should be kept, got %q", data)
+	}
+	if _, err := os.Stat(deleted); err != nil {
+		t.Errorf("deleted script should be restored: %v", err)
+	}
+	if data, _ := os.ReadFile("AGENTS.md"); string(data) != "# Ours\n" {
+		t.Errorf("AGENTS.md should be kept, got %q", data)
+	}
+	if backups, _ := filepath.Glob(".maestro-backup-*"); len(backups) != 0 {
+		t.Errorf("merge should not create backups, got %v", backups)
+	}
+
+	cfg, _ = config.Load(filepath.Join(".maestro", "config.yaml"))
+	if cfg.Project.BaseBranch != "trunk" {
+		t.Errorf("config settings should survive a merge, got %+v", cfg.Project)
+	}
+	if modified, _ := cfg.Installed.Drift("."); len(modified) != 1 || modified[0] != filepath.ToSlash(edited) {
+		t.Errorf("edited script should still be reported as drift, got %v", modified)
+	}
+}
+
+// TestParseConflictAction tests --conflict-action values.
+func TestParseConflictAction(t *testing.T) {
+	t.Parallel()
+	for in, want := range map[string]agents.ConflictAction{"overwrite": agents.ConflictOverwrite, "Backup": agents.ConflictBackup, "c": agents.ConflictCancel, "merge": agents.ConflictMerge} {
+		if got, err := parseConflictAction(in); err != nil || got != want {
+			t.Errorf("parseConflictAction(%q) = %v, %v", in, got, err)
+		}
+	}
+	if _, err := parseConflictAction("skip"); err == nil {
+		t.Error("invalid action should be rejected")
+	}
+}
+
+// failingReader fails the test when a prompt reads from it.
+type failingReader struct{ t *testing.T }
+
+func (r failingReader) Read([]byte) (int, error) {
+	r.t.Helper()
+	r.t.Fatal("prompt read stdin in unattended mode")
+	return 0, io.EOF
+}
+
+// TestConfiguredConflictActions tests that conflict.<class> and
+// conflict.dirs pick the action for each directory when not prompting, and
+// that a chosen --conflict-action wins over them.
+func TestConfiguredConflictActions(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	orig, _ := os.Getwd()
+	chdir(t, dir)
+	defer os.Chdir(orig)
+	nonInteractive = true
+	defer func() { nonInteractive, conflictActionChosen = false, false }()
+
+	os.MkdirAll(".maestro", 0755)
+	os.WriteFile(".maestro/config.yaml", []byte("conflict:\n  agents: overwrite\n  dirs:\n    .claude: backup\n    .maestro/scripts/: cancel\n"), 0644)
+
+	var out bytes.Buffer
+	actions, err := promptConflict(".", os.Stdin, &out, conflictClassAgents, []string{".claude", ".opencode", ".codex"})
+	if err != nil {
+		t.Fatalf("promptConflict() error: %v", err)
+	}
+	want := map[string]agents.ConflictAction{".claude": agents.ConflictBackup, ".opencode": agents.ConflictOverwrite, ".codex": agents.ConflictOverwrite}
+	for d, action := range want {
+		if actions[d] != action {
+			t.Errorf("%s: got %s, want %s", d, conflictActionName(actions[d]), conflictActionName(action))
+		}
+	}
+	if !strings.Contains(out.String(), "Existing .claude: using conflict.dirs..claude=backup") ||
+		!strings.Contains(out.String(), "Existing .opencode, .codex: using conflict.agents=overwrite") {
+		t.Errorf("output should say where each action came from, got:\n%s", out.String())
+	}
+
+	actions, err = promptConflict(".", os.Stdin, io.Discard, conflictClassAssets, []string{".maestro/scripts", ".maestro/templates"})
+	if err != nil || actions[".maestro/scripts"] != agents.ConflictCancel || actions[".maestro/templates"] != agents.ConflictBackup {
+		t.Errorf("assets: got %v, %v", actions, err)
+	}
+
+	conflictActionChosen = true
+	if actions, err := promptConflict(".", os.Stdin, io.Discard, conflictClassAgents, []string{".opencode"}); err != nil || actions[".opencode"] != agents.ConflictBackup {
+		t.Errorf("a chosen --conflict-action should win, got %v, %v", actions, err)
+	}
+	conflictActionChosen = false
+
+	os.WriteFile(".maestro/config.yaml", []byte("conflict:\n  agents: replace\n"), 0644)
+	if _, err := promptConflict(".", os.Stdin, io.Discard, conflictClassAgents, []string{".claude"}); err == nil || !strings.Contains(err.Error(), "conflict.agents") {
+		t.Errorf("an invalid configured action should name its key, got %v", err)
+	}
+}
+
+// TestConflictPromptRemembersPerDirectory tests that agent directories can
+// be answered one by one, that the answers can be saved to conflict.dirs,
+// and that the next prompt only asks about the directories left.
+func TestConflictPromptRemembersPerDirectory(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	orig, _ := os.Getwd()
+	chdir(t, dir)
+	defer os.Chdir(orig)
+
+	os.MkdirAll(".maestro", 0755)
+	os.WriteFile(".maestro/config.yaml", []byte("cli_version: v0.1.0\n"), 0644)
+
+	var out bytes.Buffer
+	actions, err := promptConflict(".", strings.NewReader("d\no\nb\ny\n"), &out, conflictClassAgents, []string{".opencode", ".claude"})
+	if err != nil {
+		t.Fatalf("promptConflict() error: %v", err)
+	}
+	if actions[".opencode"] != agents.ConflictOverwrite || actions[".claude"] != agents.ConflictBackup {
+		t.Errorf("got %v, want .opencode overwritten and .claude backed up", actions)
+	}
+	data, _ := os.ReadFile(".maestro/config.yaml")
+	if !strings.Contains(string(data), ".claude: backup") || !strings.Contains(string(data), ".opencode: overwrite") {
+		t.Errorf("the answers should be saved to conflict.dirs, got:\n%s", data)
+	}
+
+	out.Reset()
+	actions, err = promptConflict(".", strings.NewReader("c\n"), &out, conflictClassAgents, []string{".opencode", ".claude", ".codex"})
+	if err != nil {
+		t.Fatalf("promptConflict() error: %v", err)
+	}
+	if actions[".opencode"] != agents.ConflictOverwrite || actions[".claude"] != agents.ConflictBackup || actions[".codex"] != agents.ConflictCancel {
+		t.Errorf("got %v, want the remembered actions and .codex cancelled", actions)
+	}
+	if !strings.Contains(out.String(), "Existing .claude: using conflict.dirs..claude=backup") || !strings.Contains(out.String(), ".codex already exists") {
+		t.Errorf("only .codex should be asked about, got:\n%s", out.String())
+	}
+
+	conflictActionChosen = true
+	defer func() { conflictActionChosen = false }()
+	out.Reset()
+	if _, err := promptConflict(".", strings.NewReader("c\n"), &out, conflictClassAgents, []string{".claude"}); err != nil || !strings.Contains(out.String(), ".claude already exists") {
+		t.Errorf("with --conflict-action the prompt should ask again, got %v:\n%s", err, out.String())
+	}
+}
+
+// TestUpdateStage tests that a staged update writes to the staging
+// directory, and that its changes reach the project only once confirmed.
+func TestUpdateStage(t *testing.T) {
+	project := t.TempDir()
+	defer pathfmt.SetRoot(".")
+	script := filepath.Join(project, ".maestro", "scripts", "run.sh")
+	configPath := projectConfig(project)
+
+	os.MkdirAll(filepath.Dir(script), 0755)
+	os.WriteFile(configPath, []byte("cli_version: v1.0.0\n"), 0644)
+	os.WriteFile(script, []byte("old"), 0644)
+
+	run := func(answer string, err error, edit func(root string)) string {
+		t.Helper()
+		before, _ := os.ReadFile(script)
+		stage, beginErr := beginUpdateStage(project, report.New("update"))
+		if beginErr != nil {
+			t.Fatalf("beginUpdateStage() error: %v", beginErr)
+		}
+		edit(stage.area.Dir)
+		if data, _ := os.ReadFile(script); string(data) != string(before) {
+			t.Error("a staged change should not touch the project")
+		}
+		var out bytes.Buffer
+		if got := stage.finish(strings.NewReader(answer), &out, report.New("update"), err); got != err {
+			t.Errorf("finish() = %v, want %v", got, err)
+		}
+		if _, statErr := os.Stat(filepath.Join(project, updateStagingDir)); !os.IsNotExist(statErr) {
+			t.Error("finish() should remove the staging directory")
+		}
+		data, _ := os.ReadFile(script)
+		return string(data) + "\n" + out.String()
+	}
+	editScript := func(root string) {
+		os.WriteFile(filepath.Join(root, ".maestro", "scripts", "run.sh"), []byte("new"), 0644)
+	}
+
+	if got := run("n\n", nil, editScript); !strings.HasPrefix(got, "old\n") || !strings.Contains(got, "changed   .maestro/scripts/run.sh") {
+		t.Errorf("a declined update should list its changes and apply none, got:\n%s", got)
+	}
+	if got := run("y\n", errors.New("fetch failed"), editScript); !strings.HasPrefix(got, "old\n") {
+		t.Errorf("a failed update should apply nothing, got:\n%s", got)
+	}
+	if got := run("y\n", nil, editScript); !strings.HasPrefix(got, "new\n") {
+		t.Errorf("a confirmed update should be applied, got:\n%s", got)
+	}
+	if got := run("", nil, func(root string) { os.WriteFile(projectConfig(root), []byte("cli_version: v1.1.0\n"), 0644) }); strings.Contains(got, "Apply these changes?") {
+		t.Errorf("a config-only change should be applied without asking, got:\n%s", got)
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != "cli_version: v1.1.0\n" {
+		t.Errorf("config.yaml = %q, want the staged version", data)
+	}
+}
+
+func TestUpdateChannel(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	orig, _ := os.Getwd()
+	chdir(t, dir)
+	defer os.Chdir(orig)
+	defer func() { updateChannelFlag, updateVersion = "", "" }()
+
+	os.MkdirAll(".maestro", 0755)
+	newCmd := func() *cobra.Command {
+		c := &cobra.Command{}
+		c.Flags().StringVar(&updateChannelFlag, "channel", "", "")
+		return c
+	}
+	if channel, err := updateChannel(newCmd()); err != nil || channel != channelStable {
+		t.Errorf("updateChannel() = %q, %v; want stable by default", channel, err)
+	}
+
+	os.WriteFile(".maestro/config.yaml", []byte("channel: Prerelease\n"), 0644)
+	if channel, err := updateChannel(newCmd()); err != nil || channel != channelPrerelease {
+		t.Errorf("updateChannel() = %q, %v; want the channel setting", channel, err)
+	}
+
+	c := newCmd()
+	c.Flags().Set("channel", "nightly")
+	if channel, err := updateChannel(c); err != nil || channel != channelNightly {
+		t.Errorf("updateChannel() = %q, %v; want --channel to win", channel, err)
+	}
+	updateVersion = "v1.2.0"
+	if _, err := updateChannel(c); err == nil {
+		t.Error("--channel with --version should fail")
+	}
+	updateVersion = ""
+
+	c.Flags().Set("channel", "beta")
+	if _, err := updateChannel(c); err == nil || !strings.Contains(err.Error(), "invalid channel") {
+		t.Errorf("an unknown channel should fail, got %v", err)
+	}
+}
+
+// TestUpdateScopeFlags tests that --agents-only and --assets-only exclude
+// each other and limit --check to their part of the project.
+func TestUpdateScopeFlags(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	orig, _ := os.Getwd()
+	chdir(t, dir)
+	defer os.Chdir(orig)
+	defer func() { updateAgentsOnly, updateAssetsOnly, updateOutput = false, false, "text" }()
+	os.MkdirAll(".maestro", 0755)
+
+	updateAgentsOnly, updateAssetsOnly = true, true
+	if err := runUpdate(updateCmd, nil); err == nil || !strings.Contains(err.Error(), "cannot be used together") {
+		t.Errorf("--agents-only with --assets-only should fail, got %v", err)
+	}
+
+	// Without agent directories, an agents-only check has nothing to
+	// compare and needs no release
+	updateAssetsOnly, updateOutput = false, "json"
+	var out bytes.Buffer
+	if err := runUpdateCheck(updateCmd, &out, channelStable); err != nil {
+		t.Fatalf("runUpdateCheck() error: %v", err)
+	}
+	var report struct {
+		Items     []updateCheckItem `json:"items"`
+		Available bool              `json:"update_available"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(report.Items) != 0 || report.Available {
+		t.Errorf("--agents-only --check should leave out the CLI and assets, got %+v", report)
+	}
+}
+
+// TestUnchangedAgentBlobs tests that a refresh only skips the files that
+// are as they were installed.
+func TestUnchangedAgentBlobs(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	chdir(t, dir)
+	defer os.Chdir(orig)
+
+	for name, content := range map[string]string{
+		".codex/commands/maestro.plan.md":     "plan",
+		".codex/commands/maestro.list.md":     "list",
+		".codex/skills/maestro-plan/SKILL.md": "generated",
+		".codex/skills/maestro-list/SKILL.md": "generated",
+		".codex/config.toml":                  "model = 1",
+		".codex/notes.md":                     "notes",
+	} {
+		os.MkdirAll(filepath.Dir(name), 0755)
+		os.WriteFile(name, []byte(content), 0644)
+	}
+	sums, err := config.ChecksumFiles(".", []string{".codex"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.ProjectConfig{}
+	cfg.Installed.Files = sums
+	cfg.Installed.AgentDirs = map[string]config.InstalledAgentDir{".codex": {Blobs: map[string]string{
+		"commands/maestro.plan.md": "a",
+		"commands/maestro.list.md": "b",
+		"config.toml":              "c",
+		"notes.md":                 "d",
+		"gone.md":                  "e",
+	}}}
+
+	// Edited since the install, or whose generated skill was
+	os.WriteFile(".codex/notes.md", []byte("edited"), 0644)
+	os.Remove(".codex/skills/maestro-list/SKILL.md")
+
+	known := unchangedAgentBlobs(".", cfg, ".codex")
+	if len(known) != 2 || known["commands/maestro.plan.md"] != "a" || known["config.toml"] != "c" {
+		t.Errorf("unchangedAgentBlobs() = %v, want only the plan command and config.toml", known)
+	}
+	if known := unchangedAgentBlobs(".", cfg, ".claude"); known != nil {
+		t.Errorf("a directory without recorded blobs should have none, got %v", known)
+	}
+}
+
+// TestUnattendedPromptsTakeDefaults tests that prompts never read stdin when
+// it is not a terminal and answer with their defaults instead.
+func TestUnattendedPromptsTakeDefaults(t *testing.T) {
+	unattended, conflictActionDefault = true, "overwrite"
+	defer func() { unattended, conflictActionDefault = false, "backup" }()
+	r, w := failingReader{t}, io.Discard
+
+	if actions, err := promptConflict(".", r, w, conflictClassAgents, []string{".claude"}); err != nil || actions[".claude"] != agents.ConflictOverwrite {
+		t.Errorf("conflict prompt should use --conflict-action, got %v, %v", actions, err)
+	}
+	if ok, err := confirm(r, w, "Remove?"); err != nil || ok {
+		t.Errorf("confirmation should be declined, got %v, %v", ok, err)
+	}
+	selected, err := promptAgentSelection(r, w, agents.KnownAgentDirs(), []string{".claude"})
+	if err != nil || len(selected) != 1 || selected[0] != ".claude" {
+		t.Errorf("agent selection should keep the installed agents, got %v, %v", selected, err)
+	}
+	project, err := resolveProjectSection(".", r, w, config.ProjectSection{})
+	if err != nil || project.Name == "" || project.BaseBranch == "" {
+		t.Errorf("project settings should use detected defaults, got %+v, %v", project, err)
+	}
+}
+
+// TestPromptTimeoutFallsBackToDefault tests that an unanswered prompt takes
+// its default after --prompt-timeout, that each prompt reads only its own
+// line, and that input typed later still reaches the next prompt.
+func TestPromptTimeoutFallsBackToDefault(t *testing.T) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.Close()
+	defer pw.Close()
+	defer func() { promptTimeout = 0 }()
+	promptTimeout = 20 * time.Millisecond
+
+	answer, err := promptLine(bufio.NewReader(promptInput(pr)), io.Discard, "Base branch", "main")
+	if err != nil || answer != "main" {
+		t.Fatalf("timed-out prompt should return the default, got %q, %v", answer, err)
+	}
+
+	promptTimeout = time.Second
+	_, _ = pw.Write([]byte("trunk\nacme\n"))
+	answer, err = promptLine(bufio.NewReader(promptInput(pr)), io.Discard, "Base branch", "main")
+	if err != nil || answer != "trunk" {
+		t.Errorf("answer typed after a timeout should reach the next prompt, got %q, %v", answer, err)
+	}
+	answer, err = promptLine(bufio.NewReader(promptInput(pr)), io.Discard, "Project name", "demo")
+	if err != nil || answer != "acme" {
+		t.Errorf("each prompt should read only its own line, got %q, %v", answer, err)
+	}
+}
+
+// TestSelftestPasses runs the full selftest suite against the embedded assets.
+func TestSelftestPasses(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	_ = os.Chdir(dir)
+
+	if err := runSelftest(selftestCmd, nil); err != nil {
+		t.Fatalf("selftest should pass, got: %v", err)
+	}
+
+	cwd, _ := os.Getwd()
+	if cwd != dir {
+		t.Errorf("selftest should restore the working directory, got %s", cwd)
+	}
+	if _, err := os.Stat(".maestro"); !os.IsNotExist(err) {
+		t.Error("selftest should not touch the current project")
+	}
+}
+
+func TestPlanCommandContractIncludesResearchReadinessAndBypassPhrase(t *testing.T) {
+	planCommand := readRepoFileForCommandTests(t, ".maestro/commands/maestro.plan.md")
+
+	requiredSnippets := []string{
+		"Consider research ready only when all are true",
+		"I acknowledge proceeding without complete research",
+		"synthesis minimum quality signals are present",
+		"run `/maestro.research {feature_id}`",
+	}
+
+	for _, snippet := range requiredSnippets {
+		if !strings.Contains(planCommand, snippet) {
+			t.Fatalf("plan command contract missing snippet %q", snippet)
+		}
+	}
+}
+
+func TestPlanCommandContractKeepsLegacyStateCompatibility(t *testing.T) {
+	planCommand := readRepoFileForCommandTests(t, ".maestro/commands/maestro.plan.md")
+
+	if !strings.Contains(planCommand, "Treat missing research fields as legacy state") {
+		t.Fatal("plan command contract must preserve legacy research-field compatibility")
+	}
+
+	if !strings.Contains(planCommand, "Never fail only because research metadata fields are missing") {
+		t.Fatal("plan command contract must not hard-fail when legacy state lacks research metadata")
+	}
+}
+
+func TestSynthesisFixturesCoverQualityMinimumsAndMissingFields(t *testing.T) {
+	t.Parallel()
+	complete := readRepoFileForCommandTests(t, "cmd/maestro-cli/test/fixtures/research/complete/synthesis.md")
+	missing := readRepoFileForCommandTests(t, "cmd/maestro-cli/test/fixtures/research/missing-quality/synthesis.md")
+
+	if missingSignals := missingSynthesisQualitySignals(complete); len(missingSignals) != 0 {
+		t.Fatalf("complete synthesis fixture missing required quality signals: %v", missingSignals)
+	}
+
+	if missingSignals := missingSynthesisQualitySignals(missing); len(missingSignals) == 0 {
+		t.Fatal("missing-quality synthesis fixture must omit at least one required quality signal")
+	}
+}
+
+// TestResolveDirsDiscoversNearestProject verifies project commands find
+// the nearest .maestro/ from a subdirectory, but not past the enclosing git
+// repository, and still resolve paths given by the user from where they ran.
+func TestResolveDirsDiscoversNearestProject(t *testing.T) {
+	dir, _ := filepath.EvalSymlinks(t.TempDir())
+	project := filepath.Join(dir, "services", "api")
+	nested := filepath.Join(project, "src", "handlers")
+	os.MkdirAll(filepath.Join(project, ".maestro"), 0755)
+	os.MkdirAll(nested, 0755)
+
+	chdirFlag = nested
+	defer func() { chdirFlag = "" }()
+	resolveTestDirs(t, doctorCmd)
+	if got := workDir(doctorCmd); got != project {
+		t.Errorf("workDir() = %s, want %s", got, project)
+	}
+
+	resolveTestDirs(t, importCmd)
+	if got := userPath(importCmd, "kiro"); got != filepath.Join(nested, "kiro") {
+		t.Errorf("userPath() = %s, want it relative to %s", got, nested)
+	}
+
+	// A git repository root bounds the search
+	os.MkdirAll(filepath.Join(dir, "other", ".git"), 0755)
+	os.MkdirAll(filepath.Join(dir, ".maestro"), 0755)
+	chdirFlag = filepath.Join(dir, "other")
+	resolveTestDirs(t, removeCmd)
+	if got := workDir(removeCmd); got != chdirFlag {
+		t.Errorf("search should stop at the git root, workDir() = %s", got)
+	}
+}
+
+// TestInitPathCreatesSubdirectoryProject verifies init --path initializes
+// inside a monorepo package directory.
+func TestInitPathCreatesSubdirectoryProject(t *testing.T) {
+	dir, _ := filepath.EvalSymlinks(t.TempDir())
+	chdirFlag = dir
+	projectPath, nonInteractive = filepath.Join("services", "api"), true
+	defer func() { chdirFlag, projectPath, nonInteractive = "", "", false }()
+
+	resolveTestDirs(t, initCmd)
+	if err := runInit(initCmd, nil); err != nil {
+		t.Fatalf("init --path error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "services", "api", ".maestro", "config.yaml")); err != nil {
+		t.Errorf("expected .maestro/ in services/api: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".maestro")); !os.IsNotExist(err) {
+		t.Error("init --path should not touch the current directory")
+	}
+}
+
+// stubWizard answers the init wizard's screens without a terminal.
+type stubWizard struct {
+	agents    []string
+	action    int
+	confirmed bool
+	picked    []tui.Option
+}
+
+func (s *stubWizard) Checklist(title string, items, checked []string) ([]string, error) {
+	return s.agents, nil
+}
+
+func (s *stubWizard) Pick(title string, options []tui.Option) (int, error) {
+	s.picked = options
+	return s.action, nil
+}
+
+func (s *stubWizard) Confirm(title string, lines []string) (bool, error) {
+	return s.confirmed, nil
+}
+
+// TestInitWizardRecordsAnswers tests that the init wizard turns its answers
+// into init flags, offers merging only when .maestro/ exists, and leaves
+// everything unset when the summary is declined.
+func TestInitWizardRecordsAnswers(t *testing.T) {
+	dir := t.TempDir()
+	defer os.Chdir(chdir(t, dir))
+	defer func() {
+		nonInteractive, conflictActionDefault = false, "backup"
+		setInitAgentFlags(nil)
+		initWithNone = false
+	}()
+	if err := os.Mkdir(".claude", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ui := &stubWizard{agents: []string{".claude", ".codex"}, action: 1, confirmed: true}
+	ok, err := runInitWizard(".", ui, "embedded assets", false)
+	if err != nil || !ok {
+		t.Fatalf("runInitWizard() = %v, %v", ok, err)
+	}
+	if len(ui.picked) != 3 {
+		t.Errorf("without .maestro/ merge should not be offered, got %v", ui.picked)
+	}
+	if !initWithClaude || !initWithCodex || initWithOpenCode || initWithNone {
+		t.Errorf("agent flags not set from the checklist: claude=%v codex=%v opencode=%v none=%v", initWithClaude, initWithCodex, initWithOpenCode, initWithNone)
+	}
+	if conflictActionDefault != "overwrite" || !nonInteractive {
+		t.Errorf("conflict action %q, nonInteractive %v", conflictActionDefault, nonInteractive)
+	}
+
+	setInitAgentFlags(nil)
+	initWithNone, nonInteractive, conflictActionDefault = false, false, "backup"
+	if err := os.Mkdir(".maestro", 0755); err != nil {
+		t.Fatal(err)
+	}
+	ui = &stubWizard{agents: []string{}, action: 2, confirmed: false}
+	ok, err = runInitWizard(".", ui, "embedded assets", false)
+	if err != nil || ok {
+		t.Fatalf("declined summary should cancel, got %v, %v", ok, err)
+	}
+	if len(ui.picked) != 4 || ui.picked[2].Label != "Merge" {
+		t.Errorf("with .maestro/ merge should be offered, got %v", ui.picked)
+	}
+	if nonInteractive || conflictActionDefault != "backup" {
+		t.Errorf("cancelled wizard should not change options, got nonInteractive %v, conflict action %q", nonInteractive, conflictActionDefault)
+	}
+}
+
+// TestApplyHTTPHeadersFromGlobalConfig tests that mirror headers are read
+// from http.headers in the global config.
+func TestApplyHTTPHeadersFromGlobalConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("MIRROR_TOKEN", "s3cret")
+	defer httpheaders.SetRules(nil)
+
+	globalPath := filepath.Join(home, ".config", "maestro", "config.yaml")
+	_ = os.MkdirAll(filepath.Dir(globalPath), 0755)
+	_ = os.WriteFile(globalPath, []byte("http:\n  headers:\n    - url: https://mirror.example.com/\n      headers:\n        Authorization: Bearer ${MIRROR_TOKEN}\n"), 0644)
+
+	if err := applyHTTPHeaders(); err != nil {
+		t.Fatalf("applyHTTPHeaders() error: %v", err)
+	}
+	headers, err := httpheaders.For("https://mirror.example.com/maestro.tar.gz")
+	if err != nil || headers.Get("Authorization") != "Bearer s3cret" {
+		t.Errorf("mirror headers = %v, %v", headers, err)
+	}
+
+	_ = os.WriteFile(globalPath, []byte("http:\n  headers:\n    - headers:\n        X-Key: k\n"), 0644)
+	if err := applyHTTPHeaders(); err == nil {
+		t.Error("a rule without a url should be rejected")
+	}
+}
+
+func TestUpdateCheckItems(t *testing.T) {
+	t.Parallel()
+	cfg := &config.ProjectConfig{CLIVersion: "v0.3.0"}
+	cfg.Installed.AssetVersion = "v0.4.0"
+	cfg.Installed.AgentDirs = map[string]config.InstalledAgentDir{
+		".claude":   {Commit: "aaaaaaaaaa"},
+		".opencode": {Commit: "bbbbbbbbbb"},
+	}
+
+	items := updateCheckItems("v0.4.0", cfg, "v0.4.0", []string{".claude", ".opencode", ".codex"}, "main", "bbbbbbbbbb")
+	want := []updateCheckItem{
+		{Name: "CLI", Current: "v0.4.0", Latest: "v0.4.0"},
+		{Name: "assets", Current: "v0.4.0", Latest: "v0.4.0"},
+		{Name: ".claude", Current: "aaaaaaa", Latest: "main@bbbbbbb", Available: true},
+		{Name: ".opencode", Current: "bbbbbbb", Latest: "main@bbbbbbb"},
+		{Name: ".codex", Current: "unknown", Latest: "main@bbbbbbb", Available: true},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("updateCheckItems() =\n%+v\nwant\n%+v", items, want)
+	}
+
+	items = updateCheckItems("dev", cfg, "v0.5.0", []string{".claude"}, "", "")
+	if items[0].Available || items[0].Note != "development build" {
+		t.Errorf("a dev build should not be reported as out of date: %+v", items[0])
+	}
+	if !items[1].Available || items[2].Available || items[2].Note == "" {
+		t.Errorf("unexpected items: %+v", items)
+	}
+
+	var out bytes.Buffer
+	if err := writeUpdateCheck(&out, "text", items); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "assets") || !strings.Contains(out.String(), "1 update(s) available") {
+		t.Errorf("unexpected report:\n%s", out.String())
+	}
+}
+
+func TestReportAgents(t *testing.T) {
+	dir := t.TempDir()
+	orig := chdir(t, dir)
+	defer os.Chdir(orig)
+
+	os.MkdirAll(filepath.Join(".maestro", "state"), 0755)
+	os.WriteFile(filepath.Join(".maestro", "state", "001-auth.json"), []byte(`{"feature_id": "001-auth", "history": [
+  {"stage": "specify", "timestamp": "2026-01-01T10:00:00Z", "actor": "claude"},
+  {"stage": "plan", "timestamp": "2026-01-02T10:00:00Z", "actor": "claude"},
+  {"stage": "tasks", "timestamp": "2026-01-03T10:00:00Z"}
+]}`), 0644)
+
+	stats := agentreport.Summarize(mustReadTransitions(t), nil, time.Time{})
+	var out bytes.Buffer
+	if err := writeAgentReport(&out, stats, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"claude   0                2", "unknown  0                1", "No tasks counted", "Set MAESTRO_ACTOR"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, out.String())
+		}
+	}
+
+	reportSince = "2026-01-03"
+	defer func() { reportSince = "" }()
+	if err := runReportAgents(reportAgentsCmd, nil); err != nil {
+		t.Errorf("report agents --since: %v", err)
+	}
+	reportSince = "last week"
+	if err := runReportAgents(reportAgentsCmd, nil); err == nil {
+		t.Error("an invalid --since should fail")
+	}
+}
+
+func mustReadTransitions(t *testing.T) []agentreport.Transition {
+	t.Helper()
+	transitions, err := agentreport.ReadTransitions(filepath.Join(".maestro", "state"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return transitions
+}
+
+func TestConfirmDowngrade(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	for _, tt := range []struct {
+		installed, target, answer string
+		want, asked               bool
+	}{
+		{"v1.2.0", "v1.3.0", "", true, false},
+		{"v1.2.0", "v1.2.0", "", true, false},
+		{"main", "v1.0.0", "", true, false},
+		{"v1.2.0", "v1.1.0", "y\n", true, true},
+		{"v1.2.0", "v1.1.0", "\n", false, true},
+	} {
+		out.Reset()
+		got, err := confirmDowngrade(strings.NewReader(tt.answer), &out, tt.installed, tt.target)
+		if err != nil || got != tt.want {
+			t.Errorf("confirmDowngrade(%s -> %s, %q) = %v, %v; want %v", tt.installed, tt.target, tt.answer, got, err, tt.want)
+		}
+		if asked := strings.Contains(out.String(), "Downgrade"); asked != tt.asked {
+			t.Errorf("confirmDowngrade(%s -> %s) asked = %v, want %v", tt.installed, tt.target, asked, tt.asked)
+		}
+	}
+}
+
+// TestSpecsRestoreSavesCurrentFiles verifies restore reverts a feature by
+// number and keeps the mangled files in a new snapshot.
+func TestSpecsRestoreSavesCurrentFiles(t *testing.T) {
+	dir := t.TempDir()
+	orig := chdir(t, dir)
+	defer os.Chdir(orig)
+
+	spec := filepath.Join(".maestro", "specs", "003-user-auth", "spec.md")
+	os.MkdirAll(filepath.Dir(spec), 0755)
+	os.WriteFile(spec, []byte("# User auth\n"), 0644)
+
+	if err := runSpecsRestore(specsRestoreCmd, []string{"003"}); err == nil {
+		t.Error("restore without snapshots should fail")
+	}
+	if err := runSpecsSnapshot(specsSnapshotCmd, []string{"003"}); err != nil {
+		t.Fatalf("specs snapshot: %v", err)
+	}
+	os.WriteFile(spec, []byte("mangled\n"), 0644)
+
+	if err := runSpecsRestore(specsRestoreCmd, []string{"003"}); err != nil {
+		t.Fatalf("specs restore: %v", err)
+	}
+	if data, _ := os.ReadFile(spec); string(data) != "# User auth\n" {
+		t.Errorf("spec.md = %q after restore", data)
+	}
+	snapshots, _ := snapshot.List(".maestro", "003-user-auth")
+	if len(snapshots) != 2 {
+		t.Fatalf("snapshots = %v, want the original and the replaced files", snapshots)
+	}
+
+	// The feature can be found by number once its directory is gone
+	os.RemoveAll(filepath.Dir(spec))
+	if err := runSpecsRestore(specsRestoreCmd, []string{"003", filepath.Base(snapshots[1])}); err != nil {
+		t.Fatalf("specs restore of a deleted feature: %v", err)
+	}
+	if data, _ := os.ReadFile(spec); string(data) != "mangled\n" {
+		t.Errorf("spec.md = %q, want the files saved by the first restore", data)
+	}
+}
+
+// TestResolveFeatureIDRejectsPaths verifies IDs that are not a single
+// directory name are refused before they reach the file system, so
+// "specs snapshot .." can't archive all of .maestro/.
+func TestResolveFeatureIDRejectsPaths(t *testing.T) {
+	dir := t.TempDir()
+	orig := chdir(t, dir)
+	defer os.Chdir(orig)
+	os.MkdirAll(filepath.Join(".maestro", "specs", "003-user-auth"), 0755)
+
+	for _, id := range []string{"", ".", "..", "../specs", "003-user-auth/..", `..\specs`} {
+		if got, err := resolveFeatureID(filepath.Join(".maestro", "specs"), id); err == nil {
+			t.Errorf("resolveFeatureID(%q) = %q, want an error", id, got)
+		}
+		if err := runSpecsSnapshot(specsSnapshotCmd, []string{id}); err == nil {
+			t.Errorf("specs snapshot %q should fail", id)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(".maestro", "archive")); !os.IsNotExist(err) {
+		t.Errorf("a refused ID should take no snapshot: %v", err)
+	}
+	if got, err := resolveFeatureID(filepath.Join(".maestro", "specs"), "003"); err != nil || got != "003-user-auth" {
+		t.Errorf("resolveFeatureID(003) = %q, %v", got, err)
+	}
+}
+
+// TestListSpecs tests specs list joins each feature directory with its
+// state file and falls back to the directory name for the title.
+func TestListSpecs(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	for _, id := range []string{"002-export-csv", "001-user-auth", "003-broken"} {
+		os.MkdirAll(filepath.Join(dir, "specs", id), 0755)
+	}
+	os.MkdirAll(filepath.Join(dir, "state"), 0755)
+	os.WriteFile(filepath.Join(dir, "specs", "001-user-auth", "spec.md"), []byte("# Feature: User auth\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "state", "001-user-auth.json"), []byte(`{"stage":"plan","updated_at":"2026-03-01T09:30:00Z","branch":"spec/001-user-auth"}`), 0644)
+	os.WriteFile(filepath.Join(dir, "state", "003-broken.json"), []byte(`{`), 0644)
+
+	entries, err := listSpecs(dir)
+	if err != nil {
+		t.Fatalf("listSpecs() error: %v", err)
+	}
+	want := []specListEntry{
+		{ID: "001-user-auth", Title: "User auth", Stage: "plan", UpdatedAt: "2026-03-01T09:30:00Z", Branch: "spec/001-user-auth"},
+		{ID: "002-export-csv", Title: "Export csv", Stage: "no-state"},
+		{ID: "003-broken", Title: "Broken", Stage: "invalid"},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("listSpecs() = %+v, want %+v", entries, want)
+	}
+
+	var out bytes.Buffer
+	if err := writeSpecList(&out, entries); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 4 || !strings.HasPrefix(lines[0], "ID") || !strings.Contains(lines[2], "no-state") {
+		t.Errorf("table:\n%s", out.String())
+	}
+}
+
+// TestShowSpec tests specs show checks the artifacts and summarizes the
+// spec's metadata and sections.
+func TestShowSpec(t *testing.T) {
+	dir := t.TempDir()
+	maestroDir := filepath.Join(dir, ".maestro")
+	featureDir := filepath.Join(maestroDir, "specs", "001-user-auth")
+	os.MkdirAll(featureDir, 0755)
+	os.MkdirAll(filepath.Join(maestroDir, "state"), 0755)
+	os.WriteFile(filepath.Join(featureDir, "spec.md"), []byte("# Feature: User auth\n\n**Status:** Draft\n\n## 1. Problem Statement\n\nUsers cannot log in. Nothing works.\n\n## 2. User Stories\n\n### Story 1: Log in\n\n**As a** user\n"), 0644)
+	os.WriteFile(filepath.Join(featureDir, "plan.md"), []byte("# Plan\n"), 0644)
+	os.WriteFile(filepath.Join(maestroDir, "state", "001-user-auth.json"), []byte(`{"stage":"plan","branch":"spec/001-user-auth","epic_id":"bd-1"}`), 0644)
+
+	detail, err := showSpec(maestroDir, "001-user-auth")
+	if err != nil {
+		t.Fatalf("showSpec() error: %v", err)
+	}
+	if detail.Title != "User auth" || detail.Stage != "plan" || detail.EpicID != "bd-1" || detail.Metadata["Status"] != "Draft" {
+		t.Errorf("showSpec() = %+v", detail)
+	}
+	exists := map[string]bool{}
+	for _, a := range detail.Artifacts {
+		exists[a.Name] = a.Exists
+	}
+	if want := map[string]bool{"spec": true, "research": false, "plan": true, "tasks": false, "state": true}; !reflect.DeepEqual(exists, want) {
+		t.Errorf("artifacts exist = %v, want %v", exists, want)
+	}
+	want := []specSection{
+		{Heading: "Problem Statement", Summary: "Users cannot log in."},
+		{Heading: "User Stories", Subsections: []string{"Story 1: Log in"}},
+	}
+	if !reflect.DeepEqual(detail.Sections, want) {
+		t.Errorf("sections = %+v, want %+v", detail.Sections, want)
+	}
+}
+
+// TestStateUpdate verifies state update creates the state file, sets
+// fields, records the actor, and refuses a transition that skips a stage
+// unless forced.
+func TestStateUpdate(t *testing.T) {
+	dir := t.TempDir()
+	defer os.Chdir(chdir(t, dir))
+	t.Setenv("MAESTRO_MAIN_REPO", "")
+	t.Setenv("MAESTRO_ACTOR", "ci")
+	os.MkdirAll(".maestro", 0755)
+	defer func() { stateUpdateForce = false }()
+
+	if err := runStateUpdate(stateUpdateCmd, []string{"001-auth", "specify", "created", "user_stories=3", "branch=spec/001-auth"}); err != nil {
+		t.Fatalf("state update specify: %v", err)
+	}
+	if err := runStateUpdate(stateUpdateCmd, []string{"001-auth", "tasks", "skipped"}); !errors.Is(err, state.ErrTransition) {
+		t.Errorf("specify to tasks: error = %v, want ErrTransition", err)
+	}
+	if err := runStateUpdate(stateUpdateCmd, []string{"001-auth", "plan", "plan generated", "epic_id"}); err == nil {
+		t.Error("a field without = should fail")
+	}
+	stateUpdateForce = true
+	if err := runStateUpdate(stateUpdateCmd, []string{"001-auth", "tasks", "tasks created by hand"}); err != nil {
+		t.Fatalf("state update --force tasks: %v", err)
+	}
+
+	s, err := state.Load(filepath.Join(".maestro", "state", "001-auth.json"))
+	if err != nil {
+		t.Fatalf("state.Load() error: %v", err)
+	}
+	if s.Stage != "tasks" || s.Branch != "spec/001-auth" || string(s.Extra["user_stories"]) != "3" || s.SchemaVersion != state.SchemaVersion {
+		t.Errorf("state = %+v", s)
+	}
+	if len(s.History) != 2 || s.History[0].Actor != "ci" || s.History[1].Action != "tasks created by hand" {
+		t.Errorf("history = %+v", s.History)
+	}
+}
+
+// TestMergeMaestroAssetsKeepsLocalEdits verifies update previews its
+// changes and asks first, replaces untouched managed files, removes those
+// the release dropped, keeps edited ones with the new version beside them,
+// never replaces the project config, and records the new versions as the
+// base.
+// TestMergeMaestroAssetsRespectsIgnoreFile tests that update neither
+// changes nor removes the files listed in .maestro/.maestroignore, and
+// keeps their install manifest entries.
+func TestMergeMaestroAssetsRespectsIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+	orig := chdir(t, dir)
+	defer os.Chdir(orig)
+
+	scripts := filepath.Join(".maestro", "scripts")
+	os.MkdirAll(scripts, 0755)
+	os.WriteFile(filepath.Join(scripts, "deploy.sh"), []byte("deploy1\n"), 0644)
+	os.WriteFile(filepath.Join(scripts, "old.sh"), []byte("old\n"), 0644)
+	os.WriteFile(filepath.Join(scripts, "run.sh"), []byte("run1\n"), 0644)
+	configPath := filepath.Join(".maestro", "config.yaml")
+	if err := config.RecordInstall(configPath, "v1.0.0", []string{".maestro/scripts"}); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(ignore.File, []byte("deploy.sh\n.maestro/scripts/old.sh\n"), 0644)
+
+	content := map[string][]byte{
+		"scripts/deploy.sh": []byte("deploy2\n"),
+		"scripts/run.sh":    []byte("run2\n"),
+	}
+	var out bytes.Buffer
+	if applied, err := mergeMaestroAssets(".", strings.NewReader("y\n"), &out, content, "v2.0.0", report.New("update")); err != nil || !applied {
+		t.Fatalf("mergeMaestroAssets() = %v, %v", applied, err)
+	}
+	for name, want := range map[string]string{"deploy.sh": "deploy1\n", "old.sh": "old\n", "run.sh": "run2\n"} {
+		if data, _ := os.ReadFile(filepath.Join(scripts, name)); string(data) != want {
+			t.Errorf("%s = %q, want %q", name, data, want)
+		}
+	}
+	if !strings.Contains(out.String(), "Left alone by .maestro/.maestroignore: .maestro/scripts/deploy.sh, .maestro/scripts/old.sh") {
+		t.Errorf("output should list the ignored files:\n%s", out.String())
+	}
+	cfg, _ := config.Load(configPath)
+	if cfg.Installed.Files[".maestro/scripts/old.sh"] == "" || cfg.Installed.Files[".maestro/scripts/deploy.sh"] != merge.Sum([]byte("deploy1\n")) {
+		t.Errorf("ignored files should keep their manifest entries, got %v", cfg.Installed.Files)
+	}
+}
+
+func TestMergeMaestroAssetsKeepsLocalEdits(t *testing.T) {
+	dir := t.TempDir()
+	orig := chdir(t, dir)
+	defer os.Chdir(orig)
+
+	scripts := filepath.Join(".maestro", "scripts")
+	os.MkdirAll(scripts, 0755)
+	os.WriteFile(filepath.Join(scripts, "a.sh"), []byte("a1\n"), 0644)
+	os.WriteFile(filepath.Join(scripts, "b.sh"), []byte("b1\n"), 0644)
+	os.WriteFile(filepath.Join(scripts, "old.sh"), []byte("old\n"), 0644)
+	configPath := filepath.Join(".maestro", "config.yaml")
+	if err := config.RecordInstall(configPath, "v1.0.0", []string{".maestro/scripts"}); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(scripts, "b.sh"), []byte("b-mine\n"), 0644)
+
+	content := map[string][]byte{
+		"scripts/a.sh":   []byte("a2\n"),
+		"scripts/b.sh":   []byte("b2\n"),
+		"scripts/new.sh": []byte("new\n"),
+		"config.yaml":    []byte("cli_version: v2.0.0\n"),
+	}
+	var out bytes.Buffer
+	applied, err := mergeMaestroAssets(".", strings.NewReader("n\n"), &out, content, "v2.0.0", report.New("update"))
+	if err != nil || applied {
+		t.Fatalf("declined mergeMaestroAssets() = %v, %v", applied, err)
+	}
+	for _, want := range []string{
+		"Changes to .maestro/ (v1.0.0",
+		"added     .maestro/scripts/new.sh",
+		"changed   .maestro/scripts/a.sh",
+		"removed   .maestro/scripts/old.sh",
+		"conflict  .maestro/scripts/b.sh (edited locally; the new version goes to .maestro/scripts/b.sh.new)",
+		"1 added, 1 changed, 1 removed, 1 conflict(s)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("preview missing %q:\n%s", want, out.String())
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(scripts, "a.sh")); string(data) != "a1\n" {
+		t.Errorf("declining should change nothing, a.sh = %q", data)
+	}
+
+	applied, err = mergeMaestroAssets(".", strings.NewReader("y\n"), &out, content, "v2.0.0", report.New("update"))
+	if err != nil || !applied {
+		t.Fatalf("mergeMaestroAssets() = %v, %v", applied, err)
+	}
+	for name, want := range map[string]string{"a.sh": "a2\n", "b.sh": "b-mine\n", "b.sh.new": "b2\n", "new.sh": "new\n"} {
+		if data, _ := os.ReadFile(filepath.Join(scripts, name)); string(data) != want {
+			t.Errorf("%s = %q, want %q", name, data, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(scripts, "old.sh")); !os.IsNotExist(err) {
+		t.Error("old.sh was removed by the release and should be deleted")
+	}
+
+	cfg, _ := config.Load(configPath)
+	if cfg.Installed.AssetVersion != "v2.0.0" {
+		t.Errorf("config.yaml was replaced or not recorded: %+v", cfg.Installed)
+	}
+	if modified, missing := cfg.Installed.Drift("."); !reflect.DeepEqual(modified, []string{".maestro/scripts/b.sh"}) || len(missing) != 0 {
+		t.Errorf("drift = %v, %v; want the edited script only", modified, missing)
+	}
+	if _, ok := cfg.Installed.Files[".maestro/scripts/b.sh.new"]; ok {
+		t.Error(".new files should not be recorded in the manifest")
+	}
+}
+
+// TestGuardRollsBackFailedCommand verifies guard passes on a failing
+// command's exit code and, when told yes, undoes its changes to .maestro/.
+func TestGuardRollsBackFailedCommand(t *testing.T) {
+	dir := t.TempDir()
+	orig := chdir(t, dir)
+	defer os.Chdir(orig)
+
+	spec := filepath.Join(".maestro", "specs", "001-auth", "spec.md")
+	os.MkdirAll(filepath.Dir(spec), 0755)
+	os.WriteFile(spec, []byte("# Auth\n"), 0644)
+
+	nonInteractive = true
+	defer func() { nonInteractive = false }()
+	err := runGuard(guardCmd, []string{"sh", "-c", "echo mangled > " + spec + "; exit 3"})
+	var exit *exitError
+	if !errors.As(err, &exit) || exit.code != 3 {
+		t.Fatalf("runGuard() = %v, want exit status 3", err)
+	}
+	if data, _ := os.ReadFile(spec); string(data) != "# Auth\n" {
+		t.Errorf("spec.md = %q, want it rolled back", data)
+	}
+
+	if err := runGuard(guardCmd, []string{"true"}); err != nil {
+		t.Fatalf("runGuard(true) error: %v", err)
+	}
+	if snapshots, _ := guard.List("."); len(snapshots) != 1 {
+		t.Errorf("only the failed run's snapshot should be kept, got %v", snapshots)
+	}
+}
+
+// TestRollbackRestoresLastUpdate verifies update saves the project before
+// merging and that rollback restores it, agent dirs only when asked.
+func TestRollbackRestoresLastUpdate(t *testing.T) {
+	dir := t.TempDir()
+	orig := chdir(t, dir)
+	defer os.Chdir(orig)
+
+	scripts := filepath.Join(".maestro", "scripts")
+	os.MkdirAll(scripts, 0755)
+	os.WriteFile(filepath.Join(scripts, "a.sh"), []byte("a1\n"), 0644)
+	if err := config.RecordInstall(filepath.Join(".maestro", "config.yaml"), "v1.0.0", []string{".maestro/scripts"}); err != nil {
+		t.Fatal(err)
+	}
+	command := filepath.Join(".claude", "commands", "maestro.plan.md")
+	os.MkdirAll(filepath.Dir(command), 0755)
+	os.WriteFile(command, []byte("plan v1\n"), 0644)
+
+	nonInteractive = true
+	defer func() { nonInteractive, rollbackAgentDirs = false, false }()
+	if err := runRollback(rollbackCmd, nil); err == nil || !strings.Contains(err.Error(), "no update to roll back") {
+		t.Errorf("rollback before any update = %v", err)
+	}
+
+	content := map[string][]byte{"scripts/a.sh": []byte("a2\n"), "scripts/new.sh": []byte("new\n")}
+	if applied, err := mergeMaestroAssets(".", strings.NewReader(""), io.Discard, content, "v2.0.0", report.New("update")); err != nil || !applied {
+		t.Fatalf("mergeMaestroAssets() = %v, %v", applied, err)
+	}
+	cfg, _ := config.Load(filepath.Join(".maestro", "config.yaml"))
+	if s := cfg.Installed.Snapshot; s == nil || s.From != "v1.0.0" || s.To != "v2.0.0" || len(s.AgentDirs) != 1 {
+		t.Fatalf("update should record its snapshot, got %+v", s)
+	}
+	os.WriteFile(command, []byte("plan v2\n"), 0644)
+
+	if err := runRollback(rollbackCmd, nil); err != nil {
+		t.Fatalf("runRollback() error: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(scripts, "a.sh")); string(data) != "a1\n" {
+		t.Errorf("a.sh = %q, want it rolled back", data)
+	}
+	if _, err := os.Stat(filepath.Join(scripts, "new.sh")); !os.IsNotExist(err) {
+		t.Error("files the update added should be removed")
+	}
+	if data, _ := os.ReadFile(command); string(data) != "plan v2\n" {
+		t.Errorf("agent dirs should be left alone without --agent-dirs, got %q", data)
+	}
+	cfg, _ = config.Load(filepath.Join(".maestro", "config.yaml"))
+	if cfg.Installed.AssetVersion != "v1.0.0" {
+		t.Errorf("the config should be rolled back too, asset version %q", cfg.Installed.AssetVersion)
+	}
+
+	// The rolled-back config no longer points at the snapshot
+	snapshots, _ := guard.List(".")
+	if err := config.RecordSnapshot(filepath.Join(".maestro", "config.yaml"), config.UpdateSnapshot{Path: filepath.ToSlash(snapshots[0]), AgentDirs: []string{".claude"}}); err != nil {
+		t.Fatal(err)
+	}
+	rollbackAgentDirs = true
+	if err := runRollback(rollbackCmd, nil); err != nil {
+		t.Fatalf("runRollback(--agent-dirs) error: %v", err)
+	}
+	if data, _ := os.ReadFile(command); string(data) != "plan v1\n" {
+		t.Errorf("%s = %q, want it rolled back with --agent-dirs", command, data)
+	}
+}
+
+// TestShowChangelogRendersTargetNotes verifies update shows the target
+// release's notes as plain text.
+func TestShowChangelogRendersTargetNotes(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	release := &ghclient.Release{TagName: "v1.3.0", Body: "## Fixes\n- **Faster** `update`"}
+	showChangelog(&out, nil, release, "v1.2.0", report.New("update"))
+	for _, want := range []string{"Release notes for v1.3.0:", "  Fixes\n  -----", "• Faster update"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("changelog missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+// TestVersionBump tests that version bump sets the version fields in place
+// and refreshes the checksums of the files it changes.
+func TestVersionBump(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	chdir(t, dir)
+	defer os.Chdir(orig)
+
+	config := "# Maestro Configuration\ncli_version: \"v1.2.0\" # set by release\nproject:\n  name: demo\ninstalled:\n  asset_version: v1.2.0\n"
+	manifest := "version: v1.2.0\nmin_cli_version: v1.0.0\n"
+	os.MkdirAll(".maestro", 0755)
+	os.MkdirAll("dist", 0755)
+	os.WriteFile(".maestro/config.yaml", []byte(config), 0644)
+	os.WriteFile("dist/bundle.yaml", []byte(manifest), 0644)
+	os.WriteFile("dist/notes.md", []byte("notes\n"), 0644)
+	sum := func(s string) string {
+		h := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(h[:])
+	}
+	checksums := sum(manifest) + "  bundle.yaml\n" + sum("notes\n") + "  ./notes.md\n" + sum("gone") + "  gone.md\n"
+	os.WriteFile("dist/checksums.txt", []byte(checksums), 0644)
+
+	if err := runVersionBump(versionBumpCmd, []string{"v1.3.0"}); err == nil {
+		t.Error("version bump outside an assets repository should fail")
+	}
+	os.WriteFile(assetsRepoMarker, nil, 0644)
+	if err := runVersionBump(versionBumpCmd, []string{"v1.1.0"}); err == nil || !strings.Contains(err.Error(), "newer than") {
+		t.Errorf("bumping to an older version should fail, got %v", err)
+	}
+	if err := runVersionBump(versionBumpCmd, []string{"1.3"}); err == nil {
+		t.Error("an invalid version should fail")
+	}
+
+	versionBumpDryRun = true
+	defer func() { versionBumpDryRun = false }()
+	if err := runVersionBump(versionBumpCmd, []string{"v1.3.0"}); err != nil {
+		t.Fatalf("version bump --dry-run error: %v", err)
+	}
+	versionBumpDryRun = false
+	if data, _ := os.ReadFile(".maestro/config.yaml"); string(data) != config {
+		t.Error("--dry-run should not write")
+	}
+
+	if err := runVersionBump(versionBumpCmd, []string{"v1.3.0"}); err != nil {
+		t.Fatalf("version bump error: %v", err)
+	}
+	wantConfig := strings.ReplaceAll(config, "v1.2.0", "v1.3.0")
+	if data, _ := os.ReadFile(".maestro/config.yaml"); string(data) != wantConfig {
+		t.Errorf("config.yaml = %q, want %q", data, wantConfig)
+	}
+	wantManifest := "version: v1.3.0\nmin_cli_version: v1.0.0\n"
+	if data, _ := os.ReadFile("dist/bundle.yaml"); string(data) != wantManifest {
+		t.Errorf("bundle.yaml = %q, want %q", data, wantManifest)
+	}
+	wantChecksums := sum(wantManifest) + "  bundle.yaml\n" + sum("notes\n") + "  ./notes.md\n" + sum("gone") + "  gone.md\n"
+	if data, _ := os.ReadFile("dist/checksums.txt"); string(data) != wantChecksums {
+		t.Errorf("checksums.txt = %q, want %q", data, wantChecksums)
+	}
+
+	if bumps, err := planVersionBump(".", "v1.3.0"); err != nil || len(bumps) != 0 {
+		t.Errorf("a second bump should change nothing, got %v, %v", bumps, err)
+	}
+}
+
+// TestWarnDeprecations tests that deprecated commands, flags, and config
+// keys in use are warned about once, unless deprecation_warnings is false.
+func TestWarnDeprecations(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	orig, _ := os.Getwd()
+	chdir(t, dir)
+	defer os.Chdir(orig)
+	var out bytes.Buffer
+	deprecation.SetOutput(&out)
+	defer deprecation.SetOutput(os.Stderr)
+
+	deprecation.Register(deprecation.Deprecation{Kind: deprecation.Command, Name: "maestro olddeps", Since: "v1.4.0"})
+	deprecation.Register(deprecation.Deprecation{Kind: deprecation.Flag, Name: "maestro --olddeps-global", Since: "v1.4.0"})
+	deprecation.Register(deprecation.Deprecation{Kind: deprecation.Flag, Name: "maestro olddeps --unused", Since: "v1.4.0"})
+	deprecation.Register(deprecation.Deprecation{Kind: deprecation.ConfigKey, Name: "olddeps.key", Since: "v1.4.0", Replacement: "newdeps.key"})
+	deprecation.Register(deprecation.Deprecation{Kind: deprecation.ConfigKey, Name: "olddeps.unset", Since: "v1.4.0"})
+
+	root := &cobra.Command{Use: "maestro"}
+	root.PersistentFlags().Bool("olddeps-global", false, "")
+	sub := &cobra.Command{Use: "olddeps"}
+	sub.Flags().Bool("unused", false, "")
+	root.AddCommand(sub)
+	if err := sub.ParseFlags([]string{"--olddeps-global"}); err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(".maestro", 0755)
+	os.WriteFile(".maestro/config.yaml", []byte("olddeps:\n  key: x\n"), 0644)
+
+	warnDeprecations(sub)
+	warnDeprecations(sub)
+	got := out.String()
+	for _, want := range []string{"command maestro olddeps", "flag maestro --olddeps-global", "config key olddeps.key is deprecated since v1.4.0; use newdeps.key instead"} {
+		if strings.Count(got, want) != 1 {
+			t.Errorf("want one warning containing %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "--unused") || strings.Contains(got, "olddeps.unset") {
+		t.Errorf("unused deprecations should not warn, got:\n%s", got)
+	}
+
+	var list bytes.Buffer
+	if err := writeDeprecations(&list, "text", deprecation.All()); err != nil || !strings.Contains(list.String(), "olddeps.key") {
+		t.Errorf("writeDeprecations() = %q, %v", list.String(), err)
+	}
+	list.Reset()
+	if err := writeDeprecations(&list, "text", nil); err != nil || !strings.HasPrefix(list.String(), "Nothing is deprecated") {
+		t.Errorf("writeDeprecations() with none = %q, %v", list.String(), err)
+	}
+
+	deprecation.Register(deprecation.Deprecation{Kind: deprecation.Command, Name: "maestro quietdeps", Since: "v1.4.0"})
+	defer deprecation.Suppress(false)
+	t.Setenv("MAESTRO_DEPRECATION_WARNINGS", "false")
+	out.Reset()
+	quiet := &cobra.Command{Use: "quietdeps"}
+	root.AddCommand(quiet)
+	warnDeprecations(quiet)
+	if out.Len() != 0 {
+		t.Errorf("deprecation_warnings: false should hide warnings, got %q", out.String())
+	}
+}
+
+// TestFormatFlagAlias tests that the renamed --format flags still set the
+// output format, and warn as deprecated.
+func TestFormatFlagAlias(t *testing.T) {
+	var out bytes.Buffer
+	deprecation.SetOutput(&out)
+	defer deprecation.SetOutput(os.Stderr)
+	defer func() {
+		specsListOutput = "table"
+		specsListCmd.Flags().Lookup("format").Changed = false
+	}()
+
+	if err := specsListCmd.ParseFlags([]string{"--format", "json"}); err != nil {
+		t.Fatal(err)
+	}
+	if specsListOutput != "json" {
+		t.Errorf("--format json set the output to %q", specsListOutput)
+	}
+	warnDeprecations(specsListCmd)
+	if !strings.Contains(out.String(), "the flag maestro specs list --format is deprecated; use --output instead") {
+		t.Errorf("want a deprecation warning, got %q", out.String())
+	}
+}
+
+// TestCommandsNew tests that commands new writes the command to
+// .maestro/commands/ and each installed agent directory, and refuses to
+// overwrite one without --force.
+func TestCommandsNew(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	orig, _ := os.Getwd()
+	chdir(t, dir)
+	defer os.Chdir(orig)
+	defer func() {
+		commandsNewScripts, commandsNewArgumentHint, commandsNewForce = nil, "", false
+	}()
+
+	if err := runCommandsNew(commandsNewCmd, []string{"review"}); err == nil {
+		t.Error("commands new outside a maestro project should fail")
+	}
+	os.MkdirAll(".maestro/scripts", 0755)
+	os.MkdirAll(".claude/commands", 0755)
+	os.MkdirAll(".codex/commands", 0755)
+	os.WriteFile(".maestro/scripts/check.sh", []byte("#!/bin/bash\n"), 0755)
+
+	if err := runCommandsNew(commandsNewCmd, []string{"Review!"}); err == nil {
+		t.Error("an invalid command name should fail")
+	}
+	commandsNewScripts = []string{"missing"}
+	if err := runCommandsNew(commandsNewCmd, []string{"review"}); err == nil {
+		t.Error("a script missing from .maestro/scripts/ should fail")
+	}
+
+	commandsNewScripts = []string{"check"}
+	commandsNewArgumentHint = "<feature-id>"
+	if err := runCommandsNew(commandsNewCmd, []string{"maestro.review"}); err != nil {
+		t.Fatalf("commands new error: %v", err)
+	}
+	for _, p := range []string{".maestro/commands/maestro.review.md", ".claude/commands/maestro.review.md", ".codex/commands/maestro.review.md", ".codex/skills/maestro-review/SKILL.md"} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("%s should be created: %v", p, err)
+		}
+	}
+	data, _ := os.ReadFile(".claude/commands/maestro.review.md")
+	for _, want := range []string{"argument-hint: <feature-id>\n", "## Step 2: Parse Arguments", "## Step 3: Run check.sh", "bash .maestro/scripts/check.sh $ARGUMENTS", "## Step 4: Report"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("the command file should contain %q, got:\n%s", want, data)
+		}
+	}
+
+	os.WriteFile(".claude/commands/maestro.review.md", []byte("edited"), 0644)
+	if err := runCommandsNew(commandsNewCmd, []string{"review"}); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("an existing command should not be overwritten without --force, got %v", err)
+	}
+	commandsNewForce = true
+	if err := runCommandsNew(commandsNewCmd, []string{"review"}); err != nil {
+		t.Fatalf("commands new --force error: %v", err)
+	}
+	if data, _ := os.ReadFile(".claude/commands/maestro.review.md"); string(data) == "edited" {
+		t.Error("--force should overwrite the command")
+	}
+}
+
+// TestLintLinks tests that lint links checks the workflow markdown of the
+// project, or only the files given.
+func TestLintLinks(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	chdir(t, dir)
+	defer os.Chdir(orig)
+
+	if err := runLintLinks(lintLinksCmd, nil); err == nil {
+		t.Error("lint links outside a maestro project should fail")
+	}
+	os.MkdirAll(".maestro/specs/001-x", 0755)
+	os.MkdirAll(".maestro/scripts", 0755)
+	os.MkdirAll(".maestro/templates", 0755)
+	os.MkdirAll(".claude/commands", 0755)
+	os.WriteFile(".maestro/scripts/run.sh", nil, 0755)
+	os.WriteFile(".maestro/specs/001-x/spec.md", []byte("See [the plan](plan.md).\n"), 0644)
+	os.WriteFile(".maestro/templates/plan-template.md", []byte("[spec](spec.md)\n"), 0644)
+	os.WriteFile(".claude/commands/maestro.run.md", []byte("bash .maestro/scripts/run.sh\n"), 0644)
+	os.WriteFile("AGENTS.md", []byte("Specs live in [.maestro/specs](.maestro/specs/001-x/spec.md).\n"), 0644)
+
+	err := runLintLinks(lintLinksCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "1 broken reference(s) in 1 of 3 file(s)") {
+		t.Errorf("want the missing plan reported, got %v", err)
+	}
+	os.WriteFile(".maestro/specs/001-x/plan.md", nil, 0644)
+	if err := runLintLinks(lintLinksCmd, nil); err != nil {
+		t.Errorf("lint links error: %v", err)
+	}
+
+	os.Rename(".maestro/scripts/run.sh", ".maestro/scripts/renamed.sh")
+	if err := runLintLinks(lintLinksCmd, []string{"AGENTS.md"}); err != nil {
+		t.Errorf("only the files given should be checked, got %v", err)
+	}
+	if err := runLintLinks(lintLinksCmd, []string{".claude"}); err == nil {
+		t.Error("a reference to a renamed script should be reported")
+	}
+}
+
+// TestVersionOutput tests that version --output extracts fields with a
+// template or a JSONPath expression.
+func TestVersionOutput(t *testing.T) {
+	dir := t.TempDir()
+	defer os.Chdir(chdir(t, dir))
+	os.MkdirAll(".maestro", 0755)
+	os.WriteFile(filepath.Join(".maestro", "config.yaml"), []byte("cli_version: v1.2.0\n"), 0644)
+	defer func() { versionOutput = "text" }()
+
+	run := func(format string) string {
+		t.Helper()
+		versionOutput = format
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdout := os.Stdout
+		os.Stdout = w
+		runErr := runVersion(versionCmd, nil)
+		os.Stdout = stdout
+		w.Close()
+		out, _ := io.ReadAll(r)
+		if runErr != nil {
+			t.Fatalf("version --output %s: %v", format, runErr)
+		}
+		return string(out)
+	}
+	if got := run("template={{.version}}"); got != version.Version+"\n" {
+		t.Errorf("template: got %q", got)
+	}
+	if got := run("jsonpath={.project.cli_version}"); got != "v1.2.0\n" {
+		t.Errorf("jsonpath: got %q", got)
+	}
+	if got := run("json"); !strings.Contains(got, `"cli_version": "v1.2.0"`) {
+		t.Errorf("json: got %q", got)
+	}
+
+	versionOutput = "jsonpath={.project"
+	if err := runVersion(versionCmd, nil); err == nil {
+		t.Error("an invalid expression should be an error")
 	}
 }
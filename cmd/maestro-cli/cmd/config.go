@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/config"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect maestro configuration",
+}
+
+var configResolveCmd = &cobra.Command{
+	Use:   "resolve <key>",
+	Short: "Print the effective value of a config key",
+	Long: `Prints the value of a dotted config key (e.g. sync.remote) after merging,
+highest precedence first:
+
+  1. environment override   MAESTRO_<KEY>, e.g. MAESTRO_SYNC_REMOTE
+  2. project config         .maestro/config.yaml
+  3. global config          ~/.config/maestro/config.yaml, or
+                            maestro-data/config/config.yaml next to the
+                            binary with --portable
+  4. built-in default       ` + strings.Join(config.DefaultKeys(), ", ") + `
+
+Mappings and lists are printed as YAML. Exits with status 1 when no source
+defines the key, so scripts can supply their own fallback:
+
+  base=$(maestro config resolve project.base_branch)`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigResolve,
+}
+
+var configResolveShowSource bool
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configResolveCmd)
+	configResolveCmd.Flags().BoolVar(&configResolveShowSource, "show-source", false, "Also print where the value came from")
+	addProjectPathFlag(configResolveCmd, true)
+}
+
+func runConfigResolve(cmd *cobra.Command, args []string) error {
+	resolved, err := projectResolver(workDir(cmd)).Resolve(args[0])
+	if errors.Is(err, config.ErrKeyNotFound) {
+		return fmt.Errorf("%s is not set (override with %s)", args[0], config.EnvVar(args[0]))
+	}
+	if err != nil {
+		return fmt.Errorf("resolving %s: %w", args[0], err)
+	}
+
+	if configResolveShowSource {
+		fmt.Printf("%s\t(%s)\n", resolved.Value, resolved.Source)
+		return nil
+	}
+	fmt.Println(resolved.Value)
+	return nil
+}
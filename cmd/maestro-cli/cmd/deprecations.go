@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/spec-maestro/maestro-cli/internal/version"
+	"github.com/spec-maestro/maestro-cli/pkg/config"
+	"github.com/spec-maestro/maestro-cli/pkg/deprecation"
+	"github.com/spec-maestro/maestro-cli/pkg/output"
+)
+
+// deprecations lists the deprecated parts of the CLI. Add an entry when a
+// command, flag, or config key is deprecated, and keep it until the release
+// that removes it; names follow deprecation.Deprecation.
+var deprecations = []deprecation.Deprecation{
+	{Kind: deprecation.Flag, Name: "maestro doctor --format", Replacement: "--output"},
+	{Kind: deprecation.Flag, Name: "maestro remove --format", Replacement: "--output"},
+	{Kind: deprecation.Flag, Name: "maestro specs list --format", Replacement: "--output"},
+	{Kind: deprecation.Flag, Name: "maestro specs show --format", Replacement: "--output"},
+	{Kind: deprecation.Flag, Name: "maestro uninstall --format", Replacement: "--output"},
+}
+
+var deprecationsCmd = &cobra.Command{
+	Use:   "deprecations",
+	Short: "List deprecated commands, flags, and config keys",
+	Long:  "Lists the commands, flags, and config keys this maestro deprecates, since when, when they are expected to be removed, and what replaces them. Using one prints a warning once per invocation; set deprecation_warnings: false to hide them.",
+	Args:  cobra.NoArgs,
+	RunE:  runDeprecations,
+}
+
+var deprecationsOutput string
+
+func init() {
+	for _, d := range deprecations {
+		deprecation.Register(d)
+	}
+	rootCmd.AddCommand(deprecationsCmd)
+	deprecationsCmd.Flags().StringVar(&deprecationsOutput, "output", "text", dataFormatUsage)
+}
+
+// addFlagAlias adds the hidden flag alias to cmd, setting the same value as
+// its flag name, for a flag that was renamed. List the alias in
+// deprecations so using it warns.
+func addFlagAlias(cmd *cobra.Command, alias, name string) {
+	f := cmd.Flags().Lookup(name)
+	cmd.Flags().AddFlag(&pflag.Flag{Name: alias, Usage: "Deprecated: use --" + name, Value: f.Value, DefValue: f.DefValue, Hidden: true})
+}
+
+func runDeprecations(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(deprecationsOutput); err != nil {
+		return err
+	}
+	return writeDeprecations(os.Stdout, deprecationsOutput, deprecation.All())
+}
+
+// writeDeprecations renders the deprecations in format.
+func writeDeprecations(w io.Writer, format string, all []deprecation.Deprecation) error {
+	if output.Data(format) {
+		return output.Write(w, format, struct {
+			Deprecations []deprecation.Deprecation `json:"deprecations"`
+		}{append([]deprecation.Deprecation{}, all...)})
+	}
+
+	if len(all) == 0 {
+		_, err := fmt.Fprintf(w, "Nothing is deprecated in maestro %s.\n", version.Version)
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tNAME\tSINCE\tREMOVED IN\tREPLACEMENT")
+	for _, d := range all {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", d.Kind, d.Name, orDash(d.Since), orDash(d.RemovedIn), orDash(d.Replacement))
+	}
+	return tw.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// warnDeprecations warns about the deprecated commands, flags, and config
+// keys the invocation of cmd uses, unless deprecation_warnings is false.
+// Shell completion never warns.
+func warnDeprecations(cmd *cobra.Command) {
+	if cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd {
+		return
+	}
+	resolver := projectResolver(workDir(cmd))
+	value, _ := resolver.String("deprecation_warnings", "true")
+	if on, err := strconv.ParseBool(value); err == nil && !on {
+		deprecation.Suppress(true)
+		return
+	}
+
+	for c := cmd; c != nil; c = c.Parent() {
+		deprecation.Warn(deprecation.Command, c.CommandPath())
+	}
+	// A persistent flag is named after the command that defines it
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		for c := cmd; c != nil; c = c.Parent() {
+			if deprecation.Warn(deprecation.Flag, c.CommandPath()+" --"+f.Name) {
+				return
+			}
+		}
+	})
+	for _, d := range deprecation.All() {
+		if d.Kind != deprecation.ConfigKey {
+			continue
+		}
+		if resolved, err := resolver.Resolve(d.Name); err == nil && resolved.Source != config.SourceDefault {
+			deprecation.Warn(deprecation.ConfigKey, d.Name)
+		}
+	}
+}
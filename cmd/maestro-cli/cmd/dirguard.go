@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+)
+
+// allowUnsafeDir skips the confirmation guardTargetDir asks for.
+var allowUnsafeDir bool
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&allowUnsafeDir, "allow-unsafe-dir", false, "Allow writing .maestro/ as root into another user's directory, or into the filesystem root, home directory, or a system directory")
+}
+
+// systemDirs are directories no project lives in directly.
+var systemDirs = []string{
+	"/bin", "/boot", "/dev", "/etc", "/home", "/lib", "/opt", "/proc", "/root",
+	"/sbin", "/sys", "/tmp", "/usr", "/var",
+	"/Applications", "/Library", "/System", "/Users",
+}
+
+// unsafeTargetReasons explains why writing .maestro/ into dir is probably a
+// mistake: dir is the filesystem root, the home directory, or a system
+// directory, or the command runs as root in a directory owned by another
+// user, leaving files that user cannot edit. It returns nil when dir looks
+// like a normal project directory.
+func unsafeTargetReasons(dir string) []string {
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+
+	var reasons []string
+	switch {
+	case filepath.Dir(dir) == dir:
+		reasons = append(reasons, dir+" is the filesystem root")
+	case isHomeDir(dir):
+		reasons = append(reasons, dir+" is your home directory, not a project")
+	default:
+		for _, sys := range systemDirs {
+			if filepath.FromSlash(sys) == dir {
+				reasons = append(reasons, dir+" is a system directory")
+			}
+		}
+	}
+
+	if os.Geteuid() == 0 {
+		if info, err := os.Stat(dir); err == nil {
+			if uid, ok := fileOwner(info); ok && uid != 0 {
+				reasons = append(reasons, fmt.Sprintf("running as root in a directory owned by uid %d; the files would be owned by root", uid))
+			}
+		}
+	}
+	return reasons
+}
+
+func isHomeDir(dir string) bool {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return false
+	}
+	if resolved, err := filepath.EvalSymlinks(home); err == nil {
+		home = resolved
+	}
+	return home == dir
+}
+
+// guardTargetDir asks for confirmation before a command writes .maestro/
+// into the directory it works in when it looks like the wrong place (see
+// unsafeTargetReasons). Without a terminal to ask, or with --yes, it
+// refuses unless --allow-unsafe-dir is set: --yes answers routine
+// questions, not this one.
+func guardTargetDir(cmd *cobra.Command) error {
+	dir, err := filepath.Abs(workDir(cmd))
+	if err != nil {
+		return err
+	}
+	reasons := unsafeTargetReasons(dir)
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	command := cmd.CommandPath()
+	if allowUnsafeDir {
+		fmt.Fprintf(os.Stderr, "Warning: running '%s' in %s: %s\n", command, dir, strings.Join(reasons, "; "))
+		return nil
+	}
+	refusal := fmt.Errorf("refusing to run '%s' in %s: %s; cd into your project or use --path, or rerun with --allow-unsafe-dir if you really mean it", command, dir, strings.Join(reasons, "; "))
+	if nonInteractive || unattended {
+		return refusal
+	}
+
+	fmt.Fprintf(os.Stderr, "%s '%s' would write .maestro/ in %s:\n", glyph.Warn(), command, dir)
+	for _, reason := range reasons {
+		fmt.Fprintf(os.Stderr, "  - %s\n", reason)
+	}
+	fmt.Fprint(os.Stderr, "Type 'yes' to continue: ")
+	answer, err := bufio.NewReader(promptInput(os.Stdin)).ReadString('\n')
+	if err != nil || strings.TrimSpace(strings.ToLower(answer)) != "yes" {
+		return refusal
+	}
+	return nil
+}
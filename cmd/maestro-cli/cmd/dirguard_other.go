@@ -0,0 +1,10 @@
+//go:build !unix
+
+package cmd
+
+import "os"
+
+// fileOwner is not available on this platform.
+func fileOwner(info os.FileInfo) (int, bool) {
+	return 0, false
+}
@@ -5,8 +5,16 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/spec-maestro/maestro-cli/internal/version"
 	"github.com/spec-maestro/maestro-cli/pkg/agents"
+	"github.com/spec-maestro/maestro-cli/pkg/config"
+	"github.com/spec-maestro/maestro-cli/pkg/doctor"
+	"github.com/spec-maestro/maestro-cli/pkg/envreport"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
 	"github.com/spf13/cobra"
 )
 
@@ -25,61 +33,295 @@ var requiredMaestroDirs = []string{
 var doctorCmd = &cobra.Command{
 	Use:   "doctor",
 	Short: "Validate your maestro project setup",
-	Long:  "Checks the .maestro/ directory structure and reports any issues with remediation steps.",
+	Long:  "Checks the .maestro/ directory structure and reports any issues with remediation steps. The checks the project declares in " + doctor.ProjectFile + " (files that must exist, commands that must succeed, and minimum tool versions) run after the built-in ones.",
 	RunE:  runDoctor,
 }
 
+var (
+	doctorEmitFixes string
+	doctorEnvReport bool
+	doctorDeep      bool
+	doctorNetwork   bool
+	doctorStrict    bool
+	doctorIgnore    []string
+	doctorFeature   string
+	doctorOutput    string
+)
+
 func init() {
 	rootCmd.AddCommand(doctorCmd)
+	addProjectPathFlag(doctorCmd, true)
+	doctorCmd.Flags().BoolVar(&doctorEnvReport, "env-report", false, envReportUsage)
+	doctorCmd.Flags().BoolVar(&doctorDeep, "deep", false, "Also validate file contents: script syntax, template and frontmatter parsing, and state JSON")
+	doctorCmd.Flags().BoolVar(&doctorNetwork, "network", false, "Also check that GitHub is reachable, which token is used, and the API quota left")
+	doctorCmd.Flags().StringVar(&doctorFeature, "feature", "", "Report on one feature instead: its spec, artifacts, state, and readiness for the next stage (directory name or number)")
+	doctorCmd.Flags().BoolVar(&doctorStrict, "strict", false, "Treat warnings as failures, for CI")
+	doctorCmd.Flags().StringArrayVar(&doctorIgnore, "ignore", nil, "Skip a check, or drop a result, by name (repeatable)")
+	doctorCmd.Flags().StringVar(&doctorEmitFixes, "emit-fixes", "", "Write the commands that fix the failed checks to this shell script (- for stdout)")
+	doctorCmd.Flags().StringVar(&doctorOutput, "output", "text", "Output format: text, json, junit, template=<go template>, or jsonpath=<expression> (all but text move other output to stderr)")
+	addFlagAlias(doctorCmd, "format", "output")
 }
 
-type checkResult struct {
-	name    string
-	ok      bool
-	message string
-	fix     string
-	isWarn  bool // true if this is a warning (doesn't affect exit code)
+func init() {
+	doctor.Register(doctor.Func("project structure", func(ctx doctor.Context) []doctor.Result {
+		return projectStructureChecks(ctx.MaestroDir)
+	}))
+	doctor.Register(doctor.Func("config file", func(ctx doctor.Context) []doctor.Result {
+		return configChecks(filepath.Join(ctx.MaestroDir, "config.yaml"), version.Version)
+	}))
+	doctor.Register(doctor.Func("scripts", func(ctx doctor.Context) []doctor.Result {
+		return scriptChecks(ctx.MaestroDir)
+	}))
+	doctor.Register(doctor.Func("state files", func(ctx doctor.Context) []doctor.Result {
+		return stateChecks(ctx.MaestroDir)
+	}))
+	doctor.Register(doctor.Func("system dependencies", func(doctor.Context) []doctor.Result {
+		return systemDependencyChecks()
+	}))
+	doctor.Register(doctor.Func("git", func(ctx doctor.Context) []doctor.Result {
+		return gitChecks(ctx.Root, ctx.MaestroDir)
+	}))
+	doctor.Register(doctor.Func("agent directories", func(ctx doctor.Context) []doctor.Result {
+		return agentDirChecks(ctx.Root)
+	}))
+	doctor.Register(doctor.Func("agent CLIs", func(ctx doctor.Context) []doctor.Result {
+		return agentCLIChecks(ctx.Root, envreport.System())
+	}))
+	doctor.Register(doctor.Func("agent compatibility", func(ctx doctor.Context) []doctor.Result {
+		return agentCompatChecks(ctx.Root, ctx.MaestroDir)
+	}))
+	doctor.Register(doctor.Func("installed files", func(ctx doctor.Context) []doctor.Result {
+		return manifestChecks(ctx.Root, filepath.Join(ctx.MaestroDir, "config.yaml"))
+	}))
+	doctor.Register(doctor.Func("last update", func(ctx doctor.Context) []doctor.Result {
+		return lastUpdateChecks(filepath.Join(ctx.MaestroDir, "config.yaml"), ctx.Now)
+	}))
+	doctor.Register(doctor.Func("file contents", func(ctx doctor.Context) []doctor.Result {
+		if !ctx.Deep {
+			return nil
+		}
+		return deepChecks(ctx.MaestroDir)
+	}))
+	doctor.Register(doctor.Func("network", func(ctx doctor.Context) []doctor.Result {
+		if !ctx.Network {
+			return nil
+		}
+		return networkResults(probeNetwork(ctx.MaestroDir))
+	}))
 }
 
 func runDoctor(cmd *cobra.Command, args []string) error {
-	maestroDir := ".maestro"
-	results := []checkResult{}
+	root := workDir(cmd)
+	maestroDir := filepath.Join(root, ".maestro")
+
+	if err := doctor.ValidateFormat(doctorOutput); err != nil {
+		return err
+	}
+	if doctorOutput != "text" && doctorEmitFixes == "-" {
+		return fmt.Errorf("--emit-fixes - cannot be combined with --output %s, as both write to stdout", doctorOutput)
+	}
+	// Machine-readable results go to stdout alone
+	stdout := os.Stdout
+	if doctorOutput != "text" {
+		os.Stdout = os.Stderr
+		defer func() { os.Stdout = stdout }()
+	}
+
+	if doctorEnvReport {
+		printEnvReport()
+	}
 
 	// Check .maestro/ directory exists
 	if _, err := os.Stat(maestroDir); os.IsNotExist(err) {
-		fmt.Println("✗ .maestro/ directory not found")
-		fmt.Println("  Fix: Run 'maestro init' to initialize this project")
+		missing := []doctor.Result{{Name: ".maestro/ directory", Message: "not found", Fix: "Run 'maestro init' to initialize this project", Commands: []string{"maestro init"}}}
+		// Init failures are often network failures
+		var network []doctor.Result
+		if doctorNetwork {
+			network = networkResults(probeNetwork(maestroDir))
+		}
+		if doctorOutput == "text" {
+			fmt.Printf("%s .maestro/ directory not found\n", glyph.Fail())
+			fmt.Println("  Fix: Run 'maestro init' to initialize this project")
+			if err := doctor.Write(stdout, doctorOutput, network); err != nil {
+				return err
+			}
+		} else if err := doctor.Write(stdout, doctorOutput, append(missing, network...)); err != nil {
+			return err
+		}
+		if doctorEmitFixes != "" {
+			if err := emitFixScript(cmd, doctorEmitFixes, missing); err != nil {
+				return err
+			}
+		}
 		return fmt.Errorf("project not initialized")
 	}
-	results = append(results, checkResult{
-		name: ".maestro/ directory", ok: true, message: "found",
-	})
+
+	checks := append(doctor.Checks(), projectChecks(root)...)
+	if doctorFeature != "" {
+		featureID, err := resolveFeatureID(filepath.Join(maestroDir, "specs"), doctorFeature)
+		if err != nil {
+			return err
+		}
+		checks = []doctor.Check{doctor.Func("feature", func(ctx doctor.Context) []doctor.Result {
+			return featureChecks(ctx.MaestroDir, featureID)
+		})}
+	}
+	checks, unmatched := doctor.Ignore(checks, doctorIgnore)
+	results := doctor.RunChecks(doctor.Context{Root: root, MaestroDir: maestroDir, Deep: doctorDeep, Network: doctorNetwork, Now: time.Now()}, checks)
+	results, unmatched = doctor.IgnoreResults(results, unmatched)
+	for _, name := range unmatched {
+		fmt.Fprintf(os.Stderr, "%s --ignore %q matched no check or result\n", glyph.Warn(), name)
+	}
+	if doctorStrict {
+		results = doctor.Strict(results)
+	}
+	if err := doctor.Write(stdout, doctorOutput, results); err != nil {
+		return err
+	}
+	if doctorEmitFixes != "" {
+		if err := emitFixScript(cmd, doctorEmitFixes, results); err != nil {
+			return err
+		}
+	}
+	if doctor.Passed(results) {
+		fmt.Printf("\n%s All checks passed — project looks healthy!\n", glyph.OK())
+		return nil
+	}
+	return fmt.Errorf("some checks failed")
+}
+
+// projectChecks returns the checks the project declares in
+// .maestro/doctor.yaml, or a failing check naming the error when the file
+// is invalid.
+func projectChecks(root string) []doctor.Check {
+	checks, err := doctor.LoadProject(root)
+	if err == nil {
+		return checks
+	}
+	path := filepath.Join(root, doctor.ProjectFile)
+	return []doctor.Check{doctor.Func(doctor.ProjectFile, func(doctor.Context) []doctor.Result {
+		return []doctor.Result{{
+			Name:    pathfmt.Rel(path),
+			Path:    path,
+			Message: err.Error(),
+			Fix:     "Fix the check in " + doctor.ProjectFile + "; its checks did not run",
+		}}
+	})}
+}
+
+// projectStructureChecks verifies the required .maestro/ files and directories.
+func projectStructureChecks(maestroDir string) []doctor.Result {
+	results := []doctor.Result{{
+		Name: pathfmt.Rel(maestroDir + "/"), OK: true, Message: "found", Path: maestroDir + "/",
+	}}
 
 	// Check required files
 	for _, file := range requiredMaestroFiles {
 		path := filepath.Join(maestroDir, file)
 		_, err := os.Stat(path)
-		results = append(results, checkResult{
-			name:    file,
-			ok:      err == nil,
-			message: map[bool]string{true: "found", false: "missing"}[err == nil],
-			fix:     fmt.Sprintf("Run 'maestro init' to restore %s", file),
+		results = append(results, doctor.Result{
+			Name:     pathfmt.Rel(path),
+			OK:       err == nil,
+			Message:  map[bool]string{true: "found", false: "missing"}[err == nil],
+			Fix:      fmt.Sprintf("Run 'maestro init' to restore %s", pathfmt.Rel(path)),
+			Commands: []string{"maestro init --yes --conflict-action merge"},
+			Path:     path,
 		})
 	}
 
 	// Check required directories
 	for _, dir := range requiredMaestroDirs {
-		path := filepath.Join(maestroDir, dir)
+		path := filepath.Join(maestroDir, dir) + "/"
 		_, err := os.Stat(path)
-		results = append(results, checkResult{
-			name:    dir + "/",
-			ok:      err == nil,
-			message: map[bool]string{true: "found", false: "missing"}[err == nil],
-			fix:     fmt.Sprintf("Run 'maestro init' to restore %s/", dir),
+		results = append(results, doctor.Result{
+			Name:     pathfmt.Rel(path),
+			OK:       err == nil,
+			Message:  map[bool]string{true: "found", false: "missing"}[err == nil],
+			Fix:      fmt.Sprintf("Run 'maestro init' to restore %s", pathfmt.Rel(path)),
+			Commands: restoreDirCommands(dir),
+			Path:     path,
 		})
 	}
 
-	// Check system dependencies on PATH
+	return results
+}
+
+// configChecks validates config.yaml against the keys maestro knows and
+// compares its cli_version with the running maestro, cliVersion.
+func configChecks(configPath, cliVersion string) []doctor.Result {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		// A missing config.yaml is reported with the project structure
+		return nil
+	}
+	unknown, err := config.Validate(data)
+	if err != nil {
+		return []doctor.Result{{
+			Name:    "config schema",
+			Message: err.Error(),
+			Fix:     "Fix the value in " + pathfmt.Rel(configPath) + "; maestro cannot read the config until then",
+			Files:   []string{configPath},
+		}}
+	}
+	schema := doctor.Result{Name: "config schema", OK: true, Message: "valid"}
+	if len(unknown) > 0 {
+		keys := make([]string, 0, len(unknown))
+		for _, key := range unknown {
+			keys = append(keys, key.String())
+		}
+		if len(keys) > 5 {
+			keys = append(keys[:5:5], "...")
+		}
+		schema.OK = false
+		schema.Warn = true
+		schema.Message = fmt.Sprintf("%d unknown key(s): %s", len(unknown), strings.Join(keys, ", "))
+		schema.Fix = "Check the keys in " + pathfmt.Rel(configPath) + " for typos; maestro ignores keys it does not know"
+		schema.Files = []string{configPath}
+	}
+	results := []doctor.Result{schema}
+
+	cfg, err := config.Load(configPath)
+	if err != nil || cfg.CLIVersion == "" || cliVersion == "dev" {
+		return results
+	}
+	check := doctor.Result{Name: "cli_version", OK: true, Warn: true}
+	older, err := version.Less(cfg.CLIVersion, cliVersion)
+	newer, newerErr := version.Less(cliVersion, cfg.CLIVersion)
+	switch {
+	case err != nil || newerErr != nil:
+		check.OK = false
+		check.Message = fmt.Sprintf("cannot compare %q with maestro %s", cfg.CLIVersion, cliVersion)
+		check.Fix = "Run 'maestro update' to record a valid cli_version"
+		check.Commands = []string{"maestro update --yes"}
+	case older:
+		check.OK = false
+		check.Message = fmt.Sprintf("project is at %s, maestro is %s", cfg.CLIVersion, cliVersion)
+		check.Fix = fmt.Sprintf("Run 'maestro update' to bring the project's commands and templates to %s", cliVersion)
+		check.Commands = []string{"maestro update --yes --version " + shellQuote(cliVersion)}
+	case newer:
+		check.OK = false
+		check.Message = fmt.Sprintf("project is at %s, newer than maestro %s", cfg.CLIVersion, cliVersion)
+		check.Fix = fmt.Sprintf("Install maestro %s or newer; this maestro may not understand the project's files", cfg.CLIVersion)
+	default:
+		check.Message = fmt.Sprintf("%s matches maestro", cfg.CLIVersion)
+	}
+	return append(results, check)
+}
+
+// restoreDirCommands returns the commands that restore a required .maestro/
+// directory: user data directories start empty, and starter directories
+// are fetched again.
+func restoreDirCommands(dir string) []string {
+	for _, name := range starterDirNames() {
+		if name == dir {
+			return []string{"maestro scripts update " + dir}
+		}
+	}
+	return []string{"mkdir -p " + shellQuote(filepath.ToSlash(filepath.Join(".maestro", dir)))}
+}
+
+// systemDependencyChecks verifies the external tools maestro scripts rely on.
+func systemDependencyChecks() []doctor.Result {
 	type sysDep struct {
 		name        string
 		installHint string
@@ -108,67 +350,117 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 		},
 	}
 
+	results := []doctor.Result{}
 	for _, dep := range sysDeps {
 		_, err := exec.LookPath(dep.name)
 		if err == nil {
-			results = append(results, checkResult{
-				name:    dep.name + " (system)",
-				ok:      true,
-				message: "found on PATH",
+			results = append(results, doctor.Result{
+				Name:    dep.name + " (system)",
+				OK:      true,
+				Message: "found on PATH",
 			})
 		} else {
-			results = append(results, checkResult{
-				name:    dep.name + " (system)",
-				ok:      false,
-				message: "not found",
-				fix:     dep.installHint,
-				isWarn:  !dep.isRequired,
-			})
+			result := doctor.Result{
+				Name:    dep.name + " (system)",
+				OK:      false,
+				Message: "not found",
+				Fix:     dep.installHint,
+				Warn:    !dep.isRequired,
+			}
+			// bd is not packaged; its hint links to the install instructions
+			if install := packageInstallCommand(dep.name); install != "" && dep.name != "bd" {
+				result.Commands = []string{install}
+			}
+			results = append(results, result)
 		}
 	}
 
-	// Check optional agent directories (warnings only)
+	return results
+}
+
+// agentDirChecks reports which optional agent directories are installed.
+func agentDirChecks(projectRoot string) []doctor.Result {
 	knownAgentDirs := agents.KnownAgentDirs()
-	installedAgentDirs := agents.DetectInstalled(".")
+	installedAgentDirs := agents.DetectInstalled(projectRoot)
 	installedMap := make(map[string]bool)
 	for _, dir := range installedAgentDirs {
 		installedMap[dir] = true
 	}
 
+	results := []doctor.Result{}
 	for _, dir := range knownAgentDirs {
 		isInstalled := installedMap[dir]
-		results = append(results, checkResult{
-			name:    dir + "/",
-			ok:      isInstalled,
-			message: map[bool]string{true: "found (optional)", false: "not found (optional)"}[isInstalled],
-			fix:     fmt.Sprintf("Optional: Run 'maestro init' to add %s/ agent directory", dir),
-			isWarn:  true, // Mark as warning, doesn't affect exit code
+		path := filepath.Join(projectRoot, dir) + "/"
+		results = append(results, doctor.Result{
+			Name:     pathfmt.Rel(path),
+			Path:     path,
+			OK:       isInstalled,
+			Message:  map[bool]string{true: "found (optional)", false: "not found (optional)"}[isInstalled],
+			Fix:      fmt.Sprintf("Optional: Run 'maestro init' to add %s/ agent directory", dir),
+			Commands: []string{fmt.Sprintf("maestro init --yes --conflict-action merge --with-%s", strings.TrimPrefix(dir, "."))},
+			Warn:     true, // Mark as warning, doesn't affect exit code
 		})
 	}
 
-	// Print results
-	allOK := true
-	for _, r := range results {
-		if r.ok {
-			fmt.Printf("✓ %-30s %s\n", r.name, r.message)
-		} else {
-			// Warnings use ⚠ symbol and don't affect exit code
-			symbol := "✗"
-			if r.isWarn {
-				symbol = "⚠"
-			} else {
-				allOK = false
-			}
-			fmt.Printf("%s %-30s %s\n", symbol, r.name, r.message)
-			if r.fix != "" {
-				fmt.Printf("  Fix: %s\n", r.fix)
-			}
+	return results
+}
+
+// manifestChecks compares installed files with the checksums recorded in
+// config.yaml. Local edits are allowed, so drift is only a warning.
+func manifestChecks(root, configPath string) []doctor.Result {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil
+	}
+	if len(cfg.Installed.Files) == 0 {
+		return []doctor.Result{{
+			Name:     "install manifest",
+			Message:  "not recorded",
+			Fix:      "Run 'maestro update' to record checksums of installed files",
+			Commands: []string{"maestro update --yes"},
+			Warn:     true,
+		}}
+	}
+
+	modified, missing := cfg.Installed.Drift(root)
+	if len(modified) == 0 && len(missing) == 0 {
+		message := fmt.Sprintf("%d file(s) unchanged", len(cfg.Installed.Files))
+		if cfg.Installed.AssetVersion != "" {
+			message += " since " + cfg.Installed.AssetVersion
 		}
+		return []doctor.Result{{Name: "installed files", OK: true, Message: message}}
 	}
 
-	if allOK {
-		fmt.Println("\n✓ All checks passed — project looks healthy!")
+	drifted := append(append([]string{}, modified...), missing...)
+	commands := driftFixCommands(drifted)
+	files := drifted
+	if len(drifted) > 5 {
+		drifted = append(drifted[:5:5], "...")
+	}
+	return []doctor.Result{{
+		Name:     "installed files",
+		Message:  fmt.Sprintf("%d modified, %d missing: %s", len(modified), len(missing), strings.Join(drifted, ", ")),
+		Fix:      "Run 'maestro scripts update <dir>' to restore a directory, or keep your edits",
+		Commands: commands,
+		Warn:     true,
+		Files:    files,
+	}}
+}
+
+// lastUpdateChecks reports when the project was last updated, warning when
+// that was longer ago than staleUpdateAge.
+func lastUpdateChecks(configPath string, now time.Time) []doctor.Result {
+	cfg, err := config.Load(configPath)
+	if err != nil {
 		return nil
 	}
-	return fmt.Errorf("some checks failed")
+	message, stale := describeLastUpdate(cfg, now)
+	return []doctor.Result{{
+		Name:     "last update",
+		OK:       !stale,
+		Message:  message,
+		Fix:      "Run 'maestro update' to get the latest commands and templates",
+		Commands: []string{"maestro update --yes"},
+		Warn:     true,
+	}}
 }
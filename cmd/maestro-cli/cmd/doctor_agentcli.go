@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/spec-maestro/maestro-cli/internal/version"
+	"github.com/spec-maestro/maestro-cli/pkg/agents"
+	"github.com/spec-maestro/maestro-cli/pkg/doctor"
+	"github.com/spec-maestro/maestro-cli/pkg/envreport"
+)
+
+// agentCLI is a command-line tool the project's agents, or maestro itself,
+// run outside the scripts.
+type agentCLI struct {
+	name string
+	// dir is the agent directory whose commands the CLI runs; "" means
+	// the CLI is useful in every project.
+	dir  string
+	uses string
+	// minVersion is the oldest release with what maestro relies on, and
+	// since says what that is; "" accepts any version.
+	minVersion string
+	since      string
+	install    string
+}
+
+var agentCLIs = []agentCLI{
+	{
+		name:       "opencode",
+		dir:        ".opencode",
+		uses:       "runs the commands in .opencode/",
+		minVersion: "0.3.0",
+		since:      "project commands in .opencode/command/",
+		install:    "npm install -g opencode-ai",
+	},
+	{
+		name:       "claude",
+		dir:        ".claude",
+		uses:       "runs the commands in .claude/",
+		minVersion: "1.0.0",
+		since:      "argument-hint in command frontmatter",
+		install:    "npm install -g @anthropic-ai/claude-code",
+	},
+	{
+		name:    "codex",
+		dir:     ".codex",
+		uses:    "runs the skills in .codex/",
+		install: "npm install -g @openai/codex",
+	},
+	{
+		name:       "gh",
+		uses:       "provides a GitHub token when GITHUB_TOKEN is unset",
+		minVersion: "2.17.0",
+		since:      "gh auth token",
+	},
+}
+
+// versionPattern finds the version number in a --version line, such as
+// "gh version 2.40.1 (2023-12-13)" or "1.0.83 (Claude Code)".
+var versionPattern = regexp.MustCompile(`\d+\.\d+(\.\d+)?`)
+
+// agentCLIChecks looks for the CLI of each agent installed in the project,
+// and gh, on PATH and compares their versions with the oldest maestro
+// supports. The agents are optional, so every result is a warning.
+func agentCLIChecks(projectRoot string, d envreport.Detector) []doctor.Result {
+	installed := map[string]bool{}
+	for _, dir := range agents.DetectInstalled(projectRoot) {
+		installed[dir] = true
+	}
+
+	var results []doctor.Result
+	for _, cli := range agentCLIs {
+		if cli.dir != "" && !installed[cli.dir] {
+			continue
+		}
+		results = append(results, agentCLIResult(cli, d.Tool(cli.name)))
+	}
+	return results
+}
+
+func agentCLIResult(cli agentCLI, tool envreport.Tool) doctor.Result {
+	result := doctor.Result{Name: cli.name + " (agent CLI)", OK: true, Warn: true}
+	install := cli.install
+	if install == "" {
+		install = packageInstallCommand(cli.name)
+	}
+	if tool.Path == "" {
+		result.OK = false
+		result.Message = "not found on PATH; it " + cli.uses
+		result.Fix = fmt.Sprintf("Optional: install %s", cli.name)
+		if install != "" {
+			result.Commands = []string{install}
+		}
+		return result
+	}
+
+	found := versionPattern.FindString(tool.Version)
+	switch {
+	case found == "":
+		result.Message = "found on PATH (version unknown)"
+	case cli.minVersion == "":
+		result.Message = found + " found on PATH"
+	default:
+		if older, err := version.Less(found, cli.minVersion); err == nil && older {
+			result.OK = false
+			result.Message = fmt.Sprintf("%s is older than %s, the first release with %s", found, cli.minVersion, cli.since)
+			result.Fix = fmt.Sprintf("Optional: upgrade %s to %s or later", cli.name, cli.minVersion)
+			if install != "" {
+				result.Commands = []string{install}
+			}
+			return result
+		}
+		result.Message = fmt.Sprintf("%s found on PATH (%s or later supported)", found, cli.minVersion)
+	}
+	return result
+}
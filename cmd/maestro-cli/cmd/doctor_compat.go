@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spec-maestro/maestro-cli/pkg/agents"
+	"github.com/spec-maestro/maestro-cli/pkg/config"
+	"github.com/spec-maestro/maestro-cli/pkg/doctor"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+	"github.com/spec-maestro/maestro-cli/pkg/scriptcompat"
+)
+
+// agentCompatChecks checks each installed agent directory's commands
+// against the installed scripts, using the compatibility matrix in the
+// install manifest, or the scripts themselves when none is recorded. A call
+// to a script that does not exist fails; a flag the script does not accept
+// is a warning, since the matrix is read from the scripts' option parsing.
+func agentCompatChecks(projectRoot, maestroDir string) []doctor.Result {
+	scriptsDir := filepath.Join(maestroDir, "scripts")
+	cfg, err := config.Load(filepath.Join(maestroDir, "config.yaml"))
+	if err != nil {
+		// The config checks report an unreadable config
+		return nil
+	}
+	matrix := cfg.Installed.Scripts
+	if len(matrix) == 0 {
+		if matrix, err = scriptcompat.Build(scriptsDir); err != nil {
+			// The structure checks report a missing scripts directory
+			return nil
+		}
+	}
+
+	var results []doctor.Result
+	for _, dir := range agents.DetectInstalled(projectRoot) {
+		name := pathfmt.Rel(dir+"/") + " script calls"
+		problems, err := scriptcompat.Check(filepath.Join(projectRoot, dir), scriptsDir, matrix)
+		if err != nil {
+			results = append(results, doctor.Result{Name: name, Message: fmt.Sprintf("reading %s: %v", pathfmt.Rel(dir), err)})
+			continue
+		}
+		result := doctor.Result{Name: name, OK: true, Message: "compatible with the installed scripts"}
+		if len(problems) > 0 {
+			result = incompatibleResult(name, problems)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// incompatibleResult reports the calls an agent directory makes that the
+// installed scripts do not support.
+func incompatibleResult(name string, problems []scriptcompat.Problem) doctor.Result {
+	var entries, files []string
+	missing := 0
+	seen := map[string]bool{}
+	for _, p := range problems {
+		file := pathfmt.Rel(p.File)
+		entry := fmt.Sprintf("%s:%d calls %s", file, p.Line, p.Script)
+		if p.Missing {
+			missing++
+			entry += ", which does not exist"
+		} else {
+			entry += " with " + strings.Join(p.Flags, ", ") + ", which it does not accept"
+		}
+		entries = append(entries, entry)
+		if !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+	return doctor.Result{
+		Name:     name,
+		Message:  fmt.Sprintf("%d incompatible call(s): %s", len(problems), truncateList(entries)),
+		Fix:      "Refresh the agent directories from the release the scripts came from",
+		Commands: []string{"maestro update --agents-only --yes"},
+		Warn:     missing == 0,
+		Files:    files,
+	}
+}
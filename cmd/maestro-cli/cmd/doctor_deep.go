@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/spec-maestro/maestro-cli/pkg/doctor"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+	"github.com/spec-maestro/maestro-cli/pkg/state"
+)
+
+// maxDeepFailures is how many invalid files a deep check names.
+const maxDeepFailures = 5
+
+// deepChecks validates the content of the starter assets and state, where
+// the structure checks only see that they exist: scripts start with a
+// shebang and pass bash -n, templates parse, commands and skills have their
+// required frontmatter, and state files are valid JSON.
+func deepChecks(maestroDir string) []doctor.Result {
+	bash, _ := exec.LookPath("bash")
+	var results []doctor.Result
+	for _, check := range []struct {
+		dir      string
+		noun     string
+		match    func(name string) bool
+		validate func(path string, data []byte) error
+	}{
+		{"scripts", "script", hasExt(".sh"), func(path string, data []byte) error { return validateScript(bash, path, data) }},
+		{"templates", "template", func(string) bool { return true }, validateTemplate},
+		{"commands", "command", hasExt(".md"), requireFrontmatter("description")},
+		{"skills", "skill", func(name string) bool { return name == "SKILL.md" }, requireFrontmatter("name", "description")},
+		{"state", "state file", hasExt(".json"), validateStateJSON},
+	} {
+		dir := filepath.Join(maestroDir, check.dir)
+		if _, err := os.Stat(dir); err != nil {
+			// The structure checks report missing directories
+			continue
+		}
+		results = append(results, validateFiles(dir, check.noun, check.match, check.validate))
+	}
+	if bash == "" {
+		results = append(results, doctor.Result{
+			Name:    "script syntax",
+			Message: "bash not found; scripts were not checked with bash -n",
+			Fix:     "Install bash to check script syntax",
+			Warn:    true,
+		})
+	}
+	return results
+}
+
+// validateFiles validates each file under dir that match accepts, and
+// reports the invalid ones in a single result.
+func validateFiles(dir, noun string, match func(name string) bool, validate func(path string, data []byte) error) doctor.Result {
+	checked := 0
+	var invalid, files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !match(d.Name()) {
+			return nil
+		}
+		checked++
+		data, err := os.ReadFile(path)
+		if err == nil {
+			err = validate(path, data)
+		}
+		if err != nil {
+			rel := pathfmt.Rel(path)
+			files = append(files, rel)
+			if len(invalid) < maxDeepFailures {
+				invalid = append(invalid, fmt.Sprintf("%s (%v)", rel, err))
+			}
+		}
+		return nil
+	})
+
+	result := doctor.Result{
+		Name:  pathfmt.Rel(dir + "/"),
+		Path:  dir + "/",
+		Files: files,
+		Fix:   "Fix or remove the invalid files",
+	}
+	for _, name := range starterDirNames() {
+		if name == filepath.Base(dir) {
+			result.Fix = fmt.Sprintf("Fix the files, or run 'maestro scripts update --backup %s' to restore the starter %ss", name, noun)
+			result.Commands = []string{"maestro scripts update --backup " + name}
+		}
+	}
+	switch {
+	case err != nil:
+		result.Message = fmt.Sprintf("reading %ss: %v", noun, err)
+	case len(files) == 0:
+		result.OK = true
+		result.Message = fmt.Sprintf("%d %s(s) valid", checked, noun)
+	default:
+		if len(files) > len(invalid) {
+			invalid = append(invalid, "...")
+		}
+		result.Message = fmt.Sprintf("%d of %d %s(s) invalid: %s", len(files), checked, noun, strings.Join(invalid, ", "))
+	}
+	return result
+}
+
+// hasExt matches file names with the extension ext.
+func hasExt(ext string) func(name string) bool {
+	return func(name string) bool { return filepath.Ext(name) == ext }
+}
+
+// validateScript checks that a script starts with a shebang and, when bash
+// is available, that bash -n parses it.
+func validateScript(bash, path string, data []byte) error {
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	if !bytes.HasPrefix(line, []byte("#!")) || len(bytes.TrimSpace(line[2:])) == 0 {
+		return errors.New("no shebang")
+	}
+	if bash == "" {
+		return nil
+	}
+	out, err := exec.Command(bash, "-n", path).CombinedOutput()
+	if err != nil {
+		msg, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+		// bash prefixes errors with the path, which the result already names
+		msg = strings.TrimPrefix(msg, path+": ")
+		if msg == "" {
+			msg = err.Error()
+		}
+		return fmt.Errorf("bash -n: %s", msg)
+	}
+	return nil
+}
+
+// validateTemplate checks that a template is text without unresolved
+// conflict markers whose frontmatter, if any, parses, and that JSON and
+// YAML templates parse.
+func validateTemplate(path string, data []byte) error {
+	if err := validateText(data); err != nil {
+		return err
+	}
+	switch filepath.Ext(path) {
+	case ".json":
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return fmt.Errorf("invalid JSON: %w", err)
+		}
+	case ".yaml", ".yml":
+		var v interface{}
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return fmt.Errorf("invalid YAML: %w", err)
+		}
+	default:
+		if _, _, err := parseFrontmatter(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requireFrontmatter returns a validator for markdown files that must
+// start with YAML frontmatter defining keys.
+func requireFrontmatter(keys ...string) func(path string, data []byte) error {
+	return func(path string, data []byte) error {
+		if err := validateText(data); err != nil {
+			return err
+		}
+		fields, ok, err := parseFrontmatter(data)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("no frontmatter")
+		}
+		var missing []string
+		for _, key := range keys {
+			if strings.TrimSpace(strings.Trim(fields[key], `"'`)) == "" {
+				missing = append(missing, key)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("frontmatter has no %s", strings.Join(missing, ", "))
+		}
+		return nil
+	}
+}
+
+// validateStateJSON checks that a state file parses as a feature state.
+func validateStateJSON(path string, data []byte) error {
+	if _, err := state.Parse(data); err != nil {
+		return fmt.Errorf("invalid state: %w", err)
+	}
+	return nil
+}
+
+// validateText rejects binary content and conflict markers an update
+// with --strategy markers leaves until they are resolved.
+func validateText(data []byte) error {
+	if !utf8.Valid(data) || bytes.IndexByte(data, 0) >= 0 {
+		return errors.New("not UTF-8 text")
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "<<<<<<< ") || strings.HasPrefix(line, ">>>>>>> ") {
+			return errors.New("unresolved conflict markers")
+		}
+	}
+	return nil
+}
+
+// parseFrontmatter parses the frontmatter a markdown file starts with
+// between --- lines into its top-level keys. Agents read frontmatter
+// loosely, so values are not parsed as YAML (an argument-hint such as
+// "[id] (optional)" is not valid YAML); a key's value is the rest of its
+// line, or its indented continuation lines for a block scalar. ok is false
+// when the file has none.
+func parseFrontmatter(data []byte) (fields map[string]string, ok bool, err error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if !strings.HasPrefix(text, "---\n") {
+		return nil, false, nil
+	}
+	lines := strings.Split(text, "\n")[1:]
+	fields = make(map[string]string)
+	key := ""
+	for i, line := range lines {
+		switch {
+		case strings.TrimRight(line, " ") == "---":
+			return fields, true, nil
+		case strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#"):
+		case line[0] == ' ' || line[0] == '\t' || line[0] == '-':
+			// A continuation of the previous key's value
+			if key == "" {
+				return nil, true, fmt.Errorf("invalid frontmatter: line %d", i+2)
+			}
+			fields[key] = strings.TrimSpace(fields[key] + " " + strings.TrimSpace(line))
+		default:
+			name, value, found := strings.Cut(line, ":")
+			if !found || strings.ContainsAny(name, " \t") {
+				return nil, true, fmt.Errorf("invalid frontmatter: line %d is not a key", i+2)
+			}
+			key = name
+			value = strings.TrimSpace(value)
+			if value == ">" || value == "|" || value == ">-" || value == "|-" {
+				value = ""
+			}
+			fields[key] = value
+		}
+	}
+	return nil, true, errors.New("unterminated frontmatter")
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spec-maestro/maestro-cli/pkg/doctor"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+	"github.com/spec-maestro/maestro-cli/pkg/state"
+)
+
+// featureNext is the command that advances a feature from each stage, as
+// list-features.sh suggests it, and the stage that command starts. A
+// specified feature with open clarification markers goes to clarify first.
+var featureNext = map[string]struct{ command, stage string }{
+	"specify":  {"/maestro.plan", "plan"},
+	"clarify":  {"/maestro.plan", "plan"},
+	"research": {"/maestro.plan", "plan"},
+	"plan":     {"/maestro.tasks", "tasks"},
+	"tasks":    {"/maestro.implement", "implement"},
+}
+
+// featureArtifacts is what a feature directory holds, for doctor --feature.
+type featureArtifacts struct {
+	spec, specErr string
+	research      int
+	plan          bool
+	// tasks says where the tasks are: tasks.json or the bd epic
+	tasks string
+}
+
+// featureChecks reports the health of one feature: its spec, which stage
+// artifacts exist, whether its state file agrees with them, and whether it
+// is ready for the next stage.
+func featureChecks(maestroDir, featureID string) []doctor.Result {
+	featureDir := filepath.Join(maestroDir, "specs", featureID)
+	statePath := filepath.Join(maestroDir, "state", featureID+".json")
+	root := filepath.Dir(maestroDir)
+
+	var fs *state.State
+	var stateErr error
+	if _, err := os.Stat(statePath); err == nil {
+		fs, stateErr = readStateFile(statePath)
+	}
+	a := readFeatureArtifacts(featureDir, root, fs)
+
+	spec := doctor.Result{Name: "spec", OK: true, Message: a.spec + " found", Files: []string{a.spec}}
+	if a.specErr != "" {
+		spec.OK = false
+		spec.Message = a.specErr
+		spec.Fix = "Run /maestro.specify to write the specification"
+	}
+	results := []doctor.Result{spec, artifactsResult(a)}
+	results = append(results, featureStateResult(statePath, featureID, fs, stateErr, a, root))
+	return append(results, readinessResult(fs, a))
+}
+
+// readFeatureArtifacts looks for the spec, research, plan, and tasks of the
+// feature in featureDir. Research may live where the state's research_path
+// points, and tasks in bd, under the state's epic_id.
+func readFeatureArtifacts(featureDir, root string, fs *state.State) featureArtifacts {
+	specPath := filepath.Join(featureDir, "spec.md")
+	a := featureArtifacts{spec: pathfmt.Rel(specPath)}
+	switch data, err := os.ReadFile(specPath); {
+	case err != nil:
+		a.specErr = pathfmt.Rel(specPath) + " not found"
+	case !hasH1(data):
+		a.specErr = pathfmt.Rel(specPath) + " has no # heading; it may be a placeholder"
+	}
+
+	researchDir := filepath.Join(featureDir, "research")
+	if fs != nil && fs.ResearchPath != "" {
+		researchDir = stateTarget(root, fs.ResearchPath)
+	}
+	if entries, err := os.ReadDir(researchDir); err == nil {
+		a.research = len(entries)
+	}
+	if _, err := os.Stat(filepath.Join(featureDir, "plan.md")); err == nil {
+		a.plan = true
+	}
+	if _, err := os.Stat(filepath.Join(featureDir, "tasks.json")); err == nil {
+		a.tasks = "tasks.json"
+	} else if fs != nil && fs.EpicID != "" {
+		a.tasks = "bd epic " + fs.EpicID
+	}
+	return a
+}
+
+func hasH1(data []byte) bool {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if bytes.HasPrefix(line, []byte("# ")) {
+			return true
+		}
+	}
+	return false
+}
+
+// artifactsResult lists which stage artifacts exist. Missing ones are not
+// a problem here; the state and readiness results judge them.
+func artifactsResult(a featureArtifacts) doctor.Result {
+	var present, missing []string
+	add := func(ok bool, have, lack string) {
+		if ok {
+			present = append(present, have)
+		} else {
+			missing = append(missing, lack)
+		}
+	}
+	add(a.research > 0, fmt.Sprintf("research (%d file(s))", a.research), "research")
+	add(a.plan, "plan.md", "plan.md")
+	add(a.tasks != "", "tasks in "+a.tasks, "tasks")
+	message := "present: " + strings.Join(present, ", ")
+	switch {
+	case len(present) == 0:
+		message = "none yet: " + strings.Join(missing, ", ")
+	case len(missing) > 0:
+		message += "; not yet: " + strings.Join(missing, ", ")
+	}
+	return doctor.Result{Name: "artifacts", OK: true, Message: message}
+}
+
+// featureStateResult cross-checks the state file with the feature: it
+// names this feature, its stage is known, the paths it records exist, and
+// the artifacts its stage implies are there.
+func featureStateResult(path, featureID string, fs *state.State, stateErr error, a featureArtifacts, root string) doctor.Result {
+	rel := pathfmt.Rel(path)
+	result := doctor.Result{Name: "state", OK: true, Files: []string{rel}}
+	switch {
+	case fs == nil && stateErr == nil:
+		result.OK = false
+		result.Warn = true
+		result.Message = rel + " not found, so the feature's stage is unknown"
+		result.Fix = "Run /maestro.specify for the feature, or restore the state file from git history"
+		result.Files = nil
+		return result
+	case stateErr != nil:
+		result.OK = false
+		result.Message = fmt.Sprintf("%s: %v", rel, stateErr)
+		result.Fix = "Repair the file, e.g. from git history, or delete it to drop the feature's state"
+		return result
+	}
+
+	stage := fs.Stage
+	var problems []string
+	if fs.FeatureID != featureID {
+		problems = append(problems, fmt.Sprintf("feature_id is %s", fs.FeatureID))
+	}
+	for _, f := range statePaths(fs) {
+		if f.path != "" {
+			if _, err := os.Stat(stateTarget(root, f.path)); os.IsNotExist(err) {
+				problems = append(problems, fmt.Sprintf("%s %s does not exist", f.field, f.path))
+			}
+		}
+	}
+	index := state.Index(stage)
+	switch {
+	case stage == state.Cancelled:
+	case index < 0:
+		problems = append(problems, fmt.Sprintf("stage %q is not one of %s, or %s", stage, strings.Join(state.Stages, ", "), state.Cancelled))
+	case index >= state.Index("tasks") && !a.plan:
+		problems = append(problems, fmt.Sprintf("stage is %s, but plan.md is missing", stage))
+	case index >= state.Index("implement") && a.tasks == "":
+		problems = append(problems, fmt.Sprintf("stage is %s, but there is no tasks.json or epic_id", stage))
+	}
+
+	result.Message = fmt.Sprintf("stage %s, consistent with the artifacts", stage)
+	if len(problems) > 0 {
+		result.OK = false
+		result.Message = fmt.Sprintf("stage %s; %s", stage, strings.Join(problems, "; "))
+		result.Fix = "Restore the missing files, or correct the state file to match the feature"
+	}
+	return result
+}
+
+// readinessResult says what to run next and whether its prerequisites,
+// the ones check-prerequisites.sh enforces, are met.
+func readinessResult(fs *state.State, a featureArtifacts) doctor.Result {
+	result := doctor.Result{Name: "next stage", OK: true}
+	if fs == nil {
+		fs = &state.State{}
+	}
+	stage := fs.Stage
+	switch stage {
+	case "":
+		result.OK = false
+		result.Warn = true
+		result.Message = "unknown without a valid state file"
+		return result
+	case "implement":
+		result.Message = "implementation in progress; run /maestro.implement to continue"
+		return result
+	case "complete":
+		result.Message = "complete; run /maestro.analyze for a post-epic analysis"
+		return result
+	case "cancelled":
+		result.Message = "cancelled"
+		return result
+	}
+	next, ok := featureNext[stage]
+	if !ok {
+		result.OK = false
+		result.Message = fmt.Sprintf("unknown after stage %q", stage)
+		return result
+	}
+	if stage == "specify" && fs.ClarificationCount > 0 {
+		next.command, next.stage = "/maestro.clarify", "clarify"
+	}
+
+	var missing []string
+	if a.specErr != "" {
+		missing = append(missing, a.specErr)
+	}
+	switch next.stage {
+	case "plan":
+		if fs.ResearchReady != nil && *fs.ResearchReady && a.research == 0 {
+			missing = append(missing, "research is marked ready but has no files")
+		}
+	case "tasks":
+		if !a.plan {
+			missing = append(missing, "plan.md is missing")
+		}
+	case "implement":
+		if a.tasks == "" {
+			missing = append(missing, "no tasks were created")
+		}
+		if _, err := exec.LookPath("bd"); err != nil {
+			missing = append(missing, "bd is not on PATH")
+		}
+	}
+	if len(missing) > 0 {
+		result.OK = false
+		result.Message = fmt.Sprintf("not ready for %s: %s", next.command, strings.Join(missing, "; "))
+		result.Fix = fmt.Sprintf("Run /maestro.%s again to produce what %s needs", stage, next.command)
+		return result
+	}
+	result.Message = "ready: run " + next.command
+	return result
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/agents"
+	"github.com/spec-maestro/maestro-cli/pkg/doctor"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+)
+
+// writeFixScript writes a POSIX shell script with the commands that
+// remediate each check doctor did not pass, for the project in dir. Failed
+// checks get their commands; warnings get them commented out, as they are
+// optional. Checks without a known command keep their advice as a comment.
+func writeFixScript(w io.Writer, dir string, results []doctor.Result, now time.Time) error {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	fmt.Fprintf(&b, "# Fixes for the checks 'maestro doctor' reported on %s.\n", now.Format("2006-01-02 15:04"))
+	b.WriteString("# Review the commands before running this script.\n")
+	b.WriteString("set -e\n")
+	fmt.Fprintf(&b, "cd %s\n", shellQuote(dir))
+
+	fixes := 0
+	for _, r := range results {
+		if r.OK {
+			continue
+		}
+		fixes++
+		status := "FAIL"
+		if r.Warn {
+			status = "WARN (optional, uncomment to apply)"
+		}
+		fmt.Fprintf(&b, "\n# %s %s: %s\n", status, r.Name, r.Message)
+		if len(r.Commands) == 0 {
+			fmt.Fprintf(&b, "# No command available: %s\n", r.Fix)
+			continue
+		}
+		for _, command := range r.Commands {
+			if r.Warn {
+				b.WriteString("# ")
+			}
+			b.WriteString(command + "\n")
+		}
+	}
+	if fixes == 0 {
+		b.WriteString("\n# All checks passed; nothing to fix.\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// emitFixScript writes the fix script for the project cmd works in to
+// path, or to stdout for "-".
+func emitFixScript(cmd *cobra.Command, target string, results []doctor.Result) error {
+	dir, err := filepath.Abs(workDir(cmd))
+	if err != nil {
+		return fmt.Errorf("resolving the project directory: %w", err)
+	}
+	if target == "-" {
+		return writeFixScript(os.Stdout, dir, results, time.Now())
+	}
+
+	target = userPath(cmd, target)
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return fmt.Errorf("creating fix script: %w", err)
+	}
+	if err := writeFixScript(f, dir, results, time.Now()); err != nil {
+		f.Close()
+		return fmt.Errorf("writing fix script: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing fix script: %w", err)
+	}
+	fmt.Printf("Wrote fixes to %s; review it, then run: sh %s\n", pathfmt.Path(target), shellQuote(target))
+	return nil
+}
+
+// packageInstallCommand returns the command that installs a system package
+// with the platform's package manager, or "" when none is known.
+func packageInstallCommand(pkg string) string {
+	if runtime.GOOS == "darwin" {
+		return "brew install " + pkg
+	}
+	for _, pm := range []struct{ bin, command string }{
+		{"apt-get", "sudo apt-get install -y "},
+		{"dnf", "sudo dnf install -y "},
+		{"apk", "sudo apk add "},
+		{"pacman", "sudo pacman -S --noconfirm "},
+	} {
+		if _, err := exec.LookPath(pm.bin); err == nil {
+			return pm.command + pkg
+		}
+	}
+	return ""
+}
+
+// driftFixCommands returns the scripts update commands that restore the
+// starter directories holding drifted files.
+func driftFixCommands(drifted []string) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, file := range drifted {
+		for _, dir := range agents.RequiredStarterAssetDirs() {
+			if strings.HasPrefix(file, dir+"/") && !seen[dir] {
+				seen[dir] = true
+				names = append(names, path.Base(dir))
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return []string{"maestro scripts update --backup " + strings.Join(names, " ")}
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
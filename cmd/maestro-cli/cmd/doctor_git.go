@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spec-maestro/maestro-cli/pkg/config"
+	"github.com/spec-maestro/maestro-cli/pkg/doctor"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+)
+
+// gitChecks verifies what the workflow scripts expect of git: the project
+// is a repository (a warning, as maestro itself works without one), the
+// base branch in config.yaml exists, .maestro/state/ is not ignored, and
+// .maestro/ has no uncommitted changes. Without git on PATH the system
+// checks report it and these are skipped. git runs in root, the project
+// root.
+func gitChecks(root, maestroDir string) []doctor.Result {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil
+	}
+	if _, err := gitOutput(root, "rev-parse", "--show-toplevel"); err != nil {
+		return []doctor.Result{{
+			Name:     "git repository",
+			Warn:     true,
+			Message:  "not a git repository; the scripts create branches and worktrees with git",
+			Fix:      "Initialize a repository in the project root",
+			Commands: []string{"git init"},
+		}}
+	}
+	results := []doctor.Result{{Name: "git repository", OK: true, Message: "found"}}
+
+	cfg, err := config.Load(filepath.Join(maestroDir, "config.yaml"))
+	if err == nil && cfg.Project.BaseBranch != "" {
+		results = append(results, baseBranchCheck(root, cfg.Project.BaseBranch))
+	}
+	results = append(results, stateIgnoredCheck(root, maestroDir))
+	if result, ok := uncommittedCheck(root, maestroDir); ok {
+		results = append(results, result)
+	}
+	return results
+}
+
+// baseBranchCheck looks for the base branch locally, then on origin, where
+// worktree-create.sh also finds it.
+func baseBranchCheck(root, branch string) doctor.Result {
+	name := "base branch"
+	if _, err := gitOutput(root, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err == nil {
+		return doctor.Result{Name: name, OK: true, Message: branch + " exists"}
+	}
+	if _, err := gitOutput(root, "rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+branch); err == nil {
+		return doctor.Result{Name: name, OK: true, Message: branch + " exists on origin"}
+	}
+	return doctor.Result{
+		Name:     name,
+		Message:  fmt.Sprintf("%s (project.base_branch) does not exist locally or on origin", branch),
+		Fix:      "Fetch or create the branch, or set project.base_branch in .maestro/config.yaml to the branch features start from",
+		Commands: []string{"git fetch origin " + shellQuote(branch)},
+	}
+}
+
+// stateIgnoredCheck warns when a .gitignore rule covers .maestro/state/,
+// which keeps feature state out of commits and off other machines.
+// check-ignore also matches files that do not exist yet.
+func stateIgnoredCheck(root, maestroDir string) doctor.Result {
+	probe := path.Join(projectRel(root, maestroDir), "state", "feature.json")
+	out, err := gitOutput(root, "check-ignore", "--verbose", probe)
+	if err != nil || out == "" {
+		return doctor.Result{Name: "state not ignored", OK: true, Message: pathfmt.Rel(filepath.Join(maestroDir, "state")+"/") + " is tracked by git"}
+	}
+	// source:line:pattern<TAB>path
+	rule, _, _ := strings.Cut(out, "\t")
+	source, line, pattern := rule, "", ""
+	if parts := strings.SplitN(rule, ":", 3); len(parts) == 3 {
+		source, line, pattern = parts[0], parts[1], parts[2]
+	}
+	if !filepath.IsAbs(source) {
+		source = filepath.Join(root, source)
+	}
+	if strings.HasPrefix(pattern, "!") {
+		// An exception, such as !.maestro/state/, is the last rule to match
+		return doctor.Result{Name: "state not ignored", OK: true, Message: pathfmt.Rel(filepath.Join(maestroDir, "state")+"/") + " is tracked by git"}
+	}
+	return doctor.Result{
+		Name:    "state not ignored",
+		Message: fmt.Sprintf("%s is ignored by %q at %s:%s, so feature state is never committed", pathfmt.Rel(filepath.Join(maestroDir, "state")+"/"), pattern, pathfmt.Rel(source), line),
+		Fix:     "Remove the rule, or add a !.maestro/state/ exception after it; .maestro/state/*.lock alone is enough to ignore",
+		Warn:    true,
+		Files:   []string{pathfmt.Rel(source)},
+	}
+}
+
+// uncommittedCheck warns about files in .maestro/ that are modified,
+// staged, or untracked, which a feature branch or worktree created now
+// would not have. .maestro/cache/ belongs to this machine and is skipped.
+// ok is false when git cannot tell.
+func uncommittedCheck(root, maestroDir string) (doctor.Result, bool) {
+	dir := projectRel(root, maestroDir)
+	cache := ":(exclude)" + dir + "/cache"
+	worktree, err := gitOutput(root, "ls-files", "--modified", "--others", "--exclude-standard", "--", dir, cache)
+	if err != nil {
+		return doctor.Result{}, false
+	}
+	staged, err := gitOutput(root, "diff", "--cached", "--name-only", "--relative", "--", dir, cache)
+	if err != nil {
+		return doctor.Result{}, false
+	}
+	seen := map[string]bool{}
+	var files []string
+	for _, file := range strings.Split(worktree+"\n"+staged, "\n") {
+		if file = strings.TrimSpace(file); file != "" && !seen[file] {
+			seen[file] = true
+			files = append(files, filepath.Join(root, file))
+		}
+	}
+	sort.Strings(files)
+
+	result := doctor.Result{Name: "uncommitted changes", OK: true, Message: "none in " + pathfmt.Rel(maestroDir+"/"), Warn: true}
+	if len(files) > 0 {
+		result.OK = false
+		result.Message = fmt.Sprintf("%d file(s) in %s: %s", len(files), pathfmt.Rel(maestroDir+"/"), listFiles(files))
+		result.Fix = "Commit the changes, so the worktrees and branches the scripts create include them"
+		result.Files = relFiles(files)
+	}
+	return result, true
+}
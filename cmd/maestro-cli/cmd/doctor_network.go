@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spec-maestro/maestro-cli/pkg/config"
+	"github.com/spec-maestro/maestro-cli/pkg/doctor"
+	ghclient "github.com/spec-maestro/maestro-cli/pkg/github"
+)
+
+// networkFix is the advice for a GitHub host that does not answer.
+const networkFix = "Check your connection, and HTTPS_PROXY if you are behind a proxy; init --offline and bundles work without GitHub"
+
+// networkStatus is what doctor --network found out about reaching GitHub
+// for the project's assets repository.
+type networkStatus struct {
+	tokenSource string
+	tokenErr    error
+	limit       *ghclient.RateLimit
+	apiErr      error
+	codeloadErr error
+}
+
+// probeNetwork resolves a token the way init and update do and asks GitHub
+// for its quota, then checks that the archive host answers. A GitHub App
+// that cannot produce a token is reported, and the probes run anonymously.
+func probeNetwork(maestroDir string) networkStatus {
+	cfg, err := config.Load(filepath.Join(maestroDir, "config.yaml"))
+	if err != nil {
+		cfg = &config.ProjectConfig{}
+	}
+	owner, repo, err := assetsRepo("", cfg)
+	if err != nil {
+		owner, repo = githubOwner, githubRepo
+	}
+	var s networkStatus
+	token, source, err := ghclient.ResolveRepoTokenSource(os.Getenv("GITHUB_TOKEN"), owner, repo)
+	if err != nil {
+		s.tokenErr = err
+	}
+	// GITHUB_TOKEN is passed in, as init and update do
+	if source == "explicit" {
+		source = "GITHUB_TOKEN"
+	}
+	s.tokenSource = source
+	client := ghclient.NewClient(owner, repo, token)
+	s.limit, s.apiErr = client.RateLimit()
+	s.codeloadErr = client.PingCodeload()
+	return s
+}
+
+// networkResults reports a network probe: where the token came from, the
+// API quota left, and whether each GitHub host answers.
+func networkResults(s networkStatus) []doctor.Result {
+	token := doctor.Result{Name: "github token", OK: true, Message: "found in " + s.tokenSource}
+	switch {
+	case s.tokenErr != nil:
+		token = doctor.Result{
+			Name:    "github token",
+			Message: s.tokenErr.Error(),
+			Fix:     "Fix the GITHUB_APP_* settings, or unset GITHUB_APP_ID to use another token source (see USAGE.md, GitHub authentication)",
+		}
+	case s.tokenSource == "":
+		token = doctor.Result{
+			Name:     "github token",
+			Message:  "none found; requests are anonymous, limited to 60 per hour",
+			Fix:      "Set GITHUB_TOKEN or GH_TOKEN, or log in with the gh CLI",
+			Commands: []string{"gh auth login"},
+			Warn:     true,
+		}
+	}
+	results := []doctor.Result{token}
+
+	api := doctor.Result{Name: "api.github.com", OK: true, Message: "reachable"}
+	switch {
+	case errors.Is(s.apiErr, ghclient.ErrBadCredentials):
+		api.OK = false
+		api.Message = fmt.Sprintf("reachable, but %v", s.apiErr)
+		api.Fix = fmt.Sprintf("Replace the expired or revoked token in %s", s.tokenSource)
+	case s.apiErr != nil:
+		api.OK = false
+		api.Message = fmt.Sprintf("not reachable: %v", s.apiErr)
+		api.Fix = networkFix
+	}
+	results = append(results, api)
+	if s.limit != nil {
+		results = append(results, rateLimitResult(s.limit))
+	}
+
+	codeload := doctor.Result{Name: "codeload.github.com", OK: true, Message: "reachable"}
+	if s.codeloadErr != nil {
+		codeload.OK = false
+		codeload.Message = fmt.Sprintf("not reachable: %v", s.codeloadErr)
+		codeload.Fix = networkFix
+	}
+	return append(results, codeload)
+}
+
+// rateLimitResult reports the quota left: none fails, since init and update
+// would fail too, and less than a tenth is a warning.
+func rateLimitResult(limit *ghclient.RateLimit) doctor.Result {
+	reset := limit.Reset.Format("15:04")
+	result := doctor.Result{
+		Name:    "github rate limit",
+		OK:      true,
+		Message: fmt.Sprintf("%d of %d requests left, resets at %s", limit.Remaining, limit.Limit, reset),
+	}
+	switch {
+	case limit.Remaining == 0:
+		result.OK = false
+		result.Message = fmt.Sprintf("exhausted (%d per hour), resets at %s", limit.Limit, reset)
+		result.Fix = "Wait for the reset, or authenticate for a higher limit"
+	case limit.Remaining*10 < limit.Limit:
+		result.OK = false
+		result.Warn = true
+		result.Fix = "Authenticate for a higher limit, or wait for the reset"
+	}
+	return result
+}
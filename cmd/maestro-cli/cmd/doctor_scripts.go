@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spec-maestro/maestro-cli/pkg/config"
+	"github.com/spec-maestro/maestro-cli/pkg/doctor"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+)
+
+// script is a file in .maestro/scripts/ as the integrity checks see it.
+type script struct {
+	path       string
+	shebang    bool
+	executable bool
+	// sourced marks a library the other scripts source, which needs no
+	// exec bit.
+	sourced bool
+}
+
+// scriptChecks verifies the scripts commands run: every .sh file starts
+// with a shebang, every script is executable, and, when config.yaml has an
+// install manifest, the scripts match the checksums recorded there.
+func scriptChecks(maestroDir string) []doctor.Result {
+	dir := filepath.Join(maestroDir, "scripts")
+	scripts, err := listScripts(dir)
+	if os.IsNotExist(err) {
+		// The structure checks report a missing directory
+		return nil
+	}
+	if err != nil {
+		return []doctor.Result{{Name: "scripts", Message: fmt.Sprintf("reading %s: %v", pathfmt.Rel(dir), err)}}
+	}
+
+	var noShebang, notExecutable []string
+	for _, s := range scripts {
+		if !s.shebang && filepath.Ext(s.path) == ".sh" {
+			noShebang = append(noShebang, s.path)
+		}
+		if s.shebang && !s.executable && !s.sourced {
+			notExecutable = append(notExecutable, s.path)
+		}
+	}
+
+	shebangs := doctor.Result{Name: "script shebangs", OK: true, Message: fmt.Sprintf("%d script(s) start with #!", len(scripts))}
+	if len(noShebang) > 0 {
+		shebangs.OK = false
+		shebangs.Message = fmt.Sprintf("%d without a shebang: %s", len(noShebang), listFiles(noShebang))
+		shebangs.Fix = "Add a #! line, or run 'maestro scripts update --backup scripts' to restore the starter scripts"
+		shebangs.Commands = []string{"maestro scripts update --backup scripts"}
+		shebangs.Files = relFiles(noShebang)
+	}
+	results := []doctor.Result{shebangs}
+
+	// Windows has no exec bit; scripts there run through bash
+	if runtime.GOOS != "windows" {
+		perms := doctor.Result{Name: "script permissions", OK: true, Message: "executable", Warn: true}
+		if len(notExecutable) > 0 {
+			perms.OK = false
+			perms.Message = fmt.Sprintf("%d not executable: %s", len(notExecutable), listFiles(notExecutable))
+			perms.Fix = "Set the exec bit, which checkouts made on Windows or with core.fileMode=false lose"
+			perms.Commands = []string{"chmod +x " + quoteFiles(notExecutable)}
+			perms.Files = relFiles(notExecutable)
+		}
+		results = append(results, perms)
+	}
+
+	if checksums, ok := scriptChecksumCheck(filepath.Join(maestroDir, "config.yaml"), dir); ok {
+		results = append(results, checksums)
+	}
+	return results
+}
+
+// scriptChecksumCheck compares the scripts with the checksums the install
+// manifest in configPath records for them. ok is false when it records none.
+func scriptChecksumCheck(configPath, dir string) (doctor.Result, bool) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return doctor.Result{}, false
+	}
+	// The manifest names files relative to the project root
+	root := filepath.Dir(filepath.Dir(configPath))
+	prefix := projectRel(root, dir) + "/"
+	scripts := config.InstalledSection{Files: map[string]string{}}
+	for file, sum := range cfg.Installed.Files {
+		if strings.HasPrefix(file, prefix) {
+			scripts.Files[file] = sum
+		}
+	}
+	if len(scripts.Files) == 0 {
+		return doctor.Result{}, false
+	}
+
+	modified, missing := scripts.Drift(root)
+	result := doctor.Result{
+		Name:    "script checksums",
+		OK:      len(modified) == 0 && len(missing) == 0,
+		Message: fmt.Sprintf("%d file(s) match the install manifest", len(scripts.Files)),
+		Warn:    true,
+	}
+	if !result.OK {
+		var parts []string
+		if len(modified) > 0 {
+			parts = append(parts, fmt.Sprintf("%d modified locally: %s", len(modified), listFiles(modified)))
+		}
+		if len(missing) > 0 {
+			parts = append(parts, fmt.Sprintf("%d missing: %s", len(missing), listFiles(missing)))
+		}
+		result.Message = strings.Join(parts, "; ")
+		result.Fix = "Keep your edits, or run 'maestro scripts update --backup scripts' to restore the installed scripts"
+		result.Commands = []string{"maestro scripts update --backup scripts"}
+		result.Files = append(append([]string{}, modified...), missing...)
+	}
+	return result, true
+}
+
+// listScripts returns the scripts under dir: .sh files and files that start
+// with a shebang. Other files, such as test fixtures, are not scripts.
+func listScripts(dir string) ([]script, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+	var scripts []script
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		head, err := readHead(path)
+		if err != nil {
+			return err
+		}
+		s := script{
+			path:       path,
+			shebang:    bytes.HasPrefix(head, []byte("#!")),
+			executable: info.Mode().Perm()&0111 != 0,
+			sourced:    isSourcedLibrary(head),
+		}
+		if s.shebang || filepath.Ext(path) == ".sh" {
+			scripts = append(scripts, s)
+		}
+		return nil
+	})
+	return scripts, err
+}
+
+// readHead returns the first lines of a file, enough for its shebang and
+// leading comment.
+func readHead(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var head bytes.Buffer
+	scanner := bufio.NewScanner(f)
+	for i := 0; i < 10 && scanner.Scan(); i++ {
+		head.Write(scanner.Bytes())
+		head.WriteByte('\n')
+	}
+	return head.Bytes(), nil
+}
+
+// isSourcedLibrary reports whether a script's leading comment says to
+// source it, like worktree-detect.sh's "Source this file".
+func isSourcedLibrary(head []byte) bool {
+	return bytes.Contains(bytes.ToLower(head), []byte("# source this file"))
+}
+
+// listFiles names up to five files, relative to the project root.
+func listFiles(files []string) string {
+	names := relFiles(files)
+	if len(names) > 5 {
+		names = append(names[:5:5], "...")
+	}
+	return strings.Join(names, ", ")
+}
+
+func relFiles(files []string) []string {
+	names := make([]string, len(files))
+	for i, file := range files {
+		names[i] = pathfmt.Rel(file)
+	}
+	return names
+}
+
+// quoteFiles joins files, relative to the project root, as shell words.
+func quoteFiles(files []string) string {
+	words := make([]string, len(files))
+	for i, file := range files {
+		words[i] = shellQuote(pathfmt.Rel(file))
+	}
+	return strings.Join(words, " ")
+}
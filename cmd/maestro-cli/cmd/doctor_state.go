@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spec-maestro/maestro-cli/pkg/doctor"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+	"github.com/spec-maestro/maestro-cli/pkg/state"
+)
+
+// statePath is a field of a state file that points at a file in the
+// project, relative to its root.
+type statePath struct{ field, path string }
+
+func statePaths(s *state.State) []statePath {
+	return []statePath{{"spec_path", s.SpecPath}, {"plan_path", s.PlanPath}, {"research_path", s.ResearchPath}}
+}
+
+// stateChecks validates the feature state files in .maestro/state/: each
+// parses as a JSON object with the required fields, and the files it
+// points at exist. Corrupt files and missing fields fail; dangling paths,
+// e.g. after a spec was deleted by hand, are a warning.
+func stateChecks(maestroDir string) []doctor.Result {
+	dir := filepath.Join(maestroDir, "state")
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(paths) == 0 {
+		// The structure checks report a missing directory
+		return nil
+	}
+	sort.Strings(paths)
+	root := filepath.Dir(maestroDir)
+
+	var invalid, invalidFiles, dangling, danglingFiles []string
+	for _, path := range paths {
+		rel := pathfmt.Rel(path)
+		s, err := readStateFile(path)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s (%v)", rel, err))
+			invalidFiles = append(invalidFiles, rel)
+			continue
+		}
+		for _, f := range statePaths(s) {
+			if f.path == "" {
+				continue
+			}
+			if _, err := os.Stat(stateTarget(root, f.path)); os.IsNotExist(err) {
+				dangling = append(dangling, fmt.Sprintf("%s (%s %s)", rel, f.field, f.path))
+				danglingFiles = append(danglingFiles, rel)
+			}
+		}
+	}
+
+	files := doctor.Result{Name: "state files", OK: true, Message: fmt.Sprintf("%d state file(s) valid", len(paths))}
+	if len(invalid) > 0 {
+		files.OK = false
+		files.Message = fmt.Sprintf("%d of %d invalid: %s", len(invalid), len(paths), truncateList(invalid))
+		files.Fix = "Repair the files named, e.g. from git history, or delete them to drop the features' state"
+		files.Files = invalidFiles
+	}
+	refs := doctor.Result{Name: "state references", OK: true, Message: "every spec, plan, and research path exists", Warn: true}
+	if len(dangling) > 0 {
+		refs.OK = false
+		refs.Message = fmt.Sprintf("%d missing: %s", len(dangling), truncateList(dangling))
+		refs.Fix = "Restore the missing files, or update or remove the paths in the state files"
+		refs.Files = danglingFiles
+	}
+	return []doctor.Result{files, refs}
+}
+
+// readStateFile parses a state file and checks its required fields. A
+// syntax error names the line and column.
+func readStateFile(path string) (*state.State, error) {
+	s, err := state.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// stateTarget resolves a path from a state file, relative to the project root.
+func stateTarget(root, p string) string {
+	path := filepath.FromSlash(p)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	return path
+}
+
+// truncateList joins up to five entries, noting that there are more.
+func truncateList(entries []string) string {
+	if len(entries) > 5 {
+		entries = append(entries[:5:5], "...")
+	}
+	return strings.Join(entries, ", ")
+}
@@ -0,0 +1,407 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/internal/version"
+	"github.com/spec-maestro/maestro-cli/pkg/adopt"
+	"github.com/spec-maestro/maestro-cli/pkg/agents"
+	"github.com/spec-maestro/maestro-cli/pkg/assets"
+	"github.com/spec-maestro/maestro-cli/pkg/config"
+	"github.com/spec-maestro/maestro-cli/pkg/fs"
+	ghclient "github.com/spec-maestro/maestro-cli/pkg/github"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/ignore"
+	"github.com/spec-maestro/maestro-cli/pkg/merge"
+	"github.com/spec-maestro/maestro-cli/pkg/output"
+)
+
+// installPlan is what init or update would do, as reported by --dry-run.
+type installPlan struct {
+	Operation string               `json:"operation"`
+	Source    string               `json:"source"`
+	Files     []agents.PlannedFile `json:"files"`
+	Conflicts []string             `json:"conflicts"`
+	Notes     []string             `json:"notes"`
+}
+
+func newInstallPlan(operation, source string) *installPlan {
+	return &installPlan{Operation: operation, Source: source, Files: []agents.PlannedFile{}, Conflicts: []string{}, Notes: []string{}}
+}
+
+func (p *installPlan) conflict(format string, args ...interface{}) {
+	p.Conflicts = append(p.Conflicts, fmt.Sprintf(format, args...))
+}
+
+func (p *installPlan) note(format string, args ...interface{}) {
+	p.Notes = append(p.Notes, fmt.Sprintf(format, args...))
+}
+
+// generated records a file whose content is generated at install time, so
+// it can only be compared by existence.
+func (p *installPlan) generated(path string) {
+	change := agents.ChangeCreate
+	if _, err := os.Stat(path); err == nil {
+		change = agents.ChangeOverwrite
+	}
+	p.Files = append(p.Files, agents.PlannedFile{Path: path, Change: change})
+}
+
+// merged records the files a merge of managed files would write, and a
+// conflict for each file edited both locally and upstream.
+func (p *installPlan) merged(results []merge.Result) {
+	for _, r := range results {
+		switch r.Action {
+		case merge.Created:
+			p.Files = append(p.Files, agents.PlannedFile{Path: r.Path, Change: agents.ChangeCreate})
+		case merge.Updated, merge.Conflicted:
+			p.Files = append(p.Files, agents.PlannedFile{Path: r.Path, Change: agents.ChangeOverwrite})
+		case merge.NewFile:
+			p.Files = append(p.Files, agents.PlannedFile{Path: r.Path, Change: agents.ChangeUnchanged})
+			p.Files = append(p.Files, agents.PlannedFile{Path: r.Path + ".new", Change: agents.ChangeCreate})
+		case merge.Removed:
+			p.Files = append(p.Files, agents.PlannedFile{Path: r.Path, Change: agents.ChangeRemove})
+		default:
+			p.Files = append(p.Files, agents.PlannedFile{Path: r.Path, Change: agents.ChangeUnchanged})
+		}
+		if !r.Conflict {
+			continue
+		}
+		switch {
+		case r.Removed:
+			p.conflict("%s was edited locally and the release removes it; update would keep it", r.Path)
+		case r.Action == merge.NewFile:
+			p.conflict("%s was edited locally; update would write the new version to %s.new", r.Path, r.Path)
+		case r.Action == merge.Conflicted:
+			p.conflict("%s was edited locally; update would write conflict markers into it", r.Path)
+		default:
+			p.conflict("%s was edited locally; update would keep it and skip the new version", r.Path)
+		}
+	}
+}
+
+// counts tallies the planned files by change.
+func (p *installPlan) counts() map[agents.FileChange]int {
+	counts := map[agents.FileChange]int{agents.ChangeCreate: 0, agents.ChangeOverwrite: 0, agents.ChangeRemove: 0, agents.ChangeUnchanged: 0}
+	for _, f := range p.Files {
+		counts[f.Change]++
+	}
+	return counts
+}
+
+// write renders the plan. The text form lists only files that would change;
+// the JSON form lists every file.
+func (p *installPlan) write(w io.Writer, format string) error {
+	if output.Data(format) {
+		return output.Write(w, format, struct {
+			*installPlan
+			DryRun bool                      `json:"dry_run"`
+			Counts map[agents.FileChange]int `json:"counts"`
+		}{p, true, p.counts()})
+	}
+	if err := validateOutputFormat(format); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Dry run: maestro %s would install from %s. Nothing has been written.\n", p.Operation, p.Source)
+	if len(p.Conflicts) > 0 {
+		fmt.Fprintln(w, "\nConflicts:")
+		for _, c := range p.Conflicts {
+			fmt.Fprintf(w, "  %s %s\n", glyph.Warn(), c)
+		}
+	}
+
+	changed := 0
+	for _, f := range p.Files {
+		if f.Change == agents.ChangeUnchanged {
+			continue
+		}
+		if changed == 0 {
+			fmt.Fprintln(w, "\nFiles:")
+		}
+		fmt.Fprintf(w, "  %-9s  %s\n", f.Change, f.Path)
+		changed++
+	}
+	if len(p.Notes) > 0 {
+		fmt.Fprintln(w, "\nNotes:")
+		for _, n := range p.Notes {
+			fmt.Fprintf(w, "  %s\n", n)
+		}
+	}
+
+	counts := p.counts()
+	fmt.Fprintf(w, "\n%d to create, %d to overwrite, %d to remove, %d unchanged, %d conflict(s)\n",
+		counts[agents.ChangeCreate], counts[agents.ChangeOverwrite], counts[agents.ChangeRemove], counts[agents.ChangeUnchanged], len(p.Conflicts))
+	return nil
+}
+
+// runInitDryRun resolves the init source and agent selection and reports
+// the files init would write, without writing anything.
+func runInitDryRun(cmd *cobra.Command, r io.Reader, w io.Writer) error {
+	root := workDir(cmd)
+	if err := guardAssetsRepo(root, "maestro init", initForceSelf); err != nil {
+		return err
+	}
+	if err := validateInitFlags(); err != nil {
+		return err
+	}
+	if err := validateOutputFormat(initOutput); err != nil {
+		return err
+	}
+	src, err := initSourceFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	if initOffline && initBundle == "" {
+		if err := verifyEmbeddedStarterAssets(); err != nil {
+			return fmt.Errorf("offline install: %w", err)
+		}
+	}
+
+	if err := src.prefetchStarterAssets(); err != nil {
+		return err
+	}
+
+	plan := newInstallPlan("init", src.description)
+	if _, err := os.Stat(filepath.Join(root, ".maestro")); err == nil {
+		plan.conflict(".maestro/ already exists; init will ask to overwrite, back up, merge, or cancel")
+	}
+
+	files, err := agents.PlanAssets(root, agents.RequiredStarterAssetDirs(), src.fetchDir)
+	if err != nil {
+		return fmt.Errorf("planning required starter assets: %w", err)
+	}
+	plan.Files = append(plan.Files, files...)
+
+	for _, filePath := range agents.RequiredStarterAssetFiles() {
+		if _, err := os.Stat(filepath.Join(root, filePath)); err == nil {
+			// init never overwrites existing root files
+			continue
+		}
+		content, err := src.fetchFile(filePath)
+		if err != nil {
+			plan.note("%s would be skipped: %v", filePath, err)
+			continue
+		}
+		file, err := agents.PlanFile(root, filePath, content)
+		if err != nil {
+			return err
+		}
+		plan.Files = append(plan.Files, file)
+	}
+	plan.generated(filepath.ToSlash(filepath.Join(".maestro", "config.yaml")))
+	// Init decides on merging .maestro/ by prompting, so the plan assumes
+	// a fresh install
+	mode, err := agentsMDMode(initAgentsMD, false)
+	if err != nil {
+		return err
+	}
+	agentsMD, err := planAgentsMD(root, mode)
+	if err != nil {
+		return err
+	}
+	plan.Files = append(plan.Files, agentsMD)
+
+	// Keep stdout for the JSON plan
+	promptOut := w
+	if output.Data(initOutput) {
+		promptOut = os.Stderr
+	}
+	selected, err := initAgentDirs(root, r, promptOut)
+	if err != nil {
+		return fmt.Errorf("selecting agent directories: %w", err)
+	}
+	if err := src.prefetch(selected, nil); err != nil {
+		return fmt.Errorf("planning agent configs: %w", err)
+	}
+	for _, dir := range findExistingDirectories(root, selected) {
+		plan.conflict("%s already exists; init will ask to overwrite, back up, or cancel", dir)
+	}
+	files, err = agents.PlanAssets(root, selected, agentDirFetcher(src))
+	if err != nil {
+		return fmt.Errorf("planning agent configs: %w", err)
+	}
+	plan.Files = append(plan.Files, files...)
+	plan.Files = append(plan.Files, planAgentInstructions(root, selected)...)
+
+	if dirs := adopt.Find(root); len(dirs) > 0 && initAdopt != "none" {
+		plan.note("Existing spec folders would be offered for adoption: %s", strings.Join(dirs, ", "))
+	}
+	if initGitignore {
+		plan.note(".gitignore would get entries for: %s", strings.Join(gitignoreEntries(), " "))
+	}
+	if initGit || initCommit {
+		if err := checkInitGit(root, !initCommit); err != nil {
+			plan.conflict("%v", err)
+		} else if initCommit {
+			plan.note("The installed files would be committed to the current branch")
+		} else {
+			plan.note("The installed files would be committed to a new %s branch", initGitBranch)
+		}
+	}
+
+	return plan.write(w, initOutput)
+}
+
+// runUpdateDryRun resolves the release update would move to on channel
+// and reports the files update would write, without writing anything in the project. A release archive
+// is downloaded into the asset cache so its files can be compared.
+// --agents-only and --assets-only limit the plan to what update would
+// write with them.
+func runUpdateDryRun(cmd *cobra.Command, w io.Writer, channel string) error {
+	root := workDir(cmd)
+	if err := checkUpdateTarget(root); err != nil {
+		return err
+	}
+	if err := validateOutputFormat(updateOutput); err != nil {
+		return err
+	}
+	client, err := assetsClient(root, updateFrom)
+	if err != nil {
+		return err
+	}
+
+	var plan *installPlan
+	if updateAgentsOnly {
+		plan = newInstallPlan("update", "GitHub "+agentSourceRef())
+		plan.note("With --agents-only, update would leave .maestro/ alone")
+	} else {
+		var upToDate bool
+		if plan, upToDate, err = planUpdateAssets(root, client, channel); err != nil {
+			return err
+		}
+		if upToDate {
+			return plan.write(w, updateOutput)
+		}
+	}
+	if updateAssetsOnly {
+		plan.note("With --assets-only, update would leave the agent directories alone")
+		return plan.write(w, updateOutput)
+	}
+
+	cfg, err := config.Load(projectConfig(root))
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	installed := agents.DetectInstalled(root)
+	for _, dir := range installed {
+		plan.conflict("%s will be refreshed; update will ask to overwrite, back up, or cancel", dir)
+		content, err := fetchAgentDirWithRefFallback(client, dir, agentSourceRef())
+		if err != nil {
+			return fmt.Errorf("fetching %s: %w", dir, err)
+		}
+		if dir == ".codex" {
+			content = agents.AddCodexCommandSkills(content)
+		}
+		files, err := agents.PlanDir(root, dir, content)
+		if err != nil {
+			return fmt.Errorf("planning %s: %w", dir, err)
+		}
+		plan.Files = append(plan.Files, files...)
+	}
+
+	var offered []string
+	for _, dir := range subtract(agents.KnownAgentDirs(), installed) {
+		if !cfg.Installed.IsDeclined(dir) {
+			offered = append(offered, dir)
+		}
+	}
+	if len(offered) > 0 {
+		plan.note("Update would offer to install: %s", strings.Join(offered, ", "))
+	}
+
+	return plan.write(w, updateOutput)
+}
+
+// planUpdateAssets resolves the release update would move to on channel
+// and plans the changes to .maestro/. upToDate is true when there are
+// none, and the plan says so. root is the project.
+func planUpdateAssets(root string, client *ghclient.Client, channel string) (plan *installPlan, upToDate bool, err error) {
+	platform, err := fs.DetectPlatform()
+	if err != nil {
+		return nil, false, fmt.Errorf("detecting platform: %w", err)
+	}
+	nightly := updateVersion == "" && channel == channelNightly
+	var release *ghclient.Release
+	switch {
+	case nightly:
+		release = &ghclient.Release{TagName: nightlyRef}
+	case updateVersion != "":
+		release, err = client.FetchReleaseByTag(updateVersion)
+	default:
+		release, err = fetchChannelRelease(client, channel)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("checking for updates: %w", err)
+	}
+
+	current, latest := version.Version, release.TagName
+	plan = newInstallPlan("update", "release "+latest)
+	if updateVersion != "" {
+		cfg, err := config.Load(projectConfig(root))
+		if err != nil {
+			return nil, false, fmt.Errorf("loading config: %w", err)
+		}
+		current = installedAssetVersion(cfg)
+		if older, err := version.Less(latest, current); err == nil && older {
+			plan.note("%s is older than the installed %s; update would ask before downgrading", latest, current)
+		}
+	}
+	if current != "dev" && current == latest {
+		plan.note("Already up to date (%s)", current)
+		return plan, true, nil
+	}
+
+	var content map[string][]byte
+	if asset, err := release.FindAssetForPlatform(platform.AssetSuffix()); nightly || err != nil {
+		ref := agentSourceRef()
+		if nightly {
+			ref = nightlyRef
+			plan.note("On the nightly channel, update would fetch .maestro/ from %s", ref)
+		} else {
+			plan.note("No release asset for platform %s; update would fetch .maestro/ from %s", platform.String(), ref)
+		}
+		plan.Source = "GitHub " + ref
+		fetched, err := client.FetchAgentDir(".maestro", ref)
+		if err != nil {
+			return nil, false, fmt.Errorf("fetching .maestro directory: %w", err)
+		}
+		content = make(map[string][]byte, len(fetched))
+		for filePath, data := range fetched {
+			content[strings.TrimPrefix(filePath, ".maestro/")] = data
+		}
+	} else {
+		cache, err := assets.NewCacheManager()
+		if err != nil {
+			return nil, false, fmt.Errorf("initializing cache: %w", err)
+		}
+		cachedPath, err := cache.GetRelease(asset.DownloadURL, latest, 0)
+		if err != nil {
+			return nil, false, fmt.Errorf("downloading update: %w", err)
+		}
+		if content, err = assets.ReadAsset(cachedPath); err != nil {
+			return nil, false, fmt.Errorf("reading update: %w", err)
+		}
+	}
+
+	cfg, err := config.Load(projectConfig(root))
+	if err != nil {
+		return nil, false, fmt.Errorf("loading config: %w", err)
+	}
+	results, ignored, err := planMaestroAssets(root, cfg, maestroAssetPaths(content))
+	if err != nil {
+		return nil, false, err
+	}
+	plan.merged(results)
+	if len(ignored) > 0 {
+		plan.note("%s leaves alone: %s", ignore.File, strings.Join(ignored, ", "))
+	}
+	plan.generated(filepath.ToSlash(filepath.Join(".maestro", "config.yaml")))
+	return plan, false, nil
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spec-maestro/maestro-cli/pkg/envreport"
+)
+
+// envReportUsage is the help text of the --env-report flag on init and doctor.
+const envReportUsage = "Print the detected platform, shell, git, and agent CLIs first, for debugging setup issues (nothing is sent anywhere)"
+
+// printEnvReport prints the local environment report followed by a blank line.
+func printEnvReport() {
+	envreport.Detect().Write(os.Stdout)
+	fmt.Println()
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/export"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export maestro artifacts for readers outside the repository",
+}
+
+var exportFeatureCmd = &cobra.Command{
+	Use:   "feature <id>",
+	Short: "Assemble a feature's spec, research, plan, and task status into one document",
+	Long: `Combines the spec, research synthesis, plan, and task status of a feature
+into a single document for stakeholders who don't read the repository.
+
+<id> is the feature directory name under .maestro/specs/ (e.g. 003-user-auth)
+or just its number (e.g. 003). Task status is read from bd when the feature
+has an epic and bd is installed, otherwise from the feature's tasks.json.
+
+Formats:
+  html              standalone page; paste into Confluence or import into Notion
+  docx              Word document; upload to Confluence or Google Docs
+  single-markdown   one markdown file; import into Notion or attach anywhere`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExportFeature,
+}
+
+var (
+	exportFormat string
+	exportOutput string
+)
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportFeatureCmd)
+	exportFeatureCmd.Flags().StringVar(&exportFormat, "format", "html", "Output format: "+strings.Join(export.Formats, ", "))
+	exportFeatureCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output file, or - for stdout (default: <feature-id> with the format's extension)")
+	addProjectPathFlag(exportFeatureCmd, true)
+}
+
+func runExportFeature(cmd *cobra.Command, args []string) error {
+	if export.Extension(exportFormat) == "" {
+		return fmt.Errorf("unknown format %q (want %s)", exportFormat, strings.Join(export.Formats, ", "))
+	}
+	root := workDir(cmd)
+	if _, err := os.Stat(filepath.Join(root, ".maestro")); os.IsNotExist(err) {
+		return fmt.Errorf("not initialized — run 'maestro init' first")
+	}
+
+	featureID, err := resolveFeatureID(filepath.Join(root, ".maestro", "specs"), args[0])
+	if err != nil {
+		return err
+	}
+	doc, err := export.Load(
+		filepath.Join(root, ".maestro", "specs", featureID),
+		filepath.Join(root, ".maestro", "state", featureID+".json"),
+		bdTaskLister(root),
+	)
+	if err != nil {
+		return fmt.Errorf("loading feature %s: %w", featureID, err)
+	}
+
+	content, err := export.Render(doc, exportFormat)
+	if err != nil {
+		return fmt.Errorf("rendering %s: %w", exportFormat, err)
+	}
+
+	if exportOutput == "-" {
+		_, err := os.Stdout.Write(content)
+		return err
+	}
+	output := userPath(cmd, exportOutput)
+	if output == "" {
+		output = userPath(cmd, featureID+export.Extension(exportFormat))
+	}
+	if err := os.WriteFile(output, content, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", output, err)
+	}
+
+	var included []string
+	for _, section := range doc.Sections {
+		included = append(included, strings.ToLower(section.Title))
+	}
+	if len(doc.Tasks) > 0 {
+		included = append(included, fmt.Sprintf("%d task(s) from %s", len(doc.Tasks), doc.TaskSource))
+	}
+	fmt.Printf("%s Exported %s to %s (%s)\n", glyph.OK(), featureID, output, strings.Join(included, ", "))
+	return nil
+}
+
+// resolveFeatureID accepts a feature directory name or its number prefix.
+func resolveFeatureID(specsDir, id string) (string, error) {
+	if err := checkFeatureID(id); err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(filepath.Join(specsDir, id)); err == nil && info.IsDir() {
+		return id, nil
+	}
+	entries, err := os.ReadDir(specsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("reading %s: %w", pathfmt.Rel(specsDir), err)
+	}
+	var matches []string
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), id+"-") {
+			matches = append(matches, entry.Name())
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("feature %q not found in %s", id, pathfmt.Rel(specsDir))
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("feature %q is ambiguous: %s", id, strings.Join(matches, ", "))
+	}
+}
+
+// checkFeatureID rejects an ID that is not a single directory name, such as
+// "..", which would otherwise resolve to a directory outside the feature's.
+func checkFeatureID(id string) error {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return fmt.Errorf("%q is not a feature ID or number", id)
+	}
+	return nil
+}
+
+// bdTaskLister lists an epic's tasks with bd in the project at root, or
+// returns nil when bd is not installed.
+func bdTaskLister(root string) export.TaskLister {
+	if _, err := exec.LookPath("bd"); err != nil {
+		return nil
+	}
+	return func(epicID string) ([]export.Task, error) {
+		c := exec.Command("bd", "list", "--all", "--parent", epicID, "--json", "--limit", "0")
+		c.Dir = root
+		out, err := c.Output()
+		if err != nil {
+			return nil, fmt.Errorf("listing tasks of %s: %w", epicID, err)
+		}
+		var tasks []export.Task
+		if err := json.Unmarshal(out, &tasks); err != nil {
+			return nil, fmt.Errorf("parsing bd output: %w", err)
+		}
+		return tasks, nil
+	}
+}
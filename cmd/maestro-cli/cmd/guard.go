@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/deadline"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/guard"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+)
+
+var guardCmd = &cobra.Command{
+	Use:   "guard -- <command> [args...]",
+	Short: "Snapshot .maestro/ before running a command, and roll back if it fails",
+	Long: `Takes a snapshot of .maestro/ (and, with --tracked, of every file git
+tracks) under ` + guard.Dir + `/, then runs the command from the current
+directory. If the command fails, guard offers to roll the project back to
+the snapshot; the rollback can also be run later with
+'maestro guard rollback'. The snapshot is removed when the command
+succeeds, unless --keep is given.
+
+guard exits with the command's exit code.`,
+	Example: `  maestro guard -- bash .maestro/scripts/generated-migration.sh
+  maestro guard --tracked -- make codegen`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runGuard,
+}
+
+var guardRollbackCmd = &cobra.Command{
+	Use:   "rollback [snapshot]",
+	Short: "Restore the project to a guard snapshot",
+	Long: `Restores .maestro/ to the latest guard snapshot, or the one given, removing
+files created in .maestro/ since. Tracked files saved with --tracked are
+written back; other files outside .maestro/ are left alone.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runGuardRollback,
+}
+
+var (
+	guardTracked bool
+	guardKeep    bool
+	guardList    bool
+)
+
+func init() {
+	rootCmd.AddCommand(guardCmd)
+	guardCmd.AddCommand(guardRollbackCmd)
+	guardCmd.Flags().BoolVar(&guardTracked, "tracked", false, "Also snapshot the files git tracks")
+	guardCmd.Flags().BoolVar(&guardKeep, "keep", false, "Keep the snapshot when the command succeeds")
+	guardRollbackCmd.Flags().BoolVar(&guardList, "list", false, "List the guard snapshots instead of rolling back")
+	addProjectPathFlag(guardCmd, true)
+	addProjectPathFlag(guardRollbackCmd, true)
+}
+
+func runGuard(cmd *cobra.Command, args []string) error {
+	root := workDir(cmd)
+	if _, err := os.Stat(filepath.Join(root, ".maestro")); os.IsNotExist(err) {
+		return fmt.Errorf("not initialized — run 'maestro init' first")
+	}
+	if dash := cmd.ArgsLenAtDash(); dash > 0 {
+		return fmt.Errorf("unexpected arguments before --: %s", strings.Join(args[:dash], " "))
+	}
+
+	var tracked []string
+	if guardTracked {
+		out, err := gitOutput(root, "ls-files", "-z")
+		if err != nil {
+			return fmt.Errorf("listing tracked files: %w", err)
+		}
+		for _, file := range strings.Split(out, "\x00") {
+			if file != "" {
+				tracked = append(tracked, file)
+			}
+		}
+	}
+	snapshotPath, err := guard.Take(root, tracked, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s Snapshot saved to %s\n", glyph.OK(), pathfmt.Path(snapshotPath))
+
+	code, err := runGuarded(cmdDirs(cmd).invocation, args)
+	if err != nil {
+		return fmt.Errorf("running %s: %w (snapshot kept at %s)", args[0], err, pathfmt.Rel(snapshotPath))
+	}
+	if code == 0 {
+		if !guardKeep {
+			os.Remove(snapshotPath)
+		}
+		return nil
+	}
+
+	if timeoutErr := deadline.Err(); timeoutErr != nil {
+		fmt.Printf("%s %s stopped: %v\n", glyph.Fail(), args[0], timeoutErr)
+	} else {
+		fmt.Printf("%s %s exited with status %d\n", glyph.Fail(), args[0], code)
+	}
+	if err := offerRollback(root, os.Stdin, os.Stdout, snapshotPath); err != nil {
+		return err
+	}
+	// The command already reported its failure
+	cmd.SilenceErrors, cmd.SilenceUsage = true, true
+	return &exitError{code: code}
+}
+
+// runGuarded runs the guarded command with the terminal attached, from dir,
+// the directory maestro was run in, and returns its exit code. err is only
+// set when the command could not be started.
+func runGuarded(dir string, args []string) (int, error) {
+	// --timeout bounds the guarded command too
+	c := exec.CommandContext(deadline.Context(), args[0], args[1:]...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	c.Dir = dir
+	err := c.Run()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		return exit.ExitCode(), nil
+	}
+	return 0, err
+}
+
+// offerRollback asks to roll back to snapshotPath after the guarded command
+// failed, and otherwise prints the command that does it. root is the
+// project.
+func offerRollback(root string, r io.Reader, w io.Writer, snapshotPath string) error {
+	ok, err := confirm(r, w, "Roll back to the snapshot taken before it ran?")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		fmt.Fprintf(w, "To roll back later: maestro guard rollback %s\n", pathfmt.Rel(snapshotPath))
+		return nil
+	}
+	return rollbackTo(root, w, snapshotPath)
+}
+
+func runGuardRollback(cmd *cobra.Command, args []string) error {
+	root := workDir(cmd)
+	if _, err := os.Stat(filepath.Join(root, ".maestro")); os.IsNotExist(err) {
+		return fmt.Errorf("not initialized — run 'maestro init' first")
+	}
+	snapshots, err := guard.List(root)
+	if err != nil {
+		return err
+	}
+	if guardList {
+		if len(snapshots) == 0 {
+			fmt.Println("No guard snapshots.")
+		}
+		for _, path := range snapshots {
+			at, _ := guard.Time(path)
+			fmt.Printf("%s  %s\n", at.Format("2006-01-02 15:04:05"), pathfmt.Path(path))
+		}
+		return nil
+	}
+
+	var snapshotPath string
+	switch {
+	case len(args) == 1:
+		snapshotPath = guardSnapshotPath(cmd, args[0])
+	case len(snapshots) > 0:
+		snapshotPath = snapshots[len(snapshots)-1]
+	default:
+		return fmt.Errorf("no guard snapshots; 'maestro guard -- <command>' takes one before running the command")
+	}
+	return rollbackTo(root, os.Stdout, snapshotPath)
+}
+
+// guardSnapshotPath resolves a snapshot named on the command line: a path
+// from the current directory, a path from the project root as guard
+// prints them, or a file name in the snapshots directory.
+func guardSnapshotPath(cmd *cobra.Command, arg string) string {
+	root := workDir(cmd)
+	for _, candidate := range []string{userPath(cmd, arg), inProject(root, arg)} {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return filepath.Join(root, filepath.FromSlash(guard.Dir), filepath.Base(arg))
+}
+
+// rollbackTo restores the project at root to snapshotPath and reports what
+// changed.
+func rollbackTo(root string, w io.Writer, snapshotPath string) error {
+	written, removed, err := guard.Rollback(root, snapshotPath)
+	if err != nil {
+		return fmt.Errorf("rolling back: %w", err)
+	}
+	fmt.Fprintf(w, "%s Rolled back to %s (%d file(s) restored, %d removed)\n", glyph.OK(), pathfmt.Path(snapshotPath), written, removed)
+	return nil
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/importer"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <tool> <dir>",
+	Short: "Import features from another spec-driven tool",
+	Long: `Converts a project written with another spec-driven tool into maestro
+features. Each feature's documents are copied to a new numbered directory
+under .maestro/specs/, a state entry is written to .maestro/state/ at the
+stage the feature had reached, and its task checklist is converted to a
+tasks.json that .maestro/scripts/create-tasks.sh accepts.
+
+The source directory is only read, never modified.
+
+Supported tools: ` + strings.Join(importer.Names(), ", "),
+	ValidArgs: importer.Names(),
+	Args:      cobra.ExactArgs(2),
+	RunE:      runImport,
+}
+
+var importDryRun bool
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Show what would be imported without writing anything")
+	addProjectPathFlag(importCmd, true)
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	root := workDir(cmd)
+	tool, dir := args[0], userPath(cmd, args[1])
+
+	imp, ok := importer.Lookup(tool)
+	if !ok {
+		return fmt.Errorf("unknown tool %q (supported: %s)", tool, strings.Join(importer.Names(), ", "))
+	}
+	if _, err := os.Stat(filepath.Join(root, ".maestro")); os.IsNotExist(err) {
+		return fmt.Errorf("not initialized — run 'maestro init' first")
+	}
+
+	project, err := imp.Scan(dir)
+	if err != nil {
+		return fmt.Errorf("reading %s project: %w", tool, err)
+	}
+
+	specsDir := filepath.Join(".maestro", "specs")
+	planned := importer.Plan(root, project, specsDir)
+
+	if importDryRun {
+		fmt.Printf("Would import %d feature(s) from %s:\n", len(planned), dir)
+		for _, feature := range planned {
+			fmt.Printf("  %s %s %s (stage: %s, %d file(s), %d task(s))\n", feature.Source, glyph.Arrow(), filepath.Join(specsDir, feature.FeatureID), feature.Stage, len(feature.Files), len(feature.Tasks))
+		}
+		printImportNotes(project.Notes)
+		return nil
+	}
+
+	imported, err := importer.Apply(root, tool, planned, specsDir, filepath.Join(".maestro", "state"))
+	for _, feature := range imported {
+		fmt.Printf("%s Imported %s %s %s (stage: %s", glyph.OK(), feature.Source, glyph.Arrow(), filepath.Join(specsDir, feature.FeatureID), feature.Stage)
+		if len(feature.Tasks) > 0 {
+			fmt.Printf(", %d task(s) in tasks.json", len(feature.Tasks))
+		}
+		fmt.Println(")")
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d feature(s) from %s.\n", len(imported), tool)
+	printImportNotes(project.Notes)
+	return nil
+}
+
+func printImportNotes(notes []string) {
+	for _, note := range notes {
+		fmt.Printf("%s %s\n", glyph.Warn(), note)
+	}
+}
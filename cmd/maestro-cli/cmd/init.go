@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -12,9 +13,17 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/spec-maestro/maestro-cli/internal/version"
+	"github.com/spec-maestro/maestro-cli/pkg/adopt"
 	"github.com/spec-maestro/maestro-cli/pkg/agents"
+	"github.com/spec-maestro/maestro-cli/pkg/agentsmd"
+	"github.com/spec-maestro/maestro-cli/pkg/bundle"
 	"github.com/spec-maestro/maestro-cli/pkg/config"
+	"github.com/spec-maestro/maestro-cli/pkg/deadline"
+	"github.com/spec-maestro/maestro-cli/pkg/doctor"
 	"github.com/spec-maestro/maestro-cli/pkg/embedded"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+	"github.com/spec-maestro/maestro-cli/pkg/report"
 )
 
 var initCmd = &cobra.Command{
@@ -28,59 +37,188 @@ var (
 	initWithOpenCode bool
 	initWithClaude   bool
 	initWithCodex    bool
+	initWithAll      bool
+	initWithNone     bool
+	initOffline      bool
+	initAdopt        string
+	initVersion      string
+	initRef          string
+	initForceSelf    bool
+	initGit          bool
+	initCommit       bool
+	initOutput       string
+	initDryRun       bool
+	initAnswersPath  string
+	initProjectName  string
+	initDescription  string
+	initBaseBranch   string
+	initFrom         string
+	initBundle       string
+	initInteractive  bool
+	initGitignore    bool
+	initAgentsMD     string
+	initSkipVerify   bool
+	initEnvReport    bool
 )
 
 func init() {
 	rootCmd.AddCommand(initCmd)
+	addProjectPathFlag(initCmd, false)
 	initCmd.Flags().BoolVar(&initWithOpenCode, "with-opencode", false, "Install .opencode agent config directory")
 	initCmd.Flags().BoolVar(&initWithClaude, "with-claude", false, "Install .claude agent config directory")
 	initCmd.Flags().BoolVar(&initWithCodex, "with-codex", false, "Install .codex agent config directory")
+	initCmd.Flags().BoolVar(&initWithAll, "with-all", false, "Install every known agent config directory")
+	initCmd.Flags().BoolVar(&initWithNone, "with-none", false, "Install no agent config directories and skip the selection prompt")
+	initCmd.Flags().BoolVar(&initForceSelf, "force-self", false, "Allow running inside the maestro assets repository")
+	initCmd.Flags().StringVar(&initAdopt, "adopt", "", "Adopt existing specs/ or docs/rfcs/ documents: symlink, move, or none (default: prompt)")
+	initCmd.Flags().BoolVar(&initOffline, "offline", false, "Require every starter asset to come from the binary; fail fast if any are missing")
+	initCmd.Flags().StringVar(&initVersion, "version", "", "Install assets from this release tag (e.g. v1.2.0) instead of the embedded copy")
+	initCmd.Flags().StringVar(&initRef, "ref", "", "Install assets from this branch, tag, or commit SHA instead of the embedded copy")
+	initCmd.Flags().StringVar(&initBundle, "bundle", "", "Install assets from a bundle written by 'maestro bundle create', verified first")
+	initCmd.Flags().StringVar(&initFrom, "from", "", "Install assets from a custom GitHub repository (owner/repo or URL) and use it for later updates")
+	initCmd.Flags().BoolVar(&initGit, "git", false, "Commit the installed files to a new "+initGitBranch+" branch")
+	initCmd.Flags().StringVar(&initAgentsMD, "agents-md-mode", "", "How to treat an existing AGENTS.md: skip, append, merge (managed block), or overwrite (default: overwrite, or skip when merging)")
+	initCmd.Flags().BoolVar(&initEnvReport, "env-report", false, envReportUsage)
+	initCmd.Flags().BoolVar(&initSkipVerify, "skip-verify", false, "Skip the doctor checks init runs after installing")
+	initCmd.Flags().BoolVar(&initGitignore, "gitignore", false, "Add .gitignore entries for maestro's lock files and backup directories")
+	initCmd.Flags().BoolVar(&initCommit, "commit", false, "Commit the installed files to the current branch instead of a new branch (implies --git)")
+	initCmd.Flags().StringVar(&initOutput, "output", "text", outputFormatUsage)
+	initCmd.Flags().StringVar(&initProjectName, "name", "", "Project name to record in config.yaml (default: the directory name)")
+	initCmd.Flags().StringVar(&initDescription, "description", "", "Short project description to record in config.yaml")
+	initCmd.Flags().StringVar(&initBaseBranch, "base-branch", "", "Base branch to record in config.yaml (default: the remote's default branch, or the current branch)")
+	initCmd.Flags().StringVar(&initAnswersPath, "answers", "", "Read agent dirs, conflict action, adoption, and project settings from this YAML file and never prompt")
+	initCmd.Flags().BoolVar(&initInteractive, "interactive", false, "Choose agent dirs and conflict handling in a full-screen wizard (falls back to plain prompts without a terminal)")
+	initCmd.Flags().BoolVar(&initDryRun, "dry-run", false, "List the files init would create or overwrite and flag conflicts, without writing anything")
 }
 
-func runInit(cmd *cobra.Command, args []string) error {
-	maestroDir := ".maestro"
+func runInit(cmd *cobra.Command, args []string) (err error) {
+	root := workDir(cmd)
+	maestroDir := filepath.Join(root, ".maestro")
 
-	fmt.Printf("Installing maestro %s resources...\n", version.Version)
+	if err := applyInitAnswers(cmd, userPath(cmd, initAnswersPath)); err != nil {
+		return err
+	}
+	if initDryRun {
+		return runInitDryRun(cmd, os.Stdin, os.Stdout)
+	}
+
+	op, finish, err := beginOperation("init", initOutput)
+	if err != nil {
+		return err
+	}
+	defer func() { err = finish(err) }()
+
+	if initEnvReport {
+		printEnvReport()
+	}
+	if err := guardAssetsRepo(root, "maestro init", initForceSelf); err != nil {
+		return err
+	}
+	if err := validateInitFlags(); err != nil {
+		return err
+	}
+	if err := guardTargetDir(cmd); err != nil {
+		return err
+	}
+	if initGit || initCommit {
+		if err := checkInitGit(root, !initCommit); err != nil {
+			return err
+		}
+	}
+
+	src, err := initSourceFromFlags(cmd)
+	if err != nil {
+		return op.Fail("resolve source", err)
+	}
+
+	if initInteractive {
+		if !canRunWizard() || nonInteractive || unattended {
+			fmt.Println("Not running the init wizard without a terminal or in accessible mode; using plain prompts.")
+		} else {
+			ok, err := runInitWizard(root, initWizardUI, src.description, cmd.Flags().Changed("conflict-action"))
+			if err != nil {
+				return op.Fail("wizard", err)
+			}
+			if !ok {
+				fmt.Println("Aborted.")
+				op.Skip("wizard", "cancelled; nothing installed")
+				return nil
+			}
+			op.OK("wizard", "")
+		}
+	}
+
+	fmt.Printf("Installing maestro resources from %s...\n", src.description)
+	if err := src.prefetchStarterAssets(); err != nil {
+		return op.Fail("fetch assets", err)
+	}
+
+	if initOffline && initBundle == "" {
+		if err := verifyEmbeddedStarterAssets(); err != nil {
+			return op.Fail("verify embedded assets", fmt.Errorf("offline install: %w", err))
+		}
+		op.OK("verify embedded assets", "all starter assets are in the binary")
+	}
 
 	// Check if already initialized
+	merging := false
 	if _, err := os.Stat(maestroDir); err == nil {
-		fmt.Println(".maestro/ already exists. What would you like to do?")
-		fmt.Println("  [o] Overwrite existing files")
-		fmt.Println("  [b] Backup existing and reinitialize")
-		fmt.Println("  [c] Cancel (default)")
-		fmt.Print("Choice [o/b/c]: ")
-
-		reader := bufio.NewReader(os.Stdin)
-		choice, err := reader.ReadString('\n')
+		action, err := promptReinit(root, os.Stdin, os.Stdout, maestroDir)
 		if err != nil {
 			return fmt.Errorf("reading input: %w", err)
 		}
-		choice = strings.TrimSpace(strings.ToLower(choice))
 
-		switch choice {
-		case "o":
+		switch action {
+		case agents.ConflictOverwrite:
 			fmt.Println("Overwriting existing .maestro/...")
-		case "b":
-			backup := fmt.Sprintf(".maestro-backup-%s", time.Now().Format("20060102-150405"))
+			op.OK("existing .maestro/", "overwritten")
+		case agents.ConflictBackup:
+			backup := filepath.Join(root, fmt.Sprintf(".maestro-backup-%s", time.Now().Format("20060102-150405")))
 			if err := os.Rename(maestroDir, backup); err != nil {
-				return fmt.Errorf("creating backup: %w", err)
+				return op.Fail("existing .maestro/", fmt.Errorf("creating backup: %w", err))
 			}
-			fmt.Printf("Backup created: %s\n", backup)
+			fmt.Printf("Backup created: %s\n", pathfmt.Path(backup))
+			op.OK("existing .maestro/", "backed up to "+pathfmt.Rel(backup))
+		case agents.ConflictMerge:
+			fmt.Println("Merging into existing .maestro/...")
+			op.OK("existing .maestro/", "merging")
+			merging = true
 		default:
 			fmt.Println("Aborted.")
+			op.Skip("existing .maestro/", "cancelled; nothing installed")
 			return nil
 		}
+	} else if nonInteractive {
+		action, err := parseConflictAction(conflictActionDefault)
+		merging = err == nil && action == agents.ConflictMerge
 	}
 
-	// Install .maestro/ core directories from embedded resources
-	// Uses the transactional installer with conflict handling
-	if err := installRequiredStarterAssets(os.Stdin, os.Stdout); err != nil {
-		return fmt.Errorf("installing required starter assets: %w", err)
+	// Install .maestro/ core directories from the selected source
+	// Uses the transactional installer with conflict handling, or merges
+	// into the existing files without touching the ones the user changed
+	var merged *agents.MergeResult
+	if merging {
+		result, err := mergeAssets(root, agents.RequiredStarterAssetDirs(), src.fetchDir, os.Stdout)
+		if err != nil {
+			return op.Fail("starter assets", fmt.Errorf("merging required starter assets: %w", err))
+		}
+		merged = result
+		recordMerge(op, "starter assets", result)
+	} else if err := installRequiredStarterAssets(root, src, os.Stdin, os.Stdout); err != nil {
+		return op.Fail("starter assets", fmt.Errorf("installing required starter assets: %w", err))
+	} else {
+		op.OK("starter assets", "installed from "+src.description)
 	}
 
 	// Install required root files (constitution.md, etc.)
-	if err := installRequiredStarterFiles(); err != nil {
-		return fmt.Errorf("installing required starter files: %w", err)
+	unavailable, err := installRequiredStarterFiles(root, src)
+	if err != nil {
+		return op.Fail("starter files", fmt.Errorf("installing required starter files: %w", err))
+	}
+	if len(unavailable) > 0 {
+		op.Warn("starter files", "could not fetch "+strings.Join(unavailable, ", "))
+	} else {
+		op.OK("starter files", "")
 	}
 
 	// Create user data directories (empty — not fetched from embedded)
@@ -91,52 +229,452 @@ func runInit(cmd *cobra.Command, args []string) error {
 		filepath.Join(maestroDir, "memory"),
 	} {
 		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("creating directory %s: %w", dir, err)
+			return op.Fail("data directories", fmt.Errorf("creating directory %s: %w", pathfmt.Rel(dir), err))
 		}
 	}
 
-	// Write config
+	if err := adoptExistingSpecs(root, os.Stdin, os.Stdout); err != nil {
+		return op.Fail("adopt existing specs", fmt.Errorf("adopting existing specs: %w", err))
+	}
+
+	// Write config, keeping the existing settings and manifest when merging
+	configPath := filepath.Join(maestroDir, "config.yaml")
 	cfg := &config.ProjectConfig{
-		CLIVersion:    version.Version,
+		CLIVersion:    src.cliVersion,
 		InitializedAt: time.Now(),
 	}
-	if err := config.Save(cfg, filepath.Join(maestroDir, "config.yaml")); err != nil {
-		return fmt.Errorf("saving config: %w", err)
+	if merging {
+		existing, err := config.Load(configPath)
+		if err != nil {
+			return op.Fail("config", fmt.Errorf("loading config: %w", err))
+		}
+		existing.CLIVersion = src.cliVersion
+		if existing.InitializedAt.IsZero() {
+			existing.InitializedAt = cfg.InitializedAt
+		}
+		cfg = existing
+	}
+	if src.repo != "" {
+		cfg.Source = src.repo
+	}
+	project, err := resolveProjectSection(root, os.Stdin, os.Stdout, cfg.Project)
+	if err != nil {
+		return op.Fail("config", fmt.Errorf("asking for project settings: %w", err))
+	}
+	cfg.Project = project
+	if err := config.Save(cfg, configPath); err != nil {
+		return op.Fail("config", fmt.Errorf("saving config: %w", err))
 	}
+	op.OK("config", pathfmt.Rel(configPath))
 
 	// Generate AGENTS.md (basic version)
-	agentsMD := "# Maestro Agent Instructions\n\nRun `maestro doctor` to validate setup.\nRun `maestro update` to update to the latest version.\n"
-	if err := os.WriteFile("AGENTS.md", []byte(agentsMD), 0644); err != nil {
-		return fmt.Errorf("writing AGENTS.md: %w", err)
+	mode, err := agentsMDMode(initAgentsMD, merging)
+	if err != nil {
+		return op.Fail("AGENTS.md", err)
+	}
+	if err := writeAgentsMD(root, op, mode); err != nil {
+		return err
 	}
 
-	selectedAgentDirs, err := selectInitAgentDirs(initWithOpenCode, initWithClaude, initWithCodex, os.Stdin, os.Stdout)
+	selectedAgentDirs, err := initAgentDirs(root, os.Stdin, os.Stdout)
 	if err != nil {
-		return fmt.Errorf("installing agent configs: selecting agent directories: %w", err)
+		return op.Fail("agent configs", fmt.Errorf("installing agent configs: selecting agent directories: %w", err))
+	}
+	if err := src.prefetch(selectedAgentDirs, nil); err != nil {
+		return op.Fail("agent configs", fmt.Errorf("installing agent configs: %w", err))
 	}
 
-	if len(selectedAgentDirs) > 0 {
-		action, conflicting, err := handleAgentConflicts(selectedAgentDirs)
+	var installedAgentDirs []string
+	if len(selectedAgentDirs) > 0 && merging {
+		result, err := mergeAssets(root, selectedAgentDirs, agentDirFetcher(src), os.Stdout)
+		if err != nil {
+			return op.Fail("agent configs", fmt.Errorf("merging agent configs: %w", err))
+		}
+		merged.Append(result)
+		installedAgentDirs = selectedAgentDirs
+		recordMerge(op, "agent configs", result)
+	} else if len(selectedAgentDirs) > 0 {
+		actions, conflicting, err := handleAgentConflicts(root, selectedAgentDirs)
 		if err != nil {
-			return fmt.Errorf("installing agent configs: %w", err)
+			return op.Fail("agent configs", fmt.Errorf("installing agent configs: %w", err))
 		}
 
-		if err := applyConflictAction(action, conflicting); err != nil {
-			return fmt.Errorf("installing agent configs: %w", err)
+		cancelled, err := applyConflictActions(root, actions, conflicting)
+		if err != nil {
+			return op.Fail("agent configs", fmt.Errorf("installing agent configs: %w", err))
 		}
 
-		if action != agents.ConflictCancel {
-			if err := installEmbeddedAgentDirs(selectedAgentDirs); err != nil {
-				return fmt.Errorf("installing agent configs: %w", err)
+		if len(cancelled) == 0 || len(cancelled) < len(conflicting) {
+			install := subtract(selectedAgentDirs, cancelled)
+			if err := installAgentDirs(root, src, install); err != nil {
+				return op.Fail("agent configs", fmt.Errorf("installing agent configs: %w", err))
 			}
+			installedAgentDirs = install
+			op.OK("agent configs", strings.Join(installedAgentDirs, ", "))
+		} else {
+			op.Skip("agent configs", "cancelled")
+		}
+	} else {
+		op.Skip("agent configs", "none selected")
+		op.FollowUp("Install agent commands later with 'maestro init --with-<agent>'")
+	}
+
+	instructions, err := writeAgentInstructions(root, installedAgentDirs)
+	if err != nil {
+		return op.Fail("agent instructions", err)
+	}
+	if len(instructions) > 0 {
+		fmt.Printf("Wrote agent instructions: %s\n", strings.Join(instructions, ", "))
+		op.OK("agent instructions", strings.Join(instructions, ", "))
+	}
+
+	if err := recordInitManifest(root, src, selectedAgentDirs, installedAgentDirs, merged); err != nil {
+		return op.Fail("install manifest", fmt.Errorf("recording install manifest: %w", err))
+	}
+	agentCommands, err := recordAgentCommands(root, installedAgentDirs)
+	if err != nil {
+		return op.Fail("install manifest", fmt.Errorf("recording agent commands: %w", err))
+	}
+	op.OK("install manifest", "")
+
+	if initGitignore {
+		added, err := ensureGitignore(filepath.Join(root, ".gitignore"), gitignoreEntries())
+		if err != nil {
+			return op.Fail("gitignore", err)
+		}
+		if len(added) == 0 {
+			op.Skip("gitignore", "entries already present")
+		} else {
+			fmt.Printf("Added %d entries to .gitignore\n", len(added))
+			op.OK("gitignore", fmt.Sprintf("%d entries added", len(added)))
+		}
+	}
+
+	if initSkipVerify {
+		op.Skip("verify", "--skip-verify")
+	} else if err := verifyInit(op, maestroDir); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Maestro initialized successfully!\n", glyph.OK())
+	printAgentCommands(os.Stdout, installedAgentDirs, agentCommands)
+	for _, dir := range installedAgentDirs {
+		if c, ok := specifyCommand(agentCommands[dir]); ok {
+			op.FollowUp("In %s, start with %s", agents.AgentName(dir), c.Usage())
+		}
+	}
+
+	if initGit || initCommit {
+		paths := append([]string{".maestro", "AGENTS.md"}, installedAgentDirs...)
+		paths = append(paths, instructions...)
+		if initGitignore {
+			paths = append(paths, ".gitignore")
+		}
+		if err := commitInit(root, paths, !initCommit); err != nil {
+			return op.Fail("git commit", fmt.Errorf("committing initialized files: %w", err))
+		}
+		op.OK("git commit", initGitCommitMessage)
+	}
+	op.FollowUp("Run 'maestro doctor' to validate the setup")
+	return nil
+}
+
+// recordMerge records a merge step, warning about the files it kept.
+func recordMerge(op *report.Operation, step string, result *agents.MergeResult) {
+	detail := fmt.Sprintf("%d added, %d updated, %d unchanged, %d kept",
+		len(result.Added), len(result.Updated), len(result.Unchanged), len(result.Kept))
+	if len(result.Kept) == 0 {
+		op.OK(step, detail)
+		return
+	}
+	op.Warn(step, detail)
+	op.FollowUp("Review the kept files and merge upstream changes by hand: %s", strings.Join(result.Kept, ", "))
+}
+
+// validateInitFlags rejects flag combinations init cannot honour.
+func validateInitFlags() error {
+	if err := validateInitAgentFlags(); err != nil {
+		return err
+	}
+	if initAdopt != "" && initAdopt != "none" {
+		if _, err := adopt.ParseMode(initAdopt); err != nil {
+			return err
+		}
+	}
+
+	if initAgentsMD != "" {
+		if _, err := agentsmd.ParseMode(initAgentsMD); err != nil {
+			return err
 		}
 	}
 
-	fmt.Println("✓ Maestro initialized successfully!")
+	if initVersion != "" && initRef != "" {
+		return fmt.Errorf("--version and --ref cannot be used together")
+	}
+	if initOffline && (initVersion != "" || initRef != "" || initFrom != "") {
+		return fmt.Errorf("--offline cannot be combined with --version, --ref, or --from")
+	}
+	if initBundle != "" && (initVersion != "" || initRef != "" || initFrom != "") {
+		return fmt.Errorf("--bundle cannot be combined with --version, --ref, or --from")
+	}
 	return nil
 }
 
-func selectInitAgentDirs(withOpenCode, withClaude, withCodex bool, r io.Reader, w io.Writer) ([]string, error) {
+// initSourceFromFlags returns the embedded source, the bundle given with
+// --bundle, or the repository, release, or ref chosen with --from,
+// --version, or --ref.
+func initSourceFromFlags(cmd *cobra.Command) (*initSource, error) {
+	if initBundle != "" {
+		return bundleInitSource(userPath(cmd, initBundle))
+	}
+	if initFrom != "" || initVersion != "" || initRef != "" {
+		return pinnedInitSource(initFrom, initVersion, initRef)
+	}
+	return embeddedInitSource(), nil
+}
+
+// initSource supplies the starter assets and agent directories init installs.
+type initSource struct {
+	description string
+	fetchDir    agents.AssetFetcher
+	fetchFile   func(filePath string) ([]byte, error)
+	// ref and commit identify the upstream source; empty for embedded assets.
+	ref    string
+	commit string
+	// repo is the owner/repo given with --from, recorded as the project's
+	// source; empty for the upstream repository.
+	repo string
+	// bundle is the archive given with --bundle; empty otherwise.
+	bundle string
+	// cliVersion is recorded as cli_version so later refreshes use the same release.
+	cliVersion string
+	// cache holds what prefetch fetched; nil until the first prefetch.
+	cache *prefetched
+}
+
+// lastUpdate describes an install from this source for the config's
+// installed.last_update record.
+func (s *initSource) lastUpdate(command string, at time.Time) config.LastUpdate {
+	source := s.ref
+	if s.bundle != "" {
+		source = "bundle " + s.cliVersion
+	} else if source == "" {
+		source = "embedded " + s.cliVersion
+	} else if s.repo != "" {
+		source = s.repo + "@" + s.ref
+	}
+	return config.LastUpdate{At: at, Command: command, Source: source, Commit: s.commit}
+}
+
+// embeddedInitSource returns the default source: resources compiled into the binary.
+func embeddedInitSource() *initSource {
+	return &initSource{
+		description: fmt.Sprintf("embedded resources (%s)", version.Version),
+		fetchDir:    embedded.NewAssetFetcher(),
+		fetchFile:   embedded.FetchFile,
+		cliVersion:  version.Version,
+	}
+}
+
+// bundleInitSource returns the assets of the bundle at path, once it has
+// verified the bundle's manifest and checksums and that this maestro can
+// install it.
+func bundleInitSource(path string) (*initSource, error) {
+	files, manifest, err := bundle.Load(path, version.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &initSource{
+		description: fmt.Sprintf("bundle %s (%s)", manifest.Version, filepath.Base(path)),
+		fetchDir: func(dir string) (map[string][]byte, error) {
+			content := make(map[string][]byte)
+			for name, data := range files {
+				if rel, ok := strings.CutPrefix(name, dir+"/"); ok {
+					content[rel] = data
+				}
+			}
+			if len(content) == 0 {
+				return nil, fmt.Errorf("bundle %s has no %s", filepath.Base(path), dir)
+			}
+			return content, nil
+		},
+		fetchFile: func(filePath string) ([]byte, error) {
+			data, ok := files[filePath]
+			if !ok {
+				return nil, fmt.Errorf("bundle %s has no %s", filepath.Base(path), filePath)
+			}
+			return data, nil
+		},
+		bundle:     path,
+		cliVersion: manifest.Version,
+	}, nil
+}
+
+// adoptExistingSpecs offers to bring documents from conventional spec
+// folders (specs/, docs/rfcs/) under .maestro/specs/, creating a state entry
+// for each, and reports what was imported. root is the project.
+func adoptExistingSpecs(root string, r io.Reader, w io.Writer) error {
+	dirs := adopt.Find(root)
+	if len(dirs) == 0 || initAdopt == "none" {
+		return nil
+	}
+
+	choice := initAdopt
+	if choice == "" {
+		if nonInteractive || unattended {
+			fmt.Fprintf(w, "Found existing spec folders (%s); rerun with --adopt=symlink or --adopt=move to import them.\n", strings.Join(dirs, ", "))
+			return nil
+		}
+		fmt.Fprintf(w, "Found existing spec folders: %s\n", strings.Join(dirs, ", "))
+		fmt.Fprint(w, "Adopt them into .maestro/specs/? [s]ymlink, [m]ove, [n]o (default: n): ")
+		// A read error, including a --prompt-timeout, takes the default;
+		// running out of --timeout stops init
+		response, err := bufio.NewReader(promptInput(r)).ReadString('\n')
+		if errors.Is(err, deadline.ErrExceeded) {
+			return err
+		}
+		choice = strings.TrimSpace(strings.ToLower(response))
+		if choice == "" || choice == "n" || choice == "no" {
+			fmt.Fprintln(w, "Skipped adopting existing specs.")
+			return nil
+		}
+	}
+
+	mode, err := adopt.ParseMode(choice)
+	if err != nil {
+		return err
+	}
+
+	specsDir := filepath.Join(".maestro", "specs")
+	docs, err := adopt.Plan(root, dirs, specsDir)
+	if err != nil {
+		return err
+	}
+	adopted, err := adopt.Apply(root, docs, mode, specsDir, filepath.Join(".maestro", "state"))
+	for _, doc := range adopted {
+		fmt.Fprintf(w, "%s Adopted %s %s %s\n", glyph.OK(), doc.Source, glyph.Arrow(), doc.Dest(specsDir))
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Imported %d spec(s) from %s (%s); state entries written to .maestro/state/\n", len(adopted), strings.Join(dirs, ", "), mode)
+	return nil
+}
+
+// recordInitManifest records the asset version, installed agent directories
+// (with their upstream commit when known, so 'maestro update' can refresh
+// them incrementally), file checksums, and declined agent directories in
+// config.yaml. Agent directories count as declined only when the user made a
+// choice, not when selection was skipped in non-interactive mode.
+func recordInitManifest(root string, src *initSource, selected, installed []string, merged *agents.MergeResult) error {
+	configPath := projectConfig(root)
+	now := time.Now()
+	for _, dir := range installed {
+		if err := config.RecordAgentDir(configPath, dir, src.ref, src.commit); err != nil {
+			return err
+		}
+		if err := config.RecordAgentDirUpdate(configPath, dir, now); err != nil {
+			return err
+		}
+	}
+	if err := config.RecordUpdate(configPath, src.lastUpdate("init", now)); err != nil {
+		return err
+	}
+
+	// A merge keeps files the user changed, so only the files that now match
+	// the installed version are recorded; the rest keep their old checksums
+	// and still show up as drift.
+	if merged != nil {
+		if err := config.RecordFiles(configPath, src.cliVersion, merged.Current()); err != nil {
+			return err
+		}
+	} else {
+		roots := append(agents.RequiredStarterAssetDirs(), installed...)
+		if err := config.RecordInstall(configPath, src.cliVersion, roots); err != nil {
+			return err
+		}
+	}
+
+	choseAgents := initWithAll || initWithNone || initWithOpenCode || initWithClaude || initWithCodex
+	// Agents that were never offered to a person are not declined
+	if (nonInteractive || unattended) && !choseAgents {
+		return nil
+	}
+	return config.DeclineAgentDirs(configPath, subtract(agents.KnownAgentDirs(), selected))
+}
+
+// recordAgentCommands reads the maestro commands each installed agent
+// directory provides and stores their invocations in config.yaml.
+func recordAgentCommands(root string, dirs []string) (map[string][]agents.AgentCommand, error) {
+	commands := make(map[string][]agents.AgentCommand, len(dirs))
+	for _, dir := range dirs {
+		found, err := agents.InstalledCommands(root, dir)
+		if err != nil {
+			return nil, err
+		}
+		invocations := make([]string, 0, len(found))
+		for _, c := range found {
+			invocations = append(invocations, c.Invocation)
+		}
+		if err := config.RecordAgentCommands(projectConfig(root), dir, invocations); err != nil {
+			return nil, err
+		}
+		commands[dir] = found
+	}
+	return commands, nil
+}
+
+// printAgentCommands tells the user what to type in each installed agent.
+func printAgentCommands(w io.Writer, dirs []string, commands map[string][]agents.AgentCommand) {
+	for _, dir := range dirs {
+		if len(commands[dir]) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\nIn %s, run:\n", agents.AgentName(dir))
+		for _, c := range commands[dir] {
+			fmt.Fprintf(w, "  %s\n", c.Usage())
+		}
+		if c, ok := specifyCommand(commands[dir]); ok {
+			fmt.Fprintf(w, "Start with: %s\n", c.Usage())
+		}
+	}
+}
+
+// specifyCommand returns the command that starts a new feature.
+func specifyCommand(commands []agents.AgentCommand) (agents.AgentCommand, bool) {
+	for _, c := range commands {
+		if strings.HasSuffix(c.Invocation, "specify") {
+			return c, true
+		}
+	}
+	return agents.AgentCommand{}, false
+}
+
+// validateInitAgentFlags rejects contradictory agent selection flags before
+// anything is written.
+func validateInitAgentFlags() error {
+	if initWithAll && initWithNone {
+		return fmt.Errorf("--with-all and --with-none cannot be used together")
+	}
+	if initWithNone && (initWithOpenCode || initWithClaude || initWithCodex) {
+		return fmt.Errorf("--with-none cannot be combined with --with-opencode, --with-claude, or --with-codex")
+	}
+	return nil
+}
+
+// initAgentDirs resolves the agent directories to install from the init
+// flags, prompting only when none of them were given. root is the project.
+func initAgentDirs(root string, r io.Reader, w io.Writer) ([]string, error) {
+	if initWithNone {
+		return []string{}, nil
+	}
+	if initWithAll {
+		return agents.KnownAgentDirs(), nil
+	}
+	return selectInitAgentDirs(root, initWithOpenCode, initWithClaude, initWithCodex, r, w)
+}
+
+func selectInitAgentDirs(root string, withOpenCode, withClaude, withCodex bool, r io.Reader, w io.Writer) ([]string, error) {
 	selected := make([]string, 0, 3)
 	if withOpenCode {
 		selected = append(selected, ".opencode")
@@ -152,27 +690,24 @@ func selectInitAgentDirs(withOpenCode, withClaude, withCodex bool, r io.Reader,
 		return selected, nil
 	}
 
-	return agents.PromptAgentSelection(r, w, agents.KnownAgentDirs())
+	// Re-running init keeps the installed agent directories by default
+	return promptAgentSelection(r, w, agents.KnownAgentDirs(), agents.DetectInstalled(root))
 }
 
-func installRequiredStarterAssets(r io.Reader, w io.Writer) error {
+func installRequiredStarterAssets(root string, src *initSource, r io.Reader, w io.Writer) error {
 	required := agents.RequiredStarterAssetDirs()
-	conflicting := findExistingDirectories(required)
-	action := agents.ConflictOverwrite
+	conflicting := findExistingDirectories(root, required)
+	actions := map[string]agents.ConflictAction{}
 
 	if len(conflicting) > 0 {
-		if !isInteractiveStdin() {
-			return fmt.Errorf("detected existing starter assets in non-interactive mode (%s). rerun interactively to choose overwrite/backup/cancel", strings.Join(conflicting, ", "))
-		}
-
 		var err error
-		action, err = agents.PromptConflictResolution(r, w, conflicting)
+		actions, err = promptConflict(root, r, w, conflictClassAssets, conflicting)
 		if err != nil {
 			return fmt.Errorf("prompting for conflict resolution: %w", err)
 		}
 	}
 
-	result, err := agents.InstallRequiredAssets(required, action, embedded.NewAssetFetcher())
+	result, err := agents.InstallRequiredAssetsEach(root, required, actions, src.fetchDir)
 	if err != nil {
 		return err
 	}
@@ -181,83 +716,146 @@ func installRequiredStarterAssets(r io.Reader, w io.Writer) error {
 		fmt.Fprintf(w, "Installed required starter assets: %s\n", strings.Join(result.Installed, ", "))
 	}
 	for _, backup := range result.Backups {
-		fmt.Fprintf(w, "Backup created: %s\n", backup)
+		fmt.Fprintf(w, "Backup created: %s\n", pathfmt.Path(backup))
 	}
 
 	return nil
 }
 
-func installRequiredStarterFiles() error {
+// installRequiredStarterFiles writes the required root files that do not
+// exist yet. Files the source cannot provide are skipped with a warning and
+// returned, since they are not critical. root is the project.
+func installRequiredStarterFiles(root string, src *initSource) ([]string, error) {
 	requiredFiles := agents.RequiredStarterAssetFiles()
 	if len(requiredFiles) == 0 {
-		return nil
+		return nil, nil
 	}
 
+	var unavailable []string
+
 	for _, filePath := range requiredFiles {
 		// Check if file already exists
-		if _, err := os.Stat(filePath); err == nil {
+		path := filepath.Join(root, filePath)
+		if _, err := os.Stat(path); err == nil {
 			// File exists, skip
 			continue
 		}
 
-		// Fetch file from embedded resources
-		content, err := embedded.FetchFile(filePath)
+		content, err := src.fetchFile(filePath)
 		if err != nil {
 			// Log warning but don't fail - files might not be critical
 			fmt.Fprintf(os.Stderr, "Warning: could not fetch %s: %v\n", filePath, err)
+			unavailable = append(unavailable, filePath)
 			continue
 		}
 
 		// Ensure parent directory exists
-		dir := filepath.Dir(filePath)
+		dir := filepath.Dir(path)
 		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("creating directory for %s: %w", filePath, err)
+			return nil, fmt.Errorf("creating directory for %s: %w", filePath, err)
 		}
 
 		// Write file
-		if err := os.WriteFile(filePath, content, 0644); err != nil {
-			return fmt.Errorf("writing %s: %w", filePath, err)
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", filePath, err)
 		}
 
 		fmt.Printf("Installed: %s\n", filePath)
 	}
 
+	return unavailable, nil
+}
+
+// verifyEmbeddedStarterAssets checks that every required starter directory
+// and file is embedded in the binary, so an offline install either completes
+// or fails before writing anything.
+func verifyEmbeddedStarterAssets() error {
+	fetch := embedded.NewAssetFetcher()
+	missing := []string{}
+
+	for _, dir := range agents.RequiredStarterAssetDirs() {
+		if files, err := fetch(dir); err != nil || len(files) == 0 {
+			missing = append(missing, dir)
+		}
+	}
+	for _, filePath := range agents.RequiredStarterAssetFiles() {
+		if _, err := embedded.FetchFile(filePath); err != nil {
+			missing = append(missing, filePath)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("starter assets missing from this binary: %s (rebuild after running 'go generate ./...')", strings.Join(missing, ", "))
+	}
 	return nil
 }
 
-// installEmbeddedAgentDirs installs agent directories from embedded resources.
-func installEmbeddedAgentDirs(selected []string) error {
+// installAgentDirs installs agent directories from the init source into
+// the project at root.
+func installAgentDirs(root string, src *initSource, selected []string) error {
 	if len(selected) == 0 {
 		return nil
 	}
 
-	fetch := embedded.NewAssetFetcher()
-
+	fetch := agentDirFetcher(src)
 	for _, dir := range selected {
-		fmt.Printf("Installing %s from embedded resources...\n", dir)
+		fmt.Printf("Installing %s from %s...\n", dir, src.description)
 
 		content, err := fetch(dir)
 		if err != nil {
-			return fmt.Errorf("reading embedded %s: %w", dir, err)
-		}
-		if dir == ".codex" {
-			content = agents.AddCodexCommandSkills(content)
+			return fmt.Errorf("reading %s: %w", dir, err)
 		}
 
-		if err := agents.WriteAgentDir(content, dir); err != nil {
+		if err := agents.WriteAgentDir(root, content, dir); err != nil {
 			return fmt.Errorf("writing %s: %w", dir, err)
 		}
 
-		fmt.Printf("✓ Installed %s\n", dir)
+		fmt.Printf("%s Installed %s\n", glyph.OK(), dir)
 	}
 
 	return nil
 }
 
-func findExistingDirectories(dirs []string) []string {
+// agentDirFetcher returns the content of an agent directory as installed,
+// including the generated Codex command skills.
+func agentDirFetcher(src *initSource) agents.AssetFetcher {
+	return func(dir string) (map[string][]byte, error) {
+		content, err := src.fetchDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		if dir == ".codex" {
+			content = agents.AddCodexCommandSkills(content)
+		}
+		return content, nil
+	}
+}
+
+// mergeAssets merges dirs into the existing files, using the manifest to
+// tell files the user changed from files that are safe to update. root is
+// the project.
+func mergeAssets(root string, dirs []string, fetch agents.AssetFetcher, w io.Writer) (*agents.MergeResult, error) {
+	cfg, err := config.Load(projectConfig(root))
+	if err != nil {
+		return nil, fmt.Errorf("loading install manifest: %w", err)
+	}
+
+	result, err := agents.MergeAssets(root, dirs, fetch, cfg.Installed.Files)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(w, "Merged %s: %d added, %d updated, %d unchanged, %d kept\n",
+		strings.Join(dirs, ", "), len(result.Added), len(result.Updated), len(result.Unchanged), len(result.Kept))
+	for _, file := range result.Kept {
+		fmt.Fprintf(w, "  kept (modified or not recorded): %s\n", file)
+	}
+	return result, nil
+}
+
+func findExistingDirectories(root string, dirs []string) []string {
 	conflicting := make([]string, 0, len(dirs))
 	for _, dir := range dirs {
-		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		if info, err := os.Stat(filepath.Join(root, dir)); err == nil && info.IsDir() {
 			conflicting = append(conflicting, dir)
 		}
 	}
@@ -269,5 +867,47 @@ func isInteractiveStdin() bool {
 	if err != nil {
 		return false
 	}
+	// The null device is a character device too, and is what agent
+	// sessions and CI runners commonly attach
+	if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(info, null) {
+		return false
+	}
 	return (info.Mode() & os.ModeCharDevice) != 0
 }
+
+// verifyInit runs the doctor checks on the freshly installed project and
+// prints their report. Missing required structure means the install went
+// wrong, so it fails init; anything else doctor reports is a warning.
+func verifyInit(op *report.Operation, maestroDir string) error {
+	structure := projectStructureChecks(maestroDir)
+	results := append(append([]doctor.Result{}, structure...), systemDependencyChecks()...)
+	results = append(results, manifestChecks(filepath.Dir(maestroDir), filepath.Join(maestroDir, "config.yaml"))...)
+
+	fmt.Println("\nVerifying the installation...")
+	doctor.WriteText(os.Stdout, results)
+
+	var missing []string
+	for _, r := range structure {
+		if !r.OK {
+			missing = append(missing, r.Name)
+		}
+	}
+	if len(missing) > 0 {
+		op.FollowUp("Run 'maestro doctor' for details, then re-run 'maestro init'")
+		return op.Fail("verify", fmt.Errorf("installation is incomplete: missing %s", strings.Join(missing, ", ")))
+	}
+
+	problems := 0
+	for _, r := range results[len(structure):] {
+		if !r.OK {
+			problems++
+			op.Warning("%s: %s", r.Name, r.Message)
+		}
+	}
+	if problems > 0 {
+		op.Warn("verify", fmt.Sprintf("%d check(s) need attention", problems))
+		return nil
+	}
+	op.OK("verify", fmt.Sprintf("%d checks passed", len(results)))
+	return nil
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/spec-maestro/maestro-cli/pkg/agents"
+)
+
+// initAnswers is an answers file for maestro init --answers: every choice
+// init would otherwise prompt for, so onboarding can be scripted.
+type initAnswers struct {
+	// AgentDirs lists the agent directories to install, by directory
+	// (.claude) or agent (claude). An empty list installs none.
+	AgentDirs      *[]string `yaml:"agent_dirs"`
+	ConflictAction string    `yaml:"conflict_action"`
+	Adopt          string    `yaml:"adopt"`
+	Project        struct {
+		Name        string `yaml:"name"`
+		Description string `yaml:"description"`
+		BaseBranch  string `yaml:"base_branch"`
+	} `yaml:"project"`
+}
+
+// loadInitAnswers reads and validates an answers file. Unknown keys are
+// rejected so a typo cannot silently fall back to a default.
+func loadInitAnswers(path string) (*initAnswers, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading answers file: %w", err)
+	}
+
+	var answers initAnswers
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&answers); err != nil {
+		return nil, fmt.Errorf("parsing answers file %s: %w", path, err)
+	}
+
+	if answers.ConflictAction != "" {
+		if _, err := parseConflictAction(answers.ConflictAction); err != nil {
+			return nil, fmt.Errorf("answers file %s: conflict_action: %w", path, err)
+		}
+	}
+	if answers.AgentDirs != nil {
+		for _, name := range *answers.AgentDirs {
+			if answerAgentDir(name) == "" {
+				return nil, fmt.Errorf("answers file %s: unknown agent %q in agent_dirs (want %s)", path, name, strings.Join(agents.KnownAgentDirs(), ", "))
+			}
+		}
+	}
+	return &answers, nil
+}
+
+// answerAgentDir resolves an agent_dirs entry to a known agent directory.
+func answerAgentDir(name string) string {
+	name = strings.TrimSpace(name)
+	for _, dir := range agents.KnownAgentDirs() {
+		if name == dir || "."+name == dir {
+			return dir
+		}
+	}
+	return ""
+}
+
+// applyInitAnswers loads the --answers file and sets the init options it
+// answers, turning off every prompt. Flags given on the command line take
+// precedence over the file.
+func applyInitAnswers(cmd *cobra.Command, path string) error {
+	if path == "" {
+		return nil
+	}
+	answers, err := loadInitAnswers(path)
+	if err != nil {
+		return err
+	}
+
+	nonInteractive = true
+	if answers.ConflictAction != "" && !cmd.Flags().Changed("conflict-action") {
+		conflictActionDefault, conflictActionChosen = answers.ConflictAction, true
+	}
+	if answers.Adopt != "" && !cmd.Flags().Changed("adopt") {
+		initAdopt = answers.Adopt
+	}
+	if initProjectName == "" {
+		initProjectName = answers.Project.Name
+	}
+	if initDescription == "" {
+		initDescription = answers.Project.Description
+	}
+	if initBaseBranch == "" {
+		initBaseBranch = answers.Project.BaseBranch
+	}
+
+	agentFlagSet := initWithAll || initWithNone || initWithOpenCode || initWithClaude || initWithCodex
+	if answers.AgentDirs != nil && !agentFlagSet {
+		initWithNone = len(*answers.AgentDirs) == 0
+		for _, name := range *answers.AgentDirs {
+			switch answerAgentDir(name) {
+			case ".opencode":
+				initWithOpenCode = true
+			case ".claude":
+				initWithClaude = true
+			case ".codex":
+				initWithCodex = true
+			}
+		}
+	}
+	return nil
+}
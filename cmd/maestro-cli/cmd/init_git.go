@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+)
+
+// initGitBranch is the branch init --git commits the installed files to.
+const initGitBranch = "maestro/init"
+
+// initGitCommitMessage is the subject of the commit init --git makes.
+const initGitCommitMessage = "Initialize maestro"
+
+// checkInitGit verifies up front that the requested commit can be made, so
+// init fails before writing anything rather than after. root is the project.
+func checkInitGit(root string, newBranch bool) error {
+	if _, err := gitOutput(root, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return fmt.Errorf("--git and --commit need a git repository: %w", err)
+	}
+	if newBranch {
+		if _, err := gitOutput(root, "rev-parse", "--verify", "--quiet", "refs/heads/"+initGitBranch); err == nil {
+			return fmt.Errorf("branch %s already exists; delete it or use --commit to commit to the current branch", initGitBranch)
+		}
+	}
+	return nil
+}
+
+// commitInit stages paths and commits exactly them, optionally on a new
+// branch. Anything the user had staged before init stays staged and out of
+// the commit. paths are relative to root, the project.
+func commitInit(root string, paths []string, newBranch bool) error {
+	var existing []string
+	for _, path := range paths {
+		if _, err := os.Lstat(filepath.Join(root, path)); err == nil {
+			existing = append(existing, path)
+		}
+	}
+
+	if newBranch {
+		if _, err := gitOutput(root, "checkout", "-b", initGitBranch); err != nil {
+			return fmt.Errorf("creating branch %s: %w", initGitBranch, err)
+		}
+		fmt.Printf("%s Created branch %s\n", glyph.OK(), initGitBranch)
+	}
+
+	if _, err := gitOutput(root, append([]string{"add", "--"}, existing...)...); err != nil {
+		return fmt.Errorf("staging files: %w", err)
+	}
+	if _, err := gitOutput(root, append([]string{"commit", "-m", initGitCommitMessage, "--"}, existing...)...); err != nil {
+		return fmt.Errorf("committing: %w", err)
+	}
+	sha, err := gitOutput(root, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return fmt.Errorf("reading commit: %w", err)
+	}
+	fmt.Printf("%s Committed %s (%s): %s\n", glyph.OK(), strings.Join(existing, ", "), sha, initGitCommitMessage)
+	return nil
+}
+
+// gitOutput runs git in dir and returns its trimmed stdout. Failures
+// include git's stderr, which explains what went wrong far better than the
+// exit status.
+func gitOutput(dir string, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	c := exec.Command("git", args...)
+	c.Dir = dir
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
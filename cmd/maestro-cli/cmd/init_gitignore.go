@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spec-maestro/maestro-cli/pkg/agents"
+	"github.com/spec-maestro/maestro-cli/pkg/merge"
+)
+
+// gitignoreHeader introduces the entries init --gitignore adds.
+const gitignoreHeader = "# maestro: transient files and backups"
+
+// gitignoreEntries returns the transient paths maestro creates in a
+// project: state locks, what this machine last synced, the backups init,
+// update, and remove make, and the staging directories of an interrupted
+// overwrite or update. The asset
+// cache lives in ~/.cache/maestro, outside the project, and needs no entry.
+func gitignoreEntries() []string {
+	entries := []string{
+		".maestro/state/*.lock",
+		".maestro/cache/",
+		".maestro-backup-*/",
+		".maestro-backup-*.tar.gz*",
+		".maestro-overwrite-backup-*/",
+		merge.StagingPrefix + "*/",
+		updateStagingDir + "/",
+	}
+	for _, dir := range agents.KnownAgentDirs() {
+		entries = append(entries, dir+"-backup-*/")
+	}
+	return entries
+}
+
+// ensureGitignore appends the entries missing from the .gitignore at path,
+// creating it if needed, and returns the entries it added. Existing lines
+// and line endings are kept.
+func ensureGitignore(path string, entries []string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	present := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		present[strings.TrimSpace(line)] = true
+	}
+	var missing []string
+	for _, entry := range entries {
+		if !present[entry] {
+			missing = append(missing, entry)
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+
+	eol := "\n"
+	if bytes.Contains(data, []byte("\r\n")) {
+		eol = "\r\n"
+	}
+	var b strings.Builder
+	b.Write(data)
+	if len(data) > 0 {
+		if !bytes.HasSuffix(data, []byte("\n")) {
+			b.WriteString(eol)
+		}
+		b.WriteString(eol)
+	}
+	if !present[gitignoreHeader] {
+		b.WriteString(gitignoreHeader + eol)
+	}
+	for _, entry := range missing {
+		b.WriteString(entry + eol)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", path, err)
+	}
+	return missing, nil
+}
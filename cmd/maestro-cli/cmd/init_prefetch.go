@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/spec-maestro/maestro-cli/pkg/agents"
+)
+
+// maxConcurrentFetches bounds the requests prefetch has in flight, to stay
+// clear of GitHub's secondary rate limits.
+const maxConcurrentFetches = 4
+
+// prefetched holds what prefetch fetched, including failures, so a file the
+// source can't provide is reported where it would be installed without
+// asking again.
+type prefetched struct {
+	dirs  map[string]fetchedDir
+	files map[string]fetchedFile
+	// fetchDir and fetchFile are the source's own fetchers.
+	fetchDir  agents.AssetFetcher
+	fetchFile func(filePath string) ([]byte, error)
+}
+
+type fetchedDir struct {
+	content map[string][]byte
+	err     error
+}
+
+type fetchedFile struct {
+	content []byte
+	err     error
+}
+
+// prefetch fetches dirs and files from a remote source concurrently, since
+// each takes several round trips, and keeps the results for the installs
+// that follow. Everything is fetched even when something fails; the
+// directory failures are returned together. Files are optional, so their
+// failures surface when they are installed. The embedded source is read
+// directly and never prefetched.
+func (s *initSource) prefetch(dirs, files []string) error {
+	if s.ref == "" {
+		return nil
+	}
+	if s.cache == nil {
+		cache := &prefetched{
+			dirs:      make(map[string]fetchedDir),
+			files:     make(map[string]fetchedFile),
+			fetchDir:  s.fetchDir,
+			fetchFile: s.fetchFile,
+		}
+		s.cache = cache
+		s.fetchDir = func(dir string) (map[string][]byte, error) {
+			if f, ok := cache.dirs[dir]; ok {
+				return f.content, f.err
+			}
+			return cache.fetchDir(dir)
+		}
+		s.fetchFile = func(filePath string) ([]byte, error) {
+			if f, ok := cache.files[filePath]; ok {
+				return f.content, f.err
+			}
+			return cache.fetchFile(filePath)
+		}
+	}
+
+	dirs = uncached(dirs, s.cache.dirs)
+	files = uncached(files, s.cache.files)
+	dirResults := make([]fetchedDir, len(dirs))
+	fileResults := make([]fetchedFile, len(files))
+
+	var g errgroup.Group
+	g.SetLimit(maxConcurrentFetches)
+	for i, dir := range dirs {
+		g.Go(func() error {
+			content, err := s.cache.fetchDir(dir)
+			dirResults[i] = fetchedDir{content, err}
+			return nil
+		})
+	}
+	for i, filePath := range files {
+		g.Go(func() error {
+			content, err := s.cache.fetchFile(filePath)
+			fileResults[i] = fetchedFile{content, err}
+			return nil
+		})
+	}
+	g.Wait()
+
+	var errs []error
+	for i, dir := range dirs {
+		s.cache.dirs[dir] = dirResults[i]
+		if err := dirResults[i].err; err != nil {
+			errs = append(errs, fmt.Errorf("fetching %s: %w", dir, err))
+		}
+	}
+	for i, filePath := range files {
+		s.cache.files[filePath] = fileResults[i]
+	}
+	return errors.Join(errs...)
+}
+
+// prefetchStarterAssets prefetches the required starter assets together
+// with the agent directories chosen by flags, which are known before init
+// asks anything.
+func (s *initSource) prefetchStarterAssets() error {
+	dirs := agents.RequiredStarterAssetDirs()
+	switch {
+	case initWithNone:
+	case initWithAll:
+		dirs = append(dirs, agents.KnownAgentDirs()...)
+	default:
+		for dir, with := range map[string]bool{".opencode": initWithOpenCode, ".claude": initWithClaude, ".codex": initWithCodex} {
+			if with {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	return s.prefetch(dirs, agents.RequiredStarterAssetFiles())
+}
+
+// uncached returns the names not in cache, without duplicates.
+func uncached[T any](names []string, cache map[string]T) []string {
+	var missing []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if _, ok := cache[name]; !ok && !seen[name] {
+			seen[name] = true
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
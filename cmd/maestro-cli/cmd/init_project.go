@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spec-maestro/maestro-cli/pkg/config"
+	"github.com/spec-maestro/maestro-cli/pkg/deadline"
+	ghclient "github.com/spec-maestro/maestro-cli/pkg/github"
+)
+
+// resolveProjectSection fills in the project metadata init writes to
+// config.yaml. Each field comes from its flag (or the answers file), then
+// the existing config, then a prompt offering a detected default; in
+// non-interactive or unattended mode the default is used without asking.
+// Defaults are detected in root, the project root.
+func resolveProjectSection(root string, r io.Reader, w io.Writer, current config.ProjectSection) (config.ProjectSection, error) {
+	fields := []struct {
+		value    *string
+		flag     string
+		question string
+		detect   func() string
+	}{
+		{&current.Name, initProjectName, "Project name", func() string { return defaultProjectName(root) }},
+		{&current.Description, initDescription, "Short description", func() string { return "" }},
+		{&current.BaseBranch, initBaseBranch, "Base branch", func() string { return detectBaseBranch(root) }},
+	}
+
+	reader := bufio.NewReader(promptInput(r))
+	for _, field := range fields {
+		switch {
+		case field.flag != "":
+			*field.value = field.flag
+		case *field.value != "":
+		case nonInteractive || unattended:
+			*field.value = field.detect()
+		default:
+			answer, err := promptLine(reader, w, field.question, field.detect())
+			if err != nil {
+				return current, err
+			}
+			*field.value = answer
+		}
+	}
+	return current, nil
+}
+
+// promptLine asks question and returns the trimmed answer, or def when the
+// answer is empty or does not come within --prompt-timeout.
+func promptLine(reader *bufio.Reader, w io.Writer, question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(w, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(w, "%s (optional): ", question)
+	}
+	answer, err := reader.ReadString('\n')
+	if errors.Is(err, errPromptTimeout) {
+		fmt.Fprintf(w, "\nNo answer within %s; using %q.\n", promptTimeout, def)
+		return def, nil
+	}
+	if err != nil && (err != io.EOF || answer == "") {
+		return "", fmt.Errorf("reading input: %w", err)
+	}
+	if answer = strings.TrimSpace(answer); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// defaultProjectName is the name of the project directory, root.
+func defaultProjectName(root string) string {
+	dir, err := filepath.Abs(root)
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(dir)
+}
+
+// detectBaseBranch returns the remote's default branch when origin is
+// known, else the current branch, else main. The remote's default branch
+// comes from origin/HEAD, or, unless init runs with --offline, from asking
+// origin with git ls-remote, e.g. after 'git remote add'. The GitHub API is
+// only asked when init already fetches from GitHub, for --version, --ref,
+// or --from.
+func detectBaseBranch(root string) string {
+	if ref, err := gitOutput(root, "symbolic-ref", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		return strings.TrimPrefix(ref, "origin/")
+	}
+	if branch := remoteDefaultBranch(root); branch != "" {
+		return branch
+	}
+	if branch := githubDefaultBranch(root); branch != "" {
+		return branch
+	}
+	if branch, err := gitOutput(root, "symbolic-ref", "--short", "HEAD"); err == nil && branch != "" {
+		return branch
+	}
+	return "main"
+}
+
+// remoteLookupTimeout bounds each lookup of origin's default branch, so an
+// unreachable remote costs init a few seconds and no error.
+const remoteLookupTimeout = 5 * time.Second
+
+// remoteDefaultBranch asks origin for its HEAD with git ls-remote, without
+// prompting for credentials. It returns "" with --offline, without an
+// origin, or when origin doesn't answer in time.
+func remoteDefaultBranch(root string) string {
+	if initOffline {
+		return ""
+	}
+	if _, err := gitOutput(root, "remote", "get-url", "origin"); err != nil {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(deadline.Context(), remoteLookupTimeout)
+	defer cancel()
+	c := exec.CommandContext(ctx, "git", "ls-remote", "--symref", "origin", "HEAD")
+	c.Dir = root
+	c.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if os.Getenv("GIT_SSH_COMMAND") == "" {
+		c.Env = append(c.Env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+	}
+	out, err := c.Output()
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		// ref: refs/heads/main	HEAD
+		if ref, ok := strings.CutPrefix(line, "ref: refs/heads/"); ok {
+			branch, _, _ := strings.Cut(ref, "\t")
+			return branch
+		}
+	}
+	return ""
+}
+
+// githubDefaultBranch asks the GitHub API for the default branch of the
+// origin remote, when init fetches from GitHub anyway. It returns "" when
+// origin is not on GitHub or the request fails.
+func githubDefaultBranch(root string) string {
+	if initOffline || (initVersion == "" && initRef == "" && initFrom == "") {
+		return ""
+	}
+	url, err := gitOutput(root, "remote", "get-url", "origin")
+	if err != nil {
+		return ""
+	}
+	owner, repo, err := ghclient.ParseRepo(url)
+	if err != nil {
+		return ""
+	}
+	token, err := ghclient.ResolveRepoToken(os.Getenv("GITHUB_TOKEN"), owner, repo)
+	if err != nil {
+		return ""
+	}
+	branch, err := ghclient.NewClient(owner, repo, token).FetchDefaultBranch()
+	if err != nil {
+		return ""
+	}
+	return branch
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spec-maestro/maestro-cli/pkg/config"
+	ghclient "github.com/spec-maestro/maestro-cli/pkg/github"
+)
+
+// pinnedInitSource returns an init source that fetches assets from GitHub at
+// a release tag or an arbitrary ref. It lives outside init.go so the default
+// init path stays free of network code.
+//
+// from names a custom assets repository (see assetsRepo); without a tag or
+// ref its default branch is used.
+//
+// The ref is resolved to a commit up front, and every directory and file is
+// fetched at that commit, so one init never mixes content from two commits.
+func pinnedInitSource(from, releaseTag, ref string) (*initSource, error) {
+	owner, repo, err := assetsRepo(from, nil)
+	if err != nil {
+		return nil, err
+	}
+	token, err := ghclient.ResolveRepoToken(os.Getenv("GITHUB_TOKEN"), owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	client := ghclient.NewClient(owner, repo, token)
+
+	src := &initSource{cliVersion: releaseTag}
+	if from != "" {
+		src.repo = owner + "/" + repo
+	}
+	if releaseTag == "" && ref == "" {
+		ref = "HEAD"
+	}
+	if releaseTag != "" {
+		release, err := client.FetchReleaseByTag(releaseTag)
+		if err != nil {
+			return nil, fmt.Errorf("fetching release %s: %w", releaseTag, err)
+		}
+		ref = release.TagName
+		src.cliVersion = release.TagName
+
+		commit, err := client.FetchTagCommitSHA(ref)
+		if err != nil {
+			return nil, fmt.Errorf("resolving release %s: %w", ref, err)
+		}
+		src.commit = commit
+	} else {
+		commit, err := client.ResolveCommit(ref)
+		if err != nil {
+			return nil, fmt.Errorf("resolving ref %s: %w", ref, err)
+		}
+		src.commit = commit
+		src.cliVersion = ref
+	}
+
+	src.ref = ref
+	src.description = fmt.Sprintf("%s/%s@%s (%s)", owner, repo, ref, shortSHA(src.commit))
+	src.fetchDir = func(dir string) (map[string][]byte, error) {
+		return client.FetchAgentDir(dir, src.commit)
+	}
+	src.fetchFile = func(filePath string) ([]byte, error) {
+		return client.FetchFile(filePath, src.commit)
+	}
+	return src, nil
+}
+
+// assetsRepo returns the GitHub repository assets are fetched from: from (a
+// --from value) when set, else the source recorded in cfg, else the
+// upstream maestro repository.
+func assetsRepo(from string, cfg *config.ProjectConfig) (owner, repo string, err error) {
+	if from == "" && cfg != nil {
+		from = cfg.Source
+	}
+	if from == "" {
+		return githubOwner, githubRepo, nil
+	}
+	owner, repo, err = ghclient.ParseRepo(from)
+	if err != nil {
+		return "", "", fmt.Errorf("--from: %w", err)
+	}
+	return owner, repo, nil
+}
+
+// assetsClient returns a GitHub client for the project's assets repository
+// (see assetsRepo), reading the recorded source from the config.yaml of
+// the project at root.
+func assetsClient(root, from string) (*ghclient.Client, error) {
+	cfg, err := config.Load(projectConfig(root))
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	owner, repo, err := assetsRepo(from, cfg)
+	if err != nil {
+		return nil, err
+	}
+	token, err := ghclient.ResolveRepoToken(os.Getenv("GITHUB_TOKEN"), owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	return ghclient.NewClient(owner, repo, token), nil
+}
@@ -2,23 +2,35 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/internal/version"
 	"github.com/spec-maestro/maestro-cli/pkg/agents"
+	"github.com/spec-maestro/maestro-cli/pkg/agentsmd"
 	"github.com/spec-maestro/maestro-cli/pkg/config"
 	"github.com/spec-maestro/maestro-cli/pkg/embedded"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+	"github.com/spec-maestro/maestro-cli/pkg/report"
 )
 
 // ---------- agent dir selection (pre-existing tests) ----------
 
 func TestSelectInitAgentDirs_WithOpenCodeFlag(t *testing.T) {
-	selected, err := selectInitAgentDirs(true, false, false, strings.NewReader("\n"), &bytes.Buffer{})
+	selected, err := selectInitAgentDirs(".", true, false, false, strings.NewReader("\n"), &bytes.Buffer{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -29,7 +41,7 @@ func TestSelectInitAgentDirs_WithOpenCodeFlag(t *testing.T) {
 }
 
 func TestSelectInitAgentDirs_WithClaudeFlag(t *testing.T) {
-	selected, err := selectInitAgentDirs(false, true, false, strings.NewReader("\n"), &bytes.Buffer{})
+	selected, err := selectInitAgentDirs(".", false, true, false, strings.NewReader("\n"), &bytes.Buffer{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -40,7 +52,7 @@ func TestSelectInitAgentDirs_WithClaudeFlag(t *testing.T) {
 }
 
 func TestSelectInitAgentDirs_WithCodexFlag(t *testing.T) {
-	selected, err := selectInitAgentDirs(false, false, true, strings.NewReader("\n"), &bytes.Buffer{})
+	selected, err := selectInitAgentDirs(".", false, false, true, strings.NewReader("\n"), &bytes.Buffer{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -51,7 +63,7 @@ func TestSelectInitAgentDirs_WithCodexFlag(t *testing.T) {
 }
 
 func TestSelectInitAgentDirs_WithBothFlags(t *testing.T) {
-	selected, err := selectInitAgentDirs(true, true, false, strings.NewReader("\n"), &bytes.Buffer{})
+	selected, err := selectInitAgentDirs(".", true, true, false, strings.NewReader("\n"), &bytes.Buffer{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -62,7 +74,7 @@ func TestSelectInitAgentDirs_WithBothFlags(t *testing.T) {
 }
 
 func TestSelectInitAgentDirs_NoFlagsPromptsForSelection(t *testing.T) {
-	selected, err := selectInitAgentDirs(false, false, false, strings.NewReader("1 2\n"), &bytes.Buffer{})
+	selected, err := selectInitAgentDirs(".", false, false, false, strings.NewReader("1 2\n"), &bytes.Buffer{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -73,7 +85,7 @@ func TestSelectInitAgentDirs_NoFlagsPromptsForSelection(t *testing.T) {
 }
 
 func TestSelectInitAgentDirs_NoFlagsCanSelectCodex(t *testing.T) {
-	selected, err := selectInitAgentDirs(false, false, false, strings.NewReader("3\n"), &bytes.Buffer{})
+	selected, err := selectInitAgentDirs(".", false, false, false, strings.NewReader("3\n"), &bytes.Buffer{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -83,6 +95,54 @@ func TestSelectInitAgentDirs_NoFlagsCanSelectCodex(t *testing.T) {
 	}
 }
 
+func TestInitAgentDirs_WithAll(t *testing.T) {
+	initWithAll = true
+	defer func() { initWithAll = false }()
+
+	selected, err := initAgentDirs(".", strings.NewReader(""), &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(selected, " ") != strings.Join(agents.KnownAgentDirs(), " ") {
+		t.Fatalf("expected %v, got %v", agents.KnownAgentDirs(), selected)
+	}
+}
+
+func TestInitAgentDirs_WithNoneSkipsPrompt(t *testing.T) {
+	initWithNone = true
+	defer func() { initWithNone = false }()
+
+	// An empty reader would fail the prompt with EOF if it were reached.
+	selected, err := initAgentDirs(".", strings.NewReader(""), &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(selected) != 0 {
+		t.Fatalf("expected no agent dirs, got %v", selected)
+	}
+}
+
+func TestValidateInitAgentFlags(t *testing.T) {
+	defer func() {
+		initWithAll, initWithNone, initWithClaude = false, false, false
+	}()
+
+	initWithAll, initWithNone = true, true
+	if err := validateInitAgentFlags(); err == nil {
+		t.Error("expected error for --with-all with --with-none")
+	}
+
+	initWithAll, initWithClaude = false, true
+	if err := validateInitAgentFlags(); err == nil {
+		t.Error("expected error for --with-none with --with-claude")
+	}
+
+	initWithNone = false
+	if err := validateInitAgentFlags(); err != nil {
+		t.Errorf("unexpected error for --with-claude alone: %v", err)
+	}
+}
+
 // ---------- integration tests using embedded resources ----------
 
 // TestInitCreatesFullStructure exercises the init logic step-by-step in a
@@ -112,7 +172,7 @@ func TestInitCreatesFullStructure(t *testing.T) {
 		}
 		// Use the same writer init.go uses; map keys are paths relative to d
 		// (e.g. "maestro.init.md"), so we must join them onto d before writing.
-		if err := agents.WriteAgentDir(content, d); err != nil {
+		if err := agents.WriteAgentDir(".", content, d); err != nil {
 			t.Fatalf("WriteAgentDir(%q): %v", d, err)
 		}
 		totalFiles += len(content)
@@ -123,7 +183,7 @@ func TestInitCreatesFullStructure(t *testing.T) {
 	}
 
 	// Install required starter files (constitution.md, etc.)
-	if err := installRequiredStarterFiles(); err != nil {
+	if _, err := installRequiredStarterFiles(".", embeddedInitSource()); err != nil {
 		t.Fatalf("installRequiredStarterFiles: %v", err)
 	}
 
@@ -305,6 +365,15 @@ func TestInitEmbeddedContentDirsHaveFiles(t *testing.T) {
 	}
 }
 
+// TestInitVerifyEmbeddedStarterAssets verifies the offline pre-flight check
+// passes for a binary built with its embedded resources.
+func TestInitVerifyEmbeddedStarterAssets(t *testing.T) {
+	t.Parallel()
+	if err := verifyEmbeddedStarterAssets(); err != nil {
+		t.Fatalf("verifyEmbeddedStarterAssets returned error: %v", err)
+	}
+}
+
 // TestInitEmbeddedConstitutionAvailable verifies the constitution.md is
 // fetchable via the embedded FetchFile function.
 func TestInitEmbeddedConstitutionAvailable(t *testing.T) {
@@ -328,7 +397,7 @@ func TestInitRequiredStarterFiles(t *testing.T) {
 		t.Fatalf("creating .maestro: %v", err)
 	}
 
-	if err := installRequiredStarterFiles(); err != nil {
+	if _, err := installRequiredStarterFiles(".", embeddedInitSource()); err != nil {
 		t.Fatalf("installRequiredStarterFiles returned error: %v", err)
 	}
 
@@ -346,7 +415,7 @@ func TestInitRequiredStarterAssets(t *testing.T) {
 	defer os.Chdir(origDir)
 
 	var buf bytes.Buffer
-	err := installRequiredStarterAssets(strings.NewReader("\n"), &buf)
+	err := installRequiredStarterAssets(".", embeddedInitSource(), strings.NewReader("\n"), &buf)
 	if err != nil {
 		t.Fatalf("installRequiredStarterAssets returned error: %v", err)
 	}
@@ -357,16 +426,16 @@ func TestInitRequiredStarterAssets(t *testing.T) {
 	}
 }
 
-// TestInitInstallEmbeddedAgentDirs verifies installEmbeddedAgentDirs writes
+// TestInitInstallEmbeddedAgentDirs verifies installAgentDirs writes
 // agent directories from embedded resources to disk.
 func TestInitInstallEmbeddedAgentDirs(t *testing.T) {
 	dir := t.TempDir()
 	origDir := chdir(t, dir)
 	defer os.Chdir(origDir)
 
-	err := installEmbeddedAgentDirs([]string{".claude"})
+	err := installAgentDirs(".", embeddedInitSource(), []string{".claude"})
 	if err != nil {
-		t.Fatalf("installEmbeddedAgentDirs returned error: %v", err)
+		t.Fatalf("installAgentDirs returned error: %v", err)
 	}
 
 	assertDirExists(t, ".claude")
@@ -378,9 +447,9 @@ func TestInitInstallEmbeddedCodexGeneratesCommandSkills(t *testing.T) {
 	origDir := chdir(t, dir)
 	defer os.Chdir(origDir)
 
-	err := installEmbeddedAgentDirs([]string{".codex"})
+	err := installAgentDirs(".", embeddedInitSource(), []string{".codex"})
 	if err != nil {
-		t.Fatalf("installEmbeddedAgentDirs returned error: %v", err)
+		t.Fatalf("installAgentDirs returned error: %v", err)
 	}
 
 	assertFileExists(t, filepath.Join(".codex", "commands", "maestro.list.md"))
@@ -407,9 +476,9 @@ func TestInitInstallEmbeddedCodexGeneratesCommandSkills(t *testing.T) {
 // TestInitInstallEmbeddedAgentDirsEmpty verifies that passing an empty slice
 // does nothing and does not error.
 func TestInitInstallEmbeddedAgentDirsEmpty(t *testing.T) {
-	err := installEmbeddedAgentDirs(nil)
+	err := installAgentDirs(".", embeddedInitSource(), nil)
 	if err != nil {
-		t.Fatalf("installEmbeddedAgentDirs(nil) returned error: %v", err)
+		t.Fatalf("installAgentDirs(nil) returned error: %v", err)
 	}
 }
 
@@ -478,7 +547,7 @@ func TestInitSkipsExistingRequiredStarterFiles(t *testing.T) {
 		}
 	}
 
-	if err := installRequiredStarterFiles(); err != nil {
+	if _, err := installRequiredStarterFiles(".", embeddedInitSource()); err != nil {
 		t.Fatalf("installRequiredStarterFiles returned error: %v", err)
 	}
 
@@ -503,7 +572,7 @@ func TestInitFindExistingDirectories(t *testing.T) {
 
 	os.MkdirAll("existing-dir", 0755)
 
-	found := findExistingDirectories([]string{"existing-dir", "nonexistent-dir"})
+	found := findExistingDirectories(".", []string{"existing-dir", "nonexistent-dir"})
 	if len(found) != 1 || found[0] != "existing-dir" {
 		t.Errorf("expected [existing-dir], got %v", found)
 	}
@@ -512,6 +581,59 @@ func TestInitFindExistingDirectories(t *testing.T) {
 // ---------- helpers ----------
 
 // chdir changes to the given directory and returns the previous working dir.
+func TestInitSourcePrefetch(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	count := func(name string) {
+		mu.Lock()
+		calls[name]++
+		mu.Unlock()
+	}
+	src := &initSource{
+		ref: "main",
+		fetchDir: func(dir string) (map[string][]byte, error) {
+			count(dir)
+			if dir == ".broken" || dir == ".gone" {
+				return nil, errors.New("not found")
+			}
+			return map[string][]byte{"a.md": []byte(dir)}, nil
+		},
+		fetchFile: func(filePath string) ([]byte, error) {
+			count(filePath)
+			return nil, errors.New("not found")
+		},
+	}
+
+	err := src.prefetch([]string{".maestro", ".broken", ".claude", ".gone", ".maestro"}, []string{"constitution.md"})
+	if err == nil || !strings.Contains(err.Error(), "fetching .broken") || !strings.Contains(err.Error(), "fetching .gone") {
+		t.Fatalf("prefetch should report every failed directory, got %v", err)
+	}
+	if err := src.prefetch([]string{".claude"}, nil); err != nil {
+		t.Fatalf("prefetch of a fetched directory: %v", err)
+	}
+
+	content, err := src.fetchDir(".claude")
+	if err != nil || string(content["a.md"]) != ".claude" {
+		t.Errorf("fetchDir(.claude) = %v, %v", content, err)
+	}
+	if _, err := src.fetchFile("constitution.md"); err == nil {
+		t.Error("a failed file fetch should stay failed")
+	}
+	for name, n := range calls {
+		if n != 1 {
+			t.Errorf("%s fetched %d times, want once", name, n)
+		}
+	}
+	if len(calls) != 5 {
+		t.Errorf("fetched %v", calls)
+	}
+
+	local := embeddedInitSource()
+	if err := local.prefetch([]string{".maestro"}, nil); err != nil || local.cache != nil {
+		t.Errorf("the embedded source should not be prefetched: %v", err)
+	}
+}
+
 func chdir(t *testing.T, dir string) string {
 	t.Helper()
 	origDir, err := os.Getwd()
@@ -524,6 +646,19 @@ func chdir(t *testing.T, dir string) string {
 	return origDir
 }
 
+// resolveTestDirs resolves where cmd runs as the root command does before
+// running it, and forgets it when the test ends.
+func resolveTestDirs(t *testing.T, cmd *cobra.Command) {
+	t.Helper()
+	if err := resolveDirs(cmd); err != nil {
+		t.Fatalf("resolveDirs() error: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.SetContext(nil)
+		pathfmt.SetRoot(".")
+	})
+}
+
 func assertDirExists(t *testing.T, path string) {
 	t.Helper()
 	info, err := os.Stat(path)
@@ -579,3 +714,701 @@ func assertFileNonEmpty(t *testing.T, path string) {
 		t.Errorf("expected file %q to be non-empty", path)
 	}
 }
+
+// ---------- adopting pre-existing spec folders ----------
+
+func TestAdoptExistingSpecsWithFlag(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	os.Chdir(dir)
+
+	os.MkdirAll("specs", 0755)
+	os.WriteFile(filepath.Join("specs", "auth.md"), []byte("# Auth\n"), 0644)
+
+	initAdopt = "symlink"
+	defer func() { initAdopt = "" }()
+
+	var out bytes.Buffer
+	if err := adoptExistingSpecs(".", strings.NewReader(""), &out); err != nil {
+		t.Fatalf("adoptExistingSpecs error: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(".maestro", "specs", "001-auth", "spec.md")); err != nil {
+		t.Errorf("adopted spec missing: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(".maestro", "state", "001-auth.json")); err != nil {
+		t.Errorf("state entry missing: %v", err)
+	}
+	if !strings.Contains(out.String(), "Imported 1 spec(s)") {
+		t.Errorf("expected import report, got %q", out.String())
+	}
+}
+
+func TestAdoptExistingSpecsDefaultsToNo(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	os.Chdir(dir)
+
+	os.MkdirAll(filepath.Join("docs", "rfcs"), 0755)
+	os.WriteFile(filepath.Join("docs", "rfcs", "0001-intro.md"), []byte("# Intro\n"), 0644)
+
+	var out bytes.Buffer
+	if err := adoptExistingSpecs(".", strings.NewReader("\n"), &out); err != nil {
+		t.Fatalf("adoptExistingSpecs error: %v", err)
+	}
+	if _, err := os.Stat(".maestro"); !os.IsNotExist(err) {
+		t.Error("nothing should be adopted when the prompt is declined")
+	}
+	if !strings.Contains(out.String(), "docs/rfcs") {
+		t.Errorf("prompt should name the folder, got %q", out.String())
+	}
+}
+
+// TestInitRejectsConflictingSourceFlags verifies --version, --ref, and
+// --offline are validated before anything is fetched or written.
+func TestInitRejectsConflictingSourceFlags(t *testing.T) {
+	dir := t.TempDir()
+	origDir := chdir(t, dir)
+	defer os.Chdir(origDir)
+	defer func() { initVersion, initRef, initOffline, initFrom = "", "", false, "" }()
+
+	initVersion, initRef = "v1.0.0", "main"
+	if err := runInit(initCmd, nil); err == nil || !strings.Contains(err.Error(), "--version and --ref") {
+		t.Errorf("expected --version/--ref conflict, got %v", err)
+	}
+
+	initVersion, initOffline = "", true
+	if err := runInit(initCmd, nil); err == nil || !strings.Contains(err.Error(), "--offline") {
+		t.Errorf("expected --offline/--ref conflict, got %v", err)
+	}
+
+	initRef, initFrom = "", "acme/maestro-assets"
+	if err := runInit(initCmd, nil); err == nil || !strings.Contains(err.Error(), "--offline") {
+		t.Errorf("expected --offline/--from conflict, got %v", err)
+	}
+
+	initOffline, initBundle = false, "assets.tar.gz"
+	if err := runInit(initCmd, nil); err == nil || !strings.Contains(err.Error(), "--bundle") {
+		t.Errorf("expected --bundle/--from conflict, got %v", err)
+	}
+	initBundle = ""
+
+	if _, err := os.Stat(".maestro"); !os.IsNotExist(err) {
+		t.Error("nothing should be written when flags conflict")
+	}
+}
+
+// TestInitFromBundle tests that init installs from a bundle written by
+// bundle create, records it as the source, and refuses a damaged one.
+func TestInitFromBundle(t *testing.T) {
+	dir := t.TempDir()
+	origDir := chdir(t, dir)
+	defer os.Chdir(origDir)
+	nonInteractive, initWithClaude = true, true
+	defer func() { nonInteractive, initWithClaude, initBundle = false, false, "" }()
+
+	archive := filepath.Join(t.TempDir(), "assets.tar.gz")
+	if err := runBundleCreate(bundleCreateCmd, []string{archive}); err != nil {
+		t.Fatalf("bundle create error: %v", err)
+	}
+	if err := runBundleCreate(bundleCreateCmd, []string{archive}); err == nil {
+		t.Error("bundle create should not overwrite an existing archive")
+	}
+	if err := runBundleVerify(bundleVerifyCmd, []string{archive}); err != nil {
+		t.Errorf("a created bundle should verify, got %v", err)
+	}
+
+	initBundle = archive
+	if err := runInit(initCmd, nil); err != nil {
+		t.Fatalf("init --bundle error: %v", err)
+	}
+	want, _ := embedded.FetchFile(".claude/commands/maestro.plan.md")
+	if got, err := os.ReadFile(filepath.Join(".claude", "commands", "maestro.plan.md")); err != nil || string(got) != string(want) {
+		t.Errorf("agent files should come from the bundle, got %v", err)
+	}
+	cfg, err := config.Load(filepath.Join(".maestro", "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := cfg.Installed.LastUpdate; last == nil || last.Source != "bundle "+version.Version {
+		t.Errorf("last update should name the bundle, got %+v", last)
+	}
+
+	damaged := filepath.Join(t.TempDir(), "damaged.tar.gz")
+	os.WriteFile(damaged, []byte("not a bundle"), 0644)
+	if _, err := bundleInitSource(damaged); err == nil {
+		t.Error("a damaged bundle should be refused")
+	}
+}
+
+// TestAssetsRepo verifies --from takes precedence over the source recorded
+// in config.yaml, which takes precedence over the upstream repository.
+func TestAssetsRepo(t *testing.T) {
+	t.Parallel()
+	cfg := &config.ProjectConfig{Source: "acme/maestro-assets"}
+	for _, tc := range []struct {
+		from, owner, repo string
+		cfg               *config.ProjectConfig
+	}{
+		{"", githubOwner, githubRepo, nil},
+		{"", "acme", "maestro-assets", cfg},
+		{"https://github.com/other/assets.git", "other", "assets", cfg},
+	} {
+		owner, repo, err := assetsRepo(tc.from, tc.cfg)
+		if err != nil || owner != tc.owner || repo != tc.repo {
+			t.Errorf("assetsRepo(%q) = %s/%s, %v; want %s/%s", tc.from, owner, repo, err, tc.owner, tc.repo)
+		}
+	}
+	if _, _, err := assetsRepo("https://gitlab.com/acme/assets", nil); err == nil {
+		t.Error("a non-GitHub repository should be rejected")
+	}
+
+	src := &initSource{ref: "HEAD", commit: "abc", repo: "acme/maestro-assets"}
+	if got := src.lastUpdate("init", time.Time{}).Source; got != "acme/maestro-assets@HEAD" {
+		t.Errorf("last update source = %q", got)
+	}
+}
+
+// TestEmbeddedInitSourceRecordsNoCommit verifies agent directories installed
+// from embedded resources are not recorded as coming from an upstream commit.
+func TestEmbeddedInitSourceRecordsNoCommit(t *testing.T) {
+	t.Parallel()
+	src := embeddedInitSource()
+	if src.ref != "" || src.commit != "" {
+		t.Errorf("embedded source should have no ref/commit, got %q/%q", src.ref, src.commit)
+	}
+	if src.cliVersion != version.Version {
+		t.Errorf("cliVersion = %q, want %q", src.cliVersion, version.Version)
+	}
+}
+
+// ---------- committing the initialized files ----------
+
+// runGit runs git with args in dir, failing the test on error. It sets a
+// test identity, so commits by the test and by the code under test work.
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	c := exec.Command("git", args...)
+	c.Dir = dir
+	if out, err := c.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func TestInitGitCommitsToNewBranch(t *testing.T) {
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", "-b", "main")
+
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("unrelated"), 0644)
+	gitOutput(dir, "add", "notes.txt")
+
+	chdirFlag, nonInteractive, initWithClaude, initGit = dir, true, true, true
+	defer func() { chdirFlag, nonInteractive, initWithClaude, initGit = "", false, false, false }()
+	resolveTestDirs(t, initCmd)
+
+	if err := runInit(initCmd, nil); err != nil {
+		t.Fatalf("init --git error: %v", err)
+	}
+
+	if branch, _ := gitOutput(dir, "rev-parse", "--abbrev-ref", "HEAD"); branch != initGitBranch {
+		t.Errorf("branch = %q, want %s", branch, initGitBranch)
+	}
+	files, err := gitOutput(dir, "show", "--name-only", "--format=", "HEAD")
+	if err != nil {
+		t.Fatalf("git show: %v", err)
+	}
+	for _, want := range []string{"AGENTS.md", ".maestro/config.yaml", ".claude/"} {
+		if !strings.Contains(files, want) {
+			t.Errorf("commit should include %s, got:\n%s", want, files)
+		}
+	}
+	if strings.Contains(files, "notes.txt") {
+		t.Error("files staged before init should not be committed")
+	}
+	if staged, _ := gitOutput(dir, "diff", "--cached", "--name-only"); staged != "notes.txt" {
+		t.Errorf("notes.txt should still be staged, got %q", staged)
+	}
+}
+
+func TestInitGitignoreAppendsMissingEntries(t *testing.T) {
+	dir := t.TempDir()
+	origDir := chdir(t, dir)
+	defer os.Chdir(origDir)
+
+	os.WriteFile(".gitignore", []byte("node_modules/\r\n.maestro-backup-*/"), 0644)
+	nonInteractive, initWithNone, initGitignore = true, true, true
+	defer func() { nonInteractive, initWithNone, initGitignore = false, false, false }()
+
+	if err := runInit(initCmd, nil); err != nil {
+		t.Fatalf("init --gitignore error: %v", err)
+	}
+	data, _ := os.ReadFile(".gitignore")
+	content := string(data)
+	if !strings.HasPrefix(content, "node_modules/\r\n.maestro-backup-*/\r\n\r\n"+gitignoreHeader+"\r\n") {
+		t.Errorf("existing lines and CRLF endings should be kept:\n%q", content)
+	}
+	for _, want := range []string{".maestro/state/*.lock", ".claude-backup-*/", ".opencode-backup-*/"} {
+		if !strings.Contains(content, want+"\r\n") {
+			t.Errorf(".gitignore missing %s:\n%q", want, content)
+		}
+	}
+	if strings.Count(content, ".maestro-backup-*/") != 1 {
+		t.Errorf("present entries should not be repeated:\n%q", content)
+	}
+
+	added, err := ensureGitignore(".gitignore", gitignoreEntries())
+	if err != nil || len(added) != 0 {
+		t.Errorf("second run should add nothing, got %v, %v", added, err)
+	}
+}
+
+func TestInitAgentsMDMergeKeepsUserContent(t *testing.T) {
+	dir := t.TempDir()
+	origDir := chdir(t, dir)
+	defer os.Chdir(origDir)
+
+	os.WriteFile("AGENTS.md", []byte("# Team rules\n\nRun make lint before pushing.\n"), 0644)
+	nonInteractive, initWithNone, initAgentsMD = true, true, "merge"
+	defer func() { nonInteractive, initWithNone, initAgentsMD = false, false, "" }()
+
+	if err := runInit(initCmd, nil); err != nil {
+		t.Fatalf("init --agents-md-mode merge error: %v", err)
+	}
+	data, _ := os.ReadFile("AGENTS.md")
+	if !strings.HasPrefix(string(data), "# Team rules\n\nRun make lint before pushing.\n\n"+agentsmd.StartMarker+"\n") {
+		t.Errorf("user content should be kept above the maestro block:\n%s", data)
+	}
+
+	// update rewrites only the block
+	stale := strings.Replace(string(data), "maestro doctor", "maestro check", 1) + "\nLocal notes.\n"
+	os.WriteFile("AGENTS.md", []byte(stale), 0644)
+	op := report.New("update")
+	refreshAgentsMDBlock(".", op)
+	data, _ = os.ReadFile("AGENTS.md")
+	if !strings.Contains(string(data), "maestro doctor") || !strings.HasSuffix(string(data), "\nLocal notes.\n") {
+		t.Errorf("update should refresh the block and keep the rest:\n%s", data)
+	}
+	if len(op.Steps) != 1 || op.Steps[0].Status != report.StatusOK {
+		t.Errorf("refresh should be recorded, got %+v", op.Steps)
+	}
+}
+
+func TestInitWritesAgentInstructions(t *testing.T) {
+	dir := t.TempDir()
+	origDir := chdir(t, dir)
+	defer os.Chdir(origDir)
+
+	os.WriteFile("CLAUDE.md", []byte("# House style\n\nPrefer small PRs.\n"), 0644)
+	nonInteractive, initWithAll = true, true
+	defer func() { nonInteractive, initWithAll = false, false }()
+
+	if err := runInit(initCmd, nil); err != nil {
+		t.Fatalf("init --with-all error: %v", err)
+	}
+	claude, _ := os.ReadFile("CLAUDE.md")
+	for _, want := range []string{
+		"# House style\n\nPrefer small PRs.\n\n" + agentsmd.StartMarker + "\n",
+		"These instructions are for Claude Code.",
+		"- `/maestro.specify",
+	} {
+		if !strings.Contains(string(claude), want) {
+			t.Errorf("CLAUDE.md missing %q:\n%s", want, claude)
+		}
+	}
+	opencode, err := os.ReadFile(filepath.Join(".opencode", "AGENTS.md"))
+	if err != nil || !strings.Contains(string(opencode), "These instructions are for OpenCode.") {
+		t.Errorf(".opencode/AGENTS.md not written (%v):\n%s", err, opencode)
+	}
+
+	// update refreshes a stale block and leaves current files alone
+	os.WriteFile("CLAUDE.md", []byte(strings.Replace(string(claude), "maestro doctor", "maestro check", 1)), 0644)
+	op := report.New("update")
+	refreshAgentInstructions(".", op)
+	if data, _ := os.ReadFile("CLAUDE.md"); string(data) != string(claude) {
+		t.Errorf("update should restore the block:\n%s", data)
+	}
+	if len(op.Steps) != 1 || op.Steps[0].Detail != "CLAUDE.md" {
+		t.Errorf("only CLAUDE.md should be refreshed, got %+v", op.Steps)
+	}
+}
+
+func TestVerifyInitFailsOnMissingStructure(t *testing.T) {
+	dir := t.TempDir()
+	origDir := chdir(t, dir)
+	defer os.Chdir(origDir)
+
+	os.MkdirAll(filepath.Join(".maestro", "scripts"), 0755)
+	os.MkdirAll(filepath.Join(".maestro", "specs"), 0755)
+	os.WriteFile(filepath.Join(".maestro", "config.yaml"), []byte("cli_version: dev\n"), 0644)
+
+	op := report.New("init")
+	err := verifyInit(op, ".maestro")
+	if err == nil || !strings.Contains(err.Error(), "missing .maestro/state/") {
+		t.Fatalf("verifyInit should fail on a missing state/, got %v", err)
+	}
+	if last := op.Steps[len(op.Steps)-1]; last.Name != "verify" || last.Status != report.StatusFailed {
+		t.Errorf("failure should be recorded, got %+v", last)
+	}
+
+	os.MkdirAll(filepath.Join(".maestro", "state"), 0755)
+	op = report.New("init")
+	if err := verifyInit(op, ".maestro"); err != nil {
+		t.Errorf("complete structure should pass, got %v", err)
+	}
+}
+
+func TestInitCommitUsesCurrentBranch(t *testing.T) {
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", "-b", "main")
+
+	chdirFlag, nonInteractive, initCommit = dir, true, true
+	defer func() { chdirFlag, nonInteractive, initCommit = "", false, false }()
+	resolveTestDirs(t, initCmd)
+
+	if err := runInit(initCmd, nil); err != nil {
+		t.Fatalf("init --commit error: %v", err)
+	}
+	if branch, _ := gitOutput(dir, "rev-parse", "--abbrev-ref", "HEAD"); branch != "main" {
+		t.Errorf("branch = %q, want main", branch)
+	}
+	if subject, _ := gitOutput(dir, "log", "-1", "--format=%s"); subject != initGitCommitMessage {
+		t.Errorf("commit subject = %q", subject)
+	}
+}
+
+func TestInitGitRequiresRepository(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(dir))
+	origDir := chdir(t, dir)
+	defer os.Chdir(origDir)
+
+	initGit = true
+	defer func() { initGit = false }()
+
+	if err := runInit(initCmd, nil); err == nil || !strings.Contains(err.Error(), "git repository") {
+		t.Fatalf("expected missing repository error, got %v", err)
+	}
+	if _, err := os.Stat(".maestro"); !os.IsNotExist(err) {
+		t.Error("nothing should be written outside a git repository")
+	}
+}
+
+// ---------- agent command hints ----------
+
+func TestPrintAgentCommands(t *testing.T) {
+	t.Parallel()
+	commands := map[string][]agents.AgentCommand{
+		".claude": {
+			{Invocation: "/maestro.plan", ArgumentHint: "[feature-id]"},
+			{Invocation: "/maestro.specify", ArgumentHint: "<feature description>"},
+		},
+	}
+
+	var out bytes.Buffer
+	printAgentCommands(&out, []string{".claude", ".opencode"}, commands)
+
+	for _, want := range []string{"In Claude Code, run:", "  /maestro.plan [feature-id]\n", "Start with: /maestro.specify <feature description>"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "OpenCode") {
+		t.Error("agents without commands should not be listed")
+	}
+}
+
+func TestInitJSONOutputWritesOnlySummaryToStdout(t *testing.T) {
+	dir := t.TempDir()
+	origDir := chdir(t, dir)
+	defer os.Chdir(origDir)
+
+	nonInteractive, initWithClaude, initOutput = true, true, "json"
+	defer func() { nonInteractive, initWithClaude, initOutput = false, false, "text" }()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	runErr := runInit(initCmd, nil)
+	os.Stdout = stdout
+	w.Close()
+	out, _ := io.ReadAll(r)
+
+	if runErr != nil {
+		t.Fatalf("init error: %v", runErr)
+	}
+	var summary struct {
+		Operation string `json:"operation"`
+		Steps     []struct {
+			Name   string `json:"name"`
+			Status string `json:"status"`
+		} `json:"steps"`
+		FollowUps []string `json:"follow_ups"`
+		Counts    struct {
+			Failed int `json:"failed"`
+		} `json:"counts"`
+	}
+	if err := json.Unmarshal(out, &summary); err != nil {
+		t.Fatalf("stdout should hold only the JSON summary: %v\n%s", err, out)
+	}
+	if summary.Operation != "init" || summary.Counts.Failed != 0 || len(summary.FollowUps) == 0 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	steps := make(map[string]string)
+	for _, step := range summary.Steps {
+		steps[step.Name] = step.Status
+	}
+	for _, name := range []string{"starter assets", "config", "AGENTS.md", "agent configs", "install manifest"} {
+		if steps[name] != "ok" {
+			t.Errorf("step %q = %q, want ok (steps: %+v)", name, steps[name], summary.Steps)
+		}
+	}
+}
+
+func TestInitRejectsUnknownOutputFormat(t *testing.T) {
+	initOutput = "yaml"
+	defer func() { initOutput = "text" }()
+
+	if err := runInit(initCmd, nil); err == nil || !strings.Contains(err.Error(), "unknown output format") {
+		t.Errorf("expected unknown output format error, got %v", err)
+	}
+}
+
+func TestInitDryRunWritesNothing(t *testing.T) {
+	dir := t.TempDir()
+	origDir := chdir(t, dir)
+	defer os.Chdir(origDir)
+
+	nonInteractive, initWithClaude, initDryRun = true, true, true
+	defer func() { nonInteractive, initWithClaude, initDryRun = false, false, false }()
+
+	var buf bytes.Buffer
+	if err := runInitDryRun(initCmd, strings.NewReader(""), &buf); err != nil {
+		t.Fatalf("init --dry-run error: %v", err)
+	}
+	entries, _ := os.ReadDir(".")
+	if len(entries) != 0 {
+		t.Errorf("dry run should not write anything, found %d entries", len(entries))
+	}
+	for _, want := range []string{"Nothing has been written", "create     .maestro/config.yaml", "create     .claude/commands/maestro.specify.md", "0 conflict(s)"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("plan missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestInitDryRunFlagsConflicts(t *testing.T) {
+	dir := t.TempDir()
+	origDir := chdir(t, dir)
+	defer os.Chdir(origDir)
+
+	nonInteractive, initWithClaude = true, true
+	defer func() { nonInteractive, initWithClaude, initDryRun, initOutput = false, false, false, "text" }()
+	if err := runInit(initCmd, nil); err != nil {
+		t.Fatalf("init error: %v", err)
+	}
+	os.WriteFile(filepath.Join(".claude", "commands", "maestro.plan.md"), []byte("mine\n"), 0644)
+
+	initDryRun, initOutput = true, "json"
+	var buf bytes.Buffer
+	if err := runInitDryRun(initCmd, strings.NewReader(""), &buf); err != nil {
+		t.Fatalf("init --dry-run error: %v", err)
+	}
+	var plan struct {
+		Conflicts []string             `json:"conflicts"`
+		Files     []agents.PlannedFile `json:"files"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &plan); err != nil {
+		t.Fatalf("invalid JSON plan: %v\n%s", err, buf.String())
+	}
+	if len(plan.Conflicts) != 2 {
+		t.Errorf("expected .maestro/ and .claude conflicts, got %v", plan.Conflicts)
+	}
+	changes := make(map[string]agents.FileChange)
+	for _, f := range plan.Files {
+		changes[f.Path] = f.Change
+	}
+	if changes[".claude/commands/maestro.plan.md"] != agents.ChangeOverwrite || changes[".claude/commands/maestro.specify.md"] != agents.ChangeUnchanged {
+		t.Errorf("unexpected changes: %v", changes)
+	}
+	if data, _ := os.ReadFile(filepath.Join(".claude", "commands", "maestro.plan.md")); string(data) != "mine\n" {
+		t.Error("dry run should not overwrite files")
+	}
+}
+
+func TestInitAnswersFile(t *testing.T) {
+	dir := t.TempDir()
+	origDir := chdir(t, dir)
+	defer os.Chdir(origDir)
+
+	answers := "agent_dirs: [claude]\nconflict_action: overwrite\nproject:\n  name: billing\n  base_branch: trunk\n"
+	if err := os.WriteFile("init.yaml", []byte(answers), 0644); err != nil {
+		t.Fatal(err)
+	}
+	initAnswersPath = "init.yaml"
+	defer func() {
+		initAnswersPath, nonInteractive, conflictActionDefault = "", false, "backup"
+		initWithClaude, initWithNone = false, false
+		initProjectName, initDescription, initBaseBranch = "", "", ""
+	}()
+
+	// The second run finds .maestro/ and must answer the conflict from the file
+	for i := 0; i < 2; i++ {
+		if err := runInit(initCmd, nil); err != nil {
+			t.Fatalf("init --answers run %d error: %v", i+1, err)
+		}
+	}
+
+	cfg, err := config.Load(filepath.Join(".maestro", "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Project.Name != "billing" || cfg.Project.BaseBranch != "trunk" {
+		t.Errorf("project settings not applied: %+v", cfg.Project)
+	}
+	assertDirExists(t, ".claude")
+	if _, err := os.Stat(".codex"); !os.IsNotExist(err) {
+		t.Error(".codex should not be installed")
+	}
+	if !cfg.Installed.IsDeclined(".codex") {
+		t.Error("agent dirs left out of the answers file should be recorded as declined")
+	}
+	if backups, _ := filepath.Glob(".maestro-backup-*"); len(backups) != 0 {
+		t.Errorf("conflict_action overwrite should not back up, got %v", backups)
+	}
+}
+
+func TestLoadInitAnswersValidates(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"unknown key":     "agents: [claude]\n",
+		"unknown agent":   "agent_dirs: [cursor]\n",
+		"bad conflict":    "conflict_action: skip\n",
+		"not a yaml file": "agent_dirs: [\n",
+	} {
+		path := filepath.Join(dir, strings.ReplaceAll(name, " ", "-")+".yaml")
+		os.WriteFile(path, []byte(content), 0644)
+		if _, err := loadInitAnswers(path); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	path := filepath.Join(dir, "none.yaml")
+	os.WriteFile(path, []byte("agent_dirs: []\n"), 0644)
+	answers, err := loadInitAnswers(path)
+	if err != nil || answers.AgentDirs == nil || len(*answers.AgentDirs) != 0 {
+		t.Errorf("empty agent_dirs should be kept as an explicit empty list, got %+v, %v", answers, err)
+	}
+}
+
+func TestInitRecordsProjectSettings(t *testing.T) {
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", "-b", "main")
+	origDir := chdir(t, dir)
+	defer os.Chdir(origDir)
+
+	nonInteractive, initDescription = true, "Billing APIs"
+	defer func() { nonInteractive, initDescription = false, "" }()
+
+	if err := runInit(initCmd, nil); err != nil {
+		t.Fatalf("init error: %v", err)
+	}
+	cfg, err := config.Load(filepath.Join(".maestro", "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := config.ProjectSection{Name: filepath.Base(dir), Description: "Billing APIs", BaseBranch: "main"}
+	if cfg.Project != want {
+		t.Errorf("project = %+v, want %+v", cfg.Project, want)
+	}
+}
+
+func TestInitRecordsLastUpdate(t *testing.T) {
+	dir := t.TempDir()
+	origDir := chdir(t, dir)
+	defer os.Chdir(origDir)
+
+	nonInteractive, initWithClaude = true, true
+	defer func() { nonInteractive, initWithClaude = false, false }()
+
+	if err := runInit(initCmd, nil); err != nil {
+		t.Fatalf("init error: %v", err)
+	}
+	cfg, err := config.Load(filepath.Join(".maestro", "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := cfg.Installed.LastUpdate
+	if last == nil || last.Command != "init" || last.Source != "embedded "+version.Version || last.At.IsZero() {
+		t.Fatalf("unexpected last update: %+v", last)
+	}
+	if cfg.Installed.AgentDirs[".claude"].UpdatedAt.IsZero() {
+		t.Errorf("agent dir update time should be recorded: %+v", cfg.Installed.AgentDirs)
+	}
+}
+
+func TestDetectBaseBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", "-b", "develop")
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "initial")
+
+	// Without origin/HEAD, origin is asked, unless offline
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	runGit(t, dir, "init", "-q", "--bare", "-b", "release", remote)
+	runGit(t, dir, "push", "-q", remote, "HEAD:refs/heads/release")
+	runGit(t, dir, "remote", "add", "origin", remote)
+	if got := detectBaseBranch(dir); got != "release" {
+		t.Errorf("detectBaseBranch() = %q, want origin's default branch", got)
+	}
+	initOffline = true
+	defer func() { initOffline = false }()
+	if got := detectBaseBranch(dir); got != "develop" {
+		t.Errorf("detectBaseBranch() offline = %q, want the current branch", got)
+	}
+
+	runGit(t, dir, "update-ref", "refs/remotes/origin/trunk", "HEAD")
+	runGit(t, dir, "symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/trunk")
+	if got := detectBaseBranch(dir); got != "trunk" {
+		t.Errorf("detectBaseBranch() = %q, want origin's HEAD", got)
+	}
+}
+
+func TestResolveProjectSectionPrompts(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(dir))
+	origDir := chdir(t, dir)
+	defer os.Chdir(origDir)
+
+	initBaseBranch = "trunk"
+	defer func() { initBaseBranch = "" }()
+
+	var w bytes.Buffer
+	project, err := resolveProjectSection(".", strings.NewReader("billing\n\n"), &w, config.ProjectSection{})
+	if err != nil {
+		t.Fatalf("resolveProjectSection() error: %v", err)
+	}
+	want := config.ProjectSection{Name: "billing", BaseBranch: "trunk"}
+	if project != want {
+		t.Errorf("project = %+v, want %+v", project, want)
+	}
+	if !strings.Contains(w.String(), "Project name ["+filepath.Base(dir)+"]: ") || strings.Contains(w.String(), "Base branch") {
+		t.Errorf("should prompt for name with the directory as default and not for a flagged base branch, got %q", w.String())
+	}
+
+	project, err = resolveProjectSection(".", strings.NewReader(""), &w, config.ProjectSection{Name: "kept", Description: "kept", BaseBranch: "kept"})
+	if err != nil || project.Name != "kept" || project.BaseBranch != "trunk" {
+		t.Errorf("existing values should be kept and flags should win, got %+v, %v", project, err)
+	}
+}
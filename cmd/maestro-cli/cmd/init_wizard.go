@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spec-maestro/maestro-cli/pkg/agents"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/tui"
+)
+
+// wizardUI is the set of full-screen prompts the init wizard asks. The
+// terminal implementation is tuiWizard; tests answer with a stub.
+type wizardUI interface {
+	Checklist(title string, items, checked []string) ([]string, error)
+	Pick(title string, options []tui.Option) (int, error)
+	Confirm(title string, lines []string) (bool, error)
+}
+
+type tuiWizard struct{}
+
+func (tuiWizard) Checklist(title string, items, checked []string) ([]string, error) {
+	return tui.Checklist(title, items, checked)
+}
+
+func (tuiWizard) Pick(title string, options []tui.Option) (int, error) {
+	return tui.Pick(title, options)
+}
+
+func (tuiWizard) Confirm(title string, lines []string) (bool, error) {
+	return tui.Confirm(title, lines)
+}
+
+// initWizardUI is what init --interactive asks with.
+var initWizardUI wizardUI = tuiWizard{}
+
+// canRunWizard reports whether the full-screen wizard can take over the
+// terminal: both stdin and stdout must be terminals, and accessible output
+// must be off, as screen readers follow plain prompts far better.
+func canRunWizard() bool {
+	return isInteractiveStdin() && isTerminal(os.Stdout) && !glyph.Accessible()
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return (info.Mode() & os.ModeCharDevice) != 0
+}
+
+// runInitWizard asks for the agent directories, how to resolve existing
+// directories, and a final confirmation, then records the answers as init
+// flags and turns off the plain prompts. Agent flags and --conflict-action
+// given on the command line are not asked again. It returns false when the
+// user cancelled. root is the project init installs into.
+func runInitWizard(root string, ui wizardUI, source string, conflictActionSet bool) (bool, error) {
+	selected, err := wizardAgentDirs(root, ui)
+	if errors.Is(err, tui.ErrAborted) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	_, maestroErr := os.Stat(filepath.Join(root, ".maestro"))
+	maestroExists := maestroErr == nil
+	existing := findExistingDirectories(root, selected)
+	if maestroExists {
+		existing = append([]string{".maestro"}, existing...)
+	}
+
+	action, err := parseConflictAction(conflictActionDefault)
+	if err != nil {
+		return false, err
+	}
+	if len(existing) > 0 && !conflictActionSet {
+		if action, err = wizardConflictAction(ui, existing, maestroExists); err != nil {
+			return false, err
+		}
+		if action == agents.ConflictCancel {
+			return false, nil
+		}
+	}
+
+	dir, err := filepath.Abs(root)
+	if err != nil {
+		return false, err
+	}
+	lines := []string{
+		"Directory:    " + dir,
+		"Source:       " + source,
+		"Agent dirs:   " + listOrNone(selected),
+	}
+	if len(existing) > 0 {
+		lines = append(lines, fmt.Sprintf("Existing:     %s (%s)", strings.Join(existing, ", "), conflictActionName(action)))
+	}
+	ok, err := ui.Confirm("Install maestro with these settings?", lines)
+	if errors.Is(err, tui.ErrAborted) || (err == nil && !ok) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	setInitAgentFlags(selected)
+	conflictActionDefault = conflictActionName(action)
+	conflictActionChosen = len(existing) > 0
+	nonInteractive = true
+	return true, nil
+}
+
+// wizardAgentDirs asks which agent directories to install, unless the
+// agent flags already chose them. Installed directories start ticked.
+func wizardAgentDirs(root string, ui wizardUI) ([]string, error) {
+	if initWithAll || initWithNone || initWithOpenCode || initWithClaude || initWithCodex {
+		return initAgentDirs(root, nil, os.Stdout)
+	}
+	return ui.Checklist("Which agent config directories should maestro install?", agents.KnownAgentDirs(), agents.DetectInstalled(root))
+}
+
+// wizardConflictAction asks how to resolve the existing directories.
+// Merging is offered only when .maestro/ exists, as agent directories
+// are merged only alongside it.
+func wizardConflictAction(ui wizardUI, existing []string, canMerge bool) (agents.ConflictAction, error) {
+	actions := []agents.ConflictAction{agents.ConflictBackup, agents.ConflictOverwrite}
+	options := []tui.Option{
+		{Label: "Back up", Description: "Move the existing directories aside and install fresh copies"},
+		{Label: "Overwrite", Description: "Replace the existing files"},
+	}
+	if canMerge {
+		actions = append(actions, agents.ConflictMerge)
+		options = append(options, tui.Option{Label: "Merge", Description: "Add new files and keep the ones you changed"})
+	}
+	actions = append(actions, agents.ConflictCancel)
+	options = append(options, tui.Option{Label: "Cancel", Description: "Leave everything as it is"})
+
+	i, err := ui.Pick(strings.Join(existing, ", ")+" already exist. What should init do?", options)
+	if errors.Is(err, tui.ErrAborted) {
+		return agents.ConflictCancel, nil
+	}
+	if err != nil {
+		return agents.ConflictCancel, err
+	}
+	return actions[i], nil
+}
+
+// setInitAgentFlags records an agent selection as the --with-* flags.
+func setInitAgentFlags(selected []string) {
+	initWithAll = false
+	initWithNone = len(selected) == 0
+	initWithOpenCode, initWithClaude, initWithCodex = false, false, false
+	for _, dir := range selected {
+		switch dir {
+		case ".opencode":
+			initWithOpenCode = true
+		case ".claude":
+			initWithClaude = true
+		case ".codex":
+			initWithCodex = true
+		}
+	}
+}
+
+func listOrNone(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/agents"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/links"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check the project's workflow files",
+}
+
+var lintLinksCmd = &cobra.Command{
+	Use:   "links [file or directory...]",
+	Short: "Check relative links and file references in specs, plans, and prompts",
+	Long: `Checks the markdown in specs, plans, research, command prompts, skills,
+and the agent instruction files (default: all of them) for relative links
+whose target does not exist, and for references into the maestro assets,
+such as .maestro/scripts/create-feature.sh, that name a missing file.
+These commonly break when a file is renamed.
+
+Links are resolved from the directory of the file they are in, except
+links into .maestro/, which are resolved from the project root, the way
+agents read them. URLs, anchors, and paths with placeholders such as
+{feature_id} are not checked.`,
+	RunE: runLintLinks,
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+	lintCmd.AddCommand(lintLinksCmd)
+	addProjectPathFlag(lintLinksCmd, true)
+}
+
+// lintedDirs are the directories under .maestro/ whose markdown lint links
+// checks by default. Templates are left out: their links are meant for the
+// feature directory they are copied to.
+var lintedDirs = []string{"specs", "plans", "research", "commands", "skills", "reference"}
+
+func runLintLinks(cmd *cobra.Command, args []string) error {
+	root := workDir(cmd)
+	if _, err := os.Stat(filepath.Join(root, ".maestro")); os.IsNotExist(err) {
+		return fmt.Errorf("not initialized — run 'maestro init' first")
+	}
+
+	var files []string
+	var err error
+	if len(args) == 0 {
+		files, err = defaultLintedFiles(root)
+	} else {
+		files, err = lintedFiles(cmd, args)
+	}
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		fmt.Println("No markdown files to check.")
+		return nil
+	}
+
+	failed, total := 0, 0
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(file)))
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", file, err)
+		}
+		broken := links.Check(root, file, content)
+		if len(broken) == 0 {
+			continue
+		}
+		failed++
+		total += len(broken)
+		fmt.Printf("%s %s\n", glyph.Fail(), pathfmt.Path(file))
+		for _, b := range broken {
+			fmt.Printf("  %s\n", b)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d broken reference(s) in %d of %d file(s)", total, failed, len(files))
+	}
+	fmt.Printf("%s %d file(s) checked, no broken links\n", glyph.OK(), len(files))
+	return nil
+}
+
+// defaultLintedFiles returns the markdown files in lintedDirs, in the
+// installed agent directories, and the instruction files that exist, in
+// the project at root.
+func defaultLintedFiles(root string) ([]string, error) {
+	paths := make([]string, 0, len(lintedDirs))
+	for _, dir := range lintedDirs {
+		paths = append(paths, ".maestro/"+dir)
+	}
+	paths = append(paths, agents.DetectInstalled(root)...)
+	paths = append(paths, updateInstructionFiles(agents.KnownAgentDirs())...)
+
+	var files []string
+	for _, p := range paths {
+		found, err := markdownFiles(root, p)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, found...)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// lintedFiles returns the markdown files given on the command line, with
+// directories searched recursively, relative to the project root.
+func lintedFiles(cmd *cobra.Command, args []string) ([]string, error) {
+	root, err := filepath.Abs(workDir(cmd))
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, arg := range args {
+		abs, err := filepath.Abs(userPath(cmd, arg))
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(root, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("%s is outside the project", arg)
+		}
+		if _, err := os.Stat(abs); err != nil {
+			return nil, err
+		}
+		found, err := markdownFiles(root, filepath.ToSlash(rel))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, found...)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// markdownFiles returns p itself when it is a file, or the .md files under
+// it, slash-separated and, like p, relative to root. A missing p has none.
+func markdownFiles(root, p string) ([]string, error) {
+	dir := filepath.Join(root, filepath.FromSlash(p))
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{p}, nil
+	}
+	var files []string
+	err = filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.EqualFold(filepath.Ext(file), ".md") {
+			rel, err := filepath.Rel(dir, file)
+			if err != nil {
+				return err
+			}
+			files = append(files, path.Join(p, filepath.ToSlash(rel)))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing %s: %w", p, err)
+	}
+	return files, nil
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+// projectPath is the --path flag of commands that work on one maestro
+// project: the directory holding (or to hold) its .maestro/.
+var projectPath string
+
+// discoverProjectAnnotation marks commands that, without --path, look for
+// the nearest .maestro/ in the current directory or above it.
+const discoverProjectAnnotation = "maestro/discover-project"
+
+// addProjectPathFlag adds --path to cmd. Commands that operate on an
+// existing project set discover so they also work from a subdirectory.
+func addProjectPathFlag(cmd *cobra.Command, discover bool) {
+	cmd.Flags().StringVar(&projectPath, "path", "", "Directory of the maestro project, e.g. a monorepo package (default: the current directory)")
+	if discover {
+		if cmd.Annotations == nil {
+			cmd.Annotations = make(map[string]string)
+		}
+		cmd.Annotations[discoverProjectAnnotation] = "true"
+	}
+}
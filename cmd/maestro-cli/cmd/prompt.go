@@ -0,0 +1,399 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/spec-maestro/maestro-cli/pkg/agents"
+	"github.com/spec-maestro/maestro-cli/pkg/config"
+	"github.com/spec-maestro/maestro-cli/pkg/deadline"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+	"gopkg.in/yaml.v3"
+)
+
+// Global prompt settings. When nonInteractive is set no command reads stdin:
+// conflict prompts use conflictActionDefault, agent selection installs
+// nothing beyond the --with-* flags, and confirmations are accepted.
+//
+// unattended is set when stdin is not a terminal, as in agent-driven runs.
+// Prompts then take their own defaults without reading stdin: conflicts use
+// conflictActionDefault, agent selection keeps what is installed, and
+// confirmations are declined. promptTimeout makes a prompt on a terminal
+// fall back to the same defaults when nobody answers in time.
+var (
+	nonInteractive        bool
+	conflictActionDefault string
+	unattended            bool
+	promptTimeout         time.Duration
+)
+
+func init() {
+	rootCmd.PersistentFlags().BoolVarP(&nonInteractive, "yes", "y", false, "Never prompt; answer every question with its default (for CI)")
+	rootCmd.PersistentFlags().BoolVar(&nonInteractive, "non-interactive", false, "Alias for --yes")
+	rootCmd.PersistentFlags().StringVar(&conflictActionDefault, "conflict-action", "backup", "Action for existing files when not prompting: overwrite, backup, merge (init only), or cancel")
+	rootCmd.PersistentFlags().DurationVar(&promptTimeout, "prompt-timeout", 0, "Take a prompt's default when it is not answered within this time, e.g. 30s (default: wait)")
+}
+
+// errPromptTimeout is returned by reads from promptInput when nobody
+// answered within --prompt-timeout.
+var errPromptTimeout = errors.New("no answer before --prompt-timeout")
+
+// detectUnattended sets unattended when stdin is not a terminal and --yes
+// was not given.
+func detectUnattended() {
+	unattended = !nonInteractive && !isInteractiveStdin()
+}
+
+// promptInput applies --prompt-timeout and --timeout to reads from r when
+// r can stop a blocked read, as a pipe can. A terminal stdin is read
+// through a handle of its own that can; other readers, such as the fixed
+// input tests use, are returned as is.
+func promptInput(r io.Reader) io.Reader {
+	if promptTimeout <= 0 && deadline.Timeout() <= 0 {
+		return r
+	}
+	if r == io.Reader(os.Stdin) {
+		if !isInteractiveStdin() {
+			return r
+		}
+		r = terminalInput()
+	}
+	if d, ok := r.(deadlineReader); ok && d.SetReadDeadline(time.Time{}) == nil {
+		return timeoutReader{r: d, timeout: promptTimeout}
+	}
+	return r
+}
+
+// deadlineReader is input whose blocked reads can be stopped.
+type deadlineReader interface {
+	io.Reader
+	SetReadDeadline(t time.Time) error
+}
+
+// timeoutReader reads r a byte at a time, so a prompt takes no more than
+// its line and leaves the rest for the next one, failing with
+// errPromptTimeout when no input arrives within timeout, if set, and with
+// the deadline's error when the command runs out of time first. Nothing
+// reads r once Read returns.
+type timeoutReader struct {
+	r       deadlineReader
+	timeout time.Duration
+}
+
+func (t timeoutReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	var at time.Time
+	if t.timeout > 0 {
+		at = time.Now().Add(t.timeout)
+	}
+	end, bounded := deadline.Context().Deadline()
+	if bounded && (at.IsZero() || end.Before(at)) {
+		at = end
+	}
+	if err := t.r.SetReadDeadline(at); err != nil {
+		return 0, err
+	}
+	defer t.r.SetReadDeadline(time.Time{})
+
+	n, err := t.r.Read(p[:1])
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		if bounded && !time.Now().Before(end) {
+			return n, fmt.Errorf("%w after %s (--timeout)", deadline.ErrExceeded, deadline.Timeout())
+		}
+		return n, errPromptTimeout
+	}
+	return n, err
+}
+
+// parseConflictAction converts a --conflict-action value to a ConflictAction.
+func parseConflictAction(s string) (agents.ConflictAction, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "overwrite", "o":
+		return agents.ConflictOverwrite, nil
+	case "backup", "b", "":
+		return agents.ConflictBackup, nil
+	case "cancel", "c":
+		return agents.ConflictCancel, nil
+	case "merge", "m":
+		return agents.ConflictMerge, nil
+	default:
+		return agents.ConflictCancel, fmt.Errorf("invalid --conflict-action %q (want overwrite, backup, merge, or cancel)", s)
+	}
+}
+
+// conflictActionName returns the --conflict-action spelling of an action.
+func conflictActionName(action agents.ConflictAction) string {
+	switch action {
+	case agents.ConflictOverwrite:
+		return "overwrite"
+	case agents.ConflictBackup:
+		return "backup"
+	case agents.ConflictMerge:
+		return "merge"
+	default:
+		return "cancel"
+	}
+}
+
+// Classes of directories whose conflict action can be configured apart,
+// as conflict.<class> in the project or global config.
+const (
+	conflictClassMaestro = "maestro" // .maestro/ itself, when init runs again
+	conflictClassAssets  = "assets"  // the starter asset directories in .maestro/
+	conflictClassAgents  = "agents"  // agent directories such as .claude
+)
+
+// conflictActionChosen is set when the conflict action was picked for this
+// run, with --conflict-action, an answers file, or the init wizard, so that
+// it wins over the configured defaults.
+var conflictActionChosen bool
+
+// promptConflict asks how to handle existing directories, or applies the
+// configured default for each directory without reading r in
+// non-interactive or unattended mode, or when the prompt times out.
+// Directories with a conflict.dirs entry are not asked about. The action for
+// each conflicting directory is returned. Settings are read with the config
+// of the project at root.
+func promptConflict(root string, r io.Reader, w io.Writer, class string, conflicting []string) (map[string]agents.ConflictAction, error) {
+	if !nonInteractive && !unattended {
+		actions, err := askConflictActions(root, r, w, class, conflicting)
+		if err == nil {
+			return actions, checkNoMerge(actions)
+		}
+		if !errors.Is(err, errPromptTimeout) {
+			return nil, err
+		}
+		fmt.Fprintf(w, "\nNo answer within %s.\n", promptTimeout)
+	}
+
+	actions, err := defaultConflictActions(root, w, class, conflicting)
+	if err != nil {
+		return nil, err
+	}
+	return actions, checkNoMerge(actions)
+}
+
+// checkNoMerge rejects a merge configured for a directory that only init's
+// re-initialization can merge.
+func checkNoMerge(actions map[string]agents.ConflictAction) error {
+	for _, action := range actions {
+		if action == agents.ConflictMerge {
+			return fmt.Errorf("--conflict-action=merge only applies to maestro init")
+		}
+	}
+	return nil
+}
+
+// askConflictActions prompts for the conflicting directories that have no
+// conflict.dirs entry, unless --conflict-action was given, and takes the
+// entry's action for the others. Agent directories can be answered one by
+// one, and those answers remembered in the project config.
+func askConflictActions(root string, r io.Reader, w io.Writer, class string, conflicting []string) (map[string]agents.ConflictAction, error) {
+	actions := make(map[string]agents.ConflictAction, len(conflicting))
+	ask := conflicting
+	if !conflictActionChosen {
+		ask = nil
+		for _, dir := range conflicting {
+			action, key, ok, err := conflictDirAction(root, dir)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				ask = append(ask, dir)
+				continue
+			}
+			actions[dir] = action
+			fmt.Fprintf(w, "Existing %s: using %s=%s\n", dir, key, conflictActionName(action))
+		}
+	}
+	if len(ask) == 0 {
+		return actions, nil
+	}
+
+	configPath := projectConfig(root)
+	_, statErr := os.Stat(configPath)
+	offerRemember := class == conflictClassAgents && statErr == nil
+	asked, remember, err := agents.PromptConflictActions(promptInput(r), w, ask, offerRemember)
+	if err != nil {
+		return nil, err
+	}
+	for dir, action := range asked {
+		actions[dir] = action
+	}
+	if remember {
+		byDir := make(map[string]string, len(asked))
+		for dir, action := range asked {
+			byDir[dir] = conflictActionName(action)
+		}
+		if err := config.RememberConflictActions(configPath, byDir); err != nil {
+			fmt.Fprintf(w, "Warning: could not remember the choice: %v\n", err)
+		} else {
+			fmt.Fprintf(w, "Saved to conflict.dirs in %s; edit or remove the entries there to be asked again.\n", pathfmt.Rel(configPath))
+		}
+	}
+	return actions, nil
+}
+
+// promptReinit asks how to handle the existing .maestro directory when init
+// runs again, which can also merge into the existing files.
+func promptReinit(root string, r io.Reader, w io.Writer, dir string) (agents.ConflictAction, error) {
+	if !nonInteractive && !unattended {
+		action, err := agents.PromptReinitResolution(promptInput(r), w, []string{dir})
+		if !errors.Is(err, errPromptTimeout) {
+			return action, err
+		}
+		fmt.Fprintf(w, "\nNo answer within %s.\n", promptTimeout)
+	}
+	actions, err := defaultConflictActions(root, w, conflictClassMaestro, []string{dir})
+	if err != nil {
+		return agents.ConflictCancel, err
+	}
+	return actions[dir], nil
+}
+
+// defaultConflictActions picks the action for each conflicting directory
+// without asking and says which setting it came from.
+func defaultConflictActions(root string, w io.Writer, class string, conflicting []string) (map[string]agents.ConflictAction, error) {
+	actions := make(map[string]agents.ConflictAction, len(conflicting))
+	var order []string
+	groups := make(map[string][]string)
+	for _, dir := range conflicting {
+		action, source, err := conflictActionFor(root, class, dir)
+		if err != nil {
+			return nil, err
+		}
+		actions[dir] = action
+		setting := source + "=" + conflictActionName(action)
+		if _, ok := groups[setting]; !ok {
+			order = append(order, setting)
+		}
+		groups[setting] = append(groups[setting], dir)
+	}
+	for _, setting := range order {
+		fmt.Fprintf(w, "Existing %s: using %s\n", strings.Join(groups[setting], ", "), setting)
+	}
+	return actions, nil
+}
+
+// conflictActionFor resolves the action for an existing directory when not
+// prompting, and the setting it came from: --conflict-action when given,
+// then the directory's entry in conflict.dirs, then conflict.<class>, then
+// the --conflict-action default.
+func conflictActionFor(root, class, dir string) (agents.ConflictAction, string, error) {
+	if conflictActionChosen {
+		action, err := parseConflictAction(conflictActionDefault)
+		return action, "--conflict-action", err
+	}
+
+	if action, key, ok, err := conflictDirAction(root, dir); err != nil || ok {
+		return action, key, err
+	}
+
+	resolver := projectResolver(root)
+	key := "conflict." + class
+	if value, err := resolver.String(key, ""); err != nil {
+		return agents.ConflictCancel, "", err
+	} else if value != "" {
+		return configuredConflictAction(key, value)
+	}
+
+	action, err := parseConflictAction(conflictActionDefault)
+	return action, "--conflict-action", err
+}
+
+// conflictDirAction returns the action set for dir in conflict.dirs and the
+// key it was set under, if any.
+func conflictDirAction(root, dir string) (agents.ConflictAction, string, bool, error) {
+	dirs, err := projectResolver(root).String("conflict.dirs", "")
+	if err != nil || dirs == "" {
+		return agents.ConflictCancel, "", false, err
+	}
+	var byDir map[string]string
+	if err := yaml.Unmarshal([]byte(dirs), &byDir); err != nil {
+		return agents.ConflictCancel, "", false, fmt.Errorf("invalid conflict.dirs in config (want a mapping of directory to action): %w", err)
+	}
+	for d, value := range byDir {
+		if path.Clean(strings.TrimSuffix(d, "/")) == path.Clean(dir) {
+			action, key, err := configuredConflictAction("conflict.dirs."+d, value)
+			return action, key, err == nil, err
+		}
+	}
+	return agents.ConflictCancel, "", false, nil
+}
+
+// configuredConflictAction parses an action set in the config under key.
+func configuredConflictAction(key, value string) (agents.ConflictAction, string, error) {
+	action, err := parseConflictAction(value)
+	if err != nil {
+		return agents.ConflictCancel, "", fmt.Errorf("invalid %s %q in config (want overwrite, backup, merge, or cancel)", key, value)
+	}
+	return action, key, nil
+}
+
+// promptAgentSelection asks which agent directories to install, offering
+// preselected as the default. In non-interactive mode nothing is selected;
+// unattended or timed out, the default is taken.
+func promptAgentSelection(r io.Reader, w io.Writer, available, preselected []string) ([]string, error) {
+	if nonInteractive {
+		if len(available) > 0 {
+			fmt.Fprintln(w, "Skipping agent directory selection (non-interactive); use --with-* flags to install agent configs.")
+		}
+		return []string{}, nil
+	}
+	if !unattended {
+		selected, err := agents.PromptAgentSelectionWithDefaults(promptInput(r), w, available, preselected)
+		if !errors.Is(err, errPromptTimeout) {
+			return selected, err
+		}
+		fmt.Fprintf(w, "\nNo answer within %s.\n", promptTimeout)
+	} else if len(available) == 0 {
+		return []string{}, nil
+	}
+
+	keep := []string{}
+	for _, dir := range available {
+		for _, pre := range preselected {
+			if dir == pre {
+				keep = append(keep, dir)
+			}
+		}
+	}
+	if len(keep) > 0 {
+		fmt.Fprintf(w, "Keeping installed agent configs without asking: %s\n", strings.Join(keep, ", "))
+	} else {
+		fmt.Fprintln(w, "Not installing agent configs without an answer; use --with-* flags to install them.")
+	}
+	return keep, nil
+}
+
+// confirm asks a yes/no question, defaulting to no. In non-interactive mode
+// the answer is yes; unattended or timed out, it is the default.
+func confirm(r io.Reader, w io.Writer, question string) (bool, error) {
+	if nonInteractive {
+		return true, nil
+	}
+	if unattended {
+		fmt.Fprintf(w, "%s [y/N] n (stdin is not a terminal; pass --yes to accept)\n", question)
+		return false, nil
+	}
+
+	fmt.Fprintf(w, "%s [y/N] ", question)
+	response, err := bufio.NewReader(promptInput(r)).ReadString('\n')
+	if errors.Is(err, errPromptTimeout) {
+		fmt.Fprintf(w, "\nNo answer within %s; answering no.\n", promptTimeout)
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("reading input: %w", err)
+	}
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes", nil
+}
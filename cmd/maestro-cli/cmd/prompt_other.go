@@ -0,0 +1,14 @@
+//go:build !unix
+
+package cmd
+
+import (
+	"io"
+	"os"
+)
+
+// terminalInput returns stdin: a console's reads can't be given a deadline,
+// so prompts there wait for an answer.
+func terminalInput() io.Reader {
+	return os.Stdin
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package cmd
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// terminalInput returns the controlling terminal opened for reading, a
+// handle whose reads can be given a deadline, unlike stdin's, without
+// changing how stdin itself reads for maestro and the processes it starts.
+// It falls back to stdin when the terminal can't be opened.
+var terminalInput = sync.OnceValue(func() io.Reader {
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return os.Stdin
+	}
+	return tty
+})
@@ -1,70 +1,619 @@
 package cmd
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/agents"
+	"github.com/spec-maestro/maestro-cli/pkg/agentsmd"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/output"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+	"github.com/spec-maestro/maestro-cli/pkg/snapshot"
+	"github.com/spec-maestro/maestro-cli/pkg/tarball"
 )
 
 var removeCmd = &cobra.Command{
 	Use:   "remove",
 	Short: "Remove maestro from the current project",
-	Long:  "Removes the .maestro/ directory from the current project. Optionally creates a backup first.",
-	RunE:  runRemove,
+	Long: `Removes the .maestro/ directory from the current project. Optionally creates a backup first.
+
+--agents also removes the agent directories and maestro's block in CLAUDE.md;
+--all also removes AGENTS.md and the .gitignore entries init added.
+--keep-specs and --keep-state keep .maestro/specs/ and .maestro/state/,
+removing only the tooling around them. --backup-archive writes everything
+removed to a single .maestro-backup-<timestamp>.tar.gz with a .sha256
+checksum beside it, which 'maestro restore' brings back. Without
+--force, a checklist of everything maestro put in the project lets you pick
+what to remove, starting from what the flags select.`,
+	RunE: runRemove,
 }
 
 var removeForce bool
 var removeBackup bool
+var removeBackupArchive bool
+var removePlan bool
+var removeOutput string
+var removeAgents bool
+var removeAll bool
+var removeKeepSpecs bool
+var removeKeepState bool
 
 func init() {
 	rootCmd.AddCommand(removeCmd)
+	addProjectPathFlag(removeCmd, true)
 	removeCmd.Flags().BoolVarP(&removeForce, "force", "f", false, "Skip confirmation prompt")
 	removeCmd.Flags().BoolVar(&removeBackup, "backup", false, "Create a backup before removing")
+	removeCmd.Flags().BoolVar(&removeBackupArchive, "backup-archive", false, "Write what is removed to a .maestro-backup-<timestamp>.tar.gz, with a checksum, before removing")
+	removeCmd.Flags().BoolVar(&removePlan, "plan", false, "List what would be removed without removing anything")
+	removeCmd.Flags().BoolVar(&removeAgents, "agents", false, "Also remove the agent directories (.opencode/, .claude/, .codex/) and maestro's block in CLAUDE.md")
+	removeCmd.Flags().BoolVar(&removeAll, "all", false, "Also remove the agent directories, AGENTS.md, and maestro's .gitignore entries")
+	removeCmd.Flags().BoolVar(&removeKeepSpecs, "keep-specs", false, "Keep .maestro/specs/, removing the rest of .maestro/")
+	removeCmd.Flags().BoolVar(&removeKeepState, "keep-state", false, "Keep .maestro/state/, removing the rest of .maestro/")
+	removeCmd.Flags().StringVar(&removeOutput, "output", "text", "Plan output format: text, json, template=<go template>, or jsonpath=<expression>")
+	addFlagAlias(removeCmd, "format", "output")
+}
+
+// removalPlan describes everything remove would delete.
+type removalPlan struct {
+	Paths           []plannedRemoval `json:"paths"`
+	TotalFiles      int              `json:"total_files"`
+	TotalBytes      int64            `json:"total_bytes"`
+	Edits           []plannedEdit    `json:"edits"`
+	Kept            []string         `json:"kept"`
+	Backup          bool             `json:"backup"`
+	BackupArchive   bool             `json:"backup_archive"`
+	ExistingBackups []string         `json:"existing_backups"`
+}
+
+// plannedEdit is a file remove would take maestro's part out of, keeping
+// the rest.
+type plannedEdit struct {
+	Path   string `json:"path"`
+	Change string `json:"change"`
+}
+
+// plannedRemoval is one top-level path remove would delete.
+type plannedRemoval struct {
+	Path  string `json:"path"`
+	Files int    `json:"files"`
+	Dirs  int    `json:"dirs"`
+	Bytes int64  `json:"bytes"`
+}
+
+// buildRemovalPlan measures every path remove would delete, relative to
+// root, the project. Paths that do not exist are left out.
+func buildRemovalPlan(root string, targets []string, withBackup bool) (*removalPlan, error) {
+	plan := &removalPlan{
+		Paths:           []plannedRemoval{},
+		Edits:           []plannedEdit{},
+		Kept:            []string{},
+		Backup:          withBackup,
+		ExistingBackups: []string{},
+	}
+
+	for _, target := range targets {
+		if _, err := os.Lstat(filepath.Join(root, target)); os.IsNotExist(err) {
+			continue
+		}
+
+		entry, err := measureRemoval(root, target)
+		if err != nil {
+			return nil, err
+		}
+
+		plan.Paths = append(plan.Paths, entry)
+		plan.TotalFiles += entry.Files
+		plan.TotalBytes += entry.Bytes
+	}
+
+	backups, err := filepath.Glob(filepath.Join(root, snapshot.BackupPrefix+"*"))
+	if err != nil {
+		return nil, fmt.Errorf("listing backups: %w", err)
+	}
+	for _, b := range backups {
+		if !strings.HasSuffix(b, snapshot.ChecksumSuffix) {
+			plan.ExistingBackups = append(plan.ExistingBackups, projectRel(root, b))
+		}
+	}
+
+	return plan, nil
+}
+
+// measureRemoval counts the files, directories, and bytes under target,
+// relative to root.
+func measureRemoval(root, target string) (plannedRemoval, error) {
+	entry := plannedRemoval{Path: target}
+	err := filepath.Walk(filepath.Join(root, target), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			entry.Dirs++
+			return nil
+		}
+		entry.Files++
+		entry.Bytes += info.Size()
+		return nil
+	})
+	if err != nil {
+		return entry, fmt.Errorf("measuring %s: %w", target, err)
+	}
+	return entry, nil
+}
+
+// printRemovalPlan writes the plan in the requested format.
+func printRemovalPlan(plan *removalPlan, format string) error {
+	if err := validateOutputFormat(format); err != nil {
+		return err
+	}
+	if output.Data(format) {
+		return output.Write(os.Stdout, format, plan)
+	}
+
+	if len(plan.Paths) == 0 && len(plan.Edits) == 0 {
+		fmt.Println("Nothing to remove.")
+		return nil
+	}
+
+	if len(plan.Paths) > 0 {
+		fmt.Println("The following paths would be removed:")
+		for _, p := range plan.Paths {
+			fmt.Printf("  %-20s %d files, %d dirs, %s\n", p.Path, p.Files, p.Dirs, formatBytes(p.Bytes))
+		}
+		fmt.Printf("Total: %d files, %s\n", plan.TotalFiles, formatBytes(plan.TotalBytes))
+	}
+	if len(plan.Edits) > 0 {
+		fmt.Println("The following files would be edited:")
+		for _, e := range plan.Edits {
+			fmt.Printf("  %-20s %s\n", e.Path, e.Change)
+		}
+	}
+
+	if len(plan.Kept) > 0 {
+		fmt.Printf("Kept: %s\n", strings.Join(plan.Kept, ", "))
+	}
+	switch {
+	case plan.BackupArchive:
+		fmt.Println("A backup archive would be created first (--backup-archive).")
+	case plan.Backup:
+		fmt.Println("A backup would be created first (--backup).")
+	default:
+		fmt.Println("No backup would be created (use --backup or --backup-archive to keep a copy).")
+	}
+	if len(plan.ExistingBackups) > 0 {
+		fmt.Printf("Existing backups: %s\n", strings.Join(plan.ExistingBackups, ", "))
+	}
+	return nil
+}
+
+// formatBytes renders a byte count for humans.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
 }
 
 func runRemove(cmd *cobra.Command, args []string) error {
+	// Paths are relative to root, as the plan and messages show them
+	root := workDir(cmd)
 	maestroDir := ".maestro"
+	if removeBackup && removeBackupArchive {
+		return fmt.Errorf("--backup and --backup-archive cannot be used together")
+	}
+
+	var keep []string
+	if removeKeepSpecs {
+		keep = append(keep, "specs")
+	}
+	if removeKeepState {
+		keep = append(keep, "state")
+	}
+	items, err := removeItems(root, maestroDir, keep)
+	if err != nil {
+		return err
+	}
+	selected := selectRemoveItems(items, removeAgents || removeAll, removeAll)
+
+	if removePlan {
+		targets, err := removalTargets(root, selected, maestroDir, keep)
+		if err != nil {
+			return err
+		}
+		plan, err := buildRemovalPlan(root, targets, removeBackup)
+		if err != nil {
+			return err
+		}
+		plan.BackupArchive = removeBackupArchive
+		plan.Edits = append(plan.Edits, plannedEdits(root, selected)...)
+		for _, name := range keep {
+			if _, err := os.Stat(filepath.Join(root, maestroDir, name)); err == nil {
+				plan.Kept = append(plan.Kept, filepath.Join(maestroDir, name))
+			}
+		}
+		return printRemovalPlan(plan, removeOutput)
+	}
 
-	if _, err := os.Stat(maestroDir); os.IsNotExist(err) {
+	if len(items) == 0 {
 		fmt.Println("No .maestro/ directory found — nothing to remove.")
 		return nil
 	}
 
+	if !removeForce && len(items) > 1 && !nonInteractive && !unattended {
+		selected, err = promptRemoveItems(os.Stdin, os.Stdout, items, selected)
+		if err != nil {
+			return err
+		}
+		if len(selected) == 0 {
+			fmt.Fprintln(os.Stderr, "Nothing selected. Aborted.")
+			return nil
+		}
+	}
+	if len(selected) == 0 {
+		fmt.Printf("No .maestro/ directory found — nothing to remove. Use --agents or --all to remove %s.\n", joinItemNames(items))
+		return nil
+	}
+
 	if !removeForce {
-		fmt.Print("Are you sure you want to remove .maestro/ from this project? [y/N] ")
-		reader := bufio.NewReader(os.Stdin)
-		response, err := reader.ReadString('\n')
+		question := "Are you sure you want to remove .maestro/ from this project?"
+		if len(selected) > 1 || selected[0].Path != maestroDir {
+			question = fmt.Sprintf("Are you sure you want to remove %s from this project?", joinItemNames(selected))
+		}
+		ok, err := confirm(os.Stdin, os.Stdout, question)
 		if err != nil {
-			return fmt.Errorf("reading input: %w", err)
+			return err
 		}
-		response = strings.TrimSpace(strings.ToLower(response))
-		if response != "y" && response != "yes" {
+		if !ok {
 			fmt.Fprintln(os.Stderr, "Aborted.")
 			return nil
 		}
 	}
 
 	if removeBackup {
-		backupDir := fmt.Sprintf(".maestro-backup-%s", time.Now().Format("20060102-150405"))
-		if err := copyDir(maestroDir, backupDir); err != nil {
-			return fmt.Errorf("creating backup: %w", err)
+		for _, item := range selected {
+			if item.Strip != nil {
+				continue
+			}
+			if item.Path == maestroDir {
+				backupDir := fmt.Sprintf("%s%s", snapshot.BackupPrefix, time.Now().Format(tarball.TimeFormat))
+				if err := copyDir(filepath.Join(root, maestroDir), filepath.Join(root, backupDir)); err != nil {
+					return fmt.Errorf("creating backup: %w", err)
+				}
+				fmt.Printf("Backup created at %s\n", pathfmt.Path(backupDir))
+				continue
+			}
+			backupDir, err := agents.BackupDir(filepath.Join(root, item.Path))
+			if err != nil {
+				return fmt.Errorf("creating backup of %s: %w", item.Name, err)
+			}
+			fmt.Printf("Backup of %s created at %s\n", item.Name, pathfmt.Path(backupDir))
+		}
+	}
+
+	if removeBackupArchive {
+		targets, err := removalTargets(root, selected, maestroDir, keep)
+		if err != nil {
+			return err
 		}
-		fmt.Printf("Backup created at %s\n", backupDir)
+		var paths []string
+		for _, target := range targets {
+			if _, err := os.Lstat(filepath.Join(root, target)); err == nil {
+				paths = append(paths, filepath.ToSlash(target))
+			}
+		}
+		archive, err := snapshot.CreateBackup(root, paths, time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Backup archive created at %s (checksum in %s)\n", pathfmt.Path(archive), pathfmt.Path(archive+snapshot.ChecksumSuffix))
+		fmt.Printf("Run 'maestro restore %s' to bring it back.\n", pathfmt.Rel(archive))
 	}
 
-	if err := os.RemoveAll(maestroDir); err != nil {
-		return fmt.Errorf("removing .maestro/: %w", err)
+	for _, item := range selected {
+		if err := item.remove(root); err != nil {
+			return err
+		}
+		switch {
+		case item.Path == maestroDir:
+			fmt.Printf("%s %s removed successfully.\n", glyph.OK(), item.Name)
+		case item.Strip != nil:
+			fmt.Printf("%s Removed %s\n", glyph.OK(), item.Name)
+		default:
+			fmt.Printf("%s %s removed.\n", glyph.OK(), item.Name)
+		}
 	}
+	return nil
+}
+
+// removeItem is something maestro put in the project that remove can take
+// out: a directory or file, or maestro's part of a file shared with the
+// user.
+type removeItem struct {
+	// Name is how the checklist and messages show the item.
+	Name string
+	// Path is relative to the project root.
+	Path string
+	// Group is the flag that selects the item: "" for .maestro/, which is
+	// always selected, agents, or all.
+	Group string
+	// Strip returns the file's content without maestro's part, and what
+	// that removes; when nil, the path is removed.
+	Strip func(content string) (string, string, error)
+	// Keep lists the entries of a directory item that stay.
+	Keep []string
+}
 
-	fmt.Println("✓ .maestro/ removed successfully.")
+// remove deletes the item from the project at root. A file left empty once
+// maestro's part is gone is deleted too.
+func (item removeItem) remove(root string) error {
+	if len(item.Keep) > 0 {
+		paths, err := removableEntries(root, item.Path, item.Keep)
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			if err := os.RemoveAll(filepath.Join(root, path)); err != nil {
+				return fmt.Errorf("removing %s: %w", path, err)
+			}
+		}
+		return nil
+	}
+	path := filepath.Join(root, item.Path)
+	if item.Strip == nil {
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("removing %s: %w", item.Name, err)
+		}
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", item.Path, err)
+	}
+	rest, _, err := item.Strip(string(data))
+	if err != nil {
+		return fmt.Errorf("%s: %w", item.Path, err)
+	}
+	if strings.TrimSpace(rest) == "" {
+		err = os.Remove(path)
+	} else {
+		err = os.WriteFile(path, []byte(rest), 0644)
+	}
+	if err != nil {
+		return fmt.Errorf("removing %s: %w", item.Name, err)
+	}
 	return nil
 }
 
+// removeItems lists what maestro put in the project that still exists:
+// .maestro/, the agent directories and maestro's block in the instruction
+// files outside them, AGENTS.md, and the .gitignore entries init adds.
+// maestroDir is relative to root, the project.
+func removeItems(root, maestroDir string, keep []string) ([]removeItem, error) {
+	var items []removeItem
+	if _, err := os.Stat(filepath.Join(root, maestroDir)); err == nil {
+		item := removeItem{Name: ".maestro/", Path: maestroDir, Keep: keep}
+		if len(keep) > 0 {
+			kept := make([]string, len(keep))
+			for i, name := range keep {
+				kept[i] = name + "/"
+			}
+			item.Name = ".maestro/ except " + strings.Join(kept, " and ")
+		}
+		items = append(items, item)
+	}
+	for _, dir := range agents.DetectInstalled(root) {
+		items = append(items, removeItem{Name: dir + "/", Path: dir, Group: "agents"})
+	}
+	for _, dir := range agents.KnownAgentDirs() {
+		file := agents.InstructionFile(dir)
+		if file == "" || strings.HasPrefix(file, dir+"/") {
+			continue
+		}
+		if data, err := os.ReadFile(filepath.Join(root, file)); err == nil && strings.Contains(string(data), agentsmd.StartMarker) {
+			items = append(items, removeItem{Name: "the maestro block in " + file, Path: file, Group: "agents", Strip: stripAgentsMDBlock})
+		}
+	}
+
+	agentsMD, err := readAgentsMD(root)
+	if err != nil {
+		return nil, err
+	}
+	// Without markers AGENTS.md is maestro's only when it is exactly what
+	// init wrote; text appended to the user's own file is stripped, and a
+	// file maestro did not write, e.g. kept by --agents-md-mode skip, stays
+	switch {
+	case agentsMD == nil:
+	case strings.Contains(string(agentsMD), agentsmd.StartMarker):
+		items = append(items, removeItem{Name: "the maestro block in AGENTS.md", Path: "AGENTS.md", Group: "all", Strip: stripAgentsMDBlock})
+	case strings.TrimSpace(string(agentsMD)) == strings.TrimSpace(agentsMDContent):
+		items = append(items, removeItem{Name: "AGENTS.md", Path: "AGENTS.md", Group: "all"})
+	case strings.Contains(string(agentsMD), strings.TrimSpace(agentsMDContent)):
+		items = append(items, removeItem{Name: "maestro's instructions appended to AGENTS.md", Path: "AGENTS.md", Group: "all", Strip: stripAppendedAgentsMD})
+	}
+
+	if data, err := os.ReadFile(filepath.Join(root, ".gitignore")); err == nil {
+		if _, removed, _ := stripGitignoreEntries(string(data)); removed != "" {
+			items = append(items, removeItem{Name: "maestro's .gitignore entries", Path: ".gitignore", Group: "all", Strip: stripGitignoreEntries})
+		}
+	}
+	return items, nil
+}
+
+// selectRemoveItems returns .maestro/ and the items the flags select.
+func selectRemoveItems(items []removeItem, withAgents, withAll bool) []removeItem {
+	var selected []removeItem
+	for _, item := range items {
+		if item.Group == "" || (item.Group == "agents" && withAgents) || (item.Group == "all" && withAll) {
+			selected = append(selected, item)
+		}
+	}
+	return selected
+}
+
+// promptRemoveItems shows every item in a checklist, the selected ones
+// ticked, and returns the items picked.
+func promptRemoveItems(r io.Reader, w io.Writer, items, selected []removeItem) ([]removeItem, error) {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.Name
+	}
+	var preselected []string
+	for _, item := range selected {
+		preselected = append(preselected, item.Name)
+	}
+	picked, err := agents.PromptChecklist(promptInput(r), w, agents.Checklist{
+		Title:       "maestro added these to the project:",
+		Items:       names,
+		Preselected: preselected,
+		Marker:      "selected",
+		Verb:        "remove",
+		DefaultVerb: "remove",
+	})
+	if errors.Is(err, errPromptTimeout) {
+		fmt.Fprintf(w, "\nNo answer within %s; removing nothing.\n", promptTimeout)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var result []removeItem
+	for _, item := range items {
+		for _, name := range picked {
+			if item.Name == name {
+				result = append(result, item)
+			}
+		}
+	}
+	return result, nil
+}
+
+// removalTargets returns the paths of the items that are removed whole,
+// and with keep, the entries of .maestro/ that are not kept. The plan
+// reports .maestro/ even when it does not exist. Paths are relative to
+// root, the project.
+func removalTargets(root string, items []removeItem, maestroDir string, keep []string) ([]string, error) {
+	targets := []string{maestroDir}
+	if len(keep) > 0 {
+		entries, err := removableEntries(root, maestroDir, keep)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		targets = entries
+	}
+	for _, item := range items {
+		if item.Strip == nil && item.Path != maestroDir {
+			targets = append(targets, item.Path)
+		}
+	}
+	return targets, nil
+}
+
+// removableEntries lists the entries of dir whose names are not in keep.
+// dir and the entries are relative to root.
+func removableEntries(root, dir string, keep []string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(root, dir))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", dir, err)
+	}
+	kept := map[string]bool{}
+	for _, name := range keep {
+		kept[name] = true
+	}
+	var paths []string
+	for _, entry := range entries {
+		if !kept[entry.Name()] {
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return paths, nil
+}
+
+// plannedEdits describes the files remove would take maestro's part out of.
+func plannedEdits(root string, items []removeItem) []plannedEdit {
+	var edits []plannedEdit
+	for _, item := range items {
+		if item.Strip == nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(root, item.Path))
+		if err != nil {
+			continue
+		}
+		if _, change, err := item.Strip(string(data)); err == nil {
+			edits = append(edits, plannedEdit{Path: item.Path, Change: "remove " + change})
+		}
+	}
+	return edits
+}
+
+func joinItemNames(items []removeItem) string {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.Name
+	}
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+// stripAgentsMDBlock removes maestro's managed block from an instruction
+// file.
+func stripAgentsMDBlock(content string) (string, string, error) {
+	rest, _, err := agentsmd.RemoveBlock(content)
+	return rest, "the maestro block", err
+}
+
+// stripAppendedAgentsMD removes the instructions --agents-md-mode append
+// added to the end of the user's AGENTS.md.
+func stripAppendedAgentsMD(content string) (string, string, error) {
+	rest, _ := agentsmd.RemoveAppended(content, agentsMDContent)
+	return rest, "maestro's instructions", nil
+}
+
+// stripGitignoreEntries removes the lines init --gitignore adds, and the
+// blank lines left at the end, and says how many entries that was.
+func stripGitignoreEntries(content string) (string, string, error) {
+	maestroLines := map[string]bool{gitignoreHeader: true}
+	for _, entry := range gitignoreEntries() {
+		maestroLines[entry] = true
+	}
+	lines := strings.SplitAfter(content, "\n")
+	var kept []string
+	removed := 0
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if maestroLines[trimmed] {
+			if trimmed != gitignoreHeader {
+				removed++
+			}
+			continue
+		}
+		kept = append(kept, line)
+	}
+	for len(kept) > 0 && strings.TrimSpace(kept[len(kept)-1]) == "" {
+		kept = kept[:len(kept)-1]
+	}
+	if removed == 0 {
+		return content, "", nil
+	}
+	return strings.Join(kept, ""), fmt.Sprintf("%d maestro entries", removed), nil
+}
+
 // copyDir copies a directory recursively.
 func copyDir(src, dst string) error {
 	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/agentreport"
+	"github.com/spec-maestro/maestro-cli/pkg/output"
+	"github.com/spec-maestro/maestro-cli/pkg/state"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Summarize activity in the project",
+}
+
+var reportAgentsCmd = &cobra.Command{
+	Use:   "agents",
+	Short: "Show tasks completed, stage transitions, and average task time per actor",
+	Long: `Summarizes, for each actor, the stage transitions it drove and the bd tasks
+it completed, with the average time from creating to closing a task.
+
+Stage transitions come from the history in .maestro/state/<feature>.json.
+update-state.sh records the actor from the MAESTRO_ACTOR environment
+variable, so set it for each agent, e.g. MAESTRO_ACTOR=claude. Tasks come
+from the bd epic of each feature and are attributed to their assignee.
+Entries without an actor are counted under "unknown".`,
+	Args: cobra.NoArgs,
+	RunE: runReportAgents,
+}
+
+var (
+	reportOutput string
+	reportSince  string
+)
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.AddCommand(reportAgentsCmd)
+	reportAgentsCmd.Flags().StringVar(&reportOutput, "output", "text", dataFormatUsage)
+	reportAgentsCmd.Flags().StringVar(&reportSince, "since", "", "Only count activity on or after this date (YYYY-MM-DD)")
+	addProjectPathFlag(reportAgentsCmd, true)
+}
+
+func runReportAgents(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(reportOutput); err != nil {
+		return err
+	}
+	var since time.Time
+	if reportSince != "" {
+		var err error
+		if since, err = time.Parse("2006-01-02", reportSince); err != nil {
+			return fmt.Errorf("--since: want a date like 2026-01-31, got %q", reportSince)
+		}
+	}
+	root := workDir(cmd)
+	if _, err := os.Stat(filepath.Join(root, ".maestro")); os.IsNotExist(err) {
+		return fmt.Errorf("not initialized — run 'maestro init' first")
+	}
+
+	stateDir := filepath.Join(root, ".maestro", "state")
+	transitions, err := agentreport.ReadTransitions(stateDir)
+	if err != nil {
+		return err
+	}
+	tasks, err := bdFeatureTasks(root, stateDir)
+	if err != nil {
+		return err
+	}
+
+	stats := agentreport.Summarize(transitions, tasks, since)
+	if output.Data(reportOutput) {
+		return output.Write(os.Stdout, reportOutput, struct {
+			Actors     []agentreport.Stats `json:"actors"`
+			TaskSource string              `json:"task_source"`
+		}{stats, taskSource(tasks)})
+	}
+	return writeAgentReport(os.Stdout, stats, tasks)
+}
+
+// writeAgentReport prints the per-actor table and notes on missing data.
+func writeAgentReport(w io.Writer, stats []agentreport.Stats, tasks []agentreport.Task) error {
+	if len(stats) == 0 {
+		fmt.Fprintln(w, "No stage transitions or completed tasks recorded yet.")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ACTOR\tTASKS COMPLETED\tSTAGE TRANSITIONS\tAVG TIME PER TASK")
+	for _, s := range stats {
+		avg := "-"
+		if s.AvgTaskTime > 0 {
+			avg = s.AvgTaskTime.Round(time.Minute).String()
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", s.Actor, s.TasksCompleted, s.Transitions, avg)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if tasks == nil {
+		fmt.Fprintln(w, "\nNo tasks counted: bd is not installed or no feature has an epic.")
+	}
+	if last := stats[len(stats)-1]; last.Actor == agentreport.Unknown {
+		fmt.Fprintln(w, "\nActivity without an actor is counted as unknown. Set MAESTRO_ACTOR for each agent so update-state.sh records it, and assign bd tasks.")
+	}
+	return nil
+}
+
+// bdFeatureTasks lists the tasks of every feature epic recorded in the
+// state files in stateDir, running bd in the project at root. It returns
+// nil when bd is not installed.
+func bdFeatureTasks(root, stateDir string) ([]agentreport.Task, error) {
+	if _, err := exec.LookPath("bd"); err != nil {
+		return nil, nil
+	}
+	paths, err := filepath.Glob(filepath.Join(stateDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []agentreport.Task
+	for _, path := range paths {
+		s, err := state.Load(path)
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		if err != nil || s.EpicID == "" {
+			continue
+		}
+		c := exec.Command("bd", "list", "--all", "--parent", s.EpicID, "--json", "--limit", "0")
+		c.Dir = root
+		out, err := c.Output()
+		if err != nil {
+			return nil, fmt.Errorf("listing tasks of %s: %w", s.EpicID, err)
+		}
+		var epicTasks []agentreport.Task
+		if err := json.Unmarshal(out, &epicTasks); err != nil {
+			return nil, fmt.Errorf("parsing bd output for %s: %w", s.EpicID, err)
+		}
+		if tasks == nil {
+			tasks = []agentreport.Task{}
+		}
+		tasks = append(tasks, epicTasks...)
+	}
+	return tasks, nil
+}
+
+func taskSource(tasks []agentreport.Task) string {
+	if tasks == nil {
+		return "none"
+	}
+	return "bd"
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+	"github.com/spec-maestro/maestro-cli/pkg/snapshot"
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <archive>",
+	Short: "Restore a project from a remove --backup-archive backup",
+	Long: `Restores what 'maestro remove --backup-archive' removed from the
+.maestro-backup-<timestamp>.tar.gz it wrote. The archive is checked against
+its .sha256 file first. Files already in the project are not overwritten
+unless --force is given; files the archive does not hold are left alone.
+
+To restore one feature's snapshot, use 'maestro specs restore'.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRestore,
+}
+
+var (
+	restoreForce      bool
+	restoreNoChecksum bool
+)
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+	addProjectPathFlag(restoreCmd, false)
+	restoreCmd.Flags().BoolVarP(&restoreForce, "force", "f", false, "Overwrite files that already exist")
+	restoreCmd.Flags().BoolVar(&restoreNoChecksum, "no-checksum", false, "Restore even when the archive has no .sha256 file")
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	archive := userPath(cmd, args[0])
+
+	err := snapshot.VerifyBackup(archive)
+	switch {
+	case errors.Is(err, snapshot.ErrNoChecksum) && restoreNoChecksum:
+		fmt.Printf("%s %s has no checksum file; restoring without verifying it\n", glyph.Warn(), pathfmt.Path(archive))
+	case errors.Is(err, snapshot.ErrNoChecksum):
+		return fmt.Errorf("%w; pass --no-checksum to restore it anyway", err)
+	case err != nil:
+		return fmt.Errorf("%w; the archive may be damaged", err)
+	default:
+		fmt.Printf("%s Checksum verified\n", glyph.OK())
+	}
+
+	restored, err := snapshot.RestoreBackup(archive, workDir(cmd), restoreForce)
+	if errors.Is(err, snapshot.ErrExists) {
+		return fmt.Errorf("%w; pass --force to overwrite them", err)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s Restored %s from %s\n", glyph.OK(), strings.Join(restored, ", "), pathfmt.Path(archive))
+	return nil
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/agents"
+	"github.com/spec-maestro/maestro-cli/pkg/config"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/guard"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+	"github.com/spec-maestro/maestro-cli/pkg/report"
+)
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Undo the last update",
+	Long: `Restores .maestro/ to the snapshot 'maestro update' took before it
+changed anything, removing files the update added. With --agent-dirs the
+agent directories and instruction files are restored too; by default they
+are left as they are.
+
+The snapshot is kept under ` + guard.Dir + `/ and can also be restored
+with 'maestro guard rollback'.`,
+	Args: cobra.NoArgs,
+	RunE: runRollback,
+}
+
+var rollbackAgentDirs bool
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+	addProjectPathFlag(rollbackCmd, true)
+	rollbackCmd.Flags().BoolVar(&rollbackAgentDirs, "agent-dirs", false, "Also restore the agent directories and instruction files")
+}
+
+// updateInstructionFiles lists the instruction files update may rewrite
+// for the installed agent dirs.
+func updateInstructionFiles(dirs []string) []string {
+	files := []string{"AGENTS.md"}
+	for _, dir := range dirs {
+		if file := agents.InstructionFile(dir); file != "" && file != "AGENTS.md" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// snapshotBeforeUpdate saves .maestro/, the installed agent directories,
+// and their instruction files before update changes them, and records the
+// snapshot in the config for maestro rollback. root is the project.
+func snapshotBeforeUpdate(root string, cfg *config.ProjectConfig, to string, op *report.Operation) error {
+	dirs := agents.DetectInstalled(root)
+	extra, err := guard.Files(root, dirs)
+	if err != nil {
+		return op.Fail("snapshot", err)
+	}
+	extra = append(extra, updateInstructionFiles(dirs)...)
+
+	now := time.Now()
+	path, err := guard.Take(root, extra, now)
+	if err != nil {
+		return op.Fail("snapshot", err)
+	}
+	snapshot := config.UpdateSnapshot{
+		Path:      projectRel(root, path),
+		TakenAt:   now,
+		From:      installedAssetVersion(cfg),
+		To:        to,
+		AgentDirs: dirs,
+	}
+	if err := config.RecordSnapshot(projectConfig(root), snapshot); err != nil {
+		return op.Fail("snapshot", fmt.Errorf("recording snapshot: %w", err))
+	}
+	fmt.Printf("%s Saved the project to %s; 'maestro rollback' restores it\n", glyph.OK(), pathfmt.Path(path))
+	op.OK("snapshot", pathfmt.Rel(path))
+	return nil
+}
+
+func runRollback(cmd *cobra.Command, args []string) error {
+	root := workDir(cmd)
+	if _, err := os.Stat(filepath.Join(root, ".maestro")); os.IsNotExist(err) {
+		return fmt.Errorf("not initialized — run 'maestro init' first")
+	}
+	cfg, err := config.Load(projectConfig(root))
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	snapshot := cfg.Installed.Snapshot
+	if snapshot == nil {
+		return fmt.Errorf("no update to roll back; 'maestro update' saves the project before changing it")
+	}
+	snapshotPath := filepath.FromSlash(snapshot.Path)
+	if !filepath.IsAbs(snapshotPath) {
+		snapshotPath = filepath.Join(root, snapshotPath)
+	}
+	if _, err := os.Stat(snapshotPath); err != nil {
+		return fmt.Errorf("snapshot %s of the last update is gone: %w", snapshot.Path, err)
+	}
+
+	what := ".maestro/"
+	var paths []string
+	if rollbackAgentDirs && len(snapshot.AgentDirs) > 0 {
+		paths = append(append(paths, snapshot.AgentDirs...), updateInstructionFiles(snapshot.AgentDirs)...)
+		what += " and " + strings.Join(snapshot.AgentDirs, ", ")
+	}
+	from := snapshot.From
+	if from == "" {
+		from = "the previous version"
+	}
+	fmt.Printf("Last update: %s %s %s on %s\n", from, glyph.Arrow(), snapshot.To, snapshot.TakenAt.Local().Format("2006-01-02 15:04"))
+	ok, err := confirm(os.Stdin, os.Stdout, fmt.Sprintf("Restore %s to %s?", what, from))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	written, removed, err := guard.RollbackPaths(root, snapshotPath, paths)
+	if err != nil {
+		return fmt.Errorf("rolling back: %w", err)
+	}
+	fmt.Printf("%s Rolled back %s to %s (%d file(s) restored, %d removed)\n", glyph.OK(), what, from, written, removed)
+	if !rollbackAgentDirs && len(snapshot.AgentDirs) > 0 {
+		fmt.Println("Agent directories were left as they are; add --agent-dirs to restore them too.")
+	}
+	return nil
+}
@@ -1,12 +1,22 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 
 	"github.com/spec-maestro/maestro-cli/internal/version"
+	"github.com/spec-maestro/maestro-cli/pkg/config"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/httpheaders"
+	"github.com/spec-maestro/maestro-cli/pkg/newline"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+	"github.com/spec-maestro/maestro-cli/pkg/userdirs"
 )
 
 var rootCmd = &cobra.Command{
@@ -14,15 +24,130 @@ var rootCmd = &cobra.Command{
 	Short:   "Maestro CLI - manage maestro projects",
 	Long:    "maestro is a CLI for initializing, updating, and validating maestro projects.",
 	Version: version.Version,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Before anything reads the global config or the cache
+		userdirs.SetPortable(portable)
+		if err := resolveDirs(cmd); err != nil {
+			return err
+		}
+		detectUnattended()
+		conflictActionChosen = cmd.Flags().Changed("conflict-action")
+		if err := applyProjectSettings(cmd); err != nil {
+			return err
+		}
+		if err := startTimeout(cmd); err != nil {
+			return err
+		}
+		warnDeprecations(cmd)
+		startUpdateCheck(cmd)
+		return nil
+	},
+}
+
+// accessible is the --accessible flag.
+var accessible bool
+
+// portable is the --portable flag.
+var portable bool
+
+// exitError ends the process with code instead of 1. The command has
+// already reported why, so nothing more is printed.
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	timedOut := stopTimeout()
+	printUpdateNotice(os.Stderr)
+	if err != nil {
+		var exit *exitError
+		switch {
+		case timedOut:
+			// Whatever the command failed with, it ran out of time
+			if !errors.As(err, &exit) {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			os.Exit(timeoutExitCode)
+		case errors.As(err, &exit):
+			os.Exit(exit.code)
+		}
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
 
+// applyProjectSettings applies process-wide settings resolved from the
+// environment, .maestro/config.yaml, and the global config. Flags given on
+// the command line take precedence.
+func applyProjectSettings(cmd *cobra.Command) error {
+	resolver := projectResolver(workDir(cmd))
+	value, err := resolver.String("newline", "")
+	if err != nil {
+		// Leave reporting unreadable config to the commands that need it
+		glyph.SetAccessible(accessible)
+		pathfmt.SetHyperlinks(hyperlinksSupported())
+		return applyHTTPHeaders()
+	}
+
+	policy, err := newline.Parse(value)
+	if err != nil {
+		return fmt.Errorf("resolving newline setting: %w", err)
+	}
+	newline.SetPolicy(policy)
+
+	if !cmd.Flags().Changed("accessible") {
+		value, _ := resolver.String("accessible", "false")
+		if accessible, err = strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("resolving accessible setting: %q is not true or false", value)
+		}
+	}
+	glyph.SetAccessible(accessible)
+
+	value, _ = resolver.String("hyperlinks", "auto")
+	switch strings.ToLower(value) {
+	case "auto":
+		pathfmt.SetHyperlinks(hyperlinksSupported())
+	case "always":
+		pathfmt.SetHyperlinks(true)
+	case "never":
+		pathfmt.SetHyperlinks(false)
+	default:
+		return fmt.Errorf("resolving hyperlinks setting: %q is not auto, always, or never", value)
+	}
+	return applyHTTPHeaders()
+}
+
+// hyperlinksSupported reports whether printed paths should be hyperlinks
+// when the hyperlinks setting is auto: stdout must be a terminal known to
+// render them, and accessible output must be off.
+func hyperlinksSupported() bool {
+	return !accessible && isTerminal(os.Stdout) && pathfmt.Detect(os.Getenv)
+}
+
+// applyHTTPHeaders loads the extra request headers for artifact mirrors
+// from http.headers in the global config.
+func applyHTTPHeaders() error {
+	value, ok, err := config.GlobalValue("http.headers")
+	if err != nil || !ok {
+		return nil
+	}
+	var rules []httpheaders.Rule
+	if err := yaml.Unmarshal([]byte(value), &rules); err != nil {
+		return fmt.Errorf("reading http.headers from the global config: %w", err)
+	}
+	if err := httpheaders.SetRules(rules); err != nil {
+		return fmt.Errorf("reading http.headers from the global config: %w", err)
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.SetVersionTemplate("maestro " + version.String() + "\n")
+	rootCmd.PersistentFlags().BoolVar(&portable, "portable", false, "Keep the cache and global config in "+userdirs.DataDirName+"/ next to the maestro binary instead of the home directory ("+userdirs.PortableEnv+"=1)")
+	rootCmd.PersistentFlags().BoolVar(&accessible, "accessible", false, "Plain ASCII status words instead of symbols, no progress redraws or full-screen wizard, for screen readers (config: accessible)")
 }
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/agents"
+	"github.com/spec-maestro/maestro-cli/pkg/config"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+)
+
+var scriptsCmd = &cobra.Command{
+	Use:   "scripts",
+	Short: "Manage the helper scripts and other starter directories in .maestro/",
+}
+
+var scriptsUpdateCmd = &cobra.Command{
+	Use:   "update [dir...]",
+	Short: "Refresh selected .maestro/ starter directories from the pinned release",
+	Long: `Re-fetches only the named starter directories (default: scripts) from the
+release recorded in .maestro/config.yaml, leaving the rest of .maestro/ untouched.
+
+Valid directories: ` + strings.Join(starterDirNames(), ", ") + `
+
+Each refreshed directory is replaced as a whole; local edits inside it are lost
+unless --backup is given.`,
+	ValidArgs: starterDirNames(),
+	Args:      cobra.OnlyValidArgs,
+	RunE:      runScriptsUpdate,
+}
+
+var (
+	scriptsUpdateBackup    bool
+	scriptsUpdateForceSelf bool
+)
+
+func init() {
+	rootCmd.AddCommand(scriptsCmd)
+	scriptsCmd.AddCommand(scriptsUpdateCmd)
+	scriptsUpdateCmd.Flags().BoolVar(&scriptsUpdateBackup, "backup", false, "Back up each directory before replacing it")
+	scriptsUpdateCmd.Flags().BoolVar(&scriptsUpdateForceSelf, "force-self", false, "Allow running inside the maestro assets repository")
+	addProjectPathFlag(scriptsUpdateCmd, true)
+}
+
+func runScriptsUpdate(cmd *cobra.Command, args []string) error {
+	root := workDir(cmd)
+	if _, err := os.Stat(filepath.Join(root, ".maestro")); os.IsNotExist(err) {
+		return fmt.Errorf("not initialized — run 'maestro init' first")
+	}
+	if err := guardAssetsRepo(root, "maestro scripts update", scriptsUpdateForceSelf); err != nil {
+		return err
+	}
+	if err := guardTargetDir(cmd); err != nil {
+		return err
+	}
+
+	dirs := resolveStarterDirs(args)
+
+	cfg, err := config.Load(projectConfig(root))
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	ref := pinnedRef(cfg.CLIVersion)
+
+	client, err := assetsClient(root, "")
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Refreshing %s from %s...\n", strings.Join(dirs, ", "), ref)
+	fetch := func(dir string) (map[string][]byte, error) {
+		return fetchAgentDirWithRefFallback(client, dir, ref)
+	}
+
+	action := agents.ConflictOverwrite
+	if scriptsUpdateBackup {
+		action = agents.ConflictBackup
+	}
+	result, err := agents.InstallRequiredAssets(root, dirs, action, fetch)
+	if err != nil {
+		return fmt.Errorf("refreshing starter directories: %w", err)
+	}
+
+	if err := config.RecordInstall(projectConfig(root), "", result.Installed); err != nil {
+		return fmt.Errorf("recording install manifest: %w", err)
+	}
+
+	for _, backup := range result.Backups {
+		fmt.Printf("Backup created: %s\n", pathfmt.Path(backup))
+	}
+	for _, dir := range result.Installed {
+		fmt.Printf("%s Refreshed %s\n", glyph.OK(), dir)
+	}
+	return nil
+}
+
+// starterDirNames returns the short names (e.g. "scripts") of the required
+// starter directories that can be refreshed individually.
+func starterDirNames() []string {
+	required := agents.RequiredStarterAssetDirs()
+	names := make([]string, 0, len(required))
+	for _, dir := range required {
+		names = append(names, path.Base(dir))
+	}
+	return names
+}
+
+// resolveStarterDirs maps short names to .maestro/ paths, defaulting to scripts.
+func resolveStarterDirs(names []string) []string {
+	if len(names) == 0 {
+		names = []string{"scripts"}
+	}
+
+	seen := make(map[string]bool, len(names))
+	dirs := make([]string, 0, len(names))
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		dirs = append(dirs, path.Join(".maestro", name))
+	}
+	return dirs
+}
+
+// pinnedRef returns the git ref matching the release a project was installed
+// from, falling back to main for development builds.
+func pinnedRef(cliVersion string) string {
+	if cliVersion == "" || cliVersion == "dev" {
+		return "main"
+	}
+	return cliVersion
+}
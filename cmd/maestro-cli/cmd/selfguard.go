@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// assetsRepoMarker is committed at the root of the maestro assets repository
+// so the CLI can recognise it even in clones without a matching remote.
+const assetsRepoMarker = ".maestro-assets-repo"
+
+// isAssetsRepo reports whether root is a checkout of the
+// maestro assets repository (or a fork of it), whose .maestro/ is
+// source-controlled content rather than an installed copy. It returns a short
+// reason when it is.
+func isAssetsRepo(root string) (bool, string) {
+	if _, err := os.Stat(filepath.Join(root, assetsRepoMarker)); err == nil {
+		return true, fmt.Sprintf("found %s", assetsRepoMarker)
+	}
+
+	out, err := gitOutput(root, "remote", "-v")
+	if err != nil {
+		return false, ""
+	}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		url := strings.TrimSuffix(strings.TrimSuffix(fields[1], "/"), ".git")
+		if strings.HasSuffix(url, "/"+githubRepo) {
+			return true, fmt.Sprintf("git remote %s points at %s", fields[0], fields[1])
+		}
+	}
+	return false, ""
+}
+
+// guardAssetsRepo refuses to let a command overwrite .maestro/ inside the
+// assets repository, at root, unless force is set.
+func guardAssetsRepo(root, command string, force bool) error {
+	self, reason := isAssetsRepo(root)
+	if !self {
+		return nil
+	}
+	if force {
+		fmt.Fprintf(os.Stderr, "Warning: running '%s' inside the maestro assets repository (%s); source-controlled .maestro/ content will be overwritten.\n", command, reason)
+		return nil
+	}
+	return fmt.Errorf("refusing to run '%s' inside the maestro assets repository (%s): it would overwrite source-controlled .maestro/ content; rerun with --force-self if you really mean it", command, reason)
+}
@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/internal/version"
+	"github.com/spec-maestro/maestro-cli/pkg/agents"
+	"github.com/spec-maestro/maestro-cli/pkg/assets"
+	"github.com/spec-maestro/maestro-cli/pkg/config"
+	"github.com/spec-maestro/maestro-cli/pkg/doctor"
+	"github.com/spec-maestro/maestro-cli/pkg/embedded"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/state"
+)
+
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Run an end-to-end sanity suite in a temporary directory",
+	Long:  "Runs init (offline), doctor, state write/read, archive extraction, and install rollback against a throwaway project in a temporary directory. Use it to confirm a fresh install works on this platform before running maestro on a real repository.",
+	Args:  cobra.NoArgs,
+	RunE:  runSelftest,
+}
+
+var selftestKeep bool
+
+func init() {
+	rootCmd.AddCommand(selftestCmd)
+	selftestCmd.Flags().BoolVar(&selftestKeep, "keep", false, "Keep the temporary project directory for inspection")
+}
+
+// selftestStep is one stage of the selftest suite. Steps run in order inside
+// the temporary project directory, passed as root, and later steps rely on
+// earlier ones.
+type selftestStep struct {
+	name string
+	run  func(root string) error
+}
+
+var selftestSteps = []selftestStep{
+	{name: "init (offline)", run: selftestInit},
+	{name: "doctor", run: selftestDoctor},
+	{name: "state write/read", run: selftestState},
+	{name: "archive extraction", run: selftestExtraction},
+	{name: "install rollback", run: selftestRollback},
+}
+
+func runSelftest(cmd *cobra.Command, args []string) error {
+	fmt.Printf("Running maestro %s selftest...\n", version.Version)
+
+	dir, err := os.MkdirTemp("", "maestro-selftest-")
+	if err != nil {
+		return fmt.Errorf("creating temporary directory: %w", err)
+	}
+	if selftestKeep {
+		fmt.Printf("Project directory: %s\n", dir)
+	} else {
+		defer os.RemoveAll(dir)
+	}
+
+	failed := 0
+	for _, step := range selftestSteps {
+		start := time.Now()
+		if err := step.run(dir); err != nil {
+			failed++
+			fmt.Printf("%s %-30s %v\n", glyph.Fail(), step.name, err)
+			continue
+		}
+		fmt.Printf("%s %-30s %s\n", glyph.OK(), step.name, time.Since(start).Round(time.Millisecond))
+	}
+
+	if failed > 0 {
+		fmt.Printf("\n%d of %d selftest step(s) failed on %s.\n", failed, len(selftestSteps), version.String())
+		return fmt.Errorf("selftest failed")
+	}
+
+	fmt.Printf("\n%s All selftest steps passed — maestro works on this platform!\n", glyph.OK())
+	return nil
+}
+
+// selftestInit installs the embedded starter assets and config the same way
+// 'maestro init' does, without prompting.
+func selftestInit(root string) error {
+	if err := installRequiredStarterAssets(root, embeddedInitSource(), strings.NewReader(""), io.Discard); err != nil {
+		return err
+	}
+
+	// Unlike init, a missing starter file is a failure here: it means the
+	// binary was built without its embedded resources.
+	for _, filePath := range agents.RequiredStarterAssetFiles() {
+		content, err := embedded.FetchFile(filePath)
+		if err != nil {
+			return fmt.Errorf("reading embedded %s: %w", filePath, err)
+		}
+		if err := os.WriteFile(filepath.Join(root, filePath), content, 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", filePath, err)
+		}
+	}
+
+	for _, dir := range []string{"specs", "state", "research", "memory"} {
+		if err := os.MkdirAll(filepath.Join(root, ".maestro", dir), 0755); err != nil {
+			return fmt.Errorf("creating directory %s: %w", dir, err)
+		}
+	}
+
+	cfg := &config.ProjectConfig{
+		CLIVersion:    version.Version,
+		InitializedAt: time.Now(),
+	}
+	return config.Save(cfg, projectConfig(root))
+}
+
+// selftestDoctor runs the doctor project-structure checks. System dependency
+// checks are skipped because they describe the host, not the install.
+func selftestDoctor(root string) error {
+	for _, r := range projectStructureChecks(filepath.Join(root, ".maestro")) {
+		if r.Status() == doctor.StatusFail {
+			return fmt.Errorf("%s: %s", r.Name, r.Message)
+		}
+	}
+	return nil
+}
+
+// selftestState writes a feature state file and reads it back.
+func selftestState(root string) error {
+	want := &state.State{FeatureID: "000-selftest"}
+	want.Record("specify", "selftest", "", time.Now())
+	path := state.Path(filepath.Join(root, ".maestro"), want.FeatureID)
+	if err := state.Save(path, want); err != nil {
+		return fmt.Errorf("writing state: %w", err)
+	}
+
+	got, err := state.Load(path)
+	if err != nil {
+		return fmt.Errorf("reading state: %w", err)
+	}
+	if err := got.Validate(); err != nil {
+		return fmt.Errorf("reading state: %w", err)
+	}
+	if got.FeatureID != want.FeatureID || got.Stage != want.Stage || got.CreatedAt != want.CreatedAt || len(got.History) != 1 {
+		return fmt.Errorf("state round-trip mismatch: got %+v, want %+v", got, want)
+	}
+	return nil
+}
+
+// selftestExtraction builds small archives and checks that extraction
+// writes their content and rejects entries escaping the destination.
+func selftestExtraction(root string) error {
+	work, err := os.MkdirTemp(root, "extract-")
+	if err != nil {
+		return fmt.Errorf("creating work directory: %w", err)
+	}
+	defer os.RemoveAll(work)
+
+	archive := filepath.Join(work, "bundle.tar.gz")
+	if err := writeSelftestArchive(archive, "bundle/hello.txt", "hello from maestro\n"); err != nil {
+		return err
+	}
+	dest := filepath.Join(work, "out")
+	if err := assets.ExtractAsset(root, archive, dest); err != nil {
+		return fmt.Errorf("extracting archive: %w", err)
+	}
+	got, err := os.ReadFile(filepath.Join(dest, "bundle", "hello.txt"))
+	if err != nil {
+		return fmt.Errorf("reading extracted file: %w", err)
+	}
+	if string(got) != "hello from maestro\n" {
+		return fmt.Errorf("extracted content mismatch: %q", got)
+	}
+
+	evil := filepath.Join(work, "evil.tar.gz")
+	if err := writeSelftestArchive(evil, "../escape.txt", "escaped"); err != nil {
+		return err
+	}
+	if err := assets.ExtractAsset(root, evil, filepath.Join(work, "evil")); err == nil {
+		return fmt.Errorf("archive entry escaping the destination was not rejected")
+	}
+	return nil
+}
+
+func writeSelftestArchive(path, name, content string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating archive: %w", err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+		return fmt.Errorf("writing archive header: %w", err)
+	}
+	if _, err := tw.Write([]byte(content)); err != nil {
+		return fmt.Errorf("writing archive content: %w", err)
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("closing archive: %w", err)
+	}
+	return gz.Close()
+}
+
+// selftestRollback forces the last required directory to fail mid-install
+// and checks that the previously installed assets are restored untouched.
+func selftestRollback(root string) error {
+	required := agents.RequiredStarterAssetDirs()
+	marker := filepath.Join(root, required[0], "selftest-marker.md")
+	if err := os.WriteFile(marker, []byte("keep me\n"), 0644); err != nil {
+		return fmt.Errorf("writing marker: %w", err)
+	}
+
+	fetch := embedded.NewAssetFetcher()
+	failing := func(dir string) (map[string][]byte, error) {
+		if dir == required[len(required)-1] {
+			return map[string][]byte{"../escape.md": []byte("bad")}, nil
+		}
+		return fetch(dir)
+	}
+
+	if _, err := agents.InstallRequiredAssets(root, required, agents.ConflictOverwrite, failing); err == nil {
+		return fmt.Errorf("install with an invalid asset path unexpectedly succeeded")
+	}
+
+	if _, err := os.Stat(marker); err != nil {
+		return fmt.Errorf("existing assets were not restored after rollback: %w", err)
+	}
+	for _, dir := range required {
+		if _, err := os.Stat(filepath.Join(root, dir)); err != nil {
+			return fmt.Errorf("%s missing after rollback: %w", dir, err)
+		}
+	}
+	return selftestDoctor(root)
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+	"github.com/spec-maestro/maestro-cli/pkg/snapshot"
+)
+
+var specsCmd = &cobra.Command{
+	Use:     "specs",
+	Aliases: []string{"spec"},
+	Short:   "Manage feature artifacts under .maestro/specs/",
+}
+
+var specsSnapshotCmd = &cobra.Command{
+	Use:   "snapshot <feature>",
+	Short: "Save a feature's spec, research, plan, tasks, and state to an archive",
+	Long: `Saves the feature's directory under .maestro/specs/ and its state file
+.maestro/state/<feature>.json to a timestamped archive under
+.maestro/` + snapshot.Dir + `/, for 'maestro specs restore' to bring back.
+
+<feature> is the feature directory name (e.g. 003-user-auth) or just its
+number (e.g. 003).`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSpecsSnapshot,
+}
+
+var specsRestoreCmd = &cobra.Command{
+	Use:   "restore <feature> [snapshot]",
+	Short: "Revert a feature to a snapshot",
+	Long: `Replaces the feature's directory and state file with the content of a
+snapshot: the latest one, or the snapshot file given. Files created since
+the snapshot are removed. The current files are snapshotted first, so a
+restore can itself be undone.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runSpecsRestore,
+}
+
+var specsRestoreList bool
+
+func init() {
+	rootCmd.AddCommand(specsCmd)
+	specsCmd.AddCommand(specsSnapshotCmd)
+	specsCmd.AddCommand(specsRestoreCmd)
+	specsRestoreCmd.Flags().BoolVar(&specsRestoreList, "list", false, "List the feature's snapshots instead of restoring")
+	addProjectPathFlag(specsSnapshotCmd, true)
+	addProjectPathFlag(specsRestoreCmd, true)
+}
+
+func runSpecsSnapshot(cmd *cobra.Command, args []string) error {
+	maestroDir := filepath.Join(workDir(cmd), ".maestro")
+	if _, err := os.Stat(maestroDir); os.IsNotExist(err) {
+		return fmt.Errorf("not initialized — run 'maestro init' first")
+	}
+	featureID, err := resolveFeatureID(filepath.Join(maestroDir, "specs"), args[0])
+	if err != nil {
+		return err
+	}
+	path, err := snapshot.Create(maestroDir, featureID, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s Snapshot of %s saved to %s\n", glyph.OK(), featureID, pathfmt.Path(path))
+	return nil
+}
+
+func runSpecsRestore(cmd *cobra.Command, args []string) error {
+	maestroDir := filepath.Join(workDir(cmd), ".maestro")
+	if _, err := os.Stat(maestroDir); os.IsNotExist(err) {
+		return fmt.Errorf("not initialized — run 'maestro init' first")
+	}
+	featureID, err := resolveSnapshotFeature(maestroDir, args[0])
+	if err != nil {
+		return err
+	}
+	snapshots, err := snapshot.List(maestroDir, featureID)
+	if err != nil {
+		return err
+	}
+
+	if specsRestoreList {
+		if len(snapshots) == 0 {
+			fmt.Printf("No snapshots of %s.\n", featureID)
+		}
+		for _, path := range snapshots {
+			at, _ := snapshot.Time(path)
+			fmt.Printf("%s  %s\n", at.Format("2006-01-02 15:04:05"), pathfmt.Path(path))
+		}
+		return nil
+	}
+
+	var from string
+	switch {
+	case len(args) == 2:
+		from = userPath(cmd, args[1])
+		if _, err := os.Stat(from); os.IsNotExist(err) {
+			// A bare name refers to the snapshots directory
+			from = filepath.Join(maestroDir, filepath.FromSlash(snapshot.Dir), args[1])
+		}
+	case len(snapshots) > 0:
+		from = snapshots[len(snapshots)-1]
+	default:
+		return fmt.Errorf("no snapshots of %s; take one with 'maestro specs snapshot %s'", featureID, featureID)
+	}
+
+	current, err := snapshot.Create(maestroDir, featureID, time.Now())
+	if err != nil && !errors.Is(err, snapshot.ErrNoFiles) {
+		return fmt.Errorf("saving the current files before restoring: %w", err)
+	}
+	if err := snapshot.Restore(maestroDir, featureID, from); err != nil {
+		return err
+	}
+	fmt.Printf("%s Restored %s from %s\n", glyph.OK(), featureID, pathfmt.Path(from))
+	if current != "" {
+		fmt.Printf("The files it replaced were saved to %s\n", pathfmt.Path(current))
+	}
+	return nil
+}
+
+// resolveSnapshotFeature resolves a feature ID or number like
+// resolveFeatureID, also matching features that only exist in snapshots,
+// such as one whose directory was deleted, in the project whose .maestro
+// directory is maestroDir.
+func resolveSnapshotFeature(maestroDir, id string) (string, error) {
+	if err := checkFeatureID(id); err != nil {
+		return "", err
+	}
+	featureID, err := resolveFeatureID(filepath.Join(maestroDir, "specs"), id)
+	if err == nil {
+		return featureID, nil
+	}
+	ids, listErr := snapshot.Features(maestroDir)
+	if listErr != nil {
+		return "", listErr
+	}
+	var matches []string
+	for _, candidate := range ids {
+		if candidate == id {
+			return id, nil
+		}
+		if strings.HasPrefix(candidate, id+"-") {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	return "", err
+}
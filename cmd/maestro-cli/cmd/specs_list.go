@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/output"
+	"github.com/spec-maestro/maestro-cli/pkg/state"
+)
+
+var specsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show every feature with its stage, last update, and branch",
+	Long: `Lists the features under .maestro/specs/ with what their state files in
+.maestro/state/ record: the stage, when the state was last updated, and the
+feature's branch. The title is the first heading of spec.md, without a
+"Feature:" prefix; without one it is made from the directory name.
+Features without a state file show the stage no-state.`,
+	Args: cobra.NoArgs,
+	RunE: runSpecsList,
+}
+
+var specsListOutput string
+
+func init() {
+	specsCmd.AddCommand(specsListCmd)
+	specsListCmd.Flags().StringVar(&specsListOutput, "output", "table", "Output format: table, json, template=<go template>, or jsonpath=<expression>")
+	addFlagAlias(specsListCmd, "format", "output")
+	addProjectPathFlag(specsListCmd, true)
+}
+
+// specListEntry is one feature in specs list.
+type specListEntry struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	// Stage is the state file's stage, no-state without one, or invalid
+	// when it cannot be read.
+	Stage     string `json:"stage"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+	Branch    string `json:"branch,omitempty"`
+}
+
+func runSpecsList(cmd *cobra.Command, args []string) error {
+	if err := output.Validate(specsListOutput, "table", "json"); err != nil {
+		return err
+	}
+	maestroDir := filepath.Join(workDir(cmd), ".maestro")
+	if _, err := os.Stat(maestroDir); os.IsNotExist(err) {
+		return fmt.Errorf("not initialized — run 'maestro init' first")
+	}
+	entries, err := listSpecs(maestroDir)
+	if err != nil {
+		return err
+	}
+	if output.Data(specsListOutput) {
+		return output.Write(os.Stdout, specsListOutput, entries)
+	}
+	return writeSpecList(os.Stdout, entries)
+}
+
+// listSpecs reads every feature directory under maestroDir/specs and its
+// state file, sorted by ID.
+func listSpecs(maestroDir string) ([]specListEntry, error) {
+	specsDir := filepath.Join(maestroDir, "specs")
+	dirs, err := os.ReadDir(specsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading %s: %w", specsDir, err)
+	}
+
+	entries := []specListEntry{}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		id := dir.Name()
+		entry := specListEntry{ID: id, Title: specTitle(filepath.Join(specsDir, id, "spec.md"), id), Stage: "no-state"}
+
+		state, err := readSpecState(filepath.Join(maestroDir, "state", id+".json"))
+		if err != nil {
+			return nil, err
+		}
+		if state != nil {
+			entry.Stage, entry.UpdatedAt, entry.Branch = state.Stage, state.UpdatedAt, state.Branch
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries, nil
+}
+
+// readSpecState reads the state file at path. It returns nil when there is
+// none, and a state with the stage invalid when it is not a state file.
+func readSpecState(path string) (*state.State, error) {
+	s, err := state.Load(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if err != nil || s.Stage == "" {
+		return &state.State{Stage: "invalid"}, nil
+	}
+	return s, nil
+}
+
+// specTitle returns the first heading of the spec at path, without a
+// "Feature:" prefix, or, as list-features.sh does, the feature ID without
+// its number and with spaces for dashes.
+func specTitle(path, id string) string {
+	if f, err := os.Open(path); err == nil {
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if line := scanner.Text(); strings.HasPrefix(line, "# ") {
+				title := strings.TrimSpace(strings.TrimPrefix(line, "# "))
+				return strings.TrimSpace(strings.TrimPrefix(title, "Feature:"))
+			}
+		}
+	}
+	slug := id
+	if i := strings.Index(id, "-"); i > 0 && strings.Trim(id[:i], "0123456789") == "" {
+		slug = id[i+1:]
+	}
+	slug = strings.ReplaceAll(slug, "-", " ")
+	if slug == "" {
+		return id
+	}
+	return strings.ToUpper(slug[:1]) + slug[1:]
+}
+
+// specListTitleWidth is where long titles are cut in the table.
+const specListTitleWidth = 50
+
+// writeSpecList prints the features as a table.
+func writeSpecList(w io.Writer, entries []specListEntry) error {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "No features yet. Run /maestro.specify to start one.")
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTITLE\tSTAGE\tUPDATED\tBRANCH")
+	for _, e := range entries {
+		title := e.Title
+		if runes := []rune(title); len(runes) > specListTitleWidth {
+			title = string(runes[:specListTitleWidth-3]) + "..."
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", e.ID, title, e.Stage, specListTime(e.UpdatedAt), orDash(e.Branch))
+	}
+	return tw.Flush()
+}
+
+// specListTime shortens an RFC 3339 timestamp to the minute, in local
+// time. Anything else is shown as recorded.
+func specListTime(s string) string {
+	if s == "" {
+		return "-"
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.Local().Format("2006-01-02 15:04")
+	}
+	return s
+}
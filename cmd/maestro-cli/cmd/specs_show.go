@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/output"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+)
+
+var specsShowCmd = &cobra.Command{
+	Use:   "show <feature>",
+	Short: "Show a feature's stage, artifacts, and a summary of its spec",
+	Long: `Shows one feature: what its state file records, whether each of its
+artifacts exists, the metadata at the top of spec.md (the **Key:** value
+lines), and a summary of the spec with the first sentence of each section.
+
+<feature> is the feature directory name (e.g. 003-user-auth) or just its
+number (e.g. 003). --json prints the same as JSON, for agents and scripts;
+it is short for --output json, so the two cannot be combined.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSpecsShow,
+}
+
+var (
+	specsShowOutput string
+	specsShowJSON   bool
+)
+
+func init() {
+	specsCmd.AddCommand(specsShowCmd)
+	specsShowCmd.Flags().StringVar(&specsShowOutput, "output", "text", dataFormatUsage)
+	addFlagAlias(specsShowCmd, "format", "output")
+	specsShowCmd.Flags().BoolVar(&specsShowJSON, "json", false, "Print JSON, the same as --output json")
+	specsShowCmd.MarkFlagsMutuallyExclusive("json", "output")
+	specsShowCmd.MarkFlagsMutuallyExclusive("json", "format")
+	addProjectPathFlag(specsShowCmd, true)
+}
+
+// specDetail is everything specs show reports about a feature.
+type specDetail struct {
+	ID        string            `json:"id"`
+	Title     string            `json:"title"`
+	Stage     string            `json:"stage"`
+	CreatedAt string            `json:"created_at,omitempty"`
+	UpdatedAt string            `json:"updated_at,omitempty"`
+	Branch    string            `json:"branch,omitempty"`
+	EpicID    string            `json:"epic_id,omitempty"`
+	Metadata  map[string]string `json:"metadata"`
+	// metadataKeys are the metadata keys in the order of spec.md.
+	metadataKeys []string
+	Artifacts    []specArtifact `json:"artifacts"`
+	Sections     []specSection  `json:"sections"`
+}
+
+// specArtifact is a file or directory of the feature.
+type specArtifact struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Exists bool   `json:"exists"`
+}
+
+// specSection is a ## section of spec.md.
+type specSection struct {
+	Heading string `json:"heading"`
+	// Summary is the first sentence of the section's first paragraph,
+	// before any subsection.
+	Summary     string   `json:"summary,omitempty"`
+	Subsections []string `json:"subsections,omitempty"`
+}
+
+func runSpecsShow(cmd *cobra.Command, args []string) error {
+	format := specsShowOutput
+	if specsShowJSON {
+		format = "json"
+	}
+	if err := validateOutputFormat(format); err != nil {
+		return err
+	}
+	maestroDir := filepath.Join(workDir(cmd), ".maestro")
+	if _, err := os.Stat(maestroDir); os.IsNotExist(err) {
+		return fmt.Errorf("not initialized — run 'maestro init' first")
+	}
+	featureID, err := resolveFeatureID(filepath.Join(maestroDir, "specs"), args[0])
+	if err != nil {
+		return err
+	}
+	detail, err := showSpec(maestroDir, featureID)
+	if err != nil {
+		return err
+	}
+	if output.Data(format) {
+		return output.Write(os.Stdout, format, detail)
+	}
+	writeSpecDetail(os.Stdout, detail)
+	return nil
+}
+
+// showSpec gathers the feature's state, artifacts, and spec summary. Paths
+// are relative to the project root, the parent of maestroDir.
+func showSpec(maestroDir, featureID string) (*specDetail, error) {
+	root := filepath.Dir(maestroDir)
+	featureDir := filepath.Join(maestroDir, "specs", featureID)
+	statePath := filepath.Join(maestroDir, "state", featureID+".json")
+	state, err := readSpecState(statePath)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &specDetail{ID: featureID, Stage: "no-state", Metadata: map[string]string{}, Sections: []specSection{}}
+	paths := map[string]string{
+		"spec":     filepath.Join(featureDir, "spec.md"),
+		"research": filepath.Join(featureDir, "research"),
+		"plan":     filepath.Join(featureDir, "plan.md"),
+	}
+	if state != nil {
+		detail.Stage, detail.CreatedAt, detail.UpdatedAt = state.Stage, state.CreatedAt, state.UpdatedAt
+		detail.Branch, detail.EpicID = state.Branch, state.EpicID
+		for name, p := range map[string]string{"spec": state.SpecPath, "research": state.ResearchPath, "plan": state.PlanPath} {
+			if p != "" {
+				paths[name] = stateTarget(root, p)
+			}
+		}
+	}
+	paths["tasks"] = filepath.Join(featureDir, "tasks.json")
+	paths["state"] = statePath
+
+	for _, name := range []string{"spec", "research", "plan", "tasks", "state"} {
+		artifact := specArtifact{Name: name, Path: projectRel(root, paths[name])}
+		if _, err := os.Stat(paths[name]); err == nil {
+			artifact.Exists = true
+		}
+		detail.Artifacts = append(detail.Artifacts, artifact)
+	}
+
+	detail.Title = specTitle(paths["spec"], featureID)
+	if f, err := os.Open(paths["spec"]); err == nil {
+		detail.Metadata, detail.metadataKeys, detail.Sections = summarizeSpec(f)
+		f.Close()
+	}
+	return detail, nil
+}
+
+// projectRel returns path relative to root with forward slashes, or path
+// itself when it is outside root.
+func projectRel(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+var (
+	// specMetadataLine matches the **Key:** value lines under the title.
+	specMetadataLine = regexp.MustCompile(`^\*\*([^*:]+):\*\*\s*(.*)$`)
+	// sectionNumber is the numbering of headings such as "## 1. Problem".
+	sectionNumber = regexp.MustCompile(`^[0-9.]+\s+`)
+	// sentenceEnd ends the first sentence of a paragraph.
+	sentenceEnd = regexp.MustCompile(`[.!?](\s|$)`)
+	// orderedItem starts an item of a numbered list.
+	orderedItem = regexp.MustCompile(`^[0-9]+\.\s`)
+)
+
+// summarizeSpec reads the metadata before the first ## heading, with its
+// keys in order, and, for each ## section, its first sentence and its ###
+// headings.
+func summarizeSpec(r io.Reader) (map[string]string, []string, []specSection) {
+	metadata := map[string]string{}
+	var keys []string
+	sections := []specSection{}
+	var current *specSection
+	inCode := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "```") {
+			inCode = !inCode
+			continue
+		}
+		switch {
+		case inCode:
+		case strings.HasPrefix(line, "## "):
+			heading := sectionNumber.ReplaceAllString(strings.TrimSpace(line[3:]), "")
+			sections = append(sections, specSection{Heading: heading})
+			current = &sections[len(sections)-1]
+		case current == nil:
+			if m := specMetadataLine.FindStringSubmatch(line); m != nil {
+				if _, ok := metadata[m[1]]; !ok {
+					keys = append(keys, m[1])
+				}
+				metadata[m[1]] = m[2]
+			}
+		case strings.HasPrefix(line, "### "):
+			current.Subsections = append(current.Subsections, sectionNumber.ReplaceAllString(strings.TrimSpace(line[4:]), ""))
+		case current.Summary == "" && len(current.Subsections) == 0 && isProse(line):
+			current.Summary = firstSentence(line)
+		}
+	}
+	return metadata, keys, sections
+}
+
+// isProse reports whether a line starts a paragraph rather than a heading,
+// list, table, quote, or rule.
+func isProse(line string) bool {
+	if line == "" || line == "---" {
+		return false
+	}
+	for _, prefix := range []string{"#", "- ", "* ", "|", ">", "<!--"} {
+		if strings.HasPrefix(line, prefix) {
+			return false
+		}
+	}
+	return !orderedItem.MatchString(line)
+}
+
+func firstSentence(line string) string {
+	if loc := sentenceEnd.FindStringIndex(line); loc != nil {
+		return strings.TrimSpace(line[:loc[0]+1])
+	}
+	return line
+}
+
+// writeSpecDetail prints the feature for people.
+func writeSpecDetail(w io.Writer, d *specDetail) {
+	fmt.Fprintf(w, "%s: %s\n", d.ID, d.Title)
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+	for _, f := range [][2]string{
+		{"Stage", d.Stage},
+		{"Created", specListTime(d.CreatedAt)},
+		{"Updated", specListTime(d.UpdatedAt)},
+		{"Branch", orDash(d.Branch)},
+		{"Epic", orDash(d.EpicID)},
+	} {
+		fmt.Fprintf(tw, "  %s:\t%s\n", f[0], f[1])
+	}
+	tw.Flush()
+
+	if len(d.metadataKeys) > 0 {
+		fmt.Fprintln(w, "\nspec.md:")
+		for _, key := range d.metadataKeys {
+			fmt.Fprintf(tw, "  %s:\t%s\n", key, d.Metadata[key])
+		}
+		tw.Flush()
+	}
+
+	fmt.Fprintln(w, "\nArtifacts:")
+	for _, a := range d.Artifacts {
+		if a.Exists {
+			fmt.Fprintf(w, "  %s %-9s %s\n", glyph.OK(), a.Name, pathfmt.Path(a.Path))
+		} else {
+			fmt.Fprintf(w, "  %s %-9s %s (missing)\n", glyph.Bullet(), a.Name, a.Path)
+		}
+	}
+
+	if len(d.Sections) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nSummary:")
+	for _, s := range d.Sections {
+		fmt.Fprintf(w, "  %s\n", s.Heading)
+		if s.Summary != "" {
+			fmt.Fprintf(w, "    %s\n", s.Summary)
+		}
+		if len(s.Subsections) > 0 {
+			fmt.Fprintf(w, "    %s\n", strings.Join(s.Subsections, "; "))
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/state"
+)
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Read and write feature state files under .maestro/state/",
+}
+
+var stateUpdateCmd = &cobra.Command{
+	Use:   "update <feature> <stage> <action> [field=value...]",
+	Short: "Move a feature to a stage and record it in the feature's history",
+	Long: `Sets the feature's stage, stamps updated_at with the current time, and
+appends {stage, timestamp, action} to its history, with the actor when
+MAESTRO_ACTOR is set and the --note given. The first update creates the
+state file. Each field=value sets a top-level field; the value is JSON
+when it parses as JSON, otherwise a string. The resulting state is
+printed as JSON.
+
+.maestro/scripts/update-state.sh runs this with --force when maestro is
+installed, so the scripts record every stage they did before.
+
+Stages: ` + strings.Join(state.Stages, ", ") + `, and ` + state.Cancelled + `.
+A new feature starts at specify. A feature can go back to an earlier stage,
+or forward past clarify, research, analyze, and the stages after implement,
+but not past specify, plan, tasks, or implement; a cancelled feature stays
+cancelled. --force records the stage anyway, with a warning.
+
+The state file is written under MAESTRO_MAIN_REPO when it is set, as the
+scripts do from a git worktree.`,
+	Args: cobra.MinimumNArgs(3),
+	RunE: runStateUpdate,
+}
+
+var (
+	stateUpdateForce bool
+	stateUpdateNote  string
+)
+
+func init() {
+	rootCmd.AddCommand(stateCmd)
+	stateCmd.AddCommand(stateUpdateCmd)
+	stateUpdateCmd.Flags().BoolVar(&stateUpdateForce, "force", false, "Record the stage even when the transition is not allowed")
+	stateUpdateCmd.Flags().StringVar(&stateUpdateNote, "note", "", "Detail to record in the history entry beside the action")
+	addProjectPathFlag(stateUpdateCmd, true)
+}
+
+func runStateUpdate(cmd *cobra.Command, args []string) error {
+	featureID, stage, action := args[0], args[1], args[2]
+	fields, err := stateFields(args[3:])
+	if err != nil {
+		return err
+	}
+	base := userPath(cmd, os.Getenv("MAESTRO_MAIN_REPO"))
+	if base == "" {
+		base = workDir(cmd)
+	}
+	path := state.Path(filepath.Join(base, ".maestro"), featureID)
+
+	s, err := state.Load(path)
+	if errors.Is(err, os.ErrNotExist) {
+		s, err = &state.State{FeatureID: featureID}, nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for _, f := range fields {
+		if err := s.Set(f.key, f.value); err != nil {
+			return err
+		}
+	}
+
+	entry := state.Entry{Stage: stage, Action: action, Actor: os.Getenv("MAESTRO_ACTOR"), Note: stateUpdateNote}
+	if stateUpdateForce {
+		if err := state.CheckTransition(s.Stage, stage); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %v; recorded anyway\n", featureID, err)
+		}
+		s.RecordEntry(entry, time.Now())
+	} else if err := s.TransitionEntry(entry, time.Now()); err != nil {
+		return fmt.Errorf("%s: %w (--force records it anyway)", featureID, err)
+	}
+	if s.FeatureID == "" {
+		s.FeatureID = featureID
+	}
+	if err := state.Save(path, s); err != nil {
+		return err
+	}
+	data, err := state.Encode(s)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+type stateField struct {
+	key   string
+	value json.RawMessage
+}
+
+// stateFields parses field=value arguments, in order. A value is JSON when
+// it parses as JSON, and a string otherwise.
+func stateFields(args []string) ([]stateField, error) {
+	var fields []stateField
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%q is not field=value", arg)
+		}
+		raw := json.RawMessage(value)
+		if !json.Valid(raw) {
+			raw, _ = json.Marshal(value)
+		}
+		fields = append(fields, stateField{key, raw})
+	}
+	return fields, nil
+}
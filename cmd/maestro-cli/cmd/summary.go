@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spec-maestro/maestro-cli/pkg/output"
+	"github.com/spec-maestro/maestro-cli/pkg/report"
+)
+
+// outputFormatUsage is the help text of the --output flag on commands that
+// end with an operation summary.
+const outputFormatUsage = "Format of the summary or --dry-run plan: text, json, template=<go template>, or jsonpath=<expression> (all but text move progress output to stderr)"
+
+// dataFormatUsage is the help text of the --output flag on commands that
+// print a report.
+const dataFormatUsage = "Output format: text, json, template=<go template>, or jsonpath=<expression>"
+
+// beginOperation starts recording an operation for a command's final
+// summary. With the json format, or a template= or jsonpath= one, progress
+// output goes to stderr for the rest of the command so stdout carries only
+// the summary. The returned
+// finish func records the command's error, restores stdout, writes the
+// summary, and returns the error unchanged.
+func beginOperation(name, format string) (*report.Operation, func(error) error, error) {
+	if err := validateOutputFormat(format); err != nil {
+		return nil, nil, err
+	}
+
+	op := report.New(name)
+	stdout := os.Stdout
+	if output.Data(format) {
+		os.Stdout = os.Stderr
+	}
+
+	finish := func(err error) error {
+		os.Stdout = stdout
+		op.Finish(err)
+
+		if output.Data(format) {
+			if writeErr := output.Write(stdout, format, op.Summary()); writeErr != nil && err == nil {
+				return writeErr
+			}
+			return err
+		}
+		// A command that failed before doing anything has nothing to summarize
+		if len(op.Steps) > 0 {
+			op.WriteText(stdout)
+		}
+		return err
+	}
+	interruptOperation = finish
+	return op, finish, nil
+}
+
+func validateOutputFormat(format string) error {
+	return output.Validate(format, "text", "json")
+}
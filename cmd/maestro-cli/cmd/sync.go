@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/config"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/statesync"
+)
+
+const syncStateDir = ".maestro/state"
+
+// syncBasePath records what this machine last synced. It is not shared, so
+// it is kept out of .maestro/state/ and ignored by git.
+const syncBasePath = ".maestro/cache/sync.json"
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Share .maestro/state/ between machines",
+	Long: `Pushes and pulls the JSON files in .maestro/state/ through a shared backend.
+
+State is stored under an anonymous project fingerprint derived from the
+repository's root commit, so every clone of the project maps to the same
+entry without the backend learning the project's name or remote.
+
+The backend is configured in .maestro/config.yaml:
+
+  sync:
+    backend: git          # only "git" is supported for now
+    remote: origin
+    branch: maestro-state
+
+Files edited on both machines since the last sync are reported as conflicts
+and left alone; rerun with --force to overwrite them.`,
+}
+
+var syncPushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Upload local state to the sync backend",
+	Args:  cobra.NoArgs,
+	RunE:  runSyncPush,
+}
+
+var syncPullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Download state from the sync backend",
+	Args:  cobra.NoArgs,
+	RunE:  runSyncPull,
+}
+
+var (
+	syncRemote string
+	syncBranch string
+	syncForce  bool
+)
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.AddCommand(syncPushCmd)
+	syncCmd.AddCommand(syncPullCmd)
+	syncCmd.PersistentFlags().StringVar(&syncRemote, "remote", "", "Git remote to sync through (default: sync.remote, else origin)")
+	syncCmd.PersistentFlags().StringVar(&syncBranch, "branch", "", "Branch holding shared state (default: sync.branch, else maestro-state)")
+	syncCmd.PersistentFlags().BoolVar(&syncForce, "force", false, "Overwrite files changed on both sides")
+	addProjectPathFlag(syncPushCmd, true)
+	addProjectPathFlag(syncPullCmd, true)
+}
+
+func runSyncPush(cmd *cobra.Command, args []string) error {
+	syncer, err := newSyncer(workDir(cmd))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Pushing state for project %s to %s...\n", syncer.Fingerprint, syncer.Backend.Name())
+	result, err := syncer.Push(syncForce)
+	if err != nil {
+		return err
+	}
+	return printSyncResult(result, "Pushed", "maestro sync pull")
+}
+
+func runSyncPull(cmd *cobra.Command, args []string) error {
+	syncer, err := newSyncer(workDir(cmd))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Pulling state for project %s from %s...\n", syncer.Fingerprint, syncer.Backend.Name())
+	result, err := syncer.Pull(syncForce)
+	if err != nil {
+		return err
+	}
+	return printSyncResult(result, "Pulled", "maestro sync push")
+}
+
+// newSyncer builds a Syncer for the project at root from config and flags.
+func newSyncer(root string) (*statesync.Syncer, error) {
+	if _, err := os.Stat(filepath.Join(root, ".maestro")); os.IsNotExist(err) {
+		return nil, fmt.Errorf("not initialized — run 'maestro init' first")
+	}
+
+	backend, err := newSyncBackend(root, projectResolver(root))
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint, err := statesync.Fingerprint(root)
+	if err != nil {
+		return nil, fmt.Errorf("computing project fingerprint: %w", err)
+	}
+	stateDir := filepath.Join(root, syncStateDir)
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return nil, fmt.Errorf("creating %s: %w", syncStateDir, err)
+	}
+
+	return &statesync.Syncer{StateDir: stateDir, BasePath: filepath.Join(root, syncBasePath), Fingerprint: fingerprint, Backend: backend}, nil
+}
+
+// newSyncBackend returns the configured backend, with flags taking precedence
+// over resolved config values. git runs in root.
+func newSyncBackend(root string, r *config.Resolver) (statesync.Backend, error) {
+	settings := make(map[string]string, 3)
+	for _, key := range []string{"sync.backend", "sync.remote", "sync.branch"} {
+		value, err := r.String(key, "")
+		if err != nil {
+			return nil, fmt.Errorf("loading config: %w", err)
+		}
+		settings[key] = value
+	}
+
+	switch backend := settings["sync.backend"]; strings.ToLower(backend) {
+	case "git":
+	case "gist", "s3":
+		return nil, fmt.Errorf("sync backend %q is not supported yet; use \"git\"", backend)
+	default:
+		return nil, fmt.Errorf("unknown sync backend %q (want git)", backend)
+	}
+
+	remote := firstNonEmpty(syncRemote, settings["sync.remote"])
+	branch := firstNonEmpty(syncBranch, settings["sync.branch"])
+	return &statesync.GitBackend{RepoDir: root, Remote: remote, Branch: branch}, nil
+}
+
+func printSyncResult(result *statesync.Result, verb, otherCommand string) error {
+	for _, name := range result.Updated {
+		fmt.Printf("%s %s %s\n", glyph.OK(), verb, name)
+	}
+	if len(result.Updated) == 0 && len(result.Conflicts) == 0 {
+		fmt.Printf("%s Already up to date\n", glyph.OK())
+	} else if result.Unchanged > 0 {
+		fmt.Printf("  %d file(s) unchanged\n", result.Unchanged)
+	}
+
+	if len(result.Conflicts) > 0 {
+		for _, name := range result.Conflicts {
+			fmt.Printf("%s %s changed on both sides\n", glyph.Fail(), name)
+		}
+		return fmt.Errorf("%d conflicting file(s); reconcile them and rerun, or use --force (see also '%s')", len(result.Conflicts), otherCommand)
+	}
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+	"github.com/spec-maestro/maestro-cli/pkg/templates"
+)
+
+const templatesDir = ".maestro/templates"
+
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "Validate and preview .maestro/templates/",
+}
+
+var templatesLintCmd = &cobra.Command{
+	Use:   "lint [template...]",
+	Short: "Check templates for brace errors and unknown variables",
+	Long:  "Checks each template (default: every file in .maestro/templates/) for unbalanced braces and {UPPER_CASE} variables that have no known value. Free-text placeholders like {one sentence} are guidance for agents and are not checked.\n\nKnown variables: " + strings.Join(templateVariableNames(), ", "),
+	RunE:  runTemplatesLint,
+}
+
+var templatesRenderCmd = &cobra.Command{
+	Use:   "render <template>",
+	Short: "Render a template with variable values",
+	Long:  "Replaces {UPPER_CASE} variables with values given via --set. With --dry, unset variables are filled with sample data and the result is only printed, so template changes can be previewed before agents use them.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTemplatesRender,
+}
+
+var (
+	templatesSet       []string
+	templatesRenderDry bool
+	templatesRenderOut string
+)
+
+func init() {
+	rootCmd.AddCommand(templatesCmd)
+	templatesCmd.AddCommand(templatesLintCmd)
+	templatesCmd.AddCommand(templatesRenderCmd)
+
+	templatesLintCmd.Flags().StringArrayVar(&templatesSet, "set", nil, "Declare an extra variable as KEY=VALUE (repeatable)")
+	templatesRenderCmd.Flags().StringArrayVar(&templatesSet, "set", nil, "Set a variable as KEY=VALUE (repeatable)")
+	templatesRenderCmd.Flags().BoolVar(&templatesRenderDry, "dry", false, "Fill unset variables with sample data and print the preview")
+	templatesRenderCmd.Flags().StringVarP(&templatesRenderOut, "output", "o", "", "Write the rendered template to a file instead of stdout")
+	addProjectPathFlag(templatesLintCmd, true)
+	addProjectPathFlag(templatesRenderCmd, true)
+}
+
+func runTemplatesLint(cmd *cobra.Command, args []string) error {
+	vars, err := templateVars(templatesSet, true)
+	if err != nil {
+		return err
+	}
+
+	paths := make([]string, 0, len(args))
+	for _, arg := range args {
+		paths = append(paths, resolveTemplatePath(cmd, arg))
+	}
+	if len(paths) == 0 {
+		paths, err = filepath.Glob(filepath.Join(workDir(cmd), templatesDir, "*.md"))
+		if err != nil {
+			return fmt.Errorf("listing templates: %w", err)
+		}
+		if len(paths) == 0 {
+			return fmt.Errorf("no templates found in %s/", templatesDir)
+		}
+	}
+
+	failed := 0
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading template: %w", err)
+		}
+
+		issues := templates.Lint(content, vars)
+		if len(issues) == 0 {
+			fmt.Printf("%s %s\n", glyph.OK(), pathfmt.Rel(path))
+			continue
+		}
+		failed++
+		fmt.Printf("%s %s\n", glyph.Fail(), pathfmt.Rel(path))
+		for _, issue := range issues {
+			fmt.Printf("  %s\n", issue)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d template(s) have errors", failed)
+	}
+	return nil
+}
+
+func runTemplatesRender(cmd *cobra.Command, args []string) error {
+	vars, err := templateVars(templatesSet, templatesRenderDry)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(resolveTemplatePath(cmd, args[0]))
+	if err != nil {
+		return fmt.Errorf("reading template: %w", err)
+	}
+
+	rendered, issues := templates.Render(content, vars)
+	if len(issues) > 0 {
+		for _, issue := range issues {
+			fmt.Fprintf(os.Stderr, "  %s\n", issue)
+		}
+		return fmt.Errorf("template has %d error(s); run 'maestro templates lint' for details", len(issues))
+	}
+
+	if templatesRenderDry || templatesRenderOut == "" {
+		fmt.Print(rendered)
+		return nil
+	}
+	if err := os.WriteFile(userPath(cmd, templatesRenderOut), []byte(rendered), 0644); err != nil {
+		return fmt.Errorf("writing rendered template: %w", err)
+	}
+	fmt.Printf("%s Rendered %s to %s\n", glyph.OK(), args[0], templatesRenderOut)
+	return nil
+}
+
+// templateVars builds the variable set from KEY=VALUE pairs, optionally
+// starting from the sample data.
+func templateVars(pairs []string, withSamples bool) (map[string]string, error) {
+	vars := make(map[string]string)
+	if withSamples {
+		for k, v := range templates.SampleData {
+			vars[k] = v
+		}
+	}
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --set value %q: expected KEY=VALUE", pair)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+// resolveTemplatePath accepts a path, a file name in .maestro/templates/, or
+// a short name such as "spec" for spec-template.md.
+func resolveTemplatePath(cmd *cobra.Command, name string) string {
+	candidates := []string{
+		userPath(cmd, name),
+		filepath.Join(workDir(cmd), templatesDir, name),
+		filepath.Join(workDir(cmd), templatesDir, name+"-template.md"),
+	}
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+	return name
+}
+
+// templateVariableNames lists the variables that have sample data.
+func templateVariableNames() []string {
+	names := make([]string, 0, len(templates.SampleData))
+	for name := range templates.SampleData {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/deadline"
+)
+
+// commandTimeout is the --timeout flag.
+var commandTimeout time.Duration
+
+// timeoutGrace is how long a command may keep running past its deadline
+// to stop on its own and report what it did, before maestro exits anyway.
+const timeoutGrace = 10 * time.Second
+
+// timeoutExitCode is the exit status of a command stopped by --timeout,
+// as with timeout(1).
+const timeoutExitCode = 124
+
+var (
+	// interruptOperation writes the summary of the running operation, if
+	// any, when the watchdog has to end the command; beginOperation sets
+	// it.
+	interruptOperation func(error) error
+	// commandDone is closed when the command returns, which stands the
+	// watchdog down.
+	commandDone      = make(chan struct{})
+	closeCommandDone = sync.OnceFunc(func() { close(commandDone) })
+)
+
+func init() {
+	rootCmd.PersistentFlags().DurationVar(&commandTimeout, "timeout", 0, "Stop the command when it runs longer than this, e.g. 10m, canceling downloads and prompts (config: timeout; default: no limit)")
+}
+
+// startTimeout bounds the command by --timeout, or by the timeout setting
+// when the flag is not given, and starts the watchdog that ends it should
+// it not stop on its own.
+func startTimeout(cmd *cobra.Command) error {
+	d := commandTimeout
+	if !cmd.Flags().Changed("timeout") {
+		value, err := projectResolver(workDir(cmd)).String("timeout", "0")
+		if err != nil {
+			return nil
+		}
+		if d, err = time.ParseDuration(value); err != nil {
+			return fmt.Errorf("resolving timeout setting: %q is not a duration, e.g. 10m", value)
+		}
+	}
+	if d <= 0 {
+		return nil
+	}
+	deadline.Start(d)
+	go watchTimeout(deadline.Context())
+	return nil
+}
+
+// watchTimeout waits for the deadline of ctx. Downloads and prompts stop
+// there and the command returns with their error; a command stuck
+// elsewhere gets timeoutGrace more, after which its partial summary is
+// written and maestro exits with timeoutExitCode.
+func watchTimeout(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-commandDone:
+		return
+	}
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return
+	}
+	select {
+	case <-commandDone:
+		return
+	case <-time.After(timeoutGrace):
+	}
+
+	err := deadline.Err()
+	fmt.Fprintf(os.Stderr, "\nmaestro: %v; stopping.\n", err)
+	if interruptOperation != nil {
+		interruptOperation(err)
+	}
+	os.Exit(timeoutExitCode)
+}
+
+// stopTimeout stands the watchdog down once the command has returned, and
+// reports whether the deadline had passed.
+func stopTimeout() bool {
+	timedOut := deadline.Exceeded()
+	closeCommandDone()
+	deadline.Stop()
+	return timedOut
+}
@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/output"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+	"github.com/spec-maestro/maestro-cli/pkg/userdirs"
+)
+
+var uninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove maestro's global files from this machine",
+	Long: `Removes what maestro keeps outside projects: the asset cache, the global
+config, and shell completion scripts installed where the shells look for
+them. --binary also removes the maestro binary. Projects keep their .maestro/;
+run 'maestro remove' in each one to remove it.`,
+	Args: cobra.NoArgs,
+	RunE: runUninstall,
+}
+
+var (
+	uninstallBinary bool
+	uninstallForce  bool
+	uninstallPlan   bool
+	uninstallOutput string
+)
+
+func init() {
+	rootCmd.AddCommand(uninstallCmd)
+	uninstallCmd.Flags().BoolVar(&uninstallBinary, "binary", false, "Also remove the maestro binary")
+	uninstallCmd.Flags().BoolVarP(&uninstallForce, "force", "f", false, "Skip confirmation prompt")
+	uninstallCmd.Flags().BoolVar(&uninstallPlan, "plan", false, "List what would be removed without removing anything")
+	uninstallCmd.Flags().StringVar(&uninstallOutput, "output", "text", "Plan output format: text, json, template=<go template>, or jsonpath=<expression>")
+	addFlagAlias(uninstallCmd, "format", "output")
+}
+
+// uninstallNotes are printed after every uninstall: what maestro leaves
+// because it is not maestro's to remove.
+var uninstallNotes = []string{
+	"maestro stores no GitHub tokens. Unset GITHUB_TOKEN or GH_TOKEN, and run 'gh auth logout' to remove the gh CLI's login.",
+	"Projects keep their .maestro/. Run 'maestro remove' in each one to remove it.",
+}
+
+// uninstallItem is one path uninstall removes.
+type uninstallItem struct {
+	// Kind is cache, config, data, completion, or binary.
+	Kind  string `json:"kind"`
+	Label string `json:"label"`
+	Path  string `json:"path"`
+	Files int    `json:"files"`
+	Bytes int64  `json:"bytes"`
+	// Skipped says why the item is left for the user to remove.
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// uninstallationPlan describes everything uninstall would delete.
+type uninstallationPlan struct {
+	Items      []uninstallItem `json:"items"`
+	TotalBytes int64           `json:"total_bytes"`
+	Notes      []string        `json:"notes"`
+}
+
+// completionFile is where a shell looks for maestro's completion script.
+type completionFile struct {
+	shell string
+	path  string
+}
+
+// completionMarker is in every completion script maestro generates, so a
+// file of the same name that some other tool wrote is left alone.
+const completionMarker = "__maestro_"
+
+// completionFiles lists the places the completion instructions of bash,
+// zsh, and fish install a script, in the home directory and system-wide.
+func completionFiles(home string) []completionFile {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+	return []completionFile{
+		{"bash", filepath.Join(dataHome, "bash-completion", "completions", "maestro")},
+		{"bash", "/etc/bash_completion.d/maestro"},
+		{"bash", "/usr/local/etc/bash_completion.d/maestro"},
+		{"bash", "/opt/homebrew/etc/bash_completion.d/maestro"},
+		{"zsh", filepath.Join(home, ".zsh", "completions", "_maestro")},
+		{"zsh", filepath.Join(home, ".zfunc", "_maestro")},
+		{"zsh", filepath.Join(home, ".oh-my-zsh", "completions", "_maestro")},
+		{"zsh", "/usr/local/share/zsh/site-functions/_maestro"},
+		{"zsh", "/opt/homebrew/share/zsh/site-functions/_maestro"},
+		{"fish", filepath.Join(configHome, "fish", "completions", "maestro.fish")},
+	}
+}
+
+// buildUninstallationPlan finds the global files that exist: the cache and
+// config, or in portable mode the data directory holding both, the
+// completion scripts maestro generated, and with binary the executable.
+func buildUninstallationPlan(binary bool) (*uninstallationPlan, error) {
+	var items []uninstallItem
+	if userdirs.Portable() {
+		dir, err := userdirs.DataDir()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, uninstallItem{Kind: "data", Label: "portable data", Path: dir})
+	} else {
+		cache, err := userdirs.CacheDir()
+		if err != nil {
+			return nil, err
+		}
+		config, err := userdirs.ConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items,
+			uninstallItem{Kind: "cache", Label: "global cache", Path: cache},
+			uninstallItem{Kind: "config", Label: "global config", Path: config},
+		)
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		for _, f := range completionFiles(home) {
+			data, err := os.ReadFile(f.path)
+			if err == nil && bytes.Contains(data, []byte(completionMarker)) {
+				items = append(items, uninstallItem{Kind: "completion", Label: f.shell + " completion", Path: f.path})
+			}
+		}
+	}
+
+	if binary {
+		item, err := binaryItem()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	plan := &uninstallationPlan{Items: []uninstallItem{}, Notes: uninstallNotes}
+	for _, item := range items {
+		if _, err := os.Lstat(item.Path); os.IsNotExist(err) {
+			continue
+		}
+		entry, err := measureRemoval("", item.Path)
+		if err != nil {
+			return nil, err
+		}
+		item.Files, item.Bytes = entry.Files, entry.Bytes
+		plan.Items = append(plan.Items, item)
+		if item.Skipped == "" {
+			plan.TotalBytes += item.Bytes
+		}
+	}
+	return plan, nil
+}
+
+// binaryItem is the running maestro binary. A binary a package manager
+// installed is left to it, and Windows cannot delete a running program.
+func binaryItem() (uninstallItem, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return uninstallItem{}, fmt.Errorf("locating the maestro binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	item := uninstallItem{Kind: "binary", Label: "maestro binary", Path: exe}
+	switch {
+	case strings.Contains(filepath.ToSlash(exe), "/Cellar/"):
+		item.Skipped = "installed by Homebrew; run 'brew uninstall maestro'"
+	case runtime.GOOS == "windows":
+		item.Skipped = "Windows cannot delete a running program; delete it after maestro exits"
+	}
+	return item, nil
+}
+
+// printUninstallationPlan writes the plan in the requested format.
+func printUninstallationPlan(plan *uninstallationPlan, format string) error {
+	if err := validateOutputFormat(format); err != nil {
+		return err
+	}
+	if output.Data(format) {
+		return output.Write(os.Stdout, format, plan)
+	}
+
+	if len(plan.Items) == 0 {
+		fmt.Println("Nothing to remove.")
+	} else {
+		fmt.Println("The following would be removed:")
+		for _, item := range plan.Items {
+			line := fmt.Sprintf("  %-17s %s (%d files, %s)", item.Label, pathfmt.Path(item.Path), item.Files, formatBytes(item.Bytes))
+			if item.Skipped != "" {
+				line = fmt.Sprintf("  %-17s %s (left: %s)", item.Label, pathfmt.Path(item.Path), item.Skipped)
+			}
+			fmt.Println(line)
+		}
+		fmt.Printf("Total: %s\n", formatBytes(plan.TotalBytes))
+	}
+	for _, note := range plan.Notes {
+		fmt.Println(note)
+	}
+	return nil
+}
+
+func runUninstall(cmd *cobra.Command, args []string) error {
+	plan, err := buildUninstallationPlan(uninstallBinary)
+	if err != nil {
+		return err
+	}
+	if uninstallPlan {
+		return printUninstallationPlan(plan, uninstallOutput)
+	}
+	if len(plan.Items) == 0 {
+		fmt.Println("Nothing to remove: maestro has no global files on this machine.")
+		for _, note := range plan.Notes {
+			fmt.Println(note)
+		}
+		return nil
+	}
+
+	if !uninstallForce {
+		fmt.Println("This removes:")
+		for _, item := range plan.Items {
+			if item.Skipped == "" {
+				fmt.Printf("  %-17s %s\n", item.Label, pathfmt.Path(item.Path))
+			}
+		}
+		ok, err := confirm(os.Stdin, os.Stdout, "Remove maestro's global files from this machine?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Fprintln(os.Stderr, "Aborted.")
+			return nil
+		}
+	}
+
+	failed := 0
+	for _, item := range plan.Items {
+		if item.Skipped != "" {
+			fmt.Printf("%s %s left at %s: %s\n", glyph.Warn(), item.Label, pathfmt.Path(item.Path), item.Skipped)
+			continue
+		}
+		if err := os.RemoveAll(item.Path); err != nil {
+			failed++
+			fmt.Printf("%s %s: %v\n", glyph.Fail(), item.Label, err)
+			continue
+		}
+		fmt.Printf("%s Removed the %s: %s\n", glyph.OK(), item.Label, pathfmt.Path(item.Path))
+	}
+	for _, note := range plan.Notes {
+		fmt.Println(note)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d item(s) could not be removed", failed)
+	}
+	return nil
+}
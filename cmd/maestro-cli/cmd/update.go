@@ -2,9 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -14,11 +17,21 @@ import (
 	"github.com/spec-maestro/maestro-cli/pkg/config"
 	"github.com/spec-maestro/maestro-cli/pkg/fs"
 	ghclient "github.com/spec-maestro/maestro-cli/pkg/github"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/ignore"
+	"github.com/spec-maestro/maestro-cli/pkg/merge"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+	"github.com/spec-maestro/maestro-cli/pkg/report"
 )
 
 const (
 	githubOwner = "Tiagofv"
 	githubRepo  = "spec-maestro"
+
+	// freshDownloadAge is how long a release asset another update just
+	// downloaded is reused, so parallel updates on one machine download it
+	// once; older entries are downloaded again.
+	freshDownloadAge = 10 * time.Minute
 )
 
 var updateCmd = &cobra.Command{
@@ -28,16 +41,88 @@ var updateCmd = &cobra.Command{
 	RunE:  runUpdate,
 }
 
+var (
+	updateForceSelf      bool
+	updateOutput         string
+	updateDryRun         bool
+	updateFrom           string
+	updateCheck          bool
+	updateVersion        string
+	updateStrategy       string
+	updateChangelogSince string
+	updateNoSnapshot     bool
+	updateChannelFlag    string
+	updateAgentsOnly     bool
+	updateAssetsOnly     bool
+	updateAutoApply      bool
+)
+
 func init() {
 	rootCmd.AddCommand(updateCmd)
+	addProjectPathFlag(updateCmd, true)
+	updateCmd.Flags().BoolVar(&updateForceSelf, "force-self", false, "Allow running inside the maestro assets repository")
+	updateCmd.Flags().StringVar(&updateOutput, "output", "text", outputFormatUsage)
+	updateCmd.Flags().BoolVar(&updateDryRun, "dry-run", false, "List the files update would create or overwrite and flag conflicts, without writing anything")
+	updateCmd.Flags().BoolVar(&updateCheck, "check", false, fmt.Sprintf("Only report whether the CLI, assets, or agent dirs are out of date; exits %d when they are", exitUpdatesAvailable))
+	updateCmd.Flags().StringVar(&updateVersion, "version", "", "Update to this release tag (e.g. v1.2.0) instead of the latest; asks before downgrading")
+	updateCmd.Flags().StringVar(&updateChannelFlag, "channel", "", updateChannelUsage)
+	updateCmd.Flags().StringVar(&updateStrategy, "strategy", string(merge.WriteNew), updateStrategyUsage)
+	updateCmd.Flags().StringVar(&updateChangelogSince, "changelog-since", "", "Show the notes of every release after this version up to the target (without a value: since the installed version)")
+	updateCmd.Flags().Lookup("changelog-since").NoOptDefVal = changelogSinceInstalled
+	updateCmd.Flags().BoolVar(&updateAgentsOnly, "agents-only", false, "Only refresh the agent config directories (.opencode/, .claude/, .codex/); leave .maestro/ alone")
+	updateCmd.Flags().BoolVar(&updateAssetsOnly, "assets-only", false, "Only update the .maestro/ assets; leave the agent config directories alone")
+	updateCmd.Flags().BoolVar(&updateAutoApply, "auto-apply", false, "Apply the staged changes without asking")
+	updateCmd.Flags().BoolVar(&updateNoSnapshot, "no-snapshot", false, "Don't save the project before updating (disables 'maestro rollback')")
+	updateCmd.Flags().StringVar(&updateFrom, "from", "", "Update from a custom GitHub repository (owner/repo or URL) and use it from now on (default: the source recorded by init --from)")
 }
 
-func runUpdate(cmd *cobra.Command, args []string) error {
-	// Check project is initialized
-	if _, err := os.Stat(".maestro"); os.IsNotExist(err) {
-		return fmt.Errorf("not initialized — run 'maestro init' first")
+func runUpdate(cmd *cobra.Command, args []string) (err error) {
+	if updateCheck && updateDryRun {
+		return fmt.Errorf("--check and --dry-run cannot be used together")
+	}
+	if updateCheck && updateVersion != "" {
+		return fmt.Errorf("--check compares with the latest release and cannot be combined with --version")
+	}
+	if updateAgentsOnly && updateAssetsOnly {
+		return fmt.Errorf("--agents-only and --assets-only cannot be used together")
+	}
+	if _, err := merge.ParseStrategy(updateStrategy); err != nil {
+		return err
+	}
+	channel, err := updateChannel(cmd)
+	if err != nil {
+		return err
+	}
+	if updateCheck {
+		return runUpdateCheck(cmd, os.Stdout, channel)
+	}
+	if updateDryRun {
+		return runUpdateDryRun(cmd, os.Stdout, channel)
 	}
 
+	op, finish, err := beginOperation("update", updateOutput)
+	if err != nil {
+		return err
+	}
+	defer func() { err = finish(err) }()
+
+	if err := checkUpdateTarget(workDir(cmd)); err != nil {
+		return err
+	}
+	if err := guardTargetDir(cmd); err != nil {
+		return err
+	}
+
+	// Every change is staged, then listed and applied once confirmed. The
+	// steps below work in the staged copy, root.
+	stage, err := beginUpdateStage(workDir(cmd), op)
+	if err != nil {
+		return err
+	}
+	defer func() { err = stage.finish(os.Stdin, os.Stdout, op, err) }()
+	root := stage.area.Dir
+	configPath := projectConfig(root)
+
 	// Detect platform
 	platform, err := fs.DetectPlatform()
 	if err != nil {
@@ -46,137 +131,359 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 
 	// Fetch latest release
 	fmt.Println("Checking for updates...")
-	token := ghclient.ResolveToken(os.Getenv("GITHUB_TOKEN"))
-	client := ghclient.NewClient(githubOwner, githubRepo, token)
+	client, err := assetsClient(root, updateFrom)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	owner, repo, _ := assetsRepo(updateFrom, cfg)
+	custom := owner != githubOwner || repo != githubRepo
 
-	release, err := client.FetchLatestRelease()
+	// recordUpdate records a successful update, and the --from source so
+	// later updates keep using it
+	recordUpdate := func(source string) error {
+		if custom {
+			source = owner + "/" + repo + "@" + source
+		}
+		if err := config.RecordUpdate(configPath, config.LastUpdate{At: time.Now(), Command: "update", Source: source}); err != nil {
+			return fmt.Errorf("recording update time: %w", err)
+		}
+		if updateFrom == "" {
+			return nil
+		}
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		cfg.Source = ""
+		if custom {
+			cfg.Source = owner + "/" + repo
+		}
+		return config.Save(cfg, configPath)
+	}
+
+	// updateFromRef fetches .maestro/ from ref (the main branch, or the
+	// chosen release's tag) when there is no release asset to install, or
+	// on the nightly channel
+	updateFromRef := func(ref string) error {
+		fmt.Printf("Fetching .maestro/ from GitHub at %s...\n", ref)
+		content, err := fetchMaestroDir(client, ref)
+		if err != nil {
+			return op.Fail("assets", fmt.Errorf("updating from GitHub: %w", err))
+		}
+		if applied, err := mergeMaestroAssets(root, os.Stdin, os.Stdout, content, ref, op); err != nil || !applied {
+			if err == nil {
+				fmt.Println("Aborted.")
+			}
+			return err
+		}
+		if err := recordUpdate(ref); err != nil {
+			return op.Fail("install manifest", err)
+		}
+		op.OK("install manifest", ref)
+		refreshAgentsMDBlock(root, op)
+		if !updateAssetsOnly {
+			refreshAgentInstructions(root, op)
+		}
+		fmt.Printf("%s Updated .maestro/ from GitHub %s!\n", glyph.OK(), ref)
+		op.FollowUp("Run 'maestro doctor' to validate the setup")
+		return nil
+	}
+
+	if updateAgentsOnly {
+		return updateAgentsOnlyFrom(root, client, op)
+	}
+
+	if updateVersion == "" && channel == channelNightly {
+		fmt.Printf("Channel: %s\n", channel)
+		op.OK("check for updates", "nightly channel ("+nightlyRef+")")
+		return updateFromRef(nightlyRef)
+	}
+
+	var release *ghclient.Release
+	if updateVersion != "" {
+		release, err = client.FetchReleaseByTag(updateVersion)
+		if err != nil {
+			return op.Fail("check for updates", fmt.Errorf("fetching release %s: %w", updateVersion, err))
+		}
+	} else {
+		release, err = fetchChannelRelease(client, channel)
+	}
+	if err != nil && custom && strings.Contains(err.Error(), "resource not found") {
+		// Forks of the assets repository often publish no releases
+		fmt.Printf("Warning: %s/%s has no releases\n", owner, repo)
+		op.Warn("check for updates", fmt.Sprintf("%s/%s has no releases; fetched .maestro/ from main", owner, repo))
+		return updateFromRef("main")
+	}
 	if err != nil {
-		return fmt.Errorf("checking for updates: %w", err)
+		return op.Fail("check for updates", fmt.Errorf("checking for updates: %w", err))
 	}
 
 	current := version.Version
 	latest := release.TagName
-	fmt.Printf("Current version: %s\n", current)
-	fmt.Printf("Latest version:  %s\n", latest)
+	if updateVersion != "" {
+		// A chosen release is compared with the installed assets, which
+		// are what it replaces
+		current = installedAssetVersion(cfg)
+		fmt.Printf("Installed version: %s\n", current)
+		fmt.Printf("Target version:    %s\n", latest)
+		if ok, err := confirmDowngrade(os.Stdin, os.Stdout, current, latest); err != nil {
+			return op.Fail("check for updates", err)
+		} else if !ok {
+			fmt.Println("Aborted.")
+			op.Skip("assets", "downgrade to "+latest+" cancelled")
+			return nil
+		}
+	} else {
+		if channel != channelStable {
+			fmt.Printf("Channel: %s\n", channel)
+		}
+		fmt.Printf("Current version: %s\n", current)
+		fmt.Printf("Latest version:  %s\n", latest)
+	}
 
 	if current != "dev" && current == latest {
-		fmt.Println("✓ Already up to date!")
+		fmt.Printf("%s Already up to date!\n", glyph.OK())
+		op.OK("check for updates", "already up to date ("+current+")")
+		op.Skip("assets", "already up to date")
+		// Being current counts as updated, so doctor stops advising an update
+		if err := recordUpdate(latest); err != nil {
+			op.Warning("%v", err)
+		}
 		return nil
 	}
+	op.OK("check for updates", current+" "+glyph.Arrow()+" "+latest)
+	showChangelog(os.Stdout, client, release, installedAssetVersion(cfg), op)
 
 	fmt.Printf("Updating to %s...\n", latest)
 
 	// Find asset for platform
 	asset, err := release.FindAssetForPlatform(platform.AssetSuffix())
 	if err != nil {
-		// No release asset for this platform - fall back to fetching from GitHub main
+		// No release asset for this platform - fall back to fetching the
+		// release's tag, or main for the latest release
+		ref := agentSourceRef()
 		fmt.Printf("Warning: no release asset for platform %s\n", platform.String())
-		fmt.Println("Falling back to fetching .maestro/ from GitHub main branch...")
-		if err := updateFromGitHub(client); err != nil {
-			return fmt.Errorf("updating from GitHub: %w", err)
-		}
-		fmt.Printf("✓ Updated .maestro/ from GitHub main branch!\n")
-		return nil
+		op.Warn("assets", "no release asset for "+platform.String()+"; fetched .maestro/ from GitHub "+ref)
+		return updateFromRef(ref)
 	}
 
 	// Download and extract to .maestro/
 	cache, err := assets.NewCacheManager()
 	if err != nil {
-		return fmt.Errorf("initializing cache: %w", err)
+		return op.Fail("assets", fmt.Errorf("initializing cache: %w", err))
 	}
-	// Invalidate cache to force fresh download
-	if err := cache.Invalidate(asset.DownloadURL); err != nil {
-		return fmt.Errorf("invalidating cache: %w", err)
+	evictStaleCache(root, cache, op)
+	cachedPath, err := cache.GetRelease(asset.DownloadURL, latest, freshDownloadAge)
+	if err != nil {
+		return op.Fail("assets", fmt.Errorf("downloading update: %w", err))
 	}
 
-	cachedPath, err := cache.Get(asset.DownloadURL, 0)
+	content, err := assets.ReadAsset(cachedPath)
 	if err != nil {
-		return fmt.Errorf("downloading update: %w", err)
+		return op.Fail("assets", fmt.Errorf("reading update: %w", err))
 	}
-
-	if err := assets.ExtractAsset(cachedPath, ".maestro"); err != nil {
-		return fmt.Errorf("extracting update: %w", err)
+	if applied, err := mergeMaestroAssets(root, os.Stdin, os.Stdout, content, latest, op); err != nil || !applied {
+		if err == nil {
+			fmt.Println("Aborted.")
+		}
+		return err
 	}
 
 	// Update config with new version
-	if err := config.UpdateCLIVersion(".maestro/config.yaml", latest); err != nil {
-		return fmt.Errorf("updating config version: %w", err)
+	if err := config.UpdateCLIVersion(configPath, latest); err != nil {
+		return op.Fail("config version", fmt.Errorf("updating config version: %w", err))
+	}
+	op.OK("config version", latest)
+	if err := recordUpdate(latest); err != nil {
+		return op.Fail("install manifest", err)
+	}
+	op.OK("install manifest", latest)
+	refreshAgentsMDBlock(root, op)
+	if updateAssetsOnly {
+		fmt.Printf("%s Updated to %s successfully!\n", glyph.OK(), latest)
+		op.Skip("refresh agent configs", "--assets-only")
+		op.FollowUp("Run 'maestro doctor' to validate the setup")
+		return nil
 	}
+	refreshAgentInstructions(root, op)
 
-	fmt.Printf("✓ Updated to %s successfully!\n", latest)
-	fmt.Println("Note: Custom modifications in .maestro/ have been preserved.")
+	fmt.Printf("%s Updated to %s successfully!\n", glyph.OK(), latest)
 
-	// Update agent configurations
-	if err := updateAgentConfigs(client); err != nil {
-		return fmt.Errorf("updating agent configs: %w", err)
+	// Agent configurations are optional: their failures are reported in the
+	// summary instead of failing an update whose assets are already applied
+	updateAgentConfigs(root, client, op)
+	if failed := op.Counts().OptionalFailed; failed > 0 {
+		fmt.Printf("%s %d optional step(s) failed; see the summary for how to retry them\n", glyph.Warn(), failed)
 	}
 
+	op.FollowUp("Run 'maestro doctor' to validate the setup")
 	return nil
 }
 
+// updateAgentsOnlyFrom refreshes the installed agent directories, and
+// offers the missing ones, without updating .maestro/. As refreshing them
+// is all it does, a failed directory fails the update. root is the project.
+func updateAgentsOnlyFrom(root string, client *ghclient.Client, op *report.Operation) error {
+	fmt.Printf("Refreshing agent configurations from GitHub %s...\n", agentSourceRef())
+	op.Skip("assets", "--agents-only")
+	updateAgentConfigs(root, client, op)
+	refreshAgentInstructions(root, op)
+	if failed := op.Counts().OptionalFailed; failed > 0 {
+		return fmt.Errorf("%d agent configuration step(s) failed; see the summary for how to retry them", failed)
+	}
+	op.FollowUp("Run 'maestro doctor' to validate the setup")
+	return nil
+}
+
+// agentRetryHint tells the user how to retry installing dir after an
+// optional update step failed.
+func agentRetryHint(dir string) string {
+	return fmt.Sprintf("Retry %s with 'maestro init --with-%s' (choose merge to keep .maestro/ as it is)", dir, strings.TrimPrefix(dir, "."))
+}
+
+// failAgentDirs records an optional failure for each of dirs.
+func failAgentDirs(op *report.Operation, verb string, dirs []string, err error) {
+	for _, dir := range dirs {
+		op.FailOptional(verb+" "+dir, err, agentRetryHint(dir))
+	}
+}
+
+// checkUpdateTarget verifies root is a maestro project update may write to.
+func checkUpdateTarget(root string) error {
+	if _, err := os.Stat(filepath.Join(root, ".maestro")); os.IsNotExist(err) {
+		return fmt.Errorf("not initialized — run 'maestro init' first")
+	}
+	return guardAssetsRepo(root, "maestro update", updateForceSelf)
+}
+
 // refreshInstalledAgentDirs refreshes existing agent directories from GitHub.
-func refreshInstalledAgentDirs(client *ghclient.Client, installed []string) error {
+// Each directory is an optional step: one that fails is recorded with a
+// retry hint and the others are still refreshed. root is the project.
+func refreshInstalledAgentDirs(root string, client *ghclient.Client, installed []string, op *report.Operation) {
 	if len(installed) == 0 {
-		return nil
+		op.Skip("refresh agent configs", "none installed")
+		return
 	}
 
 	fmt.Println("\nRefreshing installed agent configurations...")
 
 	// Handle conflicts for all installed dirs
-	action, conflicting, err := handleAgentConflicts(installed)
+	actions, conflicting, err := handleAgentConflicts(root, installed)
 	if err != nil {
-		return err
+		failAgentDirs(op, "refresh", installed, err)
+		return
+	}
+
+	// Apply conflict resolution. Backups are made one directory at a time so
+	// a directory that could not be backed up is left alone while the others
+	// are refreshed. Directories whose action is cancel are left alone too.
+	var overwritten, skipped, cancelled []string
+	for _, dir := range conflicting {
+		switch actions[dir] {
+		case agents.ConflictOverwrite:
+			overwritten = append(overwritten, dir)
+		case agents.ConflictBackup:
+			if err := applyConflictAction(root, agents.ConflictBackup, []string{dir}); err != nil {
+				failAgentDirs(op, "refresh", []string{dir}, err)
+				skipped = append(skipped, dir)
+			}
+		case agents.ConflictCancel:
+			cancelled = append(cancelled, dir)
+		}
 	}
 
-	// Apply conflict resolution
-	if err := applyConflictAction(action, conflicting); err != nil {
-		return err
+	if len(overwritten) > 0 {
+		applyConflictAction(root, agents.ConflictOverwrite, overwritten)
 	}
 
-	// If user chose cancel, stop here
-	if action == agents.ConflictCancel {
+	// If every directory was cancelled, stop here
+	if len(cancelled) > 0 && len(cancelled) == len(conflicting) {
 		fmt.Println("Agent refresh cancelled.")
-		return nil
+		op.Skip("refresh agent configs", "cancelled")
+		return
+	}
+	if len(cancelled) > 0 {
+		op.Skip("refresh "+strings.Join(cancelled, ", "), "cancelled")
 	}
+	refresh := subtract(installed, append(skipped, cancelled...))
 
 	// Fetch and install the installed directories (refresh them)
-	if err := fetchAndInstallAgentDirs(client, installed); err != nil {
-		return err
+	refreshed := installAgentDirsOptional(root, client, refresh, "refresh", op)
+	if len(refreshed) > 0 {
+		fmt.Printf("%s Refreshed %d agent configuration(s)\n", glyph.OK(), len(refreshed))
+		op.OK("refresh agent configs", strings.Join(refreshed, ", "))
 	}
+}
 
-	fmt.Printf("✓ Refreshed %d agent configuration(s)\n", len(installed))
-	return nil
+// installAgentDirsOptional installs dirs, recording an optional failure for
+// each one that fails, and returns the ones that were installed.
+func installAgentDirsOptional(root string, client *ghclient.Client, dirs []string, verb string, op *report.Operation) []string {
+	if len(dirs) == 0 {
+		return nil
+	}
+	failed, err := fetchAndInstallAgentDirs(root, client, dirs)
+	if err != nil {
+		failAgentDirs(op, verb, dirs, err)
+		return nil
+	}
+
+	var installed []string
+	for _, dir := range dirs {
+		if err, ok := failed[dir]; ok {
+			fmt.Printf("%s Could not %s %s: %v\n", glyph.Warn(), verb, dir, err)
+			failAgentDirs(op, verb, []string{dir}, err)
+			continue
+		}
+		installed = append(installed, dir)
+	}
+	return installed
 }
 
-// promptInstallMissingAgentDirs prompts user to install missing agent directories.
-func promptInstallMissingAgentDirs(client *ghclient.Client, missing []string) error {
+// promptInstallMissingAgentDirs prompts user to install missing agent
+// directories. Like refreshing, installing each one is an optional step.
+func promptInstallMissingAgentDirs(root string, client *ghclient.Client, missing []string, op *report.Operation) {
 	if len(missing) == 0 {
-		return nil
+		op.Skip("install new agent configs", "none available")
+		return
 	}
 
 	fmt.Println("\nThe following agent configurations are available but not installed:")
-	selected, err := agents.PromptAgentSelection(os.Stdin, os.Stdout, missing)
+	selected, err := promptAgentSelection(os.Stdin, os.Stdout, missing, nil)
 	if err != nil {
-		return fmt.Errorf("selecting agent directories: %w", err)
+		op.FailOptional("install new agent configs", fmt.Errorf("selecting agent directories: %w", err), "Install agent configurations with 'maestro init --with-<agent>'")
+		return
+	}
+
+	if !nonInteractive && !unattended {
+		if err := config.DeclineAgentDirs(projectConfig(root), subtract(missing, selected)); err != nil {
+			// Not recording the choice only means it is offered again
+			op.Warning("recording declined agent directories: %v", err)
+		}
 	}
 
 	if len(selected) == 0 {
-		return nil
+		op.Skip("install new agent configs", "none selected")
+		return
 	}
 
 	// No conflict handling needed since these directories don't exist yet
-	if err := fetchAndInstallAgentDirs(client, selected); err != nil {
-		return err
+	installed := installAgentDirsOptional(root, client, selected, "install", op)
+	if len(installed) > 0 {
+		fmt.Printf("%s Installed %d additional agent configuration(s)\n", glyph.OK(), len(installed))
+		op.OK("install new agent configs", strings.Join(installed, ", "))
 	}
-
-	fmt.Printf("✓ Installed %d additional agent configuration(s)\n", len(selected))
-	return nil
 }
 
 // updateAgentConfigs orchestrates the agent configuration update process.
-func updateAgentConfigs(client *ghclient.Client) error {
+// Its steps are optional, so failures are only recorded in op. root is the
+// project.
+func updateAgentConfigs(root string, client *ghclient.Client, op *report.Operation) {
 	// Detect which agent directories are currently installed
-	installed := agents.DetectInstalled(".")
+	installed := agents.DetectInstalled(root)
 
 	// Determine which known agent directories are missing
 	known := agents.KnownAgentDirs()
@@ -185,141 +492,401 @@ func updateAgentConfigs(client *ghclient.Client) error {
 		installedSet[dir] = true
 	}
 
-	var missing []string
+	cfg, err := config.Load(projectConfig(root))
+	if err != nil {
+		failAgentDirs(op, "refresh", installed, fmt.Errorf("loading config: %w", err))
+		return
+	}
+
+	var missing, declined []string
 	for _, dir := range known {
-		if !installedSet[dir] {
+		switch {
+		case installedSet[dir]:
+		case cfg.Installed.IsDeclined(dir):
+			declined = append(declined, dir)
+		default:
 			missing = append(missing, dir)
 		}
 	}
+	if len(declined) > 0 {
+		fmt.Printf("Not offering previously declined agent configurations: %s (install with 'maestro init --with-<agent>')\n", strings.Join(declined, ", "))
+		op.FollowUp("Install declined agent configurations with 'maestro init --with-<agent>': %s", strings.Join(declined, ", "))
+	}
 
 	// Refresh installed agent directories
-	if err := refreshInstalledAgentDirs(client, installed); err != nil {
-		return err
-	}
+	refreshInstalledAgentDirs(root, client, installed, op)
 
 	// Prompt to install missing agent directories
-	if err := promptInstallMissingAgentDirs(client, missing); err != nil {
-		return err
-	}
-
-	return nil
+	promptInstallMissingAgentDirs(root, client, missing, op)
 }
 
-// handleAgentConflicts checks for existing agent directories and prompts for resolution.
-func handleAgentConflicts(selected []string) (agents.ConflictAction, []string, error) {
+// handleAgentConflicts checks for existing agent directories and prompts for
+// resolution. It returns the action for each existing directory. selected
+// are agent directories of the project at root.
+func handleAgentConflicts(root string, selected []string) (map[string]agents.ConflictAction, []string, error) {
 	if len(selected) == 0 {
-		return agents.ConflictCancel, nil, nil
+		return nil, nil, nil
 	}
 
 	// Detect which selected directories already exist
 	conflicting := []string{}
 	for _, dir := range selected {
-		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		if info, err := os.Stat(filepath.Join(root, dir)); err == nil && info.IsDir() {
 			conflicting = append(conflicting, dir)
 		}
 	}
 
 	if len(conflicting) == 0 {
-		return agents.ConflictOverwrite, nil, nil
+		return nil, nil, nil
 	}
 
 	// Prompt for conflict resolution
-	action, err := agents.PromptConflictResolution(os.Stdin, os.Stdout, conflicting)
+	actions, err := promptConflict(root, os.Stdin, os.Stdout, conflictClassAgents, conflicting)
 	if err != nil {
-		return agents.ConflictCancel, nil, fmt.Errorf("prompting for conflict resolution: %w", err)
+		return nil, nil, fmt.Errorf("prompting for conflict resolution: %w", err)
 	}
 
-	return action, conflicting, nil
+	return actions, conflicting, nil
 }
 
-// applyConflictAction applies the chosen conflict action to conflicting directories.
-func applyConflictAction(action agents.ConflictAction, conflicting []string) error {
+// applyConflictActions applies each directory's conflict action and returns
+// the directories left alone because their action is cancel.
+func applyConflictActions(root string, actions map[string]agents.ConflictAction, conflicting []string) ([]string, error) {
+	var cancelled []string
+	for _, action := range []agents.ConflictAction{agents.ConflictOverwrite, agents.ConflictBackup, agents.ConflictCancel} {
+		var dirs []string
+		for _, dir := range conflicting {
+			if actions[dir] == action {
+				dirs = append(dirs, dir)
+			}
+		}
+		if len(dirs) == 0 {
+			continue
+		}
+		if err := applyConflictAction(root, action, dirs); err != nil {
+			return nil, err
+		}
+		if action == agents.ConflictCancel {
+			cancelled = dirs
+		}
+	}
+	return cancelled, nil
+}
+
+// applyConflictAction applies the chosen conflict action to conflicting
+// directories of the project at root.
+func applyConflictAction(root string, action agents.ConflictAction, conflicting []string) error {
 	switch action {
 	case agents.ConflictOverwrite:
 		fmt.Println("Overwriting existing agent directories...")
 		return nil
 	case agents.ConflictBackup:
 		for _, dir := range conflicting {
-			backupPath, err := agents.BackupDir(dir)
+			backupPath, err := agents.BackupDir(filepath.Join(root, dir))
 			if err != nil {
 				return fmt.Errorf("backing up %s: %w", dir, err)
 			}
-			fmt.Printf("Backup created: %s\n", backupPath)
+			fmt.Printf("Backup created: %s\n", pathfmt.Path(backupPath))
+			if err := restoreIgnored(root, backupPath, dir); err != nil {
+				return fmt.Errorf("restoring ignored files of %s: %w", dir, err)
+			}
 		}
 		return nil
 	case agents.ConflictCancel:
-		fmt.Println("Aborted.")
+		// Other directories may still be updated, so this is not an abort
+		for _, dir := range conflicting {
+			fmt.Printf("Leaving %s unchanged\n", dir)
+		}
 		return nil
 	default:
 		return fmt.Errorf("unknown conflict action: %v", action)
 	}
 }
 
+// restoreIgnored copies the files of dir that .maestro/.maestroignore lists
+// back from its backup, so backing dir up leaves them in place. dir is
+// relative to root, the project.
+func restoreIgnored(root, backupPath, dir string) error {
+	ignored, err := ignore.Load(root)
+	if err != nil || ignored.Empty() {
+		return err
+	}
+	return filepath.WalkDir(backupPath, func(p string, d os.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(backupPath, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(root, dir, rel)
+		if !ignored.MatchFile(target) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, info.Mode().Perm())
+	})
+}
+
 // fetchAndInstallAgentDirs fetches agent directories from GitHub and installs them.
-func fetchAndInstallAgentDirs(client *ghclient.Client, selected []string) error {
+// Directories installed from a known upstream commit are refreshed incrementally:
+// only files changed between the recorded commit and the current one are fetched.
+// A directory that fails does not stop the others; its error is returned in
+// failed, keyed by directory. root is the project.
+func fetchAndInstallAgentDirs(root string, client *ghclient.Client, selected []string) (failed map[string]error, err error) {
+	failed = make(map[string]error)
 	if len(selected) == 0 {
-		return nil
+		return failed, nil
 	}
 
+	cfg, err := config.Load(projectConfig(root))
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	ref, headSHA := resolveAgentSourceCommit(client, agentSourceRef())
+
 	for _, dir := range selected {
+		if err := fetchAndInstallAgentDir(root, client, cfg, dir, ref, headSHA); err != nil {
+			failed[dir] = err
+		}
+	}
+	return failed, nil
+}
+
+// fetchAndInstallAgentDir installs one agent directory, incrementally when
+// it was installed from the commit recorded in cfg, and records where it
+// came from. A full fetch skips the files whose recorded blob SHA is still
+// upstream and which are as they were installed.
+func fetchAndInstallAgentDir(root string, client *ghclient.Client, cfg *config.ProjectConfig, dir, ref, headSHA string) error {
+	configPath := projectConfig(root)
+	record := cfg.Installed.AgentDirs[dir]
+	refreshed, changes, err := refreshAgentDirIncrementally(root, client, dir, record.Commit, headSHA)
+	if err != nil {
+		fmt.Printf("Incremental refresh of %s failed (%v); fetching full directory...\n", dir, err)
+	}
+
+	var blobs map[string]string
+	if refreshed {
+		blobs = make(map[string]string, len(record.Blobs))
+		for rel, sha := range record.Blobs {
+			blobs[rel] = sha
+		}
+		if changes != nil {
+			for _, rel := range changes.Removed {
+				delete(blobs, rel)
+			}
+			for rel, sha := range changes.Blobs {
+				blobs[rel] = sha
+			}
+		}
+	} else {
 		fmt.Printf("Fetching %s from GitHub...\n", dir)
 
 		// Fetch the directory content from GitHub (default branch fallback)
-		content, err := fetchAgentDirWithRefFallback(client, dir, "main")
+		fetched, err := fetchAgentDirSinceWithRefFallback(client, dir, agentSourceRef(), unchangedAgentBlobs(root, cfg, dir))
 		if err != nil {
 			return fmt.Errorf("fetching %s: %w", dir, err)
 		}
+		content := fetched.Files
 		if dir == ".codex" {
 			content = agents.AddCodexCommandSkills(content)
 		}
 
 		// Write the content to the project root
-		if err := agents.WriteAgentDir(content, dir); err != nil {
-			return fmt.Errorf("writing %s: %w", dir, err)
+		if len(content) > 0 {
+			if err := agents.WriteAgentDir(root, content, dir); err != nil {
+				return fmt.Errorf("writing %s: %w", dir, err)
+			}
 		}
 
-		fmt.Printf("✓ Installed %s\n", dir)
+		if unchanged := len(fetched.Blobs) - len(fetched.Files); fetched.Blobs != nil && unchanged > 0 {
+			fmt.Printf("%s Installed %s (%d unchanged file(s) skipped)\n", glyph.OK(), dir, unchanged)
+		} else {
+			fmt.Printf("%s Installed %s\n", glyph.OK(), dir)
+		}
+		blobs = fetched.Blobs
 	}
 
+	// An ignored file keeps its local content, so its upstream blob is not
+	// recorded
+	if ignored, err := ignore.Load(root); err == nil {
+		for rel := range blobs {
+			if ignored.Match(path.Join(dir, rel)) {
+				delete(blobs, rel)
+			}
+		}
+	}
+
+	if headSHA != "" {
+		if err := config.RecordAgentDir(configPath, dir, ref, headSHA); err != nil {
+			return fmt.Errorf("recording %s source commit: %w", dir, err)
+		}
+	}
+	if err := config.RecordAgentDirBlobs(configPath, dir, blobs); err != nil {
+		return fmt.Errorf("recording %s blob SHAs: %w", dir, err)
+	}
+	if err := config.RecordInstall(configPath, "", []string{dir}); err != nil {
+		return fmt.Errorf("recording %s checksums: %w", dir, err)
+	}
+	if _, err := recordAgentCommands(root, []string{dir}); err != nil {
+		return fmt.Errorf("recording agent commands: %w", err)
+	}
+	if err := config.RecordAgentDirUpdate(configPath, dir, time.Now()); err != nil {
+		return fmt.Errorf("recording %s update time: %w", dir, err)
+	}
 	return nil
 }
 
-// updateFromGitHub fetches the .maestro/ directory directly from GitHub main branch
-// when no release asset is available for the current platform.
-func updateFromGitHub(client *ghclient.Client) error {
-	fmt.Println("Fetching .maestro/ directory from GitHub main branch...")
+// unchangedAgentBlobs returns the recorded blob SHAs of the files in dir
+// that are as they were installed, keyed by path relative to dir. A file
+// that was edited or removed since is left out, so a refresh downloads it
+// again. For .codex, a command whose generated skill changed is left out
+// too, so the skill is generated again. root is the project.
+func unchangedAgentBlobs(root string, cfg *config.ProjectConfig, dir string) map[string]string {
+	recorded := cfg.Installed.AgentDirs[dir].Blobs
+	if len(recorded) == 0 {
+		return nil
+	}
+	sums, err := config.ChecksumFiles(root, []string{dir})
+	if err != nil {
+		return nil
+	}
+	unchanged := func(rel string) bool {
+		file := path.Join(dir, rel)
+		return sums[file] != "" && sums[file] == cfg.Installed.Files[file]
+	}
 
-	// Fetch the entire .maestro directory
-	content, err := client.FetchAgentDir(".maestro", "main")
+	known := make(map[string]string, len(recorded))
+	for rel, sha := range recorded {
+		if !unchanged(rel) {
+			continue
+		}
+		if skill, ok := agents.CodexCommandSkillPath(rel); ok && dir == ".codex" && !unchanged(skill) {
+			continue
+		}
+		known[rel] = sha
+	}
+	return known
+}
+
+// resolveAgentSourceCommit resolves the commit agent directories are fetched
+// from, trying master when main does not exist. It returns empty strings when
+// the commit cannot be resolved (e.g. rate limited), which disables
+// incremental refresh without failing the update.
+func resolveAgentSourceCommit(client *ghclient.Client, primaryRef string) (string, string) {
+	refs := []string{primaryRef}
+	if primaryRef == "main" {
+		refs = append(refs, "master")
+	}
+
+	for _, ref := range refs {
+		sha, err := client.FetchCommitSHA(ref)
+		if err == nil {
+			return ref, sha
+		}
+	}
+	return "", ""
+}
+
+// refreshAgentDirIncrementally applies only the upstream changes between
+// baseSHA and headSHA to an installed agent directory. It returns false when
+// an incremental refresh is not possible and the full directory must be fetched.
+// dir is relative to root, the project.
+func refreshAgentDirIncrementally(root string, client *ghclient.Client, dir, baseSHA, headSHA string) (bool, *ghclient.AgentDirChanges, error) {
+	if baseSHA == "" || headSHA == "" {
+		return false, nil, nil
+	}
+	if info, err := os.Stat(filepath.Join(root, dir)); err != nil || !info.IsDir() {
+		return false, nil, nil
+	}
+
+	if baseSHA == headSHA {
+		fmt.Printf("%s %s is already up to date (%s)\n", glyph.OK(), dir, shortSHA(headSHA))
+		return true, nil, nil
+	}
+
+	changes, err := client.FetchAgentDirChanges(dir, baseSHA, headSHA)
 	if err != nil {
-		return fmt.Errorf("fetching .maestro directory: %w", err)
+		return false, nil, err
 	}
 
-	// Write each file to the .maestro/ directory
-	for filePath, fileContent := range content {
-		fullPath := filePath
-		// Ensure we're writing to .maestro/ directory
-		if !strings.HasPrefix(fullPath, ".maestro/") {
-			fullPath = ".maestro/" + fullPath
+	removed := changes.Removed
+	if dir == ".codex" {
+		for _, rel := range changes.Removed {
+			if skillPath, ok := agents.CodexCommandSkillPath(rel); ok {
+				removed = append(removed, skillPath)
+			}
 		}
+		changes.Changed = agents.AddCodexCommandSkills(changes.Changed)
+	}
 
-		// Create parent directories if needed
-		parentDir := path.Dir(fullPath)
-		if err := os.MkdirAll(parentDir, 0755); err != nil {
-			return fmt.Errorf("creating directory %s: %w", parentDir, err)
+	if len(changes.Changed) > 0 {
+		if err := agents.WriteAgentDir(root, changes.Changed, dir); err != nil {
+			return false, nil, fmt.Errorf("writing %s: %w", dir, err)
 		}
+	}
+	if err := agents.RemoveAgentFiles(root, dir, removed); err != nil {
+		return false, nil, fmt.Errorf("removing stale files from %s: %w", dir, err)
+	}
 
-		// Write the file
-		if err := os.WriteFile(fullPath, fileContent, 0644); err != nil {
-			return fmt.Errorf("writing %s: %w", fullPath, err)
+	fmt.Printf("%s Refreshed %s %s..%s (%d changed, %d removed)\n", glyph.OK(), dir, shortSHA(baseSHA), shortSHA(headSHA), len(changes.Changed), len(removed))
+	return true, changes, nil
+}
+
+// subtract returns the entries of list not in remove.
+func subtract(list, remove []string) []string {
+	drop := make(map[string]bool, len(remove))
+	for _, v := range remove {
+		drop[v] = true
+	}
+	var out []string
+	for _, v := range list {
+		if !drop[v] {
+			out = append(out, v)
 		}
 	}
+	return out
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
 
-	fmt.Printf("✓ Updated %d files from GitHub\n", len(content))
-	return nil
+// fetchMaestroDir fetches the .maestro/ directory from GitHub at ref, for
+// when no release asset is available for the current platform.
+func fetchMaestroDir(client *ghclient.Client, ref string) (map[string][]byte, error) {
+	fmt.Printf("Fetching .maestro/ directory from GitHub at %s...\n", ref)
+	content, err := client.FetchAgentDir(".maestro", ref)
+	if err != nil {
+		return nil, fmt.Errorf("fetching .maestro directory: %w", err)
+	}
+	return content, nil
 }
 
 func fetchAgentDirWithRefFallback(client *ghclient.Client, dir string, primaryRef string) (map[string][]byte, error) {
+	fetched, err := fetchAgentDirSinceWithRefFallback(client, dir, primaryRef, nil)
+	if err != nil {
+		return nil, err
+	}
+	return fetched.Files, nil
+}
+
+// fetchAgentDirSinceWithRefFallback is fetchAgentDirWithRefFallback that
+// skips downloading the files whose blob SHA is in known.
+func fetchAgentDirSinceWithRefFallback(client *ghclient.Client, dir, primaryRef string, known map[string]string) (*ghclient.AgentDirFiles, error) {
 	refs := []string{primaryRef}
 	if primaryRef == "main" {
 		refs = append(refs, "master")
@@ -327,9 +894,9 @@ func fetchAgentDirWithRefFallback(client *ghclient.Client, dir string, primaryRe
 
 	var lastErr error
 	for _, ref := range refs {
-		content, err := client.FetchAgentDir(dir, ref)
+		fetched, err := client.FetchAgentDirSince(dir, ref, known)
 		if err == nil {
-			return content, nil
+			return fetched, nil
 		}
 
 		lastErr = err
@@ -346,3 +913,33 @@ func fetchAgentDirWithRefFallback(client *ghclient.Client, dir string, primaryRe
 
 	return nil, fmt.Errorf("tried refs %v: %w", refs, lastErr)
 }
+
+// agentSourceRef is the ref update fetches agent directories from, and
+// .maestro/ when a release has no asset for the platform: the release
+// chosen with --version, or the main branch.
+func agentSourceRef() string {
+	if updateVersion != "" {
+		return updateVersion
+	}
+	return "main"
+}
+
+// installedAssetVersion returns the version of the project's assets as
+// recorded in config.yaml, or "" when none is.
+func installedAssetVersion(cfg *config.ProjectConfig) string {
+	if cfg.Installed.AssetVersion != "" {
+		return cfg.Installed.AssetVersion
+	}
+	return cfg.CLIVersion
+}
+
+// confirmDowngrade asks before replacing assets at installed with the
+// older release target. Versions that aren't releases, such as a branch
+// name, can't be ordered and are not asked about.
+func confirmDowngrade(r io.Reader, w io.Writer, installed, target string) (bool, error) {
+	older, err := version.Less(target, installed)
+	if err != nil || !older {
+		return true, nil
+	}
+	return confirm(r, w, fmt.Sprintf("%s is older than the installed %s. Downgrade .maestro/ assets?", target, installed))
+}
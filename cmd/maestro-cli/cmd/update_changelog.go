@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spec-maestro/maestro-cli/pkg/changelog"
+	ghclient "github.com/spec-maestro/maestro-cli/pkg/github"
+	"github.com/spec-maestro/maestro-cli/pkg/report"
+)
+
+// changelogSinceInstalled is the value of a bare --changelog-since: the
+// notes since the installed version.
+const changelogSinceInstalled = "installed"
+
+// changelogMaxPages bounds how many pages of releases --changelog-since
+// reads, 100 releases each.
+const changelogMaxPages = 5
+
+// changelogEntry turns a release into a changelog entry.
+func changelogEntry(r ghclient.Release) changelog.Entry {
+	return changelog.Entry{Version: r.TagName, Date: r.PublishedAt, Body: r.Body, Prerelease: r.Prerelease}
+}
+
+// showChangelog prints the target release's notes, or with
+// --changelog-since the notes of every release after that version up to
+// the target. When the releases can't be listed or ordered it says why in
+// op and falls back to the target's notes.
+func showChangelog(w io.Writer, client *ghclient.Client, target *ghclient.Release, installed string, op *report.Operation) {
+	entries := []changelog.Entry{changelogEntry(*target)}
+	since := updateChangelogSince
+	if since == changelogSinceInstalled {
+		since = installed
+	}
+
+	if since != "" {
+		if picked, err := changelogSince(client, since, target.TagName); err != nil {
+			op.Warning("showing only the notes of %s: %v", target.TagName, err)
+		} else if len(picked) > 0 {
+			entries = picked
+		}
+	}
+
+	if len(entries) == 1 {
+		fmt.Fprintf(w, "\nRelease notes for %s:\n\n", target.TagName)
+	} else {
+		fmt.Fprintf(w, "\nRelease notes since %s (%d releases):\n\n", since, len(entries))
+	}
+	changelog.Write(w, entries)
+	fmt.Fprintln(w)
+}
+
+// changelogSince returns the notes of the releases after since up to and
+// including target, newest first.
+func changelogSince(client *ghclient.Client, since, target string) ([]changelog.Entry, error) {
+	releases, err := client.FetchReleases(changelogMaxPages)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]changelog.Entry, 0, len(releases))
+	for _, r := range releases {
+		entries = append(entries, changelogEntry(r))
+	}
+	picked, err := changelog.Between(entries, since, target)
+	if err != nil {
+		return nil, fmt.Errorf("can't list releases since %s: %w", since, err)
+	}
+	return picked, nil
+}
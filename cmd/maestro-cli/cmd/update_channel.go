@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	ghclient "github.com/spec-maestro/maestro-cli/pkg/github"
+)
+
+// Update channels: what update moves a project to when no --version is
+// given.
+const (
+	// channelStable is the latest release, as GitHub marks it.
+	channelStable = "stable"
+	// channelPrerelease is the newest release, prereleases included.
+	channelPrerelease = "prerelease"
+	// channelNightly is the head of nightlyRef, fetched from GitHub.
+	channelNightly = "nightly"
+)
+
+// nightlyRef is the branch the nightly channel fetches .maestro/ from.
+const nightlyRef = "main"
+
+const updateChannelUsage = "Release channel to update from: stable, prerelease (newest release, prereleases included), or nightly (the head of " + nightlyRef + ") (default: the channel setting, else stable)"
+
+// updateChannel returns the channel chosen with --channel, else the
+// channel setting, else stable.
+func updateChannel(cmd *cobra.Command) (string, error) {
+	channel := updateChannelFlag
+	if !cmd.Flags().Changed("channel") {
+		var err error
+		if channel, err = projectResolver(workDir(cmd)).String("channel", channelStable); err != nil {
+			return "", fmt.Errorf("reading the channel setting: %w", err)
+		}
+	} else if updateVersion != "" {
+		return "", fmt.Errorf("--version picks a release and cannot be combined with --channel")
+	}
+	switch channel = strings.ToLower(strings.TrimSpace(channel)); channel {
+	case channelStable, channelPrerelease, channelNightly:
+		return channel, nil
+	}
+	return "", fmt.Errorf("invalid channel %q (use stable, prerelease, or nightly)", channel)
+}
+
+// fetchChannelRelease fetches the release update moves to on channel. The
+// nightly channel follows a branch rather than releases; for it, the
+// newest release stands in where one is needed, as for the CLI.
+func fetchChannelRelease(client *ghclient.Client, channel string) (*ghclient.Release, error) {
+	if channel == channelStable {
+		return client.FetchLatestRelease()
+	}
+	return client.FetchNewestRelease()
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/internal/version"
+	"github.com/spec-maestro/maestro-cli/pkg/agents"
+	"github.com/spec-maestro/maestro-cli/pkg/config"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/output"
+)
+
+// exitUpdatesAvailable is the exit code of update --check when the CLI,
+// assets, or an agent directory is behind, so CI can tell it from failures.
+const exitUpdatesAvailable = 10
+
+// updateCheckItem is one thing update --check compares with upstream.
+type updateCheckItem struct {
+	Name      string `json:"name"`
+	Current   string `json:"current"`
+	Latest    string `json:"latest"`
+	Available bool   `json:"update_available"`
+	Note      string `json:"note,omitempty"`
+}
+
+// runUpdateCheck reports what update would change without changing
+// anything, and exits with exitUpdatesAvailable when something would. The
+// CLI and assets are compared with the release update moves to on
+// channel. --agents-only and --assets-only limit it to what update would
+// change with them.
+func runUpdateCheck(cmd *cobra.Command, w io.Writer, channel string) error {
+	root := workDir(cmd)
+	if err := checkUpdateTarget(root); err != nil {
+		return err
+	}
+	if err := validateOutputFormat(updateOutput); err != nil {
+		return err
+	}
+
+	client, err := assetsClient(root, updateFrom)
+	if err != nil {
+		return err
+	}
+	var latest string
+	if !updateAgentsOnly {
+		release, err := fetchChannelRelease(client, channel)
+		if err != nil {
+			return fmt.Errorf("checking for updates: %w", err)
+		}
+		latest = release.TagName
+	}
+	cfg, err := config.Load(projectConfig(root))
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	var installed []string
+	if !updateAssetsOnly {
+		installed = agents.DetectInstalled(root)
+	}
+	var ref, head string
+	if len(installed) > 0 {
+		ref, head = resolveAgentSourceCommit(client, "main")
+	}
+
+	items := updateCheckItems(version.Version, cfg, latest, installed, ref, head)
+	if updateAgentsOnly {
+		// The CLI and assets rows come first
+		items = items[2:]
+	}
+	if err := writeUpdateCheck(w, updateOutput, items); err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item.Available {
+			// The report already says what is out of date
+			cmd.SilenceErrors, cmd.SilenceUsage = true, true
+			return &exitError{code: exitUpdatesAvailable}
+		}
+	}
+	return nil
+}
+
+// updateCheckItems compares the CLI and the project's assets with the
+// latest release, and each installed agent directory with the commit
+// update would fetch it from (agentRef at agentHead, empty when that could
+// not be resolved). A development build of the CLI is never reported as
+// out of date.
+func updateCheckItems(cliVersion string, cfg *config.ProjectConfig, latest string, installed []string, agentRef, agentHead string) []updateCheckItem {
+	cli := updateCheckItem{Name: "CLI", Current: cliVersion, Latest: latest}
+	if cliVersion == "dev" {
+		cli.Note = "development build"
+	} else {
+		cli.Available = cliVersion != latest
+	}
+
+	assetVersion := installedAssetVersion(cfg)
+	items := []updateCheckItem{cli, {Name: "assets", Current: assetVersion, Latest: latest, Available: assetVersion != latest}}
+	if assetVersion == "" {
+		items[1].Current = "unknown"
+	}
+
+	for _, dir := range installed {
+		item := updateCheckItem{Name: dir, Current: "unknown", Latest: "unknown"}
+		if commit := cfg.Installed.AgentDirs[dir].Commit; commit != "" {
+			item.Current = shortSHA(commit)
+		}
+		if agentHead == "" {
+			item.Note = "could not resolve the upstream branch"
+		} else {
+			item.Latest = agentRef + "@" + shortSHA(agentHead)
+			item.Available = cfg.Installed.AgentDirs[dir].Commit != agentHead
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// writeUpdateCheck renders the update --check report in format.
+func writeUpdateCheck(w io.Writer, format string, items []updateCheckItem) error {
+	available := 0
+	for _, item := range items {
+		if item.Available {
+			available++
+		}
+	}
+	if output.Data(format) {
+		return output.Write(w, format, struct {
+			Items     []updateCheckItem `json:"items"`
+			Available bool              `json:"update_available"`
+		}{items, available > 0})
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\tCURRENT\tLATEST\t")
+	for _, item := range items {
+		status := glyph.OK() + " up to date"
+		if item.Available {
+			status = glyph.Warn() + " update available"
+		}
+		if item.Note != "" {
+			status += " (" + item.Note + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", item.Name, item.Current, item.Latest, status)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if available == 0 {
+		fmt.Fprintf(w, "\n%s Everything is up to date.\n", glyph.OK())
+		return nil
+	}
+	fmt.Fprintf(w, "\n%d update(s) available. Run 'maestro update' for the assets and agent directories", available)
+	if items[0].Available {
+		fmt.Fprint(w, ", and install the latest maestro release for the CLI")
+	}
+	fmt.Fprintln(w, ".")
+	return nil
+}
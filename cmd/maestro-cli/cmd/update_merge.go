@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spec-maestro/maestro-cli/pkg/config"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/ignore"
+	"github.com/spec-maestro/maestro-cli/pkg/merge"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+	"github.com/spec-maestro/maestro-cli/pkg/report"
+)
+
+const updateStrategyUsage = "What to do with a .maestro/ file edited locally that the update also changes: keep (leave yours), new (also write the update as <file>.new), or markers (write conflict markers)"
+
+// maestroAssetPaths keys the content of a release archive or of the
+// .maestro directory fetched from GitHub by its path from the project root.
+// The project config is left out: update never replaces it.
+func maestroAssetPaths(content map[string][]byte) map[string][]byte {
+	paths := make(map[string][]byte, len(content))
+	for name, data := range content {
+		p := ".maestro/" + strings.TrimPrefix(path.Clean(name), ".maestro/")
+		if p == ".maestro/config.yaml" {
+			continue
+		}
+		paths[p] = data
+	}
+	return paths
+}
+
+// maestroManifest returns the install manifest entries of the .maestro/
+// files an update replaces, which are the base it merges against.
+func maestroManifest(files map[string]string) map[string]string {
+	base := make(map[string]string)
+	for file, sum := range files {
+		if strings.HasPrefix(file, ".maestro/") && file != ".maestro/config.yaml" {
+			base[file] = sum
+		}
+	}
+	return base
+}
+
+// planMaestroAssets decides how the new .maestro/ files would be merged
+// into the project with the --strategy chosen for locally edited files.
+// Files .maestro/.maestroignore lists are taken out of incoming and left
+// alone; those the release or the install manifest has are returned,
+// sorted. root is the project.
+func planMaestroAssets(root string, cfg *config.ProjectConfig, incoming map[string][]byte) ([]merge.Result, []string, error) {
+	strategy, err := merge.ParseStrategy(updateStrategy)
+	if err != nil {
+		return nil, nil, err
+	}
+	ignored, err := ignore.Load(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	skipped := make(map[string]bool)
+	for p := range incoming {
+		if ignored.Match(p) {
+			delete(incoming, p)
+			skipped[p] = true
+		}
+	}
+	results, err := merge.Plan(root, incoming, maestroManifest(cfg.Installed.Files), strategy)
+	if err != nil {
+		return nil, nil, fmt.Errorf("planning update: %w", err)
+	}
+	kept := results[:0]
+	for _, r := range results {
+		if ignored.Match(r.Path) {
+			skipped[r.Path] = true
+			continue
+		}
+		kept = append(kept, r)
+	}
+	paths := make([]string, 0, len(skipped))
+	for p := range skipped {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return kept, paths, nil
+}
+
+// mergeMaestroAssets previews the changes the new .maestro/ files make,
+// asks before applying them, merges them, and records the checksums of the
+// new versions in the install manifest. It returns false when the user
+// declined. A staged update does not ask here but when it ends. root is the
+// project.
+func mergeMaestroAssets(root string, r io.Reader, w io.Writer, content map[string][]byte, assetVersion string, op *report.Operation) (bool, error) {
+	cfg, err := config.Load(projectConfig(root))
+	if err != nil {
+		return false, op.Fail("assets", fmt.Errorf("loading config: %w", err))
+	}
+	incoming := maestroAssetPaths(content)
+	results, ignored, err := planMaestroAssets(root, cfg, incoming)
+	if err != nil {
+		return false, op.Fail("assets", err)
+	}
+	if len(ignored) > 0 {
+		fmt.Fprintf(w, "Left alone by %s: %s\n", ignore.File, strings.Join(ignored, ", "))
+		op.Skip("ignored files", strings.Join(ignored, ", "))
+	}
+
+	if writeIncomingChanges(w, results, installedAssetVersion(cfg), assetVersion) > 0 && !updateStaging {
+		ok, err := confirm(r, w, "Apply these changes?")
+		if err != nil {
+			return false, op.Fail("assets", err)
+		}
+		if !ok {
+			op.Skip("assets", "update to "+assetVersion+" cancelled")
+			return false, nil
+		}
+	}
+
+	if !updateNoSnapshot {
+		if err := snapshotBeforeUpdate(root, cfg, assetVersion, op); err != nil {
+			return false, err
+		}
+	}
+
+	sums := make(map[string]string, len(incoming))
+	for p, data := range incoming {
+		sums[p] = merge.Sum(data)
+	}
+	var removed []string
+	for _, r := range results {
+		if r.Removed {
+			removed = append(removed, r.Path)
+		}
+	}
+	// Merge into a staged copy of .maestro/ that records its own install
+	// manifest, so a failure anywhere leaves the project as it was
+	recordManifest := func(staged string) error {
+		configPath := filepath.Join(staged, "config.yaml")
+		if err := config.RecordChecksums(configPath, assetVersion, sums); err != nil {
+			return fmt.Errorf("recording install manifest: %w", err)
+		}
+		if err := config.ForgetFiles(configPath, removed); err != nil {
+			return fmt.Errorf("recording install manifest: %w", err)
+		}
+		if _, err := config.Load(configPath); err != nil {
+			return fmt.Errorf("checking the updated config: %w", err)
+		}
+		return nil
+	}
+	if err := merge.ExecuteStaged(root, ".maestro", results, incoming, assetVersion, recordManifest); err != nil {
+		return false, op.Fail("assets", fmt.Errorf("merging update (.maestro/ left unchanged): %w", err))
+	}
+	op.OK("assets", assetVersion)
+
+	writeMergeSummary(w, results, op)
+	return true, nil
+}
+
+// incomingChange names how a merge result changes the project, as shown in
+// the preview, or "" when it leaves the file alone without a conflict.
+func incomingChange(r merge.Result) string {
+	switch {
+	case r.Conflict:
+		return "conflict"
+	case r.Action == merge.Created:
+		return "added"
+	case r.Action == merge.Updated:
+		return "changed"
+	case r.Action == merge.Removed:
+		return "removed"
+	}
+	return ""
+}
+
+// conflictNote says what the update does with a file changed both locally
+// and upstream.
+func conflictNote(r merge.Result) string {
+	switch {
+	case r.Removed:
+		return "removed by the release; your edited copy is kept"
+	case r.Action == merge.NewFile:
+		return "edited locally; the new version goes to " + r.Path + ".new"
+	case r.Action == merge.Conflicted:
+		return "edited locally; conflict markers will be written"
+	default:
+		return "edited locally; kept, and the new version is skipped"
+	}
+}
+
+// writeIncomingChanges prints each file the update adds, changes, or
+// removes, and each conflict, with totals, and returns how many files it
+// listed.
+func writeIncomingChanges(w io.Writer, results []merge.Result, from, to string) int {
+	counts := make(map[string]int)
+	listed := 0
+	for _, r := range results {
+		change := incomingChange(r)
+		if change == "" {
+			continue
+		}
+		if listed == 0 {
+			if from == "" {
+				from = "installed"
+			}
+			fmt.Fprintf(w, "\nChanges to .maestro/ (%s %s %s):\n", from, glyph.Arrow(), to)
+		}
+		if r.Conflict {
+			fmt.Fprintf(w, "  %-8s  %s (%s)\n", change, pathfmt.Path(r.Path), conflictNote(r))
+		} else {
+			fmt.Fprintf(w, "  %-8s  %s\n", change, pathfmt.Path(r.Path))
+		}
+		counts[change]++
+		listed++
+	}
+	if listed == 0 {
+		fmt.Fprintln(w, "No changes to .maestro/ files.")
+		return 0
+	}
+	fmt.Fprintf(w, "%d added, %d changed, %d removed, %d conflict(s)\n\n",
+		counts["added"], counts["changed"], counts["removed"], counts["conflict"])
+	return listed
+}
+
+// writeMergeSummary prints what the merge did and records the conflicts in
+// op, with what to do about them.
+func writeMergeSummary(w io.Writer, results []merge.Result, op *report.Operation) {
+	counts := make(map[merge.Action]int)
+	var paths []string
+	var newFiles, markers, kept bool
+	for _, r := range results {
+		counts[r.Action]++
+		if !r.Conflict {
+			continue
+		}
+		paths = append(paths, r.Path)
+		switch r.Action {
+		case merge.NewFile:
+			newFiles = true
+		case merge.Conflicted:
+			markers = true
+		default:
+			kept = kept || !r.Removed
+		}
+	}
+	fmt.Fprintf(w, "%s Merged .maestro/: %d added, %d changed, %d removed, %d with local edits kept\n",
+		glyph.OK(), counts[merge.Created], counts[merge.Updated], counts[merge.Removed], counts[merge.Kept]+counts[merge.NewFile])
+	if len(paths) == 0 {
+		op.OK("merge local edits", fmt.Sprintf("%d kept", counts[merge.Kept]))
+		return
+	}
+
+	op.Warn("merge local edits", fmt.Sprintf("%d conflict(s): %s", len(paths), strings.Join(paths, ", ")))
+	if newFiles {
+		op.FollowUp("Compare each conflicting file with its .new version, keep what you need, and delete the .new file")
+	}
+	if markers {
+		op.FollowUp("Resolve the conflict markers in the conflicting files")
+	}
+	if kept {
+		op.FollowUp("Your edits were kept; rerun 'maestro update --strategy new' to get the new versions")
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/internal/version"
+	ghclient "github.com/spec-maestro/maestro-cli/pkg/github"
+	"github.com/spec-maestro/maestro-cli/pkg/updatenotice"
+)
+
+// updateCheckWait is how long a command waits when it ends for a release
+// check that is still running. A check that does not finish in time is
+// left for a later command.
+const updateCheckWait = time.Second
+
+// pendingUpdateCheck delivers the latest release tag for the notice printed
+// after the command, when startUpdateCheck started a check.
+var pendingUpdateCheck <-chan string
+
+// startUpdateCheck looks up the latest release for the update notice: from
+// the cache when it was checked within updatenotice.Interval, and
+// otherwise from GitHub in the background while the command runs.
+func startUpdateCheck(cmd *cobra.Command) {
+	pendingUpdateCheck = nil
+	if !updateCheckEnabled(cmd) {
+		return
+	}
+	path, err := updatenotice.Path()
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	state := updatenotice.Load(path)
+	result := make(chan string, 1)
+	pendingUpdateCheck = result
+	if !state.Due(now) {
+		result <- state.Latest
+		return
+	}
+	go func() {
+		latest := state.Latest
+		if release, err := ghclient.NewClient(githubOwner, githubRepo, "").FetchLatestRelease(); err == nil {
+			latest = release.TagName
+		}
+		// A failed check is recorded too, so an offline machine does not
+		// retry on every command
+		_ = updatenotice.Save(path, updatenotice.State{CheckedAt: now, Latest: latest})
+		result <- latest
+	}()
+}
+
+// updateCheckEnabled reports whether cmd should check for a newer release.
+// The check is off for development builds, for the commands that already
+// compare versions, for uninstall, which would recreate the cache, outside
+// a terminal (including CI), and when turned off with
+// MAESTRO_NO_UPDATE_CHECK or the update_check setting.
+func updateCheckEnabled(cmd *cobra.Command) bool {
+	if version.Version == "dev" || os.Getenv("MAESTRO_NO_UPDATE_CHECK") != "" || os.Getenv("CI") != "" {
+		return false
+	}
+	// Commands are matched by path: referring to them would make an
+	// initialization cycle through rootCmd
+	switch path := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" "); strings.Fields(path)[0] {
+	case "update", "version", "completion", "uninstall", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return false
+	}
+	value, _ := projectResolver(workDir(cmd)).String("update_check", "true")
+	if enabled, err := strconv.ParseBool(value); err == nil && !enabled {
+		return false
+	}
+	return isTerminal(os.Stderr)
+}
+
+// printUpdateNotice prints that a newer release is available, once the
+// check startUpdateCheck started has its answer.
+func printUpdateNotice(w io.Writer) {
+	if pendingUpdateCheck == nil {
+		return
+	}
+	select {
+	case latest := <-pendingUpdateCheck:
+		if notice := updatenotice.Notice(version.Version, latest); notice != "" {
+			fmt.Fprintf(w, "\n%s\n", notice)
+		}
+	case <-time.After(updateCheckWait):
+	}
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/spec-maestro/maestro-cli/pkg/agents"
+	"github.com/spec-maestro/maestro-cli/pkg/guard"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+	"github.com/spec-maestro/maestro-cli/pkg/report"
+	"github.com/spec-maestro/maestro-cli/pkg/staging"
+)
+
+// updateStagingDir is where update stages its changes, relative to the
+// project root.
+const updateStagingDir = ".maestro/.staging"
+
+// updateStaging is set while update works in its staging directory. Its
+// steps then skip their own confirmations: the staged changes are shown
+// and confirmed together when it ends.
+var updateStaging bool
+
+// updateStagedPaths returns what update can change: .maestro/, every
+// agent directory it knows, and their instruction files.
+func updateStagedPaths() []string {
+	known := agents.KnownAgentDirs()
+	paths := append([]string{".maestro"}, known...)
+	seen := make(map[string]bool)
+	for _, file := range updateInstructionFiles(known) {
+		if !seen[file] {
+			seen[file] = true
+			paths = append(paths, file)
+		}
+	}
+	return paths
+}
+
+// updateStage is a staged update of the project.
+type updateStage struct {
+	area    *staging.Area
+	project string
+}
+
+// beginUpdateStage copies what update can change in project into
+// updateStagingDir. Every step of the update then works in the copy,
+// area.Dir, as the project root, and paths are printed relative to it as
+// they would be in the project. Snapshots in .maestro/archive are not
+// copied.
+func beginUpdateStage(project string, op *report.Operation) (*updateStage, error) {
+	area, err := staging.New(project, filepath.Join(project, filepath.FromSlash(updateStagingDir)), updateStagedPaths(), []string{filepath.ToSlash(filepath.Dir(guard.Dir))})
+	if err != nil {
+		return nil, op.Fail("stage", err)
+	}
+	pathfmt.SetRoot(area.Dir)
+	updateStaging = true
+	return &updateStage{area: area, project: project}, nil
+}
+
+// finish goes back to the project and, when the update succeeded, lists the
+// staged changes and applies them once confirmed, or right away with
+// --auto-apply. Changes to .maestro/config.yaml alone, such as recording
+// the update time, are applied without asking. The staging directory is
+// removed either way. err is what the update returned; a failed update
+// applies nothing, and neither does one whose changes fail to apply.
+func (s *updateStage) finish(r io.Reader, w io.Writer, op *report.Operation, err error) error {
+	updateStaging = false
+	pathfmt.SetRoot(s.project)
+	defer s.area.Discard()
+	if err != nil {
+		fmt.Fprintln(w, "Nothing was applied; the staged changes were discarded.")
+		return err
+	}
+
+	changes, err := s.area.Changes()
+	if err != nil {
+		return op.Fail("apply", err)
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	if !onlyConfigChanges(changes) {
+		writeStagedChanges(w, changes)
+		if !updateAutoApply {
+			ok, err := confirm(r, w, "Apply these changes?")
+			if err != nil {
+				return op.Fail("apply", err)
+			}
+			if !ok {
+				fmt.Fprintln(w, "Aborted; nothing was changed.")
+				op.Skip("apply", "declined; the staged changes were discarded")
+				return nil
+			}
+		}
+	}
+	if err := s.area.Apply(changes); err != nil {
+		if errors.Is(err, staging.ErrPartial) {
+			err = fmt.Errorf("%w ('maestro rollback' restores the project when a snapshot was taken)", err)
+		}
+		return op.Fail("apply", err)
+	}
+	op.OK("apply", fmt.Sprintf("%d file(s)", len(changes)))
+	return nil
+}
+
+// onlyConfigChanges reports whether changes only touch the project config.
+func onlyConfigChanges(changes []staging.Change) bool {
+	for _, c := range changes {
+		if c.Path != ".maestro/config.yaml" {
+			return false
+		}
+	}
+	return true
+}
+
+// writeStagedChanges prints each file the staged update adds, changes, or
+// removes, with totals.
+func writeStagedChanges(w io.Writer, changes []staging.Change) {
+	counts := make(map[string]int)
+	fmt.Fprintln(w, "\nStaged changes:")
+	for _, c := range changes {
+		fmt.Fprintf(w, "  %-8s  %s\n", c.Kind, pathfmt.Path(c.Path))
+		counts[c.Kind]++
+	}
+	fmt.Fprintf(w, "%d added, %d changed, %d removed\n\n", counts[staging.Added], counts[staging.Changed], counts[staging.Removed])
+	if dirs := stagedBackups(changes); len(dirs) > 0 {
+		fmt.Fprintf(w, "Backups: %s\n\n", strings.Join(dirs, ", "))
+	}
+}
+
+// stagedBackups returns the agent directory backups the changes add.
+func stagedBackups(changes []staging.Change) []string {
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, c := range changes {
+		top := strings.SplitN(c.Path, "/", 2)[0]
+		if c.Kind == staging.Added && strings.Contains(top, "-backup-") && !seen[top] {
+			seen[top] = true
+			dirs = append(dirs, top)
+		}
+	}
+	return dirs
+}
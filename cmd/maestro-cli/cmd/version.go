@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/internal/version"
+	"github.com/spec-maestro/maestro-cli/pkg/config"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/output"
+)
+
+// staleUpdateAge is how long after the last update maestro starts advising
+// the user to run 'maestro update'.
+const staleUpdateAge = 60 * 24 * time.Hour
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the maestro version and when the project was last updated",
+	Long:  "Prints the CLI version. Inside a maestro project it also prints when the project's assets were last installed or updated and from which release or commit, and advises updating when that was more than 60 days ago.",
+	Args:  cobra.NoArgs,
+	RunE:  runVersion,
+}
+
+var versionOutput string
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+	addProjectPathFlag(versionCmd, true)
+	versionCmd.Flags().StringVar(&versionOutput, "output", "text", dataFormatUsage)
+}
+
+// versionReport is what version --output json writes.
+type versionReport struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+	Date    string `json:"date"`
+	// Project is set inside a maestro project.
+	Project *projectVersion `json:"project,omitempty"`
+}
+
+// projectVersion describes the project's assets for version --output json.
+type projectVersion struct {
+	CLIVersion   string     `json:"cli_version,omitempty"`
+	AssetVersion string     `json:"asset_version,omitempty"`
+	LastUpdated  *time.Time `json:"last_updated,omitempty"`
+	// LastCommand and Source say how the last update was made.
+	LastCommand string `json:"last_command,omitempty"`
+	Source      string `json:"source,omitempty"`
+	Commit      string `json:"commit,omitempty"`
+	// Stale is true when the last update is older than 60 days.
+	Stale bool `json:"stale"`
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(versionOutput); err != nil {
+		return err
+	}
+	report := versionReport{Version: version.Version, Commit: version.Commit, Date: version.Date}
+	var cfg *config.ProjectConfig
+	root := workDir(cmd)
+	if _, err := os.Stat(filepath.Join(root, ".maestro")); err == nil {
+		if cfg, err = config.Load(projectConfig(root)); err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		report.Project = describeProjectVersion(cfg, time.Now())
+	}
+	if output.Data(versionOutput) {
+		return output.Write(os.Stdout, versionOutput, report)
+	}
+
+	fmt.Printf("maestro %s\n", version.String())
+	if cfg != nil {
+		printLastUpdate(os.Stdout, cfg, time.Now())
+	}
+	return nil
+}
+
+// describeProjectVersion reports the project's versions and last update.
+func describeProjectVersion(cfg *config.ProjectConfig, now time.Time) *projectVersion {
+	p := &projectVersion{CLIVersion: cfg.CLIVersion, AssetVersion: cfg.Installed.AssetVersion}
+	if at := cfg.LastUpdatedAt(); !at.IsZero() {
+		p.LastUpdated = &at
+	}
+	if last := cfg.Installed.LastUpdate; last != nil {
+		p.LastCommand, p.Source, p.Commit = last.Command, last.Source, last.Commit
+	}
+	_, p.Stale = describeLastUpdate(cfg, now)
+	return p
+}
+
+// printLastUpdate describes the project's last update and its agent
+// directories, with an advisory when the update is stale.
+func printLastUpdate(w io.Writer, cfg *config.ProjectConfig, now time.Time) {
+	message, stale := describeLastUpdate(cfg, now)
+	fmt.Fprintf(w, "Project assets: %s\n", message)
+	dirs := make([]string, 0, len(cfg.Installed.AgentDirs))
+	for dir := range cfg.Installed.AgentDirs {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		if at := cfg.Installed.AgentDirs[dir].UpdatedAt; !at.IsZero() {
+			fmt.Fprintf(w, "  %s updated %s\n", dir, describeAge(at, now))
+		}
+	}
+	if stale {
+		fmt.Fprintf(w, "%s Run 'maestro update' to get the latest commands and templates\n", glyph.Warn())
+	}
+}
+
+// describeLastUpdate says when and from where the project's assets were
+// last updated, and reports whether that is older than staleUpdateAge.
+func describeLastUpdate(cfg *config.ProjectConfig, now time.Time) (string, bool) {
+	at := cfg.LastUpdatedAt()
+	if at.IsZero() {
+		return "last update not recorded", false
+	}
+
+	message := "updated " + describeAge(at, now)
+	if last := cfg.Installed.LastUpdate; last != nil {
+		message = fmt.Sprintf("%s by maestro %s from %s", message, last.Command, last.Source)
+		if last.Commit != "" {
+			message += " (" + shortSHA(last.Commit) + ")"
+		}
+	} else {
+		message = "initialized " + describeAge(at, now)
+	}
+	return message, now.Sub(at) > staleUpdateAge
+}
+
+// describeAge formats at as a date with how many days ago it was.
+func describeAge(at, now time.Time) string {
+	days := int(now.Sub(at).Hours() / 24)
+	switch days {
+	case 0:
+		return at.Format("2006-01-02") + " (today)"
+	case 1:
+		return at.Format("2006-01-02") + " (1 day ago)"
+	default:
+		return fmt.Sprintf("%s (%d days ago)", at.Format("2006-01-02"), days)
+	}
+}
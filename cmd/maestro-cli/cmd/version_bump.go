@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/internal/version"
+	"github.com/spec-maestro/maestro-cli/pkg/bundle"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+)
+
+var versionBumpCmd = &cobra.Command{
+	Use:   "bump <version>",
+	Short: "Set the version fields of an assets repository for a release",
+	Long: "For maintainers of the assets repository or a fork of it. Sets cli_version and installed.asset_version in .maestro/config.yaml " +
+		"where they are present, and version in every " + bundle.ManifestName + ", then refreshes every " + bundle.ChecksumsName +
+		" entry whose file changed, so a release ships coherent versions and checksums. Comments and formatting are kept.",
+	Args: cobra.ExactArgs(1),
+	RunE: runVersionBump,
+}
+
+var versionBumpDryRun bool
+
+func init() {
+	versionCmd.AddCommand(versionBumpCmd)
+	versionBumpCmd.Flags().BoolVar(&versionBumpDryRun, "dry-run", false, "List the changes without writing them")
+}
+
+// versionField is a YAML field version bump sets.
+type versionField struct {
+	key string
+	// topLevel is whether the key is at the top level of the file, rather
+	// than nested in a section
+	topLevel bool
+}
+
+// versionFiles maps the base name of each file version bump edits to its
+// version fields.
+var versionFiles = map[string][]versionField{
+	"config.yaml":       {{"cli_version", true}, {"asset_version", false}},
+	bundle.ManifestName: {{"version", true}},
+}
+
+// versionBump is a change version bump makes to one file.
+type versionBump struct {
+	path    string
+	changes []string
+	data    []byte
+}
+
+func runVersionBump(cmd *cobra.Command, args []string) error {
+	target := args[0]
+	if !releaseVersion.MatchString(target) {
+		return fmt.Errorf("invalid version %q: use vMAJOR.MINOR.PATCH", target)
+	}
+	root := workDir(cmd)
+	if _, err := os.Stat(filepath.Join(root, assetsRepoMarker)); err != nil {
+		return fmt.Errorf("no %s here: run 'maestro version bump' at the root of the assets repository or a fork of it", assetsRepoMarker)
+	}
+
+	bumps, err := planVersionBump(root, target)
+	if err != nil {
+		return err
+	}
+	if len(bumps) == 0 {
+		fmt.Printf("%s Nothing to change; the version fields and checksums are already at %s\n", glyph.OK(), target)
+		return nil
+	}
+	for _, b := range bumps {
+		fmt.Printf("%s\n", pathfmt.Rel(b.path))
+		for _, change := range b.changes {
+			fmt.Printf("  %s\n", change)
+		}
+	}
+	if versionBumpDryRun {
+		fmt.Printf("\nDry run: %d file(s) would change.\n", len(bumps))
+		return nil
+	}
+	for _, b := range bumps {
+		info, err := os.Stat(b.path)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(b.path, b.data, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("writing %s: %w", b.path, err)
+		}
+	}
+	fmt.Printf("\n%s Bumped %d file(s) to %s\n", glyph.OK(), len(bumps), target)
+	return nil
+}
+
+// planVersionBump returns the changes that set the version fields under
+// root to target and bring the checksum files in line with them, sorted by
+// path. It fails before changing anything when target is older than a
+// version already there.
+func planVersionBump(root, target string) ([]versionBump, error) {
+	var versioned, checksums []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && (d.Name() == ".git" || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch {
+		case d.Name() == bundle.ChecksumsName:
+			checksums = append(checksums, path)
+		case d.Name() == bundle.ManifestName:
+			versioned = append(versioned, path)
+		case path == filepath.Join(root, ".maestro", "config.yaml"):
+			versioned = append(versioned, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make(map[string]*versionBump)
+	for _, path := range versioned {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		b := &versionBump{path: path, data: data}
+		for _, field := range versionFiles[filepath.Base(path)] {
+			var old string
+			b.data, old = setYAMLScalar(b.data, field.key, field.topLevel, target)
+			if old == "" || old == target {
+				continue
+			}
+			if older, err := version.Less(target, old); err == nil && older {
+				return nil, fmt.Errorf("%s: %s is %s, newer than %s", path, field.key, old, target)
+			}
+			b.changes = append(b.changes, fmt.Sprintf("%s: %s %s %s", field.key, old, glyph.Arrow(), target))
+		}
+		if len(b.changes) > 0 {
+			pending[path] = b
+		}
+	}
+
+	// Checksums are computed last, over the files as they will be written
+	for _, path := range checksums {
+		b, err := refreshChecksums(path, pending)
+		if err != nil {
+			return nil, err
+		}
+		if b != nil {
+			pending[path] = b
+		}
+	}
+
+	bumps := make([]versionBump, 0, len(pending))
+	for _, b := range pending {
+		bumps = append(bumps, *b)
+	}
+	sort.Slice(bumps, func(i, j int) bool { return bumps[i].path < bumps[j].path })
+	return bumps, nil
+}
+
+// releaseVersion matches a release tag, e.g. v1.2.0 or v1.3.0-rc.1.
+var releaseVersion = regexp.MustCompile(`^v\d+\.\d+\.\d+([-+][0-9A-Za-z.-]+)?$`)
+
+// yamlScalarLine matches a "key: value" line, with the value optionally
+// quoted and followed by a comment.
+var yamlScalarLine = regexp.MustCompile(`^(\s*)([A-Za-z_][\w-]*):(\s*)(["']?)([^"'#\s]*)(["']?)(\s*(#.*)?)$`)
+
+// setYAMLScalar sets every value of key in data to value, editing the
+// lines in place so comments and formatting are kept, and returns the
+// result with the first value it replaced ("" when key is not there). A
+// topLevel key only matches without indentation, any other only with.
+func setYAMLScalar(data []byte, key string, topLevel bool, value string) ([]byte, string) {
+	lines := strings.SplitAfter(string(data), "\n")
+	old := ""
+	for i, line := range lines {
+		body := strings.TrimRight(line, "\r\n")
+		m := yamlScalarLine.FindStringSubmatch(body)
+		if m == nil || m[2] != key || (m[1] == "") != topLevel || m[5] == "" {
+			continue
+		}
+		if old == "" {
+			old = m[5]
+		}
+		lines[i] = m[1] + m[2] + ":" + m[3] + m[4] + value + m[6] + m[7] + line[len(body):]
+	}
+	return []byte(strings.Join(lines, "")), old
+}
+
+// refreshChecksums updates the entries of the checksum file at path whose
+// file, relative to it, now has another SHA-256, reading the files in
+// pending as they will be written. Entries whose file is missing are kept.
+// It returns nil when nothing changes.
+func refreshChecksums(path string, pending map[string]*versionBump) (*versionBump, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b := &versionBump{path: path}
+	var out strings.Builder
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			out.WriteString(line + "\n")
+			continue
+		}
+		name := strings.TrimPrefix(fields[len(fields)-1], "*")
+		file := filepath.Join(filepath.Dir(path), filepath.FromSlash(strings.TrimPrefix(name, "./")))
+		var content []byte
+		if p, ok := pending[file]; ok {
+			content = p.data
+		} else if content, err = os.ReadFile(file); err != nil {
+			out.WriteString(line + "\n")
+			continue
+		}
+		sum := sha256.Sum256(content)
+		got := hex.EncodeToString(sum[:])
+		if !strings.EqualFold(got, fields[0]) {
+			line = strings.Replace(line, fields[0], got, 1)
+			b.changes = append(b.changes, fmt.Sprintf("%s: checksum updated", name))
+		}
+		out.WriteString(line + "\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if len(b.changes) == 0 {
+		return nil, nil
+	}
+	b.data = []byte(out.String())
+	return b, nil
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spec-maestro/maestro-cli/pkg/config"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+	"github.com/spec-maestro/maestro-cli/pkg/project"
+)
+
+// chdirFlag is the -C/--chdir flag.
+var chdirFlag string
+
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&chdirFlag, "chdir", "C", "", "Run as if maestro was started in this directory, like git -C; --path and relative paths are resolved from it")
+}
+
+// dirs is where a command works: the project root, once -C, --path, or
+// project discovery found it, and the directory paths given on the command
+// line are relative to. maestro never changes the process's directory;
+// every path it opens and every process it starts is resolved from these.
+type dirs struct {
+	work, invocation string
+}
+
+type dirsKey struct{}
+
+// resolveDirs works out where cmd runs and stores it on cmd's context,
+// before anything resolves a path or looks for the project.
+func resolveDirs(cmd *cobra.Command) error {
+	invocation, err := filepath.Abs(chdirFlag)
+	if err != nil {
+		return err
+	}
+	if chdirFlag != "" {
+		if info, err := os.Stat(invocation); err != nil {
+			return fmt.Errorf("entering -C: %w", err)
+		} else if !info.IsDir() {
+			return fmt.Errorf("entering -C: %s is not a directory", chdirFlag)
+		}
+	}
+	work, err := projectDir(cmd, invocation)
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cmd.SetContext(context.WithValue(ctx, dirsKey{}, dirs{work: work, invocation: invocation}))
+	pathfmt.SetRoot(work)
+	return nil
+}
+
+// projectDir returns the project directory for commands with a --path
+// flag, and invocation for the others. init creates the directory if
+// needed.
+func projectDir(cmd *cobra.Command, invocation string) (string, error) {
+	if cmd.Flags().Lookup("path") == nil {
+		return invocation, nil
+	}
+	if projectPath != "" {
+		dir := projectPath
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(invocation, dir)
+		}
+		if cmd == initCmd {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return "", fmt.Errorf("creating %s: %w", projectPath, err)
+			}
+		}
+		if info, err := os.Stat(dir); err != nil {
+			return "", fmt.Errorf("entering --path: %w", err)
+		} else if !info.IsDir() {
+			return "", fmt.Errorf("entering --path: %s is not a directory", projectPath)
+		}
+		return dir, nil
+	}
+
+	if cmd.Annotations[discoverProjectAnnotation] == "" {
+		return invocation, nil
+	}
+	dir, err := project.Find(invocation)
+	if errors.Is(err, project.ErrNotFound) {
+		// Let the command report the missing project in its own words
+		return invocation, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if dir != invocation {
+		fmt.Fprintf(os.Stderr, "Using maestro project at %s\n", dir)
+	}
+	return dir, nil
+}
+
+// cmdDirs returns the directories resolveDirs stored for cmd. A command run
+// without it, as tests run their handlers, works in the current directory.
+func cmdDirs(cmd *cobra.Command) dirs {
+	if ctx := cmd.Context(); ctx != nil {
+		if d, ok := ctx.Value(dirsKey{}).(dirs); ok {
+			return d
+		}
+	}
+	return dirs{work: ".", invocation: "."}
+}
+
+// workDir returns the directory cmd works in: the project root for
+// commands with a --path flag.
+func workDir(cmd *cobra.Command) string {
+	return cmdDirs(cmd).work
+}
+
+// userPath resolves a path given on the command line relative to the
+// directory the command was run from, which is not the directory it works
+// in once the project root has been discovered.
+func userPath(cmd *cobra.Command, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(cmdDirs(cmd).invocation, path)
+}
+
+// projectConfig returns the config file of the project at root.
+func projectConfig(root string) string {
+	return filepath.Join(root, ".maestro", "config.yaml")
+}
+
+// projectResolver resolves settings with the config of the project at root
+// as the project layer.
+func projectResolver(root string) *config.Resolver {
+	return &config.Resolver{ProjectPath: projectConfig(root)}
+}
+
+// inProject resolves path, as commands print them, relative to root.
+func inProject(root, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(root, path)
+}
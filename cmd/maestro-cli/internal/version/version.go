@@ -1,5 +1,11 @@
 package version
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 var (
 	// Version is the semantic version, injected at build time.
 	Version = "dev"
@@ -13,3 +19,43 @@ var (
 func String() string {
 	return Version + " (commit: " + Commit + ", built: " + Date + ")"
 }
+
+// Less reports whether version a is older than b. Both are
+// vMAJOR.MINOR.PATCH with an optional leading v; pre-release and build
+// suffixes are ignored.
+func Less(a, b string) (bool, error) {
+	pa, err := parseVersion(a)
+	if err != nil {
+		return false, err
+	}
+	pb, err := parseVersion(b)
+	if err != nil {
+		return false, err
+	}
+	for i := range pa {
+		if pa[i] != pb[i] {
+			return pa[i] < pb[i], nil
+		}
+	}
+	return false, nil
+}
+
+func parseVersion(v string) ([3]int, error) {
+	var parts [3]int
+	s := strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+	fields := strings.Split(s, ".")
+	if len(fields) > 3 {
+		return parts, fmt.Errorf("invalid version %q", v)
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return parts, fmt.Errorf("invalid version %q", v)
+		}
+		parts[i] = n
+	}
+	return parts, nil
+}
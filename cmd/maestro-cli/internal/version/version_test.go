@@ -17,3 +17,25 @@ func TestString(t *testing.T) {
 		t.Errorf("String() %q does not contain commit %q", s, Commit)
 	}
 }
+
+func TestLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"v0.4.9", "v0.5.0", true},
+		{"v0.5.0", "v0.5.0", false},
+		{"v1.0.0", "v0.10.0", false},
+		{"v0.10.0", "v0.9.1", false},
+		{"0.5.0-rc.1", "v0.5.0", false},
+		{"v1.2", "v1.2.1", true},
+	}
+	for _, tt := range tests {
+		if got, err := Less(tt.a, tt.b); err != nil || got != tt.want {
+			t.Errorf("Less(%q, %q) = %v, %v; want %v", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+	if _, err := Less("latest", "v1.0.0"); err == nil {
+		t.Error("expected error for invalid version")
+	}
+}
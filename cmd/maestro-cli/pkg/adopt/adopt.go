@@ -0,0 +1,283 @@
+// Package adopt imports spec documents that a repository kept before maestro
+// was installed (e.g. specs/ or docs/rfcs/) into .maestro/specs/, creating a
+// pipeline state entry for each.
+package adopt
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spec-maestro/maestro-cli/pkg/state"
+)
+
+// CandidateDirs are the conventional spec folders init offers to adopt.
+var CandidateDirs = []string{"specs", "docs/rfcs"}
+
+// Mode says how adopted documents are brought under .maestro/specs/.
+type Mode int
+
+const (
+	// Symlink leaves documents in place and links to them.
+	Symlink Mode = iota
+	// Move relocates documents into .maestro/specs/.
+	Move
+)
+
+// ParseMode converts a flag value to a Mode.
+func ParseMode(s string) (Mode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "symlink", "link", "s":
+		return Symlink, nil
+	case "move", "m":
+		return Move, nil
+	default:
+		return Symlink, fmt.Errorf("invalid adopt mode %q (want symlink or move)", s)
+	}
+}
+
+// String returns the flag spelling of the mode.
+func (m Mode) String() string {
+	if m == Move {
+		return "move"
+	}
+	return "symlink"
+}
+
+// Document is one spec to adopt. A Markdown file becomes the spec.md of a new
+// feature directory; a subdirectory becomes the feature directory itself.
+type Document struct {
+	Source    string // path relative to the project root, e.g. specs/auth.md
+	IsDir     bool
+	FeatureID string // e.g. 004-auth
+}
+
+// Dest returns the feature directory the document is adopted into.
+func (d Document) Dest(specsDir string) string {
+	return filepath.Join(specsDir, d.FeatureID)
+}
+
+// SpecPath returns the path of the adopted spec document in the project at
+// root. An adopted directory without a spec.md is referenced as a whole.
+func (d Document) SpecPath(root, specsDir string) string {
+	spec := filepath.Join(d.Dest(specsDir), "spec.md")
+	if d.IsDir {
+		if _, err := os.Stat(inRoot(root, spec)); err != nil {
+			return d.Dest(specsDir)
+		}
+	}
+	return spec
+}
+
+// Find returns the candidate directories under root that contain at least
+// one Markdown document.
+func Find(root string) []string {
+	var found []string
+	for _, dir := range CandidateDirs {
+		docs, err := scan(filepath.Join(root, dir))
+		if err == nil && len(docs) > 0 {
+			found = append(found, dir)
+		}
+	}
+	return found
+}
+
+// Plan assigns feature IDs to the documents in dirs, numbering after the
+// highest feature already in specsDir. dirs and specsDir are relative to
+// root.
+func Plan(root string, dirs []string, specsDir string) ([]Document, error) {
+	next := NextFeatureNumber(inRoot(root, specsDir))
+
+	var docs []Document
+	for _, dir := range dirs {
+		entries, err := scan(filepath.Join(root, dir))
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			docs = append(docs, Document{
+				Source:    filepath.ToSlash(filepath.Join(dir, entry.Name())),
+				IsDir:     entry.IsDir(),
+				FeatureID: fmt.Sprintf("%03d-%s", next, Slugify(entry.Name())),
+			})
+			next++
+		}
+	}
+	return docs, nil
+}
+
+// Apply adopts each document into specsDir and writes a state entry to
+// stateDir, both relative to root. Existing feature directories and state
+// files are never overwritten. It returns the documents adopted before any
+// error.
+func Apply(root string, docs []Document, mode Mode, specsDir, stateDir string) ([]Document, error) {
+	if err := os.MkdirAll(inRoot(root, specsDir), 0755); err != nil {
+		return nil, fmt.Errorf("creating %s: %w", specsDir, err)
+	}
+	if err := os.MkdirAll(inRoot(root, stateDir), 0755); err != nil {
+		return nil, fmt.Errorf("creating %s: %w", stateDir, err)
+	}
+
+	adopted := make([]Document, 0, len(docs))
+	for _, doc := range docs {
+		dest := doc.Dest(specsDir)
+		if _, err := os.Lstat(inRoot(root, dest)); err == nil {
+			return adopted, fmt.Errorf("adopting %s: %s already exists", doc.Source, dest)
+		}
+		if err := place(filepath.Join(root, filepath.FromSlash(doc.Source)), inRoot(root, dest), doc, mode); err != nil {
+			return adopted, fmt.Errorf("adopting %s: %w", doc.Source, err)
+		}
+		if err := writeState(root, doc, mode, specsDir, stateDir); err != nil {
+			return adopted, fmt.Errorf("adopting %s: %w", doc.Source, err)
+		}
+		adopted = append(adopted, doc)
+	}
+	return adopted, nil
+}
+
+// place moves or links a document into its feature directory, dest.
+func place(source, dest string, doc Document, mode Mode) error {
+	if !doc.IsDir {
+		if err := os.MkdirAll(dest, 0755); err != nil {
+			return err
+		}
+		dest = filepath.Join(dest, "spec.md")
+	}
+
+	if mode == Move {
+		return os.Rename(source, dest)
+	}
+
+	absSource, err := filepath.Abs(source)
+	if err != nil {
+		return err
+	}
+	absDir, err := filepath.Abs(filepath.Dir(dest))
+	if err != nil {
+		return err
+	}
+	target, err := filepath.Rel(absDir, absSource)
+	if err != nil {
+		return err
+	}
+	return os.Symlink(target, dest)
+}
+
+// writeState records the adopted document as a feature at the specify stage.
+func writeState(root string, doc Document, mode Mode, specsDir, stateDir string) error {
+	path := filepath.Join(inRoot(root, stateDir), doc.FeatureID+".json")
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	s := &state.State{FeatureID: doc.FeatureID, SpecPath: filepath.ToSlash(doc.SpecPath(root, specsDir))}
+	source, _ := json.Marshal(doc.Source)
+	if err := s.Set("adopted_from", source); err != nil {
+		return err
+	}
+	if err := s.Transition("specify", fmt.Sprintf("adopted from %s (%s)", doc.Source, mode), "", time.Now()); err != nil {
+		return err
+	}
+	return state.Save(path, s)
+}
+
+// inRoot resolves path, relative to the project root or absolute.
+func inRoot(root, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(root, path)
+}
+
+// scan returns the adoptable entries of dir, sorted by name: Markdown files
+// other than index pages, and subdirectories containing Markdown.
+func scan(dir string) ([]os.DirEntry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []os.DirEntry
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		if entry.IsDir() {
+			if hasMarkdown(filepath.Join(dir, name)) {
+				docs = append(docs, entry)
+			}
+			continue
+		}
+		if entry.Type().IsRegular() && isMarkdown(name) && !isIndexPage(name) {
+			docs = append(docs, entry)
+		}
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Name() < docs[j].Name() })
+	return docs, nil
+}
+
+func hasMarkdown(dir string) bool {
+	found := false
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && isMarkdown(d.Name()) {
+			found = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found
+}
+
+func isMarkdown(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".md")
+}
+
+func isIndexPage(name string) bool {
+	base := strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))
+	return base == "readme" || base == "index"
+}
+
+var (
+	leadingNumber = regexp.MustCompile(`^(rfc)?[-_ ]?\d+[-_ .]+`)
+	nonSlug       = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Slugify turns a file or directory name into a feature slug, dropping the
+// extension and any leading RFC number ("0007-auth-flow.md" -> "auth-flow").
+func Slugify(name string) string {
+	name = strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))
+	if stripped := leadingNumber.ReplaceAllString(name, ""); stripped != "" {
+		name = stripped
+	}
+	slug := strings.Trim(nonSlug.ReplaceAllString(name, "-"), "-")
+	if len(slug) > 40 {
+		slug = strings.TrimRight(slug[:40], "-")
+	}
+	if slug == "" {
+		return "spec"
+	}
+	return slug
+}
+
+// NextFeatureNumber returns one past the highest NNN- prefix in specsDir.
+func NextFeatureNumber(specsDir string) int {
+	highest := 0
+	entries, _ := os.ReadDir(specsDir)
+	for _, entry := range entries {
+		prefix, _, ok := strings.Cut(entry.Name(), "-")
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(prefix); err == nil && n > highest {
+			highest = n
+		}
+	}
+	return highest + 1
+}
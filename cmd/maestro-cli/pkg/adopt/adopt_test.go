@@ -0,0 +1,155 @@
+package adopt
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func setupRepo(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "specs", "README.md"), "index\n")
+	writeFile(t, filepath.Join(root, "specs", "auth.md"), "# Auth\n")
+	writeFile(t, filepath.Join(root, "specs", "payments", "spec.md"), "# Payments\n")
+	writeFile(t, filepath.Join(root, "specs", "notes.txt"), "not a spec\n")
+	writeFile(t, filepath.Join(root, "docs", "rfcs", "0007-rate-limits.md"), "# Rate limits\n")
+	os.MkdirAll(filepath.Join(root, ".maestro", "specs", "002-existing"), 0755)
+	return root
+}
+
+func TestFindAndPlan(t *testing.T) {
+	root := setupRepo(t)
+
+	dirs := Find(root)
+	if len(dirs) != 2 || dirs[0] != "specs" || dirs[1] != "docs/rfcs" {
+		t.Fatalf("Find() = %v", dirs)
+	}
+
+	docs, err := Plan(root, dirs, filepath.Join(root, ".maestro", "specs"))
+	if err != nil {
+		t.Fatalf("Plan() error: %v", err)
+	}
+	want := []Document{
+		{Source: "specs/auth.md", FeatureID: "003-auth"},
+		{Source: "specs/payments", IsDir: true, FeatureID: "004-payments"},
+		{Source: "docs/rfcs/0007-rate-limits.md", FeatureID: "005-rate-limits"},
+	}
+	if len(docs) != len(want) {
+		t.Fatalf("Plan() = %+v, want %+v", docs, want)
+	}
+	for i := range want {
+		if docs[i] != want[i] {
+			t.Errorf("doc %d = %+v, want %+v", i, docs[i], want[i])
+		}
+	}
+}
+
+func TestFindIgnoresEmptyFolders(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "specs", "README.md"), "index\n")
+	os.MkdirAll(filepath.Join(root, "docs", "rfcs"), 0755)
+
+	if dirs := Find(root); len(dirs) != 0 {
+		t.Errorf("Find() = %v, want none", dirs)
+	}
+}
+
+func TestApplySymlink(t *testing.T) {
+	root := setupRepo(t)
+	docs, _ := Plan(root, []string{"specs"}, ".maestro/specs")
+
+	adopted, err := Apply(root, docs, Symlink, ".maestro/specs", ".maestro/state")
+	if err != nil {
+		t.Fatalf("Apply() error: %v", err)
+	}
+	if len(adopted) != 2 {
+		t.Fatalf("adopted %d docs, want 2", len(adopted))
+	}
+
+	specsDir := filepath.Join(root, ".maestro", "specs")
+	content, err := os.ReadFile(filepath.Join(specsDir, "003-auth", "spec.md"))
+	if err != nil || string(content) != "# Auth\n" {
+		t.Errorf("symlinked spec = %q, %v", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "specs", "auth.md")); err != nil {
+		t.Error("symlink mode should leave the original in place")
+	}
+	content, err = os.ReadFile(filepath.Join(specsDir, "004-payments", "spec.md"))
+	if err != nil || string(content) != "# Payments\n" {
+		t.Errorf("symlinked directory spec = %q, %v", content, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, ".maestro", "state", "004-payments.json"))
+	if err != nil {
+		t.Fatalf("state entry missing: %v", err)
+	}
+	var state map[string]interface{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatalf("invalid state JSON: %v", err)
+	}
+	if state["feature_id"] != "004-payments" || state["stage"] != "specify" || state["adopted_from"] != "specs/payments" {
+		t.Errorf("unexpected state: %v", state)
+	}
+	if state["spec_path"] != ".maestro/specs/004-payments/spec.md" {
+		t.Errorf("spec_path = %v", state["spec_path"])
+	}
+}
+
+func TestApplyMove(t *testing.T) {
+	root := setupRepo(t)
+	specsDir := filepath.Join(root, ".maestro", "specs")
+	docs, _ := Plan(root, []string{"docs/rfcs"}, specsDir)
+
+	if _, err := Apply(root, docs, Move, specsDir, filepath.Join(root, ".maestro", "state")); err != nil {
+		t.Fatalf("Apply() error: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "docs", "rfcs", "0007-rate-limits.md")); !os.IsNotExist(err) {
+		t.Error("move mode should remove the original")
+	}
+	info, err := os.Lstat(filepath.Join(specsDir, "003-rate-limits", "spec.md"))
+	if err != nil || !info.Mode().IsRegular() {
+		t.Errorf("moved spec should be a regular file: %v", err)
+	}
+}
+
+func TestApplyRefusesExistingFeature(t *testing.T) {
+	root := setupRepo(t)
+	specsDir := filepath.Join(root, ".maestro", "specs")
+	docs := []Document{{Source: "specs/auth.md", FeatureID: "002-existing"}}
+
+	adopted, err := Apply(root, docs, Move, specsDir, filepath.Join(root, ".maestro", "state"))
+	if err == nil || len(adopted) != 0 {
+		t.Fatalf("expected refusal, got %v, %v", adopted, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "specs", "auth.md")); err != nil {
+		t.Error("source should be untouched after refusal")
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"auth.md":               "auth",
+		"0007-rate-limits.md":   "rate-limits",
+		"RFC-12 Token Rotation": "token-rotation",
+		"2024.md":               "2024",
+		"Payments_V2":           "payments-v2",
+	}
+	for in, want := range tests {
+		if got := Slugify(in); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// Package agentreport summarizes what each actor (an agent or a person) did
+// in a project: the stage transitions recorded in the feature state history
+// and the bd tasks they closed.
+package agentreport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spec-maestro/maestro-cli/pkg/state"
+)
+
+// Unknown is the actor of history entries and tasks that don't name one.
+const Unknown = "unknown"
+
+// Transition is a feature moving to a new stage.
+type Transition struct {
+	Feature string
+	Stage   string
+	Actor   string
+	At      time.Time
+}
+
+// Task is a bd task as listed by 'bd list --json'.
+type Task struct {
+	ID        string    `json:"id"`
+	Status    string    `json:"status"`
+	Assignee  string    `json:"assignee"`
+	CreatedAt time.Time `json:"created_at"`
+	ClosedAt  time.Time `json:"closed_at"`
+}
+
+// Stats is the summary for one actor.
+type Stats struct {
+	Actor          string `json:"actor"`
+	TasksCompleted int    `json:"tasks_completed"`
+	Transitions    int    `json:"stage_transitions"`
+	// AvgTaskTime is the mean time from creating to closing the actor's
+	// completed tasks; zero when none has both timestamps.
+	AvgTaskTime time.Duration `json:"avg_task_seconds"`
+}
+
+// MarshalJSON reports AvgTaskTime in whole seconds.
+func (s Stats) MarshalJSON() ([]byte, error) {
+	type stats Stats
+	v := stats(s)
+	v.AvgTaskTime = s.AvgTaskTime / time.Second
+	return json.Marshal(v)
+}
+
+// ReadTransitions reads the stage transitions from the history of every
+// feature state file in stateDir. Consecutive entries for the same stage
+// are one transition. A file without a parseable history is skipped.
+func ReadTransitions(stateDir string) ([]Transition, error) {
+	paths, err := filepath.Glob(filepath.Join(stateDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var transitions []Transition
+	for _, path := range paths {
+		s, err := state.Load(path)
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		if err != nil {
+			continue
+		}
+		feature := s.FeatureID
+		if feature == "" {
+			feature = filepath.Base(path[:len(path)-len(".json")])
+		}
+		transitions = append(transitions, featureTransitions(feature, s.History)...)
+	}
+	return transitions, nil
+}
+
+// featureTransitions returns the transitions in a feature's history, or
+// none when a timestamp doesn't parse.
+func featureTransitions(feature string, history []state.Entry) []Transition {
+	var transitions []Transition
+	previous := ""
+	for _, entry := range history {
+		if entry.Stage == "" || entry.Stage == previous {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, entry.Timestamp)
+		if err != nil {
+			return nil
+		}
+		previous = entry.Stage
+		transitions = append(transitions, Transition{
+			Feature: feature,
+			Stage:   entry.Stage,
+			Actor:   actor(entry.Actor),
+			At:      at,
+		})
+	}
+	return transitions
+}
+
+// Summarize returns the stats of every actor that drove a transition or
+// closed a task, sorted by actor with Unknown last. since, when not zero,
+// leaves out transitions and tasks closed before it.
+func Summarize(transitions []Transition, tasks []Task, since time.Time) []Stats {
+	byActor := make(map[string]*Stats)
+	get := func(name string) *Stats {
+		if s, ok := byActor[name]; ok {
+			return s
+		}
+		s := &Stats{Actor: name}
+		byActor[name] = s
+		return s
+	}
+
+	for _, t := range transitions {
+		if !since.IsZero() && t.At.Before(since) {
+			continue
+		}
+		get(t.Actor).Transitions++
+	}
+
+	total := make(map[string]time.Duration)
+	timed := make(map[string]int)
+	for _, t := range tasks {
+		if t.Status != "closed" || (!since.IsZero() && t.ClosedAt.Before(since)) {
+			continue
+		}
+		name := actor(t.Assignee)
+		get(name).TasksCompleted++
+		if !t.CreatedAt.IsZero() && t.ClosedAt.After(t.CreatedAt) {
+			total[name] += t.ClosedAt.Sub(t.CreatedAt)
+			timed[name]++
+		}
+	}
+
+	stats := make([]Stats, 0, len(byActor))
+	for name, s := range byActor {
+		if timed[name] > 0 {
+			s.AvgTaskTime = total[name] / time.Duration(timed[name])
+		}
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if (stats[i].Actor == Unknown) != (stats[j].Actor == Unknown) {
+			return stats[j].Actor == Unknown
+		}
+		return stats[i].Actor < stats[j].Actor
+	})
+	return stats
+}
+
+func actor(name string) string {
+	if name == "" {
+		return Unknown
+	}
+	return name
+}
@@ -0,0 +1,75 @@
+package agentreport
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadTransitions(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "001-auth.json"), []byte(`{
+  "feature_id": "001-auth",
+  "history": [
+    {"stage": "specify", "timestamp": "2026-01-01T10:00:00Z", "action": "spec created", "actor": "claude"},
+    {"stage": "specify", "timestamp": "2026-01-01T10:05:00Z", "action": "spec revised", "actor": "claude"},
+    {"stage": "plan", "timestamp": "2026-01-02T09:00:00Z", "action": "plan generated"}
+  ]
+}`), 0644)
+	os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0644)
+
+	got, err := ReadTransitions(dir)
+	if err != nil {
+		t.Fatalf("ReadTransitions() error: %v", err)
+	}
+	want := []Transition{
+		{Feature: "001-auth", Stage: "specify", Actor: "claude", At: time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)},
+		{Feature: "001-auth", Stage: "plan", Actor: Unknown, At: time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadTransitions() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	day := func(d, h int) time.Time { return time.Date(2026, 1, d, h, 0, 0, 0, time.UTC) }
+	transitions := []Transition{
+		{Actor: "claude", At: day(1, 10)},
+		{Actor: "claude", At: day(3, 10)},
+		{Actor: Unknown, At: day(3, 11)},
+	}
+	tasks := []Task{
+		{ID: "a", Status: "closed", Assignee: "codex", CreatedAt: day(2, 9), ClosedAt: day(2, 10)},
+		{ID: "b", Status: "closed", Assignee: "codex", CreatedAt: day(2, 9), ClosedAt: day(2, 12)},
+		{ID: "c", Status: "open", Assignee: "codex", CreatedAt: day(2, 9)},
+		{ID: "d", Status: "closed", Assignee: "claude", ClosedAt: day(3, 9)},
+	}
+
+	got := Summarize(transitions, tasks, time.Time{})
+	want := []Stats{
+		{Actor: "claude", TasksCompleted: 1, Transitions: 2},
+		{Actor: "codex", TasksCompleted: 2, AvgTaskTime: 2 * time.Hour},
+		{Actor: Unknown, Transitions: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Summarize() = %+v, want %+v", got, want)
+	}
+
+	got = Summarize(transitions, tasks, day(3, 0))
+	want = []Stats{
+		{Actor: "claude", TasksCompleted: 1, Transitions: 1},
+		{Actor: Unknown, Transitions: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Summarize(since) = %+v, want %+v", got, want)
+	}
+
+	data, _ := json.Marshal(Stats{Actor: "codex", AvgTaskTime: 90 * time.Minute})
+	if !strings.Contains(string(data), `"avg_task_seconds":5400`) {
+		t.Errorf("JSON should report seconds: %s", data)
+	}
+}
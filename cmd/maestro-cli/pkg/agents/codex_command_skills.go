@@ -48,6 +48,17 @@ func CodexCommandSkillName(commandName string) string {
 	return "maestro-" + strings.ReplaceAll(suffix, ".", "-")
 }
 
+// CodexCommandSkillPath returns the generated skill path for a command
+// markdown path, e.g. commands/maestro.list.md -> skills/maestro-list/SKILL.md.
+// The boolean is false when the path is not a Maestro command file.
+func CodexCommandSkillPath(commandPath string) (string, bool) {
+	commandName, ok := codexCommandNameFromPath(commandPath)
+	if !ok {
+		return "", false
+	}
+	return path.Join("skills", CodexCommandSkillName(commandName), "SKILL.md"), true
+}
+
 func codexCommandNameFromPath(filePath string) (string, bool) {
 	normalized := path.Clean(strings.ReplaceAll(filePath, "\\", "/"))
 	if !strings.HasPrefix(normalized, "commands/maestro.") || !strings.HasSuffix(normalized, ".md") {
@@ -0,0 +1,105 @@
+package agents
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// agentNames maps agent directory names to the agent that reads them.
+var agentNames = map[string]string{
+	".opencode": "OpenCode",
+	".claude":   "Claude Code",
+	".codex":    "Codex CLI",
+}
+
+// AgentName returns the display name of the agent that reads dir.
+func AgentName(dir string) string {
+	if name, ok := agentNames[dir]; ok {
+		return name
+	}
+	return dir
+}
+
+// instructionFiles maps agent directory names to the instruction file the
+// agent reads. Codex CLI reads AGENTS.md itself, so it has none.
+var instructionFiles = map[string]string{
+	".opencode": ".opencode/AGENTS.md",
+	".claude":   "CLAUDE.md",
+}
+
+// InstructionFile returns the slash-separated path, relative to the project
+// root, of the agent-specific instruction file for dir, or "" when the
+// agent reads AGENTS.md.
+func InstructionFile(dir string) string {
+	return instructionFiles[dir]
+}
+
+// AgentCommand is a maestro command as typed in a particular agent.
+type AgentCommand struct {
+	Invocation   string // e.g. /maestro.specify, or $maestro-specify in Codex
+	ArgumentHint string
+	Description  string
+}
+
+// InstalledCommands reads the maestro command files installed in agentDir
+// of the project at root and returns how to invoke each one there, sorted by invocation. Claude
+// Code and OpenCode run them as slash commands; Codex runs the generated
+// command skills.
+func InstalledCommands(root, agentDir string) ([]AgentCommand, error) {
+	paths, err := filepath.Glob(filepath.Join(root, agentDir, "commands", "maestro.*.md"))
+	if err != nil {
+		return nil, err
+	}
+
+	commands := make([]AgentCommand, 0, len(paths))
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+
+		name := strings.TrimSuffix(filepath.Base(path), ".md")
+		invocation := "/" + name
+		if agentDir == ".codex" {
+			invocation = "$" + CodexCommandSkillName(name)
+		}
+		commands = append(commands, AgentCommand{
+			Invocation:   invocation,
+			ArgumentHint: extractFrontmatterValue(content, "argument-hint"),
+			Description:  extractCommandDescription(content),
+		})
+	}
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Invocation < commands[j].Invocation
+	})
+	return commands, nil
+}
+
+// Usage returns the invocation followed by its argument hint, if any.
+func (c AgentCommand) Usage() string {
+	if c.ArgumentHint == "" {
+		return c.Invocation
+	}
+	return c.Invocation + " " + c.ArgumentHint
+}
+
+// extractFrontmatterValue returns a single-line frontmatter value.
+func extractFrontmatterValue(content []byte, key string) string {
+	lines := strings.Split(string(content), "\n")
+	if len(lines) < 3 || strings.TrimSpace(lines[0]) != "---" {
+		return ""
+	}
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if line == "---" {
+			break
+		}
+		if value, ok := strings.CutPrefix(line, key+":"); ok {
+			return strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package agents
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInstalledCommands(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	specify := "---\ndescription: Write a spec.\nargument-hint: <feature description>\n---\n\n# Specify\n"
+	for _, dir := range []string{".claude", ".codex"} {
+		dir := filepath.Join(root, dir)
+		os.MkdirAll(filepath.Join(dir, "commands"), 0755)
+		os.WriteFile(filepath.Join(dir, "commands", "maestro.specify.md"), []byte(specify), 0644)
+		os.WriteFile(filepath.Join(dir, "commands", "maestro.research.list.md"), []byte("# List\n"), 0644)
+		os.WriteFile(filepath.Join(dir, "commands", "notes.md"), []byte("not a command\n"), 0644)
+	}
+
+	commands, err := InstalledCommands(root, ".claude")
+	if err != nil {
+		t.Fatalf("InstalledCommands error: %v", err)
+	}
+	if len(commands) != 2 {
+		t.Fatalf("expected 2 commands, got %+v", commands)
+	}
+	if commands[0].Invocation != "/maestro.research.list" || commands[0].Usage() != "/maestro.research.list" {
+		t.Errorf("command 0 = %+v", commands[0])
+	}
+	if got := commands[1]; got.Usage() != "/maestro.specify <feature description>" || got.Description != "Write a spec." {
+		t.Errorf("command 1 = %+v", got)
+	}
+
+	commands, err = InstalledCommands(root, ".codex")
+	if err != nil {
+		t.Fatalf("InstalledCommands error: %v", err)
+	}
+	if len(commands) != 2 || commands[0].Invocation != "$maestro-research-list" || commands[1].Invocation != "$maestro-specify" {
+		t.Errorf("codex commands should be skill mentions, got %+v", commands)
+	}
+}
+
+func TestAgentName(t *testing.T) {
+	if AgentName(".claude") != "Claude Code" || AgentName(".other") != ".other" {
+		t.Error("unexpected agent names")
+	}
+}
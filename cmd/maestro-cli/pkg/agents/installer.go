@@ -18,15 +18,30 @@ type InstallResult struct {
 type rollbackState struct {
 	originalPath string
 	backupPath   string
+	temporary    bool // removed once the install succeeds
 }
 
-// InstallRequiredAssets installs all required directories as one transaction.
+// InstallRequiredAssets installs all required directories into the project
+// at root as one transaction. The directories are relative to root.
 //
 // Behavior:
 //   - Fetch all required content before writing anything.
 //   - Apply one global conflict action across all conflicting dirs.
 //   - On any failure, rollback to the pre-install filesystem state for required dirs.
-func InstallRequiredAssets(requiredDirs []string, action ConflictAction, fetch AssetFetcher) (*InstallResult, error) {
+func InstallRequiredAssets(root string, requiredDirs []string, action ConflictAction, fetch AssetFetcher) (*InstallResult, error) {
+	actions := make(map[string]ConflictAction, len(requiredDirs))
+	for _, dir := range requiredDirs {
+		actions[dir] = action
+	}
+	return InstallRequiredAssetsEach(root, requiredDirs, actions, fetch)
+}
+
+// InstallRequiredAssetsEach is InstallRequiredAssets with a conflict action
+// per directory. A conflicting directory whose action is ConflictCancel is
+// left as it is; when every conflicting directory is, the install is
+// cancelled. Directories missing from actions are overwritten. Backups are
+// reported relative to root.
+func InstallRequiredAssetsEach(root string, requiredDirs []string, actions map[string]ConflictAction, fetch AssetFetcher) (*InstallResult, error) {
 	if len(requiredDirs) == 0 {
 		return &InstallResult{}, nil
 	}
@@ -35,6 +50,29 @@ func InstallRequiredAssets(requiredDirs []string, action ConflictAction, fetch A
 		return nil, fmt.Errorf("fetcher is required")
 	}
 
+	// Leave the conflicting directories whose action is cancel as they are
+	conflicting := []string{}
+	kept := make(map[string]bool)
+	for _, dir := range detectConflictingDirs(root, requiredDirs) {
+		if actions[dir] == ConflictCancel {
+			kept[dir] = true
+		} else {
+			conflicting = append(conflicting, dir)
+		}
+	}
+	if len(kept) > 0 && len(conflicting) == 0 {
+		return nil, fmt.Errorf("required starter asset installation cancelled")
+	}
+	if len(kept) > 0 {
+		remaining := make([]string, 0, len(requiredDirs))
+		for _, dir := range requiredDirs {
+			if !kept[dir] {
+				remaining = append(remaining, dir)
+			}
+		}
+		requiredDirs = remaining
+	}
+
 	staged := make(map[string]map[string][]byte, len(requiredDirs))
 	for _, dir := range requiredDirs {
 		content, err := fetch(dir)
@@ -44,16 +82,15 @@ func InstallRequiredAssets(requiredDirs []string, action ConflictAction, fetch A
 		staged[dir] = content
 	}
 
-	conflicting := detectConflictingDirs(requiredDirs)
-	rollbackBackups, userBackups, err := prepareConflictAction(conflicting, action)
+	rollbackBackups, userBackups, err := prepareConflictAction(root, conflicting, actions)
 	if err != nil {
 		return nil, err
 	}
 
 	installed := make([]string, 0, len(requiredDirs))
 	for _, dir := range requiredDirs {
-		if err := WriteAgentDir(staged[dir], dir); err != nil {
-			if rollbackErr := rollbackRequiredInstall(requiredDirs, rollbackBackups); rollbackErr != nil {
+		if err := WriteAgentDir(root, staged[dir], dir); err != nil {
+			if rollbackErr := rollbackRequiredInstall(root, requiredDirs, rollbackBackups); rollbackErr != nil {
 				return nil, fmt.Errorf("writing required starter assets: %v (rollback failed: %w)", err, rollbackErr)
 			}
 			return nil, fmt.Errorf("writing required starter assets for %s: %w", dir, err)
@@ -61,8 +98,8 @@ func InstallRequiredAssets(requiredDirs []string, action ConflictAction, fetch A
 		installed = append(installed, dir)
 	}
 
-	if err := finalizeRollbackBackups(rollbackBackups, action); err != nil {
-		if rollbackErr := rollbackRequiredInstall(requiredDirs, rollbackBackups); rollbackErr != nil {
+	if err := finalizeRollbackBackups(rollbackBackups); err != nil {
+		if rollbackErr := rollbackRequiredInstall(root, requiredDirs, rollbackBackups); rollbackErr != nil {
 			return nil, fmt.Errorf("finalizing required starter assets: %v (rollback failed: %w)", err, rollbackErr)
 		}
 		return nil, err
@@ -74,30 +111,27 @@ func InstallRequiredAssets(requiredDirs []string, action ConflictAction, fetch A
 	}, nil
 }
 
-func detectConflictingDirs(requiredDirs []string) []string {
+func detectConflictingDirs(root string, requiredDirs []string) []string {
 	conflicting := make([]string, 0, len(requiredDirs))
 	for _, dir := range requiredDirs {
-		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		if info, err := os.Stat(inRoot(root, dir)); err == nil && info.IsDir() {
 			conflicting = append(conflicting, dir)
 		}
 	}
 	return conflicting
 }
 
-func prepareConflictAction(conflicting []string, action ConflictAction) ([]rollbackState, []string, error) {
+func prepareConflictAction(root string, conflicting []string, actions map[string]ConflictAction) ([]rollbackState, []string, error) {
 	if len(conflicting) == 0 {
 		return nil, nil, nil
 	}
 
-	if action == ConflictCancel {
-		return nil, nil, fmt.Errorf("required starter asset installation cancelled")
-	}
-
 	rollbackBackups := make([]rollbackState, 0, len(conflicting))
 	userBackups := []string{}
 
-	for _, dir := range conflicting {
-		switch action {
+	for _, name := range conflicting {
+		dir := inRoot(root, name)
+		switch action := actions[name]; action {
 		case ConflictBackup:
 			backupPath, err := BackupDir(dir)
 			if err != nil {
@@ -108,6 +142,9 @@ func prepareConflictAction(conflicting []string, action ConflictAction) ([]rollb
 			}
 
 			rollbackBackups = append(rollbackBackups, rollbackState{originalPath: dir, backupPath: backupPath})
+			if rel, err := filepath.Rel(root, backupPath); err == nil && !filepath.IsAbs(name) {
+				backupPath = rel
+			}
 			userBackups = append(userBackups, backupPath)
 		case ConflictOverwrite:
 			tempBackupPath, err := tempBackupPathFor(dir)
@@ -121,7 +158,7 @@ func prepareConflictAction(conflicting []string, action ConflictAction) ([]rollb
 				return nil, nil, fmt.Errorf("preparing overwrite for %s: %w", dir, err)
 			}
 
-			rollbackBackups = append(rollbackBackups, rollbackState{originalPath: dir, backupPath: tempBackupPath})
+			rollbackBackups = append(rollbackBackups, rollbackState{originalPath: dir, backupPath: tempBackupPath, temporary: true})
 		default:
 			return nil, nil, fmt.Errorf("unknown conflict action: %v", action)
 		}
@@ -140,12 +177,11 @@ func tempBackupPathFor(dir string) (string, error) {
 	return filepath.Join(tmpDir, base), nil
 }
 
-func finalizeRollbackBackups(backups []rollbackState, action ConflictAction) error {
-	if action != ConflictOverwrite {
-		return nil
-	}
-
+func finalizeRollbackBackups(backups []rollbackState) error {
 	for _, state := range backups {
+		if !state.temporary {
+			continue
+		}
 		if err := os.RemoveAll(filepath.Dir(state.backupPath)); err != nil {
 			return fmt.Errorf("removing temporary backup %s: %w", state.backupPath, err)
 		}
@@ -154,9 +190,9 @@ func finalizeRollbackBackups(backups []rollbackState, action ConflictAction) err
 	return nil
 }
 
-func rollbackRequiredInstall(requiredDirs []string, backups []rollbackState) error {
+func rollbackRequiredInstall(root string, requiredDirs []string, backups []rollbackState) error {
 	for _, dir := range requiredDirs {
-		if err := os.RemoveAll(dir); err != nil {
+		if err := os.RemoveAll(inRoot(root, dir)); err != nil {
 			return fmt.Errorf("removing partial required directory %s: %w", dir, err)
 		}
 	}
@@ -22,7 +22,7 @@ func TestInstallRequiredAssets_Success(t *testing.T) {
 		}, nil
 	}
 
-	result, err := InstallRequiredAssets(required, ConflictOverwrite, fetch)
+	result, err := InstallRequiredAssets(root, required, ConflictOverwrite, fetch)
 	if err != nil {
 		t.Fatalf("InstallRequiredAssets failed: %v", err)
 	}
@@ -57,7 +57,7 @@ func TestInstallRequiredAssets_FetchFailureNoWrites(t *testing.T) {
 		return map[string][]byte{"ok.txt": []byte("ok")}, nil
 	}
 
-	_, err := InstallRequiredAssets(required, ConflictOverwrite, fetch)
+	_, err := InstallRequiredAssets(root, required, ConflictOverwrite, fetch)
 	if err == nil {
 		t.Fatal("expected fetch failure")
 	}
@@ -84,7 +84,7 @@ func TestInstallRequiredAssets_WriteFailureRollsBack(t *testing.T) {
 		return map[string][]byte{"ok.txt": []byte("ok")}, nil
 	}
 
-	_, err := InstallRequiredAssets(required, ConflictOverwrite, fetch)
+	_, err := InstallRequiredAssets(root, required, ConflictOverwrite, fetch)
 	if err == nil {
 		t.Fatal("expected write failure")
 	}
@@ -95,3 +95,49 @@ func TestInstallRequiredAssets_WriteFailureRollsBack(t *testing.T) {
 		}
 	}
 }
+
+func TestInstallRequiredAssetsEach_PerDirectoryActions(t *testing.T) {
+	root := t.TempDir()
+	scripts := filepath.Join(root, ".maestro", "scripts")
+	skills := filepath.Join(root, ".maestro", "skills")
+	templates := filepath.Join(root, ".maestro", "templates")
+	for _, dir := range []string{scripts, skills, templates} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "local.txt"), []byte("local"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fetch := func(dir string) (map[string][]byte, error) {
+		return map[string][]byte{"ok.txt": []byte("ok")}, nil
+	}
+	actions := map[string]ConflictAction{
+		scripts:   ConflictOverwrite,
+		skills:    ConflictBackup,
+		templates: ConflictCancel,
+	}
+
+	result, err := InstallRequiredAssetsEach(root, []string{scripts, skills, templates}, actions, fetch)
+	if err != nil {
+		t.Fatalf("InstallRequiredAssetsEach failed: %v", err)
+	}
+	if len(result.Installed) != 2 || len(result.Backups) != 1 {
+		t.Fatalf("expected 2 installed and 1 backup, got %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(scripts, "local.txt")); !os.IsNotExist(err) {
+		t.Errorf("overwritten directory should not keep local files, stat err: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(result.Backups[0], "local.txt")); err != nil {
+		t.Errorf("backup should hold the local files: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(templates, "ok.txt")); !os.IsNotExist(err) {
+		t.Errorf("cancelled directory should be left alone, stat err: %v", err)
+	}
+
+	actions = map[string]ConflictAction{scripts: ConflictCancel, skills: ConflictCancel, templates: ConflictCancel}
+	if _, err := InstallRequiredAssetsEach(root, []string{scripts, skills, templates}, actions, fetch); err == nil {
+		t.Error("cancelling every conflicting directory should cancel the install")
+	}
+}
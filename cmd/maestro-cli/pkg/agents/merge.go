@@ -0,0 +1,111 @@
+package agents
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spec-maestro/maestro-cli/pkg/newline"
+	"github.com/spec-maestro/maestro-cli/pkg/safepath"
+)
+
+// MergeResult lists, by slash-separated path, what MergeAssets did with each
+// file it was given.
+type MergeResult struct {
+	Added     []string // missing on disk; written
+	Updated   []string // unchanged since install; replaced with the new version
+	Unchanged []string // already identical to the new version
+	Kept      []string // modified by the user, or never recorded; left alone
+}
+
+// Current returns the files that now match the installed version.
+func (r *MergeResult) Current() []string {
+	current := append(append(append([]string{}, r.Added...), r.Updated...), r.Unchanged...)
+	sort.Strings(current)
+	return current
+}
+
+// Append adds other's files to r.
+func (r *MergeResult) Append(other *MergeResult) {
+	r.Added = append(r.Added, other.Added...)
+	r.Updated = append(r.Updated, other.Updated...)
+	r.Unchanged = append(r.Unchanged, other.Unchanged...)
+	r.Kept = append(r.Kept, other.Kept...)
+}
+
+// MergeAssets installs dirs into the project at root without touching files
+// the user changed. recorded maps paths to the sha256 recorded when they were
+// last installed: a file whose content still has that checksum is updated, a
+// missing file is added, and any other file is kept as is. All content is
+// fetched before anything is written. dirs and the paths in the result are
+// relative to root.
+func MergeAssets(root string, dirs []string, fetch AssetFetcher, recorded map[string]string) (*MergeResult, error) {
+	if fetch == nil {
+		return nil, fmt.Errorf("fetcher is required")
+	}
+
+	staged := make(map[string]map[string][]byte, len(dirs))
+	for _, dir := range dirs {
+		content, err := fetch(dir)
+		if err != nil {
+			return nil, fmt.Errorf("fetching %s: %w", dir, err)
+		}
+		staged[dir] = content
+	}
+
+	result := &MergeResult{}
+	for _, dir := range dirs {
+		target, err := filepath.Abs(inRoot(root, dir))
+		if err != nil {
+			return nil, fmt.Errorf("resolving %s: %w", dir, err)
+		}
+
+		relPaths := make([]string, 0, len(staged[dir]))
+		for relPath := range staged[dir] {
+			relPaths = append(relPaths, relPath)
+		}
+		sort.Strings(relPaths)
+
+		for _, relPath := range relPaths {
+			fullPath, err := safepath.Join(target, relPath)
+			if err != nil {
+				return nil, err
+			}
+			name := filepath.ToSlash(filepath.Join(dir, relPath))
+			data := newline.Apply(relPath, staged[dir][relPath])
+
+			existing, err := os.ReadFile(fullPath)
+			switch {
+			case os.IsNotExist(err):
+				result.Added = append(result.Added, name)
+			case err != nil:
+				return nil, fmt.Errorf("reading %s: %w", name, err)
+			case bytes.Equal(existing, data):
+				result.Unchanged = append(result.Unchanged, name)
+				continue
+			case recorded[name] != "" && recorded[name] == checksum(existing):
+				result.Updated = append(result.Updated, name)
+			default:
+				result.Kept = append(result.Kept, name)
+				continue
+			}
+
+			if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+				return nil, fmt.Errorf("creating directory for %s: %w", name, err)
+			}
+			if err := writeFileAtomic(fullPath, data); err != nil {
+				return nil, fmt.Errorf("writing %s: %w", name, err)
+			}
+		}
+	}
+	return result, nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,72 @@
+package agents
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMergeAssets(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	dir := filepath.Join(root, "scripts")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "pristine.sh"), []byte("v1"), 0644)
+	os.WriteFile(filepath.Join(dir, "modified.sh"), []byte("customized"), 0644)
+	os.WriteFile(filepath.Join(dir, "same.sh"), []byte("v2"), 0644)
+	os.WriteFile(filepath.Join(dir, "unrecorded.sh"), []byte("v1"), 0644)
+
+	fetch := func(string) (map[string][]byte, error) {
+		return map[string][]byte{
+			"pristine.sh":   []byte("v2"),
+			"modified.sh":   []byte("v2"),
+			"same.sh":       []byte("v2"),
+			"unrecorded.sh": []byte("v2"),
+			"new/added.sh":  []byte("v2"),
+		}, nil
+	}
+	name := func(rel string) string { return "scripts/" + rel }
+	recorded := map[string]string{
+		name("pristine.sh"): checksum([]byte("v1")),
+		name("modified.sh"): checksum([]byte("v1")),
+	}
+
+	result, err := MergeAssets(root, []string{"scripts"}, fetch, recorded)
+	if err != nil {
+		t.Fatalf("MergeAssets failed: %v", err)
+	}
+
+	want := map[string][]string{
+		"added":     {name("new/added.sh")},
+		"updated":   {name("pristine.sh")},
+		"unchanged": {name("same.sh")},
+		"kept":      {name("modified.sh"), name("unrecorded.sh")},
+	}
+	got := map[string][]string{"added": result.Added, "updated": result.Updated, "unchanged": result.Unchanged, "kept": result.Kept}
+	for key, files := range want {
+		if len(got[key]) != len(files) {
+			t.Errorf("%s = %v, want %v", key, got[key], files)
+			continue
+		}
+		for i := range files {
+			if got[key][i] != files[i] {
+				t.Errorf("%s = %v, want %v", key, got[key], files)
+			}
+		}
+	}
+
+	for rel, content := range map[string]string{
+		"pristine.sh":   "v2",
+		"modified.sh":   "customized",
+		"unrecorded.sh": "v1",
+		"new/added.sh":  "v2",
+	} {
+		data, _ := os.ReadFile(filepath.Join(dir, rel))
+		if string(data) != content {
+			t.Errorf("%s = %q, want %q", rel, data, content)
+		}
+	}
+	if len(result.Current()) != 3 {
+		t.Errorf("Current() = %v", result.Current())
+	}
+}
@@ -0,0 +1,98 @@
+package agents
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spec-maestro/maestro-cli/pkg/newline"
+	"github.com/spec-maestro/maestro-cli/pkg/safepath"
+)
+
+// FileChange is what installing a file would do to the file on disk.
+type FileChange string
+
+const (
+	ChangeCreate    FileChange = "create"
+	ChangeOverwrite FileChange = "overwrite"
+	ChangeUnchanged FileChange = "unchanged"
+	// ChangeRemove is a file an update deletes because the new release no
+	// longer has it.
+	ChangeRemove FileChange = "remove"
+)
+
+// PlannedFile is one file an install would write or remove.
+type PlannedFile struct {
+	Path   string     `json:"path"`
+	Change FileChange `json:"change"`
+}
+
+// PlanFile compares data with the file at path, relative to the project
+// root, applying the line-ending policy the same way an install would.
+func PlanFile(root, path string, data []byte) (PlannedFile, error) {
+	planned := PlannedFile{Path: filepath.ToSlash(path), Change: ChangeCreate}
+	existing, err := os.ReadFile(inRoot(root, path))
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return planned, fmt.Errorf("reading %s: %w", path, err)
+	case bytes.Equal(existing, newline.Apply(path, data)):
+		planned.Change = ChangeUnchanged
+	default:
+		planned.Change = ChangeOverwrite
+	}
+	return planned, nil
+}
+
+// PlanDir lists what writing content, keyed by slash-separated paths
+// relative to dir, would do to each file, sorted by path. dir is relative
+// to the project root. Nothing is written.
+func PlanDir(root, dir string, content map[string][]byte) ([]PlannedFile, error) {
+	target, err := filepath.Abs(inRoot(root, dir))
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", dir, err)
+	}
+
+	relPaths := make([]string, 0, len(content))
+	for relPath := range content {
+		relPaths = append(relPaths, relPath)
+	}
+	sort.Strings(relPaths)
+
+	planned := make([]PlannedFile, 0, len(relPaths))
+	for _, relPath := range relPaths {
+		if _, err := safepath.Join(target, relPath); err != nil {
+			return nil, err
+		}
+		file, err := PlanFile(root, filepath.Join(dir, relPath), content[relPath])
+		if err != nil {
+			return nil, err
+		}
+		planned = append(planned, file)
+	}
+	return planned, nil
+}
+
+// PlanAssets fetches dirs and lists what installing them into the project
+// at root would do.
+func PlanAssets(root string, dirs []string, fetch AssetFetcher) ([]PlannedFile, error) {
+	if fetch == nil {
+		return nil, fmt.Errorf("fetcher is required")
+	}
+
+	var planned []PlannedFile
+	for _, dir := range dirs {
+		content, err := fetch(dir)
+		if err != nil {
+			return nil, fmt.Errorf("fetching %s: %w", dir, err)
+		}
+		files, err := PlanDir(root, dir, content)
+		if err != nil {
+			return nil, err
+		}
+		planned = append(planned, files...)
+	}
+	return planned, nil
+}
@@ -0,0 +1,51 @@
+package agents
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlanAssets(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	commands := filepath.Join(root, ".claude", "commands")
+	os.MkdirAll(commands, 0755)
+	os.WriteFile(filepath.Join(commands, "same.md"), []byte("same\n"), 0644)
+	os.WriteFile(filepath.Join(commands, "edited.md"), []byte("mine\n"), 0644)
+
+	fetch := func(string) (map[string][]byte, error) {
+		return map[string][]byte{
+			"commands/same.md":   []byte("same\n"),
+			"commands/edited.md": []byte("theirs\n"),
+			"commands/new.md":    []byte("new\n"),
+		}, nil
+	}
+	planned, err := PlanAssets(root, []string{".claude"}, fetch)
+	if err != nil {
+		t.Fatalf("PlanAssets() error: %v", err)
+	}
+
+	want := []PlannedFile{
+		{Path: ".claude/commands/edited.md", Change: ChangeOverwrite},
+		{Path: ".claude/commands/new.md", Change: ChangeCreate},
+		{Path: ".claude/commands/same.md", Change: ChangeUnchanged},
+	}
+	if len(planned) != len(want) {
+		t.Fatalf("planned = %+v, want %+v", planned, want)
+	}
+	for i := range want {
+		if planned[i] != want[i] {
+			t.Errorf("planned[%d] = %+v, want %+v", i, planned[i], want[i])
+		}
+	}
+	if _, err := os.Stat(filepath.Join(commands, "new.md")); !os.IsNotExist(err) {
+		t.Error("PlanAssets should not write files")
+	}
+}
+
+func TestPlanDirRejectsUnsafePaths(t *testing.T) {
+	if _, err := PlanDir(t.TempDir(), ".", map[string][]byte{"../escape.md": []byte("x")}); err == nil {
+		t.Error("expected error for path escaping the directory")
+	}
+}
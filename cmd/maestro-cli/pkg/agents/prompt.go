@@ -2,6 +2,7 @@ package agents
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"strconv"
@@ -16,6 +17,9 @@ const (
 	ConflictOverwrite ConflictAction = iota
 	ConflictBackup
 	ConflictCancel
+	// ConflictMerge adds missing files and leaves existing ones the user
+	// changed alone. It is only offered when re-initializing .maestro/.
+	ConflictMerge
 )
 
 // agentDescriptions maps agent directory names to their descriptions
@@ -25,56 +29,176 @@ var agentDescriptions = map[string]string{
 	".codex":    "slash commands and skills for Codex CLI",
 }
 
+// MaxPromptAttempts is how many times a prompt asks again after invalid
+// input before giving up.
+const MaxPromptAttempts = 3
+
 // PromptAgentSelection presents a multi-select prompt listing available
 // agent config directories. Returns the user's selections.
 // Empty selection (Enter with no input) returns an empty slice.
+// Invalid input is explained and asked for again, up to MaxPromptAttempts.
 // Available is typically KnownAgentDirs().
 func PromptAgentSelection(r io.Reader, w io.Writer, available []string) ([]string, error) {
-	if len(available) == 0 {
-		return []string{}, nil
-	}
+	return PromptAgentSelectionWithDefaults(r, w, available, nil)
+}
 
-	fmt.Fprintln(w, "The following agent config directories are available:")
-	for i, dir := range available {
+// PromptAgentSelectionWithDefaults is PromptAgentSelection with some
+// directories pre-selected, typically the ones already installed: they are
+// marked in the list and returned when the user just presses Enter.
+// Besides numbers, the prompt accepts ranges (1-3), all, and none.
+func PromptAgentSelectionWithDefaults(r io.Reader, w io.Writer, available, preselected []string) ([]string, error) {
+	descriptions := make(map[string]string, len(available))
+	for _, dir := range available {
 		desc := agentDescriptions[dir]
 		if desc == "" {
 			desc = "agent configuration"
 		}
-		fmt.Fprintf(w, "  [%d] %s  (%s)\n", i+1, dir, desc)
+		descriptions[dir] = desc
+	}
+	return PromptChecklist(r, w, Checklist{
+		Title:        "The following agent config directories are available:",
+		Items:        available,
+		Descriptions: descriptions,
+		Preselected:  preselected,
+		Marker:       "installed",
+		Verb:         "install",
+		DefaultVerb:  "keep",
+	})
+}
+
+// Checklist is a numbered multi-select prompt.
+type Checklist struct {
+	// Title is printed above the items.
+	Title        string
+	Items        []string
+	Descriptions map[string]string
+	// Preselected items are marked with Marker and returned when the user
+	// just presses Enter.
+	Preselected []string
+	Marker      string
+	// Verb says what selecting does, e.g. install, and DefaultVerb what
+	// Enter does with the preselected items, e.g. keep.
+	Verb        string
+	DefaultVerb string
+}
+
+// PromptChecklist asks which of c.Items to select by number, range (1-3),
+// all, or none. Invalid input is explained and asked for again, up to
+// MaxPromptAttempts.
+func PromptChecklist(r io.Reader, w io.Writer, c Checklist) ([]string, error) {
+	if len(c.Items) == 0 {
+		return []string{}, nil
+	}
+
+	isPreselected := make(map[string]bool, len(c.Preselected))
+	var defaults []string
+	var defaultNums []string
+	for _, item := range c.Preselected {
+		isPreselected[item] = true
+	}
+	for i, item := range c.Items {
+		if isPreselected[item] {
+			defaults = append(defaults, item)
+			defaultNums = append(defaultNums, strconv.Itoa(i+1))
+		}
+	}
+
+	fmt.Fprintln(w, c.Title)
+	for i, item := range c.Items {
+		desc := ""
+		if d := c.Descriptions[item]; d != "" {
+			desc = "  (" + d + ")"
+		}
+		marker := ""
+		if isPreselected[item] && c.Marker != "" {
+			marker = " [" + c.Marker + "]"
+		}
+		fmt.Fprintf(w, "  [%d] %s%s%s\n", i+1, item, desc, marker)
 	}
 	fmt.Fprintln(w, "")
-	fmt.Fprint(w, "Enter numbers to install (e.g. 1 2), or press Enter to skip: ")
+
+	question := fmt.Sprintf("Enter numbers or ranges to %s (e.g. 1 2, 1-3, all, none), or press Enter to skip: ", c.Verb)
+	if len(defaults) > 0 {
+		question = fmt.Sprintf("Enter numbers or ranges to %s (e.g. 1 2, 1-3, all, none), or press Enter to %s %s: ", c.Verb, c.DefaultVerb, strings.Join(defaultNums, " "))
+	}
 
 	reader := bufio.NewReader(r)
-	input, err := reader.ReadString('\n')
-	if err != nil {
-		return nil, fmt.Errorf("reading input: %w", err)
+	var lastErr error
+	for attempt := 1; attempt <= MaxPromptAttempts; attempt++ {
+		fmt.Fprint(w, question)
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("reading input: %w", err)
+		}
+
+		input = strings.TrimSpace(input)
+		if input == "" {
+			return append([]string{}, defaults...), nil
+		}
+		selected, err := parseAgentSelection(input, c.Items)
+		if err == nil {
+			return selected, nil
+		}
+		lastErr = err
+		if attempt < MaxPromptAttempts {
+			fmt.Fprintf(w, "Invalid selection: %v. Enter numbers or ranges between 1 and %d, all, or none.\n", err, len(c.Items))
+		}
 	}
+	return nil, fmt.Errorf("no valid selection after %d attempts: %w", MaxPromptAttempts, lastErr)
+}
 
-	input = strings.TrimSpace(input)
-	if input == "" {
-		return []string{}, nil
+// parseAgentSelection converts numbers and ranges separated by spaces or
+// commas, or the keywords all and none, to the selected directories,
+// ignoring duplicates.
+func parseAgentSelection(input string, available []string) ([]string, error) {
+	fields := strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	if len(fields) == 1 {
+		switch fields[0] {
+		case "all":
+			return append([]string{}, available...), nil
+		case "none":
+			return []string{}, nil
+		}
 	}
 
-	// Parse the input numbers
-	parts := strings.Fields(input)
 	selected := []string{}
 	seen := make(map[int]bool)
+	for _, part := range fields {
+		if part == "all" || part == "none" {
+			return nil, fmt.Errorf("'%s' cannot be combined with numbers", part)
+		}
 
-	for _, part := range parts {
-		num, err := strconv.Atoi(part)
+		lo, hi, isRange := strings.Cut(part, "-")
+		if !isRange {
+			hi = lo
+		}
+		first, err := strconv.Atoi(lo)
 		if err != nil {
-			return nil, fmt.Errorf("invalid number '%s': %w", part, err)
+			return nil, fmt.Errorf("'%s' is not a number", part)
 		}
-		if num < 1 || num > len(available) {
-			return nil, fmt.Errorf("number %d is out of range (1-%d)", num, len(available))
+		last, err := strconv.Atoi(hi)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a number or range", part)
 		}
-		if !seen[num] {
-			seen[num] = true
-			selected = append(selected, available[num-1])
+		if first > last {
+			return nil, fmt.Errorf("range %s is backwards", part)
+		}
+		if first < 1 || last > len(available) {
+			if isRange {
+				return nil, fmt.Errorf("range %s is out of range (1-%d)", part, len(available))
+			}
+			return nil, fmt.Errorf("number %d is out of range (1-%d)", first, len(available))
 		}
-	}
 
+		for num := first; num <= last; num++ {
+			if !seen[num] {
+				seen[num] = true
+				selected = append(selected, available[num-1])
+			}
+		}
+	}
 	return selected, nil
 }
 
@@ -82,10 +206,83 @@ func PromptAgentSelection(r io.Reader, w io.Writer, available []string) ([]strin
 // [o]verwrite / [b]ackup / [c]ancel for all conflicting dirs at once.
 // conflicting is the list of dirs that already exist.
 func PromptConflictResolution(r io.Reader, w io.Writer, conflicting []string) (ConflictAction, error) {
+	return promptConflictResolution(r, w, conflicting, false)
+}
+
+// PromptReinitResolution is PromptConflictResolution with a fourth choice,
+// [m]erge, for re-initializing a project that already has .maestro/.
+func PromptReinitResolution(r io.Reader, w io.Writer, conflicting []string) (ConflictAction, error) {
+	return promptConflictResolution(r, w, conflicting, true)
+}
+
+func promptConflictResolution(r io.Reader, w io.Writer, conflicting []string, offerMerge bool) (ConflictAction, error) {
 	if len(conflicting) == 0 {
 		return ConflictCancel, nil
 	}
+	choices := "o/b/c"
+	if offerMerge {
+		choices = "o/b/m/c"
+	}
+	printConflictMenu(w, conflicting, choices)
+	return readConflictChoice(bufio.NewReader(r), w, "Choice", choices)
+}
+
+// PromptConflictActions is PromptConflictResolution with an answer per
+// directory: when several directories conflict, [d] asks about each one in
+// turn. With offerRemember it then asks whether to keep the answers for
+// future runs, unless every directory was cancelled. A failed read of that
+// last answer counts as no.
+func PromptConflictActions(r io.Reader, w io.Writer, conflicting []string, offerRemember bool) (map[string]ConflictAction, bool, error) {
+	actions := make(map[string]ConflictAction, len(conflicting))
+	if len(conflicting) == 0 {
+		return actions, false, nil
+	}
+	choices := "o/b/c"
+	if len(conflicting) > 1 {
+		choices = "o/b/d/c"
+	}
+	printConflictMenu(w, conflicting, choices)
+
+	reader := bufio.NewReader(r)
+	action, err := readConflictChoice(reader, w, "Choice", choices)
+	if err != nil && !errors.Is(err, errDecideEach) {
+		return nil, false, err
+	}
+	if errors.Is(err, errDecideEach) {
+		fmt.Fprintln(w)
+		for _, dir := range conflicting {
+			if actions[dir], err = readConflictChoice(reader, w, "  "+dir, "o/b/c"); err != nil {
+				return nil, false, err
+			}
+		}
+	} else {
+		for _, dir := range conflicting {
+			actions[dir] = action
+		}
+	}
+
+	if !offerRemember || allCancelled(actions) {
+		return actions, false, nil
+	}
+	fmt.Fprint(w, "Remember for future updates? [y/N]: ")
+	answer, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Fprintln(w)
+		return actions, false, nil
+	}
+	switch strings.TrimSpace(strings.ToLower(answer)) {
+	case "y", "yes":
+		return actions, true, nil
+	}
+	return actions, false, nil
+}
+
+// errDecideEach is returned by readConflictChoice when the answer is [d].
+var errDecideEach = errors.New("decide for each directory")
 
+// printConflictMenu lists the conflicting directories and the options in
+// choices, a slash-separated list of letters.
+func printConflictMenu(w io.Writer, conflicting []string, choices string) {
 	if len(conflicting) == 1 {
 		fmt.Fprintf(w, "%s already exists. What would you like to do?\n", conflicting[0])
 	} else {
@@ -98,25 +295,56 @@ func PromptConflictResolution(r io.Reader, w io.Writer, conflicting []string) (C
 
 	fmt.Fprintln(w, "  [o] Overwrite existing files")
 	fmt.Fprintln(w, "  [b] Backup existing and reinitialize")
+	if strings.Contains(choices, "m") {
+		fmt.Fprintln(w, "  [m] Merge: add missing files, keep files you modified")
+	}
+	if strings.Contains(choices, "d") {
+		fmt.Fprintln(w, "  [d] Decide for each directory")
+	}
 	fmt.Fprintln(w, "  [c] Cancel (default)")
-	fmt.Fprint(w, "Choice [o/b/c]: ")
+}
 
-	reader := bufio.NewReader(r)
-	choice, err := reader.ReadString('\n')
-	if err != nil {
-		return ConflictCancel, fmt.Errorf("reading input: %w", err)
-	}
+// readConflictChoice asks for one of choices, re-asking after invalid input
+// up to MaxPromptAttempts. An empty answer cancels.
+func readConflictChoice(reader *bufio.Reader, w io.Writer, label, choices string) (ConflictAction, error) {
+	for attempt := 1; attempt <= MaxPromptAttempts; attempt++ {
+		fmt.Fprintf(w, "%s [%s]: ", label, choices)
+		choice, err := reader.ReadString('\n')
+		if err != nil {
+			return ConflictCancel, fmt.Errorf("reading input: %w", err)
+		}
 
-	choice = strings.TrimSpace(strings.ToLower(choice))
+		switch strings.TrimSpace(strings.ToLower(choice)) {
+		case "o", "overwrite":
+			return ConflictOverwrite, nil
+		case "b", "backup":
+			return ConflictBackup, nil
+		case "m", "merge":
+			if strings.Contains(choices, "m") {
+				return ConflictMerge, nil
+			}
+		case "d", "decide":
+			if strings.Contains(choices, "d") {
+				return ConflictCancel, errDecideEach
+			}
+		case "c", "cancel", "":
+			return ConflictCancel, nil
+		}
+		if attempt < MaxPromptAttempts {
+			fmt.Fprintf(w, "Invalid choice %q; enter one of %s, or press Enter to cancel.\n", strings.TrimSpace(choice), strings.ReplaceAll(choices, "/", ", "))
+		}
+	}
+	return ConflictCancel, fmt.Errorf("no valid choice after %d attempts", MaxPromptAttempts)
+}
 
-	switch choice {
-	case "o":
-		return ConflictOverwrite, nil
-	case "b":
-		return ConflictBackup, nil
-	default:
-		return ConflictCancel, nil
+// allCancelled reports whether every action is ConflictCancel.
+func allCancelled(actions map[string]ConflictAction) bool {
+	for _, action := range actions {
+		if action != ConflictCancel {
+			return false
+		}
 	}
+	return true
 }
 
 // BackupPath generates a timestamped backup path for a directory
@@ -210,3 +210,201 @@ func TestBackupPath(t *testing.T) {
 		t.Errorf("backup path too short: %s", path)
 	}
 }
+
+func TestPromptReinitResolution_Merge(t *testing.T) {
+	r := strings.NewReader("m\n")
+	w := &bytes.Buffer{}
+
+	action, err := PromptReinitResolution(r, w, []string{".maestro"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if action != ConflictMerge {
+		t.Errorf("expected ConflictMerge, got %v", action)
+	}
+	if !strings.Contains(w.String(), "[m] Merge") {
+		t.Errorf("expected merge option in prompt, got %q", w.String())
+	}
+}
+
+func TestPromptConflictResolution_MergeNotOffered(t *testing.T) {
+	r := strings.NewReader("m\nc\n")
+	w := &bytes.Buffer{}
+
+	action, err := PromptConflictResolution(r, w, []string{".opencode"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if action != ConflictCancel {
+		t.Errorf("expected ConflictCancel, got %v", action)
+	}
+	if strings.Contains(w.String(), "[m]") {
+		t.Errorf("merge should not be offered, got %q", w.String())
+	}
+	if !strings.Contains(w.String(), `Invalid choice "m"`) {
+		t.Errorf("m should be rejected as invalid, got %q", w.String())
+	}
+}
+
+func TestPromptAgentSelection_RetriesInvalidInput(t *testing.T) {
+	r := strings.NewReader("5\nfoo\n2\n")
+	w := &bytes.Buffer{}
+
+	selected, err := PromptAgentSelection(r, w, []string{".opencode", ".claude"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(selected) != 1 || selected[0] != ".claude" {
+		t.Errorf("expected [.claude], got %v", selected)
+	}
+	for _, want := range []string{"number 5 is out of range (1-2)", "'foo' is not a number"} {
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("expected %q in output, got %q", want, w.String())
+		}
+	}
+}
+
+func TestPromptAgentSelection_GivesUpAfterMaxAttempts(t *testing.T) {
+	r := strings.NewReader(strings.Repeat("9\n", MaxPromptAttempts) + "1\n")
+	w := &bytes.Buffer{}
+
+	_, err := PromptAgentSelection(r, w, []string{".opencode", ".claude"})
+	if err == nil || !strings.Contains(err.Error(), "attempts") {
+		t.Errorf("expected error after %d invalid attempts, got %v", MaxPromptAttempts, err)
+	}
+}
+
+func TestPromptConflictResolution_RetriesTypos(t *testing.T) {
+	r := strings.NewReader("ovewrite\nbackup\n")
+	w := &bytes.Buffer{}
+
+	action, err := PromptConflictResolution(r, w, []string{".opencode"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if action != ConflictBackup {
+		t.Errorf("expected ConflictBackup after retry, got %v", action)
+	}
+	if strings.Count(w.String(), "Choice [o/b/c]: ") != 2 {
+		t.Errorf("expected the prompt to be shown twice, got %q", w.String())
+	}
+}
+
+func TestPromptConflictResolution_GivesUpAfterMaxAttempts(t *testing.T) {
+	r := strings.NewReader(strings.Repeat("x\n", MaxPromptAttempts))
+	w := &bytes.Buffer{}
+
+	action, err := PromptConflictResolution(r, w, []string{".opencode"})
+	if err == nil || action != ConflictCancel {
+		t.Errorf("expected cancel with error, got %v, %v", action, err)
+	}
+}
+
+func TestPromptAgentSelection_KeywordsAndRanges(t *testing.T) {
+	available := []string{".opencode", ".claude", ".codex"}
+	for input, want := range map[string][]string{
+		"all\n":     {".opencode", ".claude", ".codex"},
+		"ALL\n":     {".opencode", ".claude", ".codex"},
+		"none\n":    {},
+		"1-2\n":     {".opencode", ".claude"},
+		"2-3 1\n":   {".claude", ".codex", ".opencode"},
+		"1,3\n":     {".opencode", ".codex"},
+		"1-3, 2\n":  {".opencode", ".claude", ".codex"},
+		"3-3\n":     {".codex"},
+		"2 , 1-1\n": {".claude", ".opencode"},
+	} {
+		selected, err := PromptAgentSelection(strings.NewReader(input), &bytes.Buffer{}, available)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", input, err)
+			continue
+		}
+		if strings.Join(selected, " ") != strings.Join(want, " ") {
+			t.Errorf("%q: selected %v, want %v", input, selected, want)
+		}
+	}
+}
+
+func TestParseAgentSelection_RejectsBadRanges(t *testing.T) {
+	available := []string{".opencode", ".claude", ".codex"}
+	for _, input := range []string{"3-1", "1-4", "0-2", "1-x", "all 1", "none 2"} {
+		if _, err := parseAgentSelection(input, available); err == nil {
+			t.Errorf("%q: expected error", input)
+		}
+	}
+}
+
+func TestPromptAgentSelectionWithDefaults(t *testing.T) {
+	available := []string{".opencode", ".claude", ".codex"}
+	w := &bytes.Buffer{}
+
+	selected, err := PromptAgentSelectionWithDefaults(strings.NewReader("\n"), w, available, []string{".codex", ".claude"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(selected, " ") != ".claude .codex" {
+		t.Errorf("Enter should keep the preselected dirs, got %v", selected)
+	}
+	if !strings.Contains(w.String(), ".claude  (slash commands and skills for Claude Code) [installed]") || !strings.Contains(w.String(), "press Enter to keep 2 3") {
+		t.Errorf("preselected dirs should be marked, got %q", w.String())
+	}
+
+	selected, err = PromptAgentSelectionWithDefaults(strings.NewReader("none\n"), &bytes.Buffer{}, available, []string{".claude"})
+	if err != nil || len(selected) != 0 {
+		t.Errorf("none should override the defaults, got %v, %v", selected, err)
+	}
+}
+
+func TestPromptConflictActions_DecideEach(t *testing.T) {
+	r := strings.NewReader("d\no\nx\nb\n\ny\n")
+	w := &bytes.Buffer{}
+
+	actions, remember, err := PromptConflictActions(r, w, []string{".opencode", ".claude", ".codex"}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]ConflictAction{".opencode": ConflictOverwrite, ".claude": ConflictBackup, ".codex": ConflictCancel}
+	for dir, action := range want {
+		if actions[dir] != action {
+			t.Errorf("%s: expected %v, got %v", dir, action, actions[dir])
+		}
+	}
+	if !remember {
+		t.Error("expected the answers to be remembered")
+	}
+	for _, s := range []string{"[d] Decide for each directory", "  .claude [o/b/c]: ", `Invalid choice "x"`, "Remember for future updates? [y/N]: "} {
+		if !strings.Contains(w.String(), s) {
+			t.Errorf("expected %q in output, got %q", s, w.String())
+		}
+	}
+}
+
+func TestPromptConflictActions_OneAnswer(t *testing.T) {
+	r := strings.NewReader("b\n\n")
+	w := &bytes.Buffer{}
+
+	actions, remember, err := PromptConflictActions(r, w, []string{".opencode", ".claude"}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actions[".opencode"] != ConflictBackup || actions[".claude"] != ConflictBackup || remember {
+		t.Errorf("expected backup for both, not remembered; got %v, %v", actions, remember)
+	}
+}
+
+func TestPromptConflictActions_SingleDirectory(t *testing.T) {
+	r := strings.NewReader("d\nc\n")
+	w := &bytes.Buffer{}
+
+	actions, remember, err := PromptConflictActions(r, w, []string{".opencode"}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actions[".opencode"] != ConflictCancel || remember {
+		t.Errorf("expected cancel, not remembered; got %v, %v", actions, remember)
+	}
+	if strings.Contains(w.String(), "[d]") || strings.Contains(w.String(), "Remember") {
+		t.Errorf("one directory should not offer [d], and a cancel should not be remembered, got %q", w.String())
+	}
+}
@@ -1,24 +1,34 @@
 package agents
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
+
+	"github.com/spec-maestro/maestro-cli/pkg/ignore"
+	"github.com/spec-maestro/maestro-cli/pkg/newline"
+	"github.com/spec-maestro/maestro-cli/pkg/safepath"
 )
 
-// WriteAgentDir writes the given file content to the target directory.
+// WriteAgentDir writes the given file content to the target directory of
+// the project at root; a relative targetDir is resolved from root.
 // content maps relative paths to file content bytes.
 // It creates nested directories as needed and writes files atomically.
+// Files the project's .maestro/.maestroignore lists are left as they are.
 // Returns an error if any write operation fails.
-func WriteAgentDir(content map[string][]byte, targetDir string) error {
+func WriteAgentDir(root string, content map[string][]byte, targetDir string) error {
 	if len(content) == 0 {
 		return fmt.Errorf("no content to write")
 	}
+	ignored, err := ignore.Load(root)
+	if err != nil {
+		return err
+	}
 
 	// Validate targetDir to prevent path traversal
-	cleanTarget, err := filepath.Abs(targetDir)
+	cleanTarget, err := filepath.Abs(inRoot(root, targetDir))
 	if err != nil {
 		return fmt.Errorf("resolving target directory: %w", err)
 	}
@@ -30,26 +40,13 @@ func WriteAgentDir(content map[string][]byte, targetDir string) error {
 
 	// Write each file
 	for relPath, data := range content {
-		// Validate and clean the relative path to prevent path traversal attacks
-		if strings.Contains(relPath, "..") {
-			return fmt.Errorf("invalid path contains '..': %s", relPath)
-		}
-
-		// Build full path
-		fullPath := filepath.Join(cleanTarget, relPath)
-
-		// Ensure the file path is still under cleanTarget (defense in depth)
-		cleanPath, err := filepath.Abs(fullPath)
+		// Validate the relative path to prevent path traversal and symlink attacks
+		fullPath, err := safepath.Join(cleanTarget, relPath)
 		if err != nil {
-			return fmt.Errorf("resolving path for %s: %w", relPath, err)
+			return err
 		}
-		if !strings.HasPrefix(cleanPath, cleanTarget+string(filepath.Separator)) && cleanPath != cleanTarget {
-			return fmt.Errorf("path traversal detected: %s", relPath)
-		}
-
-		// Check for symlink attacks - ensure parent directories are not symlinks
-		if err := ensureNoSymlinks(filepath.Dir(cleanPath), cleanTarget); err != nil {
-			return fmt.Errorf("symlink check failed for %s: %w", relPath, err)
+		if ignored.MatchFile(fullPath) {
+			continue
 		}
 
 		// Create parent directories
@@ -58,8 +55,9 @@ func WriteAgentDir(content map[string][]byte, targetDir string) error {
 			return fmt.Errorf("creating directory for %s: %w", relPath, err)
 		}
 
-		// Write file atomically using temp file + rename
-		if err := writeFileAtomic(fullPath, data); err != nil {
+		// Write file atomically using temp file + rename, applying the
+		// project's line-ending policy to text files
+		if err := writeFileAtomic(fullPath, newline.Apply(relPath, data)); err != nil {
 			return fmt.Errorf("writing %s: %w", relPath, err)
 		}
 	}
@@ -67,6 +65,43 @@ func WriteAgentDir(content map[string][]byte, targetDir string) error {
 	return nil
 }
 
+// RemoveAgentFiles deletes the given relative paths from targetDir of the
+// project at root and prunes directories left empty. Missing files, and
+// files .maestro/.maestroignore lists, are left alone.
+func RemoveAgentFiles(root, targetDir string, relPaths []string) error {
+	cleanTarget, err := filepath.Abs(inRoot(root, targetDir))
+	if err != nil {
+		return fmt.Errorf("resolving target directory: %w", err)
+	}
+	ignored, err := ignore.Load(root)
+	if err != nil {
+		return err
+	}
+
+	for _, relPath := range relPaths {
+		fullPath, err := safepath.Join(cleanTarget, relPath)
+		if err != nil {
+			return err
+		}
+		if ignored.MatchFile(fullPath) {
+			continue
+		}
+
+		if err := os.Remove(fullPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing %s: %w", relPath, err)
+		}
+
+		// Prune now-empty parent directories up to (not including) the target
+		for dir := filepath.Dir(fullPath); dir != cleanTarget; dir = filepath.Dir(dir) {
+			if err := os.Remove(dir); err != nil {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
 // BackupDir creates a timestamped backup of the given directory.
 // Returns the backup path or an error if the backup fails.
 // The backup path follows the format: {dirPath}-backup-{timestamp}
@@ -92,6 +127,14 @@ func BackupDir(dirPath string) (string, error) {
 	return backupPath, nil
 }
 
+// inRoot resolves path, relative to the project root or absolute.
+func inRoot(root, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(root, path)
+}
+
 // writeFileAtomic writes data to a file atomically by writing to a temp file
 // and then renaming it to the target path.
 func writeFileAtomic(path string, data []byte) error {
@@ -126,8 +169,13 @@ func writeFileAtomic(path string, data []byte) error {
 		return fmt.Errorf("closing temp file: %w", err)
 	}
 
-	// Set proper permissions (0644 for regular files)
-	if err := os.Chmod(tmpPath, 0644); err != nil {
+	// Set proper permissions (0644 for regular files, 0755 for scripts
+	// with a shebang, which commands may run directly)
+	mode := os.FileMode(0644)
+	if bytes.HasPrefix(data, []byte("#!")) {
+		mode = 0755
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
 		return fmt.Errorf("setting file permissions: %w", err)
 	}
 
@@ -140,57 +188,3 @@ func writeFileAtomic(path string, data []byte) error {
 	tmpFile = nil
 	return nil
 }
-
-// ensureNoSymlinks checks that the path and all parent directories up to root
-// do not contain symlinks. This prevents symlink attacks during file writes.
-func ensureNoSymlinks(path string, root string) error {
-	// Normalize paths
-	cleanPath, err := filepath.Abs(path)
-	if err != nil {
-		return fmt.Errorf("resolving path: %w", err)
-	}
-	cleanRoot, err := filepath.Abs(root)
-	if err != nil {
-		return fmt.Errorf("resolving root: %w", err)
-	}
-
-	// Check each component from root to path
-	current := cleanPath
-	for {
-		// Stop when we reach or go above the root
-		if current == cleanRoot {
-			break
-		}
-		if !strings.HasPrefix(current, cleanRoot+string(filepath.Separator)) && current != cleanRoot {
-			break
-		}
-
-		// Check if current path component is a symlink
-		info, err := os.Lstat(current)
-		if err != nil {
-			if os.IsNotExist(err) {
-				// Path doesn't exist yet, move to parent
-				parent := filepath.Dir(current)
-				if parent == current {
-					break // Reached filesystem root
-				}
-				current = parent
-				continue
-			}
-			return fmt.Errorf("checking path %s: %w", current, err)
-		}
-
-		if info.Mode()&os.ModeSymlink != 0 {
-			return fmt.Errorf("symlink detected at %s", current)
-		}
-
-		// Move to parent directory
-		parent := filepath.Dir(current)
-		if parent == current {
-			break // Reached filesystem root
-		}
-		current = parent
-	}
-
-	return nil
-}
@@ -3,6 +3,7 @@ package agents
 import (
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 )
 
@@ -19,7 +20,7 @@ func TestWriteAgentDir(t *testing.T) {
 	}
 
 	// Write the files
-	err := WriteAgentDir(content, targetDir)
+	err := WriteAgentDir(tmpDir, content, targetDir)
 	if err != nil {
 		t.Fatalf("WriteAgentDir failed: %v", err)
 	}
@@ -52,11 +53,32 @@ func TestWriteAgentDir(t *testing.T) {
 	}
 }
 
+func TestWriteAgentDirMakesScriptsExecutable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no exec bit on Windows")
+	}
+	tmpDir := t.TempDir()
+	targetDir := filepath.Join(tmpDir, "scripts")
+	content := map[string][]byte{
+		"run.sh":       []byte("#!/usr/bin/env bash\necho ok\n"),
+		"fixture.json": []byte("{}"),
+	}
+	if err := WriteAgentDir(tmpDir, content, targetDir); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]os.FileMode{"run.sh": 0755, "fixture.json": 0644} {
+		info, err := os.Stat(filepath.Join(targetDir, name))
+		if err != nil || info.Mode().Perm() != want {
+			t.Errorf("%s: mode %v, %v, want %o", name, info.Mode().Perm(), err, want)
+		}
+	}
+}
+
 func TestWriteAgentDirEmpty(t *testing.T) {
 	tmpDir := t.TempDir()
 	targetDir := filepath.Join(tmpDir, "empty")
 
-	err := WriteAgentDir(map[string][]byte{}, targetDir)
+	err := WriteAgentDir(tmpDir, map[string][]byte{}, targetDir)
 	if err == nil {
 		t.Fatal("Expected error for empty content, got nil")
 	}
@@ -71,7 +93,7 @@ func TestWriteAgentDirPathTraversal(t *testing.T) {
 		"../evil.txt": []byte("evil content"),
 	}
 
-	err := WriteAgentDir(content, targetDir)
+	err := WriteAgentDir(tmpDir, content, targetDir)
 	if err == nil {
 		t.Fatal("Expected error for path traversal, got nil")
 	}
@@ -152,3 +174,67 @@ func TestBackupDirNotDirectory(t *testing.T) {
 		t.Fatal("Expected error for file path, got nil")
 	}
 }
+
+func TestRemoveAgentFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	targetDir := filepath.Join(tmpDir, "agent")
+
+	content := map[string][]byte{
+		"keep.md":             []byte("keep"),
+		"commands/gone.md":    []byte("gone"),
+		"skills/old/SKILL.md": []byte("old"),
+	}
+	if err := WriteAgentDir(tmpDir, content, targetDir); err != nil {
+		t.Fatalf("WriteAgentDir failed: %v", err)
+	}
+
+	if err := RemoveAgentFiles(tmpDir, targetDir, []string{"commands/gone.md", "skills/old/SKILL.md", "missing.md"}); err != nil {
+		t.Fatalf("RemoveAgentFiles failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(targetDir, "keep.md")); err != nil {
+		t.Error("keep.md should not be removed")
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "skills")); !os.IsNotExist(err) {
+		t.Error("empty parent directories should be pruned")
+	}
+
+	if err := RemoveAgentFiles(tmpDir, targetDir, []string{"../outside.md"}); err == nil {
+		t.Error("expected error for path traversal")
+	}
+}
+
+func TestWriteAgentDirSkipsIgnoredFiles(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	path := func(name string) string { return filepath.Join(root, filepath.FromSlash(name)) }
+
+	os.MkdirAll(path(".maestro"), 0755)
+	os.WriteFile(path(".maestro/.maestroignore"), []byte(".claude/commands/custom.md\n"), 0644)
+	os.MkdirAll(path(".claude/commands"), 0755)
+	os.WriteFile(path(".claude/commands/custom.md"), []byte("mine"), 0644)
+
+	content := map[string][]byte{
+		"commands/custom.md": []byte("upstream"),
+		"commands/plan.md":   []byte("upstream"),
+	}
+	if err := WriteAgentDir(root, content, ".claude"); err != nil {
+		t.Fatalf("WriteAgentDir failed: %v", err)
+	}
+	if data, _ := os.ReadFile(path(".claude/commands/custom.md")); string(data) != "mine" {
+		t.Errorf("an ignored file should be left alone, got %q", data)
+	}
+	if data, _ := os.ReadFile(path(".claude/commands/plan.md")); string(data) != "upstream" {
+		t.Errorf("other files should be written, got %q", data)
+	}
+
+	if err := RemoveAgentFiles(root, ".claude", []string{"commands/custom.md", "commands/plan.md"}); err != nil {
+		t.Fatalf("RemoveAgentFiles failed: %v", err)
+	}
+	if _, err := os.Stat(path(".claude/commands/custom.md")); err != nil {
+		t.Error("an ignored file should not be removed")
+	}
+	if _, err := os.Stat(path(".claude/commands/plan.md")); !os.IsNotExist(err) {
+		t.Error("other files should be removed")
+	}
+}
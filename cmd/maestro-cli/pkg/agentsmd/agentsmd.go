@@ -0,0 +1,152 @@
+// Package agentsmd writes maestro's instructions into a project's AGENTS.md,
+// and the agent-specific files rendered from the same template, without
+// clobbering what the user wrote there. In merge mode the
+// instructions live in a managed block between StartMarker and EndMarker,
+// which init and update rewrite while leaving the rest of the file alone.
+package agentsmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Markers delimiting the block maestro manages.
+const (
+	StartMarker = "<!-- maestro:start -->"
+	EndMarker   = "<!-- maestro:end -->"
+)
+
+// Mode is how init treats an existing AGENTS.md.
+type Mode string
+
+const (
+	// Skip keeps an existing file as it is.
+	Skip Mode = "skip"
+	// Append adds the instructions to the end of an existing file.
+	Append Mode = "append"
+	// Merge writes the instructions into the managed block, adding the
+	// block to the end of the file when it has none.
+	Merge Mode = "merge"
+	// Overwrite replaces the file.
+	Overwrite Mode = "overwrite"
+)
+
+// ParseMode validates a mode name from the command line.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(strings.ToLower(strings.TrimSpace(s))); m {
+	case Skip, Append, Merge, Overwrite:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown AGENTS.md mode %q (want skip, append, merge, or overwrite)", s)
+	}
+}
+
+// Apply returns the AGENTS.md content after writing content in mode, given
+// the existing file (nil when there is none), and whether it changed.
+func Apply(existing []byte, content string, mode Mode) (string, bool, error) {
+	if existing == nil {
+		if mode == Merge {
+			return Block(content), true, nil
+		}
+		return content, true, nil
+	}
+
+	current := string(existing)
+	var updated string
+	switch mode {
+	case Skip:
+		return current, false, nil
+	case Overwrite:
+		updated = content
+	case Append:
+		// Appending twice would repeat the instructions
+		if strings.Contains(current, strings.TrimSpace(content)) {
+			return current, false, nil
+		}
+		updated = joinSections(current, content)
+	case Merge:
+		var found bool
+		var err error
+		updated, found, err = ReplaceBlock(current, content)
+		if err != nil {
+			return "", false, err
+		}
+		if !found {
+			updated = joinSections(current, Block(content))
+		}
+	default:
+		return "", false, fmt.Errorf("unknown AGENTS.md mode %q", mode)
+	}
+	return updated, updated != current, nil
+}
+
+// Block wraps content in the managed block markers.
+func Block(content string) string {
+	return StartMarker + "\n" + strings.TrimRight(content, "\n") + "\n" + EndMarker + "\n"
+}
+
+// ReplaceBlock replaces the managed block in current with content and
+// reports whether there was one. A start marker without an end marker is
+// an error, as replacing up to the end of the file could delete user text.
+func ReplaceBlock(current, content string) (string, bool, error) {
+	start := strings.Index(current, StartMarker)
+	if start < 0 {
+		return current, false, nil
+	}
+	end := strings.Index(current[start:], EndMarker)
+	if end < 0 {
+		return "", true, fmt.Errorf("%s without %s", StartMarker, EndMarker)
+	}
+	end += start + len(EndMarker)
+	// The block's own line ending belongs to it
+	if strings.HasPrefix(current[end:], "\n") {
+		end++
+	}
+	return current[:start] + Block(content) + current[end:], true, nil
+}
+
+// RemoveBlock removes the managed block from current, with the blank lines
+// that separate it from the user's text, and reports whether there was one.
+func RemoveBlock(current string) (string, bool, error) {
+	start := strings.Index(current, StartMarker)
+	if start < 0 {
+		return current, false, nil
+	}
+	end := strings.Index(current[start:], EndMarker)
+	if end < 0 {
+		return "", true, fmt.Errorf("%s without %s", StartMarker, EndMarker)
+	}
+	end += start + len(EndMarker)
+	before := strings.TrimRight(current[:start], "\n")
+	after := strings.TrimLeft(current[end:], "\n")
+	switch {
+	case before == "":
+		return after, true, nil
+	case after == "":
+		return before + "\n", true, nil
+	}
+	return before + "\n\n" + after, true, nil
+}
+
+// RemoveAppended removes content that append mode added to current, with
+// the blank lines before it, and reports whether it was there.
+func RemoveAppended(current, content string) (string, bool) {
+	appended := strings.TrimSpace(content)
+	start := strings.LastIndex(current, appended)
+	if appended == "" || start < 0 {
+		return current, false
+	}
+	before := strings.TrimRight(current[:start], "\n")
+	after := strings.TrimLeft(current[start+len(appended):], "\n")
+	switch {
+	case before == "":
+		return after, true
+	case after == "":
+		return before + "\n", true
+	}
+	return before + "\n\n" + after, true
+}
+
+func joinSections(current, addition string) string {
+	return strings.TrimRight(current, "\n") + "\n\n" + addition
+}
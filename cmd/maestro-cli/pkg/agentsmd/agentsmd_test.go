@@ -0,0 +1,102 @@
+package agentsmd
+
+import (
+	"strings"
+	"testing"
+)
+
+const instructions = "# Maestro Agent Instructions\n\nRun `maestro doctor`.\n"
+
+func TestApply(t *testing.T) {
+	user := []byte("# Team notes\n\nAlways run make lint.\n")
+	block := Block(instructions)
+
+	tests := []struct {
+		name     string
+		existing []byte
+		mode     Mode
+		want     string
+		changed  bool
+	}{
+		{"missing file, merge", nil, Merge, block, true},
+		{"missing file, skip", nil, Skip, instructions, true},
+		{"skip", user, Skip, string(user), false},
+		{"overwrite", user, Overwrite, instructions, true},
+		{"append", user, Append, string(user) + "\n" + instructions, true},
+		{"append twice", []byte(string(user) + "\n" + instructions), Append, string(user) + "\n" + instructions, false},
+		{"merge adds block", user, Merge, string(user) + "\n" + block, true},
+		{"merge keeps surrounding text", []byte("intro\n" + StartMarker + "\nold\n" + EndMarker + "\noutro\n"), Merge, "intro\n" + block + "outro\n", true},
+		{"merge unchanged", []byte("intro\n" + block), Merge, "intro\n" + block, false},
+	}
+	for _, tt := range tests {
+		got, changed, err := Apply(tt.existing, instructions, tt.mode)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want || changed != tt.changed {
+			t.Errorf("%s: Apply() = %q, %v; want %q, %v", tt.name, got, changed, tt.want, tt.changed)
+		}
+	}
+}
+
+func TestReplaceBlockRejectsUnterminatedBlock(t *testing.T) {
+	_, _, err := ReplaceBlock("notes\n"+StartMarker+"\nold\n", instructions)
+	if err == nil || !strings.Contains(err.Error(), EndMarker) {
+		t.Errorf("expected an unterminated block error, got %v", err)
+	}
+}
+
+func TestRemoveBlock(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"# Rules\n\n" + Block(instructions) + "\n## More\n", "# Rules\n\n## More\n"},
+		{"# Rules\n\n" + Block(instructions), "# Rules\n"},
+		{Block(instructions), ""},
+		{"no block\n", "no block\n"},
+	} {
+		got, _, err := RemoveBlock(tc.in)
+		if err != nil || got != tc.want {
+			t.Errorf("RemoveBlock(%q) = %q, %v; want %q", tc.in, got, err, tc.want)
+		}
+	}
+}
+
+func TestRemoveAppended(t *testing.T) {
+	appended, _, _ := Apply([]byte("# Rules\n"), instructions, Append)
+	if got, ok := RemoveAppended(appended, instructions); !ok || got != "# Rules\n" {
+		t.Errorf("RemoveAppended() = %q, %v; want the user's text", got, ok)
+	}
+	if got, ok := RemoveAppended("# Rules\n", instructions); ok || got != "# Rules\n" {
+		t.Errorf("RemoveAppended() without the instructions = %q, %v", got, ok)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	if m, err := ParseMode(" Merge "); err != nil || m != Merge {
+		t.Errorf("ParseMode(Merge) = %v, %v", m, err)
+	}
+	if _, err := ParseMode("replace"); err == nil {
+		t.Error("ParseMode should reject unknown modes")
+	}
+}
+
+func TestRender(t *testing.T) {
+	neutral := Render(Data{})
+	if neutral != "# Maestro Agent Instructions\n\nRun `maestro doctor` to validate setup.\nRun `maestro update` to update to the latest version.\n" {
+		t.Errorf("Render(Data{}) = %q", neutral)
+	}
+
+	got := Render(Data{Agent: "Claude Code", Commands: []Command{
+		{Usage: "/maestro.plan <feature-id>", Description: "Plan a feature"},
+		{Usage: "/maestro.tasks"},
+	}})
+	for _, want := range []string{
+		"These instructions are for Claude Code.",
+		"Run `maestro doctor` to validate setup.\n",
+		"\n## Commands\n\n- `/maestro.plan <feature-id>`: Plan a feature\n- `/maestro.tasks`\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Render() missing %q:\n%s", want, got)
+		}
+	}
+}
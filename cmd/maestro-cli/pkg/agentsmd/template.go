@@ -0,0 +1,48 @@
+package agentsmd
+
+import (
+	"strings"
+	"text/template"
+)
+
+// Data is what the instructions are rendered from. The zero value renders
+// the agent-neutral instructions for AGENTS.md.
+type Data struct {
+	// Agent is the agent an instruction file is for, e.g. "Claude Code".
+	Agent string
+	// Commands are the maestro commands installed for Agent.
+	Commands []Command
+}
+
+// Command is a maestro command as typed in the agent.
+type Command struct {
+	Usage       string
+	Description string
+}
+
+// instructionsTemplate is the canonical template every instruction file is
+// rendered from, so AGENTS.md and the agent-specific files can't drift.
+var instructionsTemplate = template.Must(template.New("instructions").Parse(`# Maestro Agent Instructions
+{{if .Agent}}
+These instructions are for {{.Agent}}. maestro generates them from the same source as AGENTS.md and refreshes them on update.
+{{end}}
+Run ` + "`maestro doctor`" + ` to validate setup.
+Run ` + "`maestro update`" + ` to update to the latest version.
+{{- if .Commands}}
+
+## Commands
+{{range .Commands}}
+- ` + "`{{.Usage}}`" + `{{if .Description}}: {{.Description}}{{end}}
+{{- end}}
+{{- end}}
+`))
+
+// Render returns the instructions for data.
+func Render(data Data) string {
+	var b strings.Builder
+	if err := instructionsTemplate.Execute(&b, data); err != nil {
+		// The template is fixed and Data has no methods that can fail
+		panic(err)
+	}
+	return b.String()
+}
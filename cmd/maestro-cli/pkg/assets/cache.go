@@ -3,25 +3,60 @@ package assets
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
+
+	"github.com/spec-maestro/maestro-cli/internal/version"
+	"github.com/spec-maestro/maestro-cli/pkg/userdirs"
+)
+
+const (
+	objectsDirName    = "objects"
+	quarantineDirName = "quarantine"
+	indexFileName     = "index.json"
+	// versionFileName records the CLI version that last evicted stale
+	// entries, so EvictStale only runs when the version changes.
+	versionFileName = "cli-version"
 )
 
+// entryExts are the extensions CachePath keeps from a URL.
+var entryExts = []string{".tar.gz", ".tgz", ".zip"}
+
 // CacheManager manages locally cached assets.
+//
+// Cached entries are deduplicated by content: every entry is hardlinked to a
+// content-addressed object under objects/<sha256>, so identical bundles cached
+// under different URLs (e.g. unchanged assets across releases) share disk space.
 type CacheManager struct {
 	dir string
 }
 
-// NewCacheManager creates a CacheManager using ~/.cache/maestro.
+// CacheEntry records metadata for a single cached URL.
+type CacheEntry struct {
+	URL       string    `json:"url"`
+	SHA256    string    `json:"sha256"`
+	Size      int64     `json:"size"`
+	FetchedAt time.Time `json:"fetched_at"`
+	// Release is the release the entry was downloaded for, when known.
+	Release string `json:"release,omitempty"`
+}
+
+// cacheIndex maps cache entry file names to their metadata.
+type cacheIndex map[string]CacheEntry
+
+// NewCacheManager creates a CacheManager using ~/.cache/maestro, or
+// maestro-data/cache next to the executable in portable mode.
 func NewCacheManager() (*CacheManager, error) {
-	home, err := os.UserHomeDir()
+	dir, err := userdirs.CacheDir()
 	if err != nil {
-		return nil, fmt.Errorf("getting home directory: %w", err)
+		return nil, err
 	}
-	dir := filepath.Join(home, ".cache", "maestro")
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, fmt.Errorf("creating cache directory: %w", err)
 	}
@@ -32,14 +67,16 @@ func NewCacheManager() (*CacheManager, error) {
 func (c *CacheManager) CachePath(url string) string {
 	h := sha256.Sum256([]byte(url))
 	key := hex.EncodeToString(h[:])[:16]
+
 	// Preserve extension
 	ext := ""
-	for _, candidate := range []string{".tar.gz", ".tgz", ".zip"} {
+	for _, candidate := range entryExts {
 		if len(url) >= len(candidate) && url[len(url)-len(candidate):] == candidate {
 			ext = candidate
 			break
 		}
 	}
+
 	return filepath.Join(c.dir, key+ext)
 }
 
@@ -57,24 +94,99 @@ func (c *CacheManager) IsCached(url string, maxAge time.Duration) bool {
 }
 
 // Get returns the cached file path, downloading if necessary.
+// A cached entry is only reused when its content still matches the checksum
+// recorded at download time; corrupt entries are quarantined and re-fetched.
 func (c *CacheManager) Get(url string, maxAge time.Duration) (string, error) {
-	if c.IsCached(url, maxAge) {
-		return c.CachePath(url), nil
-	}
+	return c.GetRelease(url, "", maxAge)
+}
+
+// GetRelease is Get for an asset of release, which is recorded with a new
+// entry so EvictStale can remove it once the CLI moves past the release.
+//
+// Concurrent calls for the same URL, from this process or others sharing
+// the cache, download it once: each entry has a lock, and a download is
+// written to a temporary file that is renamed into place when complete.
+func (c *CacheManager) GetRelease(url, release string, maxAge time.Duration) (string, error) {
 	path := c.CachePath(url)
-	if err := DownloadAsset(url, path); err != nil {
-		return "", fmt.Errorf("caching asset: %w", err)
+	_, err, _ := fetches.Do(path, func() (interface{}, error) {
+		unlock, err := c.lock(filepath.Base(path))
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+		return nil, c.fetch(url, release, path, maxAge)
+	})
+	if err != nil {
+		return "", err
 	}
 	return path, nil
 }
 
+// fetch downloads url to path unless a valid entry fresher than maxAge is
+// already there. The caller holds the entry's lock.
+func (c *CacheManager) fetch(url, release, path string, maxAge time.Duration) error {
+	if c.IsCached(url, maxAge) {
+		ok, err := c.verifyEntry(filepath.Base(path))
+		if err != nil {
+			return fmt.Errorf("verifying cached asset: %w", err)
+		}
+		if ok {
+			return nil
+		}
+	}
+
+	// Renaming the download into place never writes through the old entry,
+	// which may be a hardlink shared with other entries, and never exposes
+	// a partial file
+	tmp, err := os.CreateTemp(c.dir, filepath.Base(path)+".*.part")
+	if err != nil {
+		return fmt.Errorf("caching asset: %w", err)
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+	if err := DownloadAsset(url, tmp.Name()); err != nil {
+		return fmt.Errorf("caching asset: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("caching asset: %w", err)
+	}
+	if err := c.store(url, release, path); err != nil {
+		return fmt.Errorf("deduplicating cache entry: %w", err)
+	}
+	return nil
+}
+
 // Invalidate removes a specific cached asset.
 func (c *CacheManager) Invalidate(url string) error {
 	path := c.CachePath(url)
-	err := os.Remove(path)
-	if os.IsNotExist(err) {
+	unlockEntry, err := c.lock(filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer unlockEntry()
+	unlock, err := c.lock(indexLockName)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	err = os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	index, err := c.loadIndex()
+	if err != nil {
+		return err
+	}
+	if _, ok := index[filepath.Base(path)]; !ok {
 		return nil
 	}
+	delete(index, filepath.Base(path))
+	if err := c.saveIndex(index); err != nil {
+		return err
+	}
+	_, err = c.prune()
 	return err
 }
 
@@ -85,11 +197,407 @@ func (c *CacheManager) Clear() error {
 		return err
 	}
 	for _, entry := range entries {
-		os.Remove(filepath.Join(c.dir, entry.Name()))
+		os.RemoveAll(filepath.Join(c.dir, entry.Name()))
 	}
 	return nil
 }
 
+// EvictStale removes the entries cached for releases older than the CLI
+// version current, the first time it runs with that version, so a bundle
+// of an old release is never extracted after an upgrade. Entries without a
+// recorded release are kept. It returns the names of the entries removed.
+func (c *CacheManager) EvictStale(current string) ([]string, error) {
+	unlock, err := c.lock(indexLockName)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	versionPath := filepath.Join(c.dir, versionFileName)
+	if data, err := os.ReadFile(versionPath); err == nil && strings.TrimSpace(string(data)) == current {
+		return nil, nil
+	}
+
+	index, err := c.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+	var evicted []string
+	for name, entry := range index {
+		if entry.Release == "" {
+			continue
+		}
+		if older, err := version.Less(entry.Release, current); err != nil || !older {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, name)); err != nil && !os.IsNotExist(err) {
+			return evicted, fmt.Errorf("removing %s: %w", name, err)
+		}
+		delete(index, name)
+		evicted = append(evicted, name)
+	}
+	sort.Strings(evicted)
+	if len(evicted) > 0 {
+		if err := c.saveIndex(index); err != nil {
+			return evicted, err
+		}
+		if _, err := c.prune(); err != nil {
+			return evicted, err
+		}
+	}
+	return evicted, os.WriteFile(versionPath, []byte(current+"\n"), 0644)
+}
+
+// Entries returns the recorded metadata for every cached URL, keyed by the
+// cache entry file name.
+func (c *CacheManager) Entries() (map[string]CacheEntry, error) {
+	return c.loadIndex()
+}
+
+// Dedupe hardlinks every cached entry to its content-addressed object,
+// including entries cached before deduplication existed. It returns the
+// number of bytes reclaimed.
+func (c *CacheManager) Dedupe() (int64, error) {
+	unlock, err := c.lock(indexLockName)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return 0, err
+	}
+	index, err := c.loadIndex()
+	if err != nil {
+		return 0, err
+	}
+
+	var saved int64
+	for _, entry := range entries {
+		if entry.IsDir() || !isEntryName(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(c.dir, entry.Name())
+		hash, err := FileHash(path)
+		if err != nil {
+			return saved, fmt.Errorf("hashing %s: %w", entry.Name(), err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return saved, err
+		}
+		object := c.objectPath(hash)
+		if existing, err := os.Stat(object); err == nil && !os.SameFile(existing, info) {
+			saved += info.Size()
+		}
+		if err := c.linkObject(hash, path); err != nil {
+			return saved, err
+		}
+
+		record := index[entry.Name()]
+		record.SHA256 = hash
+		record.Size = info.Size()
+		index[entry.Name()] = record
+	}
+
+	return saved, c.saveIndex(index)
+}
+
+// VerifyReport summarizes a cache integrity check.
+type VerifyReport struct {
+	Checked     int
+	Corrupt     []string // entry names whose content no longer matched
+	Quarantined []string // paths the corrupt entries were moved to
+	Unrecorded  []string // entries without a recorded checksum
+}
+
+// Verify re-hashes every cached entry against its recorded checksum and
+// quarantines entries that no longer match, so they are never reused.
+func (c *CacheManager) Verify() (*VerifyReport, error) {
+	unlock, err := c.lock(indexLockName)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil, err
+	}
+	index, err := c.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &VerifyReport{}
+	for _, entry := range entries {
+		if entry.IsDir() || !isEntryName(entry.Name()) {
+			continue
+		}
+		if _, ok := index[entry.Name()]; !ok {
+			report.Unrecorded = append(report.Unrecorded, entry.Name())
+			continue
+		}
+
+		report.Checked++
+		dest, err := c.checkEntry(entry.Name(), index)
+		if err != nil {
+			return report, err
+		}
+		if dest != "" {
+			report.Corrupt = append(report.Corrupt, entry.Name())
+			report.Quarantined = append(report.Quarantined, dest)
+		}
+	}
+
+	if len(report.Corrupt) > 0 {
+		if err := c.saveIndex(index); err != nil {
+			return report, err
+		}
+		if _, err := c.prune(); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// verifyEntry reports whether a single entry matches its recorded checksum,
+// quarantining it when it does not. Entries without a recorded checksum are
+// trusted, matching the behavior before checksums were recorded.
+func (c *CacheManager) verifyEntry(name string) (bool, error) {
+	unlock, err := c.lock(indexLockName)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	index, err := c.loadIndex()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := index[name]; !ok {
+		return true, nil
+	}
+
+	dest, err := c.checkEntry(name, index)
+	if err != nil {
+		return false, err
+	}
+	if dest == "" {
+		return true, nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Warning: cached asset %s failed checksum verification; moved to %s\n", name, dest)
+	if err := c.saveIndex(index); err != nil {
+		return false, err
+	}
+	_, err = c.prune()
+	return false, err
+}
+
+// checkEntry re-hashes one entry. On mismatch the entry is moved into the
+// quarantine directory, dropped from index, and the destination is returned.
+func (c *CacheManager) checkEntry(name string, index cacheIndex) (string, error) {
+	path := filepath.Join(c.dir, name)
+	hash, err := FileHash(path)
+	if err != nil {
+		return "", fmt.Errorf("hashing %s: %w", name, err)
+	}
+	if hash == index[name].SHA256 {
+		return "", nil
+	}
+
+	quarantine := filepath.Join(c.dir, quarantineDirName)
+	if err := os.MkdirAll(quarantine, 0755); err != nil {
+		return "", fmt.Errorf("creating quarantine directory: %w", err)
+	}
+	dest := filepath.Join(quarantine, fmt.Sprintf("%s-%s", name, time.Now().Format("20060102-150405")))
+	if err := os.Rename(path, dest); err != nil {
+		return "", fmt.Errorf("quarantining %s: %w", name, err)
+	}
+	delete(index, name)
+	return dest, nil
+}
+
+// Prune removes content-addressed objects no longer referenced by any cache
+// entry. It returns the number of objects removed.
+func (c *CacheManager) Prune() (int, error) {
+	unlock, err := c.lock(indexLockName)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+	return c.prune()
+}
+
+// prune is Prune for a caller holding the index lock.
+func (c *CacheManager) prune() (int, error) {
+	index, err := c.loadIndex()
+	if err != nil {
+		return 0, err
+	}
+	referenced := make(map[string]bool, len(index))
+	for name, entry := range index {
+		if _, err := os.Stat(filepath.Join(c.dir, name)); err == nil {
+			referenced[entry.SHA256] = true
+		}
+	}
+
+	objects, err := os.ReadDir(filepath.Join(c.dir, objectsDirName))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, object := range objects {
+		if referenced[object.Name()] {
+			continue
+		}
+		if err := os.Remove(c.objectPath(object.Name())); err != nil {
+			return removed, fmt.Errorf("removing object %s: %w", object.Name(), err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// store records a freshly downloaded entry in the index and links it to its
+// content-addressed object.
+func (c *CacheManager) store(url, release, path string) error {
+	unlock, err := c.lock(indexLockName)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	hash, err := FileHash(path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := c.linkObject(hash, path); err != nil {
+		return err
+	}
+
+	index, err := c.loadIndex()
+	if err != nil {
+		return err
+	}
+	index[filepath.Base(path)] = CacheEntry{
+		URL:       url,
+		SHA256:    hash,
+		Size:      info.Size(),
+		FetchedAt: time.Now().UTC(),
+		Release:   release,
+	}
+	if err := c.saveIndex(index); err != nil {
+		return err
+	}
+	_, err = c.prune()
+	return err
+}
+
+// linkObject makes path and objects/<hash> the same file. When the object
+// already exists the entry is replaced by a hardlink to it; otherwise the
+// entry becomes the object. On filesystems without hardlink support the entry
+// is left as-is, which is still correct, just not deduplicated.
+func (c *CacheManager) linkObject(hash, path string) error {
+	if err := os.MkdirAll(filepath.Join(c.dir, objectsDirName), 0755); err != nil {
+		return fmt.Errorf("creating objects directory: %w", err)
+	}
+	object := c.objectPath(hash)
+
+	objectInfo, err := os.Stat(object)
+	if os.IsNotExist(err) {
+		_ = os.Link(path, object)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	pathInfo, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if os.SameFile(objectInfo, pathInfo) {
+		return nil
+	}
+	// Never link new entries to an object that has been corrupted on disk.
+	if objectHash, err := FileHash(object); err != nil || objectHash != hash {
+		os.Remove(object)
+		_ = os.Link(path, object)
+		return nil
+	}
+
+	tmp := path + ".dedupe"
+	if err := os.Link(object, tmp); err != nil {
+		return nil
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("replacing %s with deduplicated object: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// isEntryName reports whether name is a cache entry, rather than the index
+// or another file kept in the cache directory: entries keep the archive
+// extension of their URL, or are named by the bare key CachePath derives.
+func isEntryName(name string) bool {
+	for _, ext := range entryExts {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	if len(name) != 16 {
+		return false
+	}
+	_, err := hex.DecodeString(name)
+	return err == nil
+}
+
+func (c *CacheManager) objectPath(hash string) string {
+	return filepath.Join(c.dir, objectsDirName, hash)
+}
+
+func (c *CacheManager) loadIndex() (cacheIndex, error) {
+	data, err := os.ReadFile(filepath.Join(c.dir, indexFileName))
+	if os.IsNotExist(err) {
+		return cacheIndex{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading cache index: %w", err)
+	}
+
+	index := cacheIndex{}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("parsing cache index: %w", err)
+	}
+	return index, nil
+}
+
+func (c *CacheManager) saveIndex(index cacheIndex) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling cache index: %w", err)
+	}
+	// Readers that don't take the lock, such as Entries, never see a
+	// partly written index
+	tmp := filepath.Join(c.dir, indexFileName+".tmp")
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(c.dir, indexFileName))
+}
+
 // FileHash returns the SHA256 hash of a file.
 func FileHash(path string) (string, error) {
 	f, err := os.Open(path)
@@ -97,6 +605,7 @@ func FileHash(path string) (string, error) {
 		return "", err
 	}
 	defer f.Close()
+
 	h := sha256.New()
 	if _, err := io.Copy(h, f); err != nil {
 		return "", err
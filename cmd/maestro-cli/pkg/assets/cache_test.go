@@ -0,0 +1,367 @@
+package assets
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestCache(t *testing.T) *CacheManager {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	cache, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager() error: %v", err)
+	}
+	return cache
+}
+
+func TestCacheGetDeduplicatesIdenticalContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("identical bundle"))
+	}))
+	defer server.Close()
+
+	cache := newTestCache(t)
+
+	first, err := cache.Get(server.URL+"/v1/assets.tar.gz", 0)
+	if err != nil {
+		t.Fatalf("Get(v1) error: %v", err)
+	}
+	second, err := cache.Get(server.URL+"/v2/assets.tar.gz", 0)
+	if err != nil {
+		t.Fatalf("Get(v2) error: %v", err)
+	}
+	if first == second {
+		t.Fatal("different URLs should map to different cache entries")
+	}
+
+	firstInfo, _ := os.Stat(first)
+	secondInfo, _ := os.Stat(second)
+	if !os.SameFile(firstInfo, secondInfo) {
+		t.Error("identical content should be hardlinked to one object")
+	}
+
+	entries, err := cache.Entries()
+	if err != nil {
+		t.Fatalf("Entries() error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 index entries, got %d", len(entries))
+	}
+}
+
+func TestCacheInvalidatePrunesOrphanedObjects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	cache := newTestCache(t)
+	url := server.URL + "/only.tar.gz"
+	if _, err := cache.Get(url, 0); err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+
+	if err := cache.Invalidate(url); err != nil {
+		t.Fatalf("Invalidate() error: %v", err)
+	}
+
+	objects, _ := os.ReadDir(filepath.Join(cache.dir, objectsDirName))
+	if len(objects) != 0 {
+		t.Errorf("expected orphaned objects to be pruned, found %d", len(objects))
+	}
+}
+
+func TestCacheDedupeLinksLegacyEntries(t *testing.T) {
+	cache := newTestCache(t)
+	for _, name := range []string{"aaaa.tar.gz", "bbbb.tar.gz"} {
+		if err := os.WriteFile(filepath.Join(cache.dir, name), []byte("same bytes"), 0644); err != nil {
+			t.Fatalf("seeding cache: %v", err)
+		}
+	}
+
+	saved, err := cache.Dedupe()
+	if err != nil {
+		t.Fatalf("Dedupe() error: %v", err)
+	}
+	if saved != int64(len("same bytes")) {
+		t.Errorf("expected %d bytes reclaimed, got %d", len("same bytes"), saved)
+	}
+
+	a, _ := os.Stat(filepath.Join(cache.dir, "aaaa.tar.gz"))
+	b, _ := os.Stat(filepath.Join(cache.dir, "bbbb.tar.gz"))
+	if !os.SameFile(a, b) {
+		t.Error("legacy entries with identical content should share one file")
+	}
+}
+
+func TestCacheVerifyQuarantinesCorruptEntries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("pristine bundle"))
+	}))
+	defer server.Close()
+
+	cache := newTestCache(t)
+	path, err := cache.Get(server.URL+"/assets.tar.gz", 0)
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("bit rot"), 0644); err != nil {
+		t.Fatalf("corrupting entry: %v", err)
+	}
+
+	report, err := cache.Verify()
+	if err != nil {
+		t.Fatalf("Verify() error: %v", err)
+	}
+	if len(report.Corrupt) != 1 {
+		t.Fatalf("expected 1 corrupt entry, got %v", report.Corrupt)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("corrupt entry should be moved out of the cache")
+	}
+	if _, err := os.Stat(report.Quarantined[0]); err != nil {
+		t.Errorf("quarantined copy should exist: %v", err)
+	}
+}
+
+func TestCacheGetRefetchesCorruptEntry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("pristine bundle"))
+	}))
+	defer server.Close()
+
+	cache := newTestCache(t)
+	url := server.URL + "/assets.tar.gz"
+	path, err := cache.Get(url, 0)
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("bit rot"), 0644); err != nil {
+		t.Fatalf("corrupting entry: %v", err)
+	}
+
+	path, err = cache.Get(url, 0)
+	if err != nil {
+		t.Fatalf("Get() after corruption error: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "pristine bundle" {
+		t.Errorf("expected corrupt entry to be re-downloaded, got %q", data)
+	}
+}
+
+func TestCacheEvictStale(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	cache := newTestCache(t)
+	old, _ := cache.GetRelease(server.URL+"/v1.2.0/assets.tar.gz", "v1.2.0", 0)
+	current, _ := cache.GetRelease(server.URL+"/v1.3.0/assets.tar.gz", "v1.3.0", 0)
+	untagged, _ := cache.Get(server.URL+"/mirror/assets.tar.gz", 0)
+	os.WriteFile(filepath.Join(cache.dir, "update-check.json"), []byte("{}"), 0644)
+
+	evicted, err := cache.EvictStale("v1.3.0")
+	if err != nil {
+		t.Fatalf("EvictStale() error: %v", err)
+	}
+	if len(evicted) != 1 || evicted[0] != filepath.Base(old) {
+		t.Errorf("EvictStale() = %v, want only the v1.2.0 bundle", evicted)
+	}
+	for _, path := range []string{current, untagged} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s should be kept: %v", filepath.Base(path), err)
+		}
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Error("the v1.2.0 bundle should be removed")
+	}
+
+	// Only a version change evicts again
+	cache.GetRelease(server.URL+"/v1.2.0/assets.tar.gz", "v1.2.0", 0)
+	if evicted, err := cache.EvictStale("v1.3.0"); err != nil || len(evicted) != 0 {
+		t.Errorf("EvictStale() with the same version = %v, %v; want nothing evicted", evicted, err)
+	}
+
+	report, err := cache.Verify()
+	if err != nil || len(report.Unrecorded) != 0 {
+		t.Errorf("files that are not entries should not be verified: %+v, %v", report, err)
+	}
+}
+
+func TestCacheGetConcurrent(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("shared bundle"))
+	}))
+	defer server.Close()
+
+	cache := newTestCache(t)
+	// A second manager on the same directory stands in for another process
+	other := &CacheManager{dir: cache.dir}
+	url := server.URL + "/v1/assets.tar.gz"
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		c := cache
+		if i%2 == 1 {
+			c = other
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			path, err := c.Get(url, 0)
+			if err == nil {
+				var data []byte
+				if data, err = os.ReadFile(path); err == nil && string(data) != "shared bundle" {
+					err = fmt.Errorf("read %q", data)
+				}
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Get() error: %v", err)
+		}
+	}
+
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("concurrent Gets downloaded %d times, want 1", n)
+	}
+	entries, err := cache.Entries()
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Entries() = %v, %v; want one entry", entries, err)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(cache.dir, "*.part")); len(leftovers) > 0 {
+		t.Errorf("temporary downloads left behind: %v", leftovers)
+	}
+	if locks, _ := os.ReadDir(filepath.Join(cache.dir, locksDirName)); len(locks) > 0 {
+		t.Errorf("locks left behind: %d", len(locks))
+	}
+}
+
+func TestLockBreaksLockOfExitedProcess(t *testing.T) {
+	cache := newTestCache(t)
+	exited := exec.Command(os.Args[0], "-test.run=^$")
+	if err := exited.Run(); err != nil {
+		t.Fatalf("running a short-lived process: %v", err)
+	}
+	dir := filepath.Join(cache.dir, locksDirName)
+	os.MkdirAll(dir, 0755)
+	path := filepath.Join(dir, "index.lock")
+	os.WriteFile(path, []byte(fmt.Sprintf("%d %s\n", exited.ProcessState.Pid(), hostname())), 0644)
+
+	if _, known := processRunning(exited.ProcessState.Pid()); !known {
+		t.Skip("cannot tell whether a process is running on this platform")
+	}
+	done := make(chan error, 1)
+	go func() {
+		unlock, err := cache.lock(indexLockName)
+		if err == nil {
+			unlock()
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("lock() error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("lock() still waiting on the lock of a process that exited")
+	}
+}
+
+func TestLockKeepsOldLockOfRunningProcess(t *testing.T) {
+	if _, known := processRunning(os.Getpid()); !known {
+		t.Skip("cannot tell whether a process is running on this platform")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "index.lock")
+	os.WriteFile(path, []byte(fmt.Sprintf("%d %s other\n", os.Getpid(), hostname())), 0644)
+	old := time.Now().Add(-2 * staleLockAge)
+	os.Chtimes(path, old, old)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lockStale(path, info) {
+		t.Error("the lock of a running process was taken to be stale because of its age")
+	}
+	os.WriteFile(path, []byte("1 elsewhere other\n"), 0644)
+	os.Chtimes(path, old, old)
+	if info, _ = os.Stat(path); !lockStale(path, info) {
+		t.Error("an old lock from another host was not taken to be stale")
+	}
+}
+
+func TestUnlockLeavesLockTakenByAnother(t *testing.T) {
+	cache := newTestCache(t)
+	unlock, err := cache.lock(indexLockName)
+	if err != nil {
+		t.Fatalf("lock() error: %v", err)
+	}
+	path := filepath.Join(cache.dir, locksDirName, indexLockName+".lock")
+	os.WriteFile(path, []byte(fmt.Sprintf("%d %s other\n", os.Getpid(), hostname())), 0644)
+
+	unlock()
+	if lockOwner(path) != "other" {
+		t.Error("unlock() removed a lock it no longer held")
+	}
+}
+
+func TestLockExcludesConcurrentHolders(t *testing.T) {
+	cache := newTestCache(t)
+	other, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager() error: %v", err)
+	}
+	var held, overlaps int32
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		c := cache
+		if i%2 == 1 {
+			c = other
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := c.lock(indexLockName)
+			if err != nil {
+				t.Errorf("lock() error: %v", err)
+				return
+			}
+			if atomic.AddInt32(&held, 1) > 1 {
+				atomic.AddInt32(&overlaps, 1)
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&held, -1)
+			unlock()
+		}()
+	}
+	wg.Wait()
+
+	if overlaps > 0 {
+		t.Errorf("the lock was held by more than one caller %d times", overlaps)
+	}
+	if locks, _ := os.ReadDir(filepath.Join(cache.dir, locksDirName)); len(locks) > 0 {
+		t.Errorf("locks left behind: %d", len(locks))
+	}
+}
@@ -3,6 +3,7 @@ package assets
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 )
@@ -27,9 +28,13 @@ func ParseChecksumFile(checksumPath string) (map[string]string, error) {
 		return nil, fmt.Errorf("opening checksum file: %w", err)
 	}
 	defer f.Close()
+	return ParseChecksums(f)
+}
 
+// ParseChecksums parses checksums in the checksums.txt format from r.
+func ParseChecksums(r io.Reader) (map[string]string, error) {
 	checksums := make(map[string]string)
-	scanner := bufio.NewScanner(f)
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 		if line == "" || strings.HasPrefix(line, "#") {
@@ -1,7 +1,6 @@
 package assets
 
 import (
-	"archive/tar"
 	"archive/zip"
 	"compress/gzip"
 	"fmt"
@@ -10,11 +9,18 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/spec-maestro/maestro-cli/pkg/deadline"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/httpheaders"
+	"github.com/spec-maestro/maestro-cli/pkg/ignore"
+	"github.com/spec-maestro/maestro-cli/pkg/safepath"
 )
 
 // DownloadAsset downloads a file from a URL to a local path, showing progress.
 func DownloadAsset(url, destPath string) error {
-	resp, err := http.Get(url)
+	client := &http.Client{Transport: deadline.Transport{Base: httpheaders.Transport{}}}
+	resp, err := client.Get(url)
 	if err != nil {
 		return fmt.Errorf("downloading asset: %w", err)
 	}
@@ -36,6 +42,12 @@ func DownloadAsset(url, destPath string) error {
 
 	total := resp.ContentLength
 	var downloaded int64
+	// Accessible output announces the download once instead of redrawing
+	// a percentage, which screen readers would read out on every update
+	progress := total > 0 && !glyph.Accessible()
+	if total > 0 && !progress {
+		fmt.Fprintln(os.Stderr, "Downloading...")
+	}
 
 	buf := make([]byte, 32*1024)
 	for {
@@ -45,7 +57,7 @@ func DownloadAsset(url, destPath string) error {
 				return fmt.Errorf("writing to file: %w", werr)
 			}
 			downloaded += int64(n)
-			if total > 0 {
+			if progress {
 				pct := float64(downloaded) / float64(total) * 100
 				fmt.Fprintf(os.Stderr, "\rDownloading... %.0f%%", pct)
 			}
@@ -57,30 +69,97 @@ func DownloadAsset(url, destPath string) error {
 			return fmt.Errorf("reading response: %w", err)
 		}
 	}
-	if total > 0 {
+	if progress {
 		fmt.Fprintf(os.Stderr, "\rDownloading... 100%%\n")
+	} else if total > 0 {
+		fmt.Fprintln(os.Stderr, "Download complete")
 	}
 
 	return nil
 }
 
-// ExtractAsset extracts a downloaded asset (tar.gz or zip) to destDir.
-func ExtractAsset(srcPath, destDir string) error {
+// ExtractAsset extracts a downloaded asset (tar.gz or zip) to destDir of
+// the project at root; a relative destDir is resolved from root. Files the
+// project's .maestro/.maestroignore lists are left as they are.
+func ExtractAsset(root, srcPath, destDir string) error {
+	if !filepath.IsAbs(destDir) {
+		destDir = filepath.Join(root, destDir)
+	}
 	if err := os.MkdirAll(destDir, 0755); err != nil {
 		return fmt.Errorf("creating destination directory: %w", err)
 	}
+	ignored, err := ignore.Load(root)
+	if err != nil {
+		return err
+	}
+	extract := safepath.ExtractTo(destDir)
+	if !ignored.Empty() {
+		extract = func(e safepath.Entry) error {
+			if !e.IsDir && ignored.MatchFile(filepath.Join(destDir, filepath.FromSlash(e.Name))) {
+				return nil
+			}
+			return safepath.ExtractTo(destDir)(e)
+		}
+	}
 
 	switch {
 	case strings.HasSuffix(srcPath, ".tar.gz") || strings.HasSuffix(srcPath, ".tgz"):
-		return extractTarGz(srcPath, destDir)
+		return extractTarGz(srcPath, extract)
 	case strings.HasSuffix(srcPath, ".zip"):
-		return extractZip(srcPath, destDir)
+		return extractZip(srcPath, extract)
 	default:
 		return fmt.Errorf("unsupported archive format: %s", srcPath)
 	}
 }
 
-func extractTarGz(srcPath, destDir string) error {
+// ReadAsset reads the regular files of a downloaded asset (tar.gz or zip)
+// into memory, keyed by their slash-separated path in the archive, without
+// extracting anything.
+func ReadAsset(srcPath string) (map[string][]byte, error) {
+	content := make(map[string][]byte)
+	collect := func(e safepath.Entry) error {
+		if e.IsDir {
+			return nil
+		}
+		data, err := io.ReadAll(e.Body)
+		if err != nil {
+			return err
+		}
+		content[e.Name] = data
+		return nil
+	}
+
+	switch {
+	case strings.HasSuffix(srcPath, ".tar.gz") || strings.HasSuffix(srcPath, ".tgz"):
+		f, err := os.Open(srcPath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		if err := safepath.WalkTar(gz, collect); err != nil {
+			return nil, err
+		}
+	case strings.HasSuffix(srcPath, ".zip"):
+		r, err := zip.OpenReader(srcPath)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		if err := safepath.WalkZip(&r.Reader, collect); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported archive format: %s", srcPath)
+	}
+	return content, nil
+}
+
+func extractTarGz(srcPath string, extract func(safepath.Entry) error) error {
 	f, err := os.Open(srcPath)
 	if err != nil {
 		return err
@@ -93,42 +172,7 @@ func extractTarGz(srcPath, destDir string) error {
 	}
 	defer gz.Close()
 
-	tr := tar.NewReader(gz)
-	for {
-		hdr, err := tr.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
-		}
-
-		target := filepath.Join(destDir, filepath.Clean(hdr.Name))
-		if !strings.HasPrefix(target, filepath.Clean(destDir)+string(os.PathSeparator)) {
-			return fmt.Errorf("invalid path in archive: %s", hdr.Name)
-		}
-
-		switch hdr.Typeflag {
-		case tar.TypeDir:
-			if err := os.MkdirAll(target, 0755); err != nil {
-				return err
-			}
-		case tar.TypeReg:
-			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
-				return err
-			}
-			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode))
-			if err != nil {
-				return err
-			}
-			if _, err := io.Copy(out, tr); err != nil {
-				out.Close()
-				return err
-			}
-			out.Close()
-		}
-	}
-	return nil
+	return safepath.WalkTar(gz, extract)
 }
 
 // CleanupTemp removes a temporary file, ignoring errors.
@@ -136,9 +180,9 @@ func CleanupTemp(path string) {
 	os.Remove(path)
 }
 
-// DownloadAndExtract downloads an asset and extracts it to destDir.
-// The temp download file is cleaned up after extraction.
-func DownloadAndExtract(url, destDir string) error {
+// DownloadAndExtract downloads an asset and extracts it to destDir of the
+// project at root. The temp download file is cleaned up after extraction.
+func DownloadAndExtract(root, url, destDir string) error {
 	// Create temp file for download
 	tmpFile, err := os.CreateTemp("", "maestro-asset-*")
 	if err != nil {
@@ -164,48 +208,15 @@ func DownloadAndExtract(url, destDir string) error {
 		return err
 	}
 
-	return ExtractAsset(tmpPath, destDir)
+	return ExtractAsset(root, tmpPath, destDir)
 }
 
-func extractZip(srcPath, destDir string) error {
+func extractZip(srcPath string, extract func(safepath.Entry) error) error {
 	r, err := zip.OpenReader(srcPath)
 	if err != nil {
 		return err
 	}
 	defer r.Close()
 
-	for _, f := range r.File {
-		target := filepath.Join(destDir, filepath.Clean(f.Name))
-		if !strings.HasPrefix(target, filepath.Clean(destDir)+string(os.PathSeparator)) {
-			return fmt.Errorf("invalid path in archive: %s", f.Name)
-		}
-
-		if f.FileInfo().IsDir() {
-			if err := os.MkdirAll(target, 0755); err != nil {
-				return err
-			}
-			continue
-		}
-
-		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
-			return err
-		}
-
-		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, f.Mode())
-		if err != nil {
-			return err
-		}
-		rc, err := f.Open()
-		if err != nil {
-			out.Close()
-			return err
-		}
-		_, err = io.Copy(out, rc)
-		rc.Close()
-		out.Close()
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	return safepath.WalkZip(&r.Reader, extract)
 }
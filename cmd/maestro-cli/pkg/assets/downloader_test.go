@@ -0,0 +1,40 @@
+package assets
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadAsset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "assets.tar.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "commands/", Typeflag: tar.TypeDir, Mode: 0755})
+	body := []byte("# Plan\n")
+	tw.WriteHeader(&tar.Header{Name: "commands/maestro.plan.md", Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(body))})
+	tw.Write(body)
+	tw.Close()
+	gz.Close()
+	f.Close()
+
+	content, err := ReadAsset(path)
+	if err != nil {
+		t.Fatalf("ReadAsset() error: %v", err)
+	}
+	if len(content) != 1 || string(content["commands/maestro.plan.md"]) != "# Plan\n" {
+		t.Errorf("unexpected content: %v", content)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(path), "commands")); !os.IsNotExist(err) {
+		t.Error("ReadAsset should not extract files")
+	}
+	if _, err := ReadAsset(filepath.Join(t.TempDir(), "assets.rar")); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
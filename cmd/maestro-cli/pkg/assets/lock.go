@@ -0,0 +1,183 @@
+package assets
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	locksDirName  = "locks"
+	indexLockName = "index"
+	lockPoll      = 100 * time.Millisecond
+	// staleLockAge is the age at which a lock is taken to be left behind
+	// by a process that died, and is broken, unless its holder is known to
+	// be still running. A lock whose holder is known to be gone is broken
+	// at once.
+	staleLockAge = 15 * time.Minute
+	// lockRefresh is how often a held lock's modification time is renewed,
+	// so a long download doesn't age into a stale lock.
+	lockRefresh = staleLockAge / 3
+	// lockTimeout is how long to wait for a lock another process holds;
+	// longer than staleLockAge, so a left-behind lock is broken first.
+	lockTimeout = staleLockAge + 5*time.Minute
+)
+
+// fetches deduplicates concurrent Get calls for the same entry within this
+// process; the lock files do the same across processes.
+var fetches singleflight.Group
+
+// lock takes the lock called name in the cache directory, waiting while
+// another process or goroutine holds it, and returns the function that
+// releases it. Locks are files created exclusively under locks/, so they
+// work on every platform and filesystem the cache can live on. A lock file
+// records the holder's PID, host and a random token; the holder renews its
+// modification time while it holds the lock and only removes the file if
+// the token is still its own.
+func (c *CacheManager) lock(name string) (func(), error) {
+	dir := filepath.Join(c.dir, locksDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating locks directory: %w", err)
+	}
+	path := filepath.Join(dir, name+".lock")
+	token, err := lockToken()
+	if err != nil {
+		return nil, fmt.Errorf("locking %s: %w", name, err)
+	}
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = fmt.Fprintf(f, "%d %s %s\n", os.Getpid(), hostname(), token)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("locking %s: %w", name, err)
+			}
+			return holdLock(path, token), nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("locking %s: %w", name, err)
+		}
+		if info, err := os.Stat(path); err == nil && lockStale(path, info) {
+			breakLock(path, info)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for the cache lock %s; remove it if no maestro command is running", path)
+		}
+		time.Sleep(lockPoll)
+	}
+}
+
+// holdLock renews the lock at path every lockRefresh until the returned
+// function is called, which stops renewing and removes the lock if it still
+// holds token.
+func holdLock(path, token string) func() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(lockRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				if lockOwner(path) == token {
+					os.Chtimes(path, now, now)
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stop)
+			<-done
+			if lockOwner(path) == token {
+				os.Remove(path)
+			}
+		})
+	}
+}
+
+// lockStale reports whether the lock at path, last modified as info says,
+// was left behind: its holder on this host is known to be gone, or it is
+// older than staleLockAge and its holder isn't known to be running.
+func lockStale(path string, info os.FileInfo) bool {
+	pid, host, _ := readLock(path)
+	if pid > 0 && host != "" && host == hostname() {
+		if running, known := processRunning(pid); known {
+			return !running
+		}
+	}
+	return time.Since(info.ModTime()) > staleLockAge
+}
+
+// breakLock removes the stale lock at path, last modified as info says. It
+// first renames the lock to a name of its own, so of several processes
+// breaking the same lock only one removes it, and puts it back if it turns
+// out to be a newer lock taken since it was found stale.
+func breakLock(path string, info os.FileInfo) {
+	token, err := lockToken()
+	if err != nil {
+		return
+	}
+	aside := path + "." + token + ".stale"
+	if os.Rename(path, aside) != nil {
+		return
+	}
+	if moved, err := os.Stat(aside); err == nil && !os.SameFile(info, moved) {
+		if os.Link(aside, path) == nil {
+			os.Remove(aside)
+			return
+		}
+	}
+	os.Remove(aside)
+}
+
+// lockOwner returns the token recorded in the lock at path, or "" if there
+// is none.
+func lockOwner(path string) string {
+	_, _, token := readLock(path)
+	return token
+}
+
+// readLock returns the PID, host and token recorded in the lock at path.
+// Fields the lock doesn't have are left zero.
+func readLock(path string) (pid int, host, token string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, "", ""
+	}
+	fmt.Sscanf(string(data), "%d %s %s", &pid, &host, &token)
+	return pid, host, token
+}
+
+// lockToken returns a random token identifying one holder of a lock.
+func lockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil || strings.TrimSpace(name) == "" {
+		return ""
+	}
+	return strings.Fields(name)[0]
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package assets
+
+// processRunning cannot tell on this platform, so locks are only broken by
+// age.
+func processRunning(pid int) (running, known bool) {
+	return false, false
+}
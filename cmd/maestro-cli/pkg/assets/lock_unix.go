@@ -0,0 +1,15 @@
+//go:build unix
+
+package assets
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processRunning reports whether a process with pid exists. A process owned
+// by another user still counts.
+func processRunning(pid int) (running, known bool) {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM), true
+}
@@ -0,0 +1,174 @@
+// Package bundle builds and verifies offline asset bundles: archives
+// carrying maestro's assets together with a manifest and checksums, for
+// installs on machines without network access.
+package bundle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/spec-maestro/maestro-cli/internal/version"
+	"github.com/spec-maestro/maestro-cli/pkg/assets"
+	"github.com/spec-maestro/maestro-cli/pkg/tarball"
+)
+
+const (
+	// ManifestName is the bundle manifest at the root of the archive.
+	ManifestName = "bundle.yaml"
+	// ChecksumsName lists the SHA-256 of every other file in the archive.
+	ChecksumsName = "checksums.txt"
+)
+
+// Manifest describes a bundle.
+type Manifest struct {
+	// Version is the assets release the bundle was built from.
+	Version string `yaml:"version"`
+	// MinCLIVersion is the oldest maestro that can install the bundle.
+	MinCLIVersion string `yaml:"min_cli_version,omitempty"`
+}
+
+// Result is the outcome of verifying a bundle. The bundle is valid when
+// Problems is empty.
+type Result struct {
+	Manifest Manifest
+	// Files is the number of files covered by the checksums.
+	Files    int
+	Problems []string
+}
+
+// Verify reads the archive at path (tar.gz or zip) and checks its manifest,
+// checksums, and that cliVersion is at least the manifest's min_cli_version.
+// A development build ("dev") passes the version check. An error is returned
+// only when the archive can't be read at all.
+func Verify(path, cliVersion string) (*Result, error) {
+	files, err := assets.ReadAsset(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading bundle %s: %w", path, err)
+	}
+	return verify(files, cliVersion), nil
+}
+
+// Load verifies the archive at path as Verify does and returns its assets,
+// keyed by slash-separated path, without the manifest and checksums. It
+// fails when the bundle has any problem.
+func Load(path, cliVersion string) (map[string][]byte, *Manifest, error) {
+	files, err := assets.ReadAsset(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading bundle %s: %w", path, err)
+	}
+	result := verify(files, cliVersion)
+	if len(result.Problems) > 0 {
+		return nil, nil, fmt.Errorf("%s is not a valid bundle: %s; run 'maestro bundle verify' for details", path, result.Problems[0])
+	}
+	delete(files, ManifestName)
+	delete(files, ChecksumsName)
+	return files, &result.Manifest, nil
+}
+
+// Create writes files, keyed by slash-separated path, to w as a bundle
+// with manifest m and a checksum for each file.
+func Create(w io.Writer, files map[string][]byte, m Manifest, now time.Time) error {
+	if m.Version == "" {
+		return fmt.Errorf("%s needs a version", ManifestName)
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if name == ManifestName || name == ChecksumsName {
+			return fmt.Errorf("%s is reserved for the bundle itself", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var checksums bytes.Buffer
+	for _, name := range names {
+		sum := sha256.Sum256(files[name])
+		fmt.Fprintf(&checksums, "%s  %s\n", hex.EncodeToString(sum[:]), name)
+	}
+	manifest, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	archive := make(map[string][]byte, len(files)+2)
+	for name, data := range files {
+		archive[name] = data
+	}
+	archive[ManifestName] = manifest
+	archive[ChecksumsName] = checksums.Bytes()
+	return tarball.WriteFiles(w, archive, now)
+}
+
+// verify checks the files of a bundle archive.
+func verify(files map[string][]byte, cliVersion string) *Result {
+	result := &Result{}
+	problem := func(format string, args ...any) {
+		result.Problems = append(result.Problems, fmt.Sprintf(format, args...))
+	}
+
+	data, ok := files[ManifestName]
+	if !ok {
+		problem("%s is missing; this is not a maestro bundle", ManifestName)
+	} else if err := yaml.Unmarshal(data, &result.Manifest); err != nil {
+		problem("%s is not valid YAML: %v", ManifestName, err)
+	} else if result.Manifest.Version == "" {
+		problem("%s has no version", ManifestName)
+	}
+
+	if min := result.Manifest.MinCLIVersion; min != "" && cliVersion != "dev" {
+		if older, err := version.Less(cliVersion, min); err != nil {
+			problem("%s: %v", ManifestName, err)
+		} else if older {
+			problem("bundle requires maestro %s or newer, this is %s; upgrade maestro first", min, cliVersion)
+		}
+	}
+
+	data, ok = files[ChecksumsName]
+	if !ok {
+		problem("%s is missing; the bundle's files can't be verified", ChecksumsName)
+		return result
+	}
+	checksums, err := assets.ParseChecksums(bytes.NewReader(data))
+	if err != nil {
+		problem("reading %s: %v", ChecksumsName, err)
+		return result
+	}
+
+	names := make([]string, 0, len(checksums))
+	for name := range checksums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		content, ok := files[name]
+		if !ok {
+			problem("%s is listed in %s but missing from the archive", name, ChecksumsName)
+			continue
+		}
+		sum := sha256.Sum256(content)
+		if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, checksums[name]) {
+			problem("%s: checksum mismatch (expected %s, got %s)", name, checksums[name], got)
+		}
+	}
+	result.Files = len(names)
+
+	var unlisted []string
+	for name := range files {
+		if _, ok := checksums[name]; !ok && name != ManifestName && name != ChecksumsName {
+			unlisted = append(unlisted, name)
+		}
+	}
+	sort.Strings(unlisted)
+	for _, name := range unlisted {
+		problem("%s is not listed in %s", name, ChecksumsName)
+	}
+	return result
+}
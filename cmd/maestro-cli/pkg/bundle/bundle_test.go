@@ -0,0 +1,142 @@
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeBundle(t *testing.T, files map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		body := []byte(files[name])
+		tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(body))})
+		tw.Write(body)
+	}
+	tw.Close()
+	gz.Close()
+	f.Close()
+	return path
+}
+
+func sum(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+func TestVerify(t *testing.T) {
+	plan := "# Plan\n"
+	valid := map[string]string{
+		ManifestName:               "version: v1.2.0\nmin_cli_version: v0.5.0\n",
+		ChecksumsName:              sum(plan) + "  commands/maestro.plan.md\n",
+		"commands/maestro.plan.md": plan,
+	}
+
+	result, err := Verify(writeBundle(t, valid), "v0.6.1")
+	if err != nil {
+		t.Fatalf("Verify() error: %v", err)
+	}
+	if len(result.Problems) != 0 || result.Manifest.Version != "v1.2.0" || result.Files != 1 {
+		t.Errorf("valid bundle: %+v", result)
+	}
+
+	tests := []struct {
+		name    string
+		cli     string
+		change  func(map[string]string)
+		problem string
+	}{
+		{"old cli", "v0.4.9", func(map[string]string) {}, "requires maestro v0.5.0 or newer"},
+		{"dev cli", "dev", func(map[string]string) {}, ""},
+		{"no manifest", "v0.6.1", func(f map[string]string) { delete(f, ManifestName) }, "bundle.yaml is missing"},
+		{"no checksums", "v0.6.1", func(f map[string]string) { delete(f, ChecksumsName) }, "checksums.txt is missing"},
+		{"tampered", "v0.6.1", func(f map[string]string) { f["commands/maestro.plan.md"] = "# Changed\n" }, "commands/maestro.plan.md: checksum mismatch"},
+		{"missing file", "v0.6.1", func(f map[string]string) { delete(f, "commands/maestro.plan.md") }, "missing from the archive"},
+		{"unlisted file", "v0.6.1", func(f map[string]string) { f["extra.md"] = "x" }, "extra.md is not listed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := make(map[string]string)
+			for k, v := range valid {
+				files[k] = v
+			}
+			tt.change(files)
+			result, err := Verify(writeBundle(t, files), tt.cli)
+			if err != nil {
+				t.Fatalf("Verify() error: %v", err)
+			}
+			got := strings.Join(result.Problems, "\n")
+			if tt.problem == "" {
+				if got != "" {
+					t.Errorf("unexpected problems: %s", got)
+				}
+			} else if !strings.Contains(got, tt.problem) {
+				t.Errorf("problems %q, want %q", got, tt.problem)
+			}
+		})
+	}
+}
+
+func TestCreateAndLoad(t *testing.T) {
+	files := map[string][]byte{
+		".maestro/commands/maestro.plan.md": []byte("# Plan\n"),
+		".claude/commands/maestro.plan.md":  []byte("plan\n"),
+	}
+	path := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Create(f, files, Manifest{Version: "v1.2.0", MinCLIVersion: "v1.2.0"}, time.Now())
+	f.Close()
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+
+	if result, err := Verify(path, "v1.2.0"); err != nil || len(result.Problems) != 0 || result.Files != 2 {
+		t.Errorf("a created bundle should verify, got %+v, %v", result, err)
+	}
+	loaded, manifest, err := Load(path, "v1.3.0")
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if manifest.Version != "v1.2.0" || !reflect.DeepEqual(loaded, files) {
+		t.Errorf("Load() = %v, %+v; want the files created", loaded, manifest)
+	}
+	if _, _, err := Load(path, "v1.1.0"); err == nil || !strings.Contains(err.Error(), "requires maestro v1.2.0") {
+		t.Errorf("Load() by an older maestro: error = %v, want the version problem", err)
+	}
+
+	tampered := writeBundle(t, map[string]string{
+		ManifestName:                        "version: v1.2.0\n",
+		ChecksumsName:                       sum("# Plan\n") + "  .maestro/commands/maestro.plan.md\n",
+		".maestro/commands/maestro.plan.md": "# Changed\n",
+	})
+	if _, _, err := Load(tampered, "v1.2.0"); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Load() of a tampered bundle: error = %v, want a checksum mismatch", err)
+	}
+	if err := Create(io.Discard, map[string][]byte{ManifestName: nil}, Manifest{Version: "v1"}, time.Now()); err == nil {
+		t.Error("Create() should refuse a file named like the manifest")
+	}
+}
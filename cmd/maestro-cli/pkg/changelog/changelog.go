@@ -0,0 +1,151 @@
+// Package changelog renders release notes, written in GitHub markdown, as
+// plain terminal text, and picks the releases between two versions so an
+// update can show everything it brings.
+package changelog
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spec-maestro/maestro-cli/internal/version"
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+)
+
+// Entry is one release's notes.
+type Entry struct {
+	Version    string
+	Date       time.Time
+	Body       string
+	Prerelease bool
+}
+
+// Between returns the entries newer than from and no newer than to, newest
+// first. Pre-releases are only included when to is the entry itself.
+// Entries whose version can't be ordered are left out.
+func Between(entries []Entry, from, to string) ([]Entry, error) {
+	if _, err := version.Less(from, to); err != nil {
+		return nil, err
+	}
+	var picked []Entry
+	for _, e := range entries {
+		if e.Prerelease && e.Version != to {
+			continue
+		}
+		newer, err := version.Less(from, e.Version)
+		if err != nil || !newer {
+			continue
+		}
+		if later, _ := version.Less(to, e.Version); later {
+			continue
+		}
+		picked = append(picked, e)
+	}
+	sort.SliceStable(picked, func(i, j int) bool {
+		later, _ := version.Less(picked[j].Version, picked[i].Version)
+		return later
+	})
+	return picked, nil
+}
+
+// Write prints each entry's version, date, and rendered notes.
+func Write(w io.Writer, entries []Entry) {
+	for i, e := range entries {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		heading := e.Version
+		if !e.Date.IsZero() {
+			heading += " (" + e.Date.Format("2006-01-02") + ")"
+		}
+		fmt.Fprintln(w, heading)
+		body := Render(e.Body)
+		if body == "" {
+			body = "No release notes."
+		}
+		for _, line := range strings.Split(body, "\n") {
+			if line == "" {
+				fmt.Fprintln(w)
+				continue
+			}
+			fmt.Fprintln(w, "  "+line)
+		}
+	}
+}
+
+var (
+	headingRe = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*$`)
+	bulletRe  = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	imageRe   = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	linkRe    = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	strongRe  = regexp.MustCompile(`(\*\*|__)(.+?)(\*\*|__)`)
+	codeRe    = regexp.MustCompile("`([^`]*)`")
+	commentRe = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// Render turns markdown release notes into plain text: headings are
+// underlined, list items get bullets, emphasis and code markers are
+// dropped, links show their URL, and HTML comments and repeated blank
+// lines are removed.
+func Render(body string) string {
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	body = commentRe.ReplaceAllString(body, "")
+
+	var out []string
+	inCode := false
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimRight(line, " \t")
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			out = append(out, "    "+line)
+			continue
+		}
+		if m := headingRe.FindStringSubmatch(line); m != nil {
+			text := inline(m[1])
+			if len(out) > 0 && out[len(out)-1] != "" {
+				out = append(out, "")
+			}
+			out = append(out, text, strings.Repeat("-", len([]rune(text))))
+			continue
+		}
+		if m := bulletRe.FindStringSubmatch(line); m != nil {
+			out = append(out, m[1]+glyph.Bullet()+" "+inline(m[2]))
+			continue
+		}
+		out = append(out, inline(line))
+	}
+
+	// Collapse blank runs and trim blank edges
+	var lines []string
+	for _, line := range out {
+		if line == "" && (len(lines) == 0 || lines[len(lines)-1] == "") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// inline drops inline markdown from one line of text.
+func inline(s string) string {
+	s = imageRe.ReplaceAllString(s, "$1")
+	s = linkRe.ReplaceAllStringFunc(s, func(m string) string {
+		parts := linkRe.FindStringSubmatch(m)
+		if parts[1] == parts[2] {
+			return parts[2]
+		}
+		return parts[1] + " (" + parts[2] + ")"
+	})
+	s = strongRe.ReplaceAllString(s, "$2")
+	s = codeRe.ReplaceAllString(s, "$1")
+	return s
+}
@@ -0,0 +1,62 @@
+package changelog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	body := "<!-- release template -->\r\n## What's Changed\r\n\r\n\r\n* **New:** `maestro guard` by @dev in [#12](https://github.com/o/r/pull/12)\r\n  - nested item\r\n\r\n```sh\r\nmaestro guard -- make\r\n```\r\n**Full Changelog**: https://github.com/o/r/compare/v1.0.0...v1.1.0\r\n"
+	want := strings.Join([]string{
+		"What's Changed",
+		"--------------",
+		"",
+		"• New: maestro guard by @dev in #12 (https://github.com/o/r/pull/12)",
+		"  • nested item",
+		"",
+		"    maestro guard -- make",
+		"Full Changelog: https://github.com/o/r/compare/v1.0.0...v1.1.0",
+	}, "\n")
+	if got := Render(body); got != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestBetween(t *testing.T) {
+	entries := []Entry{
+		{Version: "v1.3.0-rc.1", Prerelease: true},
+		{Version: "v1.2.0"},
+		{Version: "v1.1.1"},
+		{Version: "v1.1.0"},
+		{Version: "nightly"},
+		{Version: "v1.0.0"},
+	}
+	got, err := Between(entries, "v1.0.0", "v1.2.0")
+	if err != nil {
+		t.Fatalf("Between() error: %v", err)
+	}
+	var versions []string
+	for _, e := range got {
+		versions = append(versions, e.Version)
+	}
+	if strings.Join(versions, " ") != "v1.2.0 v1.1.1 v1.1.0" {
+		t.Errorf("Between() = %v", versions)
+	}
+	if _, err := Between(entries, "dev", "v1.2.0"); err == nil {
+		t.Error("an unordered current version should be an error")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var out bytes.Buffer
+	Write(&out, []Entry{
+		{Version: "v1.1.0", Date: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), Body: "- Fix"},
+		{Version: "v1.0.1"},
+	})
+	want := "v1.1.0 (2026-02-01)\n  • Fix\n\nv1.0.1\n  No release notes.\n"
+	if out.String() != want {
+		t.Errorf("Write() = %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"time"
+)
+
+// LastUpdate records the most recent successful install or update of a
+// project's .maestro/ assets and where they came from.
+type LastUpdate struct {
+	At time.Time `yaml:"at"`
+	// Command is the maestro command that ran: init or update.
+	Command string `yaml:"command"`
+	// Source is the release tag or ref the assets came from, or
+	// "embedded <version>" for assets compiled into the binary.
+	Source string `yaml:"source"`
+	Commit string `yaml:"commit,omitempty"`
+}
+
+// UpdateSnapshot records the snapshot update took of the project before
+// changing it, which maestro rollback restores.
+type UpdateSnapshot struct {
+	// Path is the snapshot archive, relative to the project root.
+	Path    string    `yaml:"path"`
+	TakenAt time.Time `yaml:"taken_at"`
+	// From and To are the asset versions before and after the update.
+	From string `yaml:"from,omitempty"`
+	To   string `yaml:"to,omitempty"`
+	// AgentDirs are the agent directories the snapshot holds.
+	AgentDirs []string `yaml:"agent_dirs,omitempty"`
+}
+
+// RecordSnapshot stores snapshot as the one to roll the next update back to.
+func RecordSnapshot(path string, snapshot UpdateSnapshot) error {
+	cfg, err := Load(path)
+	if err != nil {
+		return err
+	}
+	cfg.Installed.Snapshot = &snapshot
+	return Save(cfg, path)
+}
+
+// RecordUpdate stores update as the project's last successful update.
+func RecordUpdate(path string, update LastUpdate) error {
+	cfg, err := Load(path)
+	if err != nil {
+		return err
+	}
+	cfg.Installed.LastUpdate = &update
+	return Save(cfg, path)
+}
+
+// RecordAgentDirUpdate records when an agent directory was last installed
+// or refreshed.
+func RecordAgentDirUpdate(path, dir string, at time.Time) error {
+	cfg, err := Load(path)
+	if err != nil {
+		return err
+	}
+	if cfg.Installed.AgentDirs == nil {
+		cfg.Installed.AgentDirs = make(map[string]InstalledAgentDir)
+	}
+	record := cfg.Installed.AgentDirs[dir]
+	record.UpdatedAt = at
+	cfg.Installed.AgentDirs[dir] = record
+	return Save(cfg, path)
+}
+
+// LastUpdatedAt returns when the project's assets were last installed or
+// updated. Projects set up before updates were recorded fall back to
+// initialized_at; the zero time means it is unknown.
+func (c *ProjectConfig) LastUpdatedAt() time.Time {
+	if c.Installed.LastUpdate != nil && !c.Installed.LastUpdate.At.IsZero() {
+		return c.Installed.LastUpdate.At
+	}
+	return c.InitializedAt
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	initialized := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := Save(&ProjectConfig{InitializedAt: initialized}, path); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, _ := Load(path)
+	if !cfg.LastUpdatedAt().Equal(initialized) {
+		t.Errorf("LastUpdatedAt() should fall back to initialized_at, got %v", cfg.LastUpdatedAt())
+	}
+
+	at := initialized.Add(48 * time.Hour)
+	if err := RecordUpdate(path, LastUpdate{At: at, Command: "update", Source: "v1.3.0", Commit: "abc123"}); err != nil {
+		t.Fatalf("RecordUpdate() error: %v", err)
+	}
+	if err := RecordAgentDirUpdate(path, ".claude", at); err != nil {
+		t.Fatalf("RecordAgentDirUpdate() error: %v", err)
+	}
+
+	cfg, _ = Load(path)
+	if got := cfg.Installed.LastUpdate; got == nil || got.Source != "v1.3.0" || got.Command != "update" || got.Commit != "abc123" {
+		t.Errorf("unexpected last update: %+v", got)
+	}
+	if !cfg.LastUpdatedAt().Equal(at) {
+		t.Errorf("LastUpdatedAt() = %v, want %v", cfg.LastUpdatedAt(), at)
+	}
+	if !cfg.Installed.AgentDirs[".claude"].UpdatedAt.Equal(at) {
+		t.Errorf("agent dir update time not recorded: %+v", cfg.Installed.AgentDirs)
+	}
+}
@@ -0,0 +1,270 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spec-maestro/maestro-cli/pkg/scriptcompat"
+)
+
+// ChecksumFiles returns the sha256 of every regular file under dirs of the
+// project at root, keyed by slash-separated path relative to root. dirs are
+// relative to root; those that do not exist are skipped.
+func ChecksumFiles(root string, dirs []string) (map[string]string, error) {
+	sums := make(map[string]string)
+	for _, dir := range dirs {
+		top := filepath.Join(root, dir)
+		err := filepath.WalkDir(top, func(path string, d fs.DirEntry, err error) error {
+			if os.IsNotExist(err) && path == top {
+				return filepath.SkipDir
+			}
+			if err != nil {
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			sum, err := fileSHA256(path)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			sums[filepath.ToSlash(rel)] = sum
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("checksumming %s: %w", dir, err)
+		}
+	}
+	return sums, nil
+}
+
+// projectRoot returns the root of the project whose config is at path.
+func projectRoot(path string) string {
+	if path == "" {
+		path = defaultConfigPath
+	}
+	return filepath.Dir(filepath.Dir(path))
+}
+
+// RecordInstall updates the install manifest: the checksums recorded under
+// each of dirs, relative to the project root, are replaced with the files now
+// on disk, and the asset version is set unless empty.
+func RecordInstall(path, assetVersion string, dirs []string) error {
+	cfg, err := Load(path)
+	if err != nil {
+		return err
+	}
+
+	sums, err := ChecksumFiles(projectRoot(path), dirs)
+	if err != nil {
+		return err
+	}
+	if cfg.Installed.Files == nil {
+		cfg.Installed.Files = make(map[string]string)
+	}
+	for file := range cfg.Installed.Files {
+		if underAny(file, dirs) {
+			delete(cfg.Installed.Files, file)
+		}
+	}
+	for file, sum := range sums {
+		cfg.Installed.Files[file] = sum
+	}
+
+	if assetVersion != "" {
+		cfg.Installed.AssetVersion = assetVersion
+	}
+	if err := recordScripts(cfg, path); err != nil {
+		return err
+	}
+	return Save(cfg, path)
+}
+
+// RecordFiles updates the manifest entries for the given files, relative to
+// the project root, leaving every other entry as it was. Files that no longer exist are dropped. The
+// asset version is set unless empty.
+func RecordFiles(path, assetVersion string, files []string) error {
+	cfg, err := Load(path)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Installed.Files == nil {
+		cfg.Installed.Files = make(map[string]string)
+	}
+	root := projectRoot(path)
+	for _, file := range files {
+		sum, err := fileSHA256(filepath.Join(root, filepath.FromSlash(file)))
+		if os.IsNotExist(err) {
+			delete(cfg.Installed.Files, file)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("checksumming %s: %w", file, err)
+		}
+		cfg.Installed.Files[file] = sum
+	}
+
+	if assetVersion != "" {
+		cfg.Installed.AssetVersion = assetVersion
+	}
+	if err := recordScripts(cfg, path); err != nil {
+		return err
+	}
+	return Save(cfg, path)
+}
+
+// RecordChecksums sets the manifest entries for the given files to the given
+// checksums, leaving every other entry as it was. Update records the
+// checksums of the versions it installed rather than of the files on disk,
+// so files whose local edits it kept are still reported as drift and merged
+// against the right base next time. The asset version is set unless empty.
+func RecordChecksums(path, assetVersion string, sums map[string]string) error {
+	cfg, err := Load(path)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Installed.Files == nil {
+		cfg.Installed.Files = make(map[string]string)
+	}
+	for file, sum := range sums {
+		cfg.Installed.Files[file] = sum
+	}
+
+	if assetVersion != "" {
+		cfg.Installed.AssetVersion = assetVersion
+	}
+	if err := recordScripts(cfg, path); err != nil {
+		return err
+	}
+	return Save(cfg, path)
+}
+
+// recordScripts refreshes the compatibility matrix from the scripts next to
+// the config at path. A project without scripts keeps none.
+func recordScripts(cfg *ProjectConfig, path string) error {
+	if path == "" {
+		path = defaultConfigPath
+	}
+	dir := filepath.Join(filepath.Dir(path), "scripts")
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		cfg.Installed.Scripts = nil
+		return nil
+	}
+	matrix, err := scriptcompat.Build(dir)
+	if err != nil {
+		return fmt.Errorf("reading scripts: %w", err)
+	}
+	cfg.Installed.Scripts = matrix
+	return nil
+}
+
+// ForgetFiles drops the manifest entries for files maestro no longer
+// manages, such as those a new release removed.
+func ForgetFiles(path string, files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		delete(cfg.Installed.Files, file)
+	}
+	return Save(cfg, path)
+}
+
+// DeclineAgentDirs remembers agent directories the user chose not to
+// install, so later updates do not offer them again.
+func DeclineAgentDirs(path string, dirs []string) error {
+	if len(dirs) == 0 {
+		return nil
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		return err
+	}
+
+	declined := cfg.Installed.DeclinedAgentDirs
+	for _, dir := range dirs {
+		if !contains(declined, dir) {
+			declined = append(declined, dir)
+		}
+	}
+	sort.Strings(declined)
+	cfg.Installed.DeclinedAgentDirs = declined
+	return Save(cfg, path)
+}
+
+// Drift compares the recorded checksums with the files of the project at
+// root and returns the files that were edited and those that were deleted,
+// sorted.
+func (s InstalledSection) Drift(root string) (modified, missing []string) {
+	for file, want := range s.Files {
+		got, err := fileSHA256(filepath.Join(root, filepath.FromSlash(file)))
+		switch {
+		case os.IsNotExist(err):
+			missing = append(missing, file)
+		case err != nil || got != want:
+			modified = append(modified, file)
+		}
+	}
+	sort.Strings(modified)
+	sort.Strings(missing)
+	return modified, missing
+}
+
+// IsDeclined reports whether the user declined installing an agent directory.
+func (s InstalledSection) IsDeclined(dir string) bool {
+	return contains(s.DeclinedAgentDirs, dir)
+}
+
+func fileSHA256(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func underAny(file string, dirs []string) bool {
+	for _, dir := range dirs {
+		dir = strings.TrimSuffix(filepath.ToSlash(dir), "/")
+		if file == dir || strings.HasPrefix(file, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func without(list []string, s string) []string {
+	out := list[:0:0]
+	for _, v := range list {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
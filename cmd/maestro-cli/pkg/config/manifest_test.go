@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordInstallAndDrift(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".maestro", "scripts"), 0755)
+	os.MkdirAll(filepath.Join(dir, ".claude"), 0755)
+	os.WriteFile(filepath.Join(dir, ".maestro", "scripts", "a.sh"), []byte("a"), 0644)
+	os.WriteFile(filepath.Join(dir, ".maestro", "scripts", "b.sh"), []byte("b"), 0644)
+	os.WriteFile(filepath.Join(dir, ".claude", "agent.md"), []byte("agent"), 0644)
+
+	path := filepath.Join(dir, ".maestro", "config.yaml")
+	if err := RecordInstall(path, "v1.2.0", []string{".maestro/scripts", ".claude", ".missing"}); err != nil {
+		t.Fatalf("RecordInstall() error: %v", err)
+	}
+
+	cfg, _ := Load(path)
+	if cfg.Installed.AssetVersion != "v1.2.0" || len(cfg.Installed.Files) != 3 {
+		t.Fatalf("unexpected manifest: %+v", cfg.Installed)
+	}
+	if modified, missing := cfg.Installed.Drift(dir); len(modified)+len(missing) != 0 {
+		t.Errorf("fresh install should have no drift, got %v %v", modified, missing)
+	}
+
+	os.WriteFile(filepath.Join(dir, ".maestro", "scripts", "a.sh"), []byte("edited"), 0644)
+	os.Remove(filepath.Join(dir, ".claude", "agent.md"))
+	modified, missing := cfg.Installed.Drift(dir)
+	if len(modified) != 1 || modified[0] != ".maestro/scripts/a.sh" {
+		t.Errorf("modified = %v", modified)
+	}
+	if len(missing) != 1 || missing[0] != ".claude/agent.md" {
+		t.Errorf("missing = %v", missing)
+	}
+
+	// Re-recording one root replaces its entries and keeps the others.
+	os.Remove(filepath.Join(dir, ".maestro", "scripts", "b.sh"))
+	if err := RecordInstall(path, "", []string{".maestro/scripts"}); err != nil {
+		t.Fatalf("RecordInstall() error: %v", err)
+	}
+	cfg, _ = Load(path)
+	if cfg.Installed.AssetVersion != "v1.2.0" {
+		t.Errorf("empty version should keep the recorded one, got %q", cfg.Installed.AssetVersion)
+	}
+	if _, ok := cfg.Installed.Files[".maestro/scripts/b.sh"]; ok {
+		t.Error("removed file should be dropped from the manifest")
+	}
+	if _, ok := cfg.Installed.Files[".claude/agent.md"]; !ok {
+		t.Error("entries under other roots should be kept")
+	}
+	if _, ok := cfg.Installed.Scripts["a.sh"]; !ok || len(cfg.Installed.Scripts) != 1 {
+		t.Errorf("the script matrix should list the installed scripts, got %v", cfg.Installed.Scripts)
+	}
+}
+
+func TestDeclineAgentDirs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	if err := DeclineAgentDirs(path, []string{".opencode", ".codex", ".opencode"}); err != nil {
+		t.Fatalf("DeclineAgentDirs() error: %v", err)
+	}
+	cfg, _ := Load(path)
+	if len(cfg.Installed.DeclinedAgentDirs) != 2 || !cfg.Installed.IsDeclined(".opencode") {
+		t.Fatalf("declined = %v", cfg.Installed.DeclinedAgentDirs)
+	}
+
+	if err := RecordAgentDir(path, ".opencode", "main", "abc123"); err != nil {
+		t.Fatalf("RecordAgentDir() error: %v", err)
+	}
+	cfg, _ = Load(path)
+	if cfg.Installed.IsDeclined(".opencode") || !cfg.Installed.IsDeclined(".codex") {
+		t.Errorf("installing a dir should clear only its decline, got %v", cfg.Installed.DeclinedAgentDirs)
+	}
+}
@@ -3,18 +3,29 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/spec-maestro/maestro-cli/pkg/scriptcompat"
 	"gopkg.in/yaml.v3"
 )
 
 const defaultConfigPath = ".maestro/config.yaml"
 
-// ProjectConfig represents the .maestro/config.yaml structure.
+// ProjectConfig represents the .maestro/config.yaml structure. Source is the
+// owner/repo of a custom assets repository set with init --from; empty means
+// the upstream maestro repository.
 type ProjectConfig struct {
 	CLIVersion    string                 `yaml:"cli_version,omitempty"`
+	Source        string                 `yaml:"source,omitempty"`
 	InitializedAt time.Time              `yaml:"initialized_at,omitempty"`
 	Project       ProjectSection         `yaml:"project,omitempty"`
+	Installed     InstalledSection       `yaml:"installed,omitempty"`
+	Newline       string                 `yaml:"newline,omitempty"`
+	Sync          SyncSection            `yaml:"sync,omitempty"`
 	Custom        map[string]interface{} `yaml:"custom,omitempty"`
 }
 
@@ -25,11 +36,50 @@ type ProjectSection struct {
 	BaseBranch  string `yaml:"base_branch,omitempty"`
 }
 
+// SyncSection configures sharing .maestro/state/ between machines.
+type SyncSection struct {
+	Backend string `yaml:"backend,omitempty"`
+	Remote  string `yaml:"remote,omitempty"`
+	Branch  string `yaml:"branch,omitempty"`
+}
+
+// InstalledSection records what maestro installed into the project. It is
+// the manifest update and doctor use to detect drift and to remember which
+// agent directories the user declined.
+type InstalledSection struct {
+	AssetVersion      string                       `yaml:"asset_version,omitempty"`
+	AgentDirs         map[string]InstalledAgentDir `yaml:"agent_dirs,omitempty"`
+	DeclinedAgentDirs []string                     `yaml:"declined_agent_dirs,omitempty"`
+	// Files maps each installed file (relative to the project root) to its
+	// sha256 as written.
+	Files      map[string]string `yaml:"files,omitempty"`
+	LastUpdate *LastUpdate       `yaml:"last_update,omitempty"`
+	// Snapshot is where update saved the project before its last run.
+	Snapshot *UpdateSnapshot `yaml:"snapshot,omitempty"`
+	// Scripts is the compatibility matrix of the installed scripts: the
+	// flags each accepts, which doctor checks agent commands against.
+	Scripts scriptcompat.Matrix `yaml:"scripts,omitempty"`
+}
+
+// InstalledAgentDir records the upstream source of an installed agent
+// directory and the maestro commands it provides.
+type InstalledAgentDir struct {
+	Ref       string    `yaml:"ref,omitempty"`
+	Commit    string    `yaml:"commit,omitempty"`
+	Commands  []string  `yaml:"commands,omitempty"`
+	UpdatedAt time.Time `yaml:"updated_at,omitempty"`
+	// Blobs maps each file installed from upstream, relative to the
+	// directory, to its git blob SHA, so a refresh can skip the files
+	// that did not change.
+	Blobs map[string]string `yaml:"blobs,omitempty"`
+}
+
 // Load reads and parses the config file at the given path.
 func Load(path string) (*ProjectConfig, error) {
 	if path == "" {
 		path = defaultConfigPath
 	}
+
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -37,25 +87,89 @@ func Load(path string) (*ProjectConfig, error) {
 		}
 		return nil, fmt.Errorf("reading config: %w", err)
 	}
+
 	var cfg ProjectConfig
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("parsing config: %w", err)
 	}
+
 	return &cfg, nil
 }
 
 // Save writes the config to disk, preserving existing content.
+// Top-level keys that ProjectConfig does not model (e.g. agent_routing,
+// compile_gate) are kept as-is, including their comments.
 func Save(cfg *ProjectConfig, path string) error {
 	if path == "" {
 		path = defaultConfigPath
 	}
-	data, err := yaml.Marshal(cfg)
+
+	var updated yaml.Node
+	if err := updated.Encode(cfg); err != nil {
+		return fmt.Errorf("marshaling config: %w", err)
+	}
+
+	if existing, err := os.ReadFile(path); err == nil {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(existing, &doc); err == nil && len(doc.Content) == 1 && doc.Content[0].Kind == yaml.MappingNode {
+			mergeModeledKeys(doc.Content[0], &updated)
+			data, err := yaml.Marshal(&doc)
+			if err != nil {
+				return fmt.Errorf("marshaling config: %w", err)
+			}
+			return os.WriteFile(path, data, 0644)
+		}
+	}
+
+	data, err := yaml.Marshal(&updated)
 	if err != nil {
 		return fmt.Errorf("marshaling config: %w", err)
 	}
 	return os.WriteFile(path, data, 0644)
 }
 
+// mergeModeledKeys replaces every key ProjectConfig models in dst with its
+// value from src, removing modeled keys that are now empty.
+func mergeModeledKeys(dst, src *yaml.Node) {
+	values := make(map[string]*yaml.Node, len(src.Content)/2)
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		values[src.Content[i].Value] = src.Content[i+1]
+	}
+
+	merged := make([]*yaml.Node, 0, len(dst.Content))
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(dst.Content); i += 2 {
+		key := dst.Content[i]
+		if !modeledKeys[key.Value] {
+			merged = append(merged, key, dst.Content[i+1])
+			continue
+		}
+		if value, ok := values[key.Value]; ok {
+			merged = append(merged, key, value)
+			seen[key.Value] = true
+		}
+	}
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		if !seen[src.Content[i].Value] {
+			merged = append(merged, src.Content[i], src.Content[i+1])
+		}
+	}
+	dst.Content = merged
+}
+
+// modeledKeys holds the top-level yaml keys owned by ProjectConfig.
+var modeledKeys = func() map[string]bool {
+	keys := make(map[string]bool)
+	t := reflect.TypeOf(ProjectConfig{})
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}()
+
 // UpdateCLIVersion updates only the cli_version field in the config.
 func UpdateCLIVersion(path, version string) error {
 	cfg, err := Load(path)
@@ -65,3 +179,132 @@ func UpdateCLIVersion(path, version string) error {
 	cfg.CLIVersion = version
 	return Save(cfg, path)
 }
+
+// RecordAgentDir records the upstream ref and commit an agent directory was
+// installed from.
+func RecordAgentDir(path, dir, ref, commit string) error {
+	cfg, err := Load(path)
+	if err != nil {
+		return err
+	}
+	if cfg.Installed.AgentDirs == nil {
+		cfg.Installed.AgentDirs = make(map[string]InstalledAgentDir)
+	}
+	record := cfg.Installed.AgentDirs[dir]
+	record.Ref, record.Commit = ref, commit
+	cfg.Installed.AgentDirs[dir] = record
+	cfg.Installed.DeclinedAgentDirs = without(cfg.Installed.DeclinedAgentDirs, dir)
+	return Save(cfg, path)
+}
+
+// RecordAgentDirBlobs replaces the blob SHAs recorded for the files of an
+// installed agent directory.
+func RecordAgentDirBlobs(path, dir string, blobs map[string]string) error {
+	cfg, err := Load(path)
+	if err != nil {
+		return err
+	}
+	if cfg.Installed.AgentDirs == nil {
+		cfg.Installed.AgentDirs = make(map[string]InstalledAgentDir)
+	}
+	record := cfg.Installed.AgentDirs[dir]
+	record.Blobs = blobs
+	cfg.Installed.AgentDirs[dir] = record
+	return Save(cfg, path)
+}
+
+// RecordAgentCommands records how the maestro commands are invoked in an
+// installed agent directory, e.g. /maestro.specify.
+func RecordAgentCommands(path, dir string, commands []string) error {
+	cfg, err := Load(path)
+	if err != nil {
+		return err
+	}
+	if cfg.Installed.AgentDirs == nil {
+		cfg.Installed.AgentDirs = make(map[string]InstalledAgentDir)
+	}
+	record := cfg.Installed.AgentDirs[dir]
+	record.Commands = commands
+	cfg.Installed.AgentDirs[dir] = record
+	return Save(cfg, path)
+}
+
+// RememberConflictActions sets the conflict.dirs entry of each directory in
+// actions, e.g. {".claude": "backup"}, replacing any entry for the same
+// directory. Like unmodeled keys on Save, the rest of the file is kept
+// as-is, including its comments.
+func RememberConflictActions(path string, actions map[string]string) error {
+	if path == "" {
+		path = defaultConfigPath
+	}
+
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parsing config: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("parsing config: %s is not a mapping", path)
+	}
+	dirs, err := childMapping(root, "conflict")
+	if err == nil {
+		dirs, err = childMapping(dirs, "dirs")
+	}
+	if err != nil {
+		return fmt.Errorf("invalid conflict.dirs in %s: %w", path, err)
+	}
+
+	names := make([]string, 0, len(actions))
+	for dir := range actions {
+		names = append(names, dir)
+	}
+	sort.Strings(names)
+	for _, dir := range names {
+		value := &yaml.Node{Kind: yaml.ScalarNode, Value: actions[dir]}
+		found := false
+		for i := 0; i+1 < len(dirs.Content); i += 2 {
+			if cleanDir(dirs.Content[i].Value) == cleanDir(dir) {
+				dirs.Content[i+1] = value
+				found = true
+			}
+		}
+		if !found {
+			dirs.Content = append(dirs.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: dir}, value)
+		}
+	}
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return fmt.Errorf("marshaling config: %w", err)
+	}
+	return os.WriteFile(path, out, 0644)
+}
+
+// childMapping returns the mapping under key in node, adding an empty one
+// when key is missing or null.
+func childMapping(node *yaml.Node, key string) (*yaml.Node, error) {
+	value := mappingValue(node, key)
+	switch {
+	case value == nil:
+		value = &yaml.Node{Kind: yaml.MappingNode}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	case value.Kind == yaml.ScalarNode && (value.Tag == "!!null" || value.Value == ""):
+		*value = yaml.Node{Kind: yaml.MappingNode}
+	case value.Kind != yaml.MappingNode:
+		return nil, fmt.Errorf("%s is not a mapping", key)
+	}
+	return value, nil
+}
+
+// cleanDir normalizes a directory name as written in the config, so
+// ".claude/" and ".claude" are the same entry.
+func cleanDir(dir string) string {
+	return path.Clean(strings.TrimSuffix(dir, "/"))
+}
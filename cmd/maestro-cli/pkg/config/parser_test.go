@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -56,3 +57,88 @@ func TestUpdateCLIVersion(t *testing.T) {
 		t.Errorf("CLIVersion after update: got %q, want %q", cfg.CLIVersion, "v0.2.0")
 	}
 }
+
+func TestSavePreservesUnmodeledKeys(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+
+	original := "# Maestro Configuration\ncli_version: v0.1.0\n\n# Agent routing\nagent_routing:\n  backend: general # Go backend\n"
+	os.WriteFile(path, []byte(original), 0644)
+
+	if err := UpdateCLIVersion(path, "v0.2.0"); err != nil {
+		t.Fatalf("UpdateCLIVersion() error: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	content := string(data)
+	for _, want := range []string{"cli_version: v0.2.0", "agent_routing:", "backend: general # Go backend", "# Agent routing"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("saved config missing %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestRecordAgentDir(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	os.WriteFile(path, []byte("cli_version: v0.1.0\n"), 0644)
+
+	if err := RecordAgentDir(path, ".claude", "main", "abc123"); err != nil {
+		t.Fatalf("RecordAgentDir() error: %v", err)
+	}
+
+	cfg, _ := Load(path)
+	got := cfg.Installed.AgentDirs[".claude"]
+	if got.Ref != "main" || got.Commit != "abc123" {
+		t.Errorf("recorded agent dir: got %+v", got)
+	}
+	if cfg.CLIVersion != "v0.1.0" {
+		t.Errorf("CLIVersion should be preserved, got %q", cfg.CLIVersion)
+	}
+}
+
+func TestRecordAgentCommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	if err := RecordAgentCommands(path, ".claude", []string{"/maestro.plan", "/maestro.specify"}); err != nil {
+		t.Fatalf("RecordAgentCommands() error: %v", err)
+	}
+	if err := RecordAgentDir(path, ".claude", "main", "def456"); err != nil {
+		t.Fatalf("RecordAgentDir() error: %v", err)
+	}
+
+	cfg, _ := Load(path)
+	got := cfg.Installed.AgentDirs[".claude"]
+	if len(got.Commands) != 2 || got.Commands[1] != "/maestro.specify" {
+		t.Errorf("commands should survive recording the source commit, got %+v", got)
+	}
+	if got.Commit != "def456" {
+		t.Errorf("commit = %q", got.Commit)
+	}
+}
+
+func TestRememberConflictActions(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	os.WriteFile(path, []byte("cli_version: v0.1.0 # pinned\nconflict:\n  agents: overwrite\n  dirs:\n    .claude/: cancel\n"), 0644)
+
+	if err := RememberConflictActions(path, map[string]string{".claude": "backup", ".opencode": "overwrite"}); err != nil {
+		t.Fatalf("RememberConflictActions() error: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	content := string(data)
+	for _, want := range []string{"cli_version: v0.1.0 # pinned", "agents: overwrite", ".claude/: backup", ".opencode: overwrite"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("saved config missing %q:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "cancel") {
+		t.Errorf("the existing .claude entry should be replaced:\n%s", content)
+	}
+
+	os.WriteFile(path, []byte("conflict: backup\n"), 0644)
+	if err := RememberConflictActions(path, map[string]string{".claude": "backup"}); err == nil {
+		t.Error("a conflict key that is not a mapping should be an error")
+	}
+}
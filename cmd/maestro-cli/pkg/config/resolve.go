@@ -0,0 +1,205 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/spec-maestro/maestro-cli/pkg/userdirs"
+)
+
+// Sources a resolved value can come from, highest precedence first.
+const (
+	SourceEnv     = "env"
+	SourceProject = "project"
+	SourceGlobal  = "global"
+	SourceDefault = "default"
+)
+
+// envPrefix prefixes environment overrides: sync.remote is MAESTRO_SYNC_REMOTE.
+const envPrefix = "MAESTRO_"
+
+// ErrKeyNotFound is returned by Resolve when no source defines the key.
+var ErrKeyNotFound = errors.New("config key not found")
+
+// Defaults holds the built-in values for keys the CLI and scripts read.
+var Defaults = map[string]string{
+	"accessible":           "false",
+	"cache.evict_stale":    "true",
+	"channel":              "stable",
+	"deprecation_warnings": "true",
+	"hyperlinks":           "auto",
+	"newline":              "keep",
+	"project.base_branch":  "main",
+	"sync.backend":         "git",
+	"sync.remote":          "origin",
+	"sync.branch":          "maestro-state",
+	"timeout":              "0",
+	"update_check":         "true",
+}
+
+// Resolved is a config value together with where it came from.
+type Resolved struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// Resolver merges environment overrides, the project config, the global
+// config, and built-in defaults, in that order of precedence.
+type Resolver struct {
+	ProjectPath string // default: .maestro/config.yaml
+	GlobalPath  string // default: GlobalConfigPath()
+}
+
+// GlobalConfigPath returns the per-user config file,
+// ~/.config/maestro/config.yaml, or maestro-data/config/config.yaml next to
+// the executable in portable mode.
+func GlobalConfigPath() (string, error) {
+	dir, err := userdirs.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// EnvVar returns the environment variable that overrides key.
+func EnvVar(key string) string {
+	name := strings.NewReplacer(".", "_", "-", "_").Replace(key)
+	return envPrefix + strings.ToUpper(name)
+}
+
+// Resolve returns the effective value of a dotted key such as "sync.remote".
+// Mappings and lists are returned as YAML. It returns ErrKeyNotFound when no
+// source defines the key.
+func (r *Resolver) Resolve(key string) (*Resolved, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, fmt.Errorf("empty config key")
+	}
+
+	if value, ok := os.LookupEnv(EnvVar(key)); ok {
+		return &Resolved{Key: key, Value: value, Source: SourceEnv}, nil
+	}
+
+	projectPath := r.ProjectPath
+	if projectPath == "" {
+		projectPath = defaultConfigPath
+	}
+	if value, ok, err := lookupFile(projectPath, key); err != nil {
+		return nil, err
+	} else if ok {
+		return &Resolved{Key: key, Value: value, Source: SourceProject}, nil
+	}
+
+	globalPath := r.GlobalPath
+	if globalPath == "" {
+		// Without a home directory there is simply no global layer.
+		globalPath, _ = GlobalConfigPath()
+	}
+	if globalPath != "" {
+		if value, ok, err := lookupFile(globalPath, key); err != nil {
+			return nil, err
+		} else if ok {
+			return &Resolved{Key: key, Value: value, Source: SourceGlobal}, nil
+		}
+	}
+
+	if value, ok := Defaults[key]; ok {
+		return &Resolved{Key: key, Value: value, Source: SourceDefault}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, key)
+}
+
+// GlobalValue returns key from the global config alone, for settings that
+// may hold secrets and so never belong in the committed project config.
+func GlobalValue(key string) (string, bool, error) {
+	path, err := GlobalConfigPath()
+	if err != nil {
+		return "", false, nil
+	}
+	return lookupFile(path, key)
+}
+
+// String resolves key and returns its value, or fallback when it is not set
+// anywhere. Unreadable config files are reported as errors.
+func (r *Resolver) String(key, fallback string) (string, error) {
+	resolved, err := r.Resolve(key)
+	if errors.Is(err, ErrKeyNotFound) {
+		return fallback, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return resolved.Value, nil
+}
+
+// DefaultKeys returns the keys with built-in defaults, sorted.
+func DefaultKeys() []string {
+	keys := make([]string, 0, len(Defaults))
+	for key := range Defaults {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// lookupFile reads a dotted key from a YAML file. A missing file or key is
+// not an error. Empty scalars count as unset, matching omitempty on save.
+func lookupFile(path, key string) (string, bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return "", false, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return "", false, nil
+	}
+
+	node := doc.Content[0]
+	for _, part := range strings.Split(key, ".") {
+		node = mappingValue(node, part)
+		if node == nil {
+			return "", false, nil
+		}
+	}
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" || node.Value == "" {
+			return "", false, nil
+		}
+		return node.Value, true, nil
+	default:
+		out, err := yaml.Marshal(node)
+		if err != nil {
+			return "", false, fmt.Errorf("encoding %s: %w", key, err)
+		}
+		return strings.TrimRight(string(out), "\n"), true, nil
+	}
+}
+
+// mappingValue returns the value for key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfig(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestResolvePrecedence(t *testing.T) {
+	project := writeConfig(t, t.TempDir(), "sync:\n  remote: upstream\n")
+	global := writeConfig(t, t.TempDir(), "sync:\n  remote: backup\n  branch: shared-state\n")
+	r := &Resolver{ProjectPath: project, GlobalPath: global}
+
+	tests := []struct {
+		key, env, want, source string
+	}{
+		{key: "sync.remote", want: "upstream", source: SourceProject},
+		{key: "sync.branch", want: "shared-state", source: SourceGlobal},
+		{key: "sync.backend", want: "git", source: SourceDefault},
+		{key: "sync.remote", env: "fork", want: "fork", source: SourceEnv},
+	}
+	for _, tt := range tests {
+		if tt.env != "" {
+			t.Setenv(EnvVar(tt.key), tt.env)
+		}
+		got, err := r.Resolve(tt.key)
+		if err != nil {
+			t.Fatalf("Resolve(%q) error: %v", tt.key, err)
+		}
+		if got.Value != tt.want || got.Source != tt.source {
+			t.Errorf("Resolve(%q) = %q from %s, want %q from %s", tt.key, got.Value, got.Source, tt.want, tt.source)
+		}
+	}
+}
+
+func TestResolveUnmodeledAndMissingKeys(t *testing.T) {
+	project := writeConfig(t, t.TempDir(), "compile_gate:\n  go: go build ./...\nbd_stable_prefix: ''\n")
+	r := &Resolver{ProjectPath: project, GlobalPath: filepath.Join(t.TempDir(), "missing.yaml")}
+
+	got, err := r.Resolve("compile_gate.go")
+	if err != nil || got.Value != "go build ./..." {
+		t.Errorf("Resolve(compile_gate.go) = %+v, %v", got, err)
+	}
+
+	got, err = r.Resolve("compile_gate")
+	if err != nil || got.Value != "go: go build ./..." {
+		t.Errorf("mapping should resolve as YAML, got %+v, %v", got, err)
+	}
+
+	if _, err := r.Resolve("bd_stable_prefix"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("empty value should be unset, got %v", err)
+	}
+	if _, err := r.Resolve("no.such.key"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected ErrKeyNotFound, got %v", err)
+	}
+
+	value, err := r.String("no.such.key", "fallback")
+	if err != nil || value != "fallback" {
+		t.Errorf("String() = %q, %v; want fallback", value, err)
+	}
+}
+
+func TestEnvVar(t *testing.T) {
+	if got := EnvVar("project.base_branch"); got != "MAESTRO_PROJECT_BASE_BRANCH" {
+		t.Errorf("EnvVar() = %q", got)
+	}
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UnknownKey is a key in config.yaml that neither maestro nor the installed
+// scripts read, usually a typo.
+type UnknownKey struct {
+	// Key is the dotted path of the key, e.g. project.base_brnach.
+	Key  string
+	Line int
+}
+
+func (k UnknownKey) String() string {
+	return fmt.Sprintf("%s (line %d)", k.Key, k.Line)
+}
+
+// scriptKeys are the top-level keys the installed scripts and commands
+// read, whose contents are theirs to define.
+var scriptKeys = []string{
+	"agent_routing",
+	"bd_label_template",
+	"bd_stable_prefix",
+	"compile_gate",
+	"review_sizing",
+	"size_mapping",
+}
+
+// settingKeys are the subkeys of the settings maestro reads by dotted key
+// rather than through ProjectConfig. A nil list leaves the subkeys
+// unchecked.
+var settingKeys = func() map[string][]string {
+	keys := map[string][]string{
+		"conflict": {"agents", "assets", "dirs", "maestro"},
+		"http":     {"headers"},
+	}
+	for key := range Defaults {
+		section, sub, nested := strings.Cut(key, ".")
+		if modeledKeys[section] {
+			continue
+		}
+		if nested {
+			keys[section] = append(keys[section], sub)
+		} else if _, ok := keys[section]; !ok {
+			keys[section] = nil
+		}
+	}
+	for _, key := range scriptKeys {
+		keys[key] = nil
+	}
+	return keys
+}()
+
+// Validate parses a config.yaml and checks it against the keys maestro
+// knows. A value of the wrong type, such as text for initialized_at, is an
+// error; unknown keys are returned in file order.
+func Validate(data []byte) ([]UnknownKey, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("parsing config: the top level is not a mapping")
+	}
+	var cfg ProjectConfig
+	if err := root.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+
+	var unknown []UnknownKey
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if modeledKeys[key.Value] {
+			unknown = append(unknown, unknownFields(key.Value, value, modeledField(key.Value))...)
+			continue
+		}
+		subkeys, ok := settingKeys[key.Value]
+		if !ok {
+			unknown = append(unknown, UnknownKey{Key: key.Value, Line: key.Line})
+			continue
+		}
+		if subkeys == nil || value.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 0; j+1 < len(value.Content); j += 2 {
+			sub := value.Content[j]
+			if !contains(subkeys, sub.Value) {
+				unknown = append(unknown, UnknownKey{Key: key.Value + "." + sub.Value, Line: sub.Line})
+			}
+		}
+	}
+	return unknown, nil
+}
+
+// modeledField returns the type of the ProjectConfig field for a top-level
+// key.
+func modeledField(key string) reflect.Type {
+	t := reflect.TypeOf(ProjectConfig{})
+	for i := 0; i < t.NumField(); i++ {
+		if yamlName(t.Field(i)) == key {
+			return t.Field(i).Type
+		}
+	}
+	return nil
+}
+
+// unknownFields returns the keys of node, found at path, that type t does
+// not have, descending into nested structs and maps of structs.
+func unknownFields(path string, node *yaml.Node, t reflect.Type) []UnknownKey {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	var unknown []UnknownKey
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		child := path + "." + key.Value
+		switch t.Kind() {
+		case reflect.Map:
+			// Map keys such as .claude are quoted to keep the path readable
+			child = fmt.Sprintf("%s[%q]", path, key.Value)
+			unknown = append(unknown, unknownFields(child, value, t.Elem())...)
+		case reflect.Struct:
+			field, ok := structField(t, key.Value)
+			if !ok {
+				unknown = append(unknown, UnknownKey{Key: child, Line: key.Line})
+				continue
+			}
+			unknown = append(unknown, unknownFields(child, value, field.Type)...)
+		}
+	}
+	return unknown
+}
+
+// structField returns the field of struct type t that yaml key name
+// decodes into.
+func structField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if yamlName(t.Field(i)) == name {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func yamlName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+	if name == "" {
+		return strings.ToLower(f.Name)
+	}
+	return name
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	data := `cli_version: v1.0.0
+project:
+  name: demo
+  base_brnach: main
+installed:
+  agent_dirs:
+    .claude:
+      ref: main
+      comit: abc
+  last_update:
+    at: 2024-01-01T00:00:00Z
+    command: update
+conflict:
+  dirs:
+    .claude: backup
+  agent: backup
+sync:
+  remote: upstream
+cache:
+  evict_stale: false
+compile_gate:
+  go: go build ./...
+custom:
+  anything: goes
+timout: 5m
+`
+	unknown, err := Validate([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, key := range unknown {
+		got = append(got, key.String())
+	}
+	want := []string{
+		"project.base_brnach (line 4)",
+		`installed.agent_dirs[".claude"].comit (line 9)`,
+		"conflict.agent (line 16)",
+		"timout (line 25)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unknown keys = %q, want %q", got, want)
+	}
+
+	if _, err := Validate([]byte("initialized_at: yesterday\n")); err == nil {
+		t.Error("a value of the wrong type should be an error")
+	}
+	if _, err := Validate([]byte("- a list\n")); err == nil || !strings.Contains(err.Error(), "not a mapping") {
+		t.Errorf("a list at the top level: err = %v", err)
+	}
+	if unknown, err := Validate(nil); err != nil || len(unknown) != 0 {
+		t.Errorf("an empty config: %v, %v", unknown, err)
+	}
+}
+
+// The config maestro ships with must validate cleanly.
+func TestValidateRepoConfig(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "..", "..", ".maestro", "config.yaml"))
+	if err != nil {
+		t.Skip(err)
+	}
+	if unknown, err := Validate(data); err != nil || len(unknown) != 0 {
+		t.Errorf("Validate(.maestro/config.yaml) = %v, %v", unknown, err)
+	}
+}
@@ -0,0 +1,134 @@
+// Package deadline bounds the total runtime of a maestro command. It holds
+// the process-wide context that network requests, prompts, and child
+// processes watch, so a stuck download or an unanswered prompt ends when
+// --timeout runs out instead of hanging an automated pipeline.
+package deadline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrExceeded is what operations stopped by the deadline fail with.
+var ErrExceeded = errors.New("command timed out")
+
+var (
+	mu      sync.Mutex
+	ctx     = context.Background()
+	cancel  context.CancelFunc
+	timeout time.Duration
+)
+
+// Start bounds the rest of the command to d, replacing an earlier
+// deadline. A d of zero or less removes the bound.
+func Start(d time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	ctx, cancel, timeout = context.Background(), nil, 0
+	if d > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), d)
+		timeout = d
+	}
+}
+
+// Stop removes the bound, as when the command has returned.
+func Stop() {
+	Start(0)
+}
+
+// Context returns the context that is done when the deadline passes.
+// Without a deadline it is never done.
+func Context() context.Context {
+	mu.Lock()
+	defer mu.Unlock()
+	return ctx
+}
+
+// Timeout returns the bound given to Start, or 0 when there is none.
+func Timeout() time.Duration {
+	mu.Lock()
+	defer mu.Unlock()
+	return timeout
+}
+
+// Exceeded reports whether the deadline has passed.
+func Exceeded() bool {
+	return errors.Is(Context().Err(), context.DeadlineExceeded)
+}
+
+// Err returns ErrExceeded, naming the timeout, once the deadline has
+// passed, and nil before.
+func Err() error {
+	if !Exceeded() {
+		return nil
+	}
+	return fmt.Errorf("%w after %s (--timeout)", ErrExceeded, Timeout())
+}
+
+// Transport cancels each request it sends, including the reading of its
+// response body, when the deadline passes.
+type Transport struct {
+	// Base sends the requests; http.DefaultTransport when nil.
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	bound := Context()
+	if bound.Done() == nil {
+		return base.RoundTrip(req)
+	}
+	if err := Err(); err != nil {
+		return nil, err
+	}
+
+	reqCtx, cancelReq := context.WithCancel(req.Context())
+	stop := context.AfterFunc(bound, cancelReq)
+	resp, err := base.RoundTrip(req.WithContext(reqCtx))
+	if err != nil {
+		stop()
+		cancelReq()
+		if deadlineErr := Err(); deadlineErr != nil {
+			return nil, deadlineErr
+		}
+		return nil, err
+	}
+	resp.Body = &body{ReadCloser: resp.Body, done: func() { stop(); cancelReq() }}
+	return resp, nil
+}
+
+// body releases the request's context once the response is closed, and
+// reports reads cut short by the deadline as ErrExceeded.
+type body struct {
+	io.ReadCloser
+	done func()
+	once sync.Once
+}
+
+func (b *body) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		if deadlineErr := Err(); deadlineErr != nil {
+			return n, deadlineErr
+		}
+	}
+	return n, err
+}
+
+func (b *body) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
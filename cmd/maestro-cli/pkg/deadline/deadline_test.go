@@ -0,0 +1,83 @@
+package deadline
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStartStop(t *testing.T) {
+	defer Stop()
+	if Context().Done() != nil || Timeout() != 0 || Err() != nil {
+		t.Fatal("without Start there should be no deadline")
+	}
+
+	Start(time.Millisecond)
+	<-Context().Done()
+	if !Exceeded() || !errors.Is(Err(), ErrExceeded) {
+		t.Fatalf("Err() = %v, want ErrExceeded after the deadline", Err())
+	}
+	if got := Err().Error(); got != "command timed out after 1ms (--timeout)" {
+		t.Errorf("Err() = %q", got)
+	}
+
+	Stop()
+	if Exceeded() || Timeout() != 0 {
+		t.Error("Stop should remove the deadline")
+	}
+}
+
+func TestTransport(t *testing.T) {
+	defer Stop()
+	release := make(chan struct{})
+	defer close(release)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	client := &http.Client{Transport: Transport{}}
+
+	// Without a deadline requests pass through
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer fast.Close()
+	resp, err := client.Get(fast.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "ok" {
+		t.Fatalf("body = %q", body)
+	}
+
+	// A stuck body is cut off at the deadline
+	Start(100 * time.Millisecond)
+	resp, err = client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	_, err = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !errors.Is(err, ErrExceeded) {
+		t.Fatalf("reading a stuck body: err = %v, want ErrExceeded", err)
+	}
+	if waited := time.Since(start); waited > 5*time.Second {
+		t.Errorf("the read took %s after the deadline", waited)
+	}
+
+	// Requests after the deadline fail without being sent
+	if _, err := client.Get(fast.URL); !errors.Is(err, ErrExceeded) {
+		t.Errorf("request after the deadline: err = %v, want ErrExceeded", err)
+	}
+}
@@ -0,0 +1,130 @@
+// Package deprecation keeps the registry of deprecated commands, flags, and
+// config keys, and warns about each one used at most once per invocation,
+// so the CLI can change its surface without silently breaking scripts.
+package deprecation
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Kind is what part of the CLI's surface a deprecation covers.
+type Kind string
+
+const (
+	Command   Kind = "command"
+	Flag      Kind = "flag"
+	ConfigKey Kind = "config key"
+)
+
+// Deprecation describes one deprecated command, flag, or config key.
+type Deprecation struct {
+	Kind Kind `json:"kind"`
+	// Name identifies it: a command path such as "maestro scripts update",
+	// a flag with its command such as "maestro update --force-self", or a
+	// dotted config key such as "sync.remote".
+	Name string `json:"name"`
+	// Since is the release that deprecated it.
+	Since string `json:"since"`
+	// RemovedIn is the release expected to remove it, if decided.
+	RemovedIn string `json:"removed_in,omitempty"`
+	// Replacement is what to use instead, if anything.
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// Message is the warning for d, e.g. "the flag maestro update --old is
+// deprecated since v1.4.0 and will be removed in v2.0.0; use --new instead".
+func (d Deprecation) Message() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "the %s %s is deprecated", d.Kind, d.Name)
+	if d.Since != "" {
+		fmt.Fprintf(&b, " since %s", d.Since)
+	}
+	if d.RemovedIn != "" {
+		fmt.Fprintf(&b, " and will be removed in %s", d.RemovedIn)
+	}
+	if d.Replacement != "" {
+		fmt.Fprintf(&b, "; use %s instead", d.Replacement)
+	}
+	return b.String()
+}
+
+var (
+	mu         sync.Mutex
+	registry             = make(map[string]Deprecation)
+	warned               = make(map[string]bool)
+	out        io.Writer = os.Stderr
+	suppressed bool
+)
+
+func key(kind Kind, name string) string {
+	return string(kind) + "\x00" + name
+}
+
+// Register adds d to the registry, replacing an entry of the same kind and
+// name.
+func Register(d Deprecation) {
+	mu.Lock()
+	defer mu.Unlock()
+	registry[key(d.Kind, d.Name)] = d
+}
+
+// All returns the registry sorted by kind and name.
+func All() []Deprecation {
+	mu.Lock()
+	defer mu.Unlock()
+	all := make([]Deprecation, 0, len(registry))
+	for _, d := range registry {
+		all = append(all, d)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Kind != all[j].Kind {
+			return all[i].Kind < all[j].Kind
+		}
+		return all[i].Name < all[j].Name
+	})
+	return all
+}
+
+// Lookup returns the deprecation of the kind and name, if registered.
+func Lookup(kind Kind, name string) (Deprecation, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	d, ok := registry[key(kind, name)]
+	return d, ok
+}
+
+// Suppress turns the warnings off, or back on.
+func Suppress(off bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	suppressed = off
+}
+
+// SetOutput sets where warnings are written (default: stderr).
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	out = w
+}
+
+// Warn writes the warning for the kind and name when it is registered and
+// has not been warned about yet. It reports whether it is deprecated,
+// whether or not a warning was written.
+func Warn(kind Kind, name string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	d, ok := registry[key(kind, name)]
+	if !ok {
+		return false
+	}
+	if !suppressed && !warned[key(kind, name)] {
+		warned[key(kind, name)] = true
+		fmt.Fprintf(out, "Warning: %s (hide with deprecation_warnings: false; list with 'maestro deprecations')\n", d.Message())
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package deprecation
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWarnOnce(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	Register(Deprecation{Kind: Flag, Name: "maestro test --old", Since: "v1.4.0", RemovedIn: "v2.0.0", Replacement: "--new"})
+	Register(Deprecation{Kind: ConfigKey, Name: "test.old", Since: "v1.4.0"})
+
+	if Warn(Flag, "maestro test --new") {
+		t.Error("Warn() should report a flag that is not registered as not deprecated")
+	}
+	for i := 0; i < 2; i++ {
+		if !Warn(Flag, "maestro test --old") {
+			t.Error("Warn() should report a registered flag as deprecated")
+		}
+	}
+	want := "Warning: the flag maestro test --old is deprecated since v1.4.0 and will be removed in v2.0.0; use --new instead"
+	if got := buf.String(); strings.Count(got, "Warning:") != 1 || !strings.HasPrefix(got, want) {
+		t.Errorf("warnings = %q, want one starting with %q", got, want)
+	}
+
+	buf.Reset()
+	Suppress(true)
+	defer Suppress(false)
+	if !Warn(ConfigKey, "test.old") || buf.Len() != 0 {
+		t.Errorf("suppressed Warn() wrote %q", buf.String())
+	}
+
+	all := All()
+	if len(all) < 2 || all[0].Kind != ConfigKey {
+		t.Errorf("All() = %v, want config keys sorted before flags", all)
+	}
+	if d, ok := Lookup(ConfigKey, "test.old"); !ok || d.Message() != "the config key test.old is deprecated since v1.4.0" {
+		t.Errorf("Lookup() = %v, %v", d, ok)
+	}
+}
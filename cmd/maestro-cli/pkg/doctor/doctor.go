@@ -0,0 +1,177 @@
+// Package doctor keeps the registry of checks maestro doctor runs, the
+// results they report, and the checks a project declares in
+// .maestro/doctor.yaml to enforce its own setup invariants.
+package doctor
+
+import (
+	"sync"
+	"time"
+)
+
+// Result is the outcome of one check.
+type Result struct {
+	Name    string
+	OK      bool
+	Message string
+	Fix     string
+	// Commands are the shell commands that apply the fix, for --emit-fixes.
+	Commands []string
+	// Warn marks a warning, which does not make doctor fail.
+	Warn bool
+	// Path is the file or directory checked, when Name is a path.
+	Path string
+	// Files are the files named in Message, printed as links.
+	Files []string
+}
+
+// Context is what checks run against. The working directory is the
+// project root.
+type Context struct {
+	// Root is the project root. Checks resolve the files and run the
+	// commands the project declares from it.
+	Root string
+	// MaestroDir is the project's .maestro directory.
+	MaestroDir string
+	// Deep asks for the slower checks of file contents.
+	Deep bool
+	// Network asks for the checks that reach GitHub.
+	Network bool
+	// Now is when doctor runs.
+	Now time.Time
+}
+
+// Check is one thing doctor verifies. A check can report several results,
+// e.g. one per required directory, or none when it does not apply.
+type Check interface {
+	Name() string
+	Run(ctx Context) []Result
+}
+
+// Func returns a Check named name that calls run.
+func Func(name string, run func(ctx Context) []Result) Check {
+	return funcCheck{name: name, run: run}
+}
+
+type funcCheck struct {
+	name string
+	run  func(ctx Context) []Result
+}
+
+func (c funcCheck) Name() string             { return c.name }
+func (c funcCheck) Run(ctx Context) []Result { return c.run(ctx) }
+
+var (
+	mu       sync.Mutex
+	registry []Check
+)
+
+// Register adds c to the registry, replacing a check of the same name in
+// place. Checks run in the order they were first registered.
+func Register(c Check) {
+	mu.Lock()
+	defer mu.Unlock()
+	for i, existing := range registry {
+		if existing.Name() == c.Name() {
+			registry[i] = c
+			return
+		}
+	}
+	registry = append(registry, c)
+}
+
+// Checks returns the registered checks in order.
+func Checks() []Check {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]Check{}, registry...)
+}
+
+// Run runs the registered checks, followed by extra, and returns their
+// results in order.
+func Run(ctx Context, extra ...Check) []Result {
+	return RunChecks(ctx, append(Checks(), extra...))
+}
+
+// RunChecks runs checks and returns their results in order.
+func RunChecks(ctx Context, checks []Check) []Result {
+	var results []Result
+	for _, c := range checks {
+		results = append(results, c.Run(ctx)...)
+	}
+	return results
+}
+
+// Ignore removes the checks named in names, so they do not run, and
+// returns the names that matched no check.
+func Ignore(checks []Check, names []string) ([]Check, []string) {
+	matched := map[string]bool{}
+	var kept []Check
+	for _, c := range checks {
+		if contains(names, c.Name()) {
+			matched[c.Name()] = true
+			continue
+		}
+		kept = append(kept, c)
+	}
+	return kept, unmatched(names, matched)
+}
+
+// IgnoreResults removes the results whose name or path is in names, and
+// returns the names that matched no result.
+func IgnoreResults(results []Result, names []string) ([]Result, []string) {
+	matched := map[string]bool{}
+	var kept []Result
+	for _, r := range results {
+		switch {
+		case contains(names, r.Name):
+			matched[r.Name] = true
+		case r.Path != "" && contains(names, r.Path):
+			matched[r.Path] = true
+		default:
+			kept = append(kept, r)
+		}
+	}
+	return kept, unmatched(names, matched)
+}
+
+// Strict returns results with every warning that did not pass turned into
+// a failure.
+func Strict(results []Result) []Result {
+	strict := make([]Result, len(results))
+	for i, r := range results {
+		if !r.OK {
+			r.Warn = false
+		}
+		strict[i] = r
+	}
+	return strict
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func unmatched(names []string, matched map[string]bool) []string {
+	var rest []string
+	for _, n := range names {
+		if !matched[n] {
+			rest = append(rest, n)
+		}
+	}
+	return rest
+}
+
+// Passed reports whether no check failed; warnings do not count.
+func Passed(results []Result) bool {
+	for _, r := range results {
+		if r.Status() == StatusFail {
+			return false
+		}
+	}
+	return true
+}
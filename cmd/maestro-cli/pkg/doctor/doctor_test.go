@@ -0,0 +1,211 @@
+package doctor
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	saved := registry
+	defer func() { registry = saved }()
+	registry = nil
+
+	Register(Func("first", func(Context) []Result { return []Result{{Name: "a", OK: true}} }))
+	Register(Func("second", func(ctx Context) []Result {
+		if !ctx.Deep {
+			return nil
+		}
+		return []Result{{Name: "deep"}}
+	}))
+	Register(Func("first", func(Context) []Result { return []Result{{Name: "b", OK: true}} }))
+
+	extra := Func("extra", func(Context) []Result { return []Result{{Name: "c", Warn: true}} })
+	var names []string
+	results := Run(Context{}, extra)
+	for _, r := range results {
+		names = append(names, r.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"b", "c"}) {
+		t.Errorf("Run() = %v, want a replaced check in place, then the extra one", names)
+	}
+	if !Passed(results) {
+		t.Error("warnings should not fail")
+	}
+	if results := Run(Context{Deep: true}); Passed(results) || len(results) != 2 {
+		t.Errorf("Run() with Deep = %+v, want the deep check to fail", results)
+	}
+}
+
+func TestIgnoreAndStrict(t *testing.T) {
+	checks := []Check{
+		Func("agents", func(Context) []Result { return []Result{{Name: ".opencode/", Path: ".opencode/", Warn: true}} }),
+		Func("network", func(Context) []Result { return []Result{{Name: "api.github.com"}} }),
+		Func("tools", func(Context) []Result {
+			return []Result{{Name: "jq", Warn: true}, {Name: "git", OK: true, Warn: true}}
+		}),
+	}
+
+	kept, rest := Ignore(checks, []string{"network", "jq", "nope"})
+	if len(kept) != 2 || !reflect.DeepEqual(rest, []string{"jq", "nope"}) {
+		t.Fatalf("Ignore() = %d checks, %v unmatched", len(kept), rest)
+	}
+	results, rest := IgnoreResults(RunChecks(Context{}, kept), rest)
+	if len(results) != 2 || !reflect.DeepEqual(rest, []string{"nope"}) {
+		t.Fatalf("IgnoreResults() = %+v, %v unmatched", results, rest)
+	}
+	if !Passed(results) {
+		t.Error("warnings should not fail without Strict")
+	}
+
+	strict := Strict(results)
+	if Passed(strict) || strict[0].Warn || !strict[1].Warn {
+		t.Errorf("Strict() = %+v, want the failed warning to fail and the passed one kept", strict)
+	}
+	if !results[0].Warn {
+		t.Error("Strict should not modify its argument")
+	}
+}
+
+func TestProjectChecks(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("command checks run with sh")
+	}
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, ".env.example"), nil, 0644)
+
+	if checks, err := LoadProject(root); err != nil || len(checks) != 0 {
+		t.Fatalf("a missing %s should declare no checks, got %v, %v", ProjectFile, checks, err)
+	}
+
+	os.MkdirAll(filepath.Join(root, ".maestro"), 0755)
+	os.WriteFile(filepath.Join(root, filepath.FromSlash(ProjectFile)), []byte(`checks:
+  - file: .env.example
+  - name: docker compose file
+    file: "compose*.yaml"
+    severity: warning
+    fix: Add a compose file
+  - command: echo checking; exit 3
+    fix_command: make setup
+  - command: sleep 5
+    timeout: 50ms
+  - tool: sh
+  - tool: maestro-no-such-tool
+  - tool: sh
+    version_args: ["-c", "echo tool version 2.10.1"]
+    min_version: "2.9"
+  - tool: sh
+    version_args: ["-c", "echo v1.2"]
+    min_version: v1.10.0
+  - command: test -f .env.example
+`), 0644)
+	checks, err := LoadProject(root)
+	if err != nil {
+		t.Fatalf("LoadProject() error: %v", err)
+	}
+
+	type outcome struct {
+		name, status, message string
+	}
+	var got []outcome
+	results := RunChecks(Context{Root: root}, checks)
+	for _, r := range results {
+		got = append(got, outcome{r.Name, r.Status(), r.Message})
+	}
+	want := []outcome{
+		{".env.example", StatusPass, "found"},
+		{"docker compose file", StatusWarn, "missing"},
+		{"$ echo checking; exit 3", StatusFail, "exit status 3: checking"},
+		{"$ sleep 5", StatusFail, "timed out after 50ms"},
+		{"sh", StatusPass, "found on PATH"},
+		{"maestro-no-such-tool", StatusFail, "not found on PATH"},
+		{"sh >= 2.9", StatusPass, "2.10.1 (>= 2.9)"},
+		{"sh >= v1.10.0", StatusFail, "1.2 is older than v1.10.0"},
+		{"$ test -f .env.example", StatusPass, "succeeded"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("project checks =\n%v\nwant\n%v", got, want)
+	}
+	if c := results[2].Commands; len(c) != 1 || c[0] != "make setup" {
+		t.Errorf("fix_command should be the fix's command, got %v", c)
+	}
+
+	for data, want := range map[string]string{
+		"checks:\n  - file: a\n    command: b\n":        "exactly one of",
+		"checks:\n  - command: b\n    min_version: 1\n": "min_version needs a tool",
+		"checks:\n  - file: a\n    severity: fatal\n":   "unknown severity",
+		"checks:\n  - command: b\n    timeout: soon\n":  "invalid timeout",
+		"checks:\n  - fille: a\n":                       "field fille not found",
+	} {
+		if _, err := ParseProject([]byte(data)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseProject(%q) error = %v, want %q", data, err, want)
+		}
+	}
+}
+
+// TestWriteFormats tests that results are written as JSON and JUnit XML,
+// with the status, severity, and fix of each.
+func TestWriteFormats(t *testing.T) {
+	results := []Result{
+		{Name: ".maestro/", OK: true, Message: "found", Fix: "never shown", Path: ".maestro/"},
+		{Name: ".maestro/state/", Message: "missing", Fix: "Run 'maestro init'", Commands: []string{"mkdir -p .maestro/state"}},
+		{Name: ".claude/", Message: "not found (optional)", Fix: "Optional: add it", Warn: true},
+	}
+
+	var out bytes.Buffer
+	if err := WriteJSON(&out, results); err != nil {
+		t.Fatalf("WriteJSON() error: %v", err)
+	}
+	var got struct {
+		OK     bool     `json:"ok"`
+		Checks []Report `json:"checks"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	want := []Report{
+		{Name: ".maestro/", Status: "pass", Severity: "error", Message: "found", Path: ".maestro/"},
+		{Name: ".maestro/state/", Status: "fail", Severity: "error", Message: "missing", Fix: "Run 'maestro init'", Commands: []string{"mkdir -p .maestro/state"}},
+		{Name: ".claude/", Status: "warn", Severity: "warning", Message: "not found (optional)", Fix: "Optional: add it"},
+	}
+	if got.OK || !reflect.DeepEqual(got.Checks, want) {
+		t.Errorf("JSON = %+v, want ok false and %+v", got, want)
+	}
+
+	out.Reset()
+	if err := WriteJUnit(&out, results); err != nil {
+		t.Fatalf("WriteJUnit() error: %v", err)
+	}
+	var suite struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Skipped  int `xml:"skipped,attr"`
+		Cases    []struct {
+			Name    string `xml:"name,attr"`
+			Failure *struct {
+				Message string `xml:"message,attr"`
+				Body    string `xml:",chardata"`
+			} `xml:"failure"`
+			Skipped *struct{} `xml:"skipped"`
+		} `xml:"testcase"`
+	}
+	if err := xml.Unmarshal(out.Bytes(), &suite); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, out.String())
+	}
+	if suite.Tests != 3 || suite.Failures != 1 || suite.Skipped != 1 || len(suite.Cases) != 3 {
+		t.Fatalf("JUnit counts wrong:\n%s", out.String())
+	}
+	if f := suite.Cases[1].Failure; f == nil || f.Message != "missing" || f.Body != "Fix: Run 'maestro init'\nmkdir -p .maestro/state" {
+		t.Errorf("the failed check should be a failure with its fix, got %+v", f)
+	}
+	if suite.Cases[0].Failure != nil || suite.Cases[0].Skipped != nil || suite.Cases[2].Skipped == nil {
+		t.Errorf("passing checks should pass and warnings be skipped:\n%s", out.String())
+	}
+}
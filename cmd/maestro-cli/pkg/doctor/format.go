@@ -0,0 +1,216 @@
+package doctor
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+	"github.com/spec-maestro/maestro-cli/pkg/output"
+	"github.com/spec-maestro/maestro-cli/pkg/pathfmt"
+)
+
+// Formats are the output formats Write supports.
+var Formats = []string{"text", "json", "junit"}
+
+// Statuses and severities of a result in the machine-readable formats.
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Report is a result as the json format writes it.
+type Report struct {
+	Name     string   `json:"name"`
+	Status   string   `json:"status"`
+	Severity string   `json:"severity"`
+	Message  string   `json:"message"`
+	Fix      string   `json:"fix,omitempty"`
+	Commands []string `json:"commands,omitempty"`
+	Path     string   `json:"path,omitempty"`
+}
+
+// ValidateFormat checks that format is one of Formats, or a template= or
+// jsonpath= format applied to the json one.
+func ValidateFormat(format string) error {
+	return output.Validate(format, Formats...)
+}
+
+// Status returns whether r passed, only warns, or failed.
+func (r Result) Status() string {
+	switch {
+	case r.OK:
+		return StatusPass
+	case r.Warn:
+		return StatusWarn
+	}
+	return StatusFail
+}
+
+// Severity returns how much a failure of r matters: warnings never fail
+// doctor.
+func (r Result) Severity() string {
+	if r.Warn {
+		return SeverityWarning
+	}
+	return SeverityError
+}
+
+// Report returns r as the json format writes it. A passing check has
+// nothing to fix.
+func (r Result) Report() Report {
+	c := Report{
+		Name:     r.Name,
+		Status:   r.Status(),
+		Severity: r.Severity(),
+		Message:  r.Message,
+		Path:     r.Path,
+	}
+	if !r.OK {
+		c.Fix, c.Commands = r.Fix, r.Commands
+	}
+	return c
+}
+
+// Write writes results to w in format: text, json, or junit.
+func Write(w io.Writer, format string, results []Result) error {
+	switch {
+	case format == "junit":
+		return WriteJUnit(w, results)
+	case output.Data(format):
+		return output.Write(w, format, jsonReport(results))
+	}
+	WriteText(w, results)
+	return nil
+}
+
+// WriteText writes a line per result with its status symbol, followed by
+// the fix of each result that did not pass.
+func WriteText(w io.Writer, results []Result) {
+	for _, r := range results {
+		name := fmt.Sprintf("%-30s", r.Name)
+		if r.Path != "" {
+			name = pathfmt.Pad(r.Path, 30)
+		}
+		message := linkFiles(r.Message, r.Files)
+		if r.OK {
+			fmt.Fprintf(w, "%s %s %s\n", glyph.OK(), name, message)
+			continue
+		}
+		// Warnings use the warning symbol and don't affect exit code
+		symbol := glyph.Fail()
+		if r.Warn {
+			symbol = glyph.Warn()
+		}
+		fmt.Fprintf(w, "%s %s %s\n", symbol, name, message)
+		if r.Fix != "" {
+			fmt.Fprintf(w, "  Fix: %s\n", r.Fix)
+		}
+	}
+}
+
+// linkFiles turns each of files named in message into a hyperlink when
+// hyperlinks are on.
+func linkFiles(message string, files []string) string {
+	if !pathfmt.Hyperlinks() || len(files) == 0 {
+		return message
+	}
+	// Longer paths first, so one that contains another is matched whole
+	sorted := append([]string{}, files...)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	var pairs []string
+	for _, file := range sorted {
+		pairs = append(pairs, file, pathfmt.Link(file, file))
+	}
+	return strings.NewReplacer(pairs...).Replace(message)
+}
+
+// WriteJSON writes results as one JSON object, with ok false when a check
+// failed.
+func WriteJSON(w io.Writer, results []Result) error {
+	return output.Write(w, "json", jsonReport(results))
+}
+
+// jsonReport is results as the json format writes them.
+func jsonReport(results []Result) interface{} {
+	out := struct {
+		OK     bool     `json:"ok"`
+		Checks []Report `json:"checks"`
+	}{OK: Passed(results), Checks: make([]Report, 0, len(results))}
+	for _, r := range results {
+		out.Checks = append(out.Checks, r.Report())
+	}
+	return out
+}
+
+// junitCase is a check as a JUnit test case.
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+// junitMessage is the failure or skipped element of a test case: message
+// is the check's message and the body its fix.
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes results as a JUnit XML report with one test case per
+// result. Failed checks are failures, and warnings are skipped, so they
+// show up in CI without failing the build.
+func WriteJUnit(w io.Writer, results []Result) error {
+	type suite struct {
+		XMLName  xml.Name    `xml:"testsuite"`
+		Name     string      `xml:"name,attr"`
+		Tests    int         `xml:"tests,attr"`
+		Failures int         `xml:"failures,attr"`
+		Skipped  int         `xml:"skipped,attr"`
+		Cases    []junitCase `xml:"testcase"`
+	}
+	s := suite{Name: "maestro doctor", Tests: len(results)}
+	for _, r := range results {
+		c := junitCase{Name: r.Name, Classname: "maestro.doctor"}
+		message := &junitMessage{Message: r.Message, Body: fixText(r)}
+		switch r.Status() {
+		case StatusFail:
+			message.Type = r.Severity()
+			c.Failure = message
+			s.Failures++
+		case StatusWarn:
+			c.Skipped = message
+			s.Skipped++
+		}
+		s.Cases = append(s.Cases, c)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// fixText returns the fix of r followed by the commands that apply it.
+func fixText(r Result) string {
+	lines := make([]string, 0, 1+len(r.Commands))
+	if r.Fix != "" {
+		lines = append(lines, "Fix: "+r.Fix)
+	}
+	lines = append(lines, r.Commands...)
+	return strings.Join(lines, "\n")
+}
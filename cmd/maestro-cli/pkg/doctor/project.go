@@ -0,0 +1,238 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/spec-maestro/maestro-cli/internal/version"
+)
+
+// ProjectFile is where a project declares its own checks, relative to the
+// project root.
+const ProjectFile = ".maestro/doctor.yaml"
+
+// defaultCommandTimeout is how long a command check may run when it sets
+// no timeout.
+const defaultCommandTimeout = 30 * time.Second
+
+// ProjectCheck is a check declared in ProjectFile. Exactly one of File,
+// Command, and Tool is set.
+type ProjectCheck struct {
+	// CheckName names the check in the output; by default it is derived
+	// from what the check verifies.
+	CheckName string `yaml:"name"`
+	// File must exist, relative to the project root. Glob patterns match
+	// when any file does.
+	File string `yaml:"file"`
+	// Command must exit 0 when run with sh -c from the project root.
+	Command string `yaml:"command"`
+	// Timeout limits Command, e.g. 10s (default: 30s).
+	Timeout string `yaml:"timeout"`
+	// Tool must be on PATH, and at least MinVersion when set.
+	Tool       string `yaml:"tool"`
+	MinVersion string `yaml:"min_version"`
+	// VersionArgs print the tool's version (default: --version).
+	VersionArgs []string `yaml:"version_args"`
+	// Fix tells the user how to fix a failure, and FixCommand is the shell
+	// command that does, for --emit-fixes.
+	Fix        string `yaml:"fix"`
+	FixCommand string `yaml:"fix_command"`
+	// Severity is error (the default), which makes doctor fail, or warning.
+	Severity string `yaml:"severity"`
+
+	timeout time.Duration
+}
+
+// LoadProject reads the checks the project at root declares in
+// ProjectFile. A missing file declares none.
+func LoadProject(root string) ([]Check, error) {
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(ProjectFile)))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", ProjectFile, err)
+	}
+	return ParseProject(data)
+}
+
+// ParseProject parses the checks of a ProjectFile. Unknown keys are
+// errors, so a misspelled key does not silently disable a check.
+func ParseProject(data []byte) ([]Check, error) {
+	var file struct {
+		Checks []*ProjectCheck `yaml:"checks"`
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			// One line per error reads better in doctor's report
+			msg := strings.ReplaceAll(strings.Join(typeErr.Errors, "; "), " in type doctor.ProjectCheck", "")
+			return nil, fmt.Errorf("parsing %s: %s", ProjectFile, msg)
+		}
+		return nil, fmt.Errorf("parsing %s: %w", ProjectFile, err)
+	}
+
+	checks := make([]Check, 0, len(file.Checks))
+	for i, c := range file.Checks {
+		if err := c.validate(); err != nil {
+			return nil, fmt.Errorf("%s: check %d: %w", ProjectFile, i+1, err)
+		}
+		checks = append(checks, c)
+	}
+	return checks, nil
+}
+
+// validate checks that c declares exactly one thing to verify, and parses
+// its timeout.
+func (c *ProjectCheck) validate() error {
+	set := 0
+	for _, v := range []string{c.File, c.Command, c.Tool} {
+		if strings.TrimSpace(v) != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("set exactly one of file, command, or tool")
+	}
+	if c.MinVersion != "" {
+		if c.Tool == "" {
+			return errors.New("min_version needs a tool")
+		}
+		if _, err := version.Less(c.MinVersion, c.MinVersion); err != nil {
+			return fmt.Errorf("min_version: %w", err)
+		}
+	}
+	if c.Severity != "" && c.Severity != SeverityError && c.Severity != SeverityWarning {
+		return fmt.Errorf("unknown severity %q (want error or warning)", c.Severity)
+	}
+	c.timeout = defaultCommandTimeout
+	if c.Timeout != "" {
+		if c.Command == "" {
+			return errors.New("timeout needs a command")
+		}
+		d, err := time.ParseDuration(c.Timeout)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid timeout %q, e.g. 10s", c.Timeout)
+		}
+		c.timeout = d
+	}
+	return nil
+}
+
+// Name returns the check's name, or a description of what it verifies.
+func (c *ProjectCheck) Name() string {
+	switch {
+	case c.CheckName != "":
+		return c.CheckName
+	case c.File != "":
+		return c.File
+	case c.Command != "":
+		return "$ " + c.Command
+	case c.MinVersion != "":
+		return fmt.Sprintf("%s >= %s", c.Tool, c.MinVersion)
+	}
+	return c.Tool
+}
+
+// Run runs the check from the project root.
+func (c *ProjectCheck) Run(ctx Context) []Result {
+	r := Result{
+		Name: c.Name(),
+		Fix:  c.Fix,
+		Warn: c.Severity == SeverityWarning,
+	}
+	if c.FixCommand != "" {
+		r.Commands = []string{c.FixCommand}
+	}
+	switch {
+	case c.File != "":
+		r.Path = c.File
+		r.OK, r.Message = c.runFile(ctx.Root)
+	case c.Command != "":
+		r.OK, r.Message = c.runCommand(ctx.Root)
+	default:
+		r.OK, r.Message = c.runTool(ctx.Root)
+	}
+	return []Result{r}
+}
+
+func (c *ProjectCheck) runFile(root string) (bool, string) {
+	matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(c.File)))
+	if err != nil {
+		return false, fmt.Sprintf("invalid pattern: %v", err)
+	}
+	if len(matches) == 0 {
+		return false, "missing"
+	}
+	return true, "found"
+}
+
+func (c *ProjectCheck) runCommand(root string) (bool, string) {
+	runCtx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(runCtx, "sh", "-c", c.Command)
+	cmd.Dir = root
+	// Children of the shell may keep its output open after it is killed
+	cmd.WaitDelay = time.Second
+	out, err := cmd.CombinedOutput()
+	switch {
+	case runCtx.Err() == context.DeadlineExceeded:
+		return false, fmt.Sprintf("timed out after %s", c.timeout)
+	case err != nil:
+		message := err.Error()
+		if line := lastLine(out); line != "" {
+			message += ": " + line
+		}
+		return false, message
+	}
+	return true, "succeeded"
+}
+
+// versionPattern finds the version in a tool's output, such as 1.22.1 in
+// "go version go1.22.1 linux/amd64".
+var versionPattern = regexp.MustCompile(`\d+\.\d+(\.\d+)?`)
+
+func (c *ProjectCheck) runTool(root string) (bool, string) {
+	path, err := exec.LookPath(c.Tool)
+	if err != nil {
+		return false, "not found on PATH"
+	}
+	if c.MinVersion == "" {
+		return true, "found on PATH"
+	}
+	args := c.VersionArgs
+	if len(args) == 0 {
+		args = []string{"--version"}
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Dir = root
+	out, err := cmd.CombinedOutput()
+	have := versionPattern.FindString(string(out))
+	if err != nil || have == "" {
+		return false, fmt.Sprintf("could not read the version from '%s %s'", c.Tool, strings.Join(args, " "))
+	}
+	if older, err := version.Less(have, c.MinVersion); err != nil || older {
+		return false, fmt.Sprintf("%s is older than %s", have, c.MinVersion)
+	}
+	return true, fmt.Sprintf("%s (>= %s)", have, c.MinVersion)
+}
+
+// lastLine returns the last non-empty line of out, where commands usually
+// say why they failed.
+func lastLine(out []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
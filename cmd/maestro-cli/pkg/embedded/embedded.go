@@ -103,6 +103,27 @@ func FetchFile(filePath string) ([]byte, error) {
 	return content, nil
 }
 
+// Files returns every embedded file keyed by its logical path (e.g.
+// ".maestro/constitution.md").
+func Files() (map[string][]byte, error) {
+	result := make(map[string][]byte)
+	err := fs.WalkDir(resources, embeddedRoot, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := resources.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("reading embedded file %s: %w", filePath, err)
+		}
+		result[strings.TrimPrefix(filePath, embeddedRoot+"/")] = content
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walking embedded resources: %w", err)
+	}
+	return result, nil
+}
+
 // ListAgentDirs returns the agent configuration directory names (e.g.
 // ".claude", ".opencode") that are present in the embedded resources.
 func ListAgentDirs() []string {
@@ -0,0 +1,127 @@
+// Package envreport describes the local environment maestro runs in — the
+// platform, shell, git, and the agent CLIs on PATH — for setup debugging
+// and support requests. Everything is detected locally; nothing is sent
+// anywhere.
+package envreport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spec-maestro/maestro-cli/internal/version"
+)
+
+// Tools lists the CLIs the report looks for, in report order.
+var Tools = []string{"git", "bd", "gh", "claude", "opencode", "codex"}
+
+// versionTimeout bounds each tool's --version call, so a hung CLI cannot
+// stall init or doctor.
+const versionTimeout = 3 * time.Second
+
+// Tool is a CLI found, or not, on PATH.
+type Tool struct {
+	Name    string `json:"name"`
+	Path    string `json:"path,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// Report is the detected environment.
+type Report struct {
+	Maestro  string `json:"maestro"`
+	Platform string `json:"platform"`
+	Shell    string `json:"shell"`
+	Tools    []Tool `json:"tools"`
+}
+
+// Detector finds tools and reads their versions; tests replace it.
+type Detector struct {
+	LookPath func(string) (string, error)
+	Version  func(path string) (string, error)
+}
+
+// System finds tools on PATH and runs them with --version.
+func System() Detector {
+	return Detector{LookPath: exec.LookPath, Version: toolVersion}
+}
+
+// Detect describes the current environment.
+func Detect() *Report {
+	return System().Detect()
+}
+
+// Detect describes the current environment using d to find tools.
+func (d Detector) Detect() *Report {
+	r := &Report{
+		Maestro:  version.String(),
+		Platform: runtime.GOOS + "/" + runtime.GOARCH,
+		Shell:    shell(),
+	}
+	for _, name := range Tools {
+		r.Tools = append(r.Tools, d.Tool(name))
+	}
+	return r
+}
+
+// Tool finds name on PATH and reads its version. Path is empty when it is
+// not found, and Version when --version fails.
+func (d Detector) Tool(name string) Tool {
+	tool := Tool{Name: name}
+	if path, err := d.LookPath(name); err == nil {
+		tool.Path = path
+		if v, err := d.Version(path); err == nil {
+			tool.Version = v
+		}
+	}
+	return tool
+}
+
+// Write renders the report as an aligned list.
+func (r *Report) Write(w io.Writer) error {
+	fmt.Fprintln(w, "Environment:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "  maestro\t%s\n", r.Maestro)
+	fmt.Fprintf(tw, "  platform\t%s\n", r.Platform)
+	fmt.Fprintf(tw, "  shell\t%s\n", r.Shell)
+	for _, tool := range r.Tools {
+		switch {
+		case tool.Path == "":
+			fmt.Fprintf(tw, "  %s\tnot found\n", tool.Name)
+		case tool.Version == "":
+			fmt.Fprintf(tw, "  %s\t%s (version unknown)\n", tool.Name, tool.Path)
+		default:
+			fmt.Fprintf(tw, "  %s\t%s (%s)\n", tool.Name, tool.Version, tool.Path)
+		}
+	}
+	return tw.Flush()
+}
+
+func shell() string {
+	if runtime.GOOS == "windows" {
+		if comspec := os.Getenv("ComSpec"); comspec != "" {
+			return comspec
+		}
+	}
+	if sh := os.Getenv("SHELL"); sh != "" {
+		return sh
+	}
+	return "unknown"
+}
+
+// toolVersion returns the first line of path --version.
+func toolVersion(path string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), versionTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, "--version").Output()
+	if err != nil {
+		return "", err
+	}
+	first, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(first), nil
+}
@@ -0,0 +1,47 @@
+package envreport
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDetectAndWrite(t *testing.T) {
+	t.Setenv("SHELL", "/bin/zsh")
+	d := Detector{
+		LookPath: func(name string) (string, error) {
+			switch name {
+			case "git", "claude":
+				return "/usr/bin/" + name, nil
+			}
+			return "", errors.New("not found")
+		},
+		Version: func(path string) (string, error) {
+			if path == "/usr/bin/git" {
+				return "git version 2.43.0", nil
+			}
+			return "", errors.New("exit status 1")
+		},
+	}
+	r := d.Detect()
+	if r.Shell != "/bin/zsh" || len(r.Tools) != len(Tools) {
+		t.Fatalf("unexpected report: %+v", r)
+	}
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"git       git version 2.43.0 (/usr/bin/git)",
+		"claude    /usr/bin/claude (version unknown)",
+		"opencode  not found",
+		"shell     /bin/zsh",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>
+</Types>`
+
+const docxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`
+
+const docxDocumentRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+
+// docxHeadingSizes are the font sizes, in half-points, of Heading1..Heading6.
+var docxHeadingSizes = []int{36, 30, 26, 24, 22, 22}
+
+// DOCX renders the document as a Word file. Headings use Word's built-in
+// Heading styles so the navigation pane and table of contents work.
+func DOCX(doc *Document) ([]byte, error) {
+	var body strings.Builder
+	var numbers []int // next number of the ordered list at each depth
+	for _, blk := range parseBlocks(Markdown(doc)) {
+		if blk.kind != blockListItem {
+			numbers = nil
+		}
+		switch blk.kind {
+		case blockHeading:
+			fmt.Fprintf(&body, `<w:p><w:pPr><w:pStyle w:val="Heading%d"/></w:pPr>%s</w:p>`, blk.level, docxRuns(blk.text))
+		case blockParagraph:
+			fmt.Fprintf(&body, `<w:p>%s</w:p>`, docxRuns(blk.text))
+		case blockQuote:
+			fmt.Fprintf(&body, `<w:p><w:pPr><w:pStyle w:val="Quote"/></w:pPr>%s</w:p>`, docxRuns(blk.text))
+		case blockCode:
+			body.WriteString(`<w:p><w:pPr><w:pStyle w:val="Code"/></w:pPr>`)
+			for i, line := range strings.Split(blk.text, "\n") {
+				if i > 0 {
+					body.WriteString(`<w:r><w:br/></w:r>`)
+				}
+				fmt.Fprintf(&body, `<w:r><w:t xml:space="preserve">%s</w:t></w:r>`, xmlEscape(line))
+			}
+			body.WriteString(`</w:p>`)
+		case blockRule:
+			body.WriteString(`<w:p><w:pPr><w:pBdr><w:bottom w:val="single" w:sz="6" w:space="1" w:color="D0D7DE"/></w:pBdr></w:pPr></w:p>`)
+		case blockListItem:
+			for len(numbers) <= blk.level {
+				numbers = append(numbers, 1)
+			}
+			numbers = numbers[:blk.level+1]
+			marker := "•"
+			if blk.ordered {
+				marker = fmt.Sprintf("%d.", numbers[blk.level])
+				numbers[blk.level]++
+			}
+			if blk.checked != nil {
+				marker = "☐"
+				if *blk.checked {
+					marker = "☑"
+				}
+			}
+			fmt.Fprintf(&body, `<w:p><w:pPr><w:ind w:left="%d" w:hanging="360"/></w:pPr><w:r><w:t>%s</w:t><w:tab/></w:r>%s</w:p>`,
+				720*(blk.level+1), marker, docxRuns(blk.text))
+		case blockTable:
+			body.WriteString(`<w:tbl><w:tblPr><w:tblStyle w:val="TableGrid"/><w:tblW w:w="0" w:type="auto"/></w:tblPr>`)
+			for i, row := range blk.rows {
+				body.WriteString(`<w:tr>`)
+				for _, text := range row {
+					runs := docxRuns(text)
+					if i == 0 {
+						runs = docxRuns("**" + text + "**")
+					}
+					fmt.Fprintf(&body, `<w:tc><w:p>%s</w:p></w:tc>`, runs)
+				}
+				body.WriteString(`</w:tr>`)
+			}
+			body.WriteString(`</w:tbl><w:p/>`)
+		}
+	}
+
+	document := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		body.String() + `<w:sectPr/></w:body></w:document>`
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", docxContentTypes},
+		{"_rels/.rels", docxRootRels},
+		{"word/_rels/document.xml.rels", docxDocumentRels},
+		{"word/styles.xml", docxStyles()},
+		{"word/document.xml", document},
+	} {
+		w, err := zw.Create(part.name)
+		if err != nil {
+			return nil, fmt.Errorf("writing %s: %w", part.name, err)
+		}
+		if _, err := w.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", part.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("finishing docx: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// docxRuns converts inline markdown to runs. Links keep their text and show
+// the target in parentheses, since hyperlinks need a relationship per URL.
+func docxRuns(text string) string {
+	var b strings.Builder
+	for _, s := range parseInline(text) {
+		var props string
+		switch {
+		case s.code:
+			props = `<w:rPr><w:rFonts w:ascii="Consolas" w:hAnsi="Consolas"/></w:rPr>`
+		case s.bold:
+			props = `<w:rPr><w:b/></w:rPr>`
+		case s.italic:
+			props = `<w:rPr><w:i/></w:rPr>`
+		}
+		content := s.text
+		if s.href != "" {
+			content = fmt.Sprintf("%s (%s)", s.text, s.href)
+		}
+		fmt.Fprintf(&b, `<w:r>%s<w:t xml:space="preserve">%s</w:t></w:r>`, props, xmlEscape(content))
+	}
+	return b.String()
+}
+
+func docxStyles() string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">`)
+	b.WriteString(`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:pPr><w:spacing w:after="120"/></w:pPr><w:rPr><w:rFonts w:ascii="Calibri" w:hAnsi="Calibri"/><w:sz w:val="22"/></w:rPr></w:style>`)
+	for i, size := range docxHeadingSizes {
+		fmt.Fprintf(&b, `<w:style w:type="paragraph" w:styleId="Heading%d"><w:name w:val="heading %d"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:pPr><w:keepNext/><w:spacing w:before="240" w:after="120"/><w:outlineLvl w:val="%d"/></w:pPr><w:rPr><w:b/><w:sz w:val="%d"/></w:rPr></w:style>`,
+			i+1, i+1, i, size)
+	}
+	b.WriteString(`<w:style w:type="paragraph" w:styleId="Quote"><w:name w:val="Quote"/><w:basedOn w:val="Normal"/><w:pPr><w:ind w:left="360"/></w:pPr><w:rPr><w:i/><w:color w:val="59636E"/></w:rPr></w:style>`)
+	b.WriteString(`<w:style w:type="paragraph" w:styleId="Code"><w:name w:val="Code"/><w:basedOn w:val="Normal"/><w:pPr><w:shd w:val="clear" w:color="auto" w:fill="F6F8FA"/></w:pPr><w:rPr><w:rFonts w:ascii="Consolas" w:hAnsi="Consolas"/><w:sz w:val="20"/></w:rPr></w:style>`)
+	b.WriteString(`<w:style w:type="table" w:styleId="TableGrid"><w:name w:val="Table Grid"/><w:tblPr><w:tblBorders>` +
+		`<w:top w:val="single" w:sz="4" w:color="D0D7DE"/><w:left w:val="single" w:sz="4" w:color="D0D7DE"/><w:bottom w:val="single" w:sz="4" w:color="D0D7DE"/>` +
+		`<w:right w:val="single" w:sz="4" w:color="D0D7DE"/><w:insideH w:val="single" w:sz="4" w:color="D0D7DE"/><w:insideV w:val="single" w:sz="4" w:color="D0D7DE"/>` +
+		`</w:tblBorders></w:tblPr></w:style>`)
+	b.WriteString(`</w:styles>`)
+	return b.String()
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
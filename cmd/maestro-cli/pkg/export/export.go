@@ -0,0 +1,256 @@
+// Package export assembles a feature's artifacts — spec, research synthesis,
+// plan, and task status — into a single document for readers outside the
+// repository, rendered as markdown, HTML, or DOCX.
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spec-maestro/maestro-cli/pkg/state"
+)
+
+// Formats lists the supported output formats.
+var Formats = []string{"html", "docx", "single-markdown"}
+
+// Extension returns the file extension for a format, or "" if the format is
+// not supported.
+func Extension(format string) string {
+	switch format {
+	case "html":
+		return ".html"
+	case "docx":
+		return ".docx"
+	case "single-markdown":
+		return ".md"
+	default:
+		return ""
+	}
+}
+
+// Render renders the document in one of Formats.
+func Render(doc *Document, format string) ([]byte, error) {
+	switch format {
+	case "html":
+		return []byte(HTML(doc)), nil
+	case "docx":
+		return DOCX(doc)
+	case "single-markdown":
+		return []byte(Markdown(doc)), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q (want %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// Document is a feature's artifacts, ready to render.
+type Document struct {
+	FeatureID string
+	Title     string
+	Stage     string
+	UpdatedAt string
+	Sections  []Section
+	Tasks     []Task
+	// TaskSource says where task status came from: "bd", "tasks.json", or
+	// empty when the feature has no tasks yet.
+	TaskSource string
+}
+
+// Section is one artifact included in the document.
+type Section struct {
+	Title   string
+	Path    string
+	Content string
+}
+
+// Task is a task and its current status.
+type Task struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+}
+
+// TaskLister returns the tasks under a bd epic.
+type TaskLister func(epicID string) ([]Task, error)
+
+// Load reads the artifacts of the feature in featureDir and its state file.
+// Task status comes from bd when the feature has an epic and listTasks is
+// set, falling back to the feature's tasks.json.
+func Load(featureDir, statePath string, listTasks TaskLister) (*Document, error) {
+	doc := &Document{FeatureID: filepath.Base(featureDir)}
+
+	fs := &state.State{}
+	if loaded, err := state.Load(statePath); err == nil {
+		fs = loaded
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading %s: %w", statePath, err)
+	}
+	doc.Stage = fs.Stage
+	doc.UpdatedAt = fs.UpdatedAt
+
+	// research_artifact_pointers maps artifact names to paths from the
+	// project root
+	var pointers map[string]string
+	json.Unmarshal(fs.Extra["research_artifact_pointers"], &pointers)
+	synthesis := filepath.Join(featureDir, "research", "synthesis.md")
+	if pointer := pointers["synthesis"]; pointer != "" {
+		synthesis = filepath.FromSlash(pointer)
+	}
+	for _, artifact := range []struct{ title, path string }{
+		{"Specification", filepath.Join(featureDir, "spec.md")},
+		{"Research synthesis", synthesis},
+		{"Implementation plan", filepath.Join(featureDir, "plan.md")},
+	} {
+		content, err := os.ReadFile(artifact.path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", artifact.path, err)
+		}
+		doc.Sections = append(doc.Sections, Section{
+			Title:   artifact.title,
+			Path:    filepath.ToSlash(artifact.path),
+			Content: string(content),
+		})
+	}
+	if len(doc.Sections) == 0 {
+		return nil, fmt.Errorf("no spec, research synthesis, or plan found in %s", featureDir)
+	}
+
+	doc.Title = doc.FeatureID
+	for _, b := range parseBlocks(doc.Sections[0].Content) {
+		if b.kind == blockHeading && b.level == 1 {
+			doc.Title = b.text
+			break
+		}
+	}
+
+	if fs.EpicID != "" && listTasks != nil {
+		if tasks, err := listTasks(fs.EpicID); err == nil {
+			doc.Tasks, doc.TaskSource = tasks, "bd"
+			return doc, nil
+		}
+	}
+	tasks, err := readTasksFile(filepath.Join(featureDir, "tasks.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) > 0 {
+		doc.Tasks, doc.TaskSource = tasks, "tasks.json"
+	}
+	return doc, nil
+}
+
+func readTasksFile(path string) ([]Task, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var file struct {
+		Tasks []struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+			Done  bool   `json:"done"`
+		} `json:"tasks"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	tasks := make([]Task, 0, len(file.Tasks))
+	for _, t := range file.Tasks {
+		status := "open"
+		if t.Done {
+			status = "closed"
+		}
+		tasks = append(tasks, Task{ID: t.ID, Title: t.Title, Status: status})
+	}
+	return tasks, nil
+}
+
+// Markdown renders the document as one markdown file. Headings inside each
+// artifact are nested two levels below the document title.
+func Markdown(doc *Document) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", doc.Title)
+	var meta []string
+	meta = append(meta, "Feature: `"+doc.FeatureID+"`")
+	if doc.Stage != "" {
+		meta = append(meta, "Stage: "+doc.Stage)
+	}
+	if doc.UpdatedAt != "" {
+		meta = append(meta, "Last updated: "+doc.UpdatedAt)
+	}
+	fmt.Fprintf(&b, "> %s\n\n", strings.Join(meta, " · "))
+
+	for _, section := range doc.Sections {
+		fmt.Fprintf(&b, "## %s\n\nSource: `%s`\n\n", section.Title, section.Path)
+		b.WriteString(strings.TrimSpace(demoteHeadings(section.Content, 2)))
+		b.WriteString("\n\n")
+	}
+
+	if len(doc.Tasks) > 0 {
+		b.WriteString("## Task status\n\n")
+		fmt.Fprintf(&b, "%s (from %s).\n\n", taskSummary(doc.Tasks), doc.TaskSource)
+		b.WriteString("| ID | Task | Status |\n|---|---|---|\n")
+		for _, t := range doc.Tasks {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", t.ID, strings.ReplaceAll(t.Title, "|", `\|`), t.Status)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// taskSummary counts tasks per status, e.g. "3 of 5 tasks closed; 2 open".
+func taskSummary(tasks []Task) string {
+	counts := make(map[string]int)
+	for _, t := range tasks {
+		counts[t.Status]++
+	}
+	summary := fmt.Sprintf("%d of %d tasks closed", counts["closed"], len(tasks))
+	delete(counts, "closed")
+
+	statuses := make([]string, 0, len(counts))
+	for status := range counts {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	for i, status := range statuses {
+		statuses[i] = fmt.Sprintf("%d %s", counts[status], status)
+	}
+	if len(statuses) > 0 {
+		summary += "; " + strings.Join(statuses, ", ")
+	}
+	return summary
+}
+
+// demoteHeadings pushes every heading outside code fences down by levels,
+// capped at h6.
+func demoteHeadings(markdown string, levels int) string {
+	lines := strings.Split(markdown, "\n")
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || !headingLine.MatchString(trimmed) {
+			continue
+		}
+		hashes := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+		level := hashes + levels
+		if level > 6 {
+			level = 6
+		}
+		lines[i] = strings.Repeat("#", level) + trimmed[hashes:]
+	}
+	return strings.Join(lines, "\n")
+}
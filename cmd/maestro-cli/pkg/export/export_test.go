@@ -0,0 +1,175 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleSpec = "# Feature: User Auth\n\n## 1. Problem\n\nUsers **cannot** sign in & stay signed in.\n\n```bash\n# not a heading\n```\n"
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func setupFeature(t *testing.T, state string) (featureDir, statePath string) {
+	t.Helper()
+	root := t.TempDir()
+	featureDir = filepath.Join(root, "specs", "003-user-auth")
+	statePath = filepath.Join(root, "state", "003-user-auth.json")
+	writeFile(t, filepath.Join(featureDir, "spec.md"), sampleSpec)
+	writeFile(t, filepath.Join(featureDir, "research", "synthesis.md"), "# Synthesis\n\nVerdict: ready\n")
+	writeFile(t, filepath.Join(featureDir, "plan.md"), "# Plan\n\n1. Add session store\n2. Add login form\n")
+	writeFile(t, filepath.Join(featureDir, "tasks.json"), `{"tasks":[{"id":"T001","title":"Add store","done":true},{"id":"T002","title":"Add form"}]}`)
+	if state != "" {
+		writeFile(t, statePath, state)
+	}
+	return featureDir, statePath
+}
+
+func TestLoadReadsArtifactsAndTasksFile(t *testing.T) {
+	featureDir, statePath := setupFeature(t, `{"stage":"implement","updated_at":"2026-01-02T03:04:05Z"}`)
+
+	doc, err := Load(featureDir, statePath, nil)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if doc.Title != "Feature: User Auth" || doc.Stage != "implement" || doc.FeatureID != "003-user-auth" {
+		t.Errorf("unexpected document: %+v", doc)
+	}
+	if len(doc.Sections) != 3 || doc.Sections[1].Title != "Research synthesis" {
+		t.Fatalf("sections = %+v", doc.Sections)
+	}
+	if doc.TaskSource != "tasks.json" || len(doc.Tasks) != 2 || doc.Tasks[0].Status != "closed" || doc.Tasks[1].Status != "open" {
+		t.Errorf("tasks = %+v from %q", doc.Tasks, doc.TaskSource)
+	}
+}
+
+func TestLoadPrefersBDForEpics(t *testing.T) {
+	featureDir, statePath := setupFeature(t, `{"stage":"implement","epic_id":"bd-1"}`)
+
+	var asked string
+	doc, err := Load(featureDir, statePath, func(epicID string) ([]Task, error) {
+		asked = epicID
+		return []Task{{ID: "bd-2", Title: "Add store", Status: "in_progress"}}, nil
+	})
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if asked != "bd-1" || doc.TaskSource != "bd" || len(doc.Tasks) != 1 {
+		t.Errorf("expected bd tasks, got %+v from %q", doc.Tasks, doc.TaskSource)
+	}
+
+	doc, err = Load(featureDir, statePath, func(string) ([]Task, error) { return nil, errors.New("bd down") })
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if doc.TaskSource != "tasks.json" {
+		t.Errorf("should fall back to tasks.json when bd fails, got %q", doc.TaskSource)
+	}
+}
+
+func TestLoadRequiresArtifacts(t *testing.T) {
+	if _, err := Load(t.TempDir(), filepath.Join(t.TempDir(), "missing.json"), nil); err == nil {
+		t.Error("expected error for feature without artifacts")
+	}
+}
+
+func TestMarkdownNestsArtifactHeadings(t *testing.T) {
+	featureDir, statePath := setupFeature(t, "")
+	doc, _ := Load(featureDir, statePath, nil)
+
+	md := Markdown(doc)
+	for _, want := range []string{
+		"# Feature: User Auth\n",
+		"## Specification\n",
+		"### Feature: User Auth\n",
+		"#### 1. Problem\n",
+		"# not a heading\n",
+		"## Task status\n",
+		"1 of 2 tasks closed; 1 open (from tasks.json).",
+		"| T002 | Add form | open |",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestHTML(t *testing.T) {
+	featureDir, statePath := setupFeature(t, "")
+	doc, _ := Load(featureDir, statePath, nil)
+
+	page := HTML(doc)
+	for _, want := range []string{
+		"<title>Feature: User Auth</title>",
+		"<h4>1. Problem</h4>",
+		"<strong>cannot</strong> sign in &amp; stay signed in.",
+		"<pre><code># not a heading</code></pre>",
+		"<ol>\n<li>Add session store</li>",
+		"<th>ID</th>",
+		"<td>T001</td><td>Add store</td><td>closed</td>",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("html missing %q:\n%s", want, page)
+		}
+	}
+}
+
+func TestDOCX(t *testing.T) {
+	featureDir, statePath := setupFeature(t, "")
+	doc, _ := Load(featureDir, statePath, nil)
+
+	data, err := DOCX(doc)
+	if err != nil {
+		t.Fatalf("DOCX() error: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("docx is not a zip: %v", err)
+	}
+
+	parts := make(map[string]string)
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		parts[f.Name] = string(content)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "word/document.xml", "word/styles.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("docx missing part %s", name)
+		}
+		if err := xml.Unmarshal([]byte(parts[name]), new(struct{})); err != nil {
+			t.Errorf("%s is not well-formed XML: %v", name, err)
+		}
+	}
+
+	document := parts["word/document.xml"]
+	for _, want := range []string{`<w:pStyle w:val="Heading1"/>`, "sign in &amp; stay", "<w:t>2.</w:t>", "<w:tbl>"} {
+		if !strings.Contains(document, want) {
+			t.Errorf("document.xml missing %q", want)
+		}
+	}
+}
+
+func TestRenderRejectsUnknownFormat(t *testing.T) {
+	if _, err := Render(&Document{}, "pdf"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+	if Extension("pdf") != "" || Extension("single-markdown") != ".md" {
+		t.Error("unexpected extensions")
+	}
+}
@@ -0,0 +1,104 @@
+package export
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+const htmlStyle = `body{font-family:-apple-system,"Segoe UI",Helvetica,Arial,sans-serif;max-width:52rem;margin:2rem auto;padding:0 1rem;line-height:1.5;color:#1f2328}
+pre{background:#f6f8fa;padding:.75rem;overflow:auto}code{font-family:ui-monospace,Menlo,Consolas,monospace;font-size:90%}
+table{border-collapse:collapse}th,td{border:1px solid #d0d7de;padding:.3rem .6rem;text-align:left}
+blockquote{margin:0;padding:0 1rem;border-left:.25rem solid #d0d7de;color:#59636e}`
+
+// HTML renders the document as a standalone HTML page. Styles are inline in
+// the page so it can be attached or pasted into Confluence or Notion as is.
+func HTML(doc *Document) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n<style>\n%s\n</style>\n</head>\n<body>\n", html.EscapeString(doc.Title), htmlStyle)
+
+	var openLists []string // closing tags of the lists currently open
+	closeLists := func(depth int) {
+		for len(openLists) > depth {
+			b.WriteString(openLists[len(openLists)-1])
+			openLists = openLists[:len(openLists)-1]
+		}
+	}
+
+	for _, blk := range parseBlocks(Markdown(doc)) {
+		if blk.kind != blockListItem {
+			closeLists(0)
+		}
+		switch blk.kind {
+		case blockHeading:
+			fmt.Fprintf(&b, "<h%d>%s</h%d>\n", blk.level, htmlInline(blk.text), blk.level)
+		case blockParagraph:
+			fmt.Fprintf(&b, "<p>%s</p>\n", htmlInline(blk.text))
+		case blockQuote:
+			fmt.Fprintf(&b, "<blockquote><p>%s</p></blockquote>\n", htmlInline(blk.text))
+		case blockCode:
+			fmt.Fprintf(&b, "<pre><code>%s</code></pre>\n", html.EscapeString(blk.text))
+		case blockRule:
+			b.WriteString("<hr>\n")
+		case blockTable:
+			b.WriteString("<table>\n")
+			for i, row := range blk.rows {
+				cell := "td"
+				if i == 0 {
+					cell = "th"
+				}
+				b.WriteString("<tr>")
+				for _, text := range row {
+					fmt.Fprintf(&b, "<%s>%s</%s>", cell, htmlInline(text), cell)
+				}
+				b.WriteString("</tr>\n")
+			}
+			b.WriteString("</table>\n")
+		case blockListItem:
+			closeLists(blk.level + 1)
+			for len(openLists) <= blk.level {
+				if blk.ordered {
+					b.WriteString("<ol>\n")
+					openLists = append(openLists, "</ol>\n")
+				} else {
+					b.WriteString("<ul>\n")
+					openLists = append(openLists, "</ul>\n")
+				}
+			}
+			b.WriteString("<li>")
+			if blk.checked != nil {
+				if *blk.checked {
+					b.WriteString("☑ ")
+				} else {
+					b.WriteString("☐ ")
+				}
+			}
+			fmt.Fprintf(&b, "%s</li>\n", htmlInline(blk.text))
+		}
+	}
+	closeLists(0)
+
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+func htmlInline(text string) string {
+	var b strings.Builder
+	for _, s := range parseInline(text) {
+		escaped := html.EscapeString(s.text)
+		switch {
+		case s.code:
+			fmt.Fprintf(&b, "<code>%s</code>", escaped)
+		case s.bold:
+			fmt.Fprintf(&b, "<strong>%s</strong>", escaped)
+		case s.italic:
+			fmt.Fprintf(&b, "<em>%s</em>", escaped)
+		case s.href != "":
+			fmt.Fprintf(&b, "<a href=\"%s\">%s</a>", html.EscapeString(s.href), escaped)
+		default:
+			b.WriteString(escaped)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,168 @@
+package export
+
+import (
+	"regexp"
+	"strings"
+)
+
+// blockKind is the type of a parsed markdown block.
+type blockKind int
+
+const (
+	blockParagraph blockKind = iota
+	blockHeading
+	blockListItem
+	blockCode
+	blockQuote
+	blockTable
+	blockRule
+)
+
+// block is one markdown block. Only the subset of markdown that maestro
+// templates produce is recognised; anything else renders as a paragraph.
+type block struct {
+	kind    blockKind
+	level   int    // heading level, or list nesting depth
+	ordered bool   // numbered list item
+	checked *bool  // task-list checkbox state
+	text    string // inline text; raw text for code blocks
+	rows    [][]string
+}
+
+var (
+	headingLine  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	listLine     = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	checkboxText = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+	ruleLine     = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+	tableDivider = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+)
+
+// parseBlocks splits markdown into blocks.
+func parseBlocks(markdown string) []block {
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	var blocks []block
+	var para []string
+
+	flush := func() {
+		if len(para) > 0 {
+			blocks = append(blocks, block{kind: blockParagraph, text: strings.Join(para, " ")})
+			para = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			flush()
+
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			flush()
+			fence := trimmed[:3]
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				code = append(code, lines[i])
+			}
+			blocks = append(blocks, block{kind: blockCode, text: strings.Join(code, "\n")})
+
+		case headingLine.MatchString(trimmed):
+			flush()
+			m := headingLine.FindStringSubmatch(trimmed)
+			blocks = append(blocks, block{kind: blockHeading, level: len(m[1]), text: m[2]})
+
+		case ruleLine.MatchString(line):
+			flush()
+			blocks = append(blocks, block{kind: blockRule})
+
+		case listLine.MatchString(line):
+			flush()
+			m := listLine.FindStringSubmatch(line)
+			item := block{
+				kind:    blockListItem,
+				level:   len(strings.ReplaceAll(m[1], "\t", "  ")) / 2,
+				ordered: !strings.ContainsAny(m[2], "-*+"),
+				text:    m[3],
+			}
+			if c := checkboxText.FindStringSubmatch(m[3]); c != nil {
+				done := c[1] != " "
+				item.checked = &done
+				item.text = c[2]
+			}
+			blocks = append(blocks, item)
+
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			var quote []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quote = append(quote, strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")))
+			}
+			i--
+			blocks = append(blocks, block{kind: blockQuote, text: strings.Join(quote, " ")})
+
+		case strings.HasPrefix(trimmed, "|") && i+1 < len(lines) && tableDivider.MatchString(lines[i+1]):
+			flush()
+			rows := [][]string{splitTableRow(trimmed)}
+			for i += 2; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+				rows = append(rows, splitTableRow(strings.TrimSpace(lines[i])))
+			}
+			i--
+			blocks = append(blocks, block{kind: blockTable, rows: rows})
+
+		default:
+			para = append(para, trimmed)
+		}
+	}
+	flush()
+	return blocks
+}
+
+func splitTableRow(line string) []string {
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+	cells := strings.Split(strings.ReplaceAll(line, `\|`, "\x00"), "|")
+	for i := range cells {
+		cells[i] = strings.ReplaceAll(strings.TrimSpace(cells[i]), "\x00", "|")
+	}
+	return cells
+}
+
+// span is a run of inline text with uniform formatting.
+type span struct {
+	text   string
+	bold   bool
+	italic bool
+	code   bool
+	href   string
+}
+
+var inlineToken = regexp.MustCompile("`[^`]+`|\\*\\*[^*]+\\*\\*|__[^_]+__|\\*[^*\\s][^*]*\\*|\\[[^\\]]+\\]\\([^)\\s]+\\)")
+
+// parseInline splits text into spans for code, bold, italic, and links.
+// Emphasis does not nest; markdown from maestro templates never needs it.
+func parseInline(text string) []span {
+	var spans []span
+	last := 0
+	for _, loc := range inlineToken.FindAllStringIndex(text, -1) {
+		if loc[0] > last {
+			spans = append(spans, span{text: text[last:loc[0]]})
+		}
+		token := text[loc[0]:loc[1]]
+		switch {
+		case strings.HasPrefix(token, "`"):
+			spans = append(spans, span{text: token[1 : len(token)-1], code: true})
+		case strings.HasPrefix(token, "**"), strings.HasPrefix(token, "__"):
+			spans = append(spans, span{text: token[2 : len(token)-2], bold: true})
+		case strings.HasPrefix(token, "["):
+			sep := strings.Index(token, "](")
+			spans = append(spans, span{text: token[1:sep], href: token[sep+2 : len(token)-1]})
+		default:
+			spans = append(spans, span{text: token[1 : len(token)-1], italic: true})
+		}
+		last = loc[1]
+	}
+	if last < len(text) {
+		spans = append(spans, span{text: text[last:]})
+	}
+	return spans
+}
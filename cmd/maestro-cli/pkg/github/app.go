@@ -0,0 +1,207 @@
+package github
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spec-maestro/maestro-cli/pkg/deadline"
+)
+
+// Environment variables that configure GitHub App authentication.
+const (
+	EnvAppID             = "GITHUB_APP_ID"
+	EnvAppPrivateKey     = "GITHUB_APP_PRIVATE_KEY"
+	EnvAppPrivateKeyPath = "GITHUB_APP_PRIVATE_KEY_PATH"
+	EnvAppInstallationID = "GITHUB_APP_INSTALLATION_ID"
+)
+
+// App authenticates as an installation of a GitHub App: it signs a
+// short-lived JWT with the app's private key and exchanges it for an
+// installation access token.
+type App struct {
+	ID string
+	// InstallationID is looked up from the repository when empty.
+	InstallationID string
+
+	key        *rsa.PrivateKey
+	baseURL    string
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	tokens map[string]string // by installation ID
+}
+
+// NewApp creates an App from its ID and PEM-encoded private key (PKCS#1 or
+// PKCS#8, as downloaded from the app settings).
+func NewApp(id string, privateKey []byte, installationID string) (*App, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, fmt.Errorf("GitHub App ID is empty")
+	}
+	key, err := parseRSAPrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub App private key: %w", err)
+	}
+	return &App{
+		ID:             id,
+		InstallationID: strings.TrimSpace(installationID),
+		key:            key,
+		baseURL:        defaultBaseURL,
+		httpClient:     &http.Client{Timeout: 30 * time.Second, Transport: deadline.Transport{}},
+		tokens:         map[string]string{},
+	}, nil
+}
+
+// AppFromEnv creates an App from GITHUB_APP_ID and GITHUB_APP_PRIVATE_KEY
+// (the PEM itself) or GITHUB_APP_PRIVATE_KEY_PATH, with an optional
+// GITHUB_APP_INSTALLATION_ID. It returns nil when no app ID is set.
+func AppFromEnv() (*App, error) {
+	id := strings.TrimSpace(os.Getenv(EnvAppID))
+	if id == "" {
+		return nil, nil
+	}
+
+	key := []byte(os.Getenv(EnvAppPrivateKey))
+	if path := os.Getenv(EnvAppPrivateKeyPath); len(bytes.TrimSpace(key)) == 0 && path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", EnvAppPrivateKeyPath, err)
+		}
+		key = data
+	}
+	if len(bytes.TrimSpace(key)) == 0 {
+		return nil, fmt.Errorf("%s is set but neither %s nor %s is", EnvAppID, EnvAppPrivateKey, EnvAppPrivateKeyPath)
+	}
+	return NewApp(id, key, os.Getenv(EnvAppInstallationID))
+}
+
+// JWT returns a token that authenticates as the app itself. It is backdated
+// a minute to allow for clock drift and expires after nine minutes, under
+// GitHub's ten-minute limit.
+func (a *App) JWT(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": a.ID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	signed := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("signing GitHub App JWT: %w", err)
+	}
+	return signed + "." + enc.EncodeToString(signature), nil
+}
+
+// InstallationToken returns an installation access token for owner/repo,
+// looking up the app's installation on the repository unless one is
+// configured. Tokens are reused for the life of the process; they are
+// valid for an hour.
+func (a *App) InstallationToken(owner, repo string) (string, error) {
+	jwt, err := a.JWT(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	installationID := a.InstallationID
+	if installationID == "" {
+		var installation struct {
+			ID int64 `json:"id"`
+		}
+		url := fmt.Sprintf("%s/repos/%s/%s/installation", a.baseURL, owner, repo)
+		if err := a.do("GET", url, jwt, http.StatusOK, &installation); err != nil {
+			return "", fmt.Errorf("finding GitHub App installation on %s/%s: %w", owner, repo, err)
+		}
+		installationID = fmt.Sprint(installation.ID)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if token, ok := a.tokens[installationID]; ok {
+		return token, nil
+	}
+
+	var access struct {
+		Token string `json:"token"`
+	}
+	url := fmt.Sprintf("%s/app/installations/%s/access_tokens", a.baseURL, installationID)
+	if err := a.do("POST", url, jwt, http.StatusCreated, &access); err != nil {
+		return "", fmt.Errorf("creating GitHub App installation token: %w", err)
+	}
+	if access.Token == "" {
+		return "", fmt.Errorf("creating GitHub App installation token: empty token in response")
+	}
+	a.tokens[installationID] = access.Token
+	return access.Token, nil
+}
+
+func (a *App) do(method, url, jwt string, wantStatus int, target interface{}) error {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", apiVersion)
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case wantStatus:
+	case http.StatusNotFound:
+		return fmt.Errorf("not found; is the app installed on the repository?")
+	case http.StatusUnauthorized:
+		return fmt.Errorf("unauthorized; check %s and the private key", EnvAppID)
+	default:
+		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("not an RSA key")
+	}
+	return key, nil
+}
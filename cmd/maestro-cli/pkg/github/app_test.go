@@ -0,0 +1,132 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testAppKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+// verifyJWT checks an app JWT's signature and returns its claims.
+func verifyJWT(t *testing.T, key *rsa.PrivateKey, jwt string) map[string]interface{} {
+	t.Helper()
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("malformed JWT %q", jwt)
+	}
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		t.Fatalf("JWT signature does not verify: %v", err)
+	}
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims map[string]interface{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatalf("JWT claims: %v", err)
+	}
+	return claims
+}
+
+func TestAppJWT(t *testing.T) {
+	key, pemKey := testAppKey(t)
+	app, err := NewApp("12345", pemKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	jwt, err := app.JWT(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := verifyJWT(t, key, jwt)
+	if claims["iss"] != "12345" || claims["iat"] != float64(now.Unix()-60) || claims["exp"] != float64(now.Unix()+540) {
+		t.Errorf("unexpected claims: %v", claims)
+	}
+}
+
+func TestAppInstallationToken(t *testing.T) {
+	key, pemKey := testAppKey(t)
+	exchanges := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verifyJWT(t, key, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repos/acme/assets/installation":
+			w.Write([]byte(`{"id": 42}`))
+		case r.Method == "POST" && r.URL.Path == "/app/installations/42/access_tokens":
+			exchanges++
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"token": "ghs_installation", "expires_at": "2030-01-01T00:00:00Z"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	app, err := NewApp("12345", pemKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.baseURL = server.URL
+	for i := 0; i < 2; i++ {
+		token, err := app.InstallationToken("acme", "assets")
+		if err != nil || token != "ghs_installation" {
+			t.Fatalf("InstallationToken() = %q, %v", token, err)
+		}
+	}
+	if exchanges != 1 {
+		t.Errorf("token should be exchanged once per process, got %d", exchanges)
+	}
+
+	if _, err := app.InstallationToken("acme", "other"); err == nil || !strings.Contains(err.Error(), "installed") {
+		t.Errorf("missing installation should explain itself, got %v", err)
+	}
+}
+
+func TestAppFromEnv(t *testing.T) {
+	_, pemKey := testAppKey(t)
+	t.Setenv(EnvAppID, "")
+	if app, err := AppFromEnv(); app != nil || err != nil {
+		t.Errorf("no app ID should mean no app, got %v, %v", app, err)
+	}
+
+	t.Setenv(EnvAppID, "12345")
+	t.Setenv(EnvAppPrivateKey, "")
+	t.Setenv(EnvAppPrivateKeyPath, "")
+	if _, err := AppFromEnv(); err == nil {
+		t.Error("an app ID without a key should be an error")
+	}
+
+	t.Setenv(EnvAppPrivateKey, string(pemKey))
+	t.Setenv(EnvAppInstallationID, "42")
+	app, err := AppFromEnv()
+	if err != nil || app.ID != "12345" || app.InstallationID != "42" {
+		t.Errorf("AppFromEnv() = %+v, %v", app, err)
+	}
+
+	t.Setenv(EnvAppPrivateKey, "not a key")
+	if _, err := AppFromEnv(); err == nil {
+		t.Error("an unparsable key should be an error")
+	}
+}
@@ -3,11 +3,16 @@ package github
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/spec-maestro/maestro-cli/pkg/deadline"
+	"github.com/spec-maestro/maestro-cli/pkg/httpheaders"
 )
 
 const (
@@ -22,6 +27,8 @@ type Release struct {
 	PublishedAt time.Time `json:"published_at"`
 	Assets      []Asset   `json:"assets"`
 	Body        string    `json:"body"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
 }
 
 // Asset represents a release asset.
@@ -36,6 +43,8 @@ type Client struct {
 	httpClient  *http.Client
 	baseURL     string
 	codeloadURL string
+	lfsURL      string
+	warnOut     io.Writer
 	token       string
 	owner       string
 	repo        string
@@ -44,9 +53,11 @@ type Client struct {
 // NewClient creates a new GitHub client.
 func NewClient(owner, repo, token string) *Client {
 	return &Client{
-		httpClient:  &http.Client{Timeout: 30 * time.Second},
+		httpClient:  &http.Client{Timeout: 30 * time.Second, Transport: deadline.Transport{Base: httpheaders.Transport{}}},
 		baseURL:     defaultBaseURL,
 		codeloadURL: defaultCodeloadURL,
+		lfsURL:      defaultLFSURL,
+		warnOut:     os.Stderr,
 		token:       token,
 		owner:       owner,
 		repo:        repo,
@@ -56,16 +67,10 @@ func NewClient(owner, repo, token string) *Client {
 // ResolveToken resolves a GitHub token from explicit input, environment,
 // or the local gh CLI auth session.
 func ResolveToken(explicit string) string {
-	if token := strings.TrimSpace(explicit); token != "" {
+	if token, _ := explicitOrEnvToken(explicit); token != "" {
 		return token
 	}
 
-	for _, envKey := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
-		if token := strings.TrimSpace(os.Getenv(envKey)); token != "" {
-			return token
-		}
-	}
-
 	if token, err := lookupTokenWithGHCLI(); err == nil {
 		return token
 	}
@@ -73,6 +78,55 @@ func ResolveToken(explicit string) string {
 	return ""
 }
 
+// ResolveRepoToken resolves a token for accessing owner/repo like
+// ResolveToken, but when no token is given and a GitHub App is configured
+// (see AppFromEnv) it uses an installation token of the app before falling
+// back to the gh CLI. App misconfiguration is an error rather than a
+// silent fallback to anonymous access.
+func ResolveRepoToken(explicit, owner, repo string) (string, error) {
+	token, _, err := ResolveRepoTokenSource(explicit, owner, repo)
+	return token, err
+}
+
+// ResolveRepoTokenSource is ResolveRepoToken, also naming where the token
+// came from: "explicit", GITHUB_TOKEN, GH_TOKEN, "GitHub App", or
+// "gh auth token". Both are empty when requests will be anonymous.
+func ResolveRepoTokenSource(explicit, owner, repo string) (token, source string, err error) {
+	if token, source := explicitOrEnvToken(explicit); token != "" {
+		return token, source, nil
+	}
+
+	app, err := appFromEnv()
+	if err != nil {
+		return "", "", err
+	}
+	if app != nil {
+		token, err := app.InstallationToken(owner, repo)
+		return token, "GitHub App", err
+	}
+
+	if token, err := lookupTokenWithGHCLI(); err == nil {
+		return token, "gh auth token", nil
+	}
+	return "", "", nil
+}
+
+// appFromEnv is AppFromEnv, memoized so one command exchanges the app's
+// credentials for an installation token only once.
+var appFromEnv = sync.OnceValues(AppFromEnv)
+
+func explicitOrEnvToken(explicit string) (token, source string) {
+	if token := strings.TrimSpace(explicit); token != "" {
+		return token, "explicit"
+	}
+	for _, envKey := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+		if token := strings.TrimSpace(os.Getenv(envKey)); token != "" {
+			return token, envKey
+		}
+	}
+	return "", ""
+}
+
 var ghTokenCommand = func() ([]byte, error) {
 	return exec.Command("gh", "auth", "token").Output()
 }
@@ -101,6 +155,45 @@ func (c *Client) FetchReleaseByTag(tag string) (*Release, error) {
 	return c.fetchRelease(url)
 }
 
+// releasesPerPage is the page size FetchReleases asks for, the most the API
+// allows.
+const releasesPerPage = 100
+
+// FetchReleases fetches the repository's published releases, newest first,
+// stopping after maxPages pages.
+func (c *Client) FetchReleases(maxPages int) ([]Release, error) {
+	var all []Release
+	for page := 1; page <= maxPages; page++ {
+		url := fmt.Sprintf("%s/repos/%s/%s/releases?per_page=%d&page=%d", c.baseURL, c.owner, c.repo, releasesPerPage, page)
+		var releases []Release
+		if err := c.doGet(url, &releases); err != nil {
+			return nil, fmt.Errorf("fetching releases: %w", err)
+		}
+		for _, r := range releases {
+			if !r.Draft {
+				all = append(all, r)
+			}
+		}
+		if len(releases) < releasesPerPage {
+			break
+		}
+	}
+	return all, nil
+}
+
+// FetchNewestRelease fetches the most recently published release,
+// prereleases included, which releases/latest leaves out.
+func (c *Client) FetchNewestRelease() (*Release, error) {
+	releases, err := c.FetchReleases(1)
+	if err != nil {
+		return nil, err
+	}
+	if len(releases) == 0 {
+		return nil, fmt.Errorf("fetching releases: resource not found")
+	}
+	return &releases[0], nil
+}
+
 // doGet performs a GET request and decodes the JSON response.
 func (c *Client) doGet(url string, target interface{}) error {
 	req, err := http.NewRequest("GET", url, nil)
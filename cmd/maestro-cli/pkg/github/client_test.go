@@ -1,6 +1,10 @@
 package github
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -28,3 +32,88 @@ func TestFindAssetForPlatform(t *testing.T) {
 		t.Error("Expected error for missing platform")
 	}
 }
+
+func TestFetchReleasesSkipsDraftsAndPages(t *testing.T) {
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages = append(pages, r.URL.Query().Get("page"))
+		var releases []Release
+		if r.URL.Query().Get("page") == "1" {
+			for i := 0; i < releasesPerPage; i++ {
+				releases = append(releases, Release{TagName: fmt.Sprintf("v1.0.%d", i), Draft: i == 0})
+			}
+		} else {
+			releases = []Release{{TagName: "v0.9.0"}}
+		}
+		json.NewEncoder(w).Encode(releases)
+	}))
+	defer server.Close()
+
+	client := NewClient("owner", "repo", "")
+	client.baseURL = server.URL
+	releases, err := client.FetchReleases(5)
+	if err != nil {
+		t.Fatalf("FetchReleases() error: %v", err)
+	}
+	if len(releases) != releasesPerPage || releases[len(releases)-1].TagName != "v0.9.0" {
+		t.Errorf("FetchReleases() returned %d releases ending in %+v", len(releases), releases[len(releases)-1])
+	}
+	if len(pages) != 2 {
+		t.Errorf("requested pages %v, want 1 and 2", pages)
+	}
+}
+
+func TestFetchNewestReleaseIncludesPrereleases(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]Release{
+			{TagName: "v1.4.0-rc.2", Draft: true},
+			{TagName: "v1.4.0-rc.1", Prerelease: true},
+			{TagName: "v1.3.0"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("owner", "repo", "")
+	client.baseURL = server.URL
+	release, err := client.FetchNewestRelease()
+	if err != nil || release.TagName != "v1.4.0-rc.1" {
+		t.Errorf("FetchNewestRelease() = %+v, %v; want the newest published prerelease", release, err)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rate_limit" {
+			// The codeload ping
+			return
+		}
+		if r.Header.Get("Authorization") == "Bearer bad" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"resources": {"core": {"limit": 5000, "remaining": 4990, "reset": 1700000000}}}`)
+	}))
+	defer server.Close()
+
+	client := NewClient("owner", "repo", "good")
+	client.baseURL = server.URL
+	limit, err := client.RateLimit()
+	if err != nil || limit.Limit != 5000 || limit.Remaining != 4990 || limit.Reset.Unix() != 1700000000 {
+		t.Errorf("RateLimit() = %+v, %v", limit, err)
+	}
+
+	client = NewClient("owner", "repo", "bad")
+	client.baseURL = server.URL
+	if _, err := client.RateLimit(); err != ErrBadCredentials {
+		t.Errorf("RateLimit() with a rejected token = %v, want ErrBadCredentials", err)
+	}
+
+	client.codeloadURL = server.URL
+	if err := client.PingCodeload(); err != nil {
+		t.Errorf("PingCodeload() = %v", err)
+	}
+	server.Close()
+	if err := client.PingCodeload(); err == nil {
+		t.Error("PingCodeload() should fail when the host does not answer")
+	}
+}
@@ -1,14 +1,19 @@
 package github
 
 import (
-	"archive/tar"
 	"compress/gzip"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
-	"path"
+	"sort"
 	"strings"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/spec-maestro/maestro-cli/pkg/safepath"
 )
 
 // TreeResponse represents a GitHub git tree response.
@@ -63,19 +68,206 @@ type BlobResponse struct {
 	Encoding string `json:"encoding"`
 }
 
-// FetchRef fetches a git reference and returns the tree SHA.
-func (c *Client) FetchRef(ref string) (treeSHA string, err error) {
-	// Get the ref (e.g., "main" -> full commit SHA)
+// CompareResponse represents a GitHub compare-commits response.
+type CompareResponse struct {
+	Status       string        `json:"status"`
+	TotalCommits int           `json:"total_commits"`
+	Files        []CompareFile `json:"files"`
+}
+
+// CompareFile represents a single changed file in a compare response.
+type CompareFile struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename"`
+	Status           string `json:"status"` // added, removed, modified, renamed, copied, changed, unchanged
+	SHA              string `json:"sha"`
+}
+
+// maxCompareFiles is the number of files GitHub returns for a comparison
+// before truncating the list; larger comparisons cannot be trusted.
+const maxCompareFiles = 300
+
+// FetchCommitSHA resolves a branch name to the commit SHA it points at.
+func (c *Client) FetchCommitSHA(ref string) (string, error) {
 	url := fmt.Sprintf("%s/repos/%s/%s/git/ref/heads/%s", c.baseURL, c.owner, c.repo, ref)
 	var refResp RefResponse
 	if err := c.doGet(url, &refResp); err != nil {
 		return "", fmt.Errorf("fetching ref: %w", err)
 	}
+	return refResp.Object.SHA, nil
+}
+
+// FetchTagCommitSHA resolves a tag name to the commit SHA it points at,
+// peeling annotated tags.
+func (c *Client) FetchTagCommitSHA(tag string) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/git/ref/tags/%s", c.baseURL, c.owner, c.repo, tag)
+	var refResp RefResponse
+	if err := c.doGet(url, &refResp); err != nil {
+		return "", fmt.Errorf("fetching tag: %w", err)
+	}
+	if refResp.Object.Type != "tag" {
+		return refResp.Object.SHA, nil
+	}
+
+	url = fmt.Sprintf("%s/repos/%s/%s/git/tags/%s", c.baseURL, c.owner, c.repo, refResp.Object.SHA)
+	var tagResp RefResponse
+	if err := c.doGet(url, &tagResp); err != nil {
+		return "", fmt.Errorf("fetching annotated tag: %w", err)
+	}
+	return tagResp.Object.SHA, nil
+}
+
+// CompareCommits lists the files changed between two commits.
+func (c *Client) CompareCommits(base, head string) (*CompareResponse, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/compare/%s...%s", c.baseURL, c.owner, c.repo, base, head)
+	var compareResp CompareResponse
+	if err := c.doGet(url, &compareResp); err != nil {
+		return nil, fmt.Errorf("comparing commits: %w", err)
+	}
+	return &compareResp, nil
+}
+
+// AgentDirChanges describes the difference of a directory between two commits.
+type AgentDirChanges struct {
+	// Changed maps relative paths (within the directory) to their new content.
+	Changed map[string][]byte
+	// Removed lists relative paths that no longer exist upstream.
+	Removed []string
+	// Blobs maps the relative paths in Changed to their git blob SHAs.
+	Blobs map[string]string
+}
+
+// FetchAgentDirChanges downloads only the files of dirName that changed
+// between baseSHA and headSHA. Renamed files are reported as a removal of the
+// old path plus the content of the new path.
+func (c *Client) FetchAgentDirChanges(dirName, baseSHA, headSHA string) (*AgentDirChanges, error) {
+	compare, err := c.CompareCommits(baseSHA, headSHA)
+	if err != nil {
+		return nil, fmt.Errorf("fetching agent dir changes: %w", err)
+	}
+	if len(compare.Files) >= maxCompareFiles {
+		return nil, fmt.Errorf("fetching agent dir changes: comparison lists %d files and may be truncated", len(compare.Files))
+	}
+
+	prefix := strings.TrimSuffix(dirName, "/") + "/"
+	changes := &AgentDirChanges{Changed: make(map[string][]byte), Blobs: make(map[string]string)}
+
+	download := make(map[string]string)
+	for _, file := range compare.Files {
+		if file.PreviousFilename != "" && strings.HasPrefix(file.PreviousFilename, prefix) && file.PreviousFilename != file.Filename {
+			changes.Removed = append(changes.Removed, strings.TrimPrefix(file.PreviousFilename, prefix))
+		}
+		if !strings.HasPrefix(file.Filename, prefix) {
+			continue
+		}
+
+		rel := strings.TrimPrefix(file.Filename, prefix)
+		switch file.Status {
+		case "removed":
+			changes.Removed = append(changes.Removed, rel)
+		case "unchanged":
+			continue
+		default:
+			download[file.Filename] = file.SHA
+			changes.Blobs[rel] = file.SHA
+		}
+	}
 
-	commitSHA := refResp.Object.SHA
+	files, err := c.downloadBlobs(download)
+	if err != nil {
+		return nil, fmt.Errorf("fetching agent dir changes: %w", err)
+	}
+	for filePath, content := range files {
+		changes.Changed[strings.TrimPrefix(filePath, prefix)] = content
+	}
+	return changes, nil
+}
+
+// maxConcurrentBlobs bounds the blob downloads a directory fetch has in
+// flight, to stay clear of GitHub's secondary rate limits.
+const maxConcurrentBlobs = 8
+
+// maxReportedBlobErrors is how many failed downloads downloadBlobs names.
+const maxReportedBlobErrors = 5
+
+// downloadBlobs downloads blobs, given by SHA keyed by repository path,
+// with at most maxConcurrentBlobs requests in flight, and resolves LFS
+// pointers. Every blob is attempted; the failures are returned together,
+// each naming its path.
+func (c *Client) downloadBlobs(blobs map[string]string) (map[string][]byte, error) {
+	paths := make([]string, 0, len(blobs))
+	for filePath := range blobs {
+		paths = append(paths, filePath)
+	}
+	sort.Strings(paths)
+
+	contents := make([][]byte, len(paths))
+	errs := make([]error, len(paths))
+	var g errgroup.Group
+	g.SetLimit(maxConcurrentBlobs)
+	for i, filePath := range paths {
+		g.Go(func() error {
+			content, err := c.DownloadBlob(blobs[filePath])
+			if err != nil {
+				errs[i] = fmt.Errorf("downloading %s: %w", filePath, err)
+				return nil
+			}
+			contents[i], errs[i] = c.resolveContent(filePath, content)
+			return nil
+		})
+	}
+	g.Wait()
+
+	var failed []error
+	files := make(map[string][]byte, len(paths))
+	for i, filePath := range paths {
+		if errs[i] != nil {
+			failed = append(failed, errs[i])
+			continue
+		}
+		files[filePath] = contents[i]
+	}
+	if len(failed) > maxReportedBlobErrors {
+		failed = append(failed[:maxReportedBlobErrors], fmt.Errorf("and %d more", len(failed)-maxReportedBlobErrors))
+	}
+	if len(failed) > 0 {
+		return nil, errors.Join(failed...)
+	}
+	return files, nil
+}
+
+// ResolveCommit resolves any ref GitHub understands — a branch, a tag, or a
+// possibly abbreviated commit SHA — to a full commit SHA.
+func (c *Client) ResolveCommit(ref string) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/commits/%s", c.baseURL, c.owner, c.repo, ref)
+	var commitResp CommitResponse
+	if err := c.doGet(url, &commitResp); err != nil {
+		return "", fmt.Errorf("resolving %s: %w", ref, err)
+	}
+	return commitResp.SHA, nil
+}
+
+// FetchRef fetches a git reference and returns the tree SHA. Full commit
+// SHAs are used as-is; otherwise branches are tried first, then tags, then
+// abbreviated commit SHAs.
+func (c *Client) FetchRef(ref string) (treeSHA string, err error) {
+	// Get the ref (e.g., "main" -> full commit SHA)
+	commitSHA := ref
+	if !isFullCommitSHA(ref) {
+		commitSHA, err = c.FetchCommitSHA(ref)
+		if err != nil && strings.Contains(err.Error(), "resource not found") {
+			commitSHA, err = c.FetchTagCommitSHA(ref)
+		}
+		if err != nil && strings.Contains(err.Error(), "resource not found") {
+			commitSHA, err = c.ResolveCommit(ref)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
 
 	// Get the commit to extract the tree SHA
-	url = fmt.Sprintf("%s/repos/%s/%s/git/commits/%s", c.baseURL, c.owner, c.repo, commitSHA)
+	url := fmt.Sprintf("%s/repos/%s/%s/git/commits/%s", c.baseURL, c.owner, c.repo, commitSHA)
 	var commitResp CommitResponse
 	if err := c.doGet(url, &commitResp); err != nil {
 		return "", fmt.Errorf("fetching commit: %w", err)
@@ -144,7 +336,11 @@ func (c *Client) FetchFile(filePath string, ref string) ([]byte, error) {
 	// Find the file in the tree
 	for _, entry := range tree.Tree {
 		if entry.Type == "blob" && entry.Path == filePath {
-			return c.DownloadBlob(entry.SHA)
+			content, err := c.DownloadBlob(entry.SHA)
+			if err != nil {
+				return nil, err
+			}
+			return c.resolveContent(filePath, content)
 		}
 	}
 
@@ -187,33 +383,27 @@ func (c *Client) fetchFileFromArchive(filePath string, ref string) ([]byte, erro
 	}
 	defer gzReader.Close()
 
-	tarReader := tar.NewReader(gzReader)
-
-	for {
-		header, err := tarReader.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, fmt.Errorf("fetching file from archive: reading entry: %w", err)
+	var content []byte
+	found := false
+	err = safepath.WalkTar(gzReader, func(entry safepath.Entry) error {
+		// Archive entries are prefixed with a "<repo>-<sha>/" directory
+		_, repoRelative, _ := strings.Cut(entry.Name, "/")
+		if entry.IsDir || repoRelative != filePath {
+			return nil
 		}
 
-		if header.Typeflag != tar.TypeReg {
-			continue
-		}
-
-		entryPath := header.Name
-		slash := strings.Index(entryPath, "/")
-		if slash == -1 || slash+1 >= len(entryPath) {
-			continue
-		}
-
-		repoRelative := entryPath[slash+1:]
-		if repoRelative != filePath {
-			continue
+		data, err := io.ReadAll(entry.Body)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", filePath, err)
 		}
-
-		return io.ReadAll(tarReader)
+		content, found = data, true
+		return fs.SkipAll
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetching file from archive: %w", err)
+	}
+	if found {
+		return c.resolveContent(filePath, content)
 	}
 
 	return nil, fmt.Errorf("fetching file from archive: file not found: %s", filePath)
@@ -222,11 +412,41 @@ func (c *Client) fetchFileFromArchive(filePath string, ref string) ([]byte, erro
 // FetchAgentDir fetches all files from a specific directory in the repository.
 // Returns a map of relative path (within dirName) to file content.
 func (c *Client) FetchAgentDir(dirName string, ref string) (map[string][]byte, error) {
+	dir, err := c.FetchAgentDirSince(dirName, ref, nil)
+	if err != nil {
+		return nil, err
+	}
+	return dir.Files, nil
+}
+
+// AgentDirFiles is an agent directory fetched by FetchAgentDirSince.
+type AgentDirFiles struct {
+	// Files maps relative paths (within the directory) to the content of
+	// the files that were downloaded.
+	Files map[string][]byte
+	// Blobs maps the relative path of every file in the directory to its
+	// git blob SHA. It is nil when the directory came from the archive,
+	// which has no blob SHAs.
+	Blobs map[string]string
+}
+
+// FetchAgentDirSince is FetchAgentDir that skips downloading the files whose
+// blob SHA is the one in known, keyed by relative path, as the caller
+// already has them.
+func (c *Client) FetchAgentDirSince(dirName, ref string, known map[string]string) (*AgentDirFiles, error) {
+	fromArchive := func() (*AgentDirFiles, error) {
+		files, err := c.fetchAgentDirFromArchive(dirName, ref)
+		if err != nil {
+			return nil, err
+		}
+		return &AgentDirFiles{Files: files}, nil
+	}
+
 	// Get the tree SHA for the ref
 	treeSHA, err := c.FetchRef(ref)
 	if err != nil {
 		if isRateLimitedError(err) {
-			return c.fetchAgentDirFromArchive(dirName, ref)
+			return fromArchive()
 		}
 		return nil, fmt.Errorf("fetching agent dir: %w", err)
 	}
@@ -235,7 +455,7 @@ func (c *Client) FetchAgentDir(dirName string, ref string) (map[string][]byte, e
 	tree, err := c.FetchTree(treeSHA)
 	if err != nil {
 		if isRateLimitedError(err) {
-			return c.fetchAgentDirFromArchive(dirName, ref)
+			return fromArchive()
 		}
 		return nil, fmt.Errorf("fetching agent dir: %w", err)
 	}
@@ -247,26 +467,46 @@ func (c *Client) FetchAgentDir(dirName string, ref string) (map[string][]byte, e
 	}
 
 	// Filter entries that start with the directory prefix and are blobs
-	files := make(map[string][]byte)
+	dir := &AgentDirFiles{Blobs: make(map[string]string)}
+	download := make(map[string]string)
 	for _, entry := range tree.Tree {
-		if entry.Type == "blob" && strings.HasPrefix(entry.Path, prefix) {
-			// Download the blob
-			content, err := c.DownloadBlob(entry.SHA)
-			if err != nil {
-				return nil, fmt.Errorf("fetching agent dir: downloading %s: %w", entry.Path, err)
-			}
-
-			// Store with relative path (remove prefix)
-			relativePath := strings.TrimPrefix(entry.Path, prefix)
-			files[relativePath] = content
+		if entry.Type != "blob" || !strings.HasPrefix(entry.Path, prefix) {
+			continue
+		}
+		// Store with relative path (remove prefix)
+		relativePath := strings.TrimPrefix(entry.Path, prefix)
+		dir.Blobs[relativePath] = entry.SHA
+		if known[relativePath] != entry.SHA {
+			download[entry.Path] = entry.SHA
 		}
 	}
 
-	if len(files) == 0 {
+	if len(dir.Blobs) == 0 {
 		return nil, fmt.Errorf("fetching agent dir: no files found in directory %s", dirName)
 	}
 
-	return files, nil
+	files, err := c.downloadBlobs(download)
+	if err != nil {
+		return nil, fmt.Errorf("fetching agent dir: %w", err)
+	}
+	dir.Files = make(map[string][]byte, len(files))
+	for filePath, content := range files {
+		dir.Files[strings.TrimPrefix(filePath, prefix)] = content
+	}
+	return dir, nil
+}
+
+// isFullCommitSHA reports whether ref is a 40-character hex commit SHA.
+func isFullCommitSHA(ref string) bool {
+	if len(ref) != 40 {
+		return false
+	}
+	for _, r := range ref {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
 }
 
 func isRateLimitedError(err error) bool {
@@ -312,45 +552,30 @@ func (c *Client) fetchAgentDirFromArchive(dirName string, ref string) (map[strin
 	}
 	defer gzReader.Close()
 
-	tarReader := tar.NewReader(gzReader)
 	prefix := strings.TrimSuffix(dirName, "/") + "/"
 	files := make(map[string][]byte)
 
-	for {
-		header, err := tarReader.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, fmt.Errorf("fetching agent dir: reading archive entry: %w", err)
-		}
-
-		if header.Typeflag != tar.TypeReg {
-			continue
-		}
-
-		entryPath := header.Name
-		slash := strings.Index(entryPath, "/")
-		if slash == -1 || slash+1 >= len(entryPath) {
-			continue
-		}
-
-		repoRelative := entryPath[slash+1:]
-		if !strings.HasPrefix(repoRelative, prefix) {
-			continue
+	err = safepath.WalkTar(gzReader, func(entry safepath.Entry) error {
+		// Archive entries are prefixed with a "<repo>-<sha>/" directory
+		_, repoRelative, _ := strings.Cut(entry.Name, "/")
+		if entry.IsDir || !strings.HasPrefix(repoRelative, prefix) {
+			return nil
 		}
-
 		rel := strings.TrimPrefix(repoRelative, prefix)
-		if rel == "" || strings.Contains(rel, "..") {
-			continue
-		}
-		rel = path.Clean(rel)
 
-		content, err := io.ReadAll(tarReader)
+		content, err := io.ReadAll(entry.Body)
 		if err != nil {
-			return nil, fmt.Errorf("fetching agent dir: reading file %s: %w", rel, err)
+			return fmt.Errorf("reading file %s: %w", rel, err)
+		}
+		content, err = c.resolveContent(repoRelative, content)
+		if err != nil {
+			return err
 		}
 		files[rel] = content
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetching agent dir: %w", err)
 	}
 
 	if len(files) == 0 {
@@ -4,12 +4,17 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestFetchRef(t *testing.T) {
@@ -77,6 +82,72 @@ func TestFetchRef(t *testing.T) {
 	}
 }
 
+func TestFetchRef_AnnotatedTag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/owner/repo/git/ref/heads/v1.2.0":
+			w.WriteHeader(http.StatusNotFound)
+		case "/repos/owner/repo/git/ref/tags/v1.2.0":
+			w.Write([]byte(`{"object":{"type":"tag","sha":"tag-object-sha"}}`))
+		case "/repos/owner/repo/git/tags/tag-object-sha":
+			w.Write([]byte(`{"object":{"type":"commit","sha":"tagged-commit-sha"}}`))
+		case "/repos/owner/repo/git/commits/tagged-commit-sha":
+			w.Write([]byte(`{"sha":"tagged-commit-sha","tree":{"sha":"tagged-tree-sha"}}`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("owner", "repo", "")
+	client.httpClient = server.Client()
+	client.baseURL = server.URL
+
+	treeSHA, err := client.FetchRef("v1.2.0")
+	if err != nil {
+		t.Fatalf("FetchRef failed: %v", err)
+	}
+	if treeSHA != "tagged-tree-sha" {
+		t.Errorf("expected tree SHA 'tagged-tree-sha', got '%s'", treeSHA)
+	}
+}
+
+func TestFetchRef_CommitSHA(t *testing.T) {
+	full := "0123456789abcdef0123456789abcdef01234567"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/owner/repo/git/ref/heads/0123456", "/repos/owner/repo/git/ref/tags/0123456":
+			w.WriteHeader(http.StatusNotFound)
+		case "/repos/owner/repo/commits/0123456":
+			w.Write([]byte(`{"sha":"` + full + `"}`))
+		case "/repos/owner/repo/git/commits/" + full:
+			w.Write([]byte(`{"sha":"` + full + `","tree":{"sha":"pinned-tree-sha"}}`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("owner", "repo", "")
+	client.httpClient = server.Client()
+	client.baseURL = server.URL
+
+	// A full SHA skips the branch and tag lookups entirely.
+	for _, ref := range []string{full, "0123456"} {
+		treeSHA, err := client.FetchRef(ref)
+		if err != nil {
+			t.Fatalf("FetchRef(%s) failed: %v", ref, err)
+		}
+		if treeSHA != "pinned-tree-sha" {
+			t.Errorf("FetchRef(%s) = %q, want 'pinned-tree-sha'", ref, treeSHA)
+		}
+	}
+}
+
 func TestFetchTree(t *testing.T) {
 	treeResp := TreeResponse{
 		SHA:       "tree-sha-456",
@@ -314,3 +385,184 @@ func buildTestTarGz(t *testing.T, files map[string]string) []byte {
 
 	return buf.Bytes()
 }
+
+func TestFetchAgentDirChanges(t *testing.T) {
+	compareResp := CompareResponse{
+		Status: "ahead",
+		Files: []CompareFile{
+			{Filename: ".claude/commands/changed.md", Status: "modified", SHA: "blob-changed"},
+			{Filename: ".claude/commands/gone.md", Status: "removed", SHA: "blob-gone"},
+			{Filename: ".claude/commands/new-name.md", PreviousFilename: ".claude/commands/old-name.md", Status: "renamed", SHA: "blob-renamed"},
+			{Filename: ".opencode/commands/other.md", Status: "modified", SHA: "blob-other"},
+		},
+	}
+
+	var mu sync.Mutex
+	blobRequests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/repos/owner/repo/compare/base-sha...head-sha":
+			json.NewEncoder(w).Encode(compareResp)
+		case strings.HasPrefix(r.URL.Path, "/repos/owner/repo/git/blobs/"):
+			sha := strings.TrimPrefix(r.URL.Path, "/repos/owner/repo/git/blobs/")
+			mu.Lock()
+			blobRequests = append(blobRequests, sha)
+			mu.Unlock()
+			json.NewEncoder(w).Encode(BlobResponse{
+				SHA:      sha,
+				Content:  base64.StdEncoding.EncodeToString([]byte("content of " + sha)),
+				Encoding: "base64",
+			})
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("owner", "repo", "")
+	client.httpClient = server.Client()
+	client.baseURL = server.URL
+
+	changes, err := client.FetchAgentDirChanges(".claude", "base-sha", "head-sha")
+	if err != nil {
+		t.Fatalf("FetchAgentDirChanges failed: %v", err)
+	}
+
+	if len(blobRequests) != 2 {
+		t.Errorf("expected only changed blobs in .claude to be downloaded, got %v", blobRequests)
+	}
+	if string(changes.Changed["commands/changed.md"]) != "content of blob-changed" {
+		t.Errorf("unexpected changed content: %q", changes.Changed["commands/changed.md"])
+	}
+	if _, ok := changes.Changed["commands/new-name.md"]; !ok {
+		t.Error("renamed file should be fetched under its new name")
+	}
+	if changes.Blobs["commands/changed.md"] != "blob-changed" || len(changes.Blobs) != len(changes.Changed) {
+		t.Errorf("Blobs should hold the SHA of each changed file, got %v", changes.Blobs)
+	}
+
+	removed := strings.Join(changes.Removed, ",")
+	if !strings.Contains(removed, "commands/gone.md") || !strings.Contains(removed, "commands/old-name.md") {
+		t.Errorf("expected removed and renamed-away paths, got %v", changes.Removed)
+	}
+}
+
+func TestFetchAgentDirSinceSkipsKnownBlobs(t *testing.T) {
+	var mu sync.Mutex
+	blobRequests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/repos/owner/repo/git/ref/heads/main":
+			json.NewEncoder(w).Encode(map[string]interface{}{"object": map[string]string{"sha": "commit-sha"}})
+		case r.URL.Path == "/repos/owner/repo/git/commits/commit-sha":
+			json.NewEncoder(w).Encode(map[string]interface{}{"tree": map[string]string{"sha": "tree-sha"}})
+		case r.URL.Path == "/repos/owner/repo/git/trees/tree-sha":
+			json.NewEncoder(w).Encode(TreeResponse{Tree: []TreeEntry{
+				{Path: ".claude/commands/same.md", Type: "blob", SHA: "blob-same"},
+				{Path: ".claude/commands/changed.md", Type: "blob", SHA: "blob-new"},
+				{Path: ".claude/commands/added.md", Type: "blob", SHA: "blob-added"},
+				{Path: ".opencode/commands/other.md", Type: "blob", SHA: "blob-other"},
+			}})
+		case strings.HasPrefix(r.URL.Path, "/repos/owner/repo/git/blobs/"):
+			sha := strings.TrimPrefix(r.URL.Path, "/repos/owner/repo/git/blobs/")
+			mu.Lock()
+			blobRequests = append(blobRequests, sha)
+			mu.Unlock()
+			json.NewEncoder(w).Encode(BlobResponse{Content: base64.StdEncoding.EncodeToString([]byte("content of " + sha)), Encoding: "base64"})
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("owner", "repo", "")
+	client.httpClient = server.Client()
+	client.baseURL = server.URL
+
+	dir, err := client.FetchAgentDirSince(".claude", "main", map[string]string{
+		"commands/same.md":    "blob-same",
+		"commands/changed.md": "blob-old",
+	})
+	if err != nil {
+		t.Fatalf("FetchAgentDirSince failed: %v", err)
+	}
+	if len(blobRequests) != 2 {
+		t.Errorf("expected only new and changed blobs to be downloaded, got %v", blobRequests)
+	}
+	if _, ok := dir.Files["commands/same.md"]; ok {
+		t.Error("a file with a known blob SHA should not be downloaded")
+	}
+	if string(dir.Files["commands/changed.md"]) != "content of blob-new" {
+		t.Errorf("unexpected changed content: %q", dir.Files["commands/changed.md"])
+	}
+	if len(dir.Blobs) != 3 || dir.Blobs["commands/same.md"] != "blob-same" {
+		t.Errorf("Blobs should list every file in the directory, got %v", dir.Blobs)
+	}
+}
+
+func TestFetchAgentDirDownloadsConcurrently(t *testing.T) {
+	var tree []TreeEntry
+	for i := 0; i < 20; i++ {
+		tree = append(tree, TreeEntry{Path: fmt.Sprintf(".claude/skills/s%02d.md", i), Type: "blob", SHA: fmt.Sprintf("blob-%02d", i)})
+	}
+	var inFlight, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/repos/owner/repo/git/ref/heads/main":
+			json.NewEncoder(w).Encode(map[string]interface{}{"object": map[string]string{"sha": "commit-sha"}})
+		case r.URL.Path == "/repos/owner/repo/git/commits/commit-sha":
+			json.NewEncoder(w).Encode(map[string]interface{}{"tree": map[string]string{"sha": "tree-sha"}})
+		case r.URL.Path == "/repos/owner/repo/git/trees/tree-sha":
+			json.NewEncoder(w).Encode(TreeResponse{Tree: tree})
+		case strings.HasPrefix(r.URL.Path, "/repos/owner/repo/git/blobs/"):
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			sha := strings.TrimPrefix(r.URL.Path, "/repos/owner/repo/git/blobs/")
+			if sha == "blob-03" || sha == "blob-17" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			json.NewEncoder(w).Encode(BlobResponse{Content: base64.StdEncoding.EncodeToString([]byte(sha)), Encoding: "base64"})
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("owner", "repo", "")
+	client.httpClient = server.Client()
+	client.baseURL = server.URL
+
+	_, err := client.FetchAgentDir(".claude", "main")
+	if err == nil {
+		t.Fatal("FetchAgentDir should fail when blobs fail to download")
+	}
+	for _, path := range []string{".claude/skills/s03.md", ".claude/skills/s17.md"} {
+		if !strings.Contains(err.Error(), path) {
+			t.Errorf("error should name %s, got %v", path, err)
+		}
+	}
+	if p := atomic.LoadInt32(&peak); p < 2 || p > maxConcurrentBlobs {
+		t.Errorf("peak concurrent blob downloads = %d, want 2..%d", p, maxConcurrentBlobs)
+	}
+
+	tree = tree[4:17]
+	files, err := client.FetchAgentDir(".claude", "main")
+	if err != nil {
+		t.Fatalf("FetchAgentDir failed: %v", err)
+	}
+	if len(files) != 13 || string(files["skills/s10.md"]) != "blob-10" {
+		t.Errorf("FetchAgentDir() returned %d files, skills/s10.md = %q", len(files), files["skills/s10.md"])
+	}
+}
@@ -0,0 +1,181 @@
+package github
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultLFSURL  = "https://github.com"
+	lfsSpecVersion = "version https://git-lfs.github.com/spec/v1"
+	lfsMediaType   = "application/vnd.git-lfs+json"
+
+	// maxLFSPointerSize is the upper bound for a git LFS pointer file; real
+	// pointers are ~130 bytes, anything larger is regular content.
+	maxLFSPointerSize = 1024
+
+	// LargeFileWarnBytes is the size above which fetched files are reported as
+	// unexpectedly large.
+	LargeFileWarnBytes = 5 * 1024 * 1024
+)
+
+// lfsPointer identifies a git LFS object referenced by a pointer file.
+type lfsPointer struct {
+	OID  string `json:"oid"`
+	Size int64  `json:"size"`
+}
+
+type lfsBatchRequest struct {
+	Operation string       `json:"operation"`
+	Transfers []string     `json:"transfers"`
+	Objects   []lfsPointer `json:"objects"`
+}
+
+type lfsBatchResponse struct {
+	Objects []struct {
+		OID     string `json:"oid"`
+		Size    int64  `json:"size"`
+		Actions struct {
+			Download *struct {
+				Href   string            `json:"href"`
+				Header map[string]string `json:"header"`
+			} `json:"download"`
+		} `json:"actions"`
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	} `json:"objects"`
+}
+
+// parseLFSPointer reports whether content is a git LFS pointer file and, if
+// so, which object it references.
+func parseLFSPointer(content []byte) (*lfsPointer, bool) {
+	if len(content) > maxLFSPointerSize || !bytes.HasPrefix(content, []byte(lfsSpecVersion)) {
+		return nil, false
+	}
+
+	pointer := &lfsPointer{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if !found {
+			continue
+		}
+		switch key {
+		case "oid":
+			pointer.OID = strings.TrimPrefix(value, "sha256:")
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, false
+			}
+			pointer.Size = size
+		}
+	}
+
+	if pointer.OID == "" || pointer.Size < 0 {
+		return nil, false
+	}
+	return pointer, true
+}
+
+// resolveContent replaces git LFS pointers with the object they reference and
+// warns about unexpectedly large files. filePath is only used for messages.
+func (c *Client) resolveContent(filePath string, content []byte) ([]byte, error) {
+	if pointer, ok := parseLFSPointer(content); ok {
+		resolved, err := c.fetchLFSObject(pointer)
+		if err != nil {
+			return nil, fmt.Errorf("fetching LFS object for %s: %w", filePath, err)
+		}
+		content = resolved
+	}
+
+	if len(content) > LargeFileWarnBytes {
+		fmt.Fprintf(c.warnOut, "Warning: %s is %.1f MB, which is unexpectedly large for maestro assets\n", filePath, float64(len(content))/(1024*1024))
+	}
+	return content, nil
+}
+
+// fetchLFSObject downloads an LFS object through the repository's LFS batch API.
+func (c *Client) fetchLFSObject(pointer *lfsPointer) ([]byte, error) {
+	body, err := json.Marshal(lfsBatchRequest{
+		Operation: "download",
+		Transfers: []string{"basic"},
+		Objects:   []lfsPointer{*pointer},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encoding batch request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/%s/%s.git/info/lfs/objects/batch", c.lfsURL, c.owner, c.repo)
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("creating batch request: %w", err)
+	}
+	req.Header.Set("Accept", lfsMediaType)
+	req.Header.Set("Content-Type", lfsMediaType)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("executing batch request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("batch request: unexpected status: %d", resp.StatusCode)
+	}
+
+	var batch lfsBatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&batch); err != nil {
+		return nil, fmt.Errorf("decoding batch response: %w", err)
+	}
+	if len(batch.Objects) == 0 {
+		return nil, fmt.Errorf("batch response contained no objects")
+	}
+	object := batch.Objects[0]
+	if object.Error != nil {
+		return nil, fmt.Errorf("LFS server error %d: %s", object.Error.Code, object.Error.Message)
+	}
+	if object.Actions.Download == nil {
+		return nil, fmt.Errorf("LFS server returned no download action")
+	}
+
+	req, err = http.NewRequest("GET", object.Actions.Download.Href, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating download request: %w", err)
+	}
+	for key, value := range object.Actions.Download.Header {
+		req.Header.Set(key, value)
+	}
+
+	resp, err = c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("downloading object: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading object: unexpected status: %d", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading object: %w", err)
+	}
+
+	sum := sha256.Sum256(content)
+	if hex.EncodeToString(sum[:]) != pointer.OID {
+		return nil, fmt.Errorf("LFS object checksum mismatch for %s", pointer.OID)
+	}
+	return content, nil
+}
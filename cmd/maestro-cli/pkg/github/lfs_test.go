@@ -0,0 +1,93 @@
+package github
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseLFSPointer(t *testing.T) {
+	pointer := "version https://git-lfs.github.com/spec/v1\noid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\nsize 12345\n"
+
+	got, ok := parseLFSPointer([]byte(pointer))
+	if !ok {
+		t.Fatal("expected content to be detected as an LFS pointer")
+	}
+	if got.OID != "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393" || got.Size != 12345 {
+		t.Errorf("unexpected pointer: %+v", got)
+	}
+
+	if _, ok := parseLFSPointer([]byte("# Regular markdown\n")); ok {
+		t.Error("regular content should not be detected as an LFS pointer")
+	}
+}
+
+func TestFetchFileResolvesLFSPointer(t *testing.T) {
+	binary := []byte("\x89PNG fake image bytes")
+	sum := sha256.Sum256(binary)
+	oid := hex.EncodeToString(sum[:])
+	pointer := fmt.Sprintf("version https://git-lfs.github.com/spec/v1\noid sha256:%s\nsize %d\n", oid, len(binary))
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/repos/owner/repo/git/ref/heads/main":
+			json.NewEncoder(w).Encode(map[string]interface{}{"object": map[string]string{"sha": "commit-sha"}})
+		case r.URL.Path == "/repos/owner/repo/git/commits/commit-sha":
+			json.NewEncoder(w).Encode(map[string]interface{}{"tree": map[string]string{"sha": "tree-sha"}})
+		case r.URL.Path == "/repos/owner/repo/git/trees/tree-sha":
+			json.NewEncoder(w).Encode(TreeResponse{Tree: []TreeEntry{{Path: "assets/logo.png", Type: "blob", SHA: "blob-sha"}}})
+		case r.URL.Path == "/repos/owner/repo/git/blobs/blob-sha":
+			json.NewEncoder(w).Encode(BlobResponse{Content: base64.StdEncoding.EncodeToString([]byte(pointer)), Encoding: "base64"})
+		case r.URL.Path == "/owner/repo.git/info/lfs/objects/batch":
+			if r.Method != "POST" || r.Header.Get("Accept") != lfsMediaType {
+				t.Errorf("unexpected batch request: %s %s", r.Method, r.Header.Get("Accept"))
+			}
+			fmt.Fprintf(w, `{"objects":[{"oid":%q,"size":%d,"actions":{"download":{"href":%q,"header":{"X-Signed":"yes"}}}}]}`,
+				oid, len(binary), server.URL+"/lfs-object")
+		case r.URL.Path == "/lfs-object":
+			if r.Header.Get("X-Signed") != "yes" {
+				t.Error("download action headers should be forwarded")
+			}
+			w.Write(binary)
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("owner", "repo", "")
+	client.httpClient = server.Client()
+	client.baseURL = server.URL
+	client.lfsURL = server.URL
+
+	content, err := client.FetchFile("assets/logo.png", "main")
+	if err != nil {
+		t.Fatalf("FetchFile failed: %v", err)
+	}
+	if !bytes.Equal(content, binary) {
+		t.Errorf("expected LFS object content, got %q", content)
+	}
+}
+
+func TestResolveContentWarnsOnLargeFiles(t *testing.T) {
+	var warnings bytes.Buffer
+	client := NewClient("owner", "repo", "")
+	client.warnOut = &warnings
+
+	large := bytes.Repeat([]byte("a"), LargeFileWarnBytes+1)
+	if _, err := client.resolveContent("fonts/huge.ttf", large); err != nil {
+		t.Fatalf("resolveContent failed: %v", err)
+	}
+	if !strings.Contains(warnings.String(), "fonts/huge.ttf") {
+		t.Errorf("expected size warning for large file, got %q", warnings.String())
+	}
+}
@@ -0,0 +1,71 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrBadCredentials is returned when GitHub rejects the client's token.
+var ErrBadCredentials = errors.New("GitHub rejected the token (401 Bad credentials)")
+
+// RateLimit is the client's REST API quota.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// RateLimit fetches the client's core REST API quota. The request does not
+// count against it.
+func (c *Client) RateLimit() (*RateLimit, error) {
+	req, err := http.NewRequest("GET", c.baseURL+"/rate_limit", nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", apiVersion)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, ErrBadCredentials
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	var body struct {
+		Resources struct {
+			Core struct {
+				Limit     int   `json:"limit"`
+				Remaining int   `json:"remaining"`
+				Reset     int64 `json:"reset"`
+			} `json:"core"`
+		} `json:"resources"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	core := body.Resources.Core
+	return &RateLimit{Limit: core.Limit, Remaining: core.Remaining, Reset: time.Unix(core.Reset, 0)}, nil
+}
+
+// PingCodeload checks that the archive host, which serves tarballs when
+// the API is rate limited, answers. Any HTTP response counts.
+func (c *Client) PingCodeload() error {
+	resp, err := c.httpClient.Head(c.codeloadURL + "/")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,63 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseRepo extracts the owner and repository from an owner/repo shorthand
+// or a GitHub URL in any of its common forms:
+//
+//	acme/maestro-assets
+//	github.com/acme/maestro-assets
+//	https://github.com/acme/maestro-assets.git
+//	git@github.com:acme/maestro-assets.git
+//	ssh://git@github.com/acme/maestro-assets
+func ParseRepo(s string) (owner, repo string, err error) {
+	path := strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(path, "git@"):
+		host, rest, ok := strings.Cut(strings.TrimPrefix(path, "git@"), ":")
+		if !ok {
+			return "", "", fmt.Errorf("invalid repository %q", s)
+		}
+		if host != "github.com" {
+			return "", "", fmt.Errorf("repository %q is not on github.com; only GitHub repositories are supported", s)
+		}
+		path = rest
+	case strings.Contains(path, "://"):
+		_, rest, _ := strings.Cut(path, "://")
+		host, rest, _ := strings.Cut(rest, "/")
+		if _, h, ok := strings.Cut(host, "@"); ok {
+			host = h
+		}
+		if host != "github.com" && host != "www.github.com" {
+			return "", "", fmt.Errorf("repository %q is not on github.com; only GitHub repositories are supported", s)
+		}
+		path = rest
+	default:
+		path = strings.TrimPrefix(path, "github.com/")
+	}
+
+	path = strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git")
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid repository %q (want owner/repo or a GitHub URL)", s)
+	}
+	return parts[0], parts[1], nil
+}
+
+// FetchDefaultBranch returns the name of the repository's default branch.
+func (c *Client) FetchDefaultBranch() (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s", c.baseURL, c.owner, c.repo)
+	var repo struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := c.doGet(url, &repo); err != nil {
+		return "", fmt.Errorf("fetching repository %s/%s: %w", c.owner, c.repo, err)
+	}
+	if repo.DefaultBranch == "" {
+		return "", fmt.Errorf("repository %s/%s has no default branch", c.owner, c.repo)
+	}
+	return repo.DefaultBranch, nil
+}
@@ -0,0 +1,53 @@
+package github
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseRepo(t *testing.T) {
+	for _, in := range []string{
+		"acme/maestro-assets",
+		"github.com/acme/maestro-assets",
+		"https://github.com/acme/maestro-assets",
+		"https://github.com/acme/maestro-assets.git",
+		"https://github.com/acme/maestro-assets/",
+		"git@github.com:acme/maestro-assets.git",
+		"ssh://git@github.com/acme/maestro-assets.git",
+	} {
+		owner, repo, err := ParseRepo(in)
+		if err != nil || owner != "acme" || repo != "maestro-assets" {
+			t.Errorf("ParseRepo(%q) = %q, %q, %v", in, owner, repo, err)
+		}
+	}
+
+	for _, in := range []string{"", "acme", "acme/assets/extra", "https://gitlab.com/acme/assets", "git@gitlab.com:acme/assets.git"} {
+		if _, _, err := ParseRepo(in); err == nil {
+			t.Errorf("ParseRepo(%q) should fail", in)
+		}
+	}
+}
+
+func TestFetchDefaultBranch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/acme/app" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"full_name":"acme/app","default_branch":"trunk"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("acme", "app", "")
+	client.baseURL = server.URL
+	if branch, err := client.FetchDefaultBranch(); err != nil || branch != "trunk" {
+		t.Errorf("FetchDefaultBranch() = %q, %v; want trunk", branch, err)
+	}
+
+	client = NewClient("acme", "missing", "")
+	client.baseURL = server.URL
+	if _, err := client.FetchDefaultBranch(); err == nil {
+		t.Error("FetchDefaultBranch() should fail for a missing repository")
+	}
+}
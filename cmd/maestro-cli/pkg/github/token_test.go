@@ -83,3 +83,28 @@ func TestResolveToken_ReturnsEmptyWithoutAnySource(t *testing.T) {
 		t.Fatalf("expected empty token, got %q", got)
 	}
 }
+
+func TestResolveRepoTokenSource(t *testing.T) {
+	origCmd, origApp := ghTokenCommand, appFromEnv
+	defer func() { ghTokenCommand, appFromEnv = origCmd, origApp }()
+	appFromEnv = func() (*App, error) { return nil, nil }
+	ghTokenCommand = func() ([]byte, error) { return []byte("gh-token\n"), nil }
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "env-token")
+
+	if token, source, err := ResolveRepoTokenSource("", "o", "r"); token != "env-token" || source != "GH_TOKEN" || err != nil {
+		t.Errorf("with GH_TOKEN: got %q from %q, %v", token, source, err)
+	}
+	t.Setenv("GH_TOKEN", "")
+	if token, source, _ := ResolveRepoTokenSource("", "o", "r"); token != "gh-token" || source != "gh auth token" {
+		t.Errorf("with gh: got %q from %q", token, source)
+	}
+	ghTokenCommand = func() ([]byte, error) { return nil, errors.New("gh not installed") }
+	if token, source, _ := ResolveRepoTokenSource("", "o", "r"); token != "" || source != "" {
+		t.Errorf("without a token: got %q from %q", token, source)
+	}
+	appFromEnv = func() (*App, error) { return nil, errors.New("GITHUB_APP_PRIVATE_KEY is not set") }
+	if _, _, err := ResolveRepoTokenSource("", "o", "r"); err == nil {
+		t.Error("a misconfigured app should be an error")
+	}
+}
@@ -0,0 +1,48 @@
+// Package glyph provides the status symbols maestro prints in front of its
+// messages. In accessible mode they are replaced by plain ASCII words that
+// screen readers announce sensibly and any terminal can display.
+package glyph
+
+import "sync"
+
+var (
+	mu         sync.RWMutex
+	accessible bool
+)
+
+// SetAccessible turns accessible output on or off.
+func SetAccessible(on bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	accessible = on
+}
+
+// Accessible reports whether accessible output is on. Commands also use it
+// to avoid progress redraws and full-screen interfaces.
+func Accessible() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return accessible
+}
+
+func pick(symbol, word string) string {
+	if Accessible() {
+		return word
+	}
+	return symbol
+}
+
+// OK marks a success.
+func OK() string { return pick("✓", "OK") }
+
+// Warn marks a warning.
+func Warn() string { return pick("⚠", "WARN") }
+
+// Fail marks a failure.
+func Fail() string { return pick("✗", "FAIL") }
+
+// Arrow separates a source from its destination, or marks a next step.
+func Arrow() string { return pick("→", "->") }
+
+// Bullet starts a list item.
+func Bullet() string { return pick("•", "-") }
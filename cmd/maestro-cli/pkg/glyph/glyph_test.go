@@ -0,0 +1,15 @@
+package glyph
+
+import "testing"
+
+func TestAccessibleGlyphs(t *testing.T) {
+	defer SetAccessible(false)
+
+	if OK() != "✓" || Warn() != "⚠" || Fail() != "✗" || Arrow() != "→" {
+		t.Errorf("default glyphs = %q %q %q %q", OK(), Warn(), Fail(), Arrow())
+	}
+	SetAccessible(true)
+	if OK() != "OK" || Warn() != "WARN" || Fail() != "FAIL" || Arrow() != "->" {
+		t.Errorf("accessible glyphs = %q %q %q %q", OK(), Warn(), Fail(), Arrow())
+	}
+}
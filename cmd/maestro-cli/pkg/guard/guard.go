@@ -0,0 +1,236 @@
+// Package guard snapshots a project's .maestro/ directory, and optionally
+// other files, before a command runs, and rolls the project back to the
+// snapshot when the command went wrong.
+package guard
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spec-maestro/maestro-cli/pkg/safepath"
+	"github.com/spec-maestro/maestro-cli/pkg/tarball"
+)
+
+// Dir is where guard snapshots are kept, relative to the project root.
+// .maestro/archive/, which holds it, is never included in a snapshot nor
+// touched by a rollback.
+const Dir = ".maestro/archive/guard"
+
+// Take archives every file under .maestro/ and the extra files, given as
+// slash-separated paths relative to root, to Dir and returns the snapshot's
+// path. Extra files that do not exist are skipped.
+func Take(root string, extra []string, now time.Time) (string, error) {
+	files, err := maestroFiles(root)
+	if err != nil {
+		return "", err
+	}
+	for _, rel := range extra {
+		if strings.HasPrefix(rel, ".maestro/") {
+			continue
+		}
+		if info, err := os.Lstat(filepath.Join(root, filepath.FromSlash(rel))); err == nil && info.Mode().IsRegular() {
+			files = append(files, rel)
+		}
+	}
+
+	dir := filepath.Join(root, filepath.FromSlash(Dir))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating %s: %w", dir, err)
+	}
+	name, err := tarball.Create(dir, "", root, files, now)
+	if err != nil {
+		return "", fmt.Errorf("snapshot: %w", err)
+	}
+	return name, nil
+}
+
+// List returns the paths of the snapshots under root, oldest first.
+func List(root string) ([]string, error) {
+	dir := filepath.Join(root, filepath.FromSlash(Dir))
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", dir, err)
+	}
+	var paths []string
+	for _, entry := range entries {
+		if _, ok := Time(entry.Name()); ok {
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Time returns when the snapshot at snapshotPath was taken, from its name.
+func Time(snapshotPath string) (time.Time, bool) {
+	stamp, ok := strings.CutSuffix(filepath.Base(snapshotPath), ".tar.gz")
+	if !ok {
+		return time.Time{}, false
+	}
+	at, err := time.ParseInLocation(tarball.TimeFormat, stamp, time.Local)
+	return at, err == nil
+}
+
+// Files lists the regular files under each of dirs, given as
+// slash-separated paths relative to root, for Take's extra files. A file
+// in dirs is listed itself, and paths that do not exist are skipped.
+func Files(root string, dirs []string) ([]string, error) {
+	var files []string
+	for _, dir := range dirs {
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(dir))); os.IsNotExist(err) {
+			continue
+		}
+		found, err := dirFiles(root, dir)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+// Rollback restores the project at root to the snapshot at snapshotPath:
+// .maestro/ is made to match it exactly, removing files created since, and
+// the other files it holds are written back. Files outside .maestro/ that
+// the snapshot does not hold are left alone. It returns the number of files
+// written and removed.
+func Rollback(root, snapshotPath string) (written, removed int, err error) {
+	return rollback(root, snapshotPath, nil, true)
+}
+
+// RollbackPaths is Rollback limited to .maestro/ and paths, given as
+// slash-separated paths relative to root. Each of them is made to match the
+// snapshot exactly, removing files created since, and the snapshot's other
+// files are left alone.
+func RollbackPaths(root, snapshotPath string, paths []string) (written, removed int, err error) {
+	return rollback(root, snapshotPath, paths, false)
+}
+
+// rollback restores .maestro/ and paths exactly, and with all also writes
+// back every other file the snapshot holds.
+func rollback(root, snapshotPath string, paths []string, all bool) (written, removed int, err error) {
+	content := make(map[string][]byte)
+	err = walk(snapshotPath, func(e safepath.Entry) error {
+		if e.IsDir || !(all || within(e.Name, paths)) {
+			return nil
+		}
+		data, err := io.ReadAll(e.Body)
+		content[e.Name] = data
+		return err
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("reading snapshot %s: %w", snapshotPath, err)
+	}
+
+	current, err := maestroFiles(root)
+	if err != nil {
+		return 0, 0, err
+	}
+	others, err := Files(root, paths)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, rel := range append(current, others...) {
+		if _, ok := content[rel]; ok {
+			continue
+		}
+		if err := os.Remove(filepath.Join(root, filepath.FromSlash(rel))); err != nil {
+			return written, removed, fmt.Errorf("removing %s: %w", rel, err)
+		}
+		removed++
+	}
+
+	extract := safepath.ExtractTo(root)
+	err = walk(snapshotPath, func(e safepath.Entry) error {
+		data, ok := content[e.Name]
+		if e.IsDir || !ok {
+			return nil
+		}
+		target := filepath.Join(root, filepath.FromSlash(e.Name))
+		if existing, err := os.ReadFile(target); err == nil && bytes.Equal(existing, data) {
+			return nil
+		}
+		if err := extract(e); err != nil {
+			return fmt.Errorf("writing %s: %w", e.Name, err)
+		}
+		// ExtractTo only applies the mode to files it creates
+		if err := os.Chmod(target, e.Mode); err != nil {
+			return fmt.Errorf("setting the mode of %s: %w", e.Name, err)
+		}
+		written++
+		return nil
+	})
+	return written, removed, err
+}
+
+// walk calls fn for every entry of the snapshot at snapshotPath.
+func walk(snapshotPath string, fn func(safepath.Entry) error) error {
+	f, err := os.Open(snapshotPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	return safepath.WalkTar(gz, fn)
+}
+
+// within reports whether name is under .maestro/ or is, or is under, one
+// of paths.
+func within(name string, paths []string) bool {
+	for _, p := range append([]string{".maestro"}, paths...) {
+		if name == p || strings.HasPrefix(name, strings.TrimSuffix(p, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// maestroFiles lists the regular files under root/.maestro, except those
+// under .maestro/archive/, as slash-separated paths relative to root.
+func maestroFiles(root string) ([]string, error) {
+	return dirFiles(root, ".maestro")
+}
+
+// dirFiles lists the regular files under root/dir, except those under
+// .maestro/archive/, as slash-separated paths relative to root.
+func dirFiles(root, dir string) ([]string, error) {
+	var files []string
+	skip := filepath.Join(root, filepath.FromSlash(path.Dir(Dir)))
+	err := filepath.WalkDir(filepath.Join(root, filepath.FromSlash(dir)), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && p == skip {
+			return filepath.SkipDir
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", dir, err)
+	}
+	return files, nil
+}
@@ -0,0 +1,140 @@
+package guard
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTakeAndRollback(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string, mode os.FileMode) {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		os.WriteFile(p, []byte(content), mode)
+	}
+	write(".maestro/scripts/run.sh", "#!/bin/sh\n", 0755)
+	write(".maestro/specs/001-auth/spec.md", "# Auth\n", 0644)
+	write(".maestro/archive/snapshots/old.tar.gz", "kept", 0644)
+	write("src/main.go", "package main\n", 0644)
+	write("notes.txt", "untracked\n", 0644)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	snapshotPath, err := Take(root, []string{"src/main.go", "missing.go"}, now)
+	if err != nil {
+		t.Fatalf("Take() error: %v", err)
+	}
+	if second, err := Take(root, nil, now); err != nil || second == snapshotPath {
+		t.Errorf("a second snapshot in the same second should get its own name, got %s, %v", second, err)
+	} else {
+		os.Remove(second)
+	}
+
+	// What an agent might do
+	write(".maestro/specs/001-auth/spec.md", "mangled\n", 0644)
+	write(".maestro/specs/002-junk/spec.md", "junk\n", 0644)
+	os.Remove(filepath.Join(root, ".maestro", "scripts", "run.sh"))
+	write("src/main.go", "broken\n", 0644)
+	write("notes.txt", "edited\n", 0644)
+
+	written, removed, err := Rollback(root, snapshotPath)
+	if err != nil {
+		t.Fatalf("Rollback() error: %v", err)
+	}
+	if written != 3 || removed != 1 {
+		t.Errorf("Rollback() = %d written, %d removed; want 3, 1", written, removed)
+	}
+	for name, want := range map[string]string{
+		".maestro/specs/001-auth/spec.md":       "# Auth\n",
+		".maestro/scripts/run.sh":               "#!/bin/sh\n",
+		".maestro/archive/snapshots/old.tar.gz": "kept",
+		"src/main.go":                           "package main\n",
+		"notes.txt":                             "edited\n",
+	} {
+		if data, _ := os.ReadFile(filepath.Join(root, filepath.FromSlash(name))); string(data) != want {
+			t.Errorf("%s = %q, want %q", name, data, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, ".maestro", "specs", "002-junk", "spec.md")); !os.IsNotExist(err) {
+		t.Error("files created in .maestro/ after the snapshot should be removed")
+	}
+	if info, err := os.Stat(filepath.Join(root, ".maestro", "scripts", "run.sh")); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("run.sh should be restored executable, got %v", info)
+	}
+
+	snapshots, err := List(root)
+	if err != nil || len(snapshots) != 1 || snapshots[0] != snapshotPath {
+		t.Errorf("List() = %v, %v", snapshots, err)
+	}
+	if at, ok := Time(snapshotPath); !ok || !at.Equal(now) {
+		t.Errorf("Time() = %v, %v", at, ok)
+	}
+}
+
+func TestRollbackPaths(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		os.WriteFile(p, []byte(content), 0644)
+	}
+	write(".maestro/config.yaml", "v1\n")
+	write(".claude/commands/maestro.plan.md", "v1\n")
+
+	files, err := Files(root, []string{".claude", ".missing"})
+	if err != nil || len(files) != 1 || files[0] != ".claude/commands/maestro.plan.md" {
+		t.Fatalf("Files() = %v, %v", files, err)
+	}
+	snapshotPath, err := Take(root, files, time.Now())
+	if err != nil {
+		t.Fatalf("Take() error: %v", err)
+	}
+
+	write(".maestro/config.yaml", "v2\n")
+	write(".claude/commands/maestro.plan.md", "v2\n")
+	write(".claude/commands/maestro.new.md", "v2\n")
+	if _, _, err := RollbackPaths(root, snapshotPath, nil); err != nil {
+		t.Fatalf("RollbackPaths() error: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, ".maestro", "config.yaml")); string(data) != "v1\n" {
+		t.Errorf(".maestro/config.yaml = %q, want it restored", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, ".claude", "commands", "maestro.plan.md")); string(data) != "v2\n" {
+		t.Error("RollbackPaths() should leave the snapshot's files outside .maestro/ and the paths alone")
+	}
+
+	written, removed, err := RollbackPaths(root, snapshotPath, []string{".claude"})
+	if err != nil {
+		t.Fatalf("RollbackPaths() error: %v", err)
+	}
+	if written != 1 || removed != 1 {
+		t.Errorf("RollbackPaths() = %d written, %d removed; want 1, 1", written, removed)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".claude", "commands", "maestro.new.md")); !os.IsNotExist(err) {
+		t.Error("RollbackPaths() should remove files created in the path since the snapshot")
+	}
+}
+
+func TestRollbackDoesNotWriteThroughSymlinks(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	os.MkdirAll(filepath.Join(root, ".maestro"), 0755)
+	os.MkdirAll(filepath.Join(root, "src"), 0755)
+	os.WriteFile(filepath.Join(root, "src", "main.go"), []byte("package main\n"), 0644)
+	snapshotPath, err := Take(root, []string{"src/main.go"}, time.Now())
+	if err != nil {
+		t.Fatalf("Take() error: %v", err)
+	}
+
+	os.RemoveAll(filepath.Join(root, "src"))
+	if err := os.Symlink(outside, filepath.Join(root, "src")); err != nil {
+		t.Skipf("cannot create symlinks: %v", err)
+	}
+	if _, _, err := Rollback(root, snapshotPath); err == nil {
+		t.Error("Rollback() should refuse to write through a symlinked directory")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "main.go")); !os.IsNotExist(err) {
+		t.Error("Rollback() wrote outside the project")
+	}
+}
@@ -0,0 +1,135 @@
+// Package httpheaders adds configured headers to maestro's outgoing HTTP
+// requests, so internal mirrors that need auth headers work without
+// putting secrets in URLs. Each rule applies to URLs starting with its
+// prefix; headers come from the rule itself, with ${VAR} expanded from the
+// environment, or from a command that prints "Name: value" lines.
+package httpheaders
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Rule adds headers to requests whose URL starts with URL.
+type Rule struct {
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+	// Command is run with sh -c once per process; each output line is a
+	// "Name: value" header.
+	Command string `yaml:"command"`
+}
+
+var (
+	mu    sync.Mutex
+	rules []Rule
+	// emitted caches command output by rule index
+	emitted map[int]http.Header
+)
+
+// SetRules replaces the configured rules. Rules without a URL are rejected,
+// since they would send their headers everywhere.
+func SetRules(r []Rule) error {
+	for i, rule := range r {
+		if strings.TrimSpace(rule.URL) == "" {
+			return fmt.Errorf("http header rule %d has no url", i+1)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	rules = r
+	emitted = map[int]http.Header{}
+	return nil
+}
+
+// For returns the headers to send to url. Later rules override earlier
+// ones for the same header.
+func For(url string) (http.Header, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	headers := http.Header{}
+	for i, rule := range rules {
+		if !strings.HasPrefix(url, rule.URL) {
+			continue
+		}
+		for name, value := range rule.Headers {
+			headers.Set(name, os.ExpandEnv(value))
+		}
+		if rule.Command == "" {
+			continue
+		}
+		out, ok := emitted[i]
+		if !ok {
+			var err error
+			if out, err = runCommand(rule.Command); err != nil {
+				return nil, fmt.Errorf("header command for %s: %w", rule.URL, err)
+			}
+			emitted[i] = out
+		}
+		for name, values := range out {
+			headers[name] = values
+		}
+	}
+	return headers, nil
+}
+
+func runCommand(command string) (http.Header, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseHeaders(string(out))
+}
+
+// parseHeaders reads "Name: value" lines, skipping blank lines.
+func parseHeaders(s string) (http.Header, error) {
+	headers := http.Header{}
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("output line %q is not a \"Name: value\" header", line)
+		}
+		headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	return headers, scanner.Err()
+}
+
+// Transport adds the configured headers to each request it sends. Headers
+// are matched against every request separately, so a redirect to another
+// host does not carry a mirror's credentials along.
+type Transport struct {
+	// Base sends the requests; http.DefaultTransport when nil.
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	headers, err := For(req.URL.String())
+	if err != nil {
+		return nil, err
+	}
+	if len(headers) == 0 {
+		return base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+	return base.RoundTrip(req)
+}
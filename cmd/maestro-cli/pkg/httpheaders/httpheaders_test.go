@@ -0,0 +1,72 @@
+package httpheaders
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFor(t *testing.T) {
+	t.Setenv("MIRROR_TOKEN", "s3cret")
+	defer SetRules(nil)
+	err := SetRules([]Rule{
+		{URL: "https://mirror.example.com/", Headers: map[string]string{"Authorization": "Bearer ${MIRROR_TOKEN}"}},
+		{URL: "https://mirror.example.com/signed/", Command: "printf 'X-Signature: abc\\n\\nX-Expires: 60\\n'"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	headers, err := For("https://mirror.example.com/signed/maestro.tar.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if headers.Get("Authorization") != "Bearer s3cret" || headers.Get("X-Signature") != "abc" || headers.Get("X-Expires") != "60" {
+		t.Errorf("unexpected headers: %v", headers)
+	}
+	if headers, _ := For("https://github.com/owner/repo"); len(headers) != 0 {
+		t.Errorf("other URLs should get no headers, got %v", headers)
+	}
+
+	if err := SetRules([]Rule{{Headers: map[string]string{"X": "y"}}}); err == nil {
+		t.Error("a rule without a url should be rejected")
+	}
+}
+
+func TestParseHeadersRejectsMalformedLines(t *testing.T) {
+	if _, err := parseHeaders("X-Token abc\n"); err == nil {
+		t.Error("a line without a colon should be rejected")
+	}
+}
+
+func TestTransportDropsHeadersOnRedirectToOtherHost(t *testing.T) {
+	var redirected http.Header
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = r.Header
+	}))
+	defer other.Close()
+	var mirrored http.Header
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mirrored = r.Header
+		http.Redirect(w, r, other.URL+"/object", http.StatusFound)
+	}))
+	defer mirror.Close()
+
+	defer SetRules(nil)
+	if err := SetRules([]Rule{{URL: mirror.URL + "/", Headers: map[string]string{"X-Api-Key": "k"}}}); err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: Transport{}}
+	resp, err := client.Get(mirror.URL + "/asset.tar.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if mirrored.Get("X-Api-Key") != "k" {
+		t.Errorf("mirror should get the header, got %v", mirrored)
+	}
+	if redirected.Get("X-Api-Key") != "" {
+		t.Errorf("redirect target should not get the header, got %v", redirected)
+	}
+}
@@ -0,0 +1,163 @@
+// Package ignore reads .maestro/.maestroignore, the gitignore-style list of
+// project files that update must never touch, such as locally customized
+// scripts and templates.
+package ignore
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// File is where the ignore list lives, relative to the project root.
+const File = ".maestro/.maestroignore"
+
+// List is a parsed ignore file. Its zero value, and a nil *List, match
+// nothing.
+type List struct {
+	patterns []pattern
+	// root is the project directory Load read the list from.
+	root string
+}
+
+// pattern is one line of an ignore file.
+type pattern struct {
+	segments []string
+	negate   bool
+	dirOnly  bool
+}
+
+// Load reads the ignore file of the project at root. A missing file is an
+// empty list.
+func Load(root string) (*List, error) {
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(File)))
+	if os.IsNotExist(err) {
+		return &List{root: root}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", File, err)
+	}
+	l := Parse(string(data))
+	l.root = root
+	return l, nil
+}
+
+// Parse reads patterns in gitignore syntax, relative to the project root:
+// blank lines and lines starting with # are skipped, ! re-includes what an
+// earlier pattern ignored, a trailing / only matches directories, a
+// pattern with a / elsewhere is anchored at the root while one without
+// matches at any depth, and ** matches any number of directories.
+func Parse(data string) *List {
+	l := &List{}
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var p pattern
+		if strings.HasPrefix(line, "!") {
+			p.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+		if !anchored {
+			line = "**/" + line
+		}
+		p.segments = strings.Split(line, "/")
+		l.patterns = append(l.patterns, p)
+	}
+	return l
+}
+
+// Match reports whether the file at rel, a slash-separated path relative
+// to the project root, is ignored: the last pattern matching it or one of
+// its directories decides.
+func (l *List) Match(rel string) bool {
+	if l == nil {
+		return false
+	}
+	segments := strings.Split(path.Clean(strings.TrimPrefix(rel, "./")), "/")
+	ignored := false
+	for _, p := range l.patterns {
+		if p.matches(segments) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// MatchFile is Match for a file on disk under the project root the list
+// was loaded from.
+func (l *List) MatchFile(file string) bool {
+	if l.Empty() {
+		return false
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return false
+	}
+	root, err := filepath.Abs(l.root)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return false
+	}
+	return l.Match(filepath.ToSlash(rel))
+}
+
+// Empty reports whether the list has no patterns.
+func (l *List) Empty() bool {
+	return l == nil || len(l.patterns) == 0
+}
+
+// matches reports whether p matches the file at segments or one of its
+// directories.
+func (p pattern) matches(segments []string) bool {
+	last := len(segments)
+	if p.dirOnly {
+		last--
+	}
+	for n := 1; n <= last; n++ {
+		if matchSegments(p.segments, segments[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches a path against pattern segments, where ** stands
+// for any number of segments.
+func matchSegments(pat, segments []string) bool {
+	if len(pat) == 0 {
+		return len(segments) == 0
+	}
+	if pat[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pat[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pat[0], segments[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(pat[1:], segments[1:])
+}
@@ -0,0 +1,68 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+	list := Parse(`# customized locally
+.maestro/scripts/deploy.sh
+.maestro/templates/
+*.local.md
+.claude/**/review.md
+!.maestro/templates/keep-updated.md
+/CLAUDE.md
+\#notes
+`)
+	for rel, want := range map[string]bool{
+		".maestro/scripts/deploy.sh":          true,
+		".maestro/scripts/deploy.sh.bak":      false,
+		".maestro/scripts/other.sh":           false,
+		".maestro/templates/spec-template.md": true,
+		".maestro/templates/nested/a.md":      true,
+		".maestro/templates/keep-updated.md":  false,
+		".maestro/templates":                  false,
+		".opencode/commands/plan.local.md":    true,
+		".claude/review.md":                   true,
+		".claude/commands/deep/review.md":     true,
+		".opencode/review.md":                 false,
+		"CLAUDE.md":                           true,
+		"docs/CLAUDE.md":                      false,
+		"#notes":                              true,
+		"./.maestro/scripts/deploy.sh":        true,
+	} {
+		if got := list.Match(rel); got != want {
+			t.Errorf("Match(%q) = %v, want %v", rel, got, want)
+		}
+	}
+
+	var empty *List
+	if empty.Match("anything") || !empty.Empty() {
+		t.Error("a nil list should match nothing")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	list, err := Load(root)
+	if err != nil || !list.Empty() {
+		t.Fatalf("a missing file should be an empty list, got %v, %v", list, err)
+	}
+
+	os.MkdirAll(filepath.Join(root, ".maestro"), 0755)
+	os.WriteFile(filepath.Join(root, File), []byte("\n# nothing yet\n.maestro/scripts/*.sh\n"), 0644)
+	list, err = Load(root)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if !list.Match(".maestro/scripts/deploy.sh") || list.Match(".maestro/scripts/README.md") {
+		t.Error("Load() should read the patterns in the file")
+	}
+	if !list.MatchFile(filepath.Join(root, ".maestro", "scripts", "deploy.sh")) || list.MatchFile(filepath.Join(root, "deploy.sh")) {
+		t.Error("MatchFile() should match files relative to the root the list was loaded from")
+	}
+}
@@ -0,0 +1,186 @@
+// Package importer converts projects written with other spec-driven tools
+// into maestro features: spec documents under .maestro/specs/, a state entry
+// per feature, and a tasks.json ready for create-tasks.sh.
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spec-maestro/maestro-cli/pkg/adopt"
+	"github.com/spec-maestro/maestro-cli/pkg/state"
+)
+
+// Importer reads another tool's project layout.
+type Importer interface {
+	// Name is the tool name used on the command line, e.g. "spec-kit".
+	Name() string
+	// Scan reads the project rooted at dir without modifying it.
+	Scan(dir string) (*Project, error)
+}
+
+// Project is what an Importer found.
+type Project struct {
+	Features []Feature
+	// Notes are things that were found but not imported automatically.
+	Notes []string
+}
+
+// Feature is one feature of the source project.
+type Feature struct {
+	Source string // feature directory in the source project
+	Slug   string
+	// Files maps paths inside the new feature directory to source files.
+	Files map[string]string
+	Tasks []Task
+	Stage string // maestro pipeline stage the feature had reached
+}
+
+// Task is a task converted to the create-tasks.sh input format.
+type Task struct {
+	ID           string   `json:"id"`
+	Title        string   `json:"title"`
+	Description  string   `json:"description,omitempty"`
+	Label        string   `json:"label"`
+	Size         string   `json:"size"`
+	Assignee     string   `json:"assignee"`
+	Dependencies []string `json:"dependencies"`
+	Done         bool     `json:"done,omitempty"`
+}
+
+// Imported records where a feature was written.
+type Imported struct {
+	Feature
+	FeatureID string
+}
+
+var registry = map[string]Importer{}
+
+func register(i Importer) {
+	registry[i.Name()] = i
+}
+
+// Lookup returns the importer for a tool name.
+func Lookup(name string) (Importer, bool) {
+	i, ok := registry[name]
+	return i, ok
+}
+
+// Names returns the supported tool names, sorted.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Plan assigns maestro feature IDs, numbering after the highest feature
+// already in specsDir of the project at root.
+func Plan(root string, project *Project, specsDir string) []Imported {
+	next := adopt.NextFeatureNumber(inRoot(root, specsDir))
+	planned := make([]Imported, 0, len(project.Features))
+	for _, feature := range project.Features {
+		planned = append(planned, Imported{
+			Feature:   feature,
+			FeatureID: fmt.Sprintf("%03d-%s", next, feature.Slug),
+		})
+		next++
+	}
+	return planned
+}
+
+// Apply copies each planned feature into specsDir and writes its state entry
+// to stateDir, both relative to root. Source files are never modified, and
+// existing features are never overwritten. It returns the features imported
+// before any error.
+func Apply(root, tool string, planned []Imported, specsDir, stateDir string) ([]Imported, error) {
+	stateDir = inRoot(root, stateDir)
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return nil, fmt.Errorf("creating %s: %w", stateDir, err)
+	}
+
+	done := make([]Imported, 0, len(planned))
+	for _, feature := range planned {
+		dest := filepath.Join(specsDir, feature.FeatureID)
+		if _, err := os.Lstat(inRoot(root, dest)); err == nil {
+			return done, fmt.Errorf("importing %s: %s already exists", feature.Source, dest)
+		}
+		if err := writeFeature(feature, inRoot(root, dest)); err != nil {
+			return done, fmt.Errorf("importing %s: %w", feature.Source, err)
+		}
+		if err := writeState(tool, feature, dest, stateDir); err != nil {
+			return done, fmt.Errorf("importing %s: %w", feature.Source, err)
+		}
+		done = append(done, feature)
+	}
+	return done, nil
+}
+
+// inRoot resolves path, relative to the project root or absolute.
+func inRoot(root, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(root, path)
+}
+
+func writeFeature(feature Imported, dest string) error {
+	for rel, source := range feature.Files {
+		content, err := os.ReadFile(source)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			return err
+		}
+	}
+
+	if len(feature.Tasks) == 0 {
+		return nil
+	}
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"feature_id": feature.FeatureID,
+		"tasks":      feature.Tasks,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding tasks: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dest, "tasks.json"), append(data, '\n'), 0644)
+}
+
+func writeState(tool string, feature Imported, dest, stateDir string) error {
+	completed := 0
+	for _, task := range feature.Tasks {
+		if task.Done {
+			completed++
+		}
+	}
+
+	s := &state.State{FeatureID: feature.FeatureID, SpecPath: filepath.ToSlash(filepath.Join(dest, "spec.md"))}
+	extra := map[string]interface{}{"imported_from": tool + ":" + filepath.ToSlash(feature.Source)}
+	if len(feature.Tasks) > 0 {
+		extra["tasks_path"] = filepath.ToSlash(filepath.Join(dest, "tasks.json"))
+		extra["tasks_total"] = len(feature.Tasks)
+		extra["tasks_completed"] = completed
+	}
+	for key, value := range extra {
+		data, _ := json.Marshal(value)
+		if err := s.Set(key, data); err != nil {
+			return err
+		}
+	}
+	// The imported feature is already at its stage, so there is no
+	// transition to check
+	s.Record(feature.Stage, fmt.Sprintf("imported from %s (%s)", tool, filepath.ToSlash(feature.Source)), "", time.Now())
+	return state.Save(filepath.Join(stateDir, feature.FeatureID+".json"), s)
+}
@@ -0,0 +1,210 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spec-maestro/maestro-cli/pkg/adopt"
+)
+
+func init() {
+	register(SpecKit{})
+}
+
+// SpecKit imports GitHub spec-kit projects: specs/NNN-name/ directories
+// holding spec.md, plan.md, tasks.md, research.md, data-model.md, and
+// contracts/, plus a constitution under .specify/memory/.
+type SpecKit struct{}
+
+// Name implements Importer.
+func (SpecKit) Name() string { return "spec-kit" }
+
+// Scan implements Importer. dir may be the project root or its specs/ folder.
+func (SpecKit) Scan(dir string) (*Project, error) {
+	specsDir := filepath.Join(dir, "specs")
+	if info, err := os.Stat(specsDir); err != nil || !info.IsDir() {
+		specsDir = dir
+	}
+
+	entries, err := os.ReadDir(specsDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", specsDir, err)
+	}
+
+	project := &Project{}
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		featureDir := filepath.Join(specsDir, entry.Name())
+		if _, err := os.Stat(filepath.Join(featureDir, "spec.md")); err != nil {
+			continue
+		}
+
+		feature, err := scanSpecKitFeature(featureDir)
+		if err != nil {
+			return nil, err
+		}
+		project.Features = append(project.Features, *feature)
+	}
+	sort.Slice(project.Features, func(i, j int) bool {
+		return project.Features[i].Source < project.Features[j].Source
+	})
+	if len(project.Features) == 0 {
+		return nil, fmt.Errorf("no spec-kit features (directories with spec.md) found in %s", specsDir)
+	}
+
+	for _, candidate := range []string{
+		filepath.Join(dir, ".specify", "memory", "constitution.md"),
+		filepath.Join(dir, "memory", "constitution.md"),
+	} {
+		if _, err := os.Stat(candidate); err == nil {
+			project.Notes = append(project.Notes, fmt.Sprintf("constitution found at %s; merge it into .maestro/constitution.md by hand", candidate))
+			break
+		}
+	}
+	return project, nil
+}
+
+func scanSpecKitFeature(featureDir string) (*Feature, error) {
+	feature := &Feature{
+		Source: featureDir,
+		Slug:   adopt.Slugify(filepath.Base(featureDir)),
+		Files:  make(map[string]string),
+	}
+
+	err := filepath.WalkDir(featureDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && path != featureDir {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(featureDir, path)
+		if err != nil {
+			return err
+		}
+		feature.Files[filepath.ToSlash(rel)] = path
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", featureDir, err)
+	}
+
+	if tasksPath, ok := feature.Files["tasks.md"]; ok {
+		tasks, err := parseSpecKitTasks(tasksPath)
+		if err != nil {
+			return nil, err
+		}
+		feature.Tasks = tasks
+	}
+	feature.Stage = specKitStage(feature)
+	return feature, nil
+}
+
+// specKitStage infers how far a feature got from the documents it has.
+func specKitStage(feature *Feature) string {
+	if len(feature.Tasks) > 0 {
+		completed := 0
+		for _, task := range feature.Tasks {
+			if task.Done {
+				completed++
+			}
+		}
+		switch {
+		case completed == len(feature.Tasks):
+			return "complete"
+		case completed > 0:
+			return "implement"
+		default:
+			return "tasks"
+		}
+	}
+	if _, ok := feature.Files["plan.md"]; ok {
+		return "plan"
+	}
+	if _, ok := feature.Files["research.md"]; ok {
+		return "research"
+	}
+	return "specify"
+}
+
+var (
+	// - [ ] T001 [P] [US1] Create User model in src/models/user.py
+	specKitTaskLine  = regexp.MustCompile(`^\s*[-*]\s+\[([ xX])\]\s+(T\d+)\b\s*(.*)$`)
+	specKitTaskTag   = regexp.MustCompile(`^\[([^\]]+)\]\s*`)
+	specKitPhaseLine = regexp.MustCompile(`^#{2,4}\s+(?:Phase\s+[\d.]+\s*[:\-–]\s*)?(.+?)\s*$`)
+)
+
+// parseSpecKitTasks reads the checklist in a spec-kit tasks.md. Tasks are
+// labeled with their user story ([US1] -> us1), falling back to the phase
+// heading they appear under.
+func parseSpecKitTasks(path string) ([]Task, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var tasks []Task
+	phase := "general"
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := specKitPhaseLine.FindStringSubmatch(line); m != nil {
+			phase = adopt.Slugify(m[1])
+			continue
+		}
+		m := specKitTaskLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		task := Task{
+			ID:           m[2],
+			Label:        phase,
+			Size:         "S",
+			Assignee:     "general",
+			Dependencies: []string{},
+			Done:         m[1] != " ",
+		}
+		rest := m[3]
+		var notes []string
+		for {
+			tag := specKitTaskTag.FindStringSubmatch(rest)
+			if tag == nil {
+				break
+			}
+			rest = rest[len(tag[0]):]
+			switch {
+			case tag[1] == "P":
+				notes = append(notes, "parallelizable")
+			case strings.HasPrefix(tag[1], "US"):
+				task.Label = strings.ToLower(tag[1])
+			default:
+				notes = append(notes, tag[1])
+			}
+		}
+		task.Title = strings.TrimSpace(rest)
+		task.Description = fmt.Sprintf("Imported from spec-kit task %s.", task.ID)
+		if len(notes) > 0 {
+			task.Description += " Tags: " + strings.Join(notes, ", ") + "."
+		}
+		tasks = append(tasks, task)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return tasks, nil
+}
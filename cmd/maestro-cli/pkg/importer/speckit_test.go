@@ -0,0 +1,151 @@
+package importer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const sampleTasks = `# Tasks: User Auth
+
+## Phase 1: Setup
+
+- [x] T001 Create project structure per implementation plan
+- [x] T002 [P] Configure linting tools
+
+## Phase 3: User Story 1 - Sign in (Priority: P1)
+
+- [ ] T010 [P] [US1] Create User model in src/models/user.py
+- [ ] T011 [US1] Implement AuthService in src/services/auth.py
+`
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func setupSpecKit(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".specify", "memory", "constitution.md"), "# Constitution\n")
+	writeFile(t, filepath.Join(root, "specs", "001-user-auth", "spec.md"), "# Auth\n")
+	writeFile(t, filepath.Join(root, "specs", "001-user-auth", "plan.md"), "# Plan\n")
+	writeFile(t, filepath.Join(root, "specs", "001-user-auth", "tasks.md"), sampleTasks)
+	writeFile(t, filepath.Join(root, "specs", "001-user-auth", "contracts", "api.yaml"), "openapi: 3.0.0\n")
+	writeFile(t, filepath.Join(root, "specs", "002-billing", "spec.md"), "# Billing\n")
+	writeFile(t, filepath.Join(root, "specs", "notes", "readme.txt"), "not a feature\n")
+	return root
+}
+
+func TestSpecKitScan(t *testing.T) {
+	root := setupSpecKit(t)
+
+	project, err := SpecKit{}.Scan(root)
+	if err != nil {
+		t.Fatalf("Scan() error: %v", err)
+	}
+	if len(project.Features) != 2 {
+		t.Fatalf("expected 2 features, got %d", len(project.Features))
+	}
+
+	auth := project.Features[0]
+	if auth.Slug != "user-auth" || auth.Stage != "implement" {
+		t.Errorf("auth feature = slug %q stage %q", auth.Slug, auth.Stage)
+	}
+	if _, ok := auth.Files["contracts/api.yaml"]; !ok {
+		t.Errorf("nested files should be included: %v", auth.Files)
+	}
+	if len(auth.Tasks) != 4 {
+		t.Fatalf("expected 4 tasks, got %+v", auth.Tasks)
+	}
+	if task := auth.Tasks[0]; task.ID != "T001" || !task.Done || task.Label != "setup" {
+		t.Errorf("task 0 = %+v", task)
+	}
+	if task := auth.Tasks[2]; task.ID != "T010" || task.Done || task.Label != "us1" || task.Title != "Create User model in src/models/user.py" {
+		t.Errorf("task 2 = %+v", task)
+	}
+
+	if billing := project.Features[1]; billing.Stage != "specify" || len(billing.Tasks) != 0 {
+		t.Errorf("billing feature = %+v", billing)
+	}
+	if len(project.Notes) != 1 {
+		t.Errorf("expected a constitution note, got %v", project.Notes)
+	}
+}
+
+func TestSpecKitScanRequiresFeatures(t *testing.T) {
+	if _, err := (SpecKit{}).Scan(t.TempDir()); err == nil {
+		t.Error("expected error for directory without features")
+	}
+}
+
+func TestApplyWritesSpecsStateAndTasks(t *testing.T) {
+	root := setupSpecKit(t)
+	project, err := SpecKit{}.Scan(filepath.Join(root, "specs"))
+	if err != nil {
+		t.Fatalf("Scan() error: %v", err)
+	}
+
+	target := t.TempDir()
+	specsDir := filepath.Join(target, ".maestro", "specs")
+	stateDir := filepath.Join(target, ".maestro", "state")
+	os.MkdirAll(filepath.Join(specsDir, "004-existing"), 0755)
+
+	imported, err := Apply(target, "spec-kit", Plan(target, project, ".maestro/specs"), ".maestro/specs", ".maestro/state")
+	if err != nil {
+		t.Fatalf("Apply() error: %v", err)
+	}
+	if len(imported) != 2 || imported[0].FeatureID != "005-user-auth" || imported[1].FeatureID != "006-billing" {
+		t.Fatalf("unexpected feature IDs: %+v", imported)
+	}
+
+	if _, err := os.Stat(filepath.Join(specsDir, "005-user-auth", "contracts", "api.yaml")); err != nil {
+		t.Errorf("nested file not copied: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "specs", "001-user-auth", "spec.md")); err != nil {
+		t.Error("source files must be left in place")
+	}
+
+	data, err := os.ReadFile(filepath.Join(specsDir, "005-user-auth", "tasks.json"))
+	if err != nil {
+		t.Fatalf("tasks.json missing: %v", err)
+	}
+	var tasks struct {
+		FeatureID string `json:"feature_id"`
+		Tasks     []Task `json:"tasks"`
+	}
+	if err := json.Unmarshal(data, &tasks); err != nil || tasks.FeatureID != "005-user-auth" || len(tasks.Tasks) != 4 {
+		t.Errorf("tasks.json = %s, %v", data, err)
+	}
+
+	data, err = os.ReadFile(filepath.Join(stateDir, "005-user-auth.json"))
+	if err != nil {
+		t.Fatalf("state entry missing: %v", err)
+	}
+	var state map[string]interface{}
+	json.Unmarshal(data, &state)
+	if state["stage"] != "implement" || state["tasks_completed"] != float64(2) || state["tasks_total"] != float64(4) {
+		t.Errorf("unexpected state: %v", state)
+	}
+	if state["spec_path"] != ".maestro/specs/005-user-auth/spec.md" {
+		t.Errorf("spec_path = %v, want it relative to the project root", state["spec_path"])
+	}
+	if _, err := os.Stat(filepath.Join(stateDir, "006-billing.json")); err != nil {
+		t.Errorf("state for second feature missing: %v", err)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	if _, ok := Lookup("spec-kit"); !ok {
+		t.Error("spec-kit importer should be registered")
+	}
+	if _, ok := Lookup("unknown"); ok {
+		t.Error("unknown tool should not resolve")
+	}
+}
@@ -0,0 +1,180 @@
+// Package links finds the relative links and file references in workflow
+// markdown, such as specs, plans, and command prompts, and reports the ones
+// whose target does not exist.
+package links
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Kinds of reference.
+const (
+	// Link is a markdown link, image, or link reference definition,
+	// relative to the file it is in.
+	Link = "link"
+	// FileRef is a path into the maestro assets, such as
+	// .maestro/scripts/create-feature.sh, relative to the project root.
+	// Prompts name them in prose and code, not only in links.
+	FileRef = "file"
+)
+
+// Ref is a reference found in a markdown file.
+type Ref struct {
+	Line   int
+	Kind   string
+	Target string
+}
+
+// Broken is a reference whose target does not exist.
+type Broken struct {
+	Ref
+	// Resolved is the missing target, slash-separated and relative to the
+	// project root.
+	Resolved string
+}
+
+func (b Broken) String() string {
+	if b.Kind == FileRef {
+		return fmt.Sprintf("line %d: %s not found", b.Line, b.Target)
+	}
+	return fmt.Sprintf("line %d: link to %s: %s not found", b.Line, b.Target, b.Resolved)
+}
+
+var (
+	inlineLink   = regexp.MustCompile(`!?\[[^\]]*\]\(\s*(<[^>]*>|[^)\s]+)(?:\s+(?:"[^"]*"|'[^']*'))?\s*\)`)
+	referenceDef = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:\s*(<[^>]*>|\S+)`)
+	codeSpan     = regexp.MustCompile("`+[^`]*`+")
+	// assetRef matches paths into the directories maestro installs, which
+	// are expected to exist once the project is initialized.
+	assetRef = regexp.MustCompile(`\.maestro/(?:scripts|templates|reference|cookbook|commands|skills)/[A-Za-z0-9_./-]*`)
+)
+
+// Find returns the references in content. Links inside code are not
+// links, but file references are found everywhere, since prompts name
+// scripts in code blocks. References with placeholders, such as
+// {feature_id} or <name>, globs, and directories are left out.
+func Find(content []byte) []Ref {
+	var refs []Ref
+	fence := ""
+	for i, line := range strings.Split(string(content), "\n") {
+		n := i + 1
+		for _, target := range fileRefs(line) {
+			refs = append(refs, Ref{Line: n, Kind: FileRef, Target: target})
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		text := codeSpan.ReplaceAllString(line, "")
+		var targets []string
+		for _, m := range inlineLink.FindAllStringSubmatch(text, -1) {
+			targets = append(targets, m[1])
+		}
+		if m := referenceDef.FindStringSubmatch(text); m != nil {
+			targets = append(targets, m[1])
+		}
+		for _, target := range targets {
+			target = strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+			if isLocal(target) {
+				refs = append(refs, Ref{Line: n, Kind: Link, Target: target})
+			}
+		}
+	}
+	return refs
+}
+
+// fileRefs returns the asset paths named on line.
+func fileRefs(line string) []string {
+	var targets []string
+	for _, loc := range assetRef.FindAllStringIndex(line, -1) {
+		start, end := loc[0], loc[1]
+		if start > 0 && isPathChar(line[start-1]) {
+			continue
+		}
+		if end < len(line) && strings.ContainsRune("{<*$[", rune(line[end])) {
+			continue
+		}
+		target := strings.TrimRight(line[start:end], ".")
+		if strings.HasSuffix(target, "/") || path.Ext(target) == "" {
+			continue
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+func isPathChar(c byte) bool {
+	return c == '/' || c == '_' || c == '-' || c == '.' ||
+		'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// isLocal reports whether a link target is a relative path that can be
+// checked: not a URL, not an anchor in the same file, not absolute, and
+// without placeholders.
+func isLocal(target string) bool {
+	switch {
+	case target == "", strings.HasPrefix(target, "#"), strings.HasPrefix(target, "/"):
+		return false
+	case strings.ContainsAny(target, "{}<>*$"):
+		return false
+	}
+	if u, err := url.Parse(target); err != nil || u.Scheme != "" || u.Host != "" {
+		return false
+	}
+	return true
+}
+
+// Check finds the references in content, the markdown file at file, and
+// returns those whose target is missing. file is slash-separated and
+// relative to root, the project root. Links are resolved from the file's
+// directory, except links into .maestro/, which prompts write relative
+// to the project root, as agents read them.
+func Check(root, file string, content []byte) []Broken {
+	var broken []Broken
+	for _, ref := range Find(content) {
+		resolved, ok := resolve(file, ref)
+		if !ok {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(resolved))); os.IsNotExist(err) {
+			broken = append(broken, Broken{Ref: ref, Resolved: resolved})
+		}
+	}
+	return broken
+}
+
+// resolve returns the path ref points to, relative to the project root.
+func resolve(file string, ref Ref) (string, bool) {
+	if ref.Kind == FileRef {
+		return ref.Target, true
+	}
+	target := ref.Target
+	if i := strings.IndexAny(target, "#?"); i >= 0 {
+		target = target[:i]
+	}
+	if target == "" {
+		return "", false
+	}
+	if unescaped, err := url.PathUnescape(target); err == nil {
+		target = unescaped
+	}
+	if strings.HasPrefix(target, ".maestro/") {
+		return path.Clean(target), true
+	}
+	return path.Join(path.Dir(file), target), true
+}
@@ -0,0 +1,58 @@
+package links
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	content := "# Plan\n" +
+		"See [the spec](spec.md), [research](research/synthesis.md#sources) and ![diagram](<img/flow chart.png> \"Flow\").\n" +
+		"Ignore [site](https://example.com), [anchor](#steps), [root](/etc/passwd), and [template]({feature_id}/spec.md).\n" +
+		"Run `bash .maestro/scripts/create-feature.sh` or `[not a link](x.md)`.\n" +
+		"```bash\n" +
+		"bash .maestro/scripts/update-state.sh \"$ID\"\n" +
+		"[in a fence](fenced.md)\n" +
+		"```\n" +
+		"Skip .maestro/state/{feature_id}.json, .maestro/scripts/, .maestro/commands/*.md and .maestro/specs/001-x/spec.md.\n" +
+		"Read .maestro/templates/spec-template.md.\n" +
+		"[ref]: ../other/plan.md\n"
+
+	want := []Ref{
+		{2, Link, "spec.md"},
+		{2, Link, "research/synthesis.md#sources"},
+		{2, Link, "img/flow chart.png"},
+		{4, FileRef, ".maestro/scripts/create-feature.sh"},
+		{6, FileRef, ".maestro/scripts/update-state.sh"},
+		{10, FileRef, ".maestro/templates/spec-template.md"},
+		{11, Link, "../other/plan.md"},
+	}
+	if got := Find([]byte(content)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Find() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	root := t.TempDir()
+	for _, file := range []string{".maestro/specs/001-x/spec.md", ".maestro/scripts/create-feature.sh", "docs/guide.md"} {
+		path := filepath.Join(root, filepath.FromSlash(file))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, nil, 0644)
+	}
+
+	content := []byte("[spec](spec.md#goals) [plan](plan.md) [guide](../../../docs/guide.md) [script](.maestro/scripts/create-feature.sh)\n" +
+		"Run .maestro/scripts/renamed.sh and [escaped](spec%2Emd).\n")
+	got := Check(root, ".maestro/specs/001-x/plan.md", content)
+	want := []Broken{
+		{Ref{1, Link, "plan.md"}, ".maestro/specs/001-x/plan.md"},
+		{Ref{2, FileRef, ".maestro/scripts/renamed.sh"}, ".maestro/scripts/renamed.sh"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check() = %v, want %v", got, want)
+	}
+	if s := got[0].String(); s != "line 1: link to plan.md: .maestro/specs/001-x/plan.md not found" {
+		t.Errorf("String() = %q", s)
+	}
+}
@@ -0,0 +1,388 @@
+// Package merge applies new versions of maestro-managed files to a project
+// without losing local edits. The install manifest's checksum of each file
+// as maestro last wrote it is the merge base: a file that still matches it
+// is replaced, and a file that was edited is only replaced when the new
+// version differs from the base too, following the chosen Strategy.
+package merge
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Strategy says what to do with a file changed both locally and upstream.
+type Strategy string
+
+const (
+	// Keep leaves the local file as it is.
+	Keep Strategy = "keep"
+	// WriteNew keeps the local file and writes the new version next to it
+	// as <file>.new.
+	WriteNew Strategy = "new"
+	// Markers writes the file with conflict markers around the lines that
+	// differ.
+	Markers Strategy = "markers"
+)
+
+// Strategies lists the valid strategies, for flag help.
+var Strategies = []Strategy{Keep, WriteNew, Markers}
+
+// ParseStrategy parses a --strategy value.
+func ParseStrategy(s string) (Strategy, error) {
+	for _, strategy := range Strategies {
+		if Strategy(strings.ToLower(s)) == strategy {
+			return strategy, nil
+		}
+	}
+	return "", fmt.Errorf("invalid strategy %q (use keep, new, or markers)", s)
+}
+
+// Action is what happened, or would happen, to one file.
+type Action string
+
+const (
+	Created   Action = "create"
+	Updated   Action = "update"
+	Unchanged Action = "unchanged"
+	// Kept means the local edits were kept; the new version is the same as
+	// the base, or the strategy is Keep.
+	Kept Action = "keep"
+	// NewFile means the new version was written to <file>.new.
+	NewFile Action = "write .new"
+	// Conflicted means the file was written with conflict markers.
+	Conflicted Action = "conflict"
+	// Removed means the file was deleted because the new version no longer
+	// has it. A deleted file that was edited is Kept instead.
+	Removed Action = "remove"
+)
+
+// Result is the merge of one file.
+type Result struct {
+	Path   string `json:"path"`
+	Action Action `json:"action"`
+	// Conflict is set when the file was changed locally and upstream.
+	Conflict bool `json:"conflict,omitempty"`
+	// Removed is set when the new version no longer has the file.
+	Removed bool `json:"removed,omitempty"`
+}
+
+// Plan decides, without writing anything, how each incoming file would be
+// merged into the project at root. incoming maps slash-separated paths
+// relative to root to their new content; base maps the same paths to the sha256
+// recorded in the install manifest. Files missing from base are treated as
+// edited locally when they exist. Files in base that incoming lacks were
+// removed upstream, so base must only hold the files incoming replaces.
+// Results are sorted by path.
+func Plan(root string, incoming map[string][]byte, base map[string]string, strategy Strategy) ([]Result, error) {
+	paths := make([]string, 0, len(incoming))
+	for p := range incoming {
+		paths = append(paths, p)
+	}
+	removed := make(map[string]bool)
+	for p := range base {
+		if _, ok := incoming[p]; !ok {
+			paths = append(paths, p)
+			removed[p] = true
+		}
+	}
+	sort.Strings(paths)
+
+	results := make([]Result, 0, len(paths))
+	for _, p := range paths {
+		var result Result
+		var err error
+		if removed[p] {
+			result, err = planRemoval(root, p, base[p])
+		} else {
+			result, err = planFile(root, p, incoming[p], base[p], strategy)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if result.Path != "" {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// Apply merges incoming into the project at root as Plan decides and
+// returns the results.
+func Apply(root string, incoming map[string][]byte, base map[string]string, strategy Strategy, label string) ([]Result, error) {
+	results, err := Plan(root, incoming, base, strategy)
+	if err != nil {
+		return nil, err
+	}
+	return results, Execute(root, results, incoming, label)
+}
+
+// Execute carries out the results of Plan in the project at root: it
+// writes and removes files. label names the incoming version in conflict
+// markers.
+func Execute(root string, results []Result, incoming map[string][]byte, label string) error {
+	return execute(results, incoming, label, func(p string) string {
+		return filepath.Join(root, filepath.FromSlash(p))
+	})
+}
+
+// StagingPrefix starts the name of the directories ExecuteStaged stages an
+// update in, next to the directory it updates. One is only left behind
+// when maestro is interrupted.
+const StagingPrefix = ".maestro-update-"
+
+// ExecuteStaged carries out the results of Plan for the files under dir,
+// relative to the project root, as one transaction. It copies dir to a staging directory next to it, merges
+// into the copy, checks that every file landed with its new content, and
+// runs validate on the copy, which can also finish it (e.g. by recording
+// the install manifest). Only then is the copy swapped in for dir with
+// renames. On any failure dir is left as it was. Every result must be
+// under dir.
+func ExecuteStaged(root, dir string, results []Result, incoming map[string][]byte, label string, validate func(staged string) error) error {
+	prefix := path.Clean(filepath.ToSlash(dir)) + "/"
+	for _, r := range results {
+		if !strings.HasPrefix(r.Path, prefix) {
+			return fmt.Errorf("%s is outside %s", r.Path, dir)
+		}
+	}
+	dir = filepath.Join(root, dir)
+	// Swap the directory a symlinked dir points to, not the link
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = real
+	}
+
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), StagingPrefix)
+	if err != nil {
+		return fmt.Errorf("creating staging directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	staged := filepath.Join(tmp, filepath.Base(dir))
+	if err := copyTree(dir, staged); err != nil {
+		return fmt.Errorf("staging %s: %w", dir, err)
+	}
+
+	locate := func(p string) string {
+		return filepath.Join(staged, filepath.FromSlash(strings.TrimPrefix(p, prefix)))
+	}
+	if err := execute(results, incoming, label, locate); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if r.Action != Created && r.Action != Updated {
+			continue
+		}
+		if data, err := os.ReadFile(locate(r.Path)); err != nil || Sum(data) != Sum(incoming[r.Path]) {
+			return fmt.Errorf("staged %s does not match the update", r.Path)
+		}
+	}
+	if validate != nil {
+		if err := validate(staged); err != nil {
+			return err
+		}
+	}
+
+	// Swap: move dir aside into the staging directory, then the copy in
+	backup := filepath.Join(tmp, filepath.Base(dir)+".orig")
+	if err := os.Rename(dir, backup); err != nil {
+		return fmt.Errorf("replacing %s: %w", dir, err)
+	}
+	if err := os.Rename(staged, dir); err != nil {
+		if restoreErr := os.Rename(backup, dir); restoreErr != nil {
+			return fmt.Errorf("replacing %s: %v (restore failed, the original is in %s: %w)", dir, err, backup, restoreErr)
+		}
+		return fmt.Errorf("replacing %s: %w", dir, err)
+	}
+	return nil
+}
+
+// execute writes and removes the files of results at the paths locate
+// maps them to.
+func execute(results []Result, incoming map[string][]byte, label string, locate func(p string) string) error {
+	for _, r := range results {
+		var target string
+		var data []byte
+		switch r.Action {
+		case Created, Updated:
+			target, data = locate(r.Path), incoming[r.Path]
+		case NewFile:
+			target, data = locate(r.Path+".new"), incoming[r.Path]
+		case Removed:
+			if err := os.Remove(locate(r.Path)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("removing %s: %w", r.Path, err)
+			}
+			continue
+		case Conflicted:
+			local, err := os.ReadFile(locate(r.Path))
+			if err != nil {
+				return fmt.Errorf("reading %s: %w", r.Path, err)
+			}
+			target, data = locate(r.Path), ConflictMarkers(local, incoming[r.Path], label)
+		default:
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("creating directory for %s: %w", target, err)
+		}
+		if err := os.WriteFile(target, data, fileMode(target)); err != nil {
+			return fmt.Errorf("writing %s: %w", target, err)
+		}
+	}
+	return nil
+}
+
+// copyTree copies the directory src to dst, which must not exist, keeping
+// file modes. Symlinks are copied as links.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.Mkdir(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, info.Mode().Perm())
+	})
+}
+
+func planFile(root, p string, incoming []byte, baseSum string, strategy Strategy) (Result, error) {
+	local, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(p)))
+	if os.IsNotExist(err) {
+		return Result{Path: p, Action: Created}, nil
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("reading %s: %w", p, err)
+	}
+
+	switch {
+	case bytes.Equal(local, incoming):
+		return Result{Path: p, Action: Unchanged}, nil
+	case baseSum != "" && Sum(local) == baseSum:
+		return Result{Path: p, Action: Updated}, nil
+	case baseSum != "" && Sum(incoming) == baseSum:
+		return Result{Path: p, Action: Kept}, nil
+	}
+
+	result := Result{Path: p, Conflict: true}
+	switch strategy {
+	case Keep:
+		result.Action = Kept
+	case WriteNew:
+		result.Action = NewFile
+	case Markers:
+		result.Action = Conflicted
+	default:
+		return Result{}, fmt.Errorf("unknown strategy %q", strategy)
+	}
+	return result, nil
+}
+
+// planRemoval decides what to do with a file the new version no longer
+// has: delete it unless it was edited. A file already gone has no result.
+func planRemoval(root, p, baseSum string) (Result, error) {
+	local, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(p)))
+	if os.IsNotExist(err) {
+		return Result{}, nil
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("reading %s: %w", p, err)
+	}
+	if Sum(local) == baseSum {
+		return Result{Path: p, Action: Removed, Removed: true}, nil
+	}
+	return Result{Path: p, Action: Kept, Conflict: true, Removed: true}, nil
+}
+
+// ConflictMarkers returns local with the lines that differ from incoming
+// replaced by a conflict block holding both versions. Lines the two
+// versions start and end with in common are left outside the block.
+func ConflictMarkers(local, incoming []byte, label string) []byte {
+	ours := splitLines(local)
+	theirs := splitLines(incoming)
+
+	prefix := 0
+	for prefix < len(ours) && prefix < len(theirs) && ours[prefix] == theirs[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(ours)-prefix && suffix < len(theirs)-prefix &&
+		ours[len(ours)-1-suffix] == theirs[len(theirs)-1-suffix] {
+		suffix++
+	}
+
+	var b bytes.Buffer
+	for _, line := range ours[:prefix] {
+		b.WriteString(line)
+	}
+	b.WriteString("<<<<<<< local\n")
+	for _, line := range ours[prefix : len(ours)-suffix] {
+		b.WriteString(terminated(line))
+	}
+	b.WriteString("=======\n")
+	for _, line := range theirs[prefix : len(theirs)-suffix] {
+		b.WriteString(terminated(line))
+	}
+	b.WriteString(">>>>>>> " + label + "\n")
+	for _, line := range ours[len(ours)-suffix:] {
+		b.WriteString(line)
+	}
+	return b.Bytes()
+}
+
+// Sum returns the sha256 of data as recorded in the install manifest.
+func Sum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// splitLines splits data after each newline, keeping the newlines.
+func splitLines(data []byte) []string {
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// terminated ends line with a newline so a marker never joins it.
+func terminated(line string) string {
+	if strings.HasSuffix(line, "\n") {
+		return line
+	}
+	return line + "\n"
+}
+
+// fileMode keeps the mode of an existing file, such as an executable
+// script, and uses 0644 for new ones.
+func fileMode(path string) os.FileMode {
+	if info, err := os.Stat(path); err == nil {
+		return info.Mode().Perm()
+	}
+	return 0644
+}
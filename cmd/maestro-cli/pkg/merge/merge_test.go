@@ -0,0 +1,196 @@
+package merge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	os.MkdirAll(filepath.Join(dir, "scripts"), 0755)
+	write := func(name, content string) {
+		os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(content), 0644)
+	}
+	write("scripts/pristine.sh", "v1\n")
+	write("scripts/edited.sh", "mine\n")
+	write("scripts/both.sh", "a\nmine\nz\n")
+	write("scripts/unknown.sh", "local\n")
+	base := map[string]string{
+		"scripts/pristine.sh": Sum([]byte("v1\n")),
+		"scripts/edited.sh":   Sum([]byte("v1\n")),
+		"scripts/both.sh":     Sum([]byte("a\nv1\nz\n")),
+	}
+	incoming := map[string][]byte{
+		"scripts/pristine.sh": []byte("v2\n"),
+		"scripts/edited.sh":   []byte("v1\n"),
+		"scripts/both.sh":     []byte("a\nv2\nz\n"),
+		"scripts/unknown.sh":  []byte("upstream\n"),
+		"scripts/added.sh":    []byte("new\n"),
+	}
+
+	for _, tt := range []struct {
+		strategy Strategy
+		want     map[string]Action
+	}{
+		{Keep, map[string]Action{"scripts/both.sh": Kept, "scripts/unknown.sh": Kept}},
+		{WriteNew, map[string]Action{"scripts/both.sh": NewFile, "scripts/unknown.sh": NewFile}},
+		{Markers, map[string]Action{"scripts/both.sh": Conflicted, "scripts/unknown.sh": Conflicted}},
+	} {
+		results, err := Plan(dir, incoming, base, tt.strategy)
+		if err != nil {
+			t.Fatalf("Plan(%s) error: %v", tt.strategy, err)
+		}
+		want := map[string]Action{"scripts/added.sh": Created, "scripts/pristine.sh": Updated, "scripts/edited.sh": Kept}
+		for p, a := range tt.want {
+			want[p] = a
+		}
+		for _, r := range results {
+			if r.Action != want[r.Path] {
+				t.Errorf("Plan(%s) %s = %s, want %s", tt.strategy, r.Path, r.Action, want[r.Path])
+			}
+			if conflict := r.Path == "scripts/both.sh" || r.Path == "scripts/unknown.sh"; r.Conflict != conflict {
+				t.Errorf("Plan(%s) %s conflict = %v", tt.strategy, r.Path, r.Conflict)
+			}
+		}
+	}
+
+	if _, err := Apply(dir, incoming, base, Markers, "v2.0.0"); err != nil {
+		t.Fatalf("Apply() error: %v", err)
+	}
+	for name, want := range map[string]string{
+		"scripts/pristine.sh": "v2\n",
+		"scripts/edited.sh":   "mine\n",
+		"scripts/added.sh":    "new\n",
+		"scripts/both.sh":     "a\n<<<<<<< local\nmine\n=======\nv2\n>>>>>>> v2.0.0\nz\n",
+	} {
+		if data, _ := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name))); string(data) != want {
+			t.Errorf("%s = %q, want %q", name, data, want)
+		}
+	}
+
+	write("scripts/both.sh", "a\nmine\nz\n")
+	if _, err := Apply(dir, incoming, base, WriteNew, "v2.0.0"); err != nil {
+		t.Fatalf("Apply() error: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "scripts", "both.sh.new")); string(data) != "a\nv2\nz\n" {
+		t.Errorf("both.sh.new = %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "scripts", "both.sh")); string(data) != "a\nmine\nz\n" {
+		t.Errorf("both.sh should be kept with the new strategy, got %q", data)
+	}
+}
+
+func TestConflictMarkersWithoutTrailingNewline(t *testing.T) {
+	got := string(ConflictMarkers([]byte("same\nmine"), []byte("same\ntheirs\n"), "main"))
+	want := "same\n<<<<<<< local\nmine\n=======\ntheirs\n>>>>>>> main\n"
+	if got != want {
+		t.Errorf("ConflictMarkers() = %q, want %q", got, want)
+	}
+}
+
+func TestParseStrategy(t *testing.T) {
+	if s, err := ParseStrategy("Markers"); err != nil || s != Markers {
+		t.Errorf("ParseStrategy(Markers) = %v, %v", s, err)
+	}
+	if _, err := ParseStrategy("overwrite"); err == nil {
+		t.Error("an unknown strategy should be rejected")
+	}
+}
+
+func TestPlanRemovals(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	os.WriteFile(filepath.Join(dir, "dropped.md"), []byte("v1\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "edited.md"), []byte("mine\n"), 0644)
+	base := map[string]string{
+		"dropped.md": Sum([]byte("v1\n")),
+		"edited.md":  Sum([]byte("v1\n")),
+		"gone.md":    Sum([]byte("v1\n")),
+	}
+	results, err := Apply(dir, nil, base, WriteNew, "v2")
+	if err != nil {
+		t.Fatalf("Apply() error: %v", err)
+	}
+	want := []Result{
+		{Path: "dropped.md", Action: Removed, Removed: true},
+		{Path: "edited.md", Action: Kept, Conflict: true, Removed: true},
+	}
+	if len(results) != len(want) || results[0] != want[0] || results[1] != want[1] {
+		t.Errorf("Apply() = %+v, want %+v", results, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "dropped.md")); !os.IsNotExist(err) {
+		t.Error("an unedited file the new version dropped should be removed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "edited.md")); err != nil {
+		t.Error("an edited file should be kept")
+	}
+}
+
+func TestExecuteStaged(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	os.MkdirAll(filepath.Join(dir, ".maestro", "scripts"), 0755)
+	os.MkdirAll(filepath.Join(dir, ".maestro", "specs"), 0755)
+	os.WriteFile(filepath.Join(dir, ".maestro", "scripts", "run.sh"), []byte("v1\n"), 0755)
+	os.WriteFile(filepath.Join(dir, ".maestro", "scripts", "old.sh"), []byte("old\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".maestro", "specs", "spec.md"), []byte("mine\n"), 0644)
+	base := map[string]string{
+		".maestro/scripts/run.sh": Sum([]byte("v1\n")),
+		".maestro/scripts/old.sh": Sum([]byte("old\n")),
+	}
+	incoming := map[string][]byte{
+		".maestro/scripts/run.sh": []byte("v2\n"),
+		".maestro/scripts/new.sh": []byte("new\n"),
+	}
+	results, err := Plan(dir, incoming, base, Keep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := func(name string) string {
+		data, _ := os.ReadFile(filepath.Join(dir, ".maestro", "scripts", name))
+		return string(data)
+	}
+
+	if err := ExecuteStaged(dir, ".maestro", results, incoming, "v2", func(string) error { return os.ErrInvalid }); err == nil {
+		t.Fatal("ExecuteStaged() should fail when validation does")
+	}
+	if read("run.sh") != "v1\n" || read("old.sh") != "old\n" || read("new.sh") != "" {
+		t.Error("a failed ExecuteStaged() should leave the directory as it was")
+	}
+
+	var validated string
+	err = ExecuteStaged(dir, ".maestro", results, incoming, "v2", func(staged string) error {
+		validated = read("run.sh")
+		return os.WriteFile(filepath.Join(staged, "config.yaml"), []byte("recorded\n"), 0644)
+	})
+	if err != nil {
+		t.Fatalf("ExecuteStaged() error: %v", err)
+	}
+	if validated != "v1\n" {
+		t.Error("the directory should not change before validation passes")
+	}
+	if read("run.sh") != "v2\n" || read("new.sh") != "new\n" || read("old.sh") != "" {
+		t.Errorf("ExecuteStaged() did not apply the update: run.sh=%q new.sh=%q old.sh=%q", read("run.sh"), read("new.sh"), read("old.sh"))
+	}
+	if info, err := os.Stat(filepath.Join(dir, ".maestro", "scripts", "run.sh")); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("run.sh should stay executable, got %v", info)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, ".maestro", "specs", "spec.md")); string(data) != "mine\n" {
+		t.Error("files the update does not touch should be kept")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, ".maestro", "config.yaml")); string(data) != "recorded\n" {
+		t.Error("what validate writes to the staged copy should be swapped in")
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(dir, StagingPrefix+"*")); len(leftovers) > 0 {
+		t.Errorf("staging directories left behind: %v", leftovers)
+	}
+
+	if err := ExecuteStaged(dir, ".maestro", []Result{{Path: "outside.txt", Action: Created}}, nil, "v2", nil); err == nil {
+		t.Error("ExecuteStaged() should refuse results outside the directory")
+	}
+}
@@ -0,0 +1,112 @@
+// Package newline applies the project's line-ending policy to text files
+// written by maestro. Fetched and embedded assets always use LF; on Windows
+// that can break tooling and create noisy diffs under core.autocrlf.
+package newline
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Policy selects the line endings used for written text files.
+type Policy string
+
+const (
+	// Keep writes content exactly as fetched (the default).
+	Keep Policy = "keep"
+	// LF converts text files to LF line endings.
+	LF Policy = "lf"
+	// CRLF converts text files to CRLF line endings.
+	CRLF Policy = "crlf"
+	// Auto uses CRLF on Windows and LF elsewhere.
+	Auto Policy = "auto"
+)
+
+// binarySniffLen matches git's heuristic: a NUL byte in the first 8000 bytes
+// marks a file as binary.
+const binarySniffLen = 8000
+
+var (
+	mu      sync.RWMutex
+	current = Keep
+)
+
+// Parse validates a policy name from config. An empty string means Keep.
+func Parse(s string) (Policy, error) {
+	switch p := Policy(strings.ToLower(strings.TrimSpace(s))); p {
+	case "":
+		return Keep, nil
+	case Keep, LF, CRLF, Auto:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown newline policy %q (want keep, lf, crlf, or auto)", s)
+	}
+}
+
+// SetPolicy sets the policy used by Apply.
+func SetPolicy(p Policy) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = p
+}
+
+// CurrentPolicy returns the policy used by Apply, with Auto resolved for the
+// running OS.
+func CurrentPolicy() Policy {
+	mu.RLock()
+	defer mu.RUnlock()
+	if current == Auto {
+		if runtime.GOOS == "windows" {
+			return CRLF
+		}
+		return LF
+	}
+	return current
+}
+
+// Apply converts the line endings of a file about to be written to name
+// according to the current policy. Binary content is returned unchanged, and
+// shell scripts always use LF because bash cannot run CRLF scripts.
+func Apply(name string, data []byte) []byte {
+	policy := CurrentPolicy()
+	if policy == Keep || IsBinary(data) {
+		return data
+	}
+	if isShellScript(name) {
+		policy = LF
+	}
+	return Convert(data, policy)
+}
+
+// Convert rewrites every line ending in data to the given policy's style.
+func Convert(data []byte, policy Policy) []byte {
+	switch policy {
+	case LF:
+		return bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	case CRLF:
+		lf := bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+		return bytes.ReplaceAll(lf, []byte("\n"), []byte("\r\n"))
+	default:
+		return data
+	}
+}
+
+// IsBinary reports whether data looks like a binary file.
+func IsBinary(data []byte) bool {
+	if len(data) > binarySniffLen {
+		data = data[:binarySniffLen]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+func isShellScript(name string) bool {
+	switch path.Ext(strings.ReplaceAll(name, `\`, "/")) {
+	case ".sh", ".bash", ".zsh":
+		return true
+	}
+	return false
+}
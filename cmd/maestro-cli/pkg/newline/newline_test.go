@@ -0,0 +1,57 @@
+package newline
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for in, want := range map[string]Policy{"": Keep, "keep": Keep, "LF": LF, " crlf ": CRLF, "auto": Auto} {
+		got, err := Parse(in)
+		if err != nil || got != want {
+			t.Errorf("Parse(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := Parse("cr"); err == nil {
+		t.Error("Parse(cr) should fail")
+	}
+}
+
+func TestApply(t *testing.T) {
+	defer SetPolicy(Keep)
+
+	SetPolicy(CRLF)
+	if got := string(Apply("notes.md", []byte("a\nb\r\nc"))); got != "a\r\nb\r\nc" {
+		t.Errorf("CRLF text: got %q", got)
+	}
+	if got := string(Apply("scripts/run.sh", []byte("echo\r\nok\n"))); got != "echo\nok\n" {
+		t.Errorf("shell scripts should always use LF, got %q", got)
+	}
+	binary := []byte("\x89PNG\x00\n\x1a\n")
+	if got := Apply("logo.png", binary); string(got) != string(binary) {
+		t.Errorf("binary content should be unchanged, got %q", got)
+	}
+
+	SetPolicy(LF)
+	if got := string(Apply("notes.md", []byte("a\r\nb\r\n"))); got != "a\nb\n" {
+		t.Errorf("LF text: got %q", got)
+	}
+
+	SetPolicy(Keep)
+	if got := string(Apply("notes.md", []byte("a\r\nb\n"))); got != "a\r\nb\n" {
+		t.Errorf("keep should not change content, got %q", got)
+	}
+}
+
+func TestAutoResolvesPerOS(t *testing.T) {
+	defer SetPolicy(Keep)
+	SetPolicy(Auto)
+
+	want := LF
+	if runtime.GOOS == "windows" {
+		want = CRLF
+	}
+	if got := CurrentPolicy(); got != want {
+		t.Errorf("CurrentPolicy() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,290 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a parsed JSONPath template in kubectl's dialect: text with
+// expressions in braces, such as {.version} or
+// {range .checks[*]}{.name}{"\n"}{end}.
+type jsonPath struct {
+	nodes []pathNode
+}
+
+// pathNode is literal text, a path to print, or a range over a path.
+type pathNode struct {
+	text  string
+	path  *pathExpr
+	body  []pathNode
+	isRng bool
+}
+
+// pathExpr is a path such as .checks[0].name. root marks a path starting
+// with $, which inside a range still starts from the top.
+type pathExpr struct {
+	root  bool
+	steps []pathStep
+}
+
+// pathStep selects a field (key), an element (index), or every field or
+// element (all).
+type pathStep struct {
+	key   string
+	index int
+	isIdx bool
+	all   bool
+}
+
+func parseJSONPath(text string) (*jsonPath, error) {
+	nodes, _, err := parsePathNodes(text, false)
+	if err != nil {
+		return nil, fmt.Errorf("parsing jsonpath %q: %w", text, err)
+	}
+	return &jsonPath{nodes: nodes}, nil
+}
+
+// parsePathNodes parses text up to the {end} that closes a range, when
+// inRange, or to the end, and returns what follows the {end}.
+func parsePathNodes(text string, inRange bool) ([]pathNode, string, error) {
+	var nodes []pathNode
+	for text != "" {
+		open := strings.IndexByte(text, '{')
+		if open < 0 {
+			nodes = append(nodes, pathNode{text: text})
+			text = ""
+			break
+		}
+		if open > 0 {
+			nodes = append(nodes, pathNode{text: text[:open]})
+		}
+		expr, rest, err := cutExpression(text[open+1:])
+		if err != nil {
+			return nil, "", err
+		}
+		text = rest
+
+		switch {
+		case expr == "end":
+			if !inRange {
+				return nil, "", errors.New("{end} without {range}")
+			}
+			return nodes, text, nil
+		case strings.HasPrefix(expr, "range "):
+			path, err := parsePathExpr(strings.TrimSpace(strings.TrimPrefix(expr, "range ")))
+			if err != nil {
+				return nil, "", err
+			}
+			body, rest, err := parsePathNodes(text, true)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, pathNode{path: path, body: body, isRng: true})
+			text = rest
+		case strings.HasPrefix(expr, `"`):
+			s, err := strconv.Unquote(expr)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid string %s", expr)
+			}
+			nodes = append(nodes, pathNode{text: s})
+		default:
+			path, err := parsePathExpr(expr)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, pathNode{path: path})
+		}
+	}
+	if inRange {
+		return nil, "", errors.New("{range} without {end}")
+	}
+	return nodes, "", nil
+}
+
+// cutExpression returns the expression up to the closing brace, skipping
+// braces inside quoted strings, and the text after it.
+func cutExpression(text string) (string, string, error) {
+	quoted := false
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quoted && c == '\\':
+			i++
+		case c == '"':
+			quoted = !quoted
+		case c == '}' && !quoted:
+			return strings.TrimSpace(text[:i]), text[i+1:], nil
+		}
+	}
+	return "", "", errors.New("unclosed {")
+}
+
+// parsePathExpr parses a path: an optional $ or @, then .key, ['key'],
+// [index], [*], or .* steps.
+func parsePathExpr(text string) (*pathExpr, error) {
+	p := &pathExpr{}
+	rest := text
+	switch {
+	case strings.HasPrefix(rest, "$"):
+		p.root = true
+		rest = rest[1:]
+	case strings.HasPrefix(rest, "@"):
+		rest = rest[1:]
+	case rest != "" && rest[0] != '.' && rest[0] != '[':
+		// A bare key, as in {version}
+		rest = "." + rest
+	}
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			return nil, fmt.Errorf("recursive descent (..) is not supported in %q", text)
+		case strings.HasPrefix(rest, ".*"):
+			p.steps = append(p.steps, pathStep{all: true})
+			rest = rest[2:]
+		case rest[0] == '.':
+			end := 1
+			for end < len(rest) && rest[end] != '.' && rest[end] != '[' {
+				end++
+			}
+			if end == 1 {
+				if len(rest) == 1 {
+					// {.} is the current value
+					rest = ""
+					continue
+				}
+				return nil, fmt.Errorf("empty key in %q", text)
+			}
+			p.steps = append(p.steps, pathStep{key: rest[1:end]})
+			rest = rest[end:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in %q", text)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				p.steps = append(p.steps, pathStep{all: true})
+			case strings.HasPrefix(inner, "?"):
+				return nil, fmt.Errorf("filters ([?(...)]) are not supported in %q; use template= for conditions", text)
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				p.steps = append(p.steps, pathStep{key: inner[1 : len(inner)-1]})
+			default:
+				n, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index [%s] in %q", inner, text)
+				}
+				p.steps = append(p.steps, pathStep{index: n, isIdx: true})
+			}
+		default:
+			return nil, fmt.Errorf("unexpected %q in %q", rest, text)
+		}
+	}
+	return p, nil
+}
+
+func (j *jsonPath) execute(w io.Writer, data interface{}) error {
+	return executeNodes(w, j.nodes, data, data)
+}
+
+func executeNodes(w io.Writer, nodes []pathNode, root, current interface{}) error {
+	for _, n := range nodes {
+		switch {
+		case n.path == nil:
+			if _, err := io.WriteString(w, n.text); err != nil {
+				return err
+			}
+		case n.isRng:
+			items := n.path.eval(root, current)
+			if len(items) == 1 {
+				if list, ok := items[0].([]interface{}); ok {
+					items = list
+				}
+			}
+			for _, item := range items {
+				if err := executeNodes(w, n.body, root, item); err != nil {
+					return err
+				}
+			}
+		default:
+			values := n.path.eval(root, current)
+			words := make([]string, len(values))
+			for i, v := range values {
+				words[i] = formatValue(v)
+			}
+			if _, err := io.WriteString(w, strings.Join(words, " ")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// eval returns the values the path selects. Missing keys and indexes out
+// of range select nothing.
+func (p *pathExpr) eval(root, current interface{}) []interface{} {
+	values := []interface{}{current}
+	if p.root {
+		values = []interface{}{root}
+	}
+	for _, step := range p.steps {
+		var next []interface{}
+		for _, v := range values {
+			switch v := v.(type) {
+			case map[string]interface{}:
+				switch {
+				case step.all:
+					keys := make([]string, 0, len(v))
+					for k := range v {
+						keys = append(keys, k)
+					}
+					sort.Strings(keys)
+					for _, k := range keys {
+						next = append(next, v[k])
+					}
+				case !step.isIdx:
+					if field, ok := v[step.key]; ok {
+						next = append(next, field)
+					}
+				}
+			case []interface{}:
+				switch {
+				case step.all:
+					next = append(next, v...)
+				case step.isIdx:
+					i := step.index
+					if i < 0 {
+						i += len(v)
+					}
+					if i >= 0 && i < len(v) {
+						next = append(next, v[i])
+					}
+				}
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+// formatValue prints strings, numbers, and booleans as they are, null as
+// nothing, and objects and arrays as compact JSON.
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
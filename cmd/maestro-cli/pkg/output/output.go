@@ -0,0 +1,130 @@
+// Package output writes the machine-readable output of maestro commands:
+// JSON, or a single part of it extracted with a Go template or a JSONPath
+// expression, as kubectl's -o template= and -o jsonpath= do, so scripts can
+// read one field without jq.
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// Prefixes of the formats that extract from the JSON output.
+const (
+	TemplatePrefix = "template="
+	JSONPathPrefix = "jsonpath="
+)
+
+// Validate checks that format is one of plain, or, when plain includes
+// json, a template= or jsonpath= format whose expression parses.
+func Validate(format string, plain ...string) error {
+	for _, p := range plain {
+		if format == p {
+			return nil
+		}
+	}
+	want := strings.Join(plain, ", ")
+	if !contains(plain, "json") {
+		return fmt.Errorf("unknown output format %q (want %s)", format, want)
+	}
+	switch {
+	case strings.HasPrefix(format, TemplatePrefix):
+		_, err := parseTemplate(strings.TrimPrefix(format, TemplatePrefix))
+		return err
+	case strings.HasPrefix(format, JSONPathPrefix):
+		_, err := parseJSONPath(strings.TrimPrefix(format, JSONPathPrefix))
+		return err
+	}
+	return fmt.Errorf("unknown output format %q (want %s, template=<go template>, or jsonpath=<expression>)", format, want)
+}
+
+// Data reports whether format writes the JSON output or a part of it, so
+// stdout must carry nothing else.
+func Data(format string) bool {
+	return format == "json" || strings.HasPrefix(format, TemplatePrefix) || strings.HasPrefix(format, JSONPathPrefix)
+}
+
+// Write writes v as indented JSON, or, for template= and jsonpath=, what
+// the expression extracts from that JSON, ending with a newline. Field
+// names are the JSON keys, e.g. template={{.version}}.
+func Write(w io.Writer, format string, v interface{}) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	data, err := jsonValue(v)
+	if err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	switch {
+	case strings.HasPrefix(format, TemplatePrefix):
+		tmpl, err := parseTemplate(strings.TrimPrefix(format, TemplatePrefix))
+		if err != nil {
+			return err
+		}
+		if err := tmpl.Execute(&out, data); err != nil {
+			return fmt.Errorf("executing template: %w", err)
+		}
+	case strings.HasPrefix(format, JSONPathPrefix):
+		path, err := parseJSONPath(strings.TrimPrefix(format, JSONPathPrefix))
+		if err != nil {
+			return err
+		}
+		if err := path.execute(&out, data); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+	if out.Len() > 0 && !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+		out.WriteByte('\n')
+	}
+	_, err = w.Write(out.Bytes())
+	return err
+}
+
+// jsonValue round-trips v through JSON, so templates and paths see the
+// JSON keys and values rather than Go fields.
+func jsonValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encoding output: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// Numbers print as they are in the JSON, not as floats
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, fmt.Errorf("encoding output: %w", err)
+	}
+	return value, nil
+}
+
+func parseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %w", err)
+	}
+	return tmpl, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type checkReport struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+var report = struct {
+	OK      bool          `json:"ok"`
+	Version string        `json:"version"`
+	Count   int           `json:"count"`
+	Checks  []checkReport `json:"checks"`
+	Labels  map[string]string
+}{
+	OK:      false,
+	Version: "v1.2.0",
+	Count:   3,
+	Checks:  []checkReport{{"config", "pass"}, {"scripts", "warn"}, {"bd", "fail"}},
+	Labels:  map[string]string{"b": "2", "a": "1"},
+}
+
+func TestWrite(t *testing.T) {
+	for _, tt := range []struct {
+		format, want string
+	}{
+		{"template={{.version}}", "v1.2.0\n"},
+		{"template={{range .checks}}{{.name}}={{.status}}\n{{end}}", "config=pass\nscripts=warn\nbd=fail\n"},
+		{"template={{json .Labels}}", `{"a":"1","b":"2"}` + "\n"},
+		{"jsonpath={.version}", "v1.2.0\n"},
+		{"jsonpath={.count} {.ok}", "3 false\n"},
+		{"jsonpath={.checks[*].name}", "config scripts bd\n"},
+		{"jsonpath={.checks[-1].status}", "fail\n"},
+		{"jsonpath={.checks[1]}", `{"name":"scripts","status":"warn"}` + "\n"},
+		{"jsonpath={$.Labels['a']}", "1\n"},
+		{"jsonpath={.Labels.*}", "1 2\n"},
+		{`jsonpath={range .checks[*]}{.name}{"\t"}{$.version}{"\n"}{end}`, "config\tv1.2.0\nscripts\tv1.2.0\nbd\tv1.2.0\n"},
+		{"jsonpath={.missing}", ""},
+	} {
+		if err := Validate(tt.format, "text", "json"); err != nil {
+			t.Errorf("Validate(%q) = %v", tt.format, err)
+			continue
+		}
+		var out bytes.Buffer
+		if err := Write(&out, tt.format, report); err != nil {
+			t.Errorf("Write(%q) = %v", tt.format, err)
+			continue
+		}
+		if out.String() != tt.want {
+			t.Errorf("Write(%q) = %q, want %q", tt.format, out.String(), tt.want)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := Write(&out, "json", report); err != nil || !strings.HasPrefix(out.String(), "{\n  \"ok\": false,") {
+		t.Errorf("Write(json) = %q, %v", out.String(), err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for format, want := range map[string]string{
+		"yaml":                     `unknown output format "yaml"`,
+		"template={{.version":      "parsing template",
+		"jsonpath={.version":       "unclosed {",
+		"jsonpath={range .checks}": "{range} without {end}",
+		"jsonpath={end}":           "{end} without {range}",
+		"jsonpath={..name}":        "recursive descent",
+		"jsonpath={.a[?(@.b)]}":    "filters",
+	} {
+		if err := Validate(format, "text", "json"); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate(%q) = %v, want an error containing %q", format, err, want)
+		}
+	}
+	if err := Validate("jsonpath={.a}", "text"); err == nil {
+		t.Error("jsonpath should need the json format")
+	}
+	if !Data("json") || !Data("jsonpath={.a}") || Data("text") {
+		t.Error("Data should report the JSON formats")
+	}
+}
@@ -0,0 +1,139 @@
+// Package pathfmt formats the file paths maestro prints the same way in
+// every command: relative to the project root, with forward slashes, and as
+// OSC 8 hyperlinks when the terminal supports them, so a path in a doctor
+// fix or a failed check can be clicked open.
+package pathfmt
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	mu         sync.RWMutex
+	hyperlinks bool
+	root       = "."
+)
+
+// SetHyperlinks turns hyperlinks on or off.
+func SetHyperlinks(on bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	hyperlinks = on
+}
+
+// Hyperlinks reports whether paths are printed as hyperlinks.
+func Hyperlinks() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return hyperlinks
+}
+
+// SetRoot sets the project root paths are printed relative to. Until it is
+// set, that is the working directory.
+func SetRoot(dir string) {
+	mu.Lock()
+	defer mu.Unlock()
+	root = dir
+}
+
+func projectRoot() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return root
+}
+
+// Rel returns p relative to the project root, with forward slashes.
+// Paths outside the project stay absolute. A trailing slash, which marks a
+// directory, is kept.
+func Rel(p string) string {
+	if p == "" {
+		return p
+	}
+	dir := strings.HasSuffix(p, "/") || strings.HasSuffix(p, string(filepath.Separator))
+	rel := filepath.Clean(p)
+	if filepath.IsAbs(rel) {
+		if root, err := filepath.Abs(projectRoot()); err == nil {
+			if r, err := filepath.Rel(root, rel); err == nil && r != ".." && !strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+				rel = r
+			}
+		}
+	}
+	rel = filepath.ToSlash(rel)
+	if dir && rel != "/" {
+		rel += "/"
+	}
+	return rel
+}
+
+// Link wraps text in an OSC 8 hyperlink to the file at p, absolute or
+// relative to the project root, when hyperlinks are on, and returns text as
+// is otherwise.
+func Link(text, p string) string {
+	if !Hyperlinks() || p == "" {
+		return text
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(projectRoot(), p)
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return text
+	}
+	host, _ := os.Hostname()
+	slashed := filepath.ToSlash(abs)
+	if !strings.HasPrefix(slashed, "/") {
+		slashed = "/" + slashed // a Windows drive letter
+	}
+	u := url.URL{Scheme: "file", Host: host, Path: slashed}
+	return "\x1b]8;;" + u.String() + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+// Path returns p relative to the project root, as a hyperlink when they
+// are on.
+func Path(p string) string {
+	return Link(Rel(p), p)
+}
+
+// Pad returns Path(p) padded with spaces to width visible characters, for
+// columns that a hyperlink's escape sequences would otherwise misalign.
+func Pad(p string, width int) string {
+	rel := Rel(p)
+	if n := len([]rune(rel)); n < width {
+		return Link(rel, p) + strings.Repeat(" ", width-n)
+	}
+	return Link(rel, p)
+}
+
+// Detect reports whether the terminal described by the environment renders
+// OSC 8 hyperlinks. FORCE_HYPERLINK=1 or 0 overrides the guess.
+func Detect(getenv func(string) string) bool {
+	if force := getenv("FORCE_HYPERLINK"); force != "" {
+		on, err := strconv.ParseBool(force)
+		return err == nil && on
+	}
+	if getenv("TERM") == "dumb" || getenv("CI") != "" {
+		return false
+	}
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty", "Hyper", "Tabby", "rio":
+		return true
+	}
+	if v, err := strconv.Atoi(getenv("VTE_VERSION")); err == nil && v >= 5000 {
+		return true
+	}
+	for _, name := range []string{"WT_SESSION", "KITTY_WINDOW_ID", "KONSOLE_VERSION", "DOMTERM", "ALACRITTY_WINDOW_ID"} {
+		if getenv(name) != "" {
+			return true
+		}
+	}
+	switch getenv("TERM") {
+	case "xterm-kitty", "alacritty", "foot", "xterm-ghostty", "wezterm":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package pathfmt
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRel(t *testing.T) {
+	root := t.TempDir()
+	SetRoot(root)
+	defer SetRoot(".")
+
+	for _, tt := range []struct{ in, want string }{
+		{filepath.Join(root, ".maestro", "config.yaml"), ".maestro/config.yaml"},
+		{filepath.Join(".maestro", "scripts") + string(filepath.Separator), ".maestro/scripts/"},
+		{"./specs/../specs/001-a", "specs/001-a"},
+		{root, "."},
+		{filepath.Dir(root), filepath.ToSlash(filepath.Dir(root))},
+	} {
+		if got := Rel(tt.in); got != tt.want {
+			t.Errorf("Rel(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLink(t *testing.T) {
+	defer SetHyperlinks(false)
+	if got := Path(".maestro/config.yaml"); got != ".maestro/config.yaml" {
+		t.Errorf("Path() without hyperlinks = %q", got)
+	}
+
+	SetHyperlinks(true)
+	got := Pad(".maestro/config.yaml", 24)
+	if !strings.HasPrefix(got, "\x1b]8;;file://") || !strings.Contains(got, "/.maestro/config.yaml\x1b\\.maestro/config.yaml\x1b]8;;\x1b\\") {
+		t.Errorf("Pad() = %q, want a hyperlink to the file", got)
+	}
+	if !strings.HasSuffix(got, "\x1b\\    ") {
+		t.Errorf("Pad() = %q, want padding to the visible width after the link", got)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	for _, tt := range []struct {
+		env  map[string]string
+		want bool
+	}{
+		{map[string]string{"TERM_PROGRAM": "vscode"}, true},
+		{map[string]string{"VTE_VERSION": "7006"}, true},
+		{map[string]string{"VTE_VERSION": "4000"}, false},
+		{map[string]string{"TERM": "xterm-256color"}, false},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app", "CI": "true"}, false},
+		{map[string]string{"TERM": "dumb", "FORCE_HYPERLINK": "1"}, true},
+		{map[string]string{"WT_SESSION": "x", "FORCE_HYPERLINK": "0"}, false},
+	} {
+		getenv := func(name string) string { return tt.env[name] }
+		if got := Detect(getenv); got != tt.want {
+			t.Errorf("Detect(%v) = %v, want %v", tt.env, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Package prereq checks that the pipeline stages before a stage are
+// complete for a feature. It is the native form of check-prerequisites.sh
+// and gives the same results, so the check runs without bash or python3.
+package prereq
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spec-maestro/maestro-cli/pkg/state"
+)
+
+// Result is the outcome of a check, in check-prerequisites.sh's JSON:
+// {"ok":true}, or {"ok":false,"error":"...","suggestion":"..."}.
+type Result struct {
+	OK         bool   `json:"ok"`
+	Error      string `json:"error,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// JSON returns the result as check-prerequisites.sh prints it.
+func (r Result) JSON() string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(r)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func fail(err, suggestion string) Result {
+	return Result{Error: err, Suggestion: suggestion}
+}
+
+// Stages are the stages Check knows, in pipeline order.
+var Stages = []string{"clarify", "research", "plan", "tasks", "implement", "review", "pm-validate"}
+
+// ResearchArtifacts are the files /maestro.research writes, all of which
+// must exist before research counts as ready.
+var ResearchArtifacts = []string{
+	"technology-options.md",
+	"pattern-catalog.md",
+	"pitfall-register.md",
+	"competitive-analysis.md",
+	"synthesis.md",
+}
+
+// BypassPhrase is what the user types for /maestro.plan to go ahead while
+// research is not ready. The plan command must contain it verbatim.
+const BypassPhrase = "I acknowledge proceeding without complete research"
+
+// lookPath finds bd; tests replace it.
+var lookPath = exec.LookPath
+
+// Check checks that featureDir is ready for stage. State files are read
+// from base/.maestro/state, and relative paths in them are resolved from
+// base, the main repository when working in a git worktree. Relative
+// featureDir and base are resolved from root, the project; messages name
+// featureDir as given.
+func Check(root, stage, featureDir, base string) Result {
+	spec := featureDir + "/spec.md"
+	featureDir, base = resolve(root, featureDir), resolve(root, base)
+	info, err := os.Stat(filepath.Join(featureDir, "spec.md"))
+	switch {
+	case err != nil || info.IsDir():
+		return fail(spec+" not found", "Check that the feature directory path is correct")
+	case info.Size() == 0:
+		return fail(spec+" is empty or missing required H1", "The spec file exists but is empty — run /maestro.specify to initialize it")
+	}
+	data, err := os.ReadFile(filepath.Join(featureDir, "spec.md"))
+	if err != nil || !hasH1(string(data)) {
+		return fail(spec+" is missing required H1 heading", "The spec file has no H1 (# ...) heading — it may be a placeholder. Run /maestro.specify to initialize it properly")
+	}
+
+	statePath := filepath.Join(base, ".maestro", "state", filepath.Base(featureDir)+".json")
+	switch stage {
+	case "clarify":
+	case "plan":
+		return researchReadiness(statePath, base)
+	case "research":
+		if !isFile(statePath) {
+			return fail("Feature state not found", "Run the previous pipeline stage first")
+		}
+	case "tasks":
+		if !isFile(filepath.Join(featureDir, "plan.md")) {
+			return fail("Implementation plan not found", "Run the previous pipeline stage first")
+		}
+	case "implement", "review", "pm-validate":
+		if _, err := lookPath("bd"); err != nil {
+			return fail("bd CLI not found", "Install bd from https://github.com/anomalyco/beads")
+		}
+	default:
+		return fail("Unknown stage: "+stage, "Valid stages: clarify, research, plan, tasks, implement")
+	}
+	return Result{OK: true}
+}
+
+func hasH1(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, "# ") {
+			return true
+		}
+	}
+	return false
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// researchReadiness checks, before planning, that research the state marks
+// ready has all its artifacts. A missing state, or a legacy one without
+// research fields, passes, and so does research not marked ready: the plan
+// command then asks for BypassPhrase. pkg/state reads the research fields,
+// from the research object older states kept them in too.
+func researchReadiness(statePath, base string) Result {
+	s, err := state.Load(statePath)
+	var pathErr *os.PathError
+	switch {
+	case errors.As(err, &pathErr):
+		return Result{OK: true}
+	case err != nil:
+		return fail("State file is not valid JSON", "Fix the state file JSON or regenerate it with the previous stage command")
+	case !s.HasResearch() || s.ResearchReady == nil || !*s.ResearchReady:
+		return Result{OK: true}
+	}
+
+	if s.ResearchPath == "" {
+		return fail("Research is marked ready but research_path is missing", "Run /maestro.research to regenerate research metadata or set research_ready=false before planning")
+	}
+	if !isDir(resolve(base, s.ResearchPath)) {
+		return fail("Research is marked ready but research directory is missing", "Run /maestro.research to regenerate missing artifacts and metadata")
+	}
+	if len(s.ResearchArtifacts) == 0 {
+		return fail("Research is marked ready but research_artifacts is missing", "Run /maestro.research to regenerate artifact metadata")
+	}
+
+	listed := map[string]bool{}
+	var missingListed []string
+	for _, artifact := range s.ResearchArtifacts {
+		listed[filepath.Base(artifact)] = true
+		if !isFile(resolve(base, artifact)) {
+			missingListed = append(missingListed, artifact)
+		}
+	}
+	var missingRequired []string
+	for _, name := range ResearchArtifacts {
+		if !listed[name] || !isFile(filepath.Join(resolve(base, s.ResearchPath), name)) {
+			missingRequired = append(missingRequired, name)
+		}
+	}
+	if len(missingRequired) > 0 {
+		return fail("Research is marked ready but required artifacts are missing: "+strings.Join(missingRequired, ", "), "Run /maestro.research to regenerate missing artifacts or set research_ready=false to use the planning bypass")
+	}
+	if len(missingListed) > 0 {
+		return fail("Research is marked ready but listed artifacts are missing: "+strings.Join(missingListed, ", "), "Run /maestro.research to regenerate artifact files and metadata")
+	}
+	return Result{OK: true}
+}
+
+func resolve(base, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(base, filepath.FromSlash(path))
+}
+
+// Usage is the error for a missing feature directory, as
+// check-prerequisites.sh reports it.
+func Usage() Result {
+	return fail("feature directory required: pass an explicit <feature_dir>", "Pass the full feature directory path, e.g.: maestro check tasks .maestro/specs/070-improve-maestro-tasks-command-speed")
+}
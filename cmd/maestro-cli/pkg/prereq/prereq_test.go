@@ -0,0 +1,86 @@
+package prereq
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spec-maestro/maestro-cli/pkg/embedded"
+)
+
+func TestCheck(t *testing.T) {
+	base := t.TempDir()
+	featureDir := filepath.Join(base, ".maestro", "specs", "001-auth")
+	research := filepath.Join(featureDir, "research")
+	os.MkdirAll(research, 0755)
+	os.MkdirAll(filepath.Join(base, ".maestro", "state"), 0755)
+	os.WriteFile(filepath.Join(featureDir, "spec.md"), []byte("# Auth\n"), 0644)
+	for _, name := range ResearchArtifacts[:4] {
+		os.WriteFile(filepath.Join(research, name), []byte("notes\n"), 0644)
+	}
+	statePath := filepath.Join(base, ".maestro", "state", "001-auth.json")
+	artifacts := `[".maestro/specs/001-auth/research/technology-options.md", ".maestro/specs/001-auth/research/pattern-catalog.md", ".maestro/specs/001-auth/research/pitfall-register.md", ".maestro/specs/001-auth/research/competitive-analysis.md", ".maestro/specs/001-auth/research/synthesis.md"]`
+
+	lookPath = func(string) (string, error) { return "", errors.New("not found") }
+	defer func() { lookPath = exec.LookPath }()
+
+	tests := []struct {
+		name, stage, state string
+		// want is "" for ok, or part of the error
+		want string
+	}{
+		{"clarify needs only the spec", "clarify", "", ""},
+		{"research needs the state", "research", "", "Feature state not found"},
+		{"plan without a state", "plan", "", ""},
+		{"plan with a legacy state", "plan", `{"stage":"specify"}`, ""},
+		{"plan with research not ready", "plan", `{"research_ready":false}`, ""},
+		{"plan with ready research missing a file", "plan", `{"research_ready":true,"research_path":".maestro/specs/001-auth/research","research_artifacts":` + artifacts + `}`, "required artifacts are missing: synthesis.md"},
+		{"plan with ready research in the research object", "plan", `{"research":{"ready":"True","path":".maestro/specs/001-auth/research"}}`, "research_artifacts is missing"},
+		{"plan with ready research and no path", "plan", `{"research_ready":true}`, "research_path is missing"},
+		{"plan with an invalid state", "plan", `{`, "State file is not valid JSON"},
+		{"tasks needs the plan", "tasks", "", "Implementation plan not found"},
+		{"implement needs bd", "implement", "", "bd CLI not found"},
+		{"unknown stage", "deploy", "", "Unknown stage: deploy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(statePath)
+			if tt.state != "" {
+				os.WriteFile(statePath, []byte(tt.state), 0644)
+			}
+			result := Check(base, tt.stage, ".maestro/specs/001-auth", ".")
+			if tt.want == "" && !result.OK {
+				t.Errorf("Check() = %s, want ok", result.JSON())
+			}
+			if tt.want != "" && (result.OK || !strings.Contains(result.Error, tt.want)) {
+				t.Errorf("Check() = %s, want an error with %q", result.JSON(), tt.want)
+			}
+		})
+	}
+
+	os.WriteFile(filepath.Join(research, "synthesis.md"), []byte("summary\n"), 0644)
+	os.WriteFile(statePath, []byte(`{"research_ready":"true","research_path":".maestro/specs/001-auth/research","research_artifacts":`+artifacts+`}`), 0644)
+	if result := Check(base, "plan", featureDir, base); result.JSON() != `{"ok":true}` {
+		t.Errorf("Check() with complete research = %s", result.JSON())
+	}
+
+	os.WriteFile(filepath.Join(featureDir, "spec.md"), []byte("placeholder\n"), 0644)
+	if result := Check(base, "clarify", featureDir, base); !strings.Contains(result.Error, "missing required H1 heading") {
+		t.Errorf("Check() with a spec without a heading = %s", result.JSON())
+	}
+}
+
+// TestPlanCommandHasBypassPhrase tests the plan command asks for the exact
+// phrase that lets planning go ahead without ready research.
+func TestPlanCommandHasBypassPhrase(t *testing.T) {
+	content, err := embedded.FetchFile(".maestro/commands/maestro.plan.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), BypassPhrase) {
+		t.Errorf("maestro.plan.md must contain %q", BypassPhrase)
+	}
+}
@@ -0,0 +1,41 @@
+// Package project locates the root of a maestro project: the directory
+// holding .maestro/. Like git looking for .git/, the search walks up from a
+// starting directory, so commands work from anywhere inside the project.
+package project
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// DirName is the directory that marks a project root.
+const DirName = ".maestro"
+
+// ErrNotFound is returned by Find when no enclosing project exists.
+var ErrNotFound = errors.New("not inside a maestro project (no .maestro/ found)")
+
+// Find walks up from start to the nearest directory containing .maestro/
+// and returns it as an absolute path. The search stops at the root of the
+// enclosing git repository, so an unrelated project further up is never
+// picked.
+func Find(start string) (string, error) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if info, err := os.Stat(filepath.Join(dir, DirName)); err == nil && info.IsDir() {
+			return dir, nil
+		}
+		// .git is a file in worktrees and submodules
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return "", ErrNotFound
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ErrNotFound
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,39 @@
+package project
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindWalksUpToNearestProject(t *testing.T) {
+	dir, _ := filepath.EvalSymlinks(t.TempDir())
+	root := filepath.Join(dir, "services", "api")
+	nested := filepath.Join(root, "src", "handlers")
+	if err := os.MkdirAll(filepath.Join(root, DirName), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, start := range []string{root, nested} {
+		got, err := Find(start)
+		if err != nil || got != root {
+			t.Errorf("Find(%s) = %q, %v; want %q", start, got, err, root)
+		}
+	}
+}
+
+func TestFindStopsAtGitRoot(t *testing.T) {
+	dir, _ := filepath.EvalSymlinks(t.TempDir())
+	repo := filepath.Join(dir, "repo")
+	_ = os.MkdirAll(filepath.Join(dir, DirName), 0755)
+	_ = os.MkdirAll(filepath.Join(repo, "pkg"), 0755)
+	_ = os.WriteFile(filepath.Join(repo, ".git"), []byte("gitdir: elsewhere\n"), 0644)
+
+	if got, err := Find(filepath.Join(repo, "pkg")); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Find() = %q, %v; want ErrNotFound", got, err)
+	}
+}
@@ -0,0 +1,225 @@
+// Package report collects what a multi-step command did — each step's
+// outcome, warnings, and follow-up actions — and renders it as one final
+// summary, either as a table or as JSON.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+)
+
+// Status is the outcome of a step.
+type Status string
+
+const (
+	StatusOK      Status = "ok"
+	StatusSkipped Status = "skipped"
+	StatusWarning Status = "warning"
+	StatusFailed  Status = "failed"
+)
+
+func statusSymbol(s Status) string {
+	switch s {
+	case StatusOK:
+		return glyph.OK()
+	case StatusWarning:
+		return glyph.Warn()
+	case StatusFailed:
+		return glyph.Fail()
+	default:
+		return "-"
+	}
+}
+
+// Step is one step of an operation. An optional step may fail without
+// failing the operation.
+type Step struct {
+	Name     string `json:"name"`
+	Status   Status `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// Counts tallies steps by outcome. Failed includes OptionalFailed.
+type Counts struct {
+	Attempted      int `json:"attempted"`
+	Succeeded      int `json:"succeeded"`
+	Skipped        int `json:"skipped"`
+	Warnings       int `json:"warnings"`
+	Failed         int `json:"failed"`
+	OptionalFailed int `json:"optional_failed"`
+}
+
+// Operation is the record of one command run.
+type Operation struct {
+	Name      string   `json:"operation"`
+	Steps     []Step   `json:"steps"`
+	Warnings  []string `json:"warnings"`
+	FollowUps []string `json:"follow_ups"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// New starts recording an operation.
+func New(name string) *Operation {
+	return &Operation{Name: name, Steps: []Step{}, Warnings: []string{}, FollowUps: []string{}}
+}
+
+// OK records a step that succeeded.
+func (o *Operation) OK(step, detail string) {
+	o.Steps = append(o.Steps, Step{Name: step, Status: StatusOK, Detail: detail})
+}
+
+// Skip records a step that was not needed or not chosen.
+func (o *Operation) Skip(step, reason string) {
+	o.Steps = append(o.Steps, Step{Name: step, Status: StatusSkipped, Detail: reason})
+}
+
+// Warn records a step that completed with a problem worth reporting. The
+// detail is also listed among the warnings.
+func (o *Operation) Warn(step, detail string) {
+	o.Steps = append(o.Steps, Step{Name: step, Status: StatusWarning, Detail: detail})
+	o.Warning(step + ": " + detail)
+}
+
+// Fail records a step that failed and returns err, so callers can record
+// and return the failure in one statement.
+func (o *Operation) Fail(step string, err error) error {
+	o.Steps = append(o.Steps, Step{Name: step, Status: StatusFailed, Detail: err.Error()})
+	return err
+}
+
+// FailOptional records an optional step that failed while the operation
+// went on. The failure is listed among the warnings, and retry, when set,
+// among the next steps.
+func (o *Operation) FailOptional(step string, err error, retry string) {
+	o.Steps = append(o.Steps, Step{Name: step, Status: StatusFailed, Detail: err.Error(), Optional: true})
+	o.Warning("%s (optional) failed: %v", step, err)
+	if retry != "" {
+		o.FollowUp("%s", retry)
+	}
+}
+
+// Warning records a warning not tied to a single step.
+func (o *Operation) Warning(format string, args ...interface{}) {
+	o.Warnings = append(o.Warnings, fmt.Sprintf(format, args...))
+}
+
+// FollowUp records something the user should do next.
+func (o *Operation) FollowUp(format string, args ...interface{}) {
+	o.FollowUps = append(o.FollowUps, fmt.Sprintf(format, args...))
+}
+
+// Finish records the error the operation ended with, if any.
+func (o *Operation) Finish(err error) {
+	if err != nil {
+		o.Error = err.Error()
+	}
+}
+
+// Counts tallies the recorded steps. Skipped steps are not attempted.
+func (o *Operation) Counts() Counts {
+	var c Counts
+	for _, step := range o.Steps {
+		switch step.Status {
+		case StatusOK:
+			c.Succeeded++
+		case StatusSkipped:
+			c.Skipped++
+		case StatusWarning:
+			c.Succeeded++
+			c.Warnings++
+		case StatusFailed:
+			c.Failed++
+			if step.Optional {
+				c.OptionalFailed++
+			}
+		}
+	}
+	c.Attempted = len(o.Steps) - c.Skipped
+	return c
+}
+
+// WriteText renders the summary table. In accessible mode each step is a
+// line of its own instead, as screen readers read tables poorly.
+func (o *Operation) WriteText(w io.Writer) error {
+	c := o.Counts()
+	fmt.Fprintf(w, "\n%s summary\n", capitalize(o.Name))
+
+	if glyph.Accessible() {
+		o.writeStepLines(w)
+	} else if err := o.writeStepTable(w); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "  %d attempted, %d succeeded, %d skipped, %d failed, %d warning(s)\n",
+		c.Attempted, c.Succeeded, c.Skipped, c.Failed, len(o.Warnings))
+	if len(o.Warnings) > 0 {
+		fmt.Fprintln(w, "Warnings:")
+		for _, warning := range o.Warnings {
+			fmt.Fprintf(w, "  %s %s\n", glyph.Warn(), warning)
+		}
+	}
+	if len(o.FollowUps) > 0 {
+		fmt.Fprintln(w, "Next steps:")
+		for _, followUp := range o.FollowUps {
+			fmt.Fprintf(w, "  %s %s\n", glyph.Arrow(), followUp)
+		}
+	}
+	return nil
+}
+
+func (o *Operation) writeStepTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  STEP\tSTATUS\tDETAIL")
+	for _, step := range o.Steps {
+		status := string(step.Status)
+		if step.Optional {
+			status += " (optional)"
+		}
+		fmt.Fprintf(tw, "  %s\t%s %s\t%s\n", step.Name, statusSymbol(step.Status), status, step.Detail)
+	}
+	return tw.Flush()
+}
+
+// writeStepLines renders each step as "name: status, detail".
+func (o *Operation) writeStepLines(w io.Writer) {
+	for _, step := range o.Steps {
+		status := string(step.Status)
+		if step.Optional {
+			status += " (optional)"
+		}
+		line := fmt.Sprintf("  %s: %s", step.Name, status)
+		if step.Detail != "" {
+			line += ", " + step.Detail
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+// WriteJSON renders the summary as a JSON object with the same content as
+// the table, plus the counts.
+func (o *Operation) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(o.Summary())
+}
+
+// Summary returns the value WriteJSON encodes.
+func (o *Operation) Summary() interface{} {
+	return struct {
+		*Operation
+		Counts Counts `json:"counts"`
+	}{o, o.Counts()}
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,132 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spec-maestro/maestro-cli/pkg/glyph"
+)
+
+func sampleOperation() *Operation {
+	op := New("update")
+	op.OK("check for updates", "v1.0.0 → v1.1.0")
+	op.Warn("assets", "no release asset for linux/arm64")
+	op.Skip("install new agent configs", "none selected")
+	op.Fail("refresh agent configs", errors.New("rate limited"))
+	op.FollowUp("Run 'maestro doctor' to validate the setup")
+	op.Finish(errors.New("updating agent configs: rate limited"))
+	return op
+}
+
+func TestCounts(t *testing.T) {
+	got := sampleOperation().Counts()
+	want := Counts{Attempted: 3, Succeeded: 2, Skipped: 1, Warnings: 1, Failed: 1}
+	if got != want {
+		t.Errorf("Counts() = %+v, want %+v", got, want)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sampleOperation().WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Update summary",
+		"STEP",
+		"✓ ok",
+		"⚠ warning",
+		"- skipped",
+		"✗ failed",
+		"3 attempted, 2 succeeded, 1 skipped, 1 failed, 1 warning(s)",
+		"⚠ assets: no release asset for linux/arm64",
+		"→ Run 'maestro doctor' to validate the setup",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("text summary missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteTextAccessible(t *testing.T) {
+	glyph.SetAccessible(true)
+	defer glyph.SetAccessible(false)
+
+	var buf bytes.Buffer
+	if err := sampleOperation().WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.ContainsAny(out, "✓⚠✗") || strings.Contains(out, "STEP") {
+		t.Errorf("accessible summary should have no symbols or table:\n%s", out)
+	}
+	for _, want := range []string{
+		"  assets: warning, no release asset for linux/arm64\n",
+		"  install new agent configs: skipped, none selected\n",
+		"WARN assets: no release asset for linux/arm64",
+		"-> Run 'maestro doctor' to validate the setup",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("accessible summary missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sampleOperation().WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Operation string   `json:"operation"`
+		Steps     []Step   `json:"steps"`
+		Warnings  []string `json:"warnings"`
+		FollowUps []string `json:"follow_ups"`
+		Error     string   `json:"error"`
+		Counts    Counts   `json:"counts"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if got.Operation != "update" || len(got.Steps) != 4 || got.Steps[3].Status != StatusFailed {
+		t.Errorf("unexpected steps: %+v", got)
+	}
+	if got.Counts.Failed != 1 || len(got.Warnings) != 1 || len(got.FollowUps) != 1 || got.Error == "" {
+		t.Errorf("unexpected summary: %+v", got)
+	}
+}
+
+func TestEmptyOperationEncodesEmptyLists(t *testing.T) {
+	var buf bytes.Buffer
+	New("init").WriteJSON(&buf)
+	if !strings.Contains(buf.String(), `"steps": []`) || !strings.Contains(buf.String(), `"warnings": []`) {
+		t.Errorf("lists should encode as [] not null:\n%s", buf.String())
+	}
+}
+
+func TestFailOptional(t *testing.T) {
+	op := New("update")
+	op.OK("assets", "v1.1.0")
+	op.FailOptional("refresh .claude", errors.New("rate limited"), "Retry with 'maestro init --with-claude'")
+
+	if got, want := op.Counts(), (Counts{Attempted: 2, Succeeded: 1, Failed: 1, OptionalFailed: 1}); got != want {
+		t.Errorf("Counts() = %+v, want %+v", got, want)
+	}
+	if len(op.Warnings) != 1 || !strings.Contains(op.Warnings[0], "refresh .claude (optional) failed: rate limited") {
+		t.Errorf("optional failure should be listed as a warning, got %v", op.Warnings)
+	}
+	if len(op.FollowUps) != 1 {
+		t.Errorf("retry hint should be a follow-up, got %v", op.FollowUps)
+	}
+
+	var buf bytes.Buffer
+	op.WriteText(&buf)
+	if !strings.Contains(buf.String(), "✗ failed (optional)") {
+		t.Errorf("text summary should mark the step optional:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,147 @@
+package safepath
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/spec-maestro/maestro-cli/pkg/newline"
+)
+
+// Entry is a validated archive entry. Name has already passed Clean.
+type Entry struct {
+	Name  string
+	Mode  fs.FileMode
+	IsDir bool
+	Body  io.Reader
+}
+
+// WalkTar calls fn for every directory and regular file in the tar stream.
+// Links, devices, and other special entries are skipped; entries with unsafe
+// names abort the walk with an error wrapping ErrUnsafePath. If fn returns
+// fs.SkipAll the walk stops without error.
+func WalkTar(r io.Reader, fn func(Entry) error) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading archive entry: %w", err)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir, tar.TypeReg:
+		default:
+			continue
+		}
+
+		name, err := Clean(hdr.Name)
+		if err != nil {
+			return fmt.Errorf("invalid path in archive: %w", err)
+		}
+
+		entry := Entry{
+			Name:  name,
+			Mode:  hdr.FileInfo().Mode().Perm(),
+			IsDir: hdr.Typeflag == tar.TypeDir,
+			Body:  tr,
+		}
+		if err := fn(entry); err == fs.SkipAll {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// WalkZip calls fn for every directory and regular file in the zip archive,
+// applying the same rules as WalkTar.
+func WalkZip(zr *zip.Reader, fn func(Entry) error) error {
+	for _, f := range zr.File {
+		mode := f.Mode()
+		if !mode.IsDir() && !mode.IsRegular() {
+			continue
+		}
+
+		name, err := Clean(f.Name)
+		if err != nil {
+			return fmt.Errorf("invalid path in archive: %w", err)
+		}
+
+		if mode.IsDir() {
+			if err := fn(Entry{Name: name, Mode: mode.Perm(), IsDir: true}); err == fs.SkipAll {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("opening %s: %w", name, err)
+		}
+		err = fn(Entry{Name: name, Mode: mode.Perm(), Body: rc})
+		rc.Close()
+		if err == fs.SkipAll {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExtractTo returns a walk function that writes entries underneath destDir.
+// It is the only routine that should materialize archive entries on disk.
+// Text files are written with the project's line-ending policy.
+func ExtractTo(destDir string) func(Entry) error {
+	return func(e Entry) error {
+		target, err := Join(destDir, e.Name)
+		if err != nil {
+			return fmt.Errorf("invalid path in archive: %w", err)
+		}
+
+		if e.IsDir {
+			return os.MkdirAll(target, 0755)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		// Never follow a pre-existing symlink at the target itself.
+		if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("%w: symlink detected at %s", ErrUnsafePath, target)
+		}
+
+		mode := e.Mode
+		if mode == 0 {
+			mode = 0644
+		}
+		body := e.Body
+		if newline.CurrentPolicy() != newline.Keep {
+			data, err := io.ReadAll(e.Body)
+			if err != nil {
+				return err
+			}
+			body = bytes.NewReader(newline.Apply(e.Name, data))
+		}
+
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, body); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	}
+}
@@ -0,0 +1,114 @@
+// Package safepath validates untrusted relative paths (archive entries, API
+// file listings, asset maps) before they are joined onto a directory on disk.
+//
+// Every code path that writes or deletes files named by remote content must go
+// through this package so traversal and symlink handling cannot diverge.
+package safepath
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnsafePath is returned (wrapped) for any path that could escape its root.
+var ErrUnsafePath = errors.New("unsafe path")
+
+// Clean validates a slash-separated relative path and returns its cleaned
+// form. Empty, absolute, and parent-escaping paths are rejected, as are paths
+// containing NUL bytes, backslashes, or Windows volume names.
+func Clean(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("%w: empty path", ErrUnsafePath)
+	}
+	if strings.ContainsRune(name, 0) {
+		return "", fmt.Errorf("%w: path contains NUL byte: %q", ErrUnsafePath, name)
+	}
+	// Backslashes are separators on Windows; reject them everywhere so a path
+	// is interpreted the same way on every platform.
+	if strings.Contains(name, `\`) {
+		return "", fmt.Errorf("%w: path contains backslash: %s", ErrUnsafePath, name)
+	}
+	if path.IsAbs(name) || filepath.VolumeName(name) != "" || (len(name) >= 2 && name[1] == ':') {
+		return "", fmt.Errorf("%w: absolute path: %s", ErrUnsafePath, name)
+	}
+	for _, segment := range strings.Split(name, "/") {
+		if segment == ".." {
+			return "", fmt.Errorf("%w: path contains '..': %s", ErrUnsafePath, name)
+		}
+	}
+
+	cleaned := path.Clean(name)
+	if cleaned == "." {
+		return "", fmt.Errorf("%w: path has no file name: %s", ErrUnsafePath, name)
+	}
+	return cleaned, nil
+}
+
+// Join validates name and joins it onto root, returning an absolute path that
+// is guaranteed to be inside root. Existing components between root and the
+// result must not be symlinks.
+func Join(root, name string) (string, error) {
+	cleaned, err := Clean(name)
+	if err != nil {
+		return "", err
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", fmt.Errorf("resolving root: %w", err)
+	}
+
+	full := filepath.Join(absRoot, filepath.FromSlash(cleaned))
+	if !Within(absRoot, full) || full == absRoot {
+		return "", fmt.Errorf("%w: path traversal detected: %s", ErrUnsafePath, name)
+	}
+	if err := CheckNoSymlinks(filepath.Dir(full), absRoot); err != nil {
+		return "", fmt.Errorf("symlink check failed for %s: %w", name, err)
+	}
+	return full, nil
+}
+
+// Within reports whether target is root or lies underneath it. Both paths
+// must already be absolute and cleaned.
+func Within(root, target string) bool {
+	if target == root {
+		return true
+	}
+	return strings.HasPrefix(target, strings.TrimSuffix(root, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// CheckNoSymlinks checks that path and all of its parents up to (not
+// including) root are not symlinks. Components that do not exist yet are
+// skipped, since they will be created as regular directories.
+func CheckNoSymlinks(path, root string) error {
+	cleanPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("resolving path: %w", err)
+	}
+	cleanRoot, err := filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("resolving root: %w", err)
+	}
+
+	for current := cleanPath; current != cleanRoot && Within(cleanRoot, current); {
+		info, err := os.Lstat(current)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("checking path %s: %w", current, err)
+		}
+		if err == nil && info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("%w: symlink detected at %s", ErrUnsafePath, current)
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			break // Reached filesystem root
+		}
+		current = parent
+	}
+
+	return nil
+}
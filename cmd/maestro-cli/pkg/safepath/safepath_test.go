@@ -0,0 +1,171 @@
+package safepath
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClean(t *testing.T) {
+	valid := map[string]string{
+		"a.md":              "a.md",
+		"dir/b.md":          "dir/b.md",
+		"./dir//c.md":       "dir/c.md",
+		"dir/":              "dir",
+		"notes..md":         "notes..md",
+		"repo-abc/.codex/x": "repo-abc/.codex/x",
+	}
+	for in, want := range valid {
+		got, err := Clean(in)
+		if err != nil {
+			t.Errorf("Clean(%q) unexpected error: %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("Clean(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	for _, in := range []string{"", ".", "..", "../x", "a/../../x", "a/..", "/etc/passwd", `a\..\x`, "C:/x", "a\x00b"} {
+		if _, err := Clean(in); !errors.Is(err, ErrUnsafePath) {
+			t.Errorf("Clean(%q) should be rejected, got %v", in, err)
+		}
+	}
+}
+
+func TestJoinRejectsSymlinkedParent(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if _, err := Join(root, "link/file.txt"); !errors.Is(err, ErrUnsafePath) {
+		t.Errorf("expected symlinked parent to be rejected, got %v", err)
+	}
+}
+
+func TestWalkTarExtract(t *testing.T) {
+	dest := t.TempDir()
+	archive := buildTar(t, map[string]string{"bundle/a.txt": "alpha", "bundle/sub/b.txt": "beta"})
+
+	if err := WalkTar(bytes.NewReader(archive), ExtractTo(dest)); err != nil {
+		t.Fatalf("WalkTar() error: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(dest, "bundle", "sub", "b.txt"))
+	if err != nil || string(got) != "beta" {
+		t.Errorf("expected extracted content %q, got %q (%v)", "beta", got, err)
+	}
+}
+
+func TestWalkTarRejectsTraversal(t *testing.T) {
+	dest := t.TempDir()
+	archive := buildTar(t, map[string]string{"../escape.txt": "evil"})
+
+	err := WalkTar(bytes.NewReader(archive), ExtractTo(dest))
+	if !errors.Is(err, ErrUnsafePath) {
+		t.Fatalf("expected ErrUnsafePath, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dest), "escape.txt")); !os.IsNotExist(err) {
+		t.Error("traversal entry was written outside the destination")
+	}
+}
+
+func TestWalkZipRejectsTraversal(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("../../escape.txt")
+	w.Write([]byte("evil"))
+	zw.Close()
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error: %v", err)
+	}
+	if err := WalkZip(zr, ExtractTo(t.TempDir())); !errors.Is(err, ErrUnsafePath) {
+		t.Fatalf("expected ErrUnsafePath, got %v", err)
+	}
+}
+
+func buildTar(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("writing header: %v", err)
+		}
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	return buf.Bytes()
+}
+
+func FuzzClean(f *testing.F) {
+	for _, seed := range []string{"a.md", "dir/b.md", "../x", "a/../../x", "/abs", `a\b`, "./.", "a//b/"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, name string) {
+		cleaned, err := Clean(name)
+		if err != nil {
+			return
+		}
+		if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "/") || strings.HasPrefix(cleaned, "../") {
+			t.Fatalf("Clean(%q) accepted unsafe result %q", name, cleaned)
+		}
+		if again, err := Clean(cleaned); err != nil || again != cleaned {
+			t.Fatalf("Clean is not idempotent for %q: %q, %v", cleaned, again, err)
+		}
+	})
+}
+
+func FuzzJoin(f *testing.F) {
+	for _, seed := range []string{"a.md", "dir/b.md", "../x", "a/../../x", "/abs", "./.."} {
+		f.Add(seed)
+	}
+	root := f.TempDir()
+	f.Fuzz(func(t *testing.T, name string) {
+		full, err := Join(root, name)
+		if err != nil {
+			return
+		}
+		if !Within(root, full) || full == root {
+			t.Fatalf("Join(%q) escaped root: %s", name, full)
+		}
+	})
+}
+
+func FuzzWalkTar(f *testing.F) {
+	f.Add("bundle/a.txt", "alpha")
+	f.Add("../escape.txt", "evil")
+	f.Add("/abs.txt", "evil")
+	f.Fuzz(func(t *testing.T, name, content string) {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			return
+		}
+		tw.Write([]byte(content))
+		tw.Close()
+
+		parent := t.TempDir()
+		dest := filepath.Join(parent, "dest")
+		_ = WalkTar(bytes.NewReader(buf.Bytes()), ExtractTo(dest))
+
+		// Nothing may be written next to the destination directory.
+		entries, err := os.ReadDir(parent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range entries {
+			if e.Name() != "dest" {
+				t.Fatalf("entry %q escaped destination as %s", name, e.Name())
+			}
+		}
+	})
+}
@@ -0,0 +1,194 @@
+// Package scriptcompat checks that agent command files call the installed
+// scripts as they exist. Command prompts name scripts in .maestro/scripts/
+// and pass them flags; when a release renames a script or drops a flag, an
+// agent directory installed from an older release keeps calling the old
+// interface, and the agent fails at run time rather than at install.
+package scriptcompat
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Dir is the scripts directory command files refer to, relative to the
+// project root.
+const Dir = ".maestro/scripts"
+
+// Matrix maps each script, relative to the scripts directory, to the long
+// flags it accepts, sorted. An empty list means the script parses no
+// flags, and calls to it are only checked by name.
+type Matrix map[string][]string
+
+// Reference is a call to a script in a command file.
+type Reference struct {
+	// Script is relative to the scripts directory, e.g. worktree-create.sh.
+	Script string
+	// Flags are the long flags passed on the same line, without values.
+	Flags []string
+	Line  int
+}
+
+// Problem is a reference the installed scripts do not support.
+type Problem struct {
+	File string
+	Reference
+	// Missing is set when the script does not exist; otherwise Flags holds
+	// the flags the script does not accept.
+	Missing bool
+}
+
+var (
+	referencePattern = regexp.MustCompile(`\.maestro/scripts/([A-Za-z0-9_.-]+(?:/[A-Za-z0-9_.-]+)*)`)
+	flagPattern      = regexp.MustCompile(`^\[?--([A-Za-z0-9][A-Za-z0-9-]*)`)
+	// caseLabelPattern matches the flag labels of a case statement, such
+	// as "--repo)", "-h|--help)", or "--harness=*)".
+	caseLabelPattern = regexp.MustCompile(`^\s*"?((?:-[A-Za-z0-9]|--[A-Za-z0-9][A-Za-z0-9-]*(?:=\*)?)(?:\|(?:-[A-Za-z0-9]|--[A-Za-z0-9][A-Za-z0-9-]*(?:=\*)?))*)"?\)`)
+)
+
+// References returns the script calls in a command file, in order.
+func References(content []byte) []Reference {
+	var refs []Reference
+	for i, line := range strings.Split(string(content), "\n") {
+		matches := referencePattern.FindAllStringSubmatchIndex(line, -1)
+		for j, m := range matches {
+			script := strings.TrimRight(line[m[2]:m[3]], ".")
+			if script == "" || strings.HasSuffix(script, "/") {
+				continue
+			}
+			// A call's arguments run to the end of the command, or to the
+			// next call on the line
+			end := len(line)
+			if j+1 < len(matches) {
+				end = matches[j+1][0]
+			}
+			refs = append(refs, Reference{Script: script, Flags: callFlags(line[m[1]:end]), Line: i + 1})
+		}
+	}
+	return refs
+}
+
+// callFlags returns the long flags in the arguments of a call, stopping at
+// the end of the shell command or of inline code.
+func callFlags(args string) []string {
+	if i := strings.IndexAny(args, "`|>;&)\"'"); i >= 0 {
+		args = args[:i]
+	}
+	var flags []string
+	for _, word := range strings.Fields(args) {
+		if m := flagPattern.FindStringSubmatch(word); m != nil {
+			flags = append(flags, "--"+m[1])
+		}
+	}
+	return flags
+}
+
+// Flags returns the long flags a script accepts, read from the labels of
+// its case statements.
+func Flags(script []byte) []string {
+	seen := map[string]bool{}
+	flags := []string{}
+	for _, line := range strings.Split(string(script), "\n") {
+		m := caseLabelPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		for _, label := range strings.Split(m[1], "|") {
+			flag := strings.TrimSuffix(label, "=*")
+			if strings.HasPrefix(flag, "--") && !seen[flag] {
+				seen[flag] = true
+				flags = append(flags, flag)
+			}
+		}
+	}
+	sort.Strings(flags)
+	return flags
+}
+
+// Build returns the matrix of the scripts under dir: .sh files and files
+// that start with a shebang.
+func Build(dir string) (Matrix, error) {
+	matrix := Matrix{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if filepath.Ext(path) != ".sh" && !bytes.HasPrefix(data, []byte("#!")) {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		matrix[filepath.ToSlash(rel)] = Flags(data)
+		return nil
+	})
+	return matrix, err
+}
+
+// Check returns the calls in the files under agentDir that the scripts in
+// scriptsDir do not support: calls to scripts that do not exist, and flags
+// the matrix does not list for a script. Scripts missing from the matrix
+// are read from scriptsDir.
+func Check(agentDir, scriptsDir string, matrix Matrix) ([]Problem, error) {
+	var problems []Problem
+	err := filepath.WalkDir(agentDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, ref := range References(data) {
+			if problem, ok := checkReference(scriptsDir, matrix, ref); ok {
+				problem.File = path
+				problems = append(problems, problem)
+			}
+		}
+		return nil
+	})
+	return problems, err
+}
+
+func checkReference(scriptsDir string, matrix Matrix, ref Reference) (Problem, bool) {
+	data, err := os.ReadFile(filepath.Join(scriptsDir, filepath.FromSlash(ref.Script)))
+	if err != nil {
+		return Problem{Reference: ref, Missing: true}, true
+	}
+	accepted, ok := matrix[ref.Script]
+	if !ok {
+		accepted = Flags(data)
+	}
+	if len(accepted) == 0 {
+		return Problem{}, false
+	}
+	var unknown []string
+	for _, flag := range ref.Flags {
+		if !contains(accepted, flag) {
+			unknown = append(unknown, flag)
+		}
+	}
+	if len(unknown) == 0 {
+		return Problem{}, false
+	}
+	ref.Flags = unknown
+	return Problem{Reference: ref}, true
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package scriptcompat
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReferences(t *testing.T) {
+	content := []byte("Run:\n" +
+		"```bash\n" +
+		"bash .maestro/scripts/worktree-create.sh --repo {repo} --feature={id} [--dry-run] | tee log\n" +
+		"```\n" +
+		"Then `.maestro/scripts/list.sh` and source .maestro/scripts/lib.sh.\n" +
+		"See .maestro/scripts/ for more.\n")
+	want := []Reference{
+		{Script: "worktree-create.sh", Flags: []string{"--repo", "--feature", "--dry-run"}, Line: 3},
+		{Script: "list.sh", Line: 5},
+		{Script: "lib.sh", Line: 5},
+	}
+	if got := References(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("References() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFlags(t *testing.T) {
+	script := []byte(`#!/usr/bin/env bash
+while [[ $# -gt 0 ]]; do
+  case "$1" in
+    --repo) REPO="$2"; shift 2 ;;
+    --harness=*) HARNESS="${1#--harness=}"; shift ;;
+    -h|--help) usage ;;
+    --) shift; break ;;
+    *) echo "unknown: $1" ;;
+  esac
+done
+`)
+	want := []string{"--harness", "--help", "--repo"}
+	if got := Flags(script); !reflect.DeepEqual(got, want) {
+		t.Errorf("Flags() = %v, want %v", got, want)
+	}
+	if got := Flags([]byte("#!/bin/sh\necho \"$1\"\n")); len(got) != 0 {
+		t.Errorf("Flags() of a script without options = %v", got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	scripts := filepath.Join(dir, "scripts")
+	agent := filepath.Join(dir, "agent")
+	os.MkdirAll(scripts, 0755)
+	os.MkdirAll(filepath.Join(agent, "commands"), 0755)
+	os.WriteFile(filepath.Join(scripts, "create.sh"), []byte("#!/bin/sh\ncase \"$1\" in\n  --repo) ;;\nesac\n"), 0755)
+	os.WriteFile(filepath.Join(scripts, "plain.sh"), []byte("#!/bin/sh\necho \"$1\"\n"), 0755)
+	os.WriteFile(filepath.Join(scripts, "notes.txt"), []byte("not a script\n"), 0644)
+	os.WriteFile(filepath.Join(agent, "commands", "run.md"), []byte(
+		"bash .maestro/scripts/create.sh --repo x --branch y\n"+
+			"bash .maestro/scripts/plain.sh --anything\n"+
+			"bash .maestro/scripts/renamed.sh\n"), 0644)
+
+	matrix, err := Build(scripts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Matrix{"create.sh": {"--repo"}, "plain.sh": {}}); !reflect.DeepEqual(matrix, want) {
+		t.Errorf("Build() = %v, want %v", matrix, want)
+	}
+
+	problems, err := Check(agent, scripts, matrix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 2 {
+		t.Fatalf("Check() = %+v, want 2 problems", problems)
+	}
+	if p := problems[0]; p.Script != "create.sh" || p.Missing || !reflect.DeepEqual(p.Flags, []string{"--branch"}) || p.Line != 1 {
+		t.Errorf("unknown flag: got %+v", p)
+	}
+	if p := problems[1]; p.Script != "renamed.sh" || !p.Missing || p.Line != 3 {
+		t.Errorf("missing script: got %+v", p)
+	}
+
+	// The recorded matrix wins over the script's own parsing
+	problems, _ = Check(agent, scripts, Matrix{"create.sh": {"--branch", "--repo"}})
+	if len(problems) != 1 || problems[0].Script != "renamed.sh" {
+		t.Errorf("Check() with a recorded matrix = %+v", problems)
+	}
+}
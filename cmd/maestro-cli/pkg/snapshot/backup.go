@@ -0,0 +1,138 @@
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spec-maestro/maestro-cli/pkg/assets"
+	"github.com/spec-maestro/maestro-cli/pkg/safepath"
+	"github.com/spec-maestro/maestro-cli/pkg/tarball"
+)
+
+// BackupPrefix starts the name of every backup remove makes, archive or
+// directory copy.
+const BackupPrefix = ".maestro-backup-"
+
+// ChecksumSuffix is appended to an archive's name for its checksum file,
+// which is in sha256sum's format.
+const ChecksumSuffix = ".sha256"
+
+// ErrNoChecksum is returned by VerifyBackup when the archive has no
+// checksum file.
+var ErrNoChecksum = errors.New("no checksum file")
+
+// ErrExists is returned by RestoreBackup when files in the archive already
+// exist and force is not set.
+var ErrExists = errors.New("files already exist")
+
+// CreateBackup archives paths, relative to root, as
+// root/.maestro-backup-<ts>.tar.gz and writes its checksum file. It returns
+// the archive's path. Directories are archived with everything under them;
+// links and other special files are left out.
+func CreateBackup(root string, paths []string, now time.Time) (string, error) {
+	name, err := tarball.Create(root, BackupPrefix, root, paths, now)
+	if err != nil {
+		return "", fmt.Errorf("backup: %w", err)
+	}
+	sum, err := fileSum(name)
+	if err != nil {
+		return "", err
+	}
+	line := sum + "  " + filepath.Base(name) + "\n"
+	if err := os.WriteFile(name+ChecksumSuffix, []byte(line), 0644); err != nil {
+		return "", fmt.Errorf("writing checksum: %w", err)
+	}
+	return name, nil
+}
+
+// VerifyBackup checks the archive against its checksum file.
+func VerifyBackup(archive string) error {
+	f, err := os.Open(archive + ChecksumSuffix)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s: %w", archive, ErrNoChecksum)
+	}
+	if err != nil {
+		return err
+	}
+	checksums, err := assets.ParseChecksums(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	want, ok := checksums[filepath.Base(archive)]
+	if !ok {
+		return fmt.Errorf("%s%s does not list %s", archive, ChecksumSuffix, filepath.Base(archive))
+	}
+
+	got, err := fileSum(archive)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(got, want) {
+		return fmt.Errorf("%s: checksum mismatch (expected %s, got %s)", archive, want, got)
+	}
+	return nil
+}
+
+// fileSum returns the hex sha256 of the file at name.
+func fileSum(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("reading %s: %w", name, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// BackupContents returns the top-level paths in the archive and the files in it
+// that already exist under root.
+func BackupContents(archive, root string) (top, existing []string, err error) {
+	seen := make(map[string]bool)
+	err = walk(archive, func(e safepath.Entry) error {
+		first := strings.SplitN(e.Name, "/", 2)[0]
+		if !seen[first] {
+			seen[first] = true
+			top = append(top, first)
+		}
+		if !e.IsDir {
+			if _, err := os.Lstat(filepath.Join(root, filepath.FromSlash(e.Name))); err == nil {
+				existing = append(existing, e.Name)
+			}
+		}
+		return nil
+	})
+	sort.Strings(top)
+	return top, existing, err
+}
+
+// RestoreBackup extracts the archive under root and returns its top-level paths.
+// Unless force is set, it refuses when any of its files already exist;
+// with force they are overwritten. Files not in the archive are left alone.
+func RestoreBackup(archive, root string, force bool) ([]string, error) {
+	top, existing, err := BackupContents(archive, root)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 && !force {
+		if len(existing) > 3 {
+			existing = append(existing[:3], fmt.Sprintf("and %d more", len(existing)-3))
+		}
+		return nil, fmt.Errorf("%w: %s", ErrExists, strings.Join(existing, ", "))
+	}
+	if err := walk(archive, safepath.ExtractTo(root)); err != nil {
+		return nil, fmt.Errorf("restoring %s: %w", archive, err)
+	}
+	return top, nil
+}
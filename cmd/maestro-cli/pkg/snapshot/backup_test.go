@@ -0,0 +1,68 @@
+package snapshot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackupCreateVerifyAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".maestro", "specs", "001-auth"), 0755)
+	os.MkdirAll(filepath.Join(dir, ".claude", "commands"), 0755)
+	os.WriteFile(filepath.Join(dir, ".maestro", "config.yaml"), []byte("project:\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".maestro", "specs", "001-auth", "spec.md"), []byte("# Auth\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".claude", "commands", "maestro.plan.md"), []byte("plan\n"), 0644)
+
+	at := time.Date(2026, 3, 1, 9, 30, 0, 0, time.Local)
+	archive, err := CreateBackup(dir, []string{".maestro", ".claude"}, at)
+	if err != nil {
+		t.Fatalf("CreateBackup() error: %v", err)
+	}
+	if filepath.Base(archive) != ".maestro-backup-20260301-093000.tar.gz" {
+		t.Errorf("archive name = %s", filepath.Base(archive))
+	}
+	if second, err := CreateBackup(dir, []string{".claude"}, at); err != nil || filepath.Base(second) != ".maestro-backup-20260301-093001.tar.gz" {
+		t.Errorf("a second archive in the same second should take the next one, got %s, %v", second, err)
+	}
+	if err := VerifyBackup(archive); err != nil {
+		t.Fatalf("VerifyBackup() error: %v", err)
+	}
+
+	if _, err := RestoreBackup(archive, dir, false); !errors.Is(err, ErrExists) {
+		t.Errorf("RestoreBackup() over existing files: error = %v, want ErrExists", err)
+	}
+
+	os.RemoveAll(filepath.Join(dir, ".maestro"))
+	os.RemoveAll(filepath.Join(dir, ".claude"))
+	top, err := RestoreBackup(archive, dir, false)
+	if err != nil {
+		t.Fatalf("RestoreBackup() error: %v", err)
+	}
+	if len(top) != 2 || top[0] != ".claude" || top[1] != ".maestro" {
+		t.Errorf("RestoreBackup() = %v", top)
+	}
+	for path, want := range map[string]string{
+		filepath.Join(dir, ".maestro", "config.yaml"):                  "project:\n",
+		filepath.Join(dir, ".maestro", "specs", "001-auth", "spec.md"): "# Auth\n",
+		filepath.Join(dir, ".claude", "commands", "maestro.plan.md"):   "plan\n",
+	} {
+		if got, _ := os.ReadFile(path); string(got) != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+
+	// A damaged archive fails its checksum
+	f, _ := os.OpenFile(archive, os.O_APPEND|os.O_WRONLY, 0644)
+	f.Write([]byte("x"))
+	f.Close()
+	if err := VerifyBackup(archive); err == nil {
+		t.Error("VerifyBackup() should fail for a modified archive")
+	}
+	os.Remove(archive + ChecksumSuffix)
+	if err := VerifyBackup(archive); !errors.Is(err, ErrNoChecksum) {
+		t.Errorf("VerifyBackup() without a checksum file: error = %v, want ErrNoChecksum", err)
+	}
+}
@@ -0,0 +1,187 @@
+// Package snapshot saves a feature's artifacts (its directory under
+// .maestro/specs/ and its state file) to a timestamped archive and restores
+// them, so a feature can be reverted after its files were damaged. It also
+// writes the checksummed backup archives of what remove deletes, and
+// restores them.
+package snapshot
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spec-maestro/maestro-cli/pkg/safepath"
+	"github.com/spec-maestro/maestro-cli/pkg/tarball"
+)
+
+// Dir is where snapshots are kept, relative to the .maestro directory.
+const Dir = "archive/snapshots"
+
+// ErrNoFiles is returned by Create when the feature has neither a directory
+// nor a state file.
+var ErrNoFiles = errors.New("no files to snapshot")
+
+// Create archives the feature's directory and state file under
+// maestroDir/Dir as <featureID>-<YYYYMMDD-HHMMSS>.tar.gz and returns its
+// path. Paths in the archive are relative to maestroDir.
+func Create(maestroDir, featureID string, now time.Time) (string, error) {
+	files, err := featureFiles(maestroDir, featureID)
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("feature %s: %w", featureID, ErrNoFiles)
+	}
+
+	dir := filepath.Join(maestroDir, filepath.FromSlash(Dir))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating %s: %w", dir, err)
+	}
+	name, err := tarball.Create(dir, featureID+"-", maestroDir, files, now)
+	if err != nil {
+		return "", fmt.Errorf("snapshot: %w", err)
+	}
+	return name, nil
+}
+
+// List returns the paths of the feature's snapshots, oldest first.
+func List(maestroDir, featureID string) ([]string, error) {
+	dir := filepath.Join(maestroDir, filepath.FromSlash(Dir))
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", dir, err)
+	}
+	var paths []string
+	for _, entry := range entries {
+		if id, _, ok := parseName(entry.Name()); ok && id == featureID {
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Features returns the IDs of the features that have snapshots, sorted.
+func Features(maestroDir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(maestroDir, filepath.FromSlash(Dir)))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var ids []string
+	for _, entry := range entries {
+		if id, _, ok := parseName(entry.Name()); ok && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Time returns when the snapshot at path was taken, from its name.
+func Time(snapshotPath string) (time.Time, bool) {
+	_, at, ok := parseName(filepath.Base(snapshotPath))
+	return at, ok
+}
+
+// Restore replaces the feature's directory and state file with the content
+// of the snapshot at snapshotPath. The snapshot is checked to contain only
+// that feature's files before anything is removed.
+func Restore(maestroDir, featureID, snapshotPath string) error {
+	specPrefix := path.Join("specs", featureID) + "/"
+	statePath := path.Join("state", featureID+".json")
+	err := walk(snapshotPath, func(e safepath.Entry) error {
+		if !strings.HasPrefix(e.Name, specPrefix) && e.Name != statePath {
+			return fmt.Errorf("snapshot %s contains %s, which is not part of feature %s", snapshotPath, e.Name, featureID)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("reading snapshot %s: %w", snapshotPath, err)
+	}
+
+	if err := os.RemoveAll(filepath.Join(maestroDir, "specs", featureID)); err != nil {
+		return fmt.Errorf("removing the current feature directory: %w", err)
+	}
+	if err := os.Remove(filepath.Join(maestroDir, filepath.FromSlash(statePath))); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing the current state file: %w", err)
+	}
+	if err := walk(snapshotPath, safepath.ExtractTo(maestroDir)); err != nil {
+		return fmt.Errorf("restoring snapshot %s: %w", snapshotPath, err)
+	}
+	return nil
+}
+
+// walk calls fn for every entry of the snapshot at snapshotPath.
+func walk(snapshotPath string, fn func(safepath.Entry) error) error {
+	f, err := os.Open(snapshotPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	return safepath.WalkTar(gz, fn)
+}
+
+// featureFiles lists the feature's files as slash-separated paths relative
+// to maestroDir.
+func featureFiles(maestroDir, featureID string) ([]string, error) {
+	var files []string
+	specDir := filepath.Join(maestroDir, "specs", featureID)
+	err := filepath.WalkDir(specDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(maestroDir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading %s: %w", specDir, err)
+	}
+
+	statePath := filepath.Join(maestroDir, "state", featureID+".json")
+	if info, err := os.Stat(statePath); err == nil && info.Mode().IsRegular() {
+		files = append(files, path.Join("state", featureID+".json"))
+	}
+	return files, nil
+}
+
+// parseName splits a snapshot file name into the feature ID and the time
+// it was taken.
+func parseName(name string) (string, time.Time, bool) {
+	base, ok := strings.CutSuffix(name, ".tar.gz")
+	if !ok || len(base) < len(tarball.TimeFormat)+2 {
+		return "", time.Time{}, false
+	}
+	stamp := base[len(base)-len(tarball.TimeFormat):]
+	at, err := time.ParseInLocation(tarball.TimeFormat, stamp, time.Local)
+	if err != nil || base[len(base)-len(tarball.TimeFormat)-1] != '-' {
+		return "", time.Time{}, false
+	}
+	return base[:len(base)-len(tarball.TimeFormat)-1], at, true
+}
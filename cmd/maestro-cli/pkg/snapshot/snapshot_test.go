@@ -0,0 +1,73 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCreateAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	feature := filepath.Join(dir, "specs", "003-user-auth")
+	os.MkdirAll(filepath.Join(feature, "research"), 0755)
+	os.MkdirAll(filepath.Join(dir, "state"), 0755)
+	os.WriteFile(filepath.Join(feature, "spec.md"), []byte("# Auth\n"), 0644)
+	os.WriteFile(filepath.Join(feature, "research", "synthesis.md"), []byte("findings\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "state", "003-user-auth.json"), []byte(`{"stage":"plan"}`), 0644)
+	os.WriteFile(filepath.Join(dir, "state", "004-other.json"), []byte(`{}`), 0644)
+
+	at := time.Date(2026, 3, 1, 9, 30, 0, 0, time.Local)
+	first, err := Create(dir, "003-user-auth", at)
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	if filepath.Base(first) != "003-user-auth-20260301-093000.tar.gz" {
+		t.Errorf("snapshot name = %s", filepath.Base(first))
+	}
+	second, err := Create(dir, "003-user-auth", at)
+	if err != nil || filepath.Base(second) != "003-user-auth-20260301-093001.tar.gz" {
+		t.Errorf("a second snapshot in the same second should take the next one, got %s, %v", second, err)
+	}
+
+	// Mangle the feature, then restore it
+	os.WriteFile(filepath.Join(feature, "spec.md"), []byte("garbage"), 0644)
+	os.WriteFile(filepath.Join(feature, "plan.md"), []byte("stray"), 0644)
+	os.RemoveAll(filepath.Join(feature, "research"))
+	os.WriteFile(filepath.Join(dir, "state", "003-user-auth.json"), []byte(`{"stage":"broken"}`), 0644)
+
+	if err := Restore(dir, "003-user-auth", first); err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+	for path, want := range map[string]string{
+		filepath.Join(feature, "spec.md"):                  "# Auth\n",
+		filepath.Join(feature, "research", "synthesis.md"): "findings\n",
+		filepath.Join(dir, "state", "003-user-auth.json"):  `{"stage":"plan"}`,
+		filepath.Join(dir, "state", "004-other.json"):      `{}`,
+	} {
+		if got, _ := os.ReadFile(path); string(got) != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(feature, "plan.md")); !os.IsNotExist(err) {
+		t.Error("files added after the snapshot should be removed")
+	}
+
+	list, err := List(dir, "003-user-auth")
+	if err != nil || len(list) != 2 || list[0] != first {
+		t.Errorf("List() = %v, %v", list, err)
+	}
+	if ids, _ := Features(dir); len(ids) != 1 || ids[0] != "003-user-auth" {
+		t.Errorf("Features() = %v", ids)
+	}
+	if got, ok := Time(first); !ok || !got.Equal(at) {
+		t.Errorf("Time() = %v, %v", got, ok)
+	}
+
+	if err := Restore(dir, "004-other", first); err == nil {
+		t.Error("restoring another feature's snapshot should fail")
+	}
+	if _, err := Create(dir, "005-missing", at); err == nil {
+		t.Error("snapshotting a feature without files should fail")
+	}
+}
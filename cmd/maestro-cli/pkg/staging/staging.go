@@ -0,0 +1,405 @@
+// Package staging copies the parts of a project a command changes into a
+// staging directory, lets the command change the copy, and applies the
+// differences back to the project only once they are confirmed.
+package staging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Kinds of change between the project and its staged copy.
+const (
+	Added   = "added"
+	Changed = "changed"
+	Removed = "removed"
+)
+
+// Change is a file the staged copy adds, changes, or removes. Path is
+// slash-separated and relative to the project root.
+type Change struct {
+	Path string `json:"path"`
+	Kind string `json:"change"`
+}
+
+// Area is a staged copy of some paths of a project.
+type Area struct {
+	// Root is the project root and Dir the staging directory.
+	Root, Dir string
+	paths     []string
+	skip      []string
+}
+
+// New copies paths, slash-separated and relative to root, into dir,
+// replacing whatever an earlier run left there, and returns the area.
+// Paths that do not exist are skipped, and so are the subtrees in skip
+// and dir itself: files added under them in the copy are applied, but
+// files there are never removed. A path that is a symlink to a directory
+// is copied as a directory.
+func New(root, dir string, paths, skip []string) (*Area, error) {
+	a := &Area{Root: root, Dir: dir, paths: paths, skip: skip}
+	if rel, err := filepath.Rel(root, dir); err == nil && !strings.HasPrefix(rel, "..") {
+		a.skip = append(append([]string{}, skip...), filepath.ToSlash(rel))
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("clearing %s: %w", dir, err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating %s: %w", dir, err)
+	}
+	for _, p := range paths {
+		if err := a.copyIn(p); err != nil {
+			a.Discard()
+			return nil, fmt.Errorf("staging %s: %w", p, err)
+		}
+	}
+	return a, nil
+}
+
+// copyIn copies the project path p into the staging directory.
+func (a *Area) copyIn(p string) error {
+	src, err := a.source(p)
+	if err != nil || src == "" {
+		return err
+	}
+	return a.walk(src, p, func(file, rel string, d fs.DirEntry) error {
+		target := filepath.Join(a.Dir, filepath.FromSlash(rel))
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(file)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case !d.Type().IsRegular():
+			return nil
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, info.Mode().Perm())
+	})
+}
+
+// source returns where the project path p is on disk, following a symlink
+// at p itself, or "" when it does not exist.
+func (a *Area) source(p string) (string, error) {
+	src := filepath.Join(a.Root, filepath.FromSlash(p))
+	real, err := filepath.EvalSymlinks(src)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return real, nil
+}
+
+// walk calls fn for every entry under src, which is the project path p,
+// with its slash-separated path relative to the project root, leaving out
+// the skipped subtrees.
+func (a *Area) walk(src, p string, fn func(file, rel string, d fs.DirEntry) error) error {
+	return filepath.WalkDir(src, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, file)
+		if err != nil {
+			return err
+		}
+		rel = path.Join(p, filepath.ToSlash(rel))
+		if a.skipped(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return fn(file, rel, d)
+	})
+}
+
+// skipped reports whether rel is in a subtree New does not copy.
+func (a *Area) skipped(rel string) bool {
+	for _, s := range a.skip {
+		if rel == s || strings.HasPrefix(rel, s+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Changes compares the staged copy with the project and returns every file
+// the copy adds, changes (in content or permissions), or removes, sorted
+// by path. Only regular files count.
+func (a *Area) Changes() ([]Change, error) {
+	var changes []Change
+	staged := make(map[string]bool)
+	err := filepath.WalkDir(a.Dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(a.Dir, file)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		staged[rel] = true
+		kind, err := a.compare(file, rel)
+		if kind != "" {
+			changes = append(changes, Change{Path: rel, Kind: kind})
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("comparing staged files: %w", err)
+	}
+
+	for _, p := range a.paths {
+		src, err := a.source(p)
+		if err != nil {
+			return nil, err
+		}
+		if src == "" {
+			continue
+		}
+		err = a.walk(src, p, func(_, rel string, d fs.DirEntry) error {
+			if d.Type().IsRegular() && !staged[rel] {
+				changes = append(changes, Change{Path: rel, Kind: Removed})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("comparing staged files: %w", err)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// compare returns how the staged file at file changes the project file rel,
+// or "" when it does not.
+func (a *Area) compare(file, rel string) (string, error) {
+	target := filepath.Join(a.Root, filepath.FromSlash(rel))
+	info, err := os.Stat(target)
+	if os.IsNotExist(err) {
+		return Added, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	stagedInfo, err := os.Stat(file)
+	if err != nil {
+		return "", err
+	}
+	if info.Mode().Perm() != stagedInfo.Mode().Perm() {
+		return Changed, nil
+	}
+	want, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	have, err := os.ReadFile(target)
+	if err != nil {
+		return "", err
+	}
+	if !bytes.Equal(want, have) {
+		return Changed, nil
+	}
+	return "", nil
+}
+
+// ErrPartial is returned by Apply when a change failed and some of the
+// changes already made could not be undone.
+var ErrPartial = errors.New("changes partly applied")
+
+// undoDir is where Apply keeps the project files it replaces or removes
+// until every change is in, relative to the staging directory.
+const undoDir = ".undo"
+
+// rename is os.Rename, replaced in tests to make a write fail.
+var rename = os.Rename
+
+// Apply makes changes in the project: added and changed files are replaced
+// with their staged version through a rename, and removed files are
+// deleted along with the directories that leaves empty. Each file it
+// replaces or removes is copied aside first, and if a change fails, the
+// ones already made are undone, so the project is left either with every
+// change or as it was.
+func (a *Area) Apply(changes []Change) error {
+	u := &undo{area: a, dir: filepath.Join(a.Dir, undoDir)}
+	defer os.RemoveAll(u.dir)
+	var removed []string
+	for _, c := range changes {
+		target := filepath.Join(a.Root, filepath.FromSlash(c.Path))
+		if err := u.keep(c.Path, target); err != nil {
+			return u.restore(fmt.Errorf("keeping %s: %w", c.Path, err))
+		}
+		if c.Kind == Removed {
+			if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+				return u.restore(fmt.Errorf("removing %s: %w", c.Path, err))
+			}
+			removed = append(removed, c.Path)
+			continue
+		}
+		if err := a.replace(c.Path, target, u); err != nil {
+			return u.restore(fmt.Errorf("writing %s: %w", c.Path, err))
+		}
+	}
+
+	// Remove the deepest directories first, so their parents can follow
+	sort.Slice(removed, func(i, j int) bool { return len(removed[i]) > len(removed[j]) })
+	for _, rel := range removed {
+		for dir := path.Dir(rel); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			if os.Remove(filepath.Join(a.Root, filepath.FromSlash(dir))) != nil {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// undo records what Apply changed in the project so far: the files it
+// touched, with copies of those that existed in dir, and the directories it
+// created.
+type undo struct {
+	area    *Area
+	dir     string
+	touched []string
+	kept    map[string]bool
+	created []string
+}
+
+// keep copies the project file rel at target into the undo directory, if
+// it exists, before Apply changes it.
+func (u *undo) keep(rel, target string) error {
+	u.touched = append(u.touched, rel)
+	info, err := os.Lstat(target)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(u.dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, data, info.Mode().Perm()); err != nil {
+		return err
+	}
+	if u.kept == nil {
+		u.kept = make(map[string]bool)
+	}
+	u.kept[rel] = true
+	return nil
+}
+
+// mkdirAll creates dir and any missing parents, recording each one it
+// creates.
+func (u *undo) mkdirAll(dir string) error {
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Lstat(d); err == nil || filepath.Dir(d) == d {
+			break
+		}
+		missing = append(missing, d)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for i := len(missing) - 1; i >= 0; i-- {
+		u.created = append(u.created, missing[i])
+	}
+	return nil
+}
+
+// restore undoes the changes Apply made, latest first, and returns err,
+// noting any file it could not put back.
+func (u *undo) restore(err error) error {
+	var failed []string
+	for i := len(u.touched) - 1; i >= 0; i-- {
+		rel := u.touched[i]
+		target := filepath.Join(u.area.Root, filepath.FromSlash(rel))
+		var rerr error
+		if u.kept[rel] {
+			rerr = writeFile(filepath.Join(u.dir, filepath.FromSlash(rel)), target)
+		} else if rerr = os.Remove(target); os.IsNotExist(rerr) {
+			rerr = nil
+		}
+		if rerr != nil {
+			failed = append(failed, rel)
+		}
+	}
+	for i := len(u.created) - 1; i >= 0; i-- {
+		os.Remove(u.created[i])
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%w; %w: could not restore %s", err, ErrPartial, strings.Join(failed, ", "))
+	}
+	return fmt.Errorf("%w; no changes were applied", err)
+}
+
+// replace writes the staged version of rel to target, creating its
+// directory through u.
+func (a *Area) replace(rel, target string, u *undo) error {
+	if err := u.mkdirAll(filepath.Dir(target)); err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(a.Dir, filepath.FromSlash(rel)), target)
+}
+
+// writeFile copies the file at src, with its permissions, to target
+// through a temporary file next to target.
+func writeFile(src, target string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	return rename(tmp.Name(), target)
+}
+
+// Discard removes the staging directory.
+func (a *Area) Discard() error {
+	return os.RemoveAll(a.Dir)
+}
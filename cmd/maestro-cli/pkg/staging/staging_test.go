@@ -0,0 +1,142 @@
+package staging
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStageChangesApply(t *testing.T) {
+	root := t.TempDir()
+	write(t, filepath.Join(root, ".maestro/config.yaml"), "cli_version: v1.0.0\n")
+	write(t, filepath.Join(root, ".maestro/scripts/run.sh"), "echo old\n")
+	write(t, filepath.Join(root, ".maestro/archive/guard/old.tar.gz"), "snapshot")
+	write(t, filepath.Join(root, ".claude/commands/old.md"), "old")
+	write(t, filepath.Join(root, "AGENTS.md"), "agents")
+
+	dir := filepath.Join(root, ".maestro/.staging")
+	write(t, filepath.Join(dir, "leftover"), "from an earlier run")
+	area, err := New(root, dir, []string{".maestro", ".claude", ".opencode", "AGENTS.md"}, []string{".maestro/archive"})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "leftover")); !os.IsNotExist(err) {
+		t.Error("New() should clear what an earlier run left")
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".maestro/archive")); !os.IsNotExist(err) {
+		t.Error("skipped subtrees should not be copied")
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".maestro/.staging")); !os.IsNotExist(err) {
+		t.Error("the staging directory should not be copied into itself")
+	}
+
+	changes, err := area.Changes()
+	if err != nil || len(changes) != 0 {
+		t.Fatalf("a fresh copy should have no changes, got %v, %v", changes, err)
+	}
+
+	write(t, filepath.Join(dir, ".maestro/scripts/run.sh"), "echo new\n")
+	os.Chmod(filepath.Join(dir, ".maestro/scripts/run.sh"), 0755)
+	write(t, filepath.Join(dir, ".maestro/archive/guard/new.tar.gz"), "snapshot")
+	write(t, filepath.Join(dir, ".opencode/commands/new.md"), "new")
+	os.RemoveAll(filepath.Join(dir, ".claude"))
+
+	changes, err = area.Changes()
+	if err != nil {
+		t.Fatalf("Changes() error: %v", err)
+	}
+	want := []Change{
+		{".claude/commands/old.md", Removed},
+		{".maestro/archive/guard/new.tar.gz", Added},
+		{".maestro/scripts/run.sh", Changed},
+		{".opencode/commands/new.md", Added},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Changes() = %v, want %v", changes, want)
+	}
+
+	if err := area.Apply(changes); err != nil {
+		t.Fatalf("Apply() error: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, ".maestro/scripts/run.sh")); string(data) != "echo new\n" {
+		t.Errorf("run.sh = %q, want the staged version", data)
+	}
+	if info, err := os.Stat(filepath.Join(root, ".maestro/scripts/run.sh")); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("run.sh should keep the staged permissions, got %v, %v", info, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".claude")); !os.IsNotExist(err) {
+		t.Error("a directory left empty by removals should be removed")
+	}
+	if _, err := os.Stat(filepath.Join(root, ".maestro/archive/guard/old.tar.gz")); err != nil {
+		t.Error("files in skipped subtrees should never be removed")
+	}
+
+	if err := area.Discard(); err != nil {
+		t.Fatalf("Discard() error: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("Discard() should remove the staging directory")
+	}
+}
+
+func TestApplyUndoesChangesWhenAWriteFails(t *testing.T) {
+	root := t.TempDir()
+	write(t, filepath.Join(root, ".maestro/a.md"), "old a")
+	write(t, filepath.Join(root, ".maestro/b.md"), "old b")
+	write(t, filepath.Join(root, ".maestro/c.md"), "old c")
+	write(t, filepath.Join(root, ".maestro/gone.md"), "kept")
+
+	dir := filepath.Join(root, ".maestro/.staging")
+	area, err := New(root, dir, []string{".maestro"}, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer area.Discard()
+	write(t, filepath.Join(dir, ".maestro/a.md"), "new a")
+	write(t, filepath.Join(dir, ".maestro/b.md"), "new b")
+	write(t, filepath.Join(dir, ".maestro/c.md"), "new c")
+	write(t, filepath.Join(dir, ".maestro/new/d.md"), "new d")
+	os.Remove(filepath.Join(dir, ".maestro/gone.md"))
+	changes, err := area.Changes()
+	if err != nil || len(changes) != 5 {
+		t.Fatalf("Changes() = %v, %v; want 5 changes", changes, err)
+	}
+
+	// Fail the write of the fourth file, after the removal and three writes
+	renames := 0
+	rename = func(from, to string) error {
+		if renames++; renames == 4 {
+			return errors.New("disk full")
+		}
+		return os.Rename(from, to)
+	}
+	defer func() { rename = os.Rename }()
+
+	err = area.Apply(changes)
+	if err == nil || errors.Is(err, ErrPartial) {
+		t.Fatalf("Apply() error = %v, want a write failure with every change undone", err)
+	}
+	for name, want := range map[string]string{"a.md": "old a", "b.md": "old b", "c.md": "old c", "gone.md": "kept"} {
+		if data, err := os.ReadFile(filepath.Join(root, ".maestro", name)); err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v; want %q", name, data, err, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, ".maestro/new")); !os.IsNotExist(err) {
+		t.Error("a directory created for an added file should be removed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, undoDir)); !os.IsNotExist(err) {
+		t.Error("the copies of replaced files should be removed")
+	}
+}
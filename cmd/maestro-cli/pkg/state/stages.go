@@ -0,0 +1,72 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Stages are the pipeline stages in order: specify, research, plan, tasks,
+// and implement, with the optional steps between them and after implement.
+// Cancelled is outside the order: any stage can go to it.
+var Stages = []string{
+	"specify", "clarify", "research", "plan", "tasks", "analyze", "implement",
+	"review", "pm-validate", "commit", "complete",
+}
+
+// Cancelled is the stage of a feature dropped with /maestro.list.
+const Cancelled = "cancelled"
+
+// required are the stages a feature can't skip on its way forward. Research
+// is optional: /maestro.plan goes ahead without it once the user gives
+// the bypass phrase.
+var required = map[string]bool{"specify": true, "plan": true, "tasks": true, "implement": true}
+
+// Index returns the position of stage in Stages, or -1 for cancelled and
+// unknown stages.
+func Index(stage string) int {
+	for i, s := range Stages {
+		if s == stage {
+			return i
+		}
+	}
+	return -1
+}
+
+// Known reports whether stage is one of Stages or cancelled.
+func Known(stage string) bool {
+	return stage == Cancelled || Index(stage) >= 0
+}
+
+// CheckTransition checks that a feature at stage from can move to stage to.
+// A new feature, with no stage, starts at specify. A feature can stay at
+// its stage, go back to redo an earlier one, or go forward past optional
+// stages, but not past a required one; cancelled is reachable from any
+// stage and leads nowhere. A state with a stage this maestro doesn't know
+// can move anywhere known.
+func CheckTransition(from, to string) error {
+	switch {
+	case !Known(to):
+		return fmt.Errorf("%w: unknown stage %q; stages are %s, and %s", ErrTransition, to, strings.Join(Stages, ", "), Cancelled)
+	case from == to, to == Cancelled:
+		return nil
+	case from == "":
+		if to != Stages[0] {
+			return fmt.Errorf("%w: a new feature starts at %s, not %s", ErrTransition, Stages[0], to)
+		}
+		return nil
+	case from == Cancelled:
+		return fmt.Errorf("%w: the feature is %s", ErrTransition, Cancelled)
+	case !Known(from):
+		return nil
+	}
+	i, j := Index(from), Index(to)
+	if j < i {
+		return nil
+	}
+	for _, s := range Stages[i+1 : j] {
+		if required[s] {
+			return fmt.Errorf("%w: %s to %s skips %s", ErrTransition, from, to, s)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,616 @@
+// Package state reads and writes feature state files,
+// .maestro/state/<feature>.json: what stage a feature is at, where its
+// artifacts are, and the history of its stages. Fields are kept as written,
+// in their order, unless they are changed: the ones the schema doesn't
+// model, so tools that add their own survive a save, and modelled ones with
+// a value the schema reads loosely, such as research_ready: "TRUE".
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchemaVersion is the version of the schema Save writes. Files without a
+// schema_version are version 0.
+const SchemaVersion = 1
+
+// ErrNewerSchema is returned for a file written by a newer maestro.
+var ErrNewerSchema = errors.New("written by a newer maestro")
+
+// ErrTransition is returned for a stage change the pipeline doesn't allow.
+var ErrTransition = errors.New("invalid stage transition")
+
+// Entry is one step in a feature's history.
+type Entry struct {
+	Stage     string `json:"stage"`
+	Timestamp string `json:"timestamp"`
+	Action    string `json:"action,omitempty"`
+	// Actor is MAESTRO_ACTOR when the step was recorded.
+	Actor string `json:"actor,omitempty"`
+	// Note is detail beyond the action, such as the counts
+	// tasks-from-plan.sh records.
+	Note string `json:"note,omitempty"`
+
+	// Extra holds the fields the schema doesn't model, as written.
+	Extra map[string]json.RawMessage `json:"-"`
+
+	// raw is the entry as read, written back while the fields still
+	// encode as canon, which they did when it was read.
+	raw, canon json.RawMessage
+}
+
+// UnmarshalJSON decodes the modelled fields and keeps the rest in Extra.
+func (e *Entry) UnmarshalJSON(data []byte) error {
+	type plain Entry
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for _, key := range []string{"stage", "timestamp", "action", "actor", "note"} {
+		delete(fields, key)
+	}
+	if len(fields) > 0 {
+		p.Extra = fields
+	}
+	*e = Entry(p)
+	canon, err := e.encode()
+	if err != nil {
+		return err
+	}
+	e.raw, e.canon = append(json.RawMessage{}, data...), canon
+	return nil
+}
+
+// MarshalJSON writes the entry as it was read when it is unchanged, and
+// otherwise the modelled fields, then Extra sorted by key.
+func (e Entry) MarshalJSON() ([]byte, error) {
+	data, err := e.encode()
+	if err == nil && e.raw != nil && bytes.Equal(data, e.canon) {
+		return e.raw, nil
+	}
+	return data, err
+}
+
+func (e Entry) encode() ([]byte, error) {
+	type plain Entry
+	data, err := marshal(plain(e))
+	if err != nil || len(e.Extra) == 0 {
+		return data, err
+	}
+	keys := make([]string, 0, len(e.Extra))
+	for key := range e.Extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	b := bytes.NewBuffer(data[:len(data)-1])
+	for _, key := range keys {
+		name, _ := json.Marshal(key)
+		b.WriteByte(',')
+		b.Write(name)
+		b.WriteByte(':')
+		b.Write(e.Extra[key])
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// State is a feature state file.
+type State struct {
+	SchemaVersion int    `json:"schema_version"`
+	FeatureID     string `json:"feature_id"`
+	Stage         string `json:"stage"`
+	CreatedAt     string `json:"created_at,omitempty"`
+	UpdatedAt     string `json:"updated_at,omitempty"`
+	Branch        string `json:"branch,omitempty"`
+	SpecPath      string `json:"spec_path,omitempty"`
+	PlanPath      string `json:"plan_path,omitempty"`
+	EpicID        string `json:"epic_id,omitempty"`
+	// ClarificationCount is the number of open clarification markers.
+	ClarificationCount int    `json:"clarification_count,omitempty"`
+	ResearchPath       string `json:"research_path,omitempty"`
+	// ResearchReady is nil when the state says nothing about research.
+	ResearchReady     *bool    `json:"research_ready,omitempty"`
+	ResearchArtifacts []string `json:"research_artifacts,omitempty"`
+	History           []Entry  `json:"history"`
+
+	// Extra holds the fields the schema doesn't model, as written.
+	Extra map[string]json.RawMessage `json:"-"`
+
+	// raw holds every field as read, and order their order. A modelled
+	// field is written back from raw while it still encodes as canon, which
+	// it did when it was read.
+	raw, canon map[string]json.RawMessage
+	order      []string
+}
+
+// known lists the modelled fields in the order Save writes them; history
+// comes last, after Extra.
+var known = []string{
+	"schema_version", "feature_id", "stage", "created_at", "updated_at", "branch",
+	"spec_path", "plan_path", "epic_id", "clarification_count",
+	"research_path", "research_ready", "research_artifacts",
+}
+
+// Path returns the state file of the feature in maestroDir.
+func Path(maestroDir, featureID string) string {
+	return filepath.Join(maestroDir, "state", featureID+".json")
+}
+
+// Load reads the state file at path and migrates it to SchemaVersion. A
+// missing file's error wraps os.ErrNotExist.
+func Load(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse decodes a state file and migrates it to SchemaVersion. A syntax
+// error names the line and column.
+func Parse(data []byte) (*State, error) {
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		var syntax *json.SyntaxError
+		if errors.As(err, &syntax) {
+			// Offset counts the offending byte
+			line, col := lineColumn(data, syntax.Offset-1)
+			return nil, fmt.Errorf("corrupt JSON at line %d, column %d: %v", line, col, err)
+		}
+		return nil, err
+	}
+	if s.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("schema version %d: %w; upgrade maestro", s.SchemaVersion, ErrNewerSchema)
+	}
+	s.migrate()
+	return &s, nil
+}
+
+// Validate checks the fields every state file has.
+func (s *State) Validate() error {
+	var missing []string
+	if strings.TrimSpace(s.FeatureID) == "" {
+		missing = append(missing, "feature_id")
+	}
+	if strings.TrimSpace(s.Stage) == "" {
+		missing = append(missing, "stage")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("no %s", strings.Join(missing, " or "))
+	}
+	return nil
+}
+
+// migrate brings a state written with an older schema up to date.
+// Version 0 files may keep research in a research object, with ready,
+// path, and artifacts; version 1 has them at the top level.
+func (s *State) migrate() {
+	if s.SchemaVersion >= 1 {
+		return
+	}
+	var research struct {
+		Ready     flexBool        `json:"ready"`
+		Path      string          `json:"path"`
+		Artifacts json.RawMessage `json:"artifacts"`
+	}
+	if raw, ok := s.Extra["research"]; ok && json.Unmarshal(raw, &research) == nil {
+		if s.ResearchReady == nil && research.Ready.set {
+			ready := research.Ready.value
+			s.ResearchReady = &ready
+		}
+		if s.ResearchPath == "" {
+			s.ResearchPath = strings.TrimSpace(research.Path)
+		}
+		if s.ResearchArtifacts == nil {
+			s.ResearchArtifacts = stringList(research.Artifacts)
+		}
+		delete(s.Extra, "research")
+	}
+	s.SchemaVersion = 1
+}
+
+// HasResearch reports whether the state records research at all; states
+// from before /maestro.research don't.
+func (s *State) HasResearch() bool {
+	if s.ResearchReady != nil || s.ResearchPath != "" || s.ResearchArtifacts != nil {
+		return true
+	}
+	for key := range s.Extra {
+		if strings.HasPrefix(key, "research_") {
+			return true
+		}
+	}
+	return false
+}
+
+// Encode returns the state as indented JSON, as Save writes it.
+func Encode(s *State) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+// Save writes the state to path, through a temporary file so a reader never
+// sees half of it, as indented JSON.
+func Save(path string, s *State) error {
+	s.SchemaVersion = SchemaVersion
+	data, err := Encode(s)
+	if err != nil {
+		return fmt.Errorf("encoding state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".state-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Set sets a field from its JSON value, such as update-state.sh's
+// field=value arguments. The other fields are kept as they are.
+func (s *State) Set(key string, value json.RawMessage) error {
+	data, err := s.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	fields[key] = value
+	if data, err = marshal(fields); err != nil {
+		return err
+	}
+	var updated State
+	if err := json.Unmarshal(data, &updated); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	updated.order = s.order
+	*s = updated
+	return nil
+}
+
+// Transition moves the feature to stage, recording action and actor in
+// its history, after checking the pipeline allows it. A new state starts
+// with created_at set to now.
+func (s *State) Transition(stage, action, actor string, now time.Time) error {
+	return s.TransitionEntry(Entry{Stage: stage, Action: action, Actor: actor}, now)
+}
+
+// Record moves the feature to stage like Transition without checking it.
+func (s *State) Record(stage, action, actor string, now time.Time) {
+	s.RecordEntry(Entry{Stage: stage, Action: action, Actor: actor}, now)
+}
+
+// TransitionEntry is Transition for an entry with more than an action and
+// actor, such as a note.
+func (s *State) TransitionEntry(entry Entry, now time.Time) error {
+	if err := CheckTransition(s.Stage, entry.Stage); err != nil {
+		return err
+	}
+	s.RecordEntry(entry, now)
+	return nil
+}
+
+// RecordEntry is Record for an entry with more than an action and actor.
+// The entry's timestamp is set to now.
+func (s *State) RecordEntry(entry Entry, now time.Time) {
+	at := now.UTC().Format(time.RFC3339)
+	if s.CreatedAt == "" {
+		s.CreatedAt = at
+	}
+	entry.Timestamp = at
+	s.Stage = entry.Stage
+	s.UpdatedAt = at
+	s.History = append(s.History, entry)
+}
+
+// UnmarshalJSON decodes the modelled fields and keeps the rest in Extra.
+// research_ready may be a string, as some commands write it, and
+// research_artifacts entries that are not strings are left out.
+func (s *State) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return errors.New("not a JSON object")
+		}
+		return err
+	}
+	if fields == nil {
+		return errors.New("not a JSON object")
+	}
+	raw := make(map[string]json.RawMessage, len(fields))
+	for key, value := range fields {
+		raw[key] = value
+	}
+	order, err := objectKeys(data)
+	if err != nil {
+		return err
+	}
+
+	*s = State{}
+	decode := func(key string, v interface{}) error {
+		raw, ok := fields[key]
+		delete(fields, key)
+		if !ok || string(raw) == "null" {
+			return nil
+		}
+		if err := json.Unmarshal(raw, v); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		return nil
+	}
+	for key, v := range map[string]interface{}{
+		"schema_version":      &s.SchemaVersion,
+		"feature_id":          &s.FeatureID,
+		"stage":               &s.Stage,
+		"created_at":          &s.CreatedAt,
+		"updated_at":          &s.UpdatedAt,
+		"branch":              &s.Branch,
+		"spec_path":           &s.SpecPath,
+		"plan_path":           &s.PlanPath,
+		"epic_id":             &s.EpicID,
+		"clarification_count": &s.ClarificationCount,
+		"research_path":       &s.ResearchPath,
+		"history":             &s.History,
+	} {
+		if err := decode(key, v); err != nil {
+			return err
+		}
+	}
+	s.ResearchPath = strings.TrimSpace(s.ResearchPath)
+
+	var ready flexBool
+	if err := decode("research_ready", &ready); err != nil {
+		return err
+	}
+	if ready.set {
+		s.ResearchReady = &ready.value
+	}
+	if raw, ok := fields["research_artifacts"]; ok {
+		s.ResearchArtifacts = stringList(raw)
+		delete(fields, "research_artifacts")
+	}
+	if len(fields) > 0 {
+		s.Extra = fields
+	}
+	canon, err := s.modelled()
+	if err != nil {
+		return err
+	}
+	s.raw, s.canon, s.order = raw, canon, order
+	return nil
+}
+
+// MarshalJSON writes the fields of a state that was read in their order,
+// each as it was read unless it changed, with schema_version first and new
+// fields before the history. A new state has the modelled fields in a fixed
+// order, then Extra sorted by key, then the history.
+func (s *State) MarshalJSON() ([]byte, error) {
+	modelled, err := s.modelled()
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	for key, value := range s.Extra {
+		fields[key] = value
+	}
+	for _, key := range append(known, "history") {
+		value, set := modelled[key]
+		if s.raw != nil {
+			canon, wasSet := s.canon[key]
+			if set == wasSet && bytes.Equal(value, canon) {
+				if value, set = s.raw[key]; !set {
+					continue
+				}
+			}
+		}
+		if set {
+			fields[key] = value
+		}
+	}
+
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for _, key := range s.keyOrder(fields) {
+		value := fields[key]
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		b.Write(name)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// keyOrder returns the keys of fields in the order MarshalJSON writes them.
+func (s *State) keyOrder(fields map[string]json.RawMessage) []string {
+	var keys []string
+	seen := map[string]bool{}
+	add := func(key string) {
+		if _, ok := fields[key]; ok && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	read := map[string]bool{}
+	for _, key := range s.order {
+		read[key] = true
+	}
+	added := func() {
+		var extra []string
+		for key := range fields {
+			if !contains(known, key) && key != "history" {
+				extra = append(extra, key)
+			}
+		}
+		sort.Strings(extra)
+		for _, key := range append(append([]string{}, known...), extra...) {
+			if !read[key] {
+				add(key)
+			}
+		}
+	}
+
+	add("schema_version")
+	for _, key := range s.order {
+		if key == "history" {
+			added()
+		}
+		add(key)
+	}
+	added()
+	add("history")
+	return keys
+}
+
+// modelled returns the modelled fields as JSON; one that is empty and
+// omitted when written is missing.
+func (s *State) modelled() (map[string]json.RawMessage, error) {
+	// The alias has no methods, so this encodes the modelled fields
+	type plain State
+	p := plain(*s)
+	if p.History == nil {
+		p.History = []Entry{}
+	}
+	data, err := marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var modelled map[string]json.RawMessage
+	if err := json.Unmarshal(data, &modelled); err != nil {
+		return nil, err
+	}
+	return modelled, nil
+}
+
+// marshal is json.Marshal without escaping <, >, and &, as jq writes them.
+func marshal(v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+// objectKeys returns the keys of the JSON object in data, in order.
+func objectKeys(data []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	var keys []string
+	seen := map[string]bool{}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		if key := token.(string); !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// flexBool decodes true, false, or a string that is "true" in any case.
+type flexBool struct {
+	value, set bool
+}
+
+func (f *flexBool) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case bool:
+		f.value, f.set = v, true
+	case string:
+		f.value, f.set = strings.EqualFold(strings.TrimSpace(v), "true"), true
+	case nil:
+	default:
+		// Anything else is set, but not true
+		f.set = true
+	}
+	return nil
+}
+
+// stringList decodes a JSON array, keeping its non-empty strings trimmed.
+// It returns nil when raw is not an array.
+func stringList(raw json.RawMessage) []string {
+	var items []interface{}
+	if json.Unmarshal(raw, &items) != nil || items == nil {
+		return nil
+	}
+	list := []string{}
+	for _, item := range items {
+		if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+			list = append(list, strings.TrimSpace(s))
+		}
+	}
+	return list
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// lineColumn returns the 1-based line and column of offset in data.
+func lineColumn(data []byte, offset int64) (int, int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	return line, int(offset) - bytes.LastIndexByte(before, '\n')
+}
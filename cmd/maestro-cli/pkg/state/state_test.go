@@ -0,0 +1,195 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadMigratesAndSaveKeepsUnknownFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "001-auth.json")
+	os.MkdirAll(filepath.Dir(path), 0755)
+	os.WriteFile(path, []byte(`{
+  "feature_id": "001-auth",
+  "stage": "research",
+  "user_stories": 5,
+  "research": {"ready": "TRUE", "path": " .maestro/specs/001-auth/research ", "artifacts": ["a.md", 3, ""]},
+  "history": [{"stage": "specify", "timestamp": "2026-03-01T09:00:00Z", "action": "created", "note": "3 stories", "source": "fork"}]
+}`), 0644)
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if s.SchemaVersion != SchemaVersion || s.FeatureID != "001-auth" || s.Stage != "research" {
+		t.Errorf("Load() = %+v", s)
+	}
+	if s.ResearchReady == nil || !*s.ResearchReady || s.ResearchPath != ".maestro/specs/001-auth/research" {
+		t.Errorf("research object not migrated: ready %v, path %q", s.ResearchReady, s.ResearchPath)
+	}
+	if strings.Join(s.ResearchArtifacts, ",") != "a.md" || !s.HasResearch() {
+		t.Errorf("research_artifacts = %q", s.ResearchArtifacts)
+	}
+	if _, ok := s.Extra["research"]; ok {
+		t.Error("the research object should be replaced by the top-level fields")
+	}
+
+	if err := s.Transition("plan", "plan generated", "ci", time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Transition() error: %v", err)
+	}
+	if err := Save(path, s); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	var saved map[string]interface{}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("saved state is not JSON: %v\n%s", err, data)
+	}
+	if saved["user_stories"] != 5.0 || saved["schema_version"] != 1.0 || saved["updated_at"] != "2026-03-02T10:00:00Z" {
+		t.Errorf("saved state = %s", data)
+	}
+	if !strings.HasPrefix(string(data), "{\n  \"schema_version\": 1,\n  \"feature_id\"") {
+		t.Errorf("saved state should start with the schema version and ID:\n%s", data)
+	}
+	history := saved["history"].([]interface{})
+	if first := history[0].(map[string]interface{}); first["note"] != "3 stories" || first["source"] != "fork" {
+		t.Errorf("history entry fields lost: %v", first)
+	}
+	if len(history) != 2 || history[1].(map[string]interface{})["actor"] != "ci" {
+		t.Errorf("history = %v", history)
+	}
+	if s.CreatedAt != "2026-03-02T10:00:00Z" {
+		t.Errorf("created_at = %q, want it set on the first save without one", s.CreatedAt)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse([]byte("{\n  \"stage\": \"plan\",\n}")); err == nil || !strings.Contains(err.Error(), "line 3, column 1") {
+		t.Errorf("trailing comma: error = %v, want its line and column", err)
+	}
+	if _, err := Parse([]byte(`["plan"]`)); err == nil || err.Error() != "not a JSON object" {
+		t.Errorf("array: error = %v", err)
+	}
+	if _, err := Parse([]byte(`{"schema_version": 2}`)); !errors.Is(err, ErrNewerSchema) {
+		t.Errorf("newer schema: error = %v, want ErrNewerSchema", err)
+	}
+	s, err := Parse([]byte(`{"stage": "plan"}`))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if err := s.Validate(); err == nil || err.Error() != "no feature_id" {
+		t.Errorf("Validate() = %v, want no feature_id", err)
+	}
+	if err := s.Set("feature_id", json.RawMessage(`"001-auth"`)); err != nil || s.Validate() != nil {
+		t.Errorf("Set(feature_id) = %v, then Validate() = %v", err, s.Validate())
+	}
+	if err := s.Set("history", json.RawMessage(`"none"`)); err == nil {
+		t.Error("Set(history) to a string should fail")
+	}
+}
+
+func TestCheckTransition(t *testing.T) {
+	for _, tc := range []struct {
+		from, to string
+		ok       bool
+	}{
+		{"", "specify", true},
+		{"", "plan", false},
+		{"specify", "specify", true},
+		{"specify", "clarify", true},
+		{"specify", "plan", true},
+		{"clarify", "plan", true},
+		{"specify", "tasks", false},
+		{"plan", "implement", false},
+		{"tasks", "implement", true},
+		{"implement", "complete", true},
+		{"tasks", "analyze", true},
+		{"analyze", "implement", true},
+		{"plan", "analyze", false},
+		{"implement", "plan", true},
+		{"plan", "cancelled", true},
+		{"cancelled", "plan", false},
+		{"legacy", "tasks", true},
+		{"plan", "shipping", false},
+	} {
+		err := CheckTransition(tc.from, tc.to)
+		if (err == nil) != tc.ok {
+			t.Errorf("CheckTransition(%q, %q) = %v, want ok %v", tc.from, tc.to, err, tc.ok)
+		}
+		if err != nil && !errors.Is(err, ErrTransition) {
+			t.Errorf("CheckTransition(%q, %q) = %v, want ErrTransition", tc.from, tc.to, err)
+		}
+	}
+}
+
+// TestSaveKeepsFilesAsWritten reads the repository's state files and the
+// script fixtures, saves each unchanged, and expects the same bytes in
+// Save's indentation: every key in its place and every value as written,
+// with schema_version added first to a file without one.
+func TestSaveKeepsFilesAsWritten(t *testing.T) {
+	root := filepath.Join("..", "..", "..", "..")
+	var paths []string
+	for _, dir := range []string{".maestro/state", ".maestro/scripts/test/fixtures/list-features/state"} {
+		matches, _ := filepath.Glob(filepath.Join(root, dir, "*.json"))
+		paths = append(paths, matches...)
+	}
+	if len(paths) == 0 {
+		t.Skip("no state files in the repository")
+	}
+	for _, path := range paths {
+		data, _ := os.ReadFile(path)
+		s, err := Parse(data)
+		if err != nil {
+			continue
+		}
+		var indented bytes.Buffer
+		json.Indent(&indented, bytes.TrimSpace(data), "", "  ")
+		want := indented.String() + "\n"
+		if !strings.Contains(want, `"schema_version"`) {
+			want = strings.Replace(want, "{\n  ", "{\n  \"schema_version\": 1,\n  ", 1)
+		}
+		out := filepath.Join(t.TempDir(), filepath.Base(path))
+		if err := Save(out, s); err != nil {
+			t.Fatalf("Save(%s) error: %v", path, err)
+		}
+		if got, _ := os.ReadFile(out); string(got) != want {
+			t.Errorf("%s changed on save:\n%s", path, got)
+		}
+	}
+}
+
+// TestSaveKeepsLooseValues tests that values the schema reads loosely are
+// written back as read, and changed fields stay in place.
+func TestSaveKeepsLooseValues(t *testing.T) {
+	s, err := Parse([]byte(`{"feature_id": "001-auth", "stage": "tasks", "research_ready": "yes",
+  "research_artifacts": ["a.md", 3], "research_path": " r ", "clarification_count": 0, "note": "<b> & c",
+  "history": [{"stage": "tasks", "timestamp": "2026-03-01T09:00:00Z", "action": ""}], "epic_id": "e-1"}`))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if err := s.Transition("analyze", "analyzed", "", time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Transition() error: %v", err)
+	}
+	if err := s.Set("user_stories", json.RawMessage(`5`)); err != nil {
+		t.Fatalf("Set() error: %v", err)
+	}
+	data, err := Encode(s)
+	if err != nil {
+		t.Fatalf("Encode() error: %v", err)
+	}
+	var compact bytes.Buffer
+	json.Compact(&compact, data)
+	want := `{"schema_version":1,"feature_id":"001-auth","stage":"analyze","research_ready":"yes",` +
+		`"research_artifacts":["a.md",3],"research_path":" r ","clarification_count":0,"note":"<b> & c",` +
+		`"created_at":"2026-03-02T10:00:00Z","updated_at":"2026-03-02T10:00:00Z","user_stories":5,` +
+		`"history":[{"stage":"tasks","timestamp":"2026-03-01T09:00:00Z","action":""},` +
+		`{"stage":"analyze","timestamp":"2026-03-02T10:00:00Z","action":"analyzed"}],"epic_id":"e-1"}`
+	if compact.String() != want {
+		t.Errorf("saved state:\n got %s\nwant %s", compact.String(), want)
+	}
+}
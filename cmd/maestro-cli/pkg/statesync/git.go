@@ -0,0 +1,150 @@
+package statesync
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+)
+
+// GitBackend stores state on a dedicated branch of a git remote, under a
+// directory named after the project fingerprint. It uses plumbing commands
+// only, so the working tree, index, and current branch are never touched.
+type GitBackend struct {
+	RepoDir string // repository used to run git (the project root)
+	Remote  string // e.g. "origin"
+	Branch  string // e.g. "maestro-state"
+}
+
+// Name implements Backend.
+func (g *GitBackend) Name() string {
+	return fmt.Sprintf("git %s/%s", g.Remote, g.Branch)
+}
+
+// trackingRef is the local ref the remote branch is fetched into.
+func (g *GitBackend) trackingRef() string {
+	return "refs/maestro/sync/" + g.Branch
+}
+
+// fetch updates the tracking ref and reports whether the remote branch exists.
+func (g *GitBackend) fetch() (bool, error) {
+	out, err := g.git(nil, nil, "ls-remote", "--heads", g.Remote, g.Branch)
+	if err != nil {
+		return false, err
+	}
+	if strings.TrimSpace(out) == "" {
+		return false, nil
+	}
+	if _, err := g.git(nil, nil, "fetch", "--quiet", "--no-tags", g.Remote, "+refs/heads/"+g.Branch+":"+g.trackingRef()); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Pull implements Backend.
+func (g *GitBackend) Pull(fingerprint string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	exists, err := g.fetch()
+	if err != nil || !exists {
+		return files, err
+	}
+
+	out, err := g.git(nil, nil, "ls-tree", "--name-only", g.trackingRef(), fingerprint+"/")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range strings.Fields(out) {
+		content, err := g.git(nil, nil, "show", g.trackingRef()+":"+entry)
+		if err != nil {
+			return nil, err
+		}
+		files[path.Base(entry)] = []byte(content)
+	}
+	return files, nil
+}
+
+// Push implements Backend. The new commit is based on the state seen by the
+// last Pull, so the remote rejects it if someone else updated the branch in
+// between.
+func (g *GitBackend) Push(fingerprint string, files map[string][]byte) error {
+	_, err := g.git(nil, nil, "rev-parse", "--verify", "--quiet", g.trackingRef())
+	exists := err == nil
+
+	index, err := os.CreateTemp("", "maestro-sync-index-")
+	if err != nil {
+		return fmt.Errorf("creating temporary index: %w", err)
+	}
+	index.Close()
+	os.Remove(index.Name()) // git creates it; an empty file is not a valid index
+	defer os.Remove(index.Name())
+	env := []string{"GIT_INDEX_FILE=" + index.Name()}
+
+	if exists {
+		if _, err := g.git(env, nil, "read-tree", g.trackingRef()); err != nil {
+			return err
+		}
+		// Replace this project's directory wholesale.
+		if _, err := g.git(env, nil, "rm", "-r", "--cached", "--quiet", "--ignore-unmatch", fingerprint); err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sha, err := g.git(nil, files[name], "hash-object", "-w", "--stdin")
+		if err != nil {
+			return err
+		}
+		info := fmt.Sprintf("100644,%s,%s/%s", strings.TrimSpace(sha), fingerprint, name)
+		if _, err := g.git(env, nil, "update-index", "--add", "--cacheinfo", info); err != nil {
+			return err
+		}
+	}
+
+	tree, err := g.git(env, nil, "write-tree")
+	if err != nil {
+		return err
+	}
+	args := []string{"commit-tree", strings.TrimSpace(tree), "-m", "maestro: sync state for " + fingerprint}
+	if exists {
+		args = append(args, "-p", g.trackingRef())
+	}
+	commit, err := g.git(nil, nil, args...)
+	if err != nil {
+		return err
+	}
+	commit = strings.TrimSpace(commit)
+
+	if _, err := g.git(nil, nil, "push", "--quiet", g.Remote, commit+":refs/heads/"+g.Branch); err != nil {
+		return fmt.Errorf("%w (the remote branch may have moved; run 'maestro sync pull' and retry)", err)
+	}
+	_, err = g.git(nil, nil, "update-ref", g.trackingRef(), commit)
+	return err
+}
+
+// git runs a git command in the repository. Commits are made with a fixed
+// identity so sync commits stay anonymous and work without user config.
+func (g *GitBackend) git(env []string, stdin []byte, args ...string) (string, error) {
+	full := append([]string{"-c", "user.name=maestro", "-c", "user.email=maestro@localhost"}, args...)
+	cmd := exec.Command("git", full...)
+	cmd.Dir = g.RepoDir
+	cmd.Env = append(os.Environ(), env...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
@@ -0,0 +1,253 @@
+// Package statesync shares .maestro/state/ between machines working on the
+// same repository. Files are keyed by an anonymous project fingerprint so a
+// shared backend can hold state for several projects without naming them.
+package statesync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Backend stores state snapshots for a project fingerprint.
+type Backend interface {
+	// Name describes the backend for messages, e.g. "git origin/maestro-state".
+	Name() string
+	// Pull returns the remote files for the fingerprint, keyed by file name.
+	Pull(fingerprint string) (map[string][]byte, error)
+	// Push replaces the remote files for the fingerprint.
+	Push(fingerprint string, files map[string][]byte) error
+}
+
+// Fingerprint returns an anonymous, stable identifier for the repository in
+// dir, derived from its root commit. It is the same on every clone and
+// reveals nothing about the project name or remote.
+func Fingerprint(dir string) (string, error) {
+	cmd := exec.Command("git", "rev-list", "--max-parents=0", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("reading root commit (is this a git repository with commits?): %w", err)
+	}
+
+	roots := strings.Fields(string(out))
+	if len(roots) == 0 {
+		return "", fmt.Errorf("repository has no root commit")
+	}
+	sort.Strings(roots)
+
+	sum := sha256.Sum256([]byte("maestro-project:" + roots[0]))
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// Result summarizes a pull or push.
+type Result struct {
+	Updated   []string // files written locally (pull) or remotely (push)
+	Unchanged int
+	Conflicts []string // files changed on both sides since the last sync
+}
+
+// syncBase is the content of the base file: the file hashes as of the last
+// successful sync, the common ancestor used to tell local edits from remote
+// ones.
+type syncBase struct {
+	Fingerprint string            `json:"fingerprint"`
+	Files       map[string]string `json:"files"`
+}
+
+// Syncer reconciles a local state directory with a backend.
+type Syncer struct {
+	StateDir string
+	// BasePath is the file recording what was last synced. It belongs to
+	// this machine, so it lives outside StateDir, where it would be synced
+	// and read as a feature's state.
+	BasePath    string
+	Fingerprint string
+	Backend     Backend
+}
+
+// Pull applies remote changes to the local state directory. Files changed
+// both locally and remotely since the last sync are reported as conflicts
+// and left untouched unless force is set, in which case the remote wins.
+func (s *Syncer) Pull(force bool) (*Result, error) {
+	remote, err := s.Backend.Pull(s.Fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("pulling from %s: %w", s.Backend.Name(), err)
+	}
+	local, err := s.readLocal()
+	if err != nil {
+		return nil, err
+	}
+	base, err := s.loadBase()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	for _, name := range sortedKeys(remote) {
+		localHash, remoteHash := hashOf(local[name]), hashOf(remote[name])
+		switch {
+		case local[name] != nil && localHash == remoteHash:
+			result.Unchanged++
+			base.Files[name] = remoteHash
+			continue
+		case local[name] != nil && localHash != base.Files[name] && remoteHash != base.Files[name] && !force:
+			result.Conflicts = append(result.Conflicts, name)
+			continue
+		case local[name] != nil && remoteHash == base.Files[name] && !force:
+			// Only the local copy changed; it wins until pushed.
+			result.Unchanged++
+			continue
+		}
+
+		if err := os.WriteFile(filepath.Join(s.StateDir, name), remote[name], 0644); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", name, err)
+		}
+		base.Files[name] = remoteHash
+		result.Updated = append(result.Updated, name)
+	}
+
+	if err := s.saveBase(base); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Push uploads local state. It refuses when a remote file changed since the
+// last sync and differs from the local copy, unless force is set, so one
+// machine cannot silently overwrite another's progress.
+func (s *Syncer) Push(force bool) (*Result, error) {
+	remote, err := s.Backend.Pull(s.Fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", s.Backend.Name(), err)
+	}
+	local, err := s.readLocal()
+	if err != nil {
+		return nil, err
+	}
+	base, err := s.loadBase()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	merged := make(map[string][]byte, len(remote)+len(local))
+	for name, content := range remote {
+		merged[name] = content
+	}
+	synced := []string{}
+	for _, name := range sortedKeys(local) {
+		localHash := hashOf(local[name])
+		remoteContent, onRemote := remote[name]
+		remoteHash := hashOf(remoteContent)
+
+		switch {
+		case onRemote && remoteHash == localHash:
+			result.Unchanged++
+			synced = append(synced, name)
+			continue
+		case onRemote && localHash == base.Files[name] && !force:
+			// Only the remote changed; 'pull' picks it up.
+			result.Unchanged++
+			continue
+		case onRemote && remoteHash != base.Files[name] && !force:
+			result.Conflicts = append(result.Conflicts, name)
+			continue
+		}
+		merged[name] = local[name]
+		synced = append(synced, name)
+		result.Updated = append(result.Updated, name)
+	}
+
+	if len(result.Conflicts) > 0 {
+		return result, nil
+	}
+	if len(result.Updated) > 0 {
+		if err := s.Backend.Push(s.Fingerprint, merged); err != nil {
+			return nil, fmt.Errorf("pushing to %s: %w", s.Backend.Name(), err)
+		}
+	}
+
+	for _, name := range synced {
+		base.Files[name] = hashOf(local[name])
+	}
+	if err := s.saveBase(base); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// readLocal returns the JSON state files in the state directory.
+func (s *Syncer) readLocal() (map[string][]byte, error) {
+	paths, err := filepath.Glob(filepath.Join(s.StateDir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("listing state files: %w", err)
+	}
+
+	files := make(map[string][]byte, len(paths))
+	for _, path := range paths {
+		name := filepath.Base(path)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+		files[name] = content
+	}
+	return files, nil
+}
+
+func (s *Syncer) loadBase() (*syncBase, error) {
+	base := &syncBase{Fingerprint: s.Fingerprint, Files: map[string]string{}}
+	data, err := os.ReadFile(s.BasePath)
+	if os.IsNotExist(err) {
+		return base, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading sync base: %w", err)
+	}
+	if err := json.Unmarshal(data, base); err != nil {
+		return nil, fmt.Errorf("parsing sync base: %w", err)
+	}
+	if base.Fingerprint != s.Fingerprint || base.Files == nil {
+		// State synced for another project (e.g. copied directory): start over.
+		return &syncBase{Fingerprint: s.Fingerprint, Files: map[string]string{}}, nil
+	}
+	return base, nil
+}
+
+func (s *Syncer) saveBase(base *syncBase) error {
+	data, err := json.MarshalIndent(base, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding sync base: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.BasePath), 0755); err != nil {
+		return fmt.Errorf("writing sync base: %w", err)
+	}
+	if err := os.WriteFile(s.BasePath, data, 0644); err != nil {
+		return fmt.Errorf("writing sync base: %w", err)
+	}
+	return nil
+}
+
+func hashOf(content []byte) string {
+	if content == nil {
+		return ""
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func sortedKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,212 @@
+package statesync
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+// setupClones creates a bare remote and two clones of a one-commit project.
+func setupClones(t *testing.T) (string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	runGit(t, root, "init", "--quiet", "--bare", "--initial-branch=main", remote)
+
+	seed := filepath.Join(root, "seed")
+	runGit(t, root, "init", "--quiet", seed)
+	os.WriteFile(filepath.Join(seed, "README.md"), []byte("project\n"), 0644)
+	runGit(t, seed, "add", ".")
+	runGit(t, seed, "commit", "--quiet", "-m", "initial")
+	runGit(t, seed, "push", "--quiet", remote, "HEAD:refs/heads/main")
+
+	a := filepath.Join(root, "a")
+	b := filepath.Join(root, "b")
+	runGit(t, root, "clone", "--quiet", remote, a)
+	runGit(t, root, "clone", "--quiet", remote, b)
+	return a, b
+}
+
+func newTestSyncer(t *testing.T, repo string) *Syncer {
+	t.Helper()
+	fp, err := Fingerprint(repo)
+	if err != nil {
+		t.Fatalf("Fingerprint: %v", err)
+	}
+	stateDir := filepath.Join(repo, ".maestro", "state")
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return &Syncer{
+		StateDir:    stateDir,
+		BasePath:    filepath.Join(repo, ".maestro", "cache", "sync.json"),
+		Fingerprint: fp,
+		Backend:     &GitBackend{RepoDir: repo, Remote: "origin", Branch: "maestro-state"},
+	}
+}
+
+func TestFingerprintStableAcrossClones(t *testing.T) {
+	a, b := setupClones(t)
+
+	fpA, err := Fingerprint(a)
+	if err != nil {
+		t.Fatalf("Fingerprint(a): %v", err)
+	}
+	fpB, err := Fingerprint(b)
+	if err != nil {
+		t.Fatalf("Fingerprint(b): %v", err)
+	}
+	if fpA != fpB {
+		t.Errorf("fingerprints differ: %s vs %s", fpA, fpB)
+	}
+	if len(fpA) != 16 {
+		t.Errorf("fingerprint %q should be 16 hex characters", fpA)
+	}
+}
+
+func TestFingerprintRequiresCommits(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	runGit(t, dir, "init", "--quiet")
+
+	if _, err := Fingerprint(dir); err == nil {
+		t.Error("expected error for repository without commits")
+	}
+}
+
+func TestPushPullRoundTrip(t *testing.T) {
+	a, b := setupClones(t)
+	sa, sb := newTestSyncer(t, a), newTestSyncer(t, b)
+
+	os.WriteFile(filepath.Join(sa.StateDir, "001-feature.json"), []byte(`{"stage":"plan"}`), 0644)
+	result, err := sa.Push(false)
+	if err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if len(result.Updated) != 1 {
+		t.Fatalf("expected 1 pushed file, got %v", result.Updated)
+	}
+
+	result, err = sb.Pull(false)
+	if err != nil {
+		t.Fatalf("Pull: %v", err)
+	}
+	if len(result.Updated) != 1 {
+		t.Fatalf("expected 1 pulled file, got %v", result.Updated)
+	}
+	got, err := os.ReadFile(filepath.Join(sb.StateDir, "001-feature.json"))
+	if err != nil || string(got) != `{"stage":"plan"}` {
+		t.Errorf("pulled content = %q, %v", got, err)
+	}
+
+	// The working tree and current branch must be untouched.
+	if _, err := os.Stat(filepath.Join(b, sb.Fingerprint)); !os.IsNotExist(err) {
+		t.Error("pull should not write the sync branch into the working tree")
+	}
+
+	// A second pull is a no-op.
+	result, err = sb.Pull(false)
+	if err != nil {
+		t.Fatalf("second Pull: %v", err)
+	}
+	if len(result.Updated) != 0 || result.Unchanged != 1 {
+		t.Errorf("second pull: %+v", result)
+	}
+}
+
+func TestConflictDetection(t *testing.T) {
+	a, b := setupClones(t)
+	sa, sb := newTestSyncer(t, a), newTestSyncer(t, b)
+	name := "001-feature.json"
+
+	os.WriteFile(filepath.Join(sa.StateDir, name), []byte(`{"stage":"plan"}`), 0644)
+	if _, err := sa.Push(false); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if _, err := sb.Pull(false); err != nil {
+		t.Fatalf("Pull: %v", err)
+	}
+
+	// Both machines advance the same feature.
+	os.WriteFile(filepath.Join(sa.StateDir, name), []byte(`{"stage":"tasks"}`), 0644)
+	if _, err := sa.Push(false); err != nil {
+		t.Fatalf("Push a: %v", err)
+	}
+	os.WriteFile(filepath.Join(sb.StateDir, name), []byte(`{"stage":"implement"}`), 0644)
+
+	result, err := sb.Push(false)
+	if err != nil {
+		t.Fatalf("Push b: %v", err)
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0] != name {
+		t.Fatalf("expected conflict on push, got %+v", result)
+	}
+
+	result, err = sb.Pull(false)
+	if err != nil {
+		t.Fatalf("Pull b: %v", err)
+	}
+	if len(result.Conflicts) != 1 {
+		t.Fatalf("expected conflict on pull, got %+v", result)
+	}
+	got, _ := os.ReadFile(filepath.Join(sb.StateDir, name))
+	if string(got) != `{"stage":"implement"}` {
+		t.Errorf("conflicting local file was overwritten: %s", got)
+	}
+
+	// --force lets the remote win.
+	if _, err := sb.Pull(true); err != nil {
+		t.Fatalf("forced Pull: %v", err)
+	}
+	got, _ = os.ReadFile(filepath.Join(sb.StateDir, name))
+	if string(got) != `{"stage":"tasks"}` {
+		t.Errorf("forced pull content = %s", got)
+	}
+}
+
+func TestPullKeepsLocalOnlyChanges(t *testing.T) {
+	a, b := setupClones(t)
+	sa, sb := newTestSyncer(t, a), newTestSyncer(t, b)
+	name := "001-feature.json"
+
+	os.WriteFile(filepath.Join(sa.StateDir, name), []byte(`{"stage":"plan"}`), 0644)
+	if _, err := sa.Push(false); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if _, err := sb.Pull(false); err != nil {
+		t.Fatalf("Pull: %v", err)
+	}
+
+	os.WriteFile(filepath.Join(sb.StateDir, name), []byte(`{"stage":"tasks"}`), 0644)
+	result, err := sb.Pull(false)
+	if err != nil {
+		t.Fatalf("Pull: %v", err)
+	}
+	if len(result.Conflicts) != 0 || len(result.Updated) != 0 {
+		t.Errorf("local-only change should be kept without conflict: %+v", result)
+	}
+	got, _ := os.ReadFile(filepath.Join(sb.StateDir, name))
+	if string(got) != `{"stage":"tasks"}` {
+		t.Errorf("local change lost: %s", got)
+	}
+}
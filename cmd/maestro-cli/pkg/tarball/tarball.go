@@ -0,0 +1,124 @@
+// Package tarball writes the tar.gz archives maestro keeps of project
+// files, guard and feature snapshots and remove's backups, named by the
+// time they were taken, and the offline asset bundles it builds. They are
+// read back through safepath.
+package tarball
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// TimeFormat stamps archive names; it sorts in time order.
+const TimeFormat = "20060102-150405"
+
+// Create writes paths, relative to root, to a new archive in dir named
+// prefix, the time now, and ".tar.gz", and returns its path. Names have
+// one-second resolution; a second archive within the same second takes
+// the next free one so the order is kept. The archive is written to a
+// temporary file and renamed into place, so a partial one is never left
+// under its name.
+func Create(dir, prefix, root string, paths []string, now time.Time) (string, error) {
+	name := filepath.Join(dir, prefix+now.Format(TimeFormat)+".tar.gz")
+	for _, err := os.Stat(name); err == nil; _, err = os.Stat(name) {
+		now = now.Add(time.Second)
+		name = filepath.Join(dir, prefix+now.Format(TimeFormat)+".tar.gz")
+	}
+
+	tmp, err := os.CreateTemp(dir, ".tarball-*")
+	if err != nil {
+		return "", fmt.Errorf("creating archive: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := Write(tmp, root, paths, now); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("writing archive: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("writing archive: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return "", fmt.Errorf("saving archive: %w", err)
+	}
+	return name, nil
+}
+
+// Write writes paths, slash-separated and relative to root, to w as a
+// tar.gz stamped with now. Directories are written with everything under
+// them, and files keep their permissions so scripts stay executable when
+// restored; links and other special files are left out.
+func Write(w io.Writer, root string, paths []string, now time.Time) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, p := range paths {
+		err := filepath.Walk(filepath.Join(root, filepath.FromSlash(p)), func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && !info.Mode().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(root, file)
+			if err != nil {
+				return err
+			}
+			hdr := &tar.Header{Name: filepath.ToSlash(rel), Typeflag: tar.TypeReg, Mode: int64(info.Mode().Perm()), Size: info.Size(), ModTime: now}
+			if info.IsDir() {
+				hdr.Name += "/"
+				hdr.Typeflag, hdr.Size = tar.TypeDir, 0
+			}
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = io.CopyN(tw, f, info.Size())
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// WriteFiles writes files, keyed by slash-separated path, to w as a tar.gz
+// of regular files stamped with now, in path order.
+func WriteFiles(w io.Writer, files map[string][]byte, now time.Time) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, name := range names {
+		hdr := &tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(files[name])), ModTime: now}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
@@ -0,0 +1,57 @@
+package tarball
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spec-maestro/maestro-cli/pkg/safepath"
+)
+
+func TestCreate(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, ".maestro/scripts"), 0755)
+	os.WriteFile(filepath.Join(root, ".maestro/scripts/run.sh"), []byte("#!/bin/sh\n"), 0755)
+	os.WriteFile(filepath.Join(root, "AGENTS.md"), []byte("agents\n"), 0644)
+	os.Symlink("AGENTS.md", filepath.Join(root, "link.md"))
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	name, err := Create(root, "backup-", root, []string{".maestro", "AGENTS.md", "link.md"}, now)
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	if filepath.Base(name) != "backup-20260301-120000.tar.gz" {
+		t.Errorf("Create() = %s, want it named by the time", name)
+	}
+	if second, err := Create(root, "backup-", root, []string{"AGENTS.md"}, now); err != nil || filepath.Base(second) != "backup-20260301-120001.tar.gz" {
+		t.Errorf("a second archive in the same second should take the next one, got %s, %v", second, err)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(root, ".tarball-*")); len(leftovers) > 0 {
+		t.Errorf("temporary files left behind: %v", leftovers)
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modes := make(map[string]os.FileMode)
+	err = safepath.WalkTar(gz, func(e safepath.Entry) error {
+		modes[e.Name] = e.Mode
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("reading the archive: %v", err)
+	}
+	want := map[string]os.FileMode{".maestro": 0755, ".maestro/scripts": 0755, ".maestro/scripts/run.sh": 0755, "AGENTS.md": 0644}
+	if !reflect.DeepEqual(modes, want) {
+		t.Errorf("archive holds %v, want %v", modes, want)
+	}
+}
@@ -0,0 +1,180 @@
+package templates
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SampleData holds preview values for the variables used by the starter
+// templates in .maestro/templates/. Variables are written as {UPPER_CASE};
+// free-text placeholders such as {one sentence} are guidance for agents and
+// are left untouched.
+var SampleData = map[string]string{
+	"ACTION":        "export a report",
+	"AUTHOR":        "Jane Doe",
+	"BENEFIT":       "I can share results with my team",
+	"DATE":          "2025-01-01",
+	"FEATURE_ID":    "001-sample-feature",
+	"FEATURE_TITLE": "Sample Feature",
+	"METHOD":        "codebase search",
+	"PROJECT_NAME":  "sample-project",
+	"ROLE":          "project maintainer",
+	"SHORT_NAME":    "sample-feature",
+	"SPEC_PATH":     ".maestro/specs/001-sample-feature/spec.md",
+}
+
+var variablePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// Issue is a problem found while linting a template.
+type Issue struct {
+	Line    int
+	Message string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("line %d: %s", i.Line, i.Message)
+}
+
+// placeholder is a {...} span found on a template line.
+type placeholder struct {
+	start, end int // byte offsets of '{' and '}' within the line
+	name       string
+}
+
+// Lint checks a template for unbalanced braces and for {UPPER_CASE}
+// variables that have no value in vars. Fenced code blocks and inline code
+// spans are ignored.
+func Lint(content []byte, vars map[string]string) []Issue {
+	_, issues := process(content, vars, false)
+	return issues
+}
+
+// Render replaces every {UPPER_CASE} variable with its value from vars and
+// returns the result along with any lint issues. Variables without a value
+// are left in place.
+func Render(content []byte, vars map[string]string) (string, []Issue) {
+	return process(content, vars, true)
+}
+
+// Variables returns the sorted, de-duplicated {UPPER_CASE} variable names
+// used by a template.
+func Variables(content []byte) []string {
+	seen := make(map[string]bool)
+	forEachLine(content, func(_ int, line string, inCode bool) string {
+		if inCode {
+			return line
+		}
+		placeholders, _ := scanLine(line)
+		for _, p := range placeholders {
+			if variablePattern.MatchString(p.name) {
+				seen[p.name] = true
+			}
+		}
+		return line
+	})
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func process(content []byte, vars map[string]string, render bool) (string, []Issue) {
+	var issues []Issue
+	out := forEachLine(content, func(lineNo int, line string, inCode bool) string {
+		if inCode {
+			return line
+		}
+
+		placeholders, problems := scanLine(line)
+		for _, problem := range problems {
+			issues = append(issues, Issue{Line: lineNo, Message: problem})
+		}
+
+		var b strings.Builder
+		last := 0
+		for _, p := range placeholders {
+			if !variablePattern.MatchString(p.name) {
+				continue
+			}
+			value, ok := vars[p.name]
+			if !ok {
+				issues = append(issues, Issue{Line: lineNo, Message: fmt.Sprintf("unknown variable {%s}", p.name)})
+				continue
+			}
+			b.WriteString(line[last:p.start])
+			b.WriteString(value)
+			last = p.end + 1
+		}
+		if !render {
+			return line
+		}
+		b.WriteString(line[last:])
+		return b.String()
+	})
+	return out, issues
+}
+
+// forEachLine calls fn for every line, reporting whether the line is inside a
+// fenced code block, and joins the returned lines.
+func forEachLine(content []byte, fn func(lineNo int, line string, inCode bool) string) string {
+	lines := strings.Split(string(content), "\n")
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		lines[i] = fn(i+1, line, inFence)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// scanLine finds {...} placeholders on a line outside inline code spans and
+// reports unbalanced or nested braces.
+func scanLine(line string) ([]placeholder, []string) {
+	var placeholders []placeholder
+	var problems []string
+	open := -1
+	inCode := false
+
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '`':
+			inCode = !inCode
+		case '{':
+			if inCode {
+				continue
+			}
+			if open >= 0 {
+				problems = append(problems, fmt.Sprintf("nested '{' at column %d", i+1))
+				continue
+			}
+			open = i
+		case '}':
+			if inCode {
+				continue
+			}
+			if open < 0 {
+				problems = append(problems, fmt.Sprintf("unmatched '}' at column %d", i+1))
+				continue
+			}
+			name := line[open+1 : i]
+			if strings.TrimSpace(name) == "" {
+				problems = append(problems, fmt.Sprintf("empty placeholder at column %d", open+1))
+			} else {
+				placeholders = append(placeholders, placeholder{start: open, end: i, name: name})
+			}
+			open = -1
+		}
+	}
+	if open >= 0 {
+		problems = append(problems, fmt.Sprintf("unclosed '{' at column %d", open+1))
+	}
+	return placeholders, problems
+}
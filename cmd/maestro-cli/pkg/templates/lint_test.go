@@ -0,0 +1,54 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLintReportsBraceErrors(t *testing.T) {
+	content := []byte("# {FEATURE_TITLE}\n{unclosed guidance\nstray } brace\n{outer {inner}}\n")
+
+	issues := Lint(content, SampleData)
+	var messages []string
+	for _, issue := range issues {
+		messages = append(messages, issue.String())
+	}
+	joined := strings.Join(messages, "\n")
+
+	for _, want := range []string{"line 2: unclosed '{'", "line 3: unmatched '}'", "line 4: nested '{'"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected issue %q, got:\n%s", want, joined)
+		}
+	}
+}
+
+func TestLintReportsUnknownVariables(t *testing.T) {
+	issues := Lint([]byte("**Spec ID:** {FEATUER_ID}\n"), SampleData)
+	if len(issues) != 1 || !strings.Contains(issues[0].Message, "{FEATUER_ID}") {
+		t.Fatalf("expected unknown variable issue, got %v", issues)
+	}
+}
+
+func TestLintIgnoresCode(t *testing.T) {
+	content := []byte("Use `map[string]{}` here\n```go\nfunc f() {\n```\n")
+	if issues := Lint(content, SampleData); len(issues) != 0 {
+		t.Errorf("code should not be linted, got %v", issues)
+	}
+}
+
+func TestRenderReplacesVariablesOnly(t *testing.T) {
+	out, issues := Render([]byte("# {FEATURE_TITLE}\n{Describe the problem}\n"), map[string]string{"FEATURE_TITLE": "Export"})
+	if len(issues) != 0 {
+		t.Fatalf("unexpected issues: %v", issues)
+	}
+	if out != "# Export\n{Describe the problem}\n" {
+		t.Errorf("unexpected render output: %q", out)
+	}
+}
+
+func TestVariables(t *testing.T) {
+	got := Variables([]byte("{DATE} {AUTHOR} {DATE} {free text}\n"))
+	if strings.Join(got, ",") != "AUTHOR,DATE" {
+		t.Errorf("Variables() = %v", got)
+	}
+}
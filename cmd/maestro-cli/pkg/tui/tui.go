@@ -0,0 +1,240 @@
+// Package tui provides the full-screen prompts behind init --interactive:
+// a checklist, a single-choice picker, and a confirmation screen. Each runs
+// as its own bubbletea program on the terminal's alternate screen.
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ErrAborted is returned when the user quits a prompt with esc or ctrl+c.
+var ErrAborted = errors.New("cancelled")
+
+// Option is one choice of a Pick prompt.
+type Option struct {
+	Label       string
+	Description string
+}
+
+// Checklist asks the user to tick any number of items, starting with the
+// ones in checked ticked, and returns the ticked items in order.
+func Checklist(title string, items, checked []string) ([]string, error) {
+	final, err := run(newChecklist(title, items, checked))
+	if err != nil {
+		return nil, err
+	}
+	return final.(checklistModel).selected()
+}
+
+// Pick asks the user to choose one of options and returns its index.
+func Pick(title string, options []Option) (int, error) {
+	final, err := run(pickModel{title: title, options: options})
+	if err != nil {
+		return 0, err
+	}
+	m := final.(pickModel)
+	if m.aborted {
+		return 0, ErrAborted
+	}
+	return m.cursor, nil
+}
+
+// Confirm shows lines under title and asks whether to go ahead.
+func Confirm(title string, lines []string) (bool, error) {
+	final, err := run(confirmModel{title: title, lines: lines})
+	if err != nil {
+		return false, err
+	}
+	m := final.(confirmModel)
+	if m.aborted {
+		return false, ErrAborted
+	}
+	return m.confirmed, nil
+}
+
+func run(m tea.Model) (tea.Model, error) {
+	final, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	if err != nil {
+		return nil, fmt.Errorf("running terminal UI: %w", err)
+	}
+	return final, nil
+}
+
+// checklistModel is the Checklist prompt.
+type checklistModel struct {
+	title   string
+	items   []string
+	checked []bool
+	cursor  int
+	aborted bool
+}
+
+func newChecklist(title string, items, checked []string) checklistModel {
+	m := checklistModel{title: title, items: items, checked: make([]bool, len(items))}
+	for i, item := range items {
+		for _, c := range checked {
+			if item == c {
+				m.checked[i] = true
+			}
+		}
+	}
+	return m
+}
+
+func (m checklistModel) Init() tea.Cmd { return nil }
+
+func (m checklistModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "up", "k":
+		m.cursor = moveCursor(m.cursor, -1, len(m.items))
+	case "down", "j", "tab":
+		m.cursor = moveCursor(m.cursor, 1, len(m.items))
+	case " ", "x":
+		if len(m.items) > 0 {
+			m.checked[m.cursor] = !m.checked[m.cursor]
+		}
+	case "a":
+		all := true
+		for _, c := range m.checked {
+			all = all && c
+		}
+		for i := range m.checked {
+			m.checked[i] = !all
+		}
+	case "enter":
+		return m, tea.Quit
+	case "esc", "ctrl+c", "q":
+		m.aborted = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m checklistModel) View() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", m.title)
+	for i, item := range m.items {
+		box := "[ ]"
+		if m.checked[i] {
+			box = "[x]"
+		}
+		fmt.Fprintf(&b, "%s %s %s\n", pointer(i == m.cursor), box, item)
+	}
+	b.WriteString("\n↑/↓ move • space toggle • a all/none • enter confirm • esc cancel\n")
+	return b.String()
+}
+
+func (m checklistModel) selected() ([]string, error) {
+	if m.aborted {
+		return nil, ErrAborted
+	}
+	selected := []string{}
+	for i, item := range m.items {
+		if m.checked[i] {
+			selected = append(selected, item)
+		}
+	}
+	return selected, nil
+}
+
+// pickModel is the Pick prompt.
+type pickModel struct {
+	title   string
+	options []Option
+	cursor  int
+	aborted bool
+}
+
+func (m pickModel) Init() tea.Cmd { return nil }
+
+func (m pickModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "up", "k":
+		m.cursor = moveCursor(m.cursor, -1, len(m.options))
+	case "down", "j", "tab":
+		m.cursor = moveCursor(m.cursor, 1, len(m.options))
+	case "enter":
+		return m, tea.Quit
+	case "esc", "ctrl+c", "q":
+		m.aborted = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m pickModel) View() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", m.title)
+	for i, option := range m.options {
+		fmt.Fprintf(&b, "%s %s\n", pointer(i == m.cursor), option.Label)
+		if option.Description != "" {
+			fmt.Fprintf(&b, "    %s\n", option.Description)
+		}
+	}
+	b.WriteString("\n↑/↓ move • enter choose • esc cancel\n")
+	return b.String()
+}
+
+// confirmModel is the Confirm prompt.
+type confirmModel struct {
+	title     string
+	lines     []string
+	confirmed bool
+	aborted   bool
+}
+
+func (m confirmModel) Init() tea.Cmd { return nil }
+
+func (m confirmModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "y", "enter":
+		m.confirmed = true
+		return m, tea.Quit
+	case "n":
+		return m, tea.Quit
+	case "esc", "ctrl+c", "q":
+		m.aborted = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m confirmModel) View() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", m.title)
+	for _, line := range m.lines {
+		fmt.Fprintf(&b, "  %s\n", line)
+	}
+	b.WriteString("\nenter/y go ahead • n/esc cancel\n")
+	return b.String()
+}
+
+func moveCursor(cursor, delta, n int) int {
+	if n == 0 {
+		return 0
+	}
+	return (cursor + delta + n) % n
+}
+
+func pointer(current bool) string {
+	if current {
+		return ">"
+	}
+	return " "
+}
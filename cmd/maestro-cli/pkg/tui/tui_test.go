@@ -0,0 +1,87 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "up":
+		return tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case " ":
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func press(m tea.Model, keys ...string) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	for _, k := range keys {
+		m, cmd = m.Update(key(k))
+	}
+	return m, cmd
+}
+
+func TestChecklist(t *testing.T) {
+	m := newChecklist("Agents", []string{".opencode", ".claude", ".codex"}, []string{".claude"})
+	if view := m.View(); !strings.Contains(view, "[x] .claude") || !strings.Contains(view, "[ ] .codex") {
+		t.Errorf("preselected items should be ticked:\n%s", view)
+	}
+
+	final, cmd := press(m, " ", "down", " ", "down", "down", "enter")
+	if cmd == nil {
+		t.Fatal("enter should quit")
+	}
+	got, err := final.(checklistModel).selected()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{".opencode"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("selected = %v, want %v", got, want)
+	}
+
+	final, _ = press(m, "a")
+	if got, _ := final.(checklistModel).selected(); len(got) != 3 {
+		t.Errorf("a should tick every item, got %v", got)
+	}
+
+	final, _ = press(m, "esc")
+	if _, err := final.(checklistModel).selected(); err != ErrAborted {
+		t.Errorf("esc should abort, got %v", err)
+	}
+}
+
+func TestPick(t *testing.T) {
+	m := pickModel{title: "Conflict", options: []Option{{Label: "Back up"}, {Label: "Overwrite"}, {Label: "Cancel"}}}
+	final, _ := press(m, "up", "enter")
+	if p := final.(pickModel); p.cursor != 2 || p.aborted {
+		t.Errorf("up from the first option should wrap to the last, got %+v", p)
+	}
+	final, _ = press(m, "q")
+	if !final.(pickModel).aborted {
+		t.Error("q should abort")
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	m := confirmModel{title: "Install?", lines: []string{"Agent dirs: .claude"}}
+	if !strings.Contains(m.View(), "Agent dirs: .claude") {
+		t.Errorf("summary lines missing:\n%s", m.View())
+	}
+	if final, _ := press(m, "enter"); !final.(confirmModel).confirmed {
+		t.Error("enter should confirm")
+	}
+	if final, _ := press(m, "n"); final.(confirmModel).confirmed || final.(confirmModel).aborted {
+		t.Error("n should decline without aborting")
+	}
+}
@@ -0,0 +1,80 @@
+// Package updatenotice remembers the latest maestro release in the user's
+// cache, so any command can say that a newer release is out without asking
+// GitHub more than once a day.
+package updatenotice
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spec-maestro/maestro-cli/internal/version"
+	"github.com/spec-maestro/maestro-cli/pkg/userdirs"
+)
+
+// Interval is how long a check's result is used before checking again.
+const Interval = 24 * time.Hour
+
+// fileName is the cache file under ~/.cache/maestro.
+const fileName = "update-check.json"
+
+// State is the result of the last check.
+type State struct {
+	CheckedAt time.Time `json:"checked_at"`
+	// Latest is the tag of the latest release, empty when the check
+	// failed.
+	Latest string `json:"latest,omitempty"`
+}
+
+// Path returns the cache file, ~/.cache/maestro/update-check.json, or
+// update-check.json in the portable cache directory.
+func Path() (string, error) {
+	dir, err := userdirs.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fileName), nil
+}
+
+// Load reads the state at path. A missing or unreadable file is the zero
+// State, which is due for a check.
+func Load(path string) State {
+	var state State
+	data, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(data, &state) != nil {
+		return State{}
+	}
+	return state
+}
+
+// Save writes state to path, creating its directory.
+func Save(path string, state State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Due reports whether the last check is older than Interval, or in the
+// future because the clock changed.
+func (s State) Due(now time.Time) bool {
+	return now.Sub(s.CheckedAt) >= Interval || s.CheckedAt.After(now)
+}
+
+// Notice returns the message that release latest is newer than current,
+// or "" when it is not or either cannot be compared.
+func Notice(current, latest string) string {
+	if latest == "" {
+		return ""
+	}
+	if newer, err := version.Less(current, latest); err != nil || !newer {
+		return ""
+	}
+	return fmt.Sprintf("A newer maestro release %s is available (you have %s). Run 'maestro update --check' for details.", latest, current)
+}
@@ -0,0 +1,47 @@
+package updatenotice
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveLoadDue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "maestro", fileName)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	if state := Load(path); !state.Due(now) {
+		t.Error("a missing cache should be due for a check")
+	}
+	if err := Save(path, State{CheckedAt: now, Latest: "v1.4.0"}); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	state := Load(path)
+	if state.Latest != "v1.4.0" || !state.CheckedAt.Equal(now) {
+		t.Errorf("Load() = %+v", state)
+	}
+	for _, tt := range []struct {
+		at  time.Time
+		due bool
+	}{
+		{now.Add(time.Hour), false},
+		{now.Add(Interval), true},
+		{now.Add(-time.Hour), true},
+	} {
+		if got := state.Due(tt.at); got != tt.due {
+			t.Errorf("Due(%v) = %v, want %v", tt.at, got, tt.due)
+		}
+	}
+}
+
+func TestNotice(t *testing.T) {
+	if got := Notice("v1.3.0", "v1.4.0"); !strings.Contains(got, "v1.4.0 is available (you have v1.3.0)") {
+		t.Errorf("Notice() = %q", got)
+	}
+	for _, tt := range [][2]string{{"v1.4.0", "v1.4.0"}, {"v1.5.0", "v1.4.0"}, {"dev", "v1.4.0"}, {"v1.3.0", ""}} {
+		if got := Notice(tt[0], tt[1]); got != "" {
+			t.Errorf("Notice(%q, %q) = %q, want none", tt[0], tt[1], got)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Package userdirs locates the directories maestro keeps its per-user
+// cache and global config in: under the home directory, or, in portable
+// mode, in a maestro-data directory next to the executable, so maestro can
+// run from a USB stick or a toolbox without writing to the machine.
+package userdirs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// PortableEnv turns on portable mode when set to a true value, like the
+// --portable flag.
+const PortableEnv = "MAESTRO_PORTABLE"
+
+// DataDirName is the directory next to the executable that holds the
+// cache and config in portable mode.
+const DataDirName = "maestro-data"
+
+var (
+	mu       sync.Mutex
+	portable bool
+	// executable returns the path of the running binary; tests replace it.
+	executable = os.Executable
+)
+
+// SetPortable turns portable mode on for the rest of the process, as the
+// --portable flag does. Turning it off leaves MAESTRO_PORTABLE in charge.
+func SetPortable(on bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	portable = on
+}
+
+// Portable reports whether portable mode is on, through SetPortable or
+// MAESTRO_PORTABLE.
+func Portable() bool {
+	mu.Lock()
+	on := portable
+	mu.Unlock()
+	if on {
+		return true
+	}
+	on, _ = strconv.ParseBool(os.Getenv(PortableEnv))
+	return on
+}
+
+// DataDir returns the portable data directory, maestro-data next to the
+// executable, with symlinks to the executable resolved.
+func DataDir() (string, error) {
+	exe, err := executable()
+	if err != nil {
+		return "", fmt.Errorf("locating the maestro executable for portable mode: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return filepath.Join(filepath.Dir(exe), DataDirName), nil
+}
+
+// CacheDir returns the cache directory: ~/.cache/maestro, or
+// maestro-data/cache in portable mode.
+func CacheDir() (string, error) {
+	return dir("cache", ".cache")
+}
+
+// ConfigDir returns the global config directory: ~/.config/maestro, or
+// maestro-data/config in portable mode.
+func ConfigDir() (string, error) {
+	return dir("config", ".config")
+}
+
+// dir returns the portable subdirectory name, or maestro under the home
+// directory's hidden.
+func dir(name, hidden string) (string, error) {
+	if Portable() {
+		data, err := DataDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(data, name), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home directory: %w", err)
+	}
+	return filepath.Join(home, hidden, "maestro"), nil
+}
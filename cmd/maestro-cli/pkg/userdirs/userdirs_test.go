@@ -0,0 +1,51 @@
+package userdirs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv(PortableEnv, "")
+	defer SetPortable(false)
+
+	if dir, err := CacheDir(); err != nil || dir != filepath.Join(home, ".cache", "maestro") {
+		t.Errorf("CacheDir() = %q, %v, want it under the home directory", dir, err)
+	}
+	if dir, err := ConfigDir(); err != nil || dir != filepath.Join(home, ".config", "maestro") {
+		t.Errorf("ConfigDir() = %q, %v, want it under the home directory", dir, err)
+	}
+
+	// The binary is found through a symlink, as when it is on PATH
+	toolbox := t.TempDir()
+	exe := filepath.Join(toolbox, "maestro")
+	os.WriteFile(exe, nil, 0755)
+	link := filepath.Join(t.TempDir(), "maestro")
+	if err := os.Symlink(exe, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	saved := executable
+	executable = func() (string, error) { return link, nil }
+	defer func() { executable = saved }()
+	toolbox, _ = filepath.EvalSymlinks(toolbox)
+
+	for _, enable := range []func(){
+		func() { SetPortable(true) },
+		func() { SetPortable(false); t.Setenv(PortableEnv, "1") },
+	} {
+		enable()
+		if !Portable() {
+			t.Fatal("Portable() = false, want true")
+		}
+		if dir, err := CacheDir(); err != nil || dir != filepath.Join(toolbox, DataDirName, "cache") {
+			t.Errorf("portable CacheDir() = %q, %v, want it next to the binary", dir, err)
+		}
+		if dir, err := ConfigDir(); err != nil || dir != filepath.Join(toolbox, DataDirName, "config") {
+			t.Errorf("portable ConfigDir() = %q, %v, want it next to the binary", dir, err)
+		}
+	}
+}
@@ -298,3 +298,41 @@ func TestPlanCommandContractRetainsExactBypassPhrase(t *testing.T) {
 		t.Fatalf("plan command must include exact bypass phrase %q", bypassPhrase)
 	}
 }
+
+// TestCheckMatchesCheckPrerequisitesScript runs maestro check and the
+// script on the same features and expects the same output and status.
+func TestCheckMatchesCheckPrerequisitesScript(t *testing.T) {
+	for _, fixture := range []string{"complete", "partial", "missing-quality"} {
+		t.Run(fixture, func(t *testing.T) {
+			worktree, featureDir := seedFeatureWithResearchFixture(t, fixture)
+			want, scriptErr := runCheckPrerequisitesPlan(t, worktree, featureDir)
+
+			cmd := exec.Command(maestroBin, "check", "plan", featureDir)
+			cmd.Dir = worktree
+			cmd.Env = append(os.Environ(), "MAESTRO_MAIN_REPO="+worktree)
+			out, err := cmd.CombinedOutput()
+			if got := strings.TrimSpace(string(out)); got != want {
+				t.Errorf("maestro check plan = %s, script = %s", got, want)
+			}
+			if (err == nil) != (scriptErr == nil) {
+				t.Errorf("maestro check plan error = %v, script error = %v", err, scriptErr)
+			}
+		})
+	}
+
+	// A relative feature directory is named as given
+	t.Run("relative", func(t *testing.T) {
+		worktree, _ := seedFeatureWithResearchFixture(t, "complete")
+		featureDir := filepath.Join(".maestro", "specs", "no-spec")
+		os.MkdirAll(filepath.Join(worktree, featureDir), 0755)
+		want, _ := runCheckPrerequisitesPlan(t, worktree, featureDir)
+
+		cmd := exec.Command(maestroBin, "check", "plan", featureDir)
+		cmd.Dir = worktree
+		cmd.Env = append(os.Environ(), "MAESTRO_MAIN_REPO="+worktree)
+		out, _ := cmd.CombinedOutput()
+		if got := strings.TrimSpace(string(out)); got != want {
+			t.Errorf("maestro check plan = %s, script = %s", got, want)
+		}
+	})
+}